@@ -8,11 +8,8 @@ import (
 	"github.com/okto-digital/regis3/internal/cli"
 )
 
-// Build-time variables (set via ldflags)
-var (
-	version   = "dev"
-	buildTime = "unknown"
-)
+// Build metadata (version, commit, build time) lives in internal/buildinfo,
+// so the CLI and release tooling read the same ldflags-injected values.
 
 func main() {
 	if err := cli.Execute(); err != nil {
@@ -0,0 +1,41 @@
+// Package buildinfo holds build metadata injected at link time.
+//
+// Set with: -ldflags "-X github.com/okto-digital/regis3/internal/buildinfo.Version=1.2.3"
+package buildinfo
+
+import "runtime"
+
+// Build-time variables (set via ldflags by the Makefile and goreleaser).
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+	BuiltBy = "manual"
+)
+
+// Info is a snapshot of the build metadata together with runtime details.
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	BuiltBy   string
+	GoVersion string
+	Platform  string
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		BuiltBy:   BuiltBy,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// IsRelease reports whether the binary was built from a tagged release.
+func IsRelease() bool {
+	return Version != "dev"
+}
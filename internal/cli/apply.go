@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// Apply flags
+var (
+	applyDryRun  bool
+	applyScripts bool
+)
+
+// applyCmd reconciles the project with its spec
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Install the items declared in .regis3.yaml",
+	Long: `Reconciles the project with its spec, .regis3.yaml in the project root:
+installs listed items that are missing, updates those whose registry content
+changed and removes installed items that are no longer listed. Dependencies
+of listed items are installed and kept too.
+
+The spec lists item references and, optionally, the target:
+
+  target: claude
+  items:
+    - skill:git-conventions
+    - stack:base
+
+Pinned items stay at their installed content while they are listed.
+
+Examples:
+  regis3 apply
+  regis3 apply --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApply()
+	},
+}
+
+func init() {
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Preview what would be installed, updated and removed")
+	applyCmd.Flags().BoolVar(&noReport, "no-report", false, "Don't report the installation to the webhook")
+	applyCmd.Flags().BoolVar(&applyScripts, "allow-scripts", false, "Run item setup scripts without asking")
+	rootCmd.AddCommand(applyCmd)
+}
+
+func runApply() error {
+	spec, err := installer.LoadSpec(".")
+	if errors.Is(err, installer.ErrNoSpec) {
+		writer.Error(i18n.Sprintf("No %s in this project", installer.SpecFile))
+		return &exitError{code: 1, message: "no project spec"}
+	}
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	// Resolve shorthand references and aliases
+	ids, notices, err := resolveRefs(manifest, spec.Items)
+	if err != nil {
+		writer.Error(fmt.Sprintf("%s: %s", installer.SpecFile, err.Error()))
+		return fmt.Errorf("item not found")
+	}
+
+	target, err := resolveTarget(spec.Target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	if !applyDryRun {
+		release, err := guardProject("apply")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	inst, err := newInstaller(target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
+		return err
+	}
+	inst.DryRun = applyDryRun
+	inst.ResolverOptions = resolverOptions(nil)
+	inst.Timings = timings()
+	inst.AllowScripts = applyScripts
+	if cfg != nil {
+		inst.MergeBudget = cfg.MergeBudgetSize()
+	}
+	if isInteractive() {
+		inst.ConfirmScript = confirmSetupScript
+		inst.ConfirmMerge = confirmMergeChange
+	}
+
+	result, err := inst.Apply(manifest, ids)
+	if errors.Is(err, installer.ErrMergeDeclined) {
+		writer.Info(i18n.Sprintf("Installation cancelled, %s left unchanged", target.MergeFile))
+		return nil
+	}
+	if err != nil {
+		writer.Error(i18n.Sprintf("Apply failed: %s", err.Error()))
+		return err
+	}
+
+	resp := installResponse("apply", result.Install, target, notices, applyDryRun)
+	reportInstall(resp, "apply", target, result.Install, applyDryRun)
+	if removed := result.Uninstall; removed != nil {
+		for _, e := range removed.Errors {
+			resp.WithSuccess(false).WithError(e.ItemID, e.Message)
+		}
+		if applyDryRun {
+			resp.WithInfo("Would remove %d items (dry run)", len(removed.Uninstalled))
+		} else if len(removed.Uninstalled) > 0 {
+			resp.WithInfo("Removed %d items not in %s", len(removed.Uninstalled), installer.SpecFile)
+		}
+		if len(removed.Skipped) > 0 {
+			resp.WithWarning("Skipped %d merged items (edit %s manually)", len(removed.Skipped), target.MergeFile)
+		}
+	}
+	for _, id := range inst.Overrides.Unmatched(manifest) {
+		resp.WithWarning("%s in %s overrides no registry item", id, installer.LocalDir)
+	}
+	writer.Write(resp.Build())
+
+	if len(result.Install.Errors) > 0 || (result.Uninstall != nil && len(result.Uninstall.Errors) > 0) {
+		return fmt.Errorf("apply failed")
+	}
+	return nil
+}
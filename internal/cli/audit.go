@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List items with executable content for security review",
+	Long: `Lists every registry item that could execute code on a developer's machine:
+
+- script and hook items
+- items with a setup script or an executable install mode
+- additional files that are scripts or executable
+- shell code blocks in the content (bash, sh, powershell, ...)
+
+Each flagged item lists its files with SHA256 hashes and modification times,
+so reviewers can approve a registry and later check nothing changed.
+
+Examples:
+  regis3 audit
+  regis3 audit --format json > audit.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAudit()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAudit() error {
+	registryPath := getRegistryPath()
+	debugf("Auditing registry: %s", registryPath)
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	findings, err := registry.Audit(registryPath, manifest)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Audit failed: %s", err.Error()))
+		return err
+	}
+
+	data := output.AuditData{
+		Items:        make([]output.AuditItem, 0, len(findings)),
+		Count:        len(findings),
+		ItemsScanned: len(manifest.Items),
+	}
+	for _, f := range findings {
+		item := output.AuditItem{ID: f.ID, Reasons: f.Reasons}
+		for _, file := range f.Files {
+			item.Files = append(item.Files, output.AuditFile{
+				Path:     file.Path,
+				SHA256:   file.SHA256,
+				Size:     file.Size,
+				Modified: file.Modified.UTC().Format(time.RFC3339),
+			})
+		}
+		data.Items = append(data.Items, item)
+	}
+
+	resp := output.NewResponseBuilder("audit").
+		WithSuccess(true).
+		WithData(&data)
+
+	writer.Write(resp.Build())
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/pkg/refs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectAutoremoveDryRun bool
+	projectAutoremoveTarget string
+)
+
+// projectAutoremoveCmd removes dependencies nothing needs any more
+var projectAutoremoveCmd = &cobra.Command{
+	Use:   "autoremove",
+	Short: "Remove dependencies no installed item needs any more",
+	Long: `Removes the items that were only installed as dependencies of other
+items and that no explicitly installed item depends on any more, such as the
+skills of a stack that was removed.
+
+Items named in 'project add' or the project spec are installed explicitly
+and never removed by autoremove; their dependencies are not. Items installed
+before regis3 recorded this count as explicit.
+
+Examples:
+  regis3 project autoremove --dry-run
+  regis3 project autoremove`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectAutoremove()
+	},
+}
+
+func init() {
+	projectAutoremoveCmd.Flags().BoolVar(&projectAutoremoveDryRun, "dry-run", false, "Preview what would be removed")
+	projectAutoremoveCmd.Flags().StringVar(&projectAutoremoveTarget, "target", "", "Target (default: from config, or detected from the project)")
+	projectCmd.AddCommand(projectAutoremoveCmd)
+}
+
+func runProjectAutoremove() error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	target, err := resolveTarget(projectAutoremoveTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	if !projectAutoremoveDryRun {
+		release, err := guardProject("project autoremove")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	inst, err := newInstaller(target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
+		return err
+	}
+	inst.DryRun = projectAutoremoveDryRun
+	inst.ResolverOptions = resolverOptions(nil)
+
+	ids, err := inst.Unneeded(manifest)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	if len(ids) == 0 {
+		writer.Write(output.NewResponseBuilder("project autoremove").
+			WithSuccess(true).
+			WithData(output.RemoveData{Removed: []output.InstalledItem{}, DryRun: projectAutoremoveDryRun}).
+			WithInfo("No unneeded dependencies installed").
+			Build())
+		return nil
+	}
+
+	result, err := inst.Uninstall(ids, manifest)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Uninstall failed: %s", err.Error()))
+		return err
+	}
+
+	removed := []output.InstalledItem{}
+	for _, id := range result.Uninstalled {
+		if itemType, name, ok := refs.Split(id); ok {
+			removed = append(removed, output.InstalledItem{Type: itemType, Name: name})
+		}
+	}
+
+	resp := output.NewResponseBuilder("project autoremove").
+		WithData(output.RemoveData{
+			Removed: removed,
+			DryRun:  projectAutoremoveDryRun,
+			Paths:   result.Paths,
+		})
+	if len(result.Errors) > 0 {
+		resp.WithSuccess(false)
+		for _, e := range result.Errors {
+			resp.WithError(e.ItemID, e.Message)
+		}
+	} else {
+		resp.WithSuccess(true)
+		if projectAutoremoveDryRun {
+			resp.WithInfo("Would remove %d unneeded dependencies (dry run)", len(removed))
+		} else {
+			resp.WithInfo("Removed %d unneeded dependencies", len(removed))
+		}
+		if len(result.Skipped) > 0 {
+			resp.WithWarning("Skipped %d merged items (edit %s manually)", len(result.Skipped), target.MergeFile)
+		}
+	}
+
+	writer.Write(resp.Build())
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("removal failed")
+	}
+	return nil
+}
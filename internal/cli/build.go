@@ -2,8 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
+	"github.com/okto-digital/regis3/internal/config"
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
@@ -14,47 +20,285 @@ var buildCmd = &cobra.Command{
 	Long: `Scans the registry directory for markdown files with regis3 frontmatter
 and builds a manifest.json file in the .build directory.
 
-The manifest is used for fast lookups and dependency resolution.`,
+The manifest is used for fast lookups and dependency resolution.
+
+Include/exclude globs from the config (build.include, build.exclude) limit
+which files are scanned. --only replaces the configured include patterns and
+--exclude adds to the configured exclude patterns. "**" matches any number of
+directories.
+
+--paths updates only the items defined in the given files in the existing
+manifest, which is much faster on large registries (e.g. in pre-commit hooks).
+Dependency and duplicate checks still cover the whole registry. Paths may be
+relative to the registry root or to the current directory.
+
+--all builds registry_path and every registry listed under registries in the
+config concurrently and reports the results together. Registries that fail
+don't stop the others from being built.
+
+--allow-errors saves the manifest despite validation errors, so unrelated
+items can still be installed while the errors are fixed. Items with errors,
+and the items depending on them, are left out of the manifest and listed in
+its quarantine section. A later --paths build keeps them quarantined until
+their file is rebuilt.
+
+Examples:
+  regis3 build
+  regis3 build --only 'skills/**'     # Focus on skills while iterating
+  regis3 build --exclude 'drafts/**'
+  regis3 build --paths skills/foo.md,skills/bar.md
+  regis3 build --all
+  regis3 build --allow-errors`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runBuild()
 	},
 }
 
+// Build command flags
+var (
+	buildOnly    []string
+	buildExclude []string
+	buildPaths   []string
+	buildAll     bool
+
+	buildAllowErrors bool
+)
+
 func init() {
+	buildCmd.Flags().StringSliceVar(&buildOnly, "only", nil, "Only build files matching these globs")
+	buildCmd.Flags().StringSliceVar(&buildExclude, "exclude", nil, "Skip files matching these globs")
+	buildCmd.Flags().StringSliceVar(&buildPaths, "paths", nil, "Only rebuild items from these files in the existing manifest")
+	buildCmd.Flags().BoolVar(&buildAll, "all", false, "Build every configured registry")
+	buildCmd.Flags().BoolVar(&buildAllowErrors, "allow-errors", false, "Save the manifest without the items that fail validation")
 	rootCmd.AddCommand(buildCmd)
 }
 
 func runBuild() error {
+	if buildAll {
+		if len(buildPaths) > 0 {
+			return fmt.Errorf("--all cannot be combined with --paths")
+		}
+		return runBuildAll()
+	}
+	if buildAllowErrors && len(buildPaths) > 0 {
+		return fmt.Errorf("--allow-errors cannot be combined with --paths")
+	}
+
 	debugf("Building manifest from: %s", getRegistryPath())
 
-	result, err := registry.BuildRegistry(getRegistryPath())
+	opts := buildOptions()
+	if len(buildOnly) > 0 {
+		opts.Filter.Include = buildOnly
+	}
+	opts.Filter.Exclude = append(opts.Filter.Exclude, buildExclude...)
+	opts.AllowErrors = buildAllowErrors
+
+	var result *registry.BuildResult
+	var err error
+	if len(buildPaths) > 0 {
+		result, err = registry.UpdateRegistry(getRegistryPath(), registryRelativePaths(buildPaths), opts)
+	} else {
+		result, err = registry.BuildRegistryWithOptions(getRegistryPath(), opts)
+	}
 	if err != nil {
-		writer.Error(fmt.Sprintf("Build failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Build failed: %s", err.Error()))
 		return err
 	}
 
 	itemCount := len(result.Manifest.Items)
-	manifestPath := fmt.Sprintf("%s/.build/manifest.json", getRegistryPath())
+	data := buildData(result)
 
 	// Create response
 	resp := output.NewResponseBuilder("build").
-		WithSuccess(true).
-		WithData(output.BuildData{
-			ItemCount:    itemCount,
-			ManifestPath: manifestPath,
-			Duration:     result.Duration.String(),
-		})
+		WithData(data)
 
 	// Add info about items found
 	if itemCount > 0 {
 		resp.WithInfo("Found %d items", itemCount)
 	}
 
-	// Add any errors from scanning
+	if !opts.Filter.IsEmpty() {
+		resp.WithWarning("Filtered build: the manifest only contains matching items")
+	}
+
+	// Add any errors from scanning. Files named with --paths must parse.
+	failed := false
 	for _, scanErr := range result.ScanErrors {
-		resp.WithWarning("%s: %s", scanErr.Path, scanErr.Message)
+		if len(buildPaths) > 0 {
+			resp.WithError(scanErr.Path, scanErr.Error())
+			failed = true
+		} else {
+			resp.WithWarning("%s: %s", scanErr.Path, scanErr.Message)
+		}
+	}
+
+	// The manifest is not saved when validation fails, unless the items
+	// with errors were left out
+	for _, issue := range result.Validation.Errors() {
+		if opts.AllowErrors {
+			resp.WithWarning("%s: %s", issue.Path, issue.Message)
+			continue
+		}
+		resp.WithError(issue.Path, issue.Message)
+		failed = true
+	}
+	if n := len(result.Manifest.Quarantine); n > 0 && opts.AllowErrors {
+		resp.WithWarning("Quarantined %d items with validation errors; they are left out of the manifest", n)
+	}
+
+	resp.WithSuccess(!failed)
+	writer.Write(resp.Build())
+
+	if failed {
+		return errValidationFailed
+	}
+	return nil
+}
+
+// registryRelativePaths converts paths to be relative to the registry root.
+// A path that exists relative to the registry is kept; otherwise a path that
+// resolves inside the registry from the current directory is converted.
+func registryRelativePaths(paths []string) []string {
+	root, err := filepath.Abs(getRegistryPath())
+	if err != nil {
+		return paths
+	}
+
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			if _, err := os.Stat(filepath.Join(root, p)); err == nil {
+				result = append(result, p)
+				continue
+			}
+		}
+		if abs, err := filepath.Abs(p); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil && pathutil.CheckRelative(rel) == nil {
+				p = rel
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// buildData returns the response data for a build of the registry.
+func buildData(result *registry.BuildResult) output.BuildData {
+	data := output.BuildData{
+		ItemCount:    len(result.Manifest.Items),
+		Excluded:     len(result.Excluded),
+		Updated:      result.Updated,
+		Removed:      result.Removed,
+		ManifestPath: fmt.Sprintf("%s/.build/manifest.json", getRegistryPath()),
+		Duration:     result.Duration.String(),
+	}
+	if result.Manifest.Health != nil {
+		data.Health = &result.Manifest.Health.Score
+	}
+	for _, q := range result.Manifest.Quarantine {
+		if q.ID != "" {
+			data.Quarantined = append(data.Quarantined, q.ID)
+		} else {
+			data.Quarantined = append(data.Quarantined, q.Path)
+		}
+	}
+	return data
+}
+
+// runBuildAll builds every configured registry concurrently.
+func runBuildAll() error {
+	registries := []config.NamedRegistry{{Name: config.DefaultRegistryName}}
+	if cfg != nil {
+		registries = cfg.AllRegistries()
+	}
+	registries[0].Path = getRegistryPath()
+
+	// Remote registries are cloned on first use
+	for _, reg := range registries {
+		if remote := reg.Remote(); remote != nil && !remote.Cloned() {
+			debugf("Cloning registry %s from %s", reg.Name, reg.URL)
+			if _, err := remote.Sync(); err != nil {
+				writer.Error(err.Error())
+				return err
+			}
+		}
+	}
+
+	base := buildOptions()
+	// Timings are not safe for concurrent use
+	base.Timings = nil
+	if len(buildOnly) > 0 {
+		base.Filter.Include = buildOnly
 	}
+	base.Filter.Exclude = append(base.Filter.Exclude, buildExclude...)
+	base.AllowErrors = buildAllowErrors
+
+	results := make([]*registry.BuildResult, len(registries))
+	errs := make([]error, len(registries))
+	var wg sync.WaitGroup
+	for i, reg := range registries {
+		debugf("Building manifest from: %s", reg.Path)
+		opts := base
+		if cfg != nil {
+			opts.StagingDir = cfg.StagingPathIn(reg.Path)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = registry.BuildRegistryWithOptions(reg.Path, opts)
+		}()
+	}
+	wg.Wait()
+
+	resp := output.NewResponseBuilder("build")
+	data := output.BuildAllData{}
+	failed := false
+	for i, reg := range registries {
+		build := output.RegistryBuild{Name: reg.Name, Path: reg.Path}
+		if errs[i] != nil {
+			build.Error = errs[i].Error()
+			resp.WithError(reg.Path, i18n.Sprintf("Build failed: %s", errs[i].Error()))
+			data.Registries = append(data.Registries, build)
+			failed = true
+			continue
+		}
 
+		result := results[i]
+		build.ItemCount = len(result.Manifest.Items)
+		build.Duration = result.Duration.String()
+		if result.Manifest.Health != nil {
+			build.Health = &result.Manifest.Health.Score
+		}
+		for _, scanErr := range result.ScanErrors {
+			resp.WithWarning("%s: %s", filepath.Join(reg.Path, scanErr.Path), scanErr.Message)
+			build.Warnings++
+		}
+		// The manifest is not saved when validation fails, unless the
+		// items with errors were left out
+		for _, issue := range result.Validation.Errors() {
+			if base.AllowErrors {
+				resp.WithWarning("%s: %s", filepath.Join(reg.Path, issue.Path), issue.Message)
+				build.Warnings++
+				continue
+			}
+			resp.WithError(filepath.Join(reg.Path, issue.Path), issue.Message)
+			build.Errors++
+			failed = true
+		}
+		build.Quarantined = len(result.Manifest.Quarantine)
+		data.Registries = append(data.Registries, build)
+	}
+
+	if !base.Filter.IsEmpty() {
+		resp.WithWarning("Filtered build: the manifest only contains matching items")
+	}
+
+	resp.WithData(&data).WithSuccess(!failed)
 	writer.Write(resp.Build())
+
+	if failed {
+		return errValidationFailed
+	}
 	return nil
 }
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/okto-digital/regis3/internal/config"
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var configCmd = &cobra.Command{
@@ -19,7 +23,8 @@ var configCmd = &cobra.Command{
 Examples:
   regis3 config                    # Show current config
   regis3 config get registry       # Get specific setting
-  regis3 config set registry ~/my-registry`,
+  regis3 config set registry ~/my-registry
+  regis3 config doctor             # Check config for problems`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runConfigShow()
 	},
@@ -30,7 +35,7 @@ var configGetCmd = &cobra.Command{
 	Short: "Get a configuration value",
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
-			return fmt.Errorf("missing key\n\nUsage: regis3 config get <key>\n\nKeys: registry, target")
+			return fmt.Errorf("missing key\n\nUsage: regis3 config get <key>\n\nKeys: registry, target, icons, locale, read_only")
 		}
 		return nil
 	},
@@ -64,7 +69,18 @@ var configPathCmd = &cobra.Command{
 	},
 }
 
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration for problems",
+	Long: `Checks the config file, its values, the registry directory, the manifest,
+and the default target, and reports anything that needs fixing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigDoctor()
+	},
+}
+
 func init() {
+	configCmd.AddCommand(configDoctorCmd)
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configPathCmd)
@@ -80,6 +96,9 @@ func runConfigShow() error {
 	if cfg != nil {
 		settings["registry"] = cfg.RegistryPath
 		settings["default_target"] = cfg.DefaultTarget
+		settings["icons"] = cfg.Icons
+		settings["locale"] = i18n.Locale()
+		settings["read_only"] = strconv.FormatBool(cfg.ReadOnly)
 	} else {
 		settings["registry"] = "(not set)"
 		settings["default_target"] = "(not set)"
@@ -108,8 +127,14 @@ func runConfigGet(key string) error {
 		value = cfg.RegistryPath
 	case "target", "default_target":
 		value = cfg.DefaultTarget
+	case "icons":
+		value = cfg.Icons
+	case "locale":
+		value = cfg.Locale
+	case "read_only":
+		value = strconv.FormatBool(cfg.ReadOnly)
 	default:
-		writer.Error(fmt.Sprintf("Unknown config key: %s", key))
+		writer.Error(i18n.Sprintf("Unknown config key: %s", key))
 		return fmt.Errorf("unknown key: %s", key)
 	}
 
@@ -130,14 +155,14 @@ func runConfigSet(key, value string) error {
 	if cfg != nil {
 		c = cfg
 	} else {
-		c = &config.Config{}
+		c = config.DefaultConfig()
 	}
 
 	// Set the value
 	switch key {
 	case "registry", "registry_path":
 		// Expand path
-		if value[0] == '~' {
+		if strings.HasPrefix(value, "~") {
 			home, _ := os.UserHomeDir()
 			value = filepath.Join(home, value[1:])
 		}
@@ -148,27 +173,23 @@ func runConfigSet(key, value string) error {
 		c.RegistryPath = value
 	case "target", "default_target":
 		c.DefaultTarget = value
+	case "icons":
+		c.Icons = value
+	case "locale":
+		c.Locale = value
+	case "read_only":
+		readOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			writer.Error(i18n.Sprintf("Invalid value for %s: %s (expected true or false)", key, value))
+			return fmt.Errorf("invalid value: %s", value)
+		}
+		c.ReadOnly = readOnly
 	default:
-		writer.Error(fmt.Sprintf("Unknown config key: %s", key))
+		writer.Error(i18n.Sprintf("Unknown config key: %s", key))
 		return fmt.Errorf("unknown key: %s", key)
 	}
 
-	// Ensure config directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		writer.Error(fmt.Sprintf("Failed to create config directory: %s", err.Error()))
-		return err
-	}
-
-	// Write config
-	data, err := yaml.Marshal(c)
-	if err != nil {
-		writer.Error(fmt.Sprintf("Failed to marshal config: %s", err.Error()))
-		return err
-	}
-
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		writer.Error(fmt.Sprintf("Failed to write config: %s", err.Error()))
+	if err := saveConfigFile(c, configPath); err != nil {
 		return err
 	}
 
@@ -180,6 +201,40 @@ func runConfigSet(key, value string) error {
 	return nil
 }
 
+// loadConfigFile loads the config file for editing and returns its path.
+// Unlike cfg, it doesn't have --registry applied.
+func loadConfigFile() (*config.Config, string, error) {
+	c, err := config.LoadUnvalidated(configFlag)
+	if err != nil {
+		writer.Error(err.Error())
+		return nil, "", err
+	}
+	path := c.Path()
+	if path == "" {
+		path = config.DefaultConfigPath()
+	}
+	return c, path, nil
+}
+
+// saveConfigFile writes the config to path after validating it.
+func saveConfigFile(c *config.Config, path string) error {
+	// Refuse to write a value that would break the next startup
+	if err := c.Validate(); err != nil {
+		if verr, ok := err.(*config.ValidationError); ok {
+			for _, fe := range verr.Errors {
+				writer.Error(fe.Error())
+			}
+		}
+		return fmt.Errorf("not saving invalid config")
+	}
+
+	if err := config.Save(c, path); err != nil {
+		writer.Error(i18n.Sprintf("Failed to write config: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
 func runConfigPath() error {
 	configPath := config.DefaultConfigPath()
 
@@ -190,3 +245,89 @@ func runConfigPath() error {
 	writer.Write(resp.Build())
 	return nil
 }
+
+func runConfigDoctor() error {
+	var checks []output.DoctorCheck
+	pass := func(name, format string, args ...interface{}) {
+		checks = append(checks, output.DoctorCheck{Name: name, Status: output.CheckOK, Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(name, format string, args ...interface{}) {
+		checks = append(checks, output.DoctorCheck{Name: name, Status: output.CheckWarning, Message: fmt.Sprintf(format, args...)})
+	}
+	fail := func(name, format string, args ...interface{}) {
+		checks = append(checks, output.DoctorCheck{Name: name, Status: output.CheckError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	configPath := configFlag
+	if configPath == "" {
+		configPath = config.DefaultConfigPath()
+	}
+
+	c, err := config.LoadUnvalidated(configFlag)
+	switch {
+	case err != nil:
+		fail("config file", "%s", err.Error())
+		c = config.DefaultConfig()
+	case c.Path() == "":
+		warn("config file", "not found at %s; using defaults (run 'regis3 init')", configPath)
+	default:
+		pass("config file", "%s", c.Path())
+	}
+	if registryFlag != "" {
+		c.RegistryPath = registryFlag
+	}
+
+	// Field values
+	if err := c.Validate(); err != nil {
+		if verr, ok := err.(*config.ValidationError); ok {
+			for _, fe := range verr.Errors {
+				fail(fe.Field, "%s", fe.Message)
+			}
+		}
+	} else {
+		pass("settings", "all values are valid")
+	}
+
+	// Registry directory and manifest
+	if info, err := os.Stat(c.RegistryPath); err != nil {
+		fail("registry", "%s does not exist (run 'regis3 init' or 'regis3 config set registry <path>')", c.RegistryPath)
+	} else if !info.IsDir() {
+		fail("registry", "%s is not a directory", c.RegistryPath)
+	} else {
+		pass("registry", "%s", c.RegistryPath)
+
+		if manifest, err := registry.LoadManifestFromRegistry(c.RegistryPath); err != nil {
+			warn("manifest", "not built yet (run 'regis3 build')")
+		} else {
+			pass("manifest", "%d items", len(manifest.Items))
+		}
+	}
+
+	// A known default target must also have a loadable definition
+	if c.DefaultTarget == "auto" {
+		pass("default_target", "auto (detected per project)")
+	} else if slices.Contains(config.KnownTargets, c.DefaultTarget) {
+		if _, err := loadTarget(c.DefaultTarget); err != nil {
+			fail("default_target", "cannot load target %q: %s", c.DefaultTarget, err.Error())
+		} else {
+			pass("default_target", "%s", c.DefaultTarget)
+		}
+	}
+
+	healthy := true
+	for _, check := range checks {
+		if check.Status == output.CheckError {
+			healthy = false
+		}
+	}
+
+	resp := output.NewResponseBuilder("config doctor").
+		WithSuccess(healthy).
+		WithData(output.DoctorData{Checks: checks})
+	writer.Write(resp.Build())
+
+	if !healthy {
+		return fmt.Errorf("configuration has problems")
+	}
+	return nil
+}
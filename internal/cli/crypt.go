@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/okto-digital/regis3/internal/crypt"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var cryptCmd = &cobra.Command{
+	Use:   "crypt",
+	Short: "Encrypt item bodies at rest",
+	Long: `Keeps the bodies of registry items encrypted on disk, for registries on
+shared drives or in repositories others can read. Frontmatter stays in
+plain text, so the registry can still be listed, searched by metadata and
+validated; bodies are decrypted transparently when items are built,
+shown or installed.
+
+The key is read from the environment:
+  REGIS3_KEY          the base64-encoded key
+  REGIS3_KEY_FILE     a file holding the key
+  REGIS3_KEY_COMMAND  a command printing the key, e.g. a password manager
+
+The registry records only the key's ID, in ` + registry.CryptFile + `.
+
+Examples:
+  regis3 crypt init      # Encrypt the registry, generating a key if none is set
+  regis3 crypt status    # Show which items are encrypted
+  regis3 crypt rotate    # Re-encrypt with a new key`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCryptStatus()
+	},
+}
+
+var cryptInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Encrypt the registry's item bodies",
+	Long: `Encrypts the bodies of all registry items and their variant files with the
+configured key, or with a newly generated key, which is printed once: store
+it safely, without it the registry can't be read.
+
+Running init again encrypts items added since in plain text.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCryptInit()
+	},
+}
+
+var cryptRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Re-encrypt the registry with a new key",
+	Long: `Decrypts the registry's item bodies with the configured key and encrypts
+them with a newly generated key, which is printed once. Update REGIS3_KEY,
+or wherever the key is kept, afterwards: the old key no longer reads the
+registry.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCryptRotate()
+	},
+}
+
+var cryptStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which items are encrypted",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCryptStatus()
+	},
+}
+
+func init() {
+	cryptCmd.AddCommand(cryptInitCmd)
+	cryptCmd.AddCommand(cryptRotateCmd)
+	cryptCmd.AddCommand(cryptStatusCmd)
+	rootCmd.AddCommand(cryptCmd)
+}
+
+func runCryptInit() error {
+	if err := guardRegistry("crypt init"); err != nil {
+		return err
+	}
+	registryPath := getRegistryPath()
+	existing, err := registry.LoadCryptConfig(registryPath)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	key, err := crypt.DefaultKey()
+	generated := errors.Is(err, crypt.ErrNoKey) && existing == nil
+	if generated {
+		key, err = crypt.GenerateKey()
+	}
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	if existing != nil && existing.KeyID != key.ID() {
+		writer.Error(i18n.Sprintf("Registry is encrypted with key %s, but the configured key is %s", existing.KeyID, key.ID()))
+		return &exitError{code: 1, message: "wrong key"}
+	}
+
+	changed, err := registry.EncryptItems(registryPath, key)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to encrypt registry: %s", err.Error()))
+		return err
+	}
+	if err := registry.SaveCryptConfig(registryPath, &registry.CryptConfig{KeyID: key.ID()}); err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	data := output.CryptData{KeyID: key.ID(), Changed: nonNil(changed)}
+	resp := output.NewResponseBuilder("crypt init").WithSuccess(true)
+	if generated {
+		data.Key = key.String()
+		resp.WithInfo("Generated key %s: %s", key.ID(), key.String())
+		resp.WithWarning("Store the key safely and set %s to it; without it the registry can't be read", crypt.KeyEnv)
+	}
+	if len(changed) > 0 {
+		resp.WithInfo("Encrypted %d items with key %s", len(changed), key.ID())
+	} else {
+		resp.WithInfo("All items are encrypted with key %s", key.ID())
+	}
+	writer.Write(resp.WithData(data).Build())
+	return nil
+}
+
+func runCryptRotate() error {
+	if err := guardRegistry("crypt rotate"); err != nil {
+		return err
+	}
+	registryPath := getRegistryPath()
+	existing, err := registry.LoadCryptConfig(registryPath)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	if existing == nil {
+		writer.Error(i18n.Sprintf("Registry is not encrypted (run 'regis3 crypt init')"))
+		return &exitError{code: 1, message: "not encrypted"}
+	}
+
+	old, err := crypt.DefaultKey()
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	if old.ID() != existing.KeyID {
+		writer.Error(i18n.Sprintf("Registry is encrypted with key %s, but the configured key is %s", existing.KeyID, old.ID()))
+		return &exitError{code: 1, message: "wrong key"}
+	}
+
+	key, err := crypt.GenerateKey()
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	changed, err := registry.RotateItems(registryPath, old, key)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to encrypt registry: %s", err.Error()))
+		return err
+	}
+	if err := registry.SaveCryptConfig(registryPath, &registry.CryptConfig{KeyID: key.ID()}); err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	resp := output.NewResponseBuilder("crypt rotate").
+		WithSuccess(true).
+		WithData(output.CryptData{KeyID: key.ID(), Key: key.String(), Changed: nonNil(changed)}).
+		WithInfo("Re-encrypted %d items from key %s to %s", len(changed), old.ID(), key.ID()).
+		WithInfo("New key %s: %s", key.ID(), key.String()).
+		WithWarning("Set %s to the new key; the old key no longer reads the registry", crypt.KeyEnv)
+	writer.Write(resp.Build())
+	return nil
+}
+
+func runCryptStatus() error {
+	registryPath := getRegistryPath()
+	existing, err := registry.LoadCryptConfig(registryPath)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	keyID := ""
+	if existing != nil {
+		keyID = existing.KeyID
+	}
+
+	status, err := registry.ItemCryptStatus(registryPath, keyID)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to scan registry: %s", err.Error()))
+		return err
+	}
+
+	resp := output.NewResponseBuilder("crypt status").WithSuccess(true).WithData(output.CryptData{
+		KeyID:     keyID,
+		Changed:   []string{},
+		Encrypted: len(status.Encrypted),
+		Plain:     status.Plain,
+		OtherKey:  status.OtherKey,
+	})
+	if existing == nil {
+		resp.WithInfo("Registry is not encrypted (run 'regis3 crypt init')")
+	} else {
+		resp.WithInfo("Registry is encrypted with key %s: %d encrypted, %d plain", keyID, len(status.Encrypted), len(status.Plain))
+		for _, path := range status.Plain {
+			resp.WithWarning("%s is not encrypted (run 'regis3 crypt init')", path)
+		}
+		for _, path := range status.OtherKey {
+			resp.WithError(path, i18n.Sprintf("encrypted with another key"))
+		}
+		switch key, err := crypt.DefaultKey(); {
+		case err != nil:
+			resp.WithWarning("%s", err.Error())
+		case key.ID() != keyID:
+			resp.WithWarning("The configured key is %s, not the registry's", key.ID())
+		}
+	}
+	writer.Write(resp.Build())
+	return nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/okto-digital/regis3/internal/ctl"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// ctlCmd groups the commands controlling a running session
+var ctlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Run actions in the open item picker",
+	Long: `Sends actions to the item picker open in the current project
+('regis3 project add' without arguments), over a local control socket.
+
+The picker performs the action and shows the outcome, and the result is
+printed here like the corresponding command's output; -f json and -f quiet
+suit scripts and editor plugins. While the picker is open, install items
+with 'regis3 ctl install' rather than 'regis3 project add', so only the
+picker writes the project's tracker.
+
+Setup scripts of items installed this way are skipped, and rewrites of the
+merge file are not confirmed.
+
+Examples:
+  regis3 ctl build
+  regis3 ctl install skill:git-conventions
+  regis3 ctl install stack:web -f json`,
+}
+
+var ctlBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Rebuild the manifest in the open picker",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCtl(ctl.Request{Action: "build"}, &output.BuildData{})
+	},
+}
+
+var ctlInstallCmd = &cobra.Command{
+	Use:   "install <type:name> [type:name...]",
+	Short: "Install items from the open picker",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCtl(ctl.Request{Action: "install", Args: args}, &output.InstallData{})
+	},
+}
+
+func init() {
+	ctlCmd.AddCommand(ctlBuildCmd)
+	ctlCmd.AddCommand(ctlInstallCmd)
+	rootCmd.AddCommand(ctlCmd)
+}
+
+// runCtl sends req to the picker open in the current project and writes its
+// reply, decoding the reply's data into data.
+func runCtl(req ctl.Request, data any) error {
+	path, err := ctl.SocketPath(".")
+	if err != nil {
+		return err
+	}
+
+	resp := output.Response{Data: data}
+	if err := ctl.Send(path, req, &resp); err != nil {
+		if errors.Is(err, ctl.ErrNoSession) {
+			writer.Error("No item picker is open in this project (open one with 'regis3 project add')")
+			return &exitError{code: 1, message: "no session"}
+		}
+		writer.Error(i18n.Sprintf("Control request failed: %s", err.Error()))
+		return err
+	}
+	if resp.Error != nil {
+		// Data is only set on success
+		resp.Data = nil
+	}
+	writer.Write(&resp)
+
+	if !resp.Success {
+		return &exitError{code: 1, message: "ctl " + req.Action + " failed"}
+	}
+	return nil
+}
+
+// listenControl serves control requests for the current project.
+func listenControl(handler ctl.Handler) (*ctl.Server, error) {
+	path, err := ctl.SocketPath(".")
+	if err != nil {
+		return nil, err
+	}
+	return ctl.Listen(path, handler)
+}
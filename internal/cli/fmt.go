@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [paths...]",
+	Short: "Normalize item frontmatter",
+	Long: `Rewrites the frontmatter of registry items in a consistent style:
+regis3 keys in canonical order (type, name, desc, ...), strings quoted only
+where needed, lists in block style, 2-space indentation and a single trailing
+newline. Comments are kept, and formatting a formatted file changes nothing.
+
+Without paths every item in the registry is formatted. Paths may be relative
+to the registry root or to the current directory. TOML frontmatter is left
+as is.
+
+--check only lists the files that need formatting and fails if there are
+any, for use in CI.
+
+Examples:
+  regis3 fmt
+  regis3 fmt skills/testing.md
+  regis3 fmt --check`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFmt(args)
+	},
+}
+
+var fmtCheck bool
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List files that need formatting without changing them")
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFmt(paths []string) error {
+	if !fmtCheck {
+		if err := guardRegistry("fmt"); err != nil {
+			return err
+		}
+	}
+	registryPath := getRegistryPath()
+	debugf("Formatting registry: %s", registryPath)
+
+	opts := buildOptions()
+	scanner := registry.NewScanner(registryPath)
+	scanner.Filter = opts.Filter
+	scanner.StagingDir = opts.StagingDir
+	scanner.Dialect = opts.Dialect
+	scan, err := scanner.Scan()
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to scan registry: %s", err.Error()))
+		return err
+	}
+
+	selected := make(map[string]bool)
+	for _, p := range registryRelativePaths(paths) {
+		selected[filepath.Clean(p)] = true
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	for _, item := range scan.Items {
+		if seen[item.Source] || (len(selected) > 0 && !selected[filepath.Clean(item.Source)]) {
+			continue
+		}
+		seen[item.Source] = true
+		sources = append(sources, item.Source)
+	}
+	sort.Strings(sources)
+
+	resp := output.NewResponseBuilder("fmt")
+	changed := []string{}
+	failed := false
+	for _, source := range sources {
+		path := filepath.Join(registryPath, source)
+		content, err := os.ReadFile(path)
+		if err == nil {
+			var formatted []byte
+			formatted, err = opts.Dialect.Format(content)
+			if err == nil && !bytes.Equal(formatted, content) {
+				changed = append(changed, source)
+				if !fmtCheck {
+					err = writeFormatted(path, formatted)
+				}
+			}
+		}
+		if err != nil {
+			resp.WithError(source, err.Error())
+			failed = true
+		}
+	}
+
+	switch {
+	case fmtCheck && len(changed) > 0:
+		resp.WithError("", i18n.Sprintf("%d files need formatting (run 'regis3 fmt')", len(changed)))
+		failed = true
+	case fmtCheck:
+		resp.WithInfo("All %d files are formatted", len(sources))
+	case len(changed) > 0:
+		resp.WithInfo("Formatted %d of %d files", len(changed), len(sources))
+	default:
+		resp.WithInfo("All %d files are formatted", len(sources))
+	}
+
+	resp.WithSuccess(!failed).WithData(changed)
+	writer.Write(resp.Build())
+
+	if failed {
+		return errFmtFailed
+	}
+	return nil
+}
+
+var errFmtFailed = &exitError{code: 1, message: "files are not formatted"}
+
+// writeFormatted replaces the file at path, keeping its permissions.
+func writeFormatted(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, info.Mode().Perm())
+}
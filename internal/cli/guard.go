@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+)
+
+// guardWait is how long an interactive command waits for another regis3
+// process to finish changing the project.
+const guardWait = 2 * time.Minute
+
+// spinnerFrames are the frames of the spinner shown while waiting.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// guardProject keeps other regis3 processes from changing the project until
+// release is called. When another process is changing it, interactive
+// sessions wait for it with a spinner and others fail right away. Errors
+// are reported through the writer.
+func guardProject(command string) (release func(), err error) {
+	guard, err := installer.AcquireGuard(".", command)
+	var busy *installer.BusyError
+	if errors.As(err, &busy) && isInteractive() {
+		guard, err = waitForGuard(command, busy)
+	}
+	if errors.As(err, &busy) {
+		writer.Error(i18n.Sprintf("Another regis3 process (PID %d, %s) is changing this project; try again when it has finished", busy.PID, busy.Command))
+		return nil, &exitError{code: 1, message: "project busy"}
+	}
+	if err != nil {
+		writer.Error(err.Error())
+		return nil, err
+	}
+	return func() {
+		if err := guard.Release(); err != nil {
+			debugf("%v", err)
+		}
+	}, nil
+}
+
+// waitForGuard retries acquiring the guard until the other process is done
+// or guardWait has passed, showing a spinner on stderr meanwhile.
+func waitForGuard(command string, busy *installer.BusyError) (*installer.Guard, error) {
+	message := i18n.Sprintf("Waiting for regis3 (PID %d, %s) to finish...", busy.PID, busy.Command)
+	defer fmt.Fprint(os.Stderr, "\r\033[K")
+
+	deadline := time.Now().Add(guardWait)
+	for frame := 0; ; frame++ {
+		fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[frame%len(spinnerFrames)], message)
+		time.Sleep(100 * time.Millisecond)
+
+		guard, err := installer.AcquireGuard(".", command)
+		if !errors.As(err, &busy) || time.Now().After(deadline) {
+			return guard, err
+		}
+	}
+}
+
+// guardRegistry fails a command that would modify the registry when the
+// registry is read-only (read_only in the config). Errors are reported
+// through the writer.
+func guardRegistry(command string) error {
+	if cfg == nil || !cfg.ReadOnly {
+		return nil
+	}
+	writer.Error(i18n.Sprintf("Registry %s is read-only; %s would modify it (set read_only: false to allow changes)", getRegistryPath(), command))
+	return &exitError{code: 1, message: "registry is read-only"}
+}
@@ -2,44 +2,79 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/charmbracelet/huh"
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/importer"
 	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
-var importList bool
+var (
+	importList           bool
+	importCheckInjection bool
+	importMerge          string
+	importMergeInto      string
+	importMergeAs        string
+)
 
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Process files in import staging directory",
-	Long: `Processes files in the import/ staging directory.
+	Long: `Processes files in the import staging directory (import/ in the
+registry unless import.staging_dir is configured).
 
 Files that now have valid regis3 frontmatter are moved to their proper
 location in the registry. Files still without frontmatter remain in staging.
 
 Use --list to see files pending in the staging directory.
 
+Use --check-injection to scan staged files for common prompt-injection
+patterns (hidden HTML comments, "ignore previous instructions", base64
+blobs, invisible characters). Flagged files stay in staging for manual
+review; run import again without the flag once they've been checked.
+
+Use --merge to add a staged file to an existing item instead of creating a
+new one: as a new section at the end of the item (--as section, the
+default; headings move down one level) or as an additional file next to
+it (--as file). The staged file's frontmatter is dropped and the file is
+removed from staging. Without --into, the item is chosen interactively.
+
 Examples:
-  regis3 import          # Process staging directory
-  regis3 import --list   # List pending files`,
+  regis3 import                     # Process staging directory
+  regis3 import --list              # List pending files
+  regis3 import --check-injection   # Hold back suspicious files
+  regis3 import --merge notes.md --into skill:testing
+  regis3 import --merge api-tips.md --into skill:api --as file`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if importList {
 			return runImportList()
 		}
+		if err := guardRegistry("import"); err != nil {
+			return err
+		}
+		if importMerge != "" {
+			return runImportMerge()
+		}
 		return runImport()
 	},
 }
 
 func init() {
 	importCmd.Flags().BoolVar(&importList, "list", false, "List pending files")
+	importCmd.Flags().BoolVar(&importCheckInjection, "check-injection", false, "Hold back staged files matching prompt-injection patterns")
+	importCmd.Flags().StringVar(&importMerge, "merge", "", "Merge this staged file into an existing item")
+	importCmd.Flags().StringVar(&importMergeInto, "into", "", "Item to merge into (type:name)")
+	importCmd.Flags().StringVar(&importMergeAs, "as", string(importer.MergeSection), "Merge as a new section or an additional file: section, file")
 	rootCmd.AddCommand(importCmd)
 }
 
 func runImportList() error {
 	debugf("Listing pending imports from: %s", getRegistryPath())
 
-	imp := importer.NewImporter(getRegistryPath())
+	imp := newImporter()
 
 	if !imp.StagingExists() {
 		resp := output.NewResponseBuilder("import").
@@ -52,7 +87,7 @@ func runImportList() error {
 
 	pending, err := imp.ListPending()
 	if err != nil {
-		writer.Error(fmt.Sprintf("Failed to list pending: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Failed to list pending: %s", err.Error()))
 		return err
 	}
 
@@ -62,6 +97,7 @@ func runImportList() error {
 			Path:          p.Path,
 			SuggestedType: p.SuggestedType,
 			SuggestedName: p.SuggestedName,
+			SuggestedDesc: p.SuggestedDesc,
 			Confidence:    p.Confidence,
 		}
 	}
@@ -71,6 +107,7 @@ func runImportList() error {
 		WithData(output.ImportData{
 			Pending: pendingItems,
 		})
+	addImporterNotices(resp)
 
 	if len(pending) == 0 {
 		resp.WithInfo("No files pending")
@@ -85,7 +122,8 @@ func runImportList() error {
 func runImport() error {
 	debugf("Processing import staging from: %s", getRegistryPath())
 
-	imp := importer.NewImporter(getRegistryPath())
+	imp := newImporter()
+	imp.CheckInjection = importCheckInjection
 
 	if !imp.StagingExists() {
 		resp := output.NewResponseBuilder("import").
@@ -98,7 +136,7 @@ func runImport() error {
 
 	result, err := imp.ProcessStaging()
 	if err != nil {
-		writer.Error(fmt.Sprintf("Import failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Import failed: %s", err.Error()))
 		return err
 	}
 
@@ -123,6 +161,15 @@ func runImport() error {
 		}
 	}
 
+	var flagged []output.FlaggedItem
+	for _, f := range result.Flagged {
+		item := output.FlaggedItem{Path: f.Path}
+		for _, finding := range f.Findings {
+			item.Findings = append(item.Findings, finding.String())
+		}
+		flagged = append(flagged, item)
+	}
+
 	var errors []string
 	for _, e := range result.Errors {
 		errors = append(errors, e.Error())
@@ -133,8 +180,10 @@ func runImport() error {
 		WithData(output.ImportData{
 			Processed: processed,
 			Pending:   pending,
+			Flagged:   flagged,
 			Errors:    errors,
 		})
+	addImporterNotices(resp)
 
 	if len(processed) > 0 {
 		resp.WithInfo("Moved %d files to registry", len(processed))
@@ -142,6 +191,9 @@ func runImport() error {
 	if len(pending) > 0 {
 		resp.WithInfo("%d files still pending (need regis3 frontmatter)", len(pending))
 	}
+	if len(flagged) > 0 {
+		resp.WithWarning("%d files kept in staging for review (possible prompt injection)", len(flagged))
+	}
 
 	for _, e := range errors {
 		resp.WithError("import", e)
@@ -156,3 +208,177 @@ func runImport() error {
 }
 
 var errImportFailed = &exitError{code: 1, message: "import had errors"}
+
+// describeFailures counts the files the configured describe command failed
+// for; describeErr is the last failure. migratedStaging are the pending files
+// moved to a newly configured staging directory, and migrateErr is why the
+// move stopped, if it did.
+var (
+	describeFailures int
+	describeErr      error
+	migratedStaging  []string
+	migrateErr       error
+)
+
+// newImporter creates an importer for the registry with the configured
+// staging directory, describing files with the configured describe command
+// if any. Files pending in the default staging directory are moved to a
+// configured one.
+func newImporter() *importer.Importer {
+	imp := importer.NewImporter(getRegistryPath())
+	if cfg == nil {
+		return imp
+	}
+
+	if staging := cfg.StagingPath(); staging != imp.StagingDir {
+		legacy := imp.StagingDir
+		imp.StagingDir = staging
+		if cfg.ReadOnly {
+			return imp
+		}
+		migratedStaging, migrateErr = imp.MigrateStaging(legacy)
+		if migrateErr != nil {
+			debugf("Moving pending files to %s failed: %s", staging, migrateErr)
+		}
+	}
+
+	dialect := cfg.Dialect()
+	imp.Dialect = dialect
+	imp.Scanner.Dialect = dialect
+	imp.Classifier.Dialect = dialect
+
+	if cfg.Import.DescribeCommand != "" {
+		describer := importer.NewCommandDescriber(cfg.Import.DescribeCommand)
+		describer.OnError = func(path string, err error) {
+			debugf("Describe command failed for %s: %s", path, err)
+			describeFailures++
+			describeErr = err
+		}
+		imp.Classifier.Describer = describer
+	}
+	return imp
+}
+
+// stagingDisplay returns the staging directory for messages: relative to the
+// registry when inside it.
+func stagingDisplay() string {
+	staging := stagingPath()
+	if rel := registry.StagingRel(getRegistryPath(), staging); rel != "" {
+		return rel + "/"
+	}
+	return staging
+}
+
+// stagingInRegistry reports whether the staging directory is inside the
+// registry, so that staging files modifies the registry.
+func stagingInRegistry() bool {
+	return registry.StagingRel(getRegistryPath(), stagingPath()) != ""
+}
+
+// stagingPath returns the configured staging directory.
+func stagingPath() string {
+	if cfg != nil {
+		return cfg.StagingPath()
+	}
+	return registry.DefaultStagingDir
+}
+
+// addImporterNotices reports pending files moved to the configured staging
+// directory and describe command failures.
+func addImporterNotices(resp *output.ResponseBuilder) {
+	if len(migratedStaging) > 0 {
+		resp.WithInfo("Moved %d pending files to the staging directory %s", len(migratedStaging), stagingDisplay())
+	}
+	if migrateErr != nil {
+		resp.WithWarning("Could not move all pending files to %s: %s", stagingDisplay(), migrateErr.Error())
+	}
+	if describeFailures > 0 {
+		resp.WithWarning("Describe command failed for %d files, descriptions were taken from the content: %s", describeFailures, describeErr.Error())
+	}
+}
+
+func runImportMerge() error {
+	debugf("Merging %s from import staging into %s", importMerge, importMergeInto)
+
+	mode := importer.MergeMode(importMergeAs)
+	if mode != importer.MergeSection && mode != importer.MergeFile {
+		return fmt.Errorf("--as must be %s or %s", importer.MergeSection, importer.MergeFile)
+	}
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	ref := importMergeInto
+	if ref == "" {
+		if !isInteractive() {
+			return fmt.Errorf("--into is required when not running interactively")
+		}
+		if ref, err = pickMergeTarget(manifest, importMerge); err != nil {
+			return err
+		}
+	}
+	ids, notices, err := resolveRefs(manifest, []string{ref})
+	if err != nil {
+		writer.Error(err.Error())
+		return fmt.Errorf("item not found")
+	}
+	item, _ := manifest.GetItem(ids[0])
+
+	imp := newImporter()
+	merged, err := imp.MergeStaged(importMerge, item, mode)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Merge failed: %s", err.Error()))
+		return err
+	}
+
+	resp := output.NewResponseBuilder("import").
+		WithSuccess(true).
+		WithData(output.ImportData{
+			Merged: []output.MergedItem{{
+				Path:     importMerge,
+				Into:     merged.Ref,
+				Mode:     string(merged.Mode),
+				DestPath: merged.DestPath,
+			}},
+		}).
+		WithInfo("Run 'regis3 build' to update the manifest")
+	addImporterNotices(resp)
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
+
+// pickMergeTarget asks which item a staged file is merged into.
+func pickMergeTarget(manifest *registry.Manifest, staged string) (string, error) {
+	ids := make([]string, 0, len(manifest.Items))
+	for id := range manifest.Items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var options []huh.Option[string]
+	for _, id := range ids {
+		options = append(options, huh.NewOption(id+"  "+manifest.Items[id].Desc, id))
+	}
+
+	var chosen string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("Merge %s into", staged)).
+				Description("Type / to filter").
+				Options(options...).
+				Value(&chosen),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return chosen, nil
+}
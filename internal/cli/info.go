@@ -2,21 +2,42 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
+var (
+	infoOpen  bool
+	infoFiles bool
+)
+
 var infoCmd = &cobra.Command{
 	Use:   "info <type:name>",
 	Short: "Show item details",
 	Long: `Shows detailed information about a registry item.
 
+The type prefix may be omitted when only one item has the name.
+
+--open opens the item's source file in $VISUAL or $EDITOR, or with the
+system's default application when neither is set. --files lists the
+absolute paths of the item's source, additional files and setup script,
+one per line with --format quiet.
+
 Examples:
   regis3 info skill:git-conventions
-  regis3 info subagent:code-reviewer`,
+  regis3 info git-conventions
+  regis3 info subagent:code-reviewer
+  regis3 info skill:git-conventions --open
+  regis3 info skill:git-conventions --files -f quiet`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return fmt.Errorf("missing item reference\n\nUsage: regis3 info <type:name>\n\nExample: regis3 info skill:git-conventions")
@@ -29,58 +50,147 @@ Examples:
 }
 
 func init() {
+	infoCmd.Flags().BoolVar(&infoOpen, "open", false, "Open the item's source file in your editor")
+	infoCmd.Flags().BoolVar(&infoFiles, "files", false, "List the absolute paths of the item's files")
 	rootCmd.AddCommand(infoCmd)
 }
 
 func runInfo(ref string) error {
-	// Parse type:name reference
-	parts := strings.SplitN(ref, ":", 2)
-	if len(parts) != 2 {
-		writer.Error("Invalid reference format. Use 'type:name' (e.g., skill:git-conventions)")
-		return fmt.Errorf("invalid reference format")
-	}
-
-	itemType, itemName := parts[0], parts[1]
-	debugf("Looking up: %s:%s", itemType, itemName)
+	debugf("Looking up: %s", ref)
 
-	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	manifest, err := loadManifest()
 	if err != nil {
-		// Try building first
-		_, buildErr := registry.BuildRegistry(getRegistryPath())
-		if buildErr != nil {
-			writer.Error(fmt.Sprintf("Failed to load registry: %s", err.Error()))
-			return err
-		}
-		manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
-		if err != nil {
-			writer.Error(fmt.Sprintf("Failed to load manifest: %s", err.Error()))
-			return err
-		}
+		return err
 	}
 
 	// Find the item
-	fullName := fmt.Sprintf("%s:%s", itemType, itemName)
-	item, ok := manifest.Items[fullName]
-	if !ok {
-		writer.Error(fmt.Sprintf("Item '%s' not found in registry", fullName))
+	ids, notices, err := resolveRefs(manifest, []string{ref})
+	if err != nil {
+		writer.Error(err.Error())
 		return fmt.Errorf("item not found")
 	}
+	item, _ := manifest.GetItem(ids[0])
+
+	if infoOpen || infoFiles {
+		return runInfoFiles(item, notices)
+	}
 
 	// Build info data
 	infoData := output.InfoData{
 		Type:         item.Type,
 		Name:         item.Name,
 		Desc:         item.Desc,
+		Aliases:      item.Aliases,
 		Path:         item.Source,
 		Tags:         item.Tags,
-		Dependencies: item.Deps,
+		Dependencies: manifest.ItemDeps(item),
+		DepsQuery:    item.DepsQuery,
+		Provides:     item.Provides,
+		Alternatives: item.OneOf,
 		Files:        item.Files,
 	}
 
 	resp := output.NewResponseBuilder("info").
 		WithSuccess(true).
 		WithData(infoData)
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
 
 	writer.Write(resp.Build())
 	return nil
 }
+
+// runInfoFiles lists the item's files, opening its source with --open.
+func runInfoFiles(item *registry.Item, notices []string) error {
+	paths, err := itemFiles(getRegistryPath(), item)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	resp := output.NewResponseBuilder("info").WithSuccess(true)
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+	if infoFiles {
+		resp.WithData(paths)
+	}
+
+	if infoOpen {
+		if err := openFile(paths[0]); err != nil {
+			writer.Error(i18n.Sprintf("Failed to open %s: %s", paths[0], err.Error()))
+			return err
+		}
+		if !infoFiles {
+			resp.WithInfo("Opened %s", paths[0])
+		}
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
+
+// itemFiles returns the absolute paths of an item's files: its source, then
+// its additional files and setup script.
+func itemFiles(registryPath string, item *registry.Item) ([]string, error) {
+	root, err := filepath.Abs(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	source, err := pathutil.Join(root, item.Source)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{source}
+	for _, file := range item.Files {
+		path, err := pathutil.Join(root, item.SourceDir, file)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	if item.Setup != "" {
+		path, err := pathutil.Join(root, item.SourceDir, item.Setup)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// openFile opens path in $VISUAL or $EDITOR, falling back to the system's
+// default application.
+func openFile(path string) error {
+	cmd, editor := editorCommand(path)
+	if !editor {
+		return cmd.Start()
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr // keep stdout clean for JSON output
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand returns the command opening path in $VISUAL or $EDITOR and
+// true, or with the system's default application and false when neither is
+// set.
+func editorCommand(path string) (*exec.Cmd, bool) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if args := strings.Fields(editor); len(args) > 0 {
+		return exec.Command(args[0], append(args[1:], path)...), true
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", path), false
+	case "windows":
+		return exec.Command("cmd", "/c", "start", "", path), false
+	default:
+		return exec.Command("xdg-open", path), false
+	}
+}
@@ -1,31 +1,60 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/huh"
 	"github.com/okto-digital/regis3/internal/config"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
 var (
 	initNonInteractive bool
 	initRegistryPath   string
+	initCloneURL       string
+	initTarget         string
 )
 
+// Registry starters offered by the setup wizard.
+const (
+	starterEmpty   = "empty"
+	starterFolders = "folders"
+	starterClone   = "clone"
+)
+
+// setupOptions are the choices made during setup.
+type setupOptions struct {
+	RegistryPath  string
+	Starter       string
+	CloneURL      string
+	DefaultTarget string
+}
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize regis3 configuration",
 	Long: `Sets up regis3 for first use by creating configuration and registry directories.
 
-In interactive mode, prompts for:
+In interactive mode, a setup wizard asks for:
 - Registry location (default: ~/.regis3/registry)
-- Whether to initialize as git repo
+- How to start the registry: empty, with a default folder structure, or
+  cloned from a git repository (e.g. a team or starter registry)
+- The default target for projects
 
-Use --yes for non-interactive mode with defaults.`,
+Running any other command before setup offers the same wizard.
+
+Use --yes for non-interactive mode with defaults.
+
+Examples:
+  regis3 init
+  regis3 init --yes --clone https://github.com/acme/regis3-registry.git`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInit()
 	},
@@ -34,73 +63,153 @@ Use --yes for non-interactive mode with defaults.`,
 func init() {
 	initCmd.Flags().BoolVarP(&initNonInteractive, "yes", "y", false, "Accept defaults without prompting")
 	initCmd.Flags().StringVar(&initRegistryPath, "registry", "", "Registry path (default: ~/.regis3/registry)")
+	initCmd.Flags().StringVar(&initCloneURL, "clone", "", "Clone the registry from this git repository")
+	initCmd.Flags().StringVar(&initTarget, "target", "auto", "Default target: "+strings.Join(config.KnownTargets, ", "))
 	rootCmd.AddCommand(initCmd)
 }
 
 func runInit() error {
 	paths, err := config.NewPaths()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Error: %v", err))
 		return err
 	}
 
 	// Check if already initialized
 	if _, err := os.Stat(paths.ConfigFile); err == nil {
-		fmt.Println("regis3 is already initialized.")
-		fmt.Printf("Config: %s\n", paths.ConfigFile)
-		fmt.Printf("Registry: %s\n", paths.RegistryDir)
+		fmt.Println(i18n.T("regis3 is already initialized."))
+		fmt.Println(i18n.Sprintf("Config: %s", paths.ConfigFile))
+		fmt.Println(i18n.Sprintf("Registry: %s", paths.RegistryDir))
 		return nil
 	}
 
-	registryPath := initRegistryPath
-	if registryPath == "" {
-		registryPath = paths.RegistryDir
+	opts := setupOptions{
+		RegistryPath:  initRegistryPath,
+		Starter:       starterEmpty,
+		CloneURL:      initCloneURL,
+		DefaultTarget: initTarget,
+	}
+	if opts.RegistryPath == "" {
+		opts.RegistryPath = paths.RegistryDir
+	}
+	if opts.CloneURL != "" {
+		opts.Starter = starterClone
 	}
-
-	createStructure := false
 
 	// Interactive mode
 	if !initNonInteractive {
-		reader := bufio.NewReader(os.Stdin)
-
-		fmt.Println("Welcome to regis3!")
+		fmt.Println(i18n.T("Welcome to regis3!"))
 		fmt.Println()
+		if err := runSetupWizard(&opts); err != nil {
+			return err
+		}
+	}
 
-		// Ask for registry path
-		fmt.Printf("Registry location [%s]: ", registryPath)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		if input != "" {
-			registryPath = expandPath(input)
+	return setup(paths, opts)
+}
+
+// runSetupWizard asks for the setup choices, starting from opts.
+func runSetupWizard(opts *setupOptions) error {
+	var targetOptions []huh.Option[string]
+	for _, target := range config.KnownTargets {
+		label := target
+		if target == "auto" {
+			label = i18n.T("auto (detect from each project)")
 		}
+		targetOptions = append(targetOptions, huh.NewOption(label, target))
+	}
 
-		// Explain folder-structure agnostic nature
-		fmt.Println()
-		fmt.Println("Note: regis3 is folder-structure agnostic. You can organize your")
-		fmt.Println("registry however you like - the structure does not affect functionality.")
-		fmt.Println()
-		fmt.Print("Create default folder structure (skills/, agents/, etc.)? [y/N]: ")
-		input, _ = reader.ReadString('\n')
-		createStructure = strings.ToLower(strings.TrimSpace(input)) == "y"
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(i18n.T("Registry location")).
+				Description(i18n.T("Where your skills, agents and other items live")).
+				Value(&opts.RegistryPath).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errors.New(i18n.T("enter a directory"))
+					}
+					return nil
+				}),
+			huh.NewSelect[string]().
+				Title(i18n.T("How do you want to start?")).
+				Description(i18n.T("regis3 is folder-structure agnostic: organize the registry however you like")).
+				Options(
+					huh.NewOption(i18n.T("Empty registry"), starterEmpty),
+					huh.NewOption(i18n.T("Default folders (skills/, agents/, commands/, ...)"), starterFolders),
+					huh.NewOption(i18n.T("Clone an existing registry from git"), starterClone),
+				).
+				Value(&opts.Starter),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title(i18n.T("Git repository to clone")).
+				Placeholder("https://github.com/acme/regis3-registry.git").
+				Value(&opts.CloneURL).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errors.New(i18n.T("enter a repository URL"))
+					}
+					return nil
+				}),
+		).WithHideFunc(func() bool { return opts.Starter != starterClone }),
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(i18n.T("Default target")).
+				Description(i18n.T("The tool items are installed for in projects")).
+				Options(targetOptions...).
+				Value(&opts.DefaultTarget),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return err
 	}
+	opts.RegistryPath = expandPath(strings.TrimSpace(opts.RegistryPath))
+	opts.CloneURL = strings.TrimSpace(opts.CloneURL)
+	return nil
+}
 
-	// Create directories
-	fmt.Printf("Creating registry at: %s\n", registryPath)
+// setup creates the registry and writes the config.
+func setup(paths *config.Paths, opts setupOptions) error {
+	registryPath := opts.RegistryPath
 
-	if err := os.MkdirAll(registryPath, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating registry directory: %v\n", err)
+	// Validate before creating anything
+	newCfg := &config.Config{
+		RegistryPath:  registryPath,
+		DefaultTarget: opts.DefaultTarget,
+		OutputFormat:  "pretty",
+		Icons:         "auto",
+		Debug:         false,
+	}
+	if err := newCfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Error: %v", err))
 		return err
 	}
 
+	if opts.Starter == starterClone {
+		fmt.Println(i18n.Sprintf("Cloning %s into %s", opts.CloneURL, registryPath))
+		if err := cloneRegistry(opts.CloneURL, registryPath); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Error cloning registry: %v", err))
+			return err
+		}
+	} else {
+		fmt.Println(i18n.Sprintf("Creating registry at: %s", registryPath))
+		if err := os.MkdirAll(registryPath, 0755); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Error creating registry directory: %v", err))
+			return err
+		}
+	}
+
 	// Always create .build directory for manifest
 	buildDir := filepath.Join(registryPath, ".build")
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating .build: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Error creating .build: %v", err))
 		return err
 	}
 
 	// Create default folder structure only if user requested
-	if createStructure {
+	if opts.Starter == starterFolders {
 		subdirs := []string{
 			"skills",
 			"agents",
@@ -113,49 +222,106 @@ func runInit() error {
 		for _, subdir := range subdirs {
 			dir := filepath.Join(registryPath, subdir)
 			if err := os.MkdirAll(dir, 0755); err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", subdir, err)
+				fmt.Fprintln(os.Stderr, i18n.Sprintf("Error creating %s: %v", subdir, err))
 				return err
 			}
 		}
-		fmt.Println("Created default folder structure.")
-	}
-
-	// Create config
-	newCfg := &config.Config{
-		RegistryPath:  registryPath,
-		DefaultTarget: "claude",
-		OutputFormat:  "pretty",
-		Debug:         false,
+		fmt.Println(i18n.T("Created default folder structure."))
 	}
 
 	// Ensure config directory exists
 	if err := os.MkdirAll(paths.ConfigDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating config directory: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Error creating config directory: %v", err))
 		return err
 	}
 
 	if err := config.Save(newCfg, paths.ConfigFile); err != nil {
-		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Error saving config: %v", err))
 		return err
 	}
 
+	// A cloned registry is ready to use
+	itemCount := 0
+	if opts.Starter == starterClone {
+		result, err := registry.BuildRegistry(registryPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Warning: could not build the manifest: %v", err))
+		} else {
+			itemCount = len(result.Manifest.Items)
+		}
+	}
+
 	fmt.Println()
-	fmt.Println("regis3 initialized successfully!")
-	fmt.Printf("  Config: %s\n", paths.ConfigFile)
-	fmt.Printf("  Registry: %s\n", registryPath)
-	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  1. Add markdown files with regis3 frontmatter to the registry")
-	fmt.Println("  2. Run 'regis3 build' to build the manifest")
-	fmt.Println("  3. Run 'regis3 list' to see available items")
-	fmt.Println("  4. Run 'regis3 project add <type:name>' to install items")
+	fmt.Println(i18n.T("regis3 initialized successfully!"))
+	fmt.Println("  " + i18n.Sprintf("Config: %s", paths.ConfigFile))
+	fmt.Println("  " + i18n.Sprintf("Registry: %s", registryPath))
+	fmt.Println("  " + i18n.Sprintf("Default target: %s", opts.DefaultTarget))
 	fmt.Println()
-	fmt.Println("Tip: Organize your registry however you like - regis3 scans all")
-	fmt.Println("     subdirectories and is folder-structure agnostic.")
+	fmt.Println(i18n.T("Next steps:"))
+	if itemCount > 0 {
+		fmt.Println("  1. " + i18n.Sprintf("Run 'regis3 list' to see the %d available items", itemCount))
+		fmt.Println("  2. " + i18n.T("Run 'regis3 project add' in a project to install items"))
+	} else {
+		fmt.Println("  1. " + i18n.T("Add markdown files with regis3 frontmatter to the registry"))
+		fmt.Println("  2. " + i18n.T("Run 'regis3 build' to build the manifest"))
+		fmt.Println("  3. " + i18n.T("Run 'regis3 list' to see available items"))
+		fmt.Println("  4. " + i18n.T("Run 'regis3 project add <type:name>' to install items"))
+		fmt.Println()
+		fmt.Println(i18n.T("Tip: Organize your registry however you like - regis3 scans all subdirectories and is folder-structure agnostic."))
+	}
 
 	return nil
 }
 
+// cloneRegistry clones a git repository into path, which must not exist or
+// be empty.
+func cloneRegistry(url, path string) error {
+	if err := registry.ValidateRemoteURL(url); err != nil {
+		return err
+	}
+	if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+		return errors.New(i18n.Sprintf("%s already exists and is not empty", path))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "clone", "--quiet", "--", url, path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// offerSetup runs the setup wizard when no config exists and the user
+// agrees. It reports whether setup ran, so the command can continue.
+func offerSetup() (bool, error) {
+	if !isInteractive() {
+		fmt.Fprintln(os.Stderr, i18n.T("regis3 is not set up yet. Run 'regis3 init' to set up."))
+		return false, nil
+	}
+
+	start := true
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(i18n.T("regis3 is not set up yet. Set it up now?")).
+				Description(i18n.T("Creates ~/.regis3/config.yaml and a registry")).
+				Affirmative(i18n.T("Set up")).
+				Negative(i18n.T("Not now")).
+				Value(&start),
+		),
+	)
+	if err := form.Run(); err != nil || !start {
+		return false, err
+	}
+
+	if err := runInit(); err != nil {
+		return false, err
+	}
+	fmt.Println()
+	return true, nil
+}
+
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~") {
 		home, err := os.UserHomeDir()
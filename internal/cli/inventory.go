@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var projectInventoryTarget string
+
+// projectInventoryCmd lists the installed items as an inventory
+var projectInventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "List the installed items as an inventory (SBOM)",
+	Long: `Lists every item installed in the current project with its content hash,
+license and the registry it came from, for organizations that keep an
+inventory of the AI configuration in each repository.
+
+--format cyclonedx writes a CycloneDX 1.5 JSON document (one component per
+item and target); --format json writes regis3's own JSON response.
+Licenses come from the items' license field in the registry.
+
+Examples:
+  regis3 project inventory
+  regis3 project inventory --format cyclonedx -o bom.cdx.json
+  regis3 project inventory --format json --target cursor`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectInventory()
+	},
+}
+
+func init() {
+	projectInventoryCmd.Flags().StringVar(&projectInventoryTarget, "target", "", "Target to list (default: every target installed in this project)")
+	projectCmd.AddCommand(projectInventoryCmd)
+}
+
+func runProjectInventory() error {
+	targets := projectTargets()
+	if projectInventoryTarget != "" {
+		target, err := resolveTarget(projectInventoryTarget)
+		if err != nil {
+			writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+			return err
+		}
+		targets = []*installer.Target{target}
+	}
+
+	items, err := installer.Inventory(".", targets, loadStatusManifest())
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to read the inventory: %s", err.Error()))
+		return err
+	}
+
+	project := "."
+	if abs, err := filepath.Abs("."); err == nil {
+		project = filepath.Base(abs)
+	}
+	data := &output.InventoryData{Project: project, Items: []output.InventoryItem{}}
+	for _, item := range items {
+		entry := output.InventoryItem{
+			ID:       item.ID,
+			Target:   item.Target,
+			Version:  item.Version,
+			Hash:     item.Hash,
+			License:  item.License,
+			Variant:  item.Variant,
+			Registry: item.Registry,
+		}
+		for _, f := range item.Files {
+			entry.Files = append(entry.Files, f.Path)
+		}
+		data.Items = append(data.Items, entry)
+	}
+
+	if formatFlag == "cyclonedx" {
+		bom, err := installer.CycloneDX(project, items)
+		if err != nil {
+			writer.Error(i18n.Sprintf("Failed to read the inventory: %s", err.Error()))
+			return err
+		}
+		data.BOM = string(bom) + "\n"
+		writer.Write(output.NewResponseBuilder("project inventory").WithSuccess(true).WithData(data).Build())
+		return nil
+	}
+
+	resp := output.NewResponseBuilder("project inventory").WithSuccess(true).WithData(data)
+	unlicensed := 0
+	for _, item := range items {
+		if item.License == "" {
+			unlicensed++
+		}
+	}
+	if len(items) > 0 {
+		resp.WithInfo("%d items installed, %d without a license", len(items), unlicensed)
+	}
+	writer.Write(resp.Build())
+	return nil
+}
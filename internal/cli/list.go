@@ -2,27 +2,41 @@ package cli
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
 var (
-	listTypeFlag string
-	listTagFlag  string
+	listTypeFlag    string
+	listTagFlag     string
+	listLicenseFlag string
+	listSortFlag    string
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List items in the registry",
-	Long: `Lists all items in the registry, optionally filtered by type or tag.
+	Long: `Lists all items in the registry, optionally filtered by type, tag or
+license. --license takes a glob compared ignoring case (e.g. GPL*), or
+"none" for items that declare no license.
+
+Items are grouped by type, then sorted by name, unless --sort or the
+list.sort config setting choose another order: name (alphabetical),
+modified (recently modified first) or size (largest first). The
+list.type_order config setting changes the order of the types.
 
 Examples:
   regis3 list                  # List all items
   regis3 list --type skill     # List only skills
-  regis3 list --tag frontend   # List items with 'frontend' tag`,
+  regis3 list --tag frontend   # List items with 'frontend' tag
+  regis3 list --license 'GPL*' # List items under a GPL license
+  regis3 list --sort modified  # Recently modified items first
+  regis3 list --sort size      # Largest items first`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runList()
 	},
@@ -31,26 +45,29 @@ Examples:
 func init() {
 	listCmd.Flags().StringVarP(&listTypeFlag, "type", "t", "", "Filter by type")
 	listCmd.Flags().StringVar(&listTagFlag, "tag", "", "Filter by tag")
+	listCmd.Flags().StringVar(&listLicenseFlag, "license", "", `Filter by license (a glob, or "none")`)
+	listCmd.Flags().StringVar(&listSortFlag, "sort", "", "Sort order: type, name, modified or size (default: from config, or type)")
 	rootCmd.AddCommand(listCmd)
 }
 
 func runList() error {
 	debugf("Listing items from: %s", getRegistryPath())
 
-	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	order := itemOrder()
+	if listSortFlag != "" {
+		order.By = listSortFlag
+	}
+	if order.By == "" {
+		order.By = registry.SortByType
+	}
+	if order.By != "size" && !slices.Contains(registry.SortOrders, order.By) {
+		writer.Error(i18n.Sprintf("Invalid sort order: %s (must be type, name, modified or size)", order.By))
+		return fmt.Errorf("invalid sort order: %s", order.By)
+	}
+
+	manifest, err := loadManifest()
 	if err != nil {
-		// Try building first
-		debugf("Manifest not found, building...")
-		_, buildErr := registry.BuildRegistry(getRegistryPath())
-		if buildErr != nil {
-			writer.Error(fmt.Sprintf("Failed to load registry: %s", err.Error()))
-			return err
-		}
-		manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
-		if err != nil {
-			writer.Error(fmt.Sprintf("Failed to load manifest: %s", err.Error()))
-			return err
-		}
+		return err
 	}
 
 	// Convert map to slice and filter
@@ -62,25 +79,30 @@ func runList() error {
 		if listTagFlag != "" && !hasTag(item.Tags, listTagFlag) {
 			continue
 		}
+		if listLicenseFlag != "" && !item.MatchLicense(listLicenseFlag) {
+			continue
+		}
 		items = append(items, item)
 	}
 
-	// Sort by type then name
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].Type != items[j].Type {
-			return items[i].Type < items[j].Type
-		}
-		return items[i].Name < items[j].Name
-	})
+	// Largest first, ties in type order
+	order.Sort(getRegistryPath(), items)
+	if order.By == "size" {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Size > items[j].Size
+		})
+	}
 
 	// Build list data
 	listItems := make([]output.ListItem, len(items))
 	for i, item := range items {
 		listItems[i] = output.ListItem{
-			Type: item.Type,
-			Name: item.Name,
-			Desc: item.Desc,
-			Tags: item.Tags,
+			Type:    item.Type,
+			Name:    item.Name,
+			Desc:    item.Desc,
+			Tags:    item.Tags,
+			Size:    item.Size,
+			License: item.License,
 		}
 	}
 
@@ -90,20 +112,32 @@ func runList() error {
 			Items:      listItems,
 			TotalCount: len(manifest.Items),
 			Filtered:   len(items) != len(manifest.Items),
+			Sort:       order.By,
 		})
 
 	if len(items) == 0 {
-		if listTypeFlag != "" || listTagFlag != "" {
+		if listTypeFlag != "" || listTagFlag != "" || listLicenseFlag != "" {
 			resp.WithInfo("No items match the filter")
 		} else {
 			resp.WithInfo("Registry is empty")
 		}
 	}
+	if n := len(manifest.Quarantine); n > 0 {
+		resp.WithWarning("%d items are quarantined because of validation errors (see 'regis3 quarantine')", n)
+	}
 
 	writer.Write(resp.Build())
 	return nil
 }
 
+// itemOrder returns the configured order of items.
+func itemOrder() registry.ItemOrder {
+	if cfg == nil {
+		return registry.ItemOrder{}
+	}
+	return cfg.ItemOrder()
+}
+
 func hasTag(tags []string, tag string) bool {
 	for _, t := range tags {
 		if t == tag {
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// Project sync flags
+var (
+	projectSyncDryRun  bool
+	projectSyncTarget  string
+	projectSyncScripts bool
+)
+
+// projectSyncCmd reinstalls what the project lockfile records
+var projectSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install exactly what regis3.lock records",
+	Long: `Reconciles the project with its lockfile, regis3.lock: installs the locked
+items at their locked content and removes installed items the lockfile
+doesn't list.
+
+project add, remove and update keep regis3.lock up to date. Commit it so
+other machines and CI reproduce the same installation with 'project sync'.
+Items whose registry content changed since they were locked are not
+installed; update the registry to the state the lockfile was written with,
+or run 'project add' to install and lock the new content. Stack
+alternatives and capability providers resolve to the items recorded in the
+lockfile.
+
+The lockfile records each target's items separately. Without --target, the
+only target in the lockfile is synced, or else the project's target.
+
+Examples:
+  regis3 project sync
+  regis3 project sync --dry-run
+  regis3 project sync --target cursor`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectSync()
+	},
+}
+
+func init() {
+	projectSyncCmd.Flags().BoolVar(&projectSyncDryRun, "dry-run", false, "Preview what would be installed and removed")
+	projectSyncCmd.Flags().BoolVar(&noReport, "no-report", false, "Don't report the installation to the webhook")
+	projectSyncCmd.Flags().StringVar(&projectSyncTarget, "target", "", "Target (default: the lockfile's only target, or the project's)")
+	projectSyncCmd.Flags().BoolVar(&projectSyncScripts, "allow-scripts", false, "Run item setup scripts without asking")
+
+	projectCmd.AddCommand(projectSyncCmd)
+}
+
+func runProjectSync() error {
+	lock, err := installer.LoadLock(".")
+	if errors.Is(err, installer.ErrNoLock) {
+		writer.Error(i18n.Sprintf("No %s in this project (project add writes it)", installer.LockFile))
+		return &exitError{code: 1, message: "no lockfile"}
+	}
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	targetName := projectSyncTarget
+	if targetName == "" && len(lock.Targets) == 1 {
+		for name := range lock.Targets {
+			targetName = name
+		}
+	}
+	target, err := resolveTarget(targetName)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+	if _, ok := lock.Targets[target.Name]; !ok {
+		locked := make([]string, 0, len(lock.Targets))
+		for name := range lock.Targets {
+			locked = append(locked, name)
+		}
+		slices.Sort(locked)
+		writer.Error(i18n.Sprintf("%s has no items for target %s (locked: %s)", installer.LockFile, target.Name, strings.Join(locked, ", ")))
+		return &exitError{code: 1, message: "target not locked"}
+	}
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if !projectSyncDryRun {
+		release, err := guardProject("project sync")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	inst, err := newInstaller(target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
+		return err
+	}
+	inst.DryRun = projectSyncDryRun
+	inst.ResolverOptions = resolverOptions(nil)
+	inst.Timings = timings()
+	inst.AllowScripts = projectSyncScripts
+	if cfg != nil {
+		inst.MergeBudget = cfg.MergeBudgetSize()
+	}
+	if isInteractive() {
+		inst.ConfirmScript = confirmSetupScript
+		inst.ConfirmMerge = confirmMergeChange
+	}
+	result, err := inst.Sync(manifest, lock)
+	if errors.Is(err, installer.ErrMergeDeclined) {
+		writer.Info(i18n.Sprintf("Installation cancelled, %s left unchanged", target.MergeFile))
+		return nil
+	}
+	if err != nil {
+		writer.Error(i18n.Sprintf("Sync failed: %s", err.Error()))
+		return err
+	}
+
+	resp := installResponse("project sync", result.Install, target, nil, projectSyncDryRun)
+	reportInstall(resp, "project sync", target, result.Install, projectSyncDryRun)
+	if lock.Registry != inst.RegistryPath && lock.Registry != inst.RegistryURL {
+		resp.WithWarning("%s was written with the registry %s", installer.LockFile, lock.Registry)
+	}
+	if removed := result.Uninstall; removed != nil {
+		for _, e := range removed.Errors {
+			resp.WithSuccess(false).WithError(e.ItemID, e.Message)
+		}
+		if projectSyncDryRun {
+			resp.WithInfo("Would remove %d items (dry run)", len(removed.Uninstalled))
+		} else if len(removed.Uninstalled) > 0 {
+			resp.WithInfo("Removed %d items not in %s", len(removed.Uninstalled), installer.LockFile)
+		}
+		if len(removed.Skipped) > 0 {
+			resp.WithWarning("Skipped %d merged items (edit %s manually)", len(removed.Skipped), target.MergeFile)
+		}
+	}
+	writer.Write(resp.Build())
+
+	if len(result.Install.Errors) > 0 || (result.Uninstall != nil && len(result.Uninstall.Errors) > 0) {
+		return fmt.Errorf("sync failed")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/okto-digital/regis3/pkg/refs"
+)
+
+// buildOptions returns registry build options derived from the config.
+func buildOptions() registry.BuildOptions {
+	opts := registry.BuildOptions{}
+	if cfg != nil {
+		opts.DependencyRules = cfg.DependencyRules
+		opts.Lint = registry.LintConfig{Disable: cfg.Lint.Disable}
+		opts.SizeBudgets = cfg.Budgets()
+		opts.RequireLicense = cfg.License.Require
+		opts.Filter = registry.Filter{
+			Include: cfg.Build.Include,
+			Exclude: cfg.Build.Exclude,
+		}
+		opts.StagingDir = cfg.StagingPath()
+		opts.Dialect = cfg.Dialect()
+	}
+	opts.Timings = timings()
+	return opts
+}
+
+// resolverOptions returns dependency resolution options derived from the config.
+// Preferred alternatives from flags take precedence over configured ones.
+func resolverOptions(prefer []string) resolver.Options {
+	opts := resolver.Options{
+		Preferred:  append([]string{}, prefer...),
+		GraphCache: filepath.Join(getRegistryPath(), registry.DefaultBuildDir, resolver.DefaultGraphFile),
+	}
+	if cfg != nil {
+		opts.Providers = cfg.Providers
+		opts.Preferred = append(opts.Preferred, cfg.Prefer...)
+	}
+	if isInteractive() {
+		opts.Choose = pickAlternative
+	}
+	return opts
+}
+
+// buildRegistry builds the registry manifest using the configured options.
+func buildRegistry() (*registry.BuildResult, error) {
+	return registry.BuildRegistryWithOptions(getRegistryPath(), buildOptions())
+}
+
+// loadManifest loads the registry manifest, building it first if it doesn't exist.
+// Errors are reported through the writer.
+func loadManifest() (*registry.Manifest, error) {
+	defer timings().Start("load manifest")()
+
+	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	if err == nil {
+		return manifest, nil
+	}
+
+	debugf("Manifest not found, building...")
+	if _, buildErr := buildRegistry(); buildErr != nil {
+		writer.Error(i18n.Sprintf("Failed to load registry: %s", err.Error()))
+		return nil, err
+	}
+
+	manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to load manifest: %s", err.Error()))
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// resolveRefs maps references given on the command line to item full names.
+// References may omit the type when unambiguous and may name an alias; the
+// returned notices flag the deprecated aliases used.
+func resolveRefs(manifest *registry.Manifest, refs []string) ([]string, []string, error) {
+	ids := make([]string, 0, len(refs))
+	var notices []string
+	for _, ref := range refs {
+		id, err := manifest.ResolveRef(ref)
+		if q, ok := manifest.GetQuarantined(ref); ok && err != nil {
+			return nil, nil, errors.New(i18n.Sprintf("%s is quarantined because of validation errors: %s (see 'regis3 quarantine')", ref, strings.Join(q.Errors, "; ")))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if isAlias(manifest, ref, id) {
+			notices = append(notices, aliasNotice(ref, id))
+		}
+		ids = append(ids, id)
+	}
+	return ids, notices, nil
+}
+
+// isAlias reports whether ref reached the item id through one of its aliases.
+func isAlias(manifest *registry.Manifest, ref, id string) bool {
+	item, ok := manifest.GetItem(id)
+	if !ok {
+		return false
+	}
+	return refs.NameOf(ref) != item.Name
+}
+
+// deprecationNotice is the warning shown when a deprecated item is
+// installed.
+func deprecationNotice(id, replacedBy string) string {
+	if replacedBy == "" {
+		return i18n.Sprintf("%s is deprecated and may be removed from the registry", id)
+	}
+	return i18n.Sprintf("%s is deprecated, use %s instead", id, replacedBy)
+}
+
+// aliasNotice is the deprecation notice shown when a ref is an item alias.
+func aliasNotice(alias, target string) string {
+	return fmt.Sprintf("'%s' is a deprecated alias for '%s'", alias, target)
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var registryMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate item frontmatter to the current schema",
+	Long: `Rewrites the regis3 block of every registry item for changes to the
+frontmatter schema, such as renamed fields, applying the migrations after
+the registry's schema version in order. Only the changed keys are rewritten;
+comments, quoting and the rest of each file stay as they are.
+
+The registry's schema version is kept in ` + registry.SchemaFile + `. --all
+applies every migration again, for items added in the old schema since; items
+already migrated are left unchanged.
+
+If any file can't be migrated, no file is changed. --dry-run shows the change
+to each file without writing it.
+
+Examples:
+  regis3 registry migrate --dry-run
+  regis3 registry migrate
+  regis3 registry migrate --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryMigrate()
+	},
+}
+
+var (
+	registryMigrateDryRun bool
+	registryMigrateAll    bool
+)
+
+func init() {
+	registryMigrateCmd.Flags().BoolVar(&registryMigrateDryRun, "dry-run", false, "Show the changes without writing them")
+	registryMigrateCmd.Flags().BoolVar(&registryMigrateAll, "all", false, "Apply every migration, not just those after the registry's schema version")
+	registryCmd.AddCommand(registryMigrateCmd)
+}
+
+func runRegistryMigrate() error {
+	if !registryMigrateDryRun {
+		if err := guardRegistry("registry migrate"); err != nil {
+			return err
+		}
+	}
+	registryPath := getRegistryPath()
+	version, err := registry.LoadSchemaVersion(registryPath)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	from := version
+	if registryMigrateAll {
+		from = 0
+	}
+	migrations := registry.PendingMigrations(from)
+
+	data := &output.MigrateData{From: version, To: registry.SchemaVersion, Migrations: []string{}, Files: []output.MigrateFile{}, DryRun: registryMigrateDryRun}
+	resp := output.NewResponseBuilder("registry migrate")
+	if len(migrations) == 0 {
+		resp.WithSuccess(true).WithData(data).WithInfo("Registry is at schema version %d, nothing to migrate", version)
+		writer.Write(resp.Build())
+		return nil
+	}
+	for _, m := range migrations {
+		data.Migrations = append(data.Migrations, fmt.Sprintf("%d: %s", m.Version, m.Desc))
+	}
+
+	opts := buildOptions()
+	scanner := registry.NewScanner(registryPath)
+	scanner.Filter = opts.Filter
+	scanner.StagingDir = opts.StagingDir
+	scanner.Dialect = opts.Dialect
+	scan, err := scanner.Scan()
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to scan registry: %s", err.Error()))
+		return err
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	for _, item := range scan.Items {
+		if !seen[item.Source] {
+			seen[item.Source] = true
+			sources = append(sources, item.Source)
+		}
+	}
+	sort.Strings(sources)
+
+	// Every file is migrated before any is written, so a failure leaves
+	// the registry unchanged
+	migrated := make(map[string][]byte)
+	failed := false
+	for _, source := range sources {
+		content, err := os.ReadFile(filepath.Join(registryPath, source))
+		if err == nil {
+			var updated []byte
+			updated, err = opts.Dialect.Migrate(content, migrations)
+			if err == nil && !bytes.Equal(updated, content) {
+				migrated[source] = updated
+				data.Files = append(data.Files, output.MigrateFile{
+					Path: source,
+					Diff: installer.LineDiff(string(content), string(updated)),
+				})
+			}
+		}
+		if err != nil {
+			resp.WithError(source, err.Error())
+			failed = true
+		}
+	}
+
+	switch {
+	case failed:
+		resp.WithInfo("No files were changed; fix the files above and run the migration again")
+	case registryMigrateDryRun:
+		resp.WithInfo("Would migrate %d of %d files to schema version %d (dry run)", len(data.Files), len(sources), registry.SchemaVersion)
+	default:
+		for _, f := range data.Files {
+			if err := writeFormatted(filepath.Join(registryPath, f.Path), migrated[f.Path]); err != nil {
+				resp.WithError(f.Path, err.Error())
+				failed = true
+			}
+		}
+		if !failed {
+			err = registry.SaveSchemaVersion(registryPath, registry.SchemaVersion)
+			if err != nil {
+				resp.WithError(registry.SchemaFile, err.Error())
+				failed = true
+			}
+		}
+		if !failed {
+			resp.WithInfo("Migrated %d of %d files to schema version %d", len(data.Files), len(sources), registry.SchemaVersion)
+		}
+	}
+
+	resp.WithSuccess(!failed).WithData(data)
+	writer.Write(resp.Build())
+	if failed {
+		return &exitError{code: 1, message: "migration failed"}
+	}
+	return nil
+}
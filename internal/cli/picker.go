@@ -2,128 +2,365 @@ package cli
 
 import (
 	"fmt"
-	"sort"
-	"strings"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+	"github.com/okto-digital/regis3/internal/ctl"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
 	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/tui"
+	"github.com/okto-digital/regis3/pkg/refs"
 )
 
-// itemOption represents a selectable item in the picker.
-type itemOption struct {
-	ref   string // type:name
-	label string // display label
-}
-
-// pickItemsToAdd shows an interactive picker for selecting items to add.
-func pickItemsToAdd(manifest *registry.Manifest) ([]string, error) {
+// pickItemsToAdd shows a full-screen picker for selecting items to add.
+// Items already installed for target are marked, and quarantined items are
+// listed for editing. The list is reloaded when the manifest is rebuilt
+// while the picker is open, and the command palette can rebuild it. While
+// the picker is open, 'regis3 ctl' sends it actions over the project's
+// control socket.
+func pickItemsToAdd(manifest *registry.Manifest, target *installer.Target) ([]string, error) {
 	if len(manifest.Items) == 0 {
 		return nil, fmt.Errorf("no items found in registry")
 	}
 
-	// Group items by type
-	grouped := groupItemsByType(manifest)
-
-	// Build options with visual grouping
-	options := buildGroupedOptions(grouped)
+	session := &pickerSession{
+		target:       target,
+		manifestPath: filepath.Join(getRegistryPath(), registry.DefaultBuildDir, registry.DefaultManifestFile),
+		events:       make(chan tui.Event, 4),
+	}
+	if info, err := os.Stat(session.manifestPath); err == nil {
+		session.lastModified = info.ModTime()
+	}
 
-	if len(options) == 0 {
-		return nil, fmt.Errorf("no items available")
+	picker := tui.NewPickerWithOrder("Select items to add", pickerEntries(manifest, target), pickerOrder())
+	picker.Icons = iconSet().Icons()
+	picker.Reload = session.reload
+	picker.Edit = func(e tui.Entry) *exec.Cmd {
+		cmd, _ := editorCommand(filepath.Join(getRegistryPath(), e.Source))
+		return cmd
 	}
+	picker.Actions = []tui.Action{{
+		Name: "build",
+		Desc: "Rebuild the manifest from the registry",
+		Run:  session.build,
+	}}
 
-	// Create huh select options (skip group headers, they're just for visual reference)
-	var huhOptions []huh.Option[string]
-	for _, opt := range options {
-		if opt.ref != "" {
-			huhOptions = append(huhOptions, huh.NewOption(opt.label, opt.ref))
-		}
+	if server, err := listenControl(session.handle); err != nil {
+		debugf("Control socket not available: %s", err)
+	} else {
+		defer server.Close()
+		picker.Events = session.events
 	}
+	return picker.Run()
+}
 
-	// Create multi-select
-	var selected []string
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewMultiSelect[string]().
-				Title("Select items to add").
-				Description("Use arrow keys to navigate, space to select, enter to confirm").
-				Options(huhOptions...).
-				Value(&selected).
-				Filterable(true).
-				Limit(20),
-		),
-	)
+// pickerSession is the state of an open picker. Its reload, palette actions
+// and control requests run in the background, one at a time.
+type pickerSession struct {
+	target       *installer.Target
+	manifestPath string
+	events       chan tui.Event
+
+	mu           sync.Mutex
+	lastModified time.Time
+}
 
-	err := form.Run()
+// reload returns the entries of a manifest rebuilt since the last check, or
+// nil when it is unchanged.
+func (s *pickerSession) reload() ([]tui.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, err := os.Stat(s.manifestPath)
+	if err != nil || info.ModTime().Equal(s.lastModified) {
+		return nil, err
+	}
+	manifest, err := registry.LoadManifest(s.manifestPath)
 	if err != nil {
 		return nil, err
 	}
+	s.lastModified = info.ModTime()
+	return pickerEntries(manifest, s.target), nil
+}
+
+// build rebuilds the manifest and returns its entries.
+func (s *pickerSession) build() ([]tui.Entry, error) {
+	_, entries, err := s.buildManifest()
+	return entries, err
+}
+
+// buildManifest rebuilds the manifest, quarantining items with errors again
+// if the current manifest has any.
+func (s *pickerSession) buildManifest() (*registry.BuildResult, []tui.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opts := buildOptions()
+	if current, err := registry.LoadManifest(s.manifestPath); err == nil {
+		opts.AllowErrors = len(current.Quarantine) > 0
+	}
+	result, err := registry.BuildRegistryWithOptions(getRegistryPath(), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info, err := os.Stat(s.manifestPath); err == nil {
+		s.lastModified = info.ModTime()
+	}
+	return result, pickerEntries(result.Manifest, s.target), nil
+}
+
+// install installs items for the picker's target and returns the entries
+// with the new items marked. Setup scripts are skipped and merge file
+// rewrites aren't confirmed, as nobody can answer a prompt.
+func (s *pickerSession) install(args []string) (*output.Response, []tui.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest, err := registry.LoadManifest(s.manifestPath)
+	if err != nil {
+		return output.NewErrorResponse("ctl install", err), nil
+	}
+	ids, notices, err := resolveRefs(manifest, args)
+	if err != nil {
+		return output.NewErrorResponse("ctl install", err), nil
+	}
+
+	// Nothing can show a spinner here, so don't wait for other processes
+	guard, err := installer.AcquireGuard(".", "ctl install")
+	if err != nil {
+		return output.NewErrorResponse("ctl install", err), nil
+	}
+	defer guard.Release()
 
-	// Filter out empty selections (group headers)
-	var result []string
-	for _, s := range selected {
-		if s != "" {
-			result = append(result, s)
+	inst, err := newInstaller(s.target)
+	if err != nil {
+		return output.NewErrorResponse("ctl install", err), nil
+	}
+	inst.ResolverOptions = resolverOptions(nil)
+	inst.Explicit = true
+	if cfg != nil {
+		inst.MergeBudget = cfg.MergeBudgetSize()
+	}
+	result, err := inst.Install(manifest, ids)
+	if err != nil {
+		return output.NewErrorResponse("ctl install", err), nil
+	}
+	resp := installResponse("ctl install", result, s.target, notices, false)
+	reportInstall(resp, "ctl install", s.target, result, false)
+	return resp.Build(), pickerEntries(manifest, s.target)
+}
+
+// handle performs a request sent over the control socket and shows its
+// outcome in the picker.
+func (s *pickerSession) handle(req ctl.Request) any {
+	var resp *output.Response
+	var entries []tui.Entry
+	switch req.Action {
+	case "build":
+		result, built, err := s.buildManifest()
+		if err != nil {
+			resp = output.NewErrorResponse("ctl build", err)
+			break
+		}
+		entries = built
+		resp = output.NewResponseBuilder("ctl build").
+			WithSuccess(true).
+			WithData(buildData(result)).
+			Build()
+	case "install":
+		if len(req.Args) == 0 {
+			return output.NewErrorResponse("ctl install", fmt.Errorf("no items to install"))
 		}
+		resp, entries = s.install(req.Args)
+	default:
+		return output.NewErrorResponse("ctl "+req.Action, fmt.Errorf("unknown action %q", req.Action))
 	}
 
-	return result, nil
+	event := tui.Event{Notice: req.Action + " finished", Entries: entries}
+	switch {
+	case resp.Error != nil:
+		event.Notice = req.Action + " failed: " + resp.Error.Message
+	case !resp.Success:
+		event.Notice = req.Action + " failed"
+	case entries != nil:
+		event.Notice += ", list refreshed"
+	}
+	if data, ok := resp.Data.(output.InstallData); ok {
+		for _, d := range data.DeprecatedInstalled {
+			event.Notice += "; " + deprecationNotice(d.ID, d.ReplacedBy)
+		}
+	}
+	// The picker may have closed meanwhile
+	select {
+	case s.events <- event:
+	default:
+	}
+	return resp
 }
 
-// groupItemsByType groups manifest items by their type.
-func groupItemsByType(manifest *registry.Manifest) map[string][]*registry.Item {
-	grouped := make(map[string][]*registry.Item)
+// pickerEntries lists the manifest's items for the picker, marking the
+// items installed for target. Items suggested for the project come first,
+// best fit first.
+func pickerEntries(manifest *registry.Manifest, target *installer.Target) []tui.Entry {
+	tracker := loadTracker(target)
+	entry := func(id string, item *registry.Item) tui.Entry {
+		var modified time.Time
+		if info, err := os.Stat(filepath.Join(manifest.RegistryPath, item.Source)); err == nil {
+			modified = info.ModTime()
+		}
+		return tui.Entry{
+			Ref:        id,
+			Type:       item.Type,
+			Name:       item.Name,
+			Desc:       item.Desc,
+			Source:     item.Source,
+			Tags:       item.Tags,
+			Deps:       manifest.ItemDeps(item),
+			Installed:  tracker.IsInstalled(id),
+			Modified:   modified,
+			Deprecated: item.Deprecated(),
+			ReplacedBy: item.ReplacedBy,
+		}
+	}
 
-	for _, item := range manifest.Items {
-		grouped[item.Type] = append(grouped[item.Type], item)
+	entries := make([]tui.Entry, 0, len(manifest.Items))
+	suggested := make(map[string]bool)
+	if _, suggestions, err := projectSuggestions(manifest, tracker); err != nil {
+		debugf("Could not suggest items: %s", err)
+	} else {
+		for _, s := range suggestions {
+			e := entry(s.Ref, manifest.Items[s.Ref])
+			e.Suggested = suggestionReason(s)
+			entries = append(entries, e)
+			suggested[s.Ref] = true
+		}
 	}
+	for id, item := range manifest.Items {
+		if !suggested[id] {
+			entries = append(entries, entry(id, item))
+		}
+	}
+	for _, q := range manifest.Quarantine {
+		e := tui.Entry{Ref: q.Path, Name: q.Path, Source: q.Path, Quarantined: q.Errors}
+		if itemType, name, ok := refs.Split(q.ID); ok {
+			e.Ref, e.Type, e.Name = q.ID, itemType, name
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
 
-	// Sort items within each group by name
-	for itemType := range grouped {
-		sort.Slice(grouped[itemType], func(i, j int) bool {
-			return grouped[itemType][i].Name < grouped[itemType][j].Name
-		})
+// pickerOrder returns the configured order of the picker's entries within
+// their type group.
+func pickerOrder() tui.Order {
+	order := itemOrder()
+	o := tui.Order{Types: order.TypeOrder()}
+	if order.By == registry.SortByModified {
+		o.Less = func(a, b tui.Entry) bool {
+			if !a.Modified.Equal(b.Modified) {
+				return a.Modified.After(b.Modified)
+			}
+			return a.Ref < b.Ref
+		}
 	}
+	return o
+}
 
-	return grouped
+// loadTracker loads the project's tracker for target, or an empty one.
+func loadTracker(target *installer.Target) *installer.Tracker {
+	tracker, err := installer.LoadTargetTracker(".", target)
+	if err != nil {
+		debugf("Could not load tracker: %s", err)
+		tracker = installer.NewTracker(".", target.Name)
+	}
+	return tracker
 }
 
-// buildGroupedOptions creates a flat list of options with group headers.
-func buildGroupedOptions(grouped map[string][]*registry.Item) []itemOption {
-	var options []itemOption
+// isInteractive reports whether prompts can be shown to the user.
+func isInteractive() bool {
+	if formatFlag != "pretty" {
+		return false
+	}
+	// /dev/null is a character device too, so check for a terminal
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
 
-	// Define type order for consistent display
-	typeOrder := []string{
-		"skill", "subagent", "command", "doc", "prompt",
-		"philosophy", "project", "ruleset",
-		"mcp", "script", "hook", "stack",
+// pickAlternative asks the user to choose one of a stack's alternatives.
+func pickAlternative(stack string, alternatives []string) (string, error) {
+	var huhOptions []huh.Option[string]
+	for _, alt := range alternatives {
+		huhOptions = append(huhOptions, huh.NewOption(alt, alt))
 	}
 
-	// Add items in type order
-	for _, itemType := range typeOrder {
-		items, ok := grouped[itemType]
-		if !ok || len(items) == 0 {
-			continue
-		}
+	chosen := alternatives[0]
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("%s offers alternatives", stack)).
+				Description("Choose one to install").
+				Options(huhOptions...).
+				Value(&chosen),
+		),
+	)
 
-		// Add group header
-		header := fmt.Sprintf("── %s ──", strings.Title(itemType)+"s")
-		options = append(options, itemOption{ref: "", label: header})
-
-		// Add items
-		for _, item := range items {
-			ref := fmt.Sprintf("%s:%s", item.Type, item.Name)
-			label := fmt.Sprintf("  %s", ref)
-			if item.Desc != "" {
-				// Truncate description if too long
-				desc := item.Desc
-				if len(desc) > 50 {
-					desc = desc[:47] + "..."
-				}
-				label = fmt.Sprintf("  %-30s  %s", ref, desc)
-			}
-			options = append(options, itemOption{ref: ref, label: label})
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return chosen, nil
+}
+
+// confirmSetupScript asks the user whether to run an item's setup script.
+func confirmSetupScript(item *registry.Item, script string) (bool, error) {
+	run := false
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Run setup script for %s?", item.FullName())).
+				Description(script).
+				Affirmative("Run").
+				Negative("Skip").
+				Value(&run),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return false, err
+	}
+	return run, nil
+}
+
+// confirmMergeChange asks the user whether to rewrite the merge file's
+// managed section when the rewrite loses content. Item types switched off
+// under confirm_merge in the config are rewritten without asking.
+func confirmMergeChange(change *installer.MergeChange) (bool, error) {
+	ask := false
+	for _, id := range change.Items {
+		if cfg == nil || cfg.ConfirmsMerge(refs.TypeOf(id)) {
+			ask = true
+			break
 		}
 	}
+	if !ask {
+		return true, nil
+	}
 
-	return options
+	rewrite := false
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Rewrite the managed section of %s?", change.File)).
+				Description(change.Summary() + "\n\n" + change.Diff()).
+				Affirmative("Rewrite").
+				Negative("Cancel").
+				Value(&rewrite),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return false, err
+	}
+	return rewrite, nil
 }
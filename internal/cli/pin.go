@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/pkg/refs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectPinTarget string
+
+	projectUpdateAll     bool
+	projectUpdateDryRun  bool
+	projectUpdateTarget  string
+	projectUpdateScripts bool
+)
+
+// projectPinCmd pins installed items to their installed content
+var projectPinCmd = &cobra.Command{
+	Use:   "pin <type:name> [type:name...]",
+	Short: "Keep installed items at their current content",
+	Long: `Pins installed items to the registry content they were installed from.
+
+Pinned items are skipped by 'project update' and 'project add' until they
+are unpinned (or reinstalled with --force), so a project can stay on an
+older variant of an item on purpose. 'project status' marks pinned items.
+
+Examples:
+  regis3 project pin skill:git-conventions
+  regis3 project unpin skill:git-conventions`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("missing item reference\n\nUsage: regis3 project pin <type:name> [type:name...]\n\nExample: regis3 project pin skill:git-conventions")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectPin("project pin", args, true)
+	},
+}
+
+// projectUnpinCmd lets pinned items update again
+var projectUnpinCmd = &cobra.Command{
+	Use:   "unpin <type:name> [type:name...]",
+	Short: "Let pinned items update again",
+	Long: `Unpins items pinned with 'project pin', so 'project update' installs
+their latest registry content again.
+
+Examples:
+  regis3 project unpin skill:git-conventions`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("missing item reference\n\nUsage: regis3 project unpin <type:name> [type:name...]\n\nExample: regis3 project unpin skill:git-conventions")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectPin("project unpin", args, false)
+	},
+}
+
+// projectUpdateCmd reinstalls installed items with newer registry content
+var projectUpdateCmd = &cobra.Command{
+	Use:   "update [type:name...]",
+	Short: "Update installed items to the latest registry content",
+	Long: `Reinstalls installed items whose registry content changed.
+
+Name the items to update, or use --all for every installed item with an
+update available. Pinned items are skipped until they are unpinned.
+
+Examples:
+  regis3 project update --all
+  regis3 project update skill:git-conventions
+  regis3 project update --all --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !projectUpdateAll {
+			return fmt.Errorf("missing item reference\n\nUsage: regis3 project update <type:name> [type:name...]\n       regis3 project update --all")
+		}
+		return runProjectUpdate(args)
+	},
+}
+
+func init() {
+	projectPinCmd.Flags().StringVar(&projectPinTarget, "target", "", "Target (default: from config, or detected from the project)")
+	projectUnpinCmd.Flags().StringVar(&projectPinTarget, "target", "", "Target (default: from config, or detected from the project)")
+
+	projectUpdateCmd.Flags().BoolVar(&projectUpdateAll, "all", false, "Update every installed item with an update available")
+	projectUpdateCmd.Flags().BoolVar(&projectUpdateDryRun, "dry-run", false, "Preview what would be updated")
+	projectUpdateCmd.Flags().BoolVar(&noReport, "no-report", false, "Don't report the update to the webhook")
+	projectUpdateCmd.Flags().StringVar(&projectUpdateTarget, "target", "", "Target (default: from config, or detected from the project)")
+	projectUpdateCmd.Flags().BoolVar(&projectUpdateScripts, "allow-scripts", false, "Run item setup scripts without asking")
+
+	projectCmd.AddCommand(projectPinCmd)
+	projectCmd.AddCommand(projectUnpinCmd)
+	projectCmd.AddCommand(projectUpdateCmd)
+}
+
+func runProjectPin(command string, args []string, pinned bool) error {
+	target, err := resolveTarget(projectPinTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	release, err := guardProject(command)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tracker, err := installer.LoadTargetTracker(".", target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Error: %s", err.Error()))
+		return err
+	}
+
+	ids, notices := resolveInstalledRefs(tracker, args)
+	data := output.PinData{Items: []string{}, Pinned: pinned}
+	for _, id := range ids {
+		if tracker.SetPinned(id, pinned) {
+			data.Items = append(data.Items, id)
+		} else {
+			data.NotInstalled = append(data.NotInstalled, id)
+		}
+	}
+
+	if len(data.Items) > 0 {
+		if err := tracker.Save(); err != nil {
+			writer.Error(i18n.Sprintf("Error: %s", err.Error()))
+			return err
+		}
+	}
+
+	resp := output.NewResponseBuilder(command).
+		WithSuccess(len(data.NotInstalled) == 0).
+		WithData(&data)
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+	for _, id := range data.NotInstalled {
+		resp.WithError(id, "not installed in this project")
+	}
+	message := "Pinned %s"
+	if !pinned {
+		message = "Unpinned %s"
+	}
+	for _, id := range data.Items {
+		resp.WithInfo(message, id)
+	}
+
+	writer.Write(resp.Build())
+
+	if len(data.NotInstalled) > 0 {
+		return fmt.Errorf("items not installed")
+	}
+	return nil
+}
+
+func runProjectUpdate(args []string) error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	target, err := resolveTarget(projectUpdateTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	if !projectUpdateDryRun {
+		release, err := guardProject("project update")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	inst, err := newInstaller(target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
+		return err
+	}
+	inst.DryRun = projectUpdateDryRun
+	inst.ResolverOptions = resolverOptions(nil)
+	inst.Timings = timings()
+	inst.AllowScripts = projectUpdateScripts
+	if cfg != nil {
+		inst.MergeBudget = cfg.MergeBudgetSize()
+	}
+	if isInteractive() {
+		inst.ConfirmScript = confirmSetupScript
+		inst.ConfirmMerge = confirmMergeChange
+	}
+
+	ids, notices := resolveInstalledRefs(inst.Tracker, args)
+	var skipped []string
+	if projectUpdateAll {
+		outdated, pinned := inst.Outdated(manifest)
+		ids = append(ids, outdated...)
+		skipped = pinned
+	}
+	for _, id := range ids {
+		if !inst.Tracker.IsInstalled(id) {
+			writer.Error(i18n.Sprintf("%s is not installed in this project (use 'regis3 project add')", id))
+			return fmt.Errorf("item not installed")
+		}
+	}
+
+	resp := output.NewResponseBuilder("project update")
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+	for _, id := range skipped {
+		resp.WithInfo("Skipped pinned %s (run 'regis3 project unpin %s' to update it)", id, id)
+	}
+	if len(ids) == 0 {
+		resp.WithSuccess(true).
+			WithData(output.InstallData{Installed: []output.InstalledItem{}, Target: target.Name, DryRun: projectUpdateDryRun}).
+			WithInfo("All installed items are up to date")
+		writer.Write(resp.Build())
+		return nil
+	}
+
+	result, err := inst.Install(manifest, ids)
+	if errors.Is(err, installer.ErrMergeDeclined) {
+		writer.Info(i18n.Sprintf("Update cancelled, %s left unchanged", target.MergeFile))
+		return nil
+	}
+	if err != nil {
+		writer.Error(i18n.Sprintf("Update failed: %s", err.Error()))
+		return err
+	}
+
+	var updated []output.InstalledItem
+	for _, id := range append(append([]string{}, result.Installed...), result.Updated...) {
+		if itemType, name, ok := refs.Split(id); ok {
+			updated = append(updated, output.InstalledItem{Type: itemType, Name: name})
+		}
+	}
+
+	resp.WithData(output.InstallData{
+		Installed:   updated,
+		Skipped:     result.Skipped,
+		Target:      target.Name,
+		DryRun:      projectUpdateDryRun,
+		LocalMerged: result.LocalMerged,
+		Conflicts:   result.Conflicts,
+		KeptLocal:   result.KeptLocal,
+	})
+	for _, id := range result.Pinned {
+		resp.WithInfo("Skipped pinned %s (run 'regis3 project unpin %s' to update it)", id, id)
+	}
+	for _, id := range inst.Overrides.Unmatched(manifest) {
+		resp.WithWarning("%s in %s overrides no registry item", id, installer.LocalDir)
+	}
+
+	if len(result.Errors) > 0 {
+		resp.WithSuccess(false)
+		for _, e := range result.Errors {
+			resp.WithError(e.ItemID, e.Message)
+		}
+		if result.RolledBack {
+			resp.WithInfo("Nothing was updated: the update was rolled back")
+		}
+	} else {
+		resp.WithSuccess(true)
+		if projectUpdateDryRun {
+			resp.WithInfo("Would update %d items (dry run)", len(updated))
+		} else if len(updated) > 0 {
+			resp.WithInfo("Updated %d items", len(updated))
+		}
+		reportLocalChanges(resp, result)
+		if len(result.MergedItems) > 0 {
+			resp.WithInfo("Merged %d items into %s", len(result.MergedItems), target.MergeFile)
+		}
+		for _, id := range result.Local {
+			resp.WithInfo("Used the local override of %s from %s", id, installer.LocalDir)
+		}
+		for _, id := range result.Scripts {
+			resp.WithInfo("Ran setup script for %s", id)
+		}
+		for _, id := range result.SkippedScripts {
+			resp.WithWarning("Skipped setup script for %s (use --allow-scripts to run it)", id)
+		}
+		if result.MergeChange != nil {
+			resp.WithWarning("%s", result.MergeChange.Summary())
+		}
+		if result.MergeBudget != nil {
+			resp.WithWarning("%s", result.MergeBudget.Error())
+		}
+		reportInstall(resp, "project update", target, result, projectUpdateDryRun)
+	}
+
+	writer.Write(resp.Build())
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("update failed")
+	}
+	return nil
+}
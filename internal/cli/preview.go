@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/preview"
+	"github.com/spf13/cobra"
+)
+
+var (
+	previewTarget string
+	previewAddr   string
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview <file>",
+	Short: "Preview an item in the browser while editing it",
+	Long: `Serves a live HTML preview of a registry item file: the rendered content,
+its metadata and validation issues, and the file installing it writes for a
+target (the merge file section for merged items). The page reloads when the
+file is saved; links on the page switch between the available targets.
+
+The file may be given relative to the current directory or the registry.
+The preview runs until interrupted with ctrl+c.
+
+Examples:
+  regis3 preview skills/git-conventions.md
+  regis3 preview skills/git-conventions.md --target cursor
+  regis3 preview philosophies/kiss.md --addr localhost:8080`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPreview(args[0])
+	},
+}
+
+func init() {
+	previewCmd.Flags().StringVar(&previewTarget, "target", "", "Target shown first (default: from config, or detected from the project)")
+	previewCmd.Flags().StringVar(&previewAddr, "addr", "localhost:0", "Address to serve on (port 0 picks a free port)")
+	rootCmd.AddCommand(previewCmd)
+}
+
+func runPreview(path string) error {
+	file, ok := previewFile(path)
+	if !ok {
+		writer.Error(i18n.Sprintf("File not found: %s", path))
+		return &exitError{code: 1, message: "file not found"}
+	}
+
+	target, err := resolveTarget(previewTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+	targets := []*installer.Target{target}
+	for _, t := range availableTargets() {
+		if t.Name != target.Name {
+			targets = append(targets, t)
+		}
+	}
+
+	server := &preview.Server{
+		RegistryPath: getRegistryPath(),
+		File:         file,
+		Dialect:      buildOptions().Dialect,
+		Targets:      targets,
+	}
+
+	listener, err := net.Listen("tcp", previewAddr)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Cannot serve the preview: %s", err.Error()))
+		return err
+	}
+	writer.Info(i18n.Sprintf("Previewing %s at http://%s (ctrl+c to stop)", path, listener.Addr()))
+
+	// Stop cleanly on ctrl+c, so deferred output is written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	httpServer := &http.Server{Handler: server.Handler()}
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// previewFile returns the absolute path of an item file given relative to
+// the current directory or the registry.
+func previewFile(path string) (string, bool) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = append(candidates, filepath.Join(getRegistryPath(), path))
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(candidate)
+			return abs, err == nil
+		}
+	}
+	return "", false
+}
@@ -1,23 +1,41 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/installer"
 	"github.com/okto-digital/regis3/internal/output"
 	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/okto-digital/regis3/pkg/refs"
 	"github.com/spf13/cobra"
 )
 
 // Project command flags
 var (
-	projectAddDryRun    bool
-	projectAddForce     bool
-	projectAddTarget    string
-	projectRemoveDryRun bool
-	projectRemoveTarget string
-	projectStatusTarget string
+	projectAddDryRun     bool
+	projectAddForce      bool
+	projectAddTarget     string
+	projectAddAll        bool
+	projectAddExplain    bool
+	projectAddChoose     []string
+	projectAddFromFile   string
+	projectAddScripts    bool
+	projectAddStrict     bool
+	projectAddVariant    string
+	projectAddLink       bool
+	projectRemoveDryRun  bool
+	projectRemoveTarget  string
+	projectRemoveForce   bool
+	projectRemoveCascade bool
+	projectStatusTarget  string
+	projectStatusAll     bool
 )
 
 // projectCmd is the parent command for project operations
@@ -42,33 +60,87 @@ var projectAddCmd = &cobra.Command{
 	Long: `Installs one or more items from the registry to the current project.
 
 Dependencies are automatically resolved and installed in the correct order.
+When a stack offers alternatives (one_of), the choice comes from --choose,
+the "prefer" config setting, or an interactive prompt (first entry otherwise).
 Items are installed to the .claude/ directory (for Claude Code target).
+The type prefix may be omitted when only one item has the name.
+
+Items may declare a setup script that runs after they are installed, e.g.
+to register an MCP server. Scripts only run after confirmation, or without
+asking when --allow-scripts is given; otherwise they are skipped.
+
+When the managed section of the merge file (e.g. CLAUDE.md) grows past the
+merge_budget config setting, a warning lists the largest merged items;
+--strict-merge-budget fails the installation instead.
+
+When the installation would rewrite the managed section so that it gets
+shorter or loses headings, a diff of the section is shown and nothing is
+installed unless confirmed; the confirm_merge config setting turns this off
+per item type.
+
+Items with a project-local copy in .regis3/local/ (laid out like the
+registry) are installed from that copy instead of the registry.
+
+While authoring items, --link installs symbolic links to the registry
+files instead of copies, so edits show in the project at once; files the
+target rewrites (headers, inlined files) and encrypted bodies are still
+copied. Installing again without --link after an item changes copies it.
+
+Items may define named variants of their body (variants in the
+frontmatter); --variant installs that variant of the items that define it.
+Installed items keep their variant on later updates until another one is
+selected; --variant default goes back to the item's own body.
+
+Several targets may be given as a comma-separated --target list, or all of
+them with --all-targets; each target keeps its own record of installed
+items, and alternatives chosen for the first target are used for the rest.
 
 Examples:
   regis3 project add skill:git-conventions
+  regis3 project add git-conventions
   regis3 project add skill:git-conventions skill:clean-code
-  regis3 project add stack:vue-fullstack`,
+  regis3 project add stack:vue-fullstack
+  regis3 project add stack:web --choose skill:vitest-testing
+  regis3 project add skill:git-conventions --target claude,cursor
+  regis3 project add stack:web --explain-skips
+  regis3 project add skill:code-review --variant concise
+  regis3 project add skill:code-review --link
+  regis3 project add --from-file items.txt
+  cat items.txt | regis3 project add --from-file -
+
+An item list has one reference per line; blank lines and "#" comments are
+ignored, so curated lists can be kept in a repository.`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectAddFromFile != "" {
+			refs, err := readRefFile(projectAddFromFile)
+			if err != nil {
+				writer.Error(err.Error())
+				return err
+			}
+			if len(refs) == 0 && len(args) == 0 {
+				writer.Info(i18n.Sprintf("No items listed in %s", projectAddFromFile))
+				return nil
+			}
+			args = append(args, refs...)
+		}
+
 		// If no args provided, show interactive picker
 		if len(args) == 0 {
-			manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+			manifest, err := loadManifest()
 			if err != nil {
-				_, buildErr := registry.BuildRegistry(getRegistryPath())
-				if buildErr != nil {
-					writer.Error(fmt.Sprintf("Failed to load registry: %s", err.Error()))
-					return err
-				}
-				manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
-				if err != nil {
-					writer.Error(fmt.Sprintf("Failed to load manifest: %s", err.Error()))
-					return err
-				}
+				return err
+			}
+
+			targets, err := resolveTargets(projectAddTarget, projectAddAll)
+			if err != nil {
+				writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+				return err
 			}
 
-			selected, err := pickItemsToAdd(manifest)
+			selected, err := pickItemsToAdd(manifest, targets[0])
 			if err != nil {
-				writer.Error(fmt.Sprintf("Selection cancelled: %s", err.Error()))
+				writer.Error(i18n.Sprintf("Selection cancelled: %s", err.Error()))
 				return err
 			}
 
@@ -90,9 +162,14 @@ var projectRemoveCmd = &cobra.Command{
 	Short:   "Remove items from the current project",
 	Long: `Removes one or more installed items from the current project.
 
+Items that other installed items depend on, directly or through stacks,
+are not removed: --cascade removes the dependent items too, and --force
+removes the items anyway, with a warning.
+
 Examples:
   regis3 project remove skill:git-conventions
-  regis3 project rm skill:git-conventions skill:clean-code`,
+  regis3 project rm skill:git-conventions skill:clean-code
+  regis3 project remove skill:testing --cascade`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return fmt.Errorf("missing item reference\n\nUsage: regis3 project remove <type:name> [type:name...]\n\nExample: regis3 project remove skill:git-conventions")
@@ -108,11 +185,17 @@ Examples:
 var projectStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show items installed in the current project",
-	Long: `Shows all items currently installed in the current project.
+	Long: `Shows all items currently installed in the current project, with
+pending updates, items deleted from the registry, and installed files that
+are missing or were modified locally (drift).
+
+Use --all-targets to report every target with items installed in this
+project in one table.
 
 Examples:
   regis3 project status
-  regis3 project status --target claude`,
+  regis3 project status --target claude
+  regis3 project status --all-targets`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runProjectStatus()
 	},
@@ -121,13 +204,26 @@ Examples:
 func init() {
 	// Add flags
 	projectAddCmd.Flags().BoolVar(&projectAddDryRun, "dry-run", false, "Preview what would be installed")
+	projectAddCmd.Flags().BoolVar(&noReport, "no-report", false, "Don't report the installation to the webhook")
 	projectAddCmd.Flags().BoolVarP(&projectAddForce, "force", "F", false, "Force reinstall even if already installed")
-	projectAddCmd.Flags().StringVar(&projectAddTarget, "target", "", "Target (default: from config)")
+	projectAddCmd.Flags().StringVar(&projectAddTarget, "target", "", "Target, or comma-separated targets (default: from config, or detected from the project)")
+	projectAddCmd.Flags().BoolVar(&projectAddAll, "all-targets", false, "Install to every available target")
+	projectAddCmd.MarkFlagsMutuallyExclusive("target", "all-targets")
+	projectAddCmd.Flags().BoolVar(&projectAddExplain, "explain-skips", false, "Explain why each skipped item was skipped")
+	projectAddCmd.Flags().StringSliceVar(&projectAddChoose, "choose", nil, "Preferred alternative for stacks with one_of (repeatable)")
+	projectAddCmd.Flags().BoolVar(&projectAddScripts, "allow-scripts", false, "Run item setup scripts without asking")
+	projectAddCmd.Flags().BoolVar(&projectAddStrict, "strict-merge-budget", false, "Fail if the merge file exceeds the configured merge_budget")
+	projectAddCmd.Flags().StringVar(&projectAddFromFile, "from-file", "", "Read item references from a file, one per line (- for stdin)")
+	projectAddCmd.Flags().BoolVar(&projectAddLink, "link", false, "Link installed files to the registry instead of copying them, for authoring")
+	projectAddCmd.Flags().StringVar(&projectAddVariant, "variant", "", "Install the named variant of items that define it (default: the item's own body)")
 
 	projectRemoveCmd.Flags().BoolVar(&projectRemoveDryRun, "dry-run", false, "Preview what would be removed")
-	projectRemoveCmd.Flags().StringVar(&projectRemoveTarget, "target", "", "Target (default: from config)")
+	projectRemoveCmd.Flags().StringVar(&projectRemoveTarget, "target", "", "Target (default: from config, or detected from the project)")
+	projectRemoveCmd.Flags().BoolVarP(&projectRemoveForce, "force", "F", false, "Remove items even if installed items depend on them")
+	projectRemoveCmd.Flags().BoolVar(&projectRemoveCascade, "cascade", false, "Also remove the installed items that depend on the removed ones")
 
-	projectStatusCmd.Flags().StringVar(&projectStatusTarget, "target", "", "Target (default: from config)")
+	projectStatusCmd.Flags().StringVar(&projectStatusTarget, "target", "", "Target (default: from config, or detected from the project)")
+	projectStatusCmd.Flags().BoolVar(&projectStatusAll, "all-targets", false, "Report every target installed in this project")
 
 	// Add subcommands to project
 	projectCmd.AddCommand(projectAddCmd)
@@ -138,104 +234,266 @@ func init() {
 	rootCmd.AddCommand(projectCmd)
 }
 
-func runProjectAdd(refs []string) error {
-	// Validate references
-	for _, ref := range refs {
-		if !strings.Contains(ref, ":") {
-			writer.Error(fmt.Sprintf("Invalid reference '%s' - use format 'type:name'", ref))
-			return fmt.Errorf("invalid reference: %s", ref)
-		}
+func runProjectAdd(args []string) error {
+	// Load manifest
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
 	}
 
-	// Load manifest
-	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	// Resolve shorthand references and aliases
+	ids, notices, err := resolveRefs(manifest, args)
 	if err != nil {
-		_, buildErr := registry.BuildRegistry(getRegistryPath())
-		if buildErr != nil {
-			writer.Error(fmt.Sprintf("Failed to load registry: %s", err.Error()))
-			return err
-		}
-		manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
-		if err != nil {
-			writer.Error(fmt.Sprintf("Failed to load manifest: %s", err.Error()))
-			return err
-		}
+		writer.Error(err.Error())
+		return fmt.Errorf("item not found")
 	}
 
-	// Get target
-	targetName := projectAddTarget
-	if targetName == "" && cfg != nil {
-		targetName = cfg.DefaultTarget
+	if err := checkVariant(manifest, ids, projectAddVariant); err != nil {
+		writer.Error(err.Error())
+		return &exitError{code: 1, message: "unknown variant"}
 	}
-	if targetName == "" {
-		targetName = "claude"
+
+	// Get targets
+	targets, err := resolveTargets(projectAddTarget, projectAddAll)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
 	}
 
-	// Get target config
-	var target *installer.Target
-	if targetName == "claude" {
-		target = installer.DefaultClaudeTarget()
-	} else {
-		target, err = installer.LoadTargetByName("targets", targetName)
+	if !projectAddDryRun {
+		release, err := guardProject("project add")
 		if err != nil {
-			writer.Error(fmt.Sprintf("Target not found: %s", err.Error()))
 			return err
 		}
+		defer release()
+	}
+
+	if len(targets) > 1 {
+		return addToTargets(manifest, ids, notices, targets)
 	}
+	target := targets[0]
 
 	// Create installer
-	inst, err := installer.NewInstaller(".", getRegistryPath(), target)
+	inst, err := projectAddInstaller(target, projectAddChoose)
 	if err != nil {
-		writer.Error(fmt.Sprintf("Installer error: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
 		return err
 	}
-	inst.DryRun = projectAddDryRun
-	inst.Force = projectAddForce
 
 	// Install items
-	result, err := inst.Install(manifest, refs)
+	result, err := inst.Install(manifest, ids)
+	if errors.Is(err, installer.ErrMergeDeclined) {
+		writer.Info(i18n.Sprintf("Installation cancelled, %s left unchanged", target.MergeFile))
+		return nil
+	}
 	if err != nil {
-		writer.Error(fmt.Sprintf("Installation failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Installation failed: %s", err.Error()))
+		if result != nil && result.MergeBudget != nil {
+			writer.Info(i18n.Sprintf("Move detailed content into skill files, which load on demand, to shrink %s", target.MergeFile))
+			return fmt.Errorf("merge budget exceeded")
+		}
 		return err
 	}
 
-	// Build response
+	resp := installResponse("project add", result, target, notices, projectAddDryRun)
+	reportInstall(resp, "project add", target, result, projectAddDryRun)
+	if projectAddExplain {
+		explainSkips(resp, result)
+	}
+	for _, id := range inst.Overrides.Unmatched(manifest) {
+		resp.WithWarning("%s in %s overrides no registry item", id, installer.LocalDir)
+	}
+	writer.Write(resp.Build())
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("installation failed")
+	}
+	return nil
+}
+
+// checkVariant returns an error if a variant is selected that none of the
+// given items or their dependencies defines.
+func checkVariant(manifest *registry.Manifest, ids []string, variant string) error {
+	if variant == "" || variant == registry.DefaultVariant {
+		return nil
+	}
+	graph := resolver.NewResolverWithOptions(manifest, resolverOptions(nil)).Graph()
+	for _, id := range ids {
+		for _, id := range append([]string{id}, graph.AllDependencies(id)...) {
+			item, ok := manifest.Items[id]
+			if !ok {
+				continue
+			}
+			if _, ok := item.Variants[variant]; ok {
+				return nil
+			}
+		}
+	}
+	return errors.New(i18n.Sprintf("No item to install defines variant %q", variant))
+}
+
+// projectAddInstaller creates the installer for project add with the
+// command's flags applied.
+func projectAddInstaller(target *installer.Target, choose []string) (*installer.Installer, error) {
+	inst, err := newInstaller(target)
+	if err != nil {
+		return nil, err
+	}
+	inst.DryRun = projectAddDryRun
+	inst.Force = projectAddForce
+	inst.ResolverOptions = resolverOptions(choose)
+	inst.Timings = timings()
+	inst.AllowScripts = projectAddScripts
+	inst.StrictMergeBudget = projectAddStrict
+	inst.Variant = projectAddVariant
+	inst.Link = projectAddLink
+	inst.Explicit = true
+	if cfg != nil {
+		inst.MergeBudget = cfg.MergeBudgetSize()
+	}
+	if isInteractive() {
+		inst.ConfirmScript = confirmSetupScript
+		inst.ConfirmMerge = confirmMergeChange
+	}
+	return inst, nil
+}
+
+// addToTargets installs the items to each target in turn and reports the
+// results together, each message prefixed with its target. A target that
+// fails doesn't stop the others.
+func addToTargets(manifest *registry.Manifest, ids, notices []string, targets []*installer.Target) error {
+	data := output.TargetsInstallData{Targets: []output.InstallData{}}
+	resp := output.NewResponse("project add", data)
+	for _, notice := range notices {
+		resp.WithWarning(notice)
+	}
+
+	results := make(map[string]*installer.InstallResult)
+	choose := append([]string{}, projectAddChoose...)
+	failed := false
+	for _, target := range targets {
+		inst, err := projectAddInstaller(target, choose)
+		if err != nil {
+			resp.WithMessage(output.LevelError, target.Name+": "+i18n.Sprintf("Installer error: %s", err.Error()))
+			failed = true
+			continue
+		}
+		result, err := inst.Install(manifest, ids)
+		if errors.Is(err, installer.ErrMergeDeclined) {
+			resp.WithInfo(target.Name + ": " + i18n.Sprintf("Installation cancelled, %s left unchanged", target.MergeFile))
+			continue
+		}
+		if err != nil {
+			resp.WithMessage(output.LevelError, target.Name+": "+i18n.Sprintf("Installation failed: %s", err.Error()))
+			failed = true
+			continue
+		}
+		results[target.Name] = result
+		// Later targets get the alternatives chosen for this one
+		for _, choice := range result.Choices {
+			choose = append(choose, choice)
+		}
+
+		builder := installResponse("project add", result, target, nil, projectAddDryRun)
+		reportInstall(builder, "project add", target, result, projectAddDryRun)
+		if projectAddExplain {
+			explainSkips(builder, result)
+		}
+		targetResp := builder.Build()
+		if d, ok := targetResp.Data.(output.InstallData); ok {
+			data.Targets = append(data.Targets, d)
+		}
+		for _, m := range targetResp.Messages {
+			m.Text = target.Name + ": " + m.Text
+			resp.Messages = append(resp.Messages, m)
+		}
+	}
+
+	combined := installer.CombineResults(results)
+	resp.Data = data
+	resp.Success = !failed && len(combined.Errors) == 0
+	changed := 0
+	for _, result := range results {
+		if len(result.Installed)+len(result.Updated) > 0 {
+			changed++
+		}
+	}
+	if resp.Success && !projectAddDryRun && changed > 1 {
+		resp.WithInfo(i18n.Sprintf("Installed %d items to %d targets", len(combined.Installed)+len(combined.Updated), changed))
+	}
+	writer.Write(resp)
+
+	if !resp.Success {
+		return fmt.Errorf("installation failed")
+	}
+	return nil
+}
+
+// reportLocalChanges reports what an install did with local changes to the
+// files it updated.
+func reportLocalChanges(resp *output.ResponseBuilder, result *installer.InstallResult) {
+	for _, id := range result.LocalMerged {
+		resp.WithInfo("Merged local changes to %s with the update", id)
+	}
+	for _, id := range result.Conflicts {
+		resp.WithWarning("Local changes to %s conflict with the update; resolve the conflict markers in its file", id)
+	}
+	for _, id := range result.KeptLocal {
+		resp.WithWarning("Kept local changes to %s without updating it (use --force to overwrite them)", id)
+	}
+}
+
+// installResponse builds the response reporting an installation's result.
+func installResponse(command string, result *installer.InstallResult, target *installer.Target, notices []string, dryRun bool) *output.ResponseBuilder {
 	var installed []output.InstalledItem
 	for _, id := range result.Installed {
-		parts := strings.SplitN(id, ":", 2)
-		if len(parts) == 2 {
-			installed = append(installed, output.InstalledItem{
-				Type: parts[0],
-				Name: parts[1],
-			})
+		if itemType, name, ok := refs.Split(id); ok {
+			installed = append(installed, output.InstalledItem{Type: itemType, Name: name, Variant: result.Variants[id]})
 		}
 	}
 	for _, id := range result.Updated {
-		parts := strings.SplitN(id, ":", 2)
-		if len(parts) == 2 {
-			installed = append(installed, output.InstalledItem{
-				Type: parts[0],
-				Name: parts[1],
-			})
+		if itemType, name, ok := refs.Split(id); ok {
+			installed = append(installed, output.InstalledItem{Type: itemType, Name: name, Variant: result.Variants[id]})
 		}
 	}
 
-	resp := output.NewResponseBuilder("project add").
+	var deprecated []output.DeprecatedItem
+	for id, replacement := range result.Deprecated {
+		deprecated = append(deprecated, output.DeprecatedItem{ID: id, ReplacedBy: replacement})
+	}
+	sort.Slice(deprecated, func(i, j int) bool { return deprecated[i].ID < deprecated[j].ID })
+
+	resp := output.NewResponseBuilder(command).
 		WithData(output.InstallData{
-			Installed: installed,
-			Skipped:   result.Skipped,
-			Target:    targetName,
-			DryRun:    projectAddDryRun,
+			Installed:           installed,
+			Skipped:             result.Skipped,
+			Target:              target.Name,
+			DryRun:              dryRun,
+			DeprecatedInstalled: deprecated,
+			LocalMerged:         result.LocalMerged,
+			Conflicts:           result.Conflicts,
+			KeptLocal:           result.KeptLocal,
+			Linked:              result.Linked,
+			NotLinked:           result.NotLinked,
 		})
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+	for _, d := range deprecated {
+		resp.WithWarning("%s", deprecationNotice(d.ID, d.ReplacedBy))
+	}
 
 	if len(result.Errors) > 0 {
 		resp.WithSuccess(false)
 		for _, e := range result.Errors {
 			resp.WithError(e.ItemID, e.Message)
 		}
+		if result.RolledBack {
+			resp.WithInfo("Nothing was installed: the installation was rolled back")
+		}
 	} else {
 		resp.WithSuccess(true)
-		if projectAddDryRun {
+		if dryRun {
 			resp.WithInfo("Would install %d items (dry run)", len(installed))
 		} else if len(installed) > 0 {
 			resp.WithInfo("Installed %d items to project", len(installed))
@@ -243,66 +501,132 @@ func runProjectAdd(refs []string) error {
 		if len(result.Skipped) > 0 {
 			resp.WithInfo("Skipped %d already installed", len(result.Skipped))
 		}
+		for _, id := range result.Pinned {
+			resp.WithInfo("Kept pinned %s (use --force or 'regis3 project unpin' to update it)", id)
+		}
+		reportLocalChanges(resp, result)
+		if len(result.Linked) > 0 {
+			resp.WithInfo("Linked %d items to their registry files", len(result.Linked))
+		}
+		notLinked := make([]string, 0, len(result.NotLinked))
+		for id := range result.NotLinked {
+			notLinked = append(notLinked, id)
+		}
+		sort.Strings(notLinked)
+		for _, id := range notLinked {
+			resp.WithWarning("Copied %s instead of linking it: %s", id, result.NotLinked[id])
+		}
 		if len(result.MergedItems) > 0 {
 			resp.WithInfo("Merged %d items into %s", len(result.MergedItems), target.MergeFile)
 		}
+		for _, id := range result.Local {
+			resp.WithInfo("Used the local override of %s from %s", id, installer.LocalDir)
+		}
+		for _, id := range result.Scripts {
+			resp.WithInfo("Ran setup script for %s", id)
+		}
+		for _, id := range result.SkippedScripts {
+			resp.WithWarning("Skipped setup script for %s (use --allow-scripts to run it)", id)
+		}
+		if result.MergeChange != nil {
+			resp.WithWarning("%s", result.MergeChange.Summary())
+		}
+		if result.MergeBudget != nil {
+			resp.WithWarning("%s", result.MergeBudget.Error())
+			resp.WithInfo("Move detailed content into skill files, which load on demand, to shrink %s", target.MergeFile)
+		}
+		stacks := make([]string, 0, len(result.Choices))
+		for stack := range result.Choices {
+			stacks = append(stacks, stack)
+		}
+		sort.Strings(stacks)
+		for _, stack := range stacks {
+			resp.WithInfo("Chose %s for %s", result.Choices[stack], stack)
+		}
 	}
 
-	writer.Write(resp.Build())
-
-	if len(result.Errors) > 0 {
-		return fmt.Errorf("installation failed")
-	}
-	return nil
+	return resp
 }
 
-func runProjectRemove(refs []string) error {
+func runProjectRemove(args []string) error {
 	// Get target
-	targetName := projectRemoveTarget
-	if targetName == "" && cfg != nil {
-		targetName = cfg.DefaultTarget
-	}
-	if targetName == "" {
-		targetName = "claude"
+	target, err := resolveTarget(projectRemoveTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
 	}
 
-	// Get target config
-	var target *installer.Target
-	var err error
-	if targetName == "claude" {
-		target = installer.DefaultClaudeTarget()
-	} else {
-		target, err = installer.LoadTargetByName("targets", targetName)
+	if !projectRemoveDryRun {
+		release, err := guardProject("project remove")
 		if err != nil {
-			writer.Error(fmt.Sprintf("Target not found: %s", err.Error()))
 			return err
 		}
+		defer release()
 	}
 
 	// Create installer
-	inst, err := installer.NewInstaller(".", getRegistryPath(), target)
+	inst, err := newInstaller(target)
 	if err != nil {
-		writer.Error(fmt.Sprintf("Installer error: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
 		return err
 	}
 	inst.DryRun = projectRemoveDryRun
 
+	ids, notices := resolveInstalledRefs(inst.Tracker, args)
+
+	// Merged items can only be removed with the manifest at hand
+	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	if err != nil {
+		debugf("uninstalling without manifest: %v", err)
+		manifest = nil
+	}
+
+	// Items others depend on are only removed with --cascade or --force
+	var dependents map[string][]string
+	if manifest != nil {
+		dependents, err = inst.InstalledDependents(ids, manifest)
+		if err != nil {
+			writer.Error(err.Error())
+			return err
+		}
+	}
+	needed := make([]string, 0, len(dependents))
+	for id := range dependents {
+		needed = append(needed, id)
+	}
+	sort.Strings(needed)
+	if len(needed) > 0 && !projectRemoveCascade && !projectRemoveForce {
+		resp := output.NewResponseBuilder("project remove").WithSuccess(false)
+		for _, id := range needed {
+			resp.WithError(id, i18n.Sprintf("%s is needed by %s (use --cascade to remove them too, or --force to remove it anyway)", id, strings.Join(dependents[id], ", ")))
+		}
+		writer.Write(resp.Build())
+		return &exitError{code: 1, message: "items are needed by installed items"}
+	}
+	var cascaded []string
+	if projectRemoveCascade {
+		for _, id := range needed {
+			for _, dependent := range dependents[id] {
+				if !slices.Contains(ids, dependent) {
+					ids = append(ids, dependent)
+					cascaded = append(cascaded, dependent)
+				}
+			}
+		}
+	}
+
 	// Uninstall items
-	result, err := inst.Uninstall(refs)
+	result, err := inst.Uninstall(ids, manifest)
 	if err != nil {
-		writer.Error(fmt.Sprintf("Uninstall failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Uninstall failed: %s", err.Error()))
 		return err
 	}
 
 	// Build response
 	var removed []output.InstalledItem
 	for _, id := range result.Uninstalled {
-		parts := strings.SplitN(id, ":", 2)
-		if len(parts) == 2 {
-			removed = append(removed, output.InstalledItem{
-				Type: parts[0],
-				Name: parts[1],
-			})
+		if itemType, name, ok := refs.Split(id); ok {
+			removed = append(removed, output.InstalledItem{Type: itemType, Name: name})
 		}
 	}
 
@@ -311,7 +635,11 @@ func runProjectRemove(refs []string) error {
 			Removed:  removed,
 			NotFound: result.NotFound,
 			DryRun:   projectRemoveDryRun,
+			Paths:    result.Paths,
 		})
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
 
 	if len(result.Errors) > 0 {
 		resp.WithSuccess(false)
@@ -320,6 +648,14 @@ func runProjectRemove(refs []string) error {
 		}
 	} else {
 		resp.WithSuccess(true)
+		for _, id := range cascaded {
+			resp.WithInfo("%s depends on the removed items and is removed too", id)
+		}
+		if !projectRemoveCascade {
+			for _, id := range needed {
+				resp.WithWarning("%s is removed though installed items depend on it: %s", id, strings.Join(dependents[id], ", "))
+			}
+		}
 		if projectRemoveDryRun {
 			resp.WithInfo("Would remove %d items (dry run)", len(removed))
 		} else if len(removed) > 0 {
@@ -331,6 +667,11 @@ func runProjectRemove(refs []string) error {
 		if len(result.Skipped) > 0 {
 			resp.WithWarning("Skipped %d merged items (edit %s manually)", len(result.Skipped), target.MergeFile)
 		}
+		if manifest != nil && !projectRemoveDryRun {
+			if unneeded, err := inst.Unneeded(manifest); err == nil && len(unneeded) > 0 {
+				resp.WithInfo("%d dependencies are no longer needed (run 'regis3 project autoremove' to remove them)", len(unneeded))
+			}
+		}
 	}
 
 	writer.Write(resp.Build())
@@ -342,40 +683,57 @@ func runProjectRemove(refs []string) error {
 }
 
 func runProjectStatus() error {
+	if projectStatusAll {
+		return runStatusAllTargets("project status")
+	}
+
 	// Get target
-	targetName := projectStatusTarget
-	if targetName == "" && cfg != nil {
-		targetName = cfg.DefaultTarget
+	target, reason, err := chooseTarget(projectStatusTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
 	}
-	if targetName == "" {
-		targetName = "claude"
+
+	data, err := targetStatus(target, loadStatusManifest())
+	if err != nil {
+		writer.Error(i18n.Sprintf("Error: %s", err.Error()))
+		return err
 	}
+	data.TargetReason = reason
+	items := data.Items
 
-	// Get target config
-	var target *installer.Target
-	var err error
-	if targetName == "claude" {
-		target = installer.DefaultClaudeTarget()
+	resp := output.NewResponseBuilder("project status").
+		WithSuccess(true).
+		WithData(data)
+
+	if len(items) == 0 {
+		resp.WithInfo("No items installed in this project")
 	} else {
-		target, err = installer.LoadTargetByName("targets", targetName)
-		if err != nil {
-			writer.Error(fmt.Sprintf("Target not found: %s", err.Error()))
-			return err
-		}
+		resp.WithInfo("%d items installed in this project", len(items))
+		addStatusWarnings(resp, items, "")
 	}
 
-	// Load manifest (needed for status check)
+	writer.Write(resp.Build())
+	return nil
+}
+
+// loadStatusManifest loads the registry manifest for status checks. Without
+// a manifest, status still lists what the trackers record.
+func loadStatusManifest() *registry.Manifest {
 	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
 	if err != nil {
-		// If no manifest, just show tracker status
-		manifest = &registry.Manifest{Items: make(map[string]*registry.Item)}
+		return &registry.Manifest{Items: make(map[string]*registry.Item)}
 	}
+	return manifest
+}
 
+// targetStatus returns the installed items of a target in this project,
+// sorted by type and name.
+func targetStatus(target *installer.Target, manifest *registry.Manifest) (output.StatusData, error) {
 	// Create installer to access status
-	inst, err := installer.NewInstaller(".", getRegistryPath(), target)
+	inst, err := newInstaller(target)
 	if err != nil {
-		writer.Error(fmt.Sprintf("Error: %s", err.Error()))
-		return err
+		return output.StatusData{}, err
 	}
 
 	// Get status
@@ -385,46 +743,222 @@ func runProjectStatus() error {
 	var items []output.StatusItem
 	for _, s := range status.Items {
 		if s.Installed {
-			installedAt := ""
-			if s.InstalledAt != nil {
-				if t, ok := s.InstalledAt.(string); ok {
-					installedAt = t
-				}
-			}
 			items = append(items, output.StatusItem{
 				Type:        s.Type,
 				Name:        s.Name,
-				InstalledAt: installedAt,
+				InstalledAt: s.InstalledAt,
+				UpdatedAt:   s.UpdatedAt,
 				DestPath:    s.Path,
+				Merged:      s.Merged,
 				NeedsUpdate: s.NeedsUpdate,
+				Pinned:      s.Pinned,
+				Variant:     s.Variant,
+				Linked:      s.Linked,
+				Local:       s.Local,
+				Removed:     s.Removed,
+				Drift:       s.Drift,
 			})
 		}
 	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Type != items[j].Type {
+			return items[i].Type < items[j].Type
+		}
+		return items[i].Name < items[j].Name
+	})
 
-	resp := output.NewResponseBuilder("project status").
-		WithSuccess(true).
-		WithData(output.StatusData{
-			Items:  items,
-			Target: targetName,
-		})
+	return output.StatusData{Items: items, Target: target.Name}, nil
+}
 
-	if len(items) == 0 {
-		resp.WithInfo("No items installed in this project")
-	} else {
-		resp.WithInfo("%d items installed in this project", len(items))
+// addStatusWarnings adds warnings for pending updates, items deleted
+// upstream and drifted files. A non-empty target prefixes each warning.
+func addStatusWarnings(resp *output.ResponseBuilder, items []output.StatusItem, target string) {
+	prefix := ""
+	if target != "" {
+		prefix = target + ": "
+	}
+
+	// Check for updates
+	updateCount, pinnedCount := 0, 0
+	for _, item := range items {
+		if item.NeedsUpdate && item.Pinned {
+			pinnedCount++
+		} else if item.NeedsUpdate {
+			updateCount++
+		}
+	}
+	if updateCount > 0 {
+		resp.WithWarning("%s%d items have updates available", prefix, updateCount)
+	}
+	if pinnedCount > 0 {
+		resp.WithInfo("%s%d pinned items have updates available", prefix, pinnedCount)
+	}
+
+	// Check for items deleted upstream
+	for _, item := range items {
+		if item.Removed {
+			resp.WithWarning("%s%s:%s no longer exists in the registry", prefix, item.Type, item.Name)
+		}
+	}
+
+	// Check for files changed in the project
+	for _, item := range items {
+		switch item.Drift {
+		case installer.DriftMissing:
+			resp.WithWarning("%s%s:%s is missing from %s", prefix, item.Type, item.Name, item.DestPath)
+		case installer.DriftModified:
+			resp.WithWarning("%s%s:%s was modified locally (%s)", prefix, item.Type, item.Name, item.DestPath)
+		}
+	}
+}
+
+// resolveInstalledRefs resolves shorthand references and aliases of renamed
+// items through the registry, unless the reference itself is installed. It
+// returns the item IDs and alias notices; references the registry doesn't
+// know are kept as given.
+func resolveInstalledRefs(tracker *installer.Tracker, refs []string) ([]string, []string) {
+	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	if err != nil {
+		return refs, nil
+	}
 
-		// Check for updates
-		updateCount := 0
-		for _, item := range items {
-			if item.NeedsUpdate {
-				updateCount++
+	var notices []string
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		if id, err := manifest.ResolveRef(ref); err == nil && tracker.GetInstalled(ref) == nil {
+			if isAlias(manifest, ref, id) {
+				notices = append(notices, aliasNotice(ref, id))
 			}
+			ref = id
 		}
-		if updateCount > 0 {
-			resp.WithWarning("%d items have updates available", updateCount)
+		ids[i] = ref
+	}
+	return ids, notices
+}
+
+// resolveTarget returns the target named by flag, falling back to the
+// configured default target and then to claude.
+func resolveTarget(flag string) (*installer.Target, error) {
+	target, reason, err := chooseTarget(flag)
+	if err == nil {
+		debugf("Target %s: %s", target.Name, reason)
+	}
+	return target, err
+}
+
+// resolveTargets returns the targets named by a comma-separated flag, or
+// every available target with all. Without either it returns the single
+// target resolveTarget picks.
+func resolveTargets(flag string, all bool) ([]*installer.Target, error) {
+	if all {
+		return availableTargets(), nil
+	}
+	if !strings.Contains(flag, ",") {
+		target, err := resolveTarget(flag)
+		if err != nil {
+			return nil, err
 		}
+		return []*installer.Target{target}, nil
 	}
 
-	writer.Write(resp.Build())
-	return nil
+	var targets []*installer.Target
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(flag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		target, err := loadTarget(name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets in %q", flag)
+	}
+	return targets, nil
+}
+
+// chooseTarget resolves the target like resolveTarget and explains why it
+// was chosen. With default_target set to auto (or unset), the target is
+// detected from the tool directories in the current project.
+func chooseTarget(flag string) (*installer.Target, string, error) {
+	if flag != "" {
+		target, err := loadTarget(flag)
+		return target, "set by --target", err
+	}
+	if cfg != nil && cfg.DefaultTarget != "" && cfg.DefaultTarget != "auto" {
+		target, err := loadTarget(cfg.DefaultTarget)
+		return target, "set by default_target in config", err
+	}
+
+	target, reason := installer.DetectTarget(".", availableTargets())
+	return target, reason, nil
+}
+
+// availableTargets returns the built-in targets followed by the loadable
+// definitions in the targets directory, by name.
+func availableTargets() []*installer.Target {
+	names, err := installer.ListAvailableTargets("targets")
+	if err != nil {
+		debugf("Could not list targets: %s", err)
+	}
+	sort.Strings(names)
+
+	targets := installer.BuiltinTargets()
+	for _, name := range names {
+		if _, ok := installer.BuiltinTarget(name); ok {
+			continue
+		}
+		target, err := loadTarget(name)
+		if err != nil {
+			debugf("Skipping target %s: %s", name, err)
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// loadTarget returns a built-in target or loads a target definition from
+// the targets directory.
+func loadTarget(name string) (*installer.Target, error) {
+	if target, ok := installer.BuiltinTarget(name); ok {
+		return target, nil
+	}
+	return installer.LoadTargetByName("targets", name)
+}
+
+// newInstaller creates an installer for the current project that installs
+// the project's local overrides in place of their registry items and
+// records the registry's URL in the lockfile when it is remote.
+func newInstaller(target *installer.Target) (*installer.Installer, error) {
+	inst, err := installer.NewInstaller(".", getRegistryPath(), target)
+	if err != nil {
+		return nil, err
+	}
+	inst.Overrides, err = installer.LoadOverrides(".", buildOptions().Dialect)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		inst.RegistryURL = cfg.RegistryURL
+	}
+	return inst, nil
+}
+
+// readRefFile reads an item list from path, or from stdin if path is "-".
+func readRefFile(path string) ([]string, error) {
+	if path == "-" {
+		return registry.ReadRefList(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open item list: %w", err)
+	}
+	defer f.Close()
+	return registry.ReadRefList(f)
 }
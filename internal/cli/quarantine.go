@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var quarantineOpen bool
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine [type:name|path...]",
+	Short: "List items left out of the manifest because of validation errors",
+	Long: `Lists the items that 'regis3 build --allow-errors' left out of the
+manifest because they, or items they depend on, failed validation, with
+their errors. Quarantined items can't be installed until they are fixed and
+the registry is rebuilt.
+
+Name items by reference or source path to list only those. --open opens
+their source files in $VISUAL or $EDITOR, or with the system's default
+application when neither is set. With --format quiet, the source paths are
+listed one per line.
+
+Examples:
+  regis3 quarantine
+  regis3 quarantine skill:broken --open
+  regis3 quarantine -f quiet`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runQuarantine(args)
+	},
+}
+
+func init() {
+	quarantineCmd.Flags().BoolVar(&quarantineOpen, "open", false, "Open the source files of the items in your editor")
+	rootCmd.AddCommand(quarantineCmd)
+}
+
+func runQuarantine(args []string) error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	quarantined := manifest.Quarantine
+	if len(args) > 0 {
+		quarantined = nil
+		for _, ref := range args {
+			q, ok := manifest.GetQuarantined(ref)
+			if !ok {
+				writer.Error(i18n.Sprintf("%s is not quarantined", ref))
+				return fmt.Errorf("item not quarantined")
+			}
+			quarantined = append(quarantined, *q)
+		}
+	}
+
+	data := &output.QuarantineData{Items: []output.QuarantinedItem{}}
+	for _, q := range quarantined {
+		data.Items = append(data.Items, output.QuarantinedItem{ID: q.ID, Path: q.Path, Errors: q.Errors})
+	}
+
+	resp := output.NewResponseBuilder("quarantine").WithSuccess(true).WithData(data)
+	if quarantineOpen {
+		for _, q := range quarantined {
+			path := filepath.Join(getRegistryPath(), q.Path)
+			if err := openFile(path); err != nil {
+				writer.Error(i18n.Sprintf("Failed to open %s: %s", path, err.Error()))
+				return err
+			}
+		}
+	}
+	if len(quarantined) > 0 {
+		resp.WithInfo("Fix the files and run 'regis3 build' to add the items back (with --allow-errors while others remain broken)")
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
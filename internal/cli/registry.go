@@ -0,0 +1,516 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/config"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/okto-digital/regis3/pkg/refs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	registryAddName        string
+	registryHealthBadge    string
+	registryStaleDays      int
+	registryImpactProjects []string
+	registryTrendsLast     int
+)
+
+// registryCmd groups commands about the registry itself
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Inspect the registry",
+	Long: `Commands about the registry as a whole.
+
+Examples:
+  regis3 registry add git@github.com:org/registry.git
+  regis3 registry health
+  regis3 registry stale --days 180
+  regis3 registry impact skill:testing
+  regis3 registry trends`,
+}
+
+var registryAddCmd = &cobra.Command{
+	Use:   "add <path-or-url>",
+	Short: "Add a registry",
+	Long: `Adds a registry to the registries in the config, named after its
+directory or repository (or --name). build --all builds it together with
+registry_path, and --registry <name> uses it for any command.
+
+Registries given as a git URL are cloned into ~/.regis3/cache/registries
+and built; 'regis3 --registry <name> update' fetches them again. A git URL
+in registry_path works the same way and is cloned on first use.
+
+Examples:
+  regis3 registry add git@github.com:org/registry.git
+  regis3 registry add https://github.com/org/registry.git --name team
+  regis3 registry add ~/team-registry`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryAdd(args[0])
+	},
+}
+
+var registryRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a registry",
+	Long: `Removes a registry from the config. The clone of a remote registry is
+deleted; local registries are left as they are.
+
+Examples:
+  regis3 registry remove team`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryRemove(args[0])
+	},
+}
+
+var registryHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Show the registry health score",
+	Long: `Shows the health score computed by the last build, from 0 to 100, and
+the items that lower it:
+
+- items with validation warnings
+- items without tags
+- items without an author
+- drafts unchanged for 90 days
+- markdown files no item uses (orphans)
+
+--badge writes the score as an SVG badge for the registry's README.
+
+Examples:
+  regis3 registry health
+  regis3 registry health --badge health.svg`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryHealth()
+	},
+}
+
+var registryStaleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "Find items that haven't changed in a long time",
+	Long: `Lists items whose source and additional files haven't changed in --days
+days and that are marked draft or that no other item depends on, so curators
+can prune or update abandoned content.
+
+Change times come from the registry's git history. Files git doesn't track,
+or registries outside a git repository, use file modification times.
+
+Examples:
+  regis3 registry stale
+  regis3 registry stale --days 90 --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if registryStaleDays < 1 {
+			return fmt.Errorf("--days must be at least 1")
+		}
+		return runRegistryStale()
+	},
+}
+
+var registryImpactCmd = &cobra.Command{
+	Use:   "impact <item>",
+	Short: "Show what renaming or deleting an item would break",
+	Long: `Lists the registry items that depend on an item (directly, through other
+items, or as a stack alternative) and the workspace projects that have the
+item or one of those dependents installed. Run it before renaming or deleting
+an item: those projects can no longer update the affected items.
+
+Projects come from the workspace setting in the config, a list of project
+directories or globs (e.g. ~/code/*). --projects checks other directories
+instead.
+
+Examples:
+  regis3 registry impact skill:testing
+  regis3 registry impact testing --projects ~/code/api,~/code/web`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryImpact(args[0])
+	},
+}
+
+var registryTrendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "Show how the registry changed over its builds",
+	Long: `Shows the item count, items with validation warnings, content size and
+health score of the last builds, and how they and the number of items of
+each type changed since the first of them.
+
+Every build records its statistics in .build/history.jsonl; builds that
+change nothing and filtered builds are not recorded.
+
+Examples:
+  regis3 registry trends
+  regis3 registry trends --last 0
+  regis3 registry trends --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if registryTrendsLast < 0 {
+			return fmt.Errorf("--last must not be negative")
+		}
+		return runRegistryTrends()
+	},
+}
+
+func init() {
+	registryAddCmd.Flags().StringVar(&registryAddName, "name", "", "Name of the registry (default: its directory or repository name)")
+	registryTrendsCmd.Flags().IntVar(&registryTrendsLast, "last", 10, "Number of builds to show (0 for all)")
+	registryImpactCmd.Flags().StringSliceVar(&registryImpactProjects, "projects", nil, "Check these project directories instead of the workspace")
+	registryHealthCmd.Flags().StringVar(&registryHealthBadge, "badge", "", "Write an SVG badge of the score to this file")
+	registryStaleCmd.Flags().IntVar(&registryStaleDays, "days", 180, "Flag items unchanged for this many days")
+
+	registryCmd.AddCommand(registryAddCmd)
+	registryCmd.AddCommand(registryRemoveCmd)
+	registryCmd.AddCommand(registryHealthCmd)
+	registryCmd.AddCommand(registryStaleCmd)
+	registryCmd.AddCommand(registryImpactCmd)
+	registryCmd.AddCommand(registryTrendsCmd)
+	rootCmd.AddCommand(registryCmd)
+}
+
+func runRegistryHealth() error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	// Manifests from older versions have no score yet
+	health := manifest.Health
+	if health == nil {
+		debugf("Manifest has no health score, rebuilding...")
+		result, err := buildRegistry()
+		if err != nil {
+			writer.Error(i18n.Sprintf("Build failed: %s", err.Error()))
+			return err
+		}
+		health = result.Manifest.Health
+	}
+	if health == nil {
+		writer.Error("Filtered builds have no health score (build without build.include or --only)")
+		return fmt.Errorf("no health score")
+	}
+
+	data := output.HealthData{
+		Score:       health.Score,
+		Items:       health.Items,
+		Warned:      nonNil(health.Warned),
+		Untagged:    nonNil(health.Untagged),
+		Unowned:     nonNil(health.Unowned),
+		StaleDrafts: nonNil(health.StaleDrafts),
+		Orphans:     nonNil(health.Orphans),
+	}
+
+	if registryHealthBadge != "" {
+		if err := os.WriteFile(registryHealthBadge, health.Badge(), 0644); err != nil {
+			writer.Error(i18n.Sprintf("Failed to write badge: %s", err.Error()))
+			return err
+		}
+		data.Badge = registryHealthBadge
+	}
+
+	resp := output.NewResponseBuilder("registry health").
+		WithSuccess(true).
+		WithData(&data)
+
+	writer.Write(resp.Build())
+	return nil
+}
+
+func runRegistryStale() error {
+	registryPath := getRegistryPath()
+	debugf("Looking for stale items in: %s", registryPath)
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -registryStaleDays)
+	report := registry.FindStale(registryPath, manifest, cutoff)
+
+	data := output.StaleData{
+		Items:        make([]output.StaleItem, 0, len(report.Items)),
+		Count:        len(report.Items),
+		ItemsScanned: len(manifest.Items),
+		Days:         registryStaleDays,
+		FromGit:      report.FromGit,
+	}
+	for _, item := range report.Items {
+		var reasons []string
+		if item.Draft {
+			reasons = append(reasons, "draft")
+		}
+		if item.Dependents == 0 {
+			reasons = append(reasons, "no dependents")
+		}
+		data.Items = append(data.Items, output.StaleItem{
+			ID:          item.ID,
+			Source:      item.Source,
+			LastChanged: item.LastChanged.Format(time.DateOnly),
+			Reasons:     reasons,
+		})
+	}
+
+	resp := output.NewResponseBuilder("registry stale").
+		WithSuccess(true).
+		WithData(&data)
+	if !report.FromGit {
+		resp.WithWarning("Change times are file modification times (the registry is not a git repository)")
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
+
+func runRegistryImpact(ref string) error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	// Deleted items are only known by their tombstone
+	id, err := manifest.ResolveRef(ref)
+	if err != nil {
+		if _, ok := manifest.GetTombstone(ref); !ok {
+			writer.Error(err.Error())
+			return err
+		}
+		id = ref
+	}
+
+	affected := map[string]bool{id: true}
+	dependents := resolver.NewResolverWithOptions(manifest, resolverOptions(nil)).Graph().AllDependents(id)
+	for _, item := range manifest.Items {
+		if slices.Contains(item.OneOf, id) && !slices.Contains(dependents, item.FullName()) {
+			dependents = append(dependents, item.FullName())
+		}
+	}
+	sort.Strings(dependents)
+	for _, dependent := range dependents {
+		affected[dependent] = true
+	}
+
+	projects := registryImpactProjects
+	if projects == nil && cfg != nil {
+		projects = cfg.WorkspaceProjects()
+	}
+
+	data := output.ImpactData{
+		Item:            id,
+		Dependents:      nonNil(dependents),
+		Projects:        []output.ProjectImpact{},
+		ProjectsScanned: len(projects),
+	}
+	resp := output.NewResponseBuilder("registry impact")
+	for _, project := range projects {
+		for _, target := range projectTargetsIn(project) {
+			tracker, err := installer.LoadTargetTracker(project, target)
+			if err != nil {
+				resp.WithWarning("%s: %s", project, err.Error())
+				continue
+			}
+			var items []string
+			for _, installed := range tracker.ListInstalled() {
+				if affected[installed] {
+					items = append(items, installed)
+				}
+			}
+			if len(items) > 0 {
+				sort.Strings(items)
+				data.Projects = append(data.Projects, output.ProjectImpact{Path: project, Target: target.Name, Items: items})
+			}
+		}
+	}
+
+	if len(projects) == 0 {
+		resp.WithWarning("No projects to check (set workspace in the config or use --projects)")
+	}
+
+	resp.WithSuccess(true).WithData(&data)
+	writer.Write(resp.Build())
+	return nil
+}
+
+func runRegistryTrends() error {
+	history, err := registry.LoadHistory(getRegistryPath())
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	data := output.TrendsData{
+		Builds:   []output.TrendBuild{},
+		Recorded: len(history),
+		Types:    []output.TypeTrend{},
+	}
+	if registryTrendsLast > 0 && len(history) > registryTrendsLast {
+		history = history[len(history)-registryTrendsLast:]
+	}
+	for _, entry := range history {
+		data.Builds = append(data.Builds, output.TrendBuild{
+			Time:   entry.Time,
+			Items:  entry.Items,
+			Warned: entry.Warned,
+			Size:   entry.Size,
+			Score:  entry.Score,
+		})
+	}
+
+	if len(history) > 0 {
+		first, last := history[0].Stats.ByType(), history[len(history)-1].Stats.ByType()
+		for _, itemType := range registry.ValidTypes {
+			t := output.TypeTrend{Type: string(itemType), First: first[string(itemType)], Last: last[string(itemType)]}
+			if t.First > 0 || t.Last > 0 {
+				data.Types = append(data.Types, t)
+			}
+		}
+	}
+
+	resp := output.NewResponseBuilder("registry trends").
+		WithSuccess(true).
+		WithData(&data)
+	writer.Write(resp.Build())
+	return nil
+}
+
+// nonNil returns list, or an empty list if it is nil, so JSON output has
+// arrays rather than null.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func runRegistryAdd(location string) error {
+	name := registryAddName
+	if name == "" {
+		if registry.IsRemote(location) {
+			name = registry.RemoteName(location)
+		} else {
+			name = strings.ToLower(filepath.Base(filepath.Clean(location)))
+		}
+	}
+	if !refs.IsRegistryName(name) || name == config.DefaultRegistryName {
+		writer.Error(i18n.Sprintf("Invalid registry name: %s (choose one with --name)", name))
+		return &exitError{code: 1, message: "invalid registry name"}
+	}
+
+	// Edit the config file as it is, without --registry or environment
+	// overrides
+	c, configPath, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Registries[name]; ok {
+		writer.Error(i18n.Sprintf("A registry named %s already exists", name))
+		return &exitError{code: 1, message: "registry exists"}
+	}
+
+	if registry.IsRemote(location) {
+		if err := registry.ValidateRemoteURL(location); err != nil {
+			writer.Error(err.Error())
+			return &exitError{code: 1, message: "invalid registry URL"}
+		}
+	} else {
+		abs, err := filepath.Abs(expandPath(location))
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			writer.Error(i18n.Sprintf("Registry not found: %s", location))
+			return &exitError{code: 1, message: "registry not found"}
+		}
+		location = abs
+	}
+	reg := config.NewNamedRegistry(name, location)
+
+	resp := output.NewResponseBuilder("registry")
+	data := output.RegistryAddData{Name: name, Location: location, Path: reg.Path, Remote: reg.URL != ""}
+	if reg.URL != "" {
+		if _, err := reg.Remote().Sync(); err != nil {
+			writer.Error(err.Error())
+			return err
+		}
+		resp.WithInfo("Cloned %s into %s", reg.URL, reg.Path)
+	}
+	result, err := registry.BuildRegistryWithOptions(reg.Path, registryBuildOptions(reg))
+	if err != nil {
+		writer.Error(i18n.Sprintf("Build failed: %s", err.Error()))
+		return err
+	}
+	data.ItemCount = len(result.Manifest.Items)
+	if result.Validation.HasErrors() {
+		resp.WithWarning("%s has validation errors; run 'regis3 --registry %s validate'", name, name)
+	}
+
+	if c.Registries == nil {
+		c.Registries = make(map[string]string)
+	}
+	c.Registries[name] = location
+	if err := saveConfigFile(c, configPath); err != nil {
+		return err
+	}
+
+	resp.WithSuccess(true).WithData(&data).WithInfo("Added registry %s (%d items)", name, data.ItemCount)
+	writer.Write(resp.Build())
+	return nil
+}
+
+func runRegistryRemove(name string) error {
+	c, configPath, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	location, ok := c.Registries[name]
+	if !ok {
+		writer.Error(i18n.Sprintf("No registry named %s", name))
+		return &exitError{code: 1, message: "registry not found"}
+	}
+	delete(c.Registries, name)
+	if err := saveConfigFile(c, configPath); err != nil {
+		return err
+	}
+
+	resp := output.NewResponseBuilder("registry").WithSuccess(true)
+	if remote := config.NewNamedRegistry(name, location).Remote(); remote != nil {
+		if err := os.RemoveAll(remote.Dir); err != nil {
+			resp.WithWarning("Could not delete the clone %s: %s", remote.Dir, err.Error())
+		}
+	}
+	resp.WithInfo("Removed registry %s", name)
+	writer.Write(resp.Build())
+	return nil
+}
+
+// cloneRemote clones a remote registry into the cache and builds it.
+func cloneRemote(reg config.NamedRegistry) error {
+	debugf("Cloning registry %s from %s", reg.Name, reg.URL)
+	if _, err := reg.Remote().Sync(); err != nil {
+		return err
+	}
+	if _, err := registry.BuildRegistryWithOptions(reg.Path, registryBuildOptions(reg)); err != nil {
+		return fmt.Errorf("failed to build %s: %w", reg.URL, err)
+	}
+	return nil
+}
+
+// registryBuildOptions returns the build options for a registry, with its
+// own staging directory.
+func registryBuildOptions(reg config.NamedRegistry) registry.BuildOptions {
+	opts := buildOptions()
+	if cfg != nil {
+		opts.StagingDir = cfg.StagingPathIn(reg.Path)
+	}
+	return opts
+}
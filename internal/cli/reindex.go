@@ -3,8 +3,8 @@ package cli
 import (
 	"fmt"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
-	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
@@ -32,9 +32,9 @@ func init() {
 func runReindex() error {
 	debugf("Reindexing registry: %s", getRegistryPath())
 
-	result, err := registry.BuildRegistry(getRegistryPath())
+	result, err := buildRegistry()
 	if err != nil {
-		writer.Error(fmt.Sprintf("Reindex failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Reindex failed: %s", err.Error()))
 		return err
 	}
 
@@ -45,6 +45,7 @@ func runReindex() error {
 		WithSuccess(true).
 		WithData(output.BuildData{
 			ItemCount:    itemCount,
+			Excluded:     len(result.Excluded),
 			ManifestPath: manifestPath,
 			Duration:     result.Duration.String(),
 		}).
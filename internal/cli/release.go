@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/okto-digital/regis3/internal/buildinfo"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/packaging"
+	"github.com/okto-digital/regis3/internal/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// Release command flags
+var (
+	releaseVersion   string
+	releaseChecksums string
+	releaseOutput    string
+)
+
+var releaseCmd = &cobra.Command{
+	Use:    "release",
+	Short:  "Release maintenance tasks",
+	Long:   `Commands used when publishing regis3 releases.`,
+	Hidden: true,
+}
+
+var releasePackagingCmd = &cobra.Command{
+	Use:   "packaging",
+	Short: "Generate Homebrew and Scoop packaging metadata",
+	Long: `Generates a Homebrew formula and a Scoop manifest for a release from its
+checksums.txt, so package manager metadata stays in sync with published
+archives.
+
+The version defaults to the version this binary was built with.
+
+Examples:
+  regis3 release packaging --checksums dist/checksums.txt
+  regis3 release packaging --version 1.2.3 --checksums checksums.txt --output packaging`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReleasePackaging()
+	},
+}
+
+func init() {
+	releasePackagingCmd.Flags().StringVar(&releaseVersion, "version", buildinfo.Version, "Release version")
+	releasePackagingCmd.Flags().StringVar(&releaseChecksums, "checksums", "dist/"+upgrade.ChecksumsFile, "Path to the release checksums file")
+	releasePackagingCmd.Flags().StringVarP(&releaseOutput, "output", "o", "packaging", "Output directory")
+	releaseCmd.AddCommand(releasePackagingCmd)
+	rootCmd.AddCommand(releaseCmd)
+}
+
+func runReleasePackaging() error {
+	checksums, err := os.ReadFile(releaseChecksums)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to read checksums: %s", err.Error()))
+		return err
+	}
+
+	release, err := packaging.NewRelease(releaseVersion, checksums)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	files, err := release.WriteFiles(releaseOutput)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	resp := output.NewResponseBuilder("release").
+		WithSuccess(true).
+		WithData(output.ReleaseData{
+			Version: release.Version,
+			Files:   files,
+		})
+	writer.Write(resp.Build())
+	return nil
+}
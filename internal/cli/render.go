@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var projectRenderTarget string
+
+// projectRenderCmd renders items into a single markdown document
+var projectRenderCmd = &cobra.Command{
+	Use:   "render [type:name...]",
+	Short: "Render the project's items into one markdown file",
+	Long: `Concatenates the registry content of items into one self-contained
+markdown document, for assistants without file-based configuration: paste
+it into a chat or a system prompt.
+
+Without arguments, the items installed in the current project (for any
+target) are rendered; otherwise the named items and their dependencies.
+Merged items (project, philosophy, ruleset) come first, in the order of the
+merge file, then the other items with dependencies before the items that
+need them. Each item is enclosed in <!-- regis3:item type:name --> and
+<!-- regis3:end type:name --> comments. Stacks have no content of their own,
+and additional files of an item are not included.
+
+--target none renders the content as is; a named target applies its
+content transforms (default: the project's target).
+
+Examples:
+  regis3 project render --target none -o ASSISTANT.md
+  regis3 project render stack:web --target none
+  regis3 project render -f json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectRender(args)
+	},
+}
+
+func init() {
+	projectRenderCmd.Flags().StringVar(&projectRenderTarget, "target", "", `Target whose transforms to apply, or "none" (default: from config, or detected from the project)`)
+	projectCmd.AddCommand(projectRenderCmd)
+}
+
+func runProjectRender(args []string) error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	var target *installer.Target
+	if projectRenderTarget != installer.NoneTarget {
+		if target, err = resolveTarget(projectRenderTarget); err != nil {
+			writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+			return err
+		}
+	}
+
+	var ids, prefer []string
+	if len(args) > 0 {
+		var notices []string
+		ids, notices, err = resolveRefs(manifest, args)
+		if err != nil {
+			writer.Error(err.Error())
+			return fmt.Errorf("item not found")
+		}
+		for _, notice := range notices {
+			debugf("%s", notice)
+		}
+	} else {
+		installed := make(map[string]bool)
+		for _, t := range projectTargets() {
+			tracker, err := installer.LoadTargetTracker(".", t)
+			if err != nil {
+				debugf("Could not load tracker for %s: %s", t.Name, err)
+				continue
+			}
+			for _, id := range tracker.ListInstalled() {
+				installed[id] = true
+			}
+		}
+		if len(installed) == 0 {
+			writer.Error("No items installed in this project; name the items to render")
+			return &exitError{code: 1, message: "nothing to render"}
+		}
+
+		var missing []string
+		for id := range installed {
+			if _, ok := manifest.Items[id]; ok {
+				ids = append(ids, id)
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			writer.Error(i18n.Sprintf("Installed items not in the registry: %s", strings.Join(missing, ", ")))
+			return &exitError{code: 1, message: "installed items not in the registry"}
+		}
+		sort.Strings(ids)
+		// Stacks offering alternatives resolve to the installed ones
+		prefer = ids
+	}
+
+	resolved, err := resolver.NewResolverWithOptions(manifest, resolverOptions(prefer)).Resolve(ids)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to resolve dependencies: %s", err.Error()))
+		return err
+	}
+	if len(resolved.Missing) > 0 {
+		writer.Error(i18n.Sprintf("Missing dependencies: %s", strings.Join(resolved.Missing, ", ")))
+		return &exitError{code: 1, message: "missing dependencies"}
+	}
+
+	content, err := installer.Render(resolved.Items, getRegistryPath(), target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to render: %s", err.Error()))
+		return err
+	}
+
+	data := output.RenderData{
+		Target:  installer.NoneTarget,
+		Items:   make([]string, 0, len(resolved.Items)),
+		Content: content,
+	}
+	if target != nil {
+		data.Target = target.Name
+	}
+	for _, item := range installer.RenderOrder(resolved.Items) {
+		data.Items = append(data.Items, item.FullName())
+	}
+
+	resp := output.NewResponseBuilder("project render").
+		WithSuccess(true).
+		WithData(&data)
+	writer.Write(resp.Build())
+	return nil
+}
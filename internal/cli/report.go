@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/report"
+)
+
+// noReport skips reporting an installation to the webhook.
+var noReport bool
+
+// reportInstall posts a summary of an installation to the webhook set in
+// report.url, adding a warning to resp if that fails. Dry runs,
+// installations that changed nothing and --no-report aren't reported.
+func reportInstall(resp *output.ResponseBuilder, command string, target *installer.Target, result *installer.InstallResult, dryRun bool) {
+	if noReport || dryRun || cfg == nil || cfg.Report.URL == "" || result == nil {
+		return
+	}
+	summary := report.NewSummary(command, ".", target.Name, result.Installed, result.Updated, result.MergedItems)
+	if summary.Empty() {
+		return
+	}
+	if err := report.NewClient(cfg.Report.URL).Send(summary); err != nil {
+		resp.WithWarning("Failed to report the installation: %s", err.Error())
+		return
+	}
+	debugf("Reported installation to %s", cfg.Report.URL)
+}
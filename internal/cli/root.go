@@ -5,7 +5,9 @@ import (
 	"os"
 
 	"github.com/okto-digital/regis3/internal/config"
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/profile"
 	"github.com/spf13/cobra"
 )
 
@@ -15,10 +17,14 @@ var (
 	debugFlag    bool
 	configFlag   string
 	registryFlag string
+	profileFlag  string
+	outputFlag   string
 
 	// Global state
-	cfg    *config.Config
-	writer output.Writer
+	cfg      *config.Config
+	writer   output.Writer
+	profiler *profile.Profiler
+	outFile  *os.File
 )
 
 // rootCmd is the base command.
@@ -32,13 +38,23 @@ It supports multiple targets (Claude Code, Cursor, etc.) and provides
 dependency resolution, validation, and organized installation.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Skip setup for init command when no config exists
-		if cmd.Name() == "init" {
+		if cmd == initCmd {
+			i18n.SetLocale(i18n.Detect(""))
 			return nil
 		}
 
-		// Load config
+		// Load config. The config commands must work on an invalid config
+		// so it can be inspected and repaired.
 		var err error
-		cfg, err = loadConfig()
+		if isConfigCommand(cmd) {
+			cfg, err = config.LoadUnvalidated(configFlag)
+		} else {
+			cfg, err = loadConfig()
+		}
+		if err != nil && cmd == configDoctorCmd {
+			// The doctor reports load errors itself
+			cfg, err = config.DefaultConfig(), nil
+		}
 		if err != nil {
 			// If config doesn't exist and not running init, suggest init
 			if os.IsNotExist(err) {
@@ -48,13 +64,47 @@ dependency resolution, validation, and organized installation.`,
 			return err
 		}
 
-		// Override registry path if flag provided
+		// First run: offer the setup wizard, then run the command
+		if needsSetup(cmd) {
+			i18n.SetLocale(i18n.Detect(cfg.Locale))
+			ran, err := offerSetup()
+			if err != nil {
+				return err
+			}
+			if ran {
+				if cfg, err = loadConfig(); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Override registry if flag provided
 		if registryFlag != "" {
-			cfg.RegistryPath = registryFlag
+			cfg.UseRegistry(registryFlag)
 		}
 
+		i18n.SetLocale(i18n.Detect(cfg.Locale))
+
 		// Initialize output writer
-		writer = createWriter()
+		writer, err = createWriter()
+		if err != nil {
+			return err
+		}
+
+		// Remote registries are cloned on first use
+		if remote := cfg.Registry().Remote(); remote != nil && !remote.Cloned() && !isConfigCommand(cmd) {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Cloning registry %s...", remote.URL))
+			if err := cloneRemote(cfg.Registry()); err != nil {
+				return err
+			}
+		}
+
+		if profileFlag != "" {
+			profiler, err = profile.Start(profileFlag)
+			if err != nil {
+				return err
+			}
+		}
 
 		return nil
 	},
@@ -64,15 +114,26 @@ dependency resolution, validation, and organized installation.`,
 
 // Execute runs the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if profiler != nil {
+		stopProfiling()
+	}
+	if outFile != nil {
+		if closeErr := outFile.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write %s: %w", outFile.Name(), closeErr)
+		}
+	}
+	return err
 }
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "f", "pretty", "Output format: pretty, json, quiet")
+	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "f", "pretty", "Output format: pretty, json, quiet, vscode")
 	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Enable debug output")
 	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file path")
-	rootCmd.PersistentFlags().StringVar(&registryFlag, "registry", "", "Override registry path")
+	rootCmd.PersistentFlags().StringVar(&registryFlag, "registry", "", "Use another registry: a configured registry's name, a path or a git URL")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Write CPU/heap profiles and a timing breakdown to this directory")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "Write command output to this file instead of stdout")
 }
 
 // loadConfig loads the configuration.
@@ -80,29 +141,105 @@ func loadConfig() (*config.Config, error) {
 	return config.Load(configFlag)
 }
 
-// createWriter creates an output writer based on flags.
-func createWriter() output.Writer {
+// needsSetup reports whether regis3 has never been set up: there is no
+// config file and no registry. Commands that work without a registry don't
+// need setup.
+func needsSetup(cmd *cobra.Command) bool {
+	if cfg.Path() != "" || registryFlag != "" || cfg.RegistryURL != "" || isConfigCommand(cmd) {
+		return false
+	}
+	switch cmd.Name() {
+	case "version", "upgrade", "help", "completion":
+		return false
+	}
+	_, err := os.Stat(cfg.RegistryPath)
+	return os.IsNotExist(err)
+}
+
+// isConfigCommand reports whether cmd is the config command or one of its subcommands.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// createWriter creates an output writer based on flags. With --output,
+// command output goes to that file (without colors); errors still go to
+// stderr.
+func createWriter() (output.Writer, error) {
 	format := output.FormatPretty
 	switch formatFlag {
 	case "json":
 		format = output.FormatJSON
 	case "quiet":
 		format = output.FormatQuiet
+	case "vscode":
+		format = output.FormatVSCode
+	}
+
+	outCfg := output.DefaultConfig()
+	outCfg.Icons = iconSet()
+	if outputFlag != "" {
+		f, err := os.Create(outputFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		outFile = f
+		outCfg.Output = f
+		outCfg.NoColor = true
 	}
-	return output.New(format, nil)
+	return output.New(format, outCfg), nil
+}
+
+// iconSet returns the configured icon set, detecting it from the terminal
+// when set to auto.
+func iconSet() output.IconSet {
+	if cfg == nil {
+		return output.DetectIconSet()
+	}
+	return output.ParseIconSet(cfg.Icons)
 }
 
 // getRegistryPath returns the registry path from config or flag.
 func getRegistryPath() string {
-	if registryFlag != "" {
-		return registryFlag
-	}
 	if cfg != nil {
 		return cfg.RegistryPath
 	}
+	if registryFlag != "" {
+		return registryFlag
+	}
 	return config.DefaultRegistryPath()
 }
 
+// timings returns the timing record of the active profiler, or nil when
+// profiling is off.
+func timings() *profile.Timings {
+	if profiler == nil {
+		return nil
+	}
+	return profiler.Timings
+}
+
+// stopProfiling writes the profiles and prints the timing breakdown to stderr,
+// keeping stdout clean for JSON output.
+func stopProfiling() {
+	files, err := profiler.Stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Profiling failed: %v\n", err)
+		return
+	}
+	if t := profiler.Timings.String(); t != "" {
+		fmt.Fprintf(os.Stderr, "\nTiming breakdown:\n%s", t)
+	}
+	fmt.Fprintf(os.Stderr, "Profiles written to %s:\n", profiler.Dir)
+	for _, f := range files {
+		fmt.Fprintf(os.Stderr, "  %s\n", f)
+	}
+}
+
 // debugf prints debug output if debug mode is enabled.
 func debugf(format string, args ...interface{}) {
 	if debugFlag {
@@ -3,12 +3,17 @@ package cli
 import (
 	"fmt"
 
+	"github.com/charmbracelet/huh"
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/importer"
 	"github.com/okto-digital/regis3/internal/output"
 	"github.com/spf13/cobra"
 )
 
-var scanDryRun bool
+var (
+	scanDryRun    bool
+	scanSplitOnH1 bool
+)
 
 var scanCmd = &cobra.Command{
 	Use:   "scan <path>",
@@ -16,12 +21,19 @@ var scanCmd = &cobra.Command{
 	Long: `Scans an external directory for markdown files and imports them to the registry.
 
 Files with valid regis3 frontmatter are imported directly to the appropriate
-directory. Files without regis3 frontmatter are placed in the import/
-staging directory for manual review.
+directory. Files without regis3 frontmatter are placed in the import
+staging directory for manual review (import/ in the registry unless
+import.staging_dir is configured).
+
+Use --split-on-h1 for documents that hold several prompts or skills under
+their own "# " headings: each section is staged as a separate item with
+suggested frontmatter. The split is previewed and confirmed before anything
+is written; with --dry-run only the preview is shown.
 
 Examples:
   regis3 scan ~/Documents/prompts
-  regis3 scan ./my-skills --dry-run`,
+  regis3 scan ./my-skills --dry-run
+  regis3 scan ./prompt-collection.md --split-on-h1`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return fmt.Errorf("missing path argument\n\nUsage: regis3 scan <path>\n\nExample: regis3 scan ~/Documents/prompts")
@@ -32,27 +44,64 @@ Examples:
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if !scanDryRun && stagingInRegistry() {
+			if err := guardRegistry("scan"); err != nil {
+				return err
+			}
+		}
 		return runScan(args[0])
 	},
 }
 
 func init() {
 	scanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "Preview what would be imported")
+	scanCmd.Flags().BoolVar(&scanSplitOnH1, "split-on-h1", false, "Stage each level-1 heading section of a file as its own item")
 	rootCmd.AddCommand(scanCmd)
 }
 
 func runScan(path string) error {
 	debugf("Scanning: %s", path)
 
-	imp := importer.NewImporter(getRegistryPath())
+	imp := newImporter()
 	imp.DryRun = scanDryRun
+	imp.SplitOnH1 = scanSplitOnH1
+
+	// Preview splits before writing them
+	if scanSplitOnH1 && !scanDryRun && isInteractive() {
+		imp.DryRun = true
+		preview, err := imp.ScanAndImport(path)
+		if err != nil {
+			writer.Error(i18n.Sprintf("Scan failed: %s", err.Error()))
+			return err
+		}
+		if len(preview.Split) > 0 {
+			writeScanResult(preview, true)
+			write, err := confirmSplit(preview)
+			if err != nil {
+				return err
+			}
+			if !write {
+				return nil
+			}
+		}
+		imp.DryRun = false
+	}
 
 	result, err := imp.ScanAndImport(path)
 	if err != nil {
-		writer.Error(fmt.Sprintf("Scan failed: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Scan failed: %s", err.Error()))
 		return err
 	}
 
+	writeScanResult(result, scanDryRun)
+	if len(result.Errors) > 0 {
+		return errScanFailed
+	}
+	return nil
+}
+
+// writeScanResult writes the result of a scan, or of its dry run.
+func writeScanResult(result *importer.ImportResult, dryRun bool) {
 	// Build response data
 	imported := make([]output.ImportedItem, len(result.Imported))
 	for i, item := range result.Imported {
@@ -74,6 +123,21 @@ func runScan(path string) error {
 		}
 	}
 
+	var split []output.ImportedItem
+	splitFiles := make(map[string]bool)
+	for _, item := range result.Split {
+		split = append(split, output.ImportedItem{
+			SourcePath: item.SourcePath,
+			DestPath:   item.DestPath,
+			Type:       item.Type,
+			Name:       item.Name,
+			Desc:       item.Desc,
+			Section:    item.Section,
+			Lines:      item.Lines,
+		})
+		splitFiles[item.SourcePath] = true
+	}
+
 	var errors []string
 	for _, e := range result.Errors {
 		errors = append(errors, e.Error())
@@ -84,31 +148,55 @@ func runScan(path string) error {
 		WithData(output.ScanData{
 			Imported: imported,
 			Staged:   staged,
+			Split:    split,
 			Errors:   errors,
-			DryRun:   scanDryRun,
+			DryRun:   dryRun,
 		})
 
-	if scanDryRun {
+	if dryRun {
 		resp.WithInfo("Would import %d files, stage %d files (dry run)", len(imported), len(staged))
+		if len(split) > 0 {
+			resp.WithInfo("Would split %d files into %d staged items (dry run)", len(splitFiles), len(split))
+		}
 	} else {
 		if len(imported) > 0 {
 			resp.WithInfo("Imported %d files to registry", len(imported))
 		}
 		if len(staged) > 0 {
-			resp.WithInfo("Staged %d files in import/ (need regis3 headers)", len(staged))
+			resp.WithInfo("Staged %d files in %s (need regis3 headers)", len(staged), stagingDisplay())
+		}
+		if len(split) > 0 {
+			resp.WithInfo("Split %d files into %d staged items in %s (review their frontmatter)", len(splitFiles), len(split), stagingDisplay())
 		}
 	}
 
 	for _, e := range errors {
 		resp.WithError("scan", e)
 	}
+	addImporterNotices(resp)
+	describeFailures = 0
 
 	writer.Write(resp.Build())
+}
 
-	if len(result.Errors) > 0 {
-		return errScanFailed
+// confirmSplit asks whether to write the previewed split items.
+func confirmSplit(preview *importer.ImportResult) (bool, error) {
+	write := true
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Stage %d items split from the files above?", len(preview.Split))).
+				Description("Declining writes nothing; scan again without --split-on-h1 to stage the files whole").
+				Affirmative("Stage").
+				Negative("Cancel").
+				Value(&write),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return false, err
 	}
-	return nil
+	return write, nil
 }
 
 var errScanFailed = &exitError{code: 1, message: "scan had errors"}
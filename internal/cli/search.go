@@ -2,6 +2,8 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/okto-digital/regis3/internal/output"
@@ -35,33 +37,31 @@ func init() {
 func runSearch(query string) error {
 	debugf("Searching for: %s", query)
 
-	manifest, err := registry.LoadManifestFromRegistry(getRegistryPath())
+	manifest, err := loadManifest()
 	if err != nil {
-		// Try building first
-		_, buildErr := registry.BuildRegistry(getRegistryPath())
-		if buildErr != nil {
-			writer.Error(fmt.Sprintf("Failed to load registry: %s", err.Error()))
-			return err
-		}
-		manifest, err = registry.LoadManifestFromRegistry(getRegistryPath())
-		if err != nil {
-			writer.Error(fmt.Sprintf("Failed to load manifest: %s", err.Error()))
-			return err
-		}
+		return err
 	}
 
 	// Search items
-	items := searchItems(manifest.Items, query)
-
-	// Build list data
-	listItems := make([]output.ListItem, len(items))
-	for i, item := range items {
+	matches := searchItems(manifest.Items, query)
+
+	// Build list data, locating the matching field for editors
+	root := registryRoot()
+	dialect := buildOptions().Dialect
+	listItems := make([]output.ListItem, len(matches))
+	for i, match := range matches {
+		item := match.item
 		listItems[i] = output.ListItem{
 			Type: item.Type,
 			Name: item.Name,
 			Desc: item.Desc,
 			Tags: item.Tags,
 		}
+		if content, err := os.ReadFile(filepath.Join(root, item.Source)); err == nil {
+			pos := dialect.Locate(content, match.field)
+			listItems[i].File = filepath.Join(root, item.Source)
+			listItems[i].Line, listItems[i].Column = pos.Line, pos.Column
+		}
 	}
 
 	resp := output.NewResponseBuilder("search").
@@ -72,37 +72,43 @@ func runSearch(query string) error {
 			Filtered:   true,
 		})
 
-	if len(items) == 0 {
+	if len(matches) == 0 {
 		resp.WithInfo("No items match '%s'", query)
 	} else {
-		resp.WithInfo("Found %d items matching '%s'", len(items), query)
+		resp.WithInfo("Found %d items matching '%s'", len(matches), query)
 	}
 
 	writer.Write(resp.Build())
 	return nil
 }
 
-func searchItems(items map[string]*registry.Item, query string) []*registry.Item {
+// searchMatch is an item matching a search, with the field that matched.
+type searchMatch struct {
+	item  *registry.Item
+	field string
+}
+
+func searchItems(items map[string]*registry.Item, query string) []searchMatch {
 	query = strings.ToLower(query)
-	var matches []*registry.Item
+	var matches []searchMatch
 
 	for _, item := range items {
 		// Search in name
 		if strings.Contains(strings.ToLower(item.Name), query) {
-			matches = append(matches, item)
+			matches = append(matches, searchMatch{item, "name"})
 			continue
 		}
 
 		// Search in description
 		if strings.Contains(strings.ToLower(item.Desc), query) {
-			matches = append(matches, item)
+			matches = append(matches, searchMatch{item, "desc"})
 			continue
 		}
 
 		// Search in tags
 		for _, tag := range item.Tags {
 			if strings.Contains(strings.ToLower(tag), query) {
-				matches = append(matches, item)
+				matches = append(matches, searchMatch{item, "tags"})
 				break
 			}
 		}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"os"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd is a shortcut for project status
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show items installed in the current project",
+	Long: `Shows items installed in the current project (same as 'project status').
+
+Use --all-targets to report installed items, pending updates and drift for
+every target with items installed in this project in one table.
+
+Examples:
+  regis3 status
+  regis3 status --all-targets
+  regis3 status --all-targets --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectStatus()
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVar(&projectStatusTarget, "target", "", "Target (default: from config, or detected from the project)")
+	statusCmd.Flags().BoolVar(&projectStatusAll, "all-targets", false, "Report every target installed in this project")
+	rootCmd.AddCommand(statusCmd)
+}
+
+// runStatusAllTargets reports the status of every target installed in the
+// current project.
+func runStatusAllTargets(command string) error {
+	targets := projectTargets()
+	manifest := loadStatusManifest()
+
+	data := output.TargetsStatusData{Targets: []output.StatusData{}}
+	resp := output.NewResponseBuilder(command)
+	total, failed := 0, 0
+	for _, target := range targets {
+		status, err := targetStatus(target, manifest)
+		if err != nil {
+			resp.WithError(target.Name, err.Error())
+			failed++
+			continue
+		}
+		data.Targets = append(data.Targets, status)
+		total += len(status.Items)
+		addStatusWarnings(resp, status.Items, target.Name)
+	}
+
+	resp.WithSuccess(failed == 0).WithData(data)
+	if len(data.Targets) == 0 {
+		resp.WithInfo("No items installed in this project")
+	} else {
+		resp.WithInfo("%d items installed for %d targets", total, len(data.Targets))
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
+
+// projectTargets returns the known targets (built-in claude and those in the
+// targets directory) that have a tracker in the current project, by name.
+func projectTargets() []*installer.Target {
+	return projectTargetsIn(".")
+}
+
+// projectTargetsIn returns the known targets that have a tracker in the
+// project at dir, by name.
+func projectTargetsIn(dir string) []*installer.Target {
+	var targets []*installer.Target
+	for _, target := range availableTargets() {
+		if _, err := os.Stat(target.TrackerPath(dir)); err == nil {
+			targets = append(targets, target)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	return targets
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/recommend"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest registry items for the current project",
+	Long: `Inspects the current project and recommends registry items that fit it.
+
+Languages are detected from the project's source files and frameworks from
+the dependencies in go.mod, package.json and requirements.txt. Items match
+by their tags, category and name; a framework match ranks above a language
+match. Items already installed, or named like existing skills, agents or
+commands in .claude/, are left out.
+
+With --pick, the item picker opens with the suggestions listed first.
+Press "i" to install the item under the cursor right away, or select
+several and confirm with enter. The picker of 'regis3 project add' shows
+the same suggestions.
+
+Examples:
+  regis3 suggest
+  regis3 suggest --limit 5
+  regis3 suggest --pick`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSuggest()
+	},
+}
+
+var suggestDepsCmd = &cobra.Command{
+	Use:   "suggest-deps <file>",
+	Short: "Suggest dependencies mentioned in an item",
+	Long: `Scans an item's content for mentions of other registry items and
+proposes the ones missing from its deps.
+
+An item is mentioned by its full reference (skill:git-conventions) or by
+its name alone (git-conventions). Names shared by several items are
+ambiguous and only match as full references. Suggestions that the
+configured dependency rules forbid are left out.
+
+With --write, the suggestions are added to the item's deps in place.
+
+Examples:
+  regis3 suggest-deps skills/backend/api-design.md
+  regis3 suggest-deps skills/backend/api-design.md --write`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSuggestDeps(args[0])
+	},
+}
+
+// Suggest command flags
+var (
+	suggestWrite bool
+	suggestLimit int
+	suggestPick  bool
+)
+
+func init() {
+	suggestDepsCmd.Flags().BoolVar(&suggestWrite, "write", false, "Add the suggested dependencies to the file")
+	rootCmd.AddCommand(suggestDepsCmd)
+
+	suggestCmd.Flags().IntVar(&suggestLimit, "limit", 10, "Maximum number of suggestions (0 for all)")
+	suggestCmd.Flags().BoolVar(&suggestPick, "pick", false, "Choose suggestions to install in the item picker")
+	// Installing from the picker goes through project add, so share its target
+	suggestCmd.Flags().StringVar(&projectAddTarget, "target", "", "Target (default: from config, or detected from the project)")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+func runSuggest() error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	target, err := resolveTarget(projectAddTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	if suggestPick {
+		if !isInteractive() {
+			return fmt.Errorf("--pick needs an interactive terminal")
+		}
+		selected, err := pickItemsToAdd(manifest, target)
+		if err != nil {
+			writer.Error(i18n.Sprintf("Selection cancelled: %s", err.Error()))
+			return err
+		}
+		if len(selected) == 0 {
+			writer.Info("No items selected")
+			return nil
+		}
+		return runProjectAdd(selected)
+	}
+
+	project, suggestions, err := projectSuggestions(manifest, loadTracker(target))
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to inspect the project: %s", err.Error()))
+		return err
+	}
+
+	data := output.SuggestData{
+		Languages:   nonNil(project.Languages),
+		Frameworks:  nonNil(project.Frameworks),
+		Suggestions: make([]output.ItemSuggestion, 0, len(suggestions)),
+	}
+	for _, s := range suggestions {
+		data.Suggestions = append(data.Suggestions, output.ItemSuggestion{
+			Ref:     s.Ref,
+			Desc:    manifest.Items[s.Ref].Desc,
+			Score:   s.Score,
+			Matches: s.Matches,
+		})
+	}
+
+	resp := output.NewResponseBuilder("suggest").
+		WithSuccess(true).
+		WithData(&data)
+	if len(suggestions) > 0 {
+		resp.WithInfo("Install with 'regis3 project add <item>' or pick them with 'regis3 suggest --pick'")
+	}
+	writer.Write(resp.Build())
+	return nil
+}
+
+// projectSuggestions detects what the project in the working directory is
+// built with and recommends up to suggestLimit items not yet installed.
+func projectSuggestions(manifest *registry.Manifest, tracker *installer.Tracker) (*recommend.Project, []recommend.Suggestion, error) {
+	project, err := recommend.Detect(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	debugf("Detected languages %v, frameworks %v", project.Languages, project.Frameworks)
+
+	installed := make(map[string]bool)
+	for _, id := range tracker.ListInstalled() {
+		installed[id] = true
+	}
+	suggestions := recommend.Recommend(manifest, project, installed)
+	if suggestLimit > 0 && len(suggestions) > suggestLimit {
+		suggestions = suggestions[:suggestLimit]
+	}
+	return project, suggestions, nil
+}
+
+// suggestionReason describes why an item is suggested.
+func suggestionReason(s recommend.Suggestion) string {
+	return "matches " + strings.Join(s.Matches, ", ")
+}
+
+func runSuggestDeps(path string) error {
+	debugf("Suggesting dependencies for: %s", path)
+	if suggestWrite {
+		if err := guardRegistry("suggest-deps --write"); err != nil {
+			return err
+		}
+	}
+
+	scanner := registry.NewScanner(getRegistryPath())
+	scanner.Dialect = buildOptions().Dialect
+	item, err := scanner.ScanFile(path)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Failed to parse %s: %s", path, err.Error()))
+		return err
+	}
+
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	var rules registry.DependencyRules
+	if cfg != nil {
+		rules = cfg.DependencyRules
+	}
+	suggestions := registry.SuggestDeps(item, manifest, rules)
+
+	data := output.SuggestDepsData{
+		Item:        item.FullName(),
+		Path:        path,
+		Suggestions: make([]output.DepSuggestion, 0, len(suggestions)),
+	}
+	refs := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		data.Suggestions = append(data.Suggestions, output.DepSuggestion{Ref: s.Ref, Mention: s.Mention})
+		refs = append(refs, s.Ref)
+	}
+
+	if suggestWrite && len(refs) > 0 {
+		if err := registry.AddDeps(path, refs); err != nil {
+			writer.Error(i18n.Sprintf("Failed to update %s: %s", path, err.Error()))
+			return err
+		}
+		data.Written = true
+	}
+
+	resp := output.NewResponseBuilder("suggest-deps").
+		WithSuccess(true).
+		WithData(&data)
+	if data.Written {
+		resp.WithInfo("Run 'regis3 build' to update the manifest")
+	}
+
+	writer.Write(resp.Build())
+	return nil
+}
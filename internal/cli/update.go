@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
@@ -18,7 +19,9 @@ var updateCmd = &cobra.Command{
 	Long: `Updates the registry by pulling the latest changes from git.
 
 This command runs 'git pull' in the registry directory to fetch
-the latest items from the remote repository.
+the latest items from the remote repository. Registries configured
+by git URL are fetched into their clone in the cache, replacing any
+changes made there.
 
 After pulling, it automatically rebuilds the manifest.
 
@@ -37,30 +40,41 @@ func runUpdate() error {
 	registryPath := getRegistryPath()
 	debugf("Updating registry: %s", registryPath)
 
-	// Check if registry is a git repo
-	gitDir := filepath.Join(registryPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		writer.Error("Registry is not a git repository")
-		return fmt.Errorf("not a git repository")
-	}
-
-	// Run git pull
-	cmd := exec.Command("git", "-C", registryPath, "pull", "--ff-only")
-	gitOutput, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(gitOutput))
-
-	if err != nil {
-		writer.Error(fmt.Sprintf("Git pull failed: %s", outputStr))
-		return err
+	var outputStr string
+	var alreadyUpToDate bool
+	if remote := cfg.Registry().Remote(); remote != nil {
+		changed, err := remote.Sync()
+		if err != nil {
+			writer.Error(i18n.Sprintf("Git fetch failed: %s", err.Error()))
+			return err
+		}
+		alreadyUpToDate = !changed
+	} else {
+		// Check if registry is a git repo
+		gitDir := filepath.Join(registryPath, ".git")
+		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+			writer.Error("Registry is not a git repository")
+			return fmt.Errorf("not a git repository")
+		}
+
+		// Run git pull
+		cmd := exec.Command("git", "-C", registryPath, "pull", "--ff-only")
+		gitOutput, err := cmd.CombinedOutput()
+		outputStr = strings.TrimSpace(string(gitOutput))
+
+		if err != nil {
+			writer.Error(i18n.Sprintf("Git pull failed: %s", outputStr))
+			return err
+		}
+
+		// Check if there were updates
+		alreadyUpToDate = strings.Contains(outputStr, "Already up to date")
 	}
 
-	// Check if there were updates
-	alreadyUpToDate := strings.Contains(outputStr, "Already up to date")
-
 	// Rebuild manifest
-	result, err := registry.BuildRegistry(registryPath)
+	result, err := registry.BuildRegistryWithOptions(registryPath, buildOptions())
 	if err != nil {
-		writer.Error(fmt.Sprintf("Failed to rebuild manifest: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Failed to rebuild manifest: %s", err.Error()))
 		return err
 	}
 
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/okto-digital/regis3/internal/buildinfo"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// Upgrade command flags
+var (
+	upgradeCheck bool
+	upgradeForce bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade regis3 to the latest release",
+	Long: `Checks GitHub for a newer regis3 release and replaces the running binary.
+
+The downloaded archive is verified against the release's checksums.txt
+before anything is replaced. Binaries installed through a package manager
+(Homebrew, apt, rpm) should be upgraded with that package manager instead.
+
+Examples:
+  regis3 upgrade --check   # Only report whether an update is available
+  regis3 upgrade           # Download and install the latest release`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUpgrade()
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only check for a newer version")
+	upgradeCmd.Flags().BoolVarP(&upgradeForce, "force", "F", false, "Reinstall even if already up to date (or a dev build)")
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade() error {
+	client := upgrade.NewClient()
+
+	debugf("Checking %s", client.ReleaseURL)
+	release, err := client.LatestRelease()
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	data := output.UpgradeData{
+		Current:         buildinfo.Version,
+		Latest:          release.Version(),
+		UpdateAvailable: upgrade.CompareVersions(buildinfo.Version, release.Version()) < 0,
+		ReleaseURL:      release.HTMLURL,
+	}
+	resp := output.NewResponseBuilder("upgrade").WithSuccess(true)
+
+	if upgradeCheck || (!data.UpdateAvailable && !upgradeForce) {
+		writer.Write(resp.WithData(data).Build())
+		return nil
+	}
+
+	if !buildinfo.IsRelease() && !upgradeForce {
+		writer.Error("This is a development build; use --force to replace it with a release")
+		return fmt.Errorf("refusing to replace development build")
+	}
+
+	// Download archive and checksums
+	archiveName := upgrade.CurrentArchiveName(release.Version())
+	archiveAsset, ok := release.Asset(archiveName)
+	if !ok {
+		writer.Error(i18n.Sprintf("No release archive for this platform: %s", archiveName))
+		return fmt.Errorf("asset not found: %s", archiveName)
+	}
+	checksumAsset, ok := release.Asset(upgrade.ChecksumsFile)
+	if !ok {
+		writer.Error("Release has no checksums; refusing to install an unverified binary")
+		return fmt.Errorf("asset not found: %s", upgrade.ChecksumsFile)
+	}
+
+	debugf("Downloading %s", archiveAsset.URL)
+	archive, err := client.Download(archiveAsset)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+	checksums, err := client.Download(checksumAsset)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	if err := upgrade.VerifyChecksum(archive, archiveName, checksums); err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	binary, err := upgrade.ExtractBinary(archive, archiveName)
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	// Replace the running executable
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		writer.Error(i18n.Sprintf("Cannot locate executable: %s", err.Error()))
+		return err
+	}
+
+	if err := upgrade.ReplaceExecutable(exe, binary); err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	data.Upgraded = true
+	data.Path = exe
+	writer.Write(resp.WithData(data).Build())
+	return nil
+}
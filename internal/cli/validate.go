@@ -1,10 +1,12 @@
 package cli
 
 import (
-	"fmt"
+	"path/filepath"
 
+	"github.com/okto-digital/regis3/internal/i18n"
 	"github.com/okto-digital/regis3/internal/output"
 	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +17,13 @@ var validateCmd = &cobra.Command{
 - Required fields (type, name, desc)
 - Valid type values
 - Unique type:name combinations
-- Existing dependencies
-- File references`,
+- Existing dependencies, resolvable capabilities and no circular dependencies
+- File references
+- Unresolved merge conflict markers (<<<<<<<, =======, >>>>>>>)
+
+Issues point at the frontmatter field they concern: -f json includes file,
+line and column, and -f vscode prints file:line:column: severity: message
+lines for editor problem matchers.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runValidate()
 	},
@@ -30,15 +37,32 @@ func runValidate() error {
 	debugf("Validating registry: %s", getRegistryPath())
 
 	// Build and validate
-	result, err := registry.BuildRegistry(getRegistryPath())
+	result, err := buildRegistry()
 	if err != nil {
-		writer.Error(fmt.Sprintf("Failed to scan registry: %s", err.Error()))
+		writer.Error(i18n.Sprintf("Failed to scan registry: %s", err.Error()))
 		return err
 	}
 
 	itemCount := len(result.Manifest.Items)
+	depIssues := dependencyIssues(result.Manifest)
+	for _, issue := range depIssues {
+		result.Validation.Issues = append(result.Validation.Issues, issue.ValidationIssue())
+	}
+	result.Validation.Locate(getRegistryPath(), buildOptions().Dialect)
 	issues := result.Validation.Issues
 
+	data := issueData(issues)
+	for i, issue := range depIssues {
+		d := &data[len(data)-len(depIssues)+i]
+		switch issue := issue.(type) {
+		case *resolver.CycleIssue:
+			d.Cycle = issue.Cycle
+		case *resolver.MissingDepIssue:
+			d.Item = issue.Item
+			d.Dependency = issue.Dep
+		}
+	}
+
 	// Build response
 	resp := output.NewResponseBuilder("validate").
 		WithData(output.ValidateData{
@@ -46,6 +70,7 @@ func runValidate() error {
 			ErrorCount: countSeverity(issues, registry.SeverityError),
 			WarnCount:  countSeverity(issues, registry.SeverityWarning),
 			InfoCount:  countSeverity(issues, registry.SeverityInfo),
+			Issues:     data,
 		})
 
 	// Add issues as messages
@@ -79,6 +104,21 @@ func runValidate() error {
 	return nil
 }
 
+// dependencyIssues returns the problems of the resolved dependency graph the
+// validator doesn't report: circular dependencies, and capabilities without
+// a usable provider. Dependencies on items that don't exist are reported by
+// the validator already.
+func dependencyIssues(manifest *registry.Manifest) []resolver.Issue {
+	var issues []resolver.Issue
+	for _, issue := range resolver.NewResolverWithOptions(manifest, resolverOptions(nil)).Validate().Issues {
+		if missing, ok := issue.(*resolver.MissingDepIssue); ok && missing.Reason == "" {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
 func countSeverity(issues []registry.ValidationIssue, severity registry.Severity) int {
 	count := 0
 	for _, issue := range issues {
@@ -89,6 +129,34 @@ func countSeverity(issues []registry.ValidationIssue, severity registry.Severity
 	return count
 }
 
+// issueData returns the located issues for the response, with absolute
+// file paths for editors.
+func issueData(issues []registry.ValidationIssue) []output.IssueData {
+	root := registryRoot()
+	data := make([]output.IssueData, len(issues))
+	for i, issue := range issues {
+		data[i] = output.IssueData{
+			Severity: issue.Severity.String(),
+			Path:     issue.Path,
+			File:     filepath.Join(root, issue.Path),
+			Line:     issue.Line,
+			Column:   issue.Column,
+			Field:    issue.Field,
+			Message:  issue.Message,
+		}
+	}
+	return data
+}
+
+// registryRoot returns the absolute path of the registry.
+func registryRoot() string {
+	root, err := filepath.Abs(getRegistryPath())
+	if err != nil {
+		return getRegistryPath()
+	}
+	return root
+}
+
 var errValidationFailed = &exitError{code: 1, message: "validation failed"}
 
 type exitError struct {
@@ -1,23 +1,20 @@
 package cli
 
 import (
-	"fmt"
-	"runtime"
-
+	"github.com/okto-digital/regis3/internal/buildinfo"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/spf13/cobra"
 )
 
-// Version information (set via ldflags by goreleaser)
-var (
-	version = "dev"
-	commit  = "unknown"
-	date    = "unknown"
-	builtBy = "manual"
-)
-
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
+	Long: `Shows the regis3 version together with its build metadata.
+
+Examples:
+  regis3 version
+  regis3 version --format json   # Machine-readable build metadata`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runVersion()
 	},
@@ -28,13 +25,19 @@ func init() {
 }
 
 func runVersion() error {
-	fmt.Printf("regis3 %s\n", version)
-	fmt.Printf("  Built:    %s\n", date)
-	fmt.Printf("  Commit:   %s\n", commit)
-	fmt.Printf("  Go:       %s\n", runtime.Version())
-	fmt.Printf("  Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
-	if builtBy != "manual" {
-		fmt.Printf("  Built by: %s\n", builtBy)
-	}
+	info := buildinfo.Get()
+
+	resp := output.NewResponseBuilder("version").
+		WithSuccess(true).
+		WithData(output.VersionData{
+			Version:         info.Version,
+			Commit:          info.Commit,
+			BuildTime:       info.Date,
+			BuiltBy:         info.BuiltBy,
+			GoVersion:       info.GoVersion,
+			Platform:        info.Platform,
+			ManifestVersion: registry.ManifestVersion,
+		})
+	writer.Write(resp.Build())
 	return nil
 }
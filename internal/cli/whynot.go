@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// whyNotTarget is the target why-not explains for.
+var whyNotTarget string
+
+// whyNotCmd explains why installing an item would skip it
+var whyNotCmd = &cobra.Command{
+	Use:   "why-not <type:name>",
+	Short: "Explain why installing an item skips it",
+	Long: `Tells whether 'regis3 project add' would skip an item in the current
+project and names the rule or state deciding it: the item is already
+installed and up to date, pinned, kept at its locked content, or a stack,
+which has no file of its own. Items that would be installed, updated or
+merged into the merge file are reported as such.
+
+Only the item itself is considered, not its dependencies; use
+'project add --explain-skips' to see the reasons for a whole installation.
+
+Examples:
+  regis3 why-not skill:git-conventions
+  regis3 why-not git-conventions --target cursor`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWhyNot(args[0])
+	},
+}
+
+func init() {
+	whyNotCmd.Flags().StringVar(&whyNotTarget, "target", "", "Target (default: from config, or detected from the project)")
+	rootCmd.AddCommand(whyNotCmd)
+}
+
+func runWhyNot(ref string) error {
+	manifest, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	ids, notices, err := resolveRefs(manifest, []string{ref})
+	if err != nil {
+		writer.Error(err.Error())
+		return fmt.Errorf("item not found")
+	}
+
+	target, err := resolveTarget(whyNotTarget)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Target not found: %s", err.Error()))
+		return err
+	}
+
+	inst, err := newInstaller(target)
+	if err != nil {
+		writer.Error(i18n.Sprintf("Installer error: %s", err.Error()))
+		return err
+	}
+	inst.ResolverOptions = resolverOptions(nil)
+
+	e, err := inst.Explain(manifest, ids[0])
+	if err != nil {
+		writer.Error(err.Error())
+		return err
+	}
+
+	resp := output.NewResponseBuilder("why-not").
+		WithSuccess(true).
+		WithData(output.WhyNotData{Item: e.Item, Target: e.Target, Skipped: e.Skipped, Reason: e.Reason})
+	for _, notice := range notices {
+		resp.WithWarning("%s", notice)
+	}
+	if e.Skipped {
+		resp.WithInfo("%s is skipped for %s: %s", e.Item, e.Target, e.Reason)
+	} else {
+		resp.WithInfo("%s is not skipped for %s: %s", e.Item, e.Target, e.Reason)
+	}
+	writer.Write(resp.Build())
+	return nil
+}
+
+// explainSkips adds to an install response why each skipped or pinned
+// item was left as it is.
+func explainSkips(resp *output.ResponseBuilder, result *installer.InstallResult) {
+	if data, ok := resp.Build().Data.(output.InstallData); ok {
+		data.SkipReasons = result.SkipReasons
+		resp.WithData(data)
+	}
+
+	ids := make([]string, 0, len(result.SkipReasons))
+	for id := range result.SkipReasons {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		resp.WithInfo("Skipped %s: %s", id, result.SkipReasons[id])
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
 	"github.com/spf13/viper"
 )
 
@@ -12,17 +16,356 @@ type Config struct {
 	// RegistryPath is the path to the registry directory.
 	RegistryPath string `mapstructure:"registry_path"`
 
-	// DefaultTarget is the default output target (claude, cursor, gpt).
+	// RegistryURL is the git URL registry_path is set to when the registry
+	// is remote; RegistryPath is then its clone in the cache.
+	RegistryURL string `mapstructure:"-"`
+
+	// Registries names further registries by path or git URL (e.g. team:
+	// ~/team-registry). build --all builds them together with
+	// registry_path.
+	Registries map[string]string `mapstructure:"registries"`
+
+	// Workspace lists the project directories that use the registry, as
+	// paths or globs (e.g. ~/code/*). registry impact checks them for
+	// installed items before an item is renamed or deleted.
+	Workspace []string `mapstructure:"workspace"`
+
+	// ReadOnly refuses the commands that modify the registry (import,
+	// scan, fmt, suggest-deps --write, crypt), for machines that use a
+	// shared registry without maintaining it.
+	ReadOnly bool `mapstructure:"read_only"`
+
+	// DefaultTarget is the default output target (claude, codex, cursor, gpt), or
+	// auto to detect it per project.
 	DefaultTarget string `mapstructure:"default_target"`
 
-	// OutputFormat is the default output format (pretty, json, quiet).
+	// OutputFormat is the default output format (pretty, json, quiet, vscode).
 	OutputFormat string `mapstructure:"output_format"`
 
+	// Icons is the icon set of pretty output (auto, unicode, ascii, none).
+	// auto picks unicode or ascii from the terminal.
+	Icons string `mapstructure:"icons"`
+
 	// Debug enables debug output.
 	Debug bool `mapstructure:"debug"`
+
+	// DependencyRules restricts which item types each type may depend on
+	// (e.g. skill: [skill, doc]). Types without an entry are unrestricted.
+	DependencyRules map[string][]string `mapstructure:"dependency_rules"`
+
+	// Providers selects which item satisfies a capability when several
+	// items provide it (e.g. capability:git-workflow: skill:git-flow).
+	Providers map[string]string `mapstructure:"providers"`
+
+	// Prefer lists items to pick when a stack offers alternatives (one_of),
+	// e.g. [skill:vitest-testing]. Earlier entries win.
+	Prefer []string `mapstructure:"prefer"`
+
+	// Build restricts which registry files are scanned during builds.
+	Build BuildConfig `mapstructure:"build"`
+
+	// Lint configures content consistency checks during validation.
+	Lint LintConfig `mapstructure:"lint"`
+
+	// SizeBudgets limits content length per item type
+	// (e.g. philosophy: {warn: 2KB}, skill: {warn: 20KB, max: 40KB}).
+	SizeBudgets map[string]SizeBudgetConfig `mapstructure:"size_budgets"`
+
+	// Locale selects the language of CLI messages (en, de). When empty,
+	// it is taken from the LC_ALL, LC_MESSAGES or LANG environment variables.
+	Locale string `mapstructure:"locale"`
+
+	// Import configures importing external files.
+	Import ImportConfig `mapstructure:"import"`
+
+	// MergeBudget limits the size of the managed section regis3 writes to
+	// the merge file (e.g. CLAUDE.md), as bytes or with a KB/MB suffix.
+	MergeBudget string `mapstructure:"merge_budget"`
+
+	// ConfirmMerge turns the confirmation before an install removes
+	// content from the merge file's managed section on or off per merge
+	// type (e.g. philosophy: false). Types without an entry ask.
+	ConfirmMerge map[string]bool `mapstructure:"confirm_merge"`
+
+	// List sets the order of items in list and the item picker.
+	List ListConfig `mapstructure:"list"`
+
+	// Report configures reporting installations to a webhook.
+	Report ReportConfig `mapstructure:"report"`
+
+	// License configures license compliance checks during validation.
+	License LicenseConfig `mapstructure:"license"`
+
+	// path is the config file the values were read from, if any.
+	path string
+}
+
+// Path returns the config file the configuration was loaded from, or an
+// empty string if no file was read.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// BuildConfig holds registry build settings.
+type BuildConfig struct {
+	// Include limits builds to files matching these globs (e.g. skills/**).
+	// Empty means all files.
+	Include []string `mapstructure:"include"`
+
+	// Exclude skips files matching these globs (e.g. drafts/**).
+	Exclude []string `mapstructure:"exclude"`
+
+	// Frontmatter lists the accepted frontmatter formats (yaml, toml).
+	// Empty means yaml only.
+	Frontmatter []string `mapstructure:"frontmatter"`
+
+	// MetaKeys lists the frontmatter keys item metadata is read from, tried
+	// in order, so legacy content can be adopted as is (e.g. [regis3, regis,
+	// meta.regis3]). Dotted keys address nested blocks. Empty means regis3.
+	MetaKeys []string `mapstructure:"meta_keys"`
+}
+
+// Dialect returns the frontmatter formats and keys builds and imports read
+// item metadata from.
+func (c *Config) Dialect() registry.Dialect {
+	dialect := registry.Dialect{Keys: c.Build.MetaKeys}
+	for _, format := range c.Build.Frontmatter {
+		dialect.Formats = append(dialect.Formats, frontmatter.Format(format))
+	}
+	return dialect
+}
+
+// ImportConfig holds import settings.
+type ImportConfig struct {
+	// DescribeCommand is a shell command that suggests descriptions for
+	// imported files without one, e.g. a language model CLI. It reads the
+	// content on stdin; the first line of output is the description. When
+	// empty or failing, descriptions come from the content's first
+	// paragraph or headings.
+	DescribeCommand string `mapstructure:"describe_command"`
+
+	// StagingDir is where imported files wait for regis3 frontmatter,
+	// relative to the registry or absolute (e.g. .staging or
+	// ~/regis3-staging). Empty means the registry's import directory.
+	StagingDir string `mapstructure:"staging_dir"`
+}
+
+// DefaultRegistryName names registry_path among the configured registries.
+const DefaultRegistryName = "default"
+
+// NamedRegistry is a registry directory and the name it is configured as.
+type NamedRegistry struct {
+	Name string
+	Path string
+
+	// URL is the git URL of a remote registry, whose Path is its clone in
+	// the cache.
+	URL string
+}
+
+// Remote returns the remote registry, or nil if the registry is local.
+func (r NamedRegistry) Remote() *registry.Remote {
+	if r.URL == "" {
+		return nil
+	}
+	return &registry.Remote{URL: r.URL, Dir: r.Path}
+}
+
+// AllRegistries returns registry_path, named DefaultRegistryName, followed
+// by the configured registries sorted by name. A leading ~ in their paths
+// expands to the home directory; remote registries resolve to their clone.
+func (c *Config) AllRegistries() []NamedRegistry {
+	all := []NamedRegistry{c.Registry()}
+	names := make([]string, 0, len(c.Registries))
+	for name := range c.Registries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		all = append(all, NewNamedRegistry(name, c.Registries[name]))
+	}
+	return all
+}
+
+// Registry returns the registry in use, named DefaultRegistryName.
+func (c *Config) Registry() NamedRegistry {
+	return NamedRegistry{Name: DefaultRegistryName, Path: c.RegistryPath, URL: c.RegistryURL}
+}
+
+// UseRegistry switches to another registry, given as the name of a
+// configured registry, a path or a git URL. Names take precedence over
+// relative paths.
+func (c *Config) UseRegistry(location string) {
+	if configured, ok := c.Registries[location]; ok {
+		location = configured
+	}
+	c.RegistryPath, c.RegistryURL = resolveRegistry(location)
+}
+
+// NewNamedRegistry returns the registry at location (a path or git URL)
+// under a name.
+func NewNamedRegistry(name, location string) NamedRegistry {
+	path, url := resolveRegistry(location)
+	return NamedRegistry{Name: name, Path: path, URL: url}
+}
+
+// resolveRegistry returns the directory of a registry location, with a
+// leading ~ expanded, and the URL of remote registries, whose directory is
+// their clone in DefaultCacheDir.
+func resolveRegistry(location string) (path, url string) {
+	if registry.IsRemote(location) {
+		return registry.NewRemote(DefaultCacheDir(), location).Dir, location
+	}
+	return expandHome(location), ""
+}
+
+// WorkspaceProjects returns the directories matched by the workspace
+// entries, sorted and without duplicates. A leading ~ expands to the home
+// directory; entries matching nothing are skipped.
+func (c *Config) WorkspaceProjects() []string {
+	seen := make(map[string]bool)
+	var projects []string
+	for _, pattern := range c.Workspace {
+		matches, err := filepath.Glob(expandHome(pattern))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+			projects = append(projects, match)
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// expandHome expands a leading ~ in path to the home directory.
+func expandHome(path string) string {
+	if len(path) > 0 && path[0] == '~' {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// StagingPath returns the import staging directory.
+func (c *Config) StagingPath() string {
+	return c.StagingPathIn(c.RegistryPath)
+}
+
+// StagingPathIn returns the import staging directory of the registry at
+// registryPath: relative staging directories are inside each registry.
+func (c *Config) StagingPathIn(registryPath string) string {
+	dir := c.Import.StagingDir
+	if dir == "" {
+		dir = registry.DefaultStagingDir
+	}
+	dir = expandHome(dir)
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(registryPath, dir)
+}
+
+// ReportConfig holds install reporting settings.
+type ReportConfig struct {
+	// URL is the webhook a JSON summary (project, items, user, target) is
+	// posted to after each installation or update. Empty means no
+	// reporting; --no-report skips it for one command.
+	URL string `mapstructure:"url"`
+}
+
+// LicenseConfig holds license compliance settings.
+type LicenseConfig struct {
+	// Require lists registry paths (globs like build.include, e.g.
+	// imported/**) whose items must declare a license.
+	Require []string `mapstructure:"require"`
+}
+
+// ListConfig holds the order items are shown in.
+type ListConfig struct {
+	// Sort is the default order: type (grouped by type, then by name),
+	// name (alphabetical) or modified (recently modified first). Empty
+	// means type. The item picker always groups items by type and applies
+	// the order within each group.
+	Sort string `mapstructure:"sort"`
+
+	// TypeOrder lists item types in the order they are shown (e.g. [stack,
+	// skill]); types not listed follow in the default order.
+	TypeOrder []string `mapstructure:"type_order"`
+}
+
+// ItemOrder returns the configured order of items.
+func (c *Config) ItemOrder() registry.ItemOrder {
+	return registry.ItemOrder{By: c.List.Sort, Types: c.List.TypeOrder}
+}
+
+// LintConfig holds content consistency check settings.
+type LintConfig struct {
+	// Disable lists checks to skip (heading, subagent-role, nested-item).
+	Disable []string `mapstructure:"disable"`
+}
+
+// SizeBudgetConfig holds the content size budget for an item type. Sizes are
+// bytes or use a KB/MB suffix; empty means no limit.
+type SizeBudgetConfig struct {
+	// Warn is the size above which validation warns.
+	Warn string `mapstructure:"warn"`
+
+	// Max is the size above which validation fails.
+	Max string `mapstructure:"max"`
+}
+
+// Budgets returns the parsed size budgets. Invalid sizes (rejected by
+// Validate) are treated as no limit.
+func (c *Config) Budgets() registry.SizeBudgets {
+	if len(c.SizeBudgets) == 0 {
+		return nil
+	}
+	budgets := make(registry.SizeBudgets, len(c.SizeBudgets))
+	for itemType, b := range c.SizeBudgets {
+		var budget registry.SizeBudget
+		if b.Warn != "" {
+			budget.Warn, _ = registry.ParseSize(b.Warn)
+		}
+		if b.Max != "" {
+			budget.Max, _ = registry.ParseSize(b.Max)
+		}
+		budgets[itemType] = budget
+	}
+	return budgets
+}
+
+// MergeBudgetSize returns the parsed merge budget in bytes, or 0 if unset or
+// invalid (rejected by Validate).
+func (c *Config) MergeBudgetSize() int {
+	if c.MergeBudget == "" {
+		return 0
+	}
+	size, _ := registry.ParseSize(c.MergeBudget)
+	return size
+}
+
+// ConfirmsMerge reports whether installing an item of itemType asks before
+// removing content from the merge file's managed section.
+func (c *Config) ConfirmsMerge(itemType string) bool {
+	if confirm, ok := c.ConfirmMerge[itemType]; ok {
+		return confirm
+	}
+	return true
 }
 
 // DefaultConfig returns the default configuration.
+//
+// Defaults: the registry lives in ~/.regis3/registry, the target is detected
+// per project, output is pretty-printed and debug output is off. Dependency
+// rules, providers, preferences and build filters are empty, meaning no
+// restrictions, no preferred alternatives and a build of the whole registry.
+// All lint checks are enabled, and item and merge file sizes are unlimited.
 func DefaultConfig() *Config {
 	paths, _ := NewPaths()
 	registryPath := ""
@@ -32,15 +375,40 @@ func DefaultConfig() *Config {
 
 	return &Config{
 		RegistryPath:  registryPath,
-		DefaultTarget: "claude",
+		DefaultTarget: "auto",
 		OutputFormat:  "pretty",
+		Icons:         "auto",
 		Debug:         false,
 	}
 }
 
-// Load loads configuration from file and environment.
+// Load loads configuration from file and environment and validates it.
+// A *ValidationError lists every invalid field.
 func Load(configPath string) (*Config, error) {
+	cfg, err := LoadUnvalidated(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		if verr, ok := err.(*ValidationError); ok {
+			verr.Path = cfg.path
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadUnvalidated loads configuration like Load but skips validation, so
+// commands that repair or diagnose a broken config can still run.
+//
+// Values are resolved in order of precedence: environment variables
+// (REGIS3_REGISTRY_PATH, ...), the config file, then DefaultConfig. Empty
+// strings in the file fall back to the default, and a leading ~ in
+// registry_path expands to the home directory. A git URL in registry_path
+// resolves to the registry's clone in DefaultCacheDir.
+func LoadUnvalidated(configPath string) (*Config, error) {
 	cfg := DefaultConfig()
+	defaults := *cfg
 
 	v := viper.New()
 	v.SetConfigType("yaml")
@@ -49,7 +417,10 @@ func Load(configPath string) (*Config, error) {
 	v.SetDefault("registry_path", cfg.RegistryPath)
 	v.SetDefault("default_target", cfg.DefaultTarget)
 	v.SetDefault("output_format", cfg.OutputFormat)
+	v.SetDefault("icons", cfg.Icons)
 	v.SetDefault("debug", cfg.Debug)
+	v.SetDefault("locale", cfg.Locale)
+	v.SetDefault("read_only", cfg.ReadOnly)
 
 	// Environment variables (REGIS3_REGISTRY_PATH, etc.)
 	v.SetEnvPrefix("REGIS3")
@@ -70,23 +441,35 @@ func Load(configPath string) (*Config, error) {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			// Only return error if it's not a "file not found" error
 			if !os.IsNotExist(err) {
-				return nil, err
+				return nil, fmt.Errorf("failed to read config %s: %w", v.ConfigFileUsed(), err)
 			}
 		}
+	} else {
+		cfg.path = v.ConfigFileUsed()
 	}
 
 	// Unmarshal into struct
 	if err := v.Unmarshal(cfg); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse config %s: %w", v.ConfigFileUsed(), err)
 	}
 
-	// Expand home directory in registry path
-	if len(cfg.RegistryPath) > 0 && cfg.RegistryPath[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			cfg.RegistryPath = filepath.Join(home, cfg.RegistryPath[1:])
-		}
+	// Explicitly empty values mean "use the default"
+	if cfg.RegistryPath == "" {
+		cfg.RegistryPath = defaults.RegistryPath
+	}
+	if cfg.DefaultTarget == "" {
+		cfg.DefaultTarget = defaults.DefaultTarget
+	}
+	if cfg.OutputFormat == "" {
+		cfg.OutputFormat = defaults.OutputFormat
 	}
+	if cfg.Icons == "" {
+		cfg.Icons = defaults.Icons
+	}
+
+	// Expand home directory in registry path; remote registries are used
+	// through their clone
+	cfg.RegistryPath, cfg.RegistryURL = resolveRegistry(cfg.RegistryPath)
 
 	return cfg, nil
 }
@@ -110,6 +493,15 @@ func DefaultRegistryPath() string {
 	return paths.RegistryDir
 }
 
+// DefaultCacheDir returns the directory remote registries are cloned into.
+func DefaultCacheDir() string {
+	paths, err := NewPaths()
+	if err != nil {
+		return filepath.Join(os.TempDir(), AppName, "registries")
+	}
+	return paths.CacheDir
+}
+
 // DefaultConfigPath returns the default config file path.
 func DefaultConfigPath() string {
 	paths, err := NewPaths()
@@ -126,10 +518,85 @@ func Save(cfg *Config, path string) error {
 	v.SetConfigType("yaml")
 	v.SetConfigFile(path)
 
-	v.Set("registry_path", cfg.RegistryPath)
+	if cfg.RegistryURL != "" {
+		v.Set("registry_path", cfg.RegistryURL)
+	} else {
+		v.Set("registry_path", cfg.RegistryPath)
+	}
 	v.Set("default_target", cfg.DefaultTarget)
 	v.Set("output_format", cfg.OutputFormat)
+	if cfg.Icons != "" {
+		v.Set("icons", cfg.Icons)
+	}
 	v.Set("debug", cfg.Debug)
+	if cfg.Locale != "" {
+		v.Set("locale", cfg.Locale)
+	}
+	if len(cfg.Registries) > 0 {
+		v.Set("registries", cfg.Registries)
+	}
+	if len(cfg.Workspace) > 0 {
+		v.Set("workspace", cfg.Workspace)
+	}
+	if cfg.ReadOnly {
+		v.Set("read_only", true)
+	}
+	if len(cfg.DependencyRules) > 0 {
+		v.Set("dependency_rules", cfg.DependencyRules)
+	}
+	if len(cfg.Providers) > 0 {
+		v.Set("providers", cfg.Providers)
+	}
+	if len(cfg.Prefer) > 0 {
+		v.Set("prefer", cfg.Prefer)
+	}
+	if len(cfg.Build.Include) > 0 {
+		v.Set("build.include", cfg.Build.Include)
+	}
+	if len(cfg.Build.Exclude) > 0 {
+		v.Set("build.exclude", cfg.Build.Exclude)
+	}
+	if len(cfg.Build.Frontmatter) > 0 {
+		v.Set("build.frontmatter", cfg.Build.Frontmatter)
+	}
+	if len(cfg.Build.MetaKeys) > 0 {
+		v.Set("build.meta_keys", cfg.Build.MetaKeys)
+	}
+	if len(cfg.Lint.Disable) > 0 {
+		v.Set("lint.disable", cfg.Lint.Disable)
+	}
+	if len(cfg.SizeBudgets) > 0 {
+		budgets := make(map[string]map[string]string, len(cfg.SizeBudgets))
+		for itemType, b := range cfg.SizeBudgets {
+			budget := make(map[string]string)
+			if b.Warn != "" {
+				budget["warn"] = b.Warn
+			}
+			if b.Max != "" {
+				budget["max"] = b.Max
+			}
+			budgets[itemType] = budget
+		}
+		v.Set("size_budgets", budgets)
+	}
+	if cfg.Import.DescribeCommand != "" {
+		v.Set("import.describe_command", cfg.Import.DescribeCommand)
+	}
+	if cfg.Import.StagingDir != "" {
+		v.Set("import.staging_dir", cfg.Import.StagingDir)
+	}
+	if cfg.MergeBudget != "" {
+		v.Set("merge_budget", cfg.MergeBudget)
+	}
+	if len(cfg.ConfirmMerge) > 0 {
+		v.Set("confirm_merge", cfg.ConfirmMerge)
+	}
+	if cfg.Report.URL != "" {
+		v.Set("report.url", cfg.Report.URL)
+	}
+	if len(cfg.License.Require) > 0 {
+		v.Set("license.require", cfg.License.Require)
+	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
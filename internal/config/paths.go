@@ -43,6 +43,9 @@ type Paths struct {
 	// RegistryDir is the registry directory path.
 	RegistryDir string
 
+	// CacheDir holds the clones of remote registries.
+	CacheDir string
+
 	// WorkDir is the current working directory.
 	WorkDir string
 }
@@ -66,6 +69,7 @@ func NewPaths() (*Paths, error) {
 		ConfigDir:   configDir,
 		ConfigFile:  filepath.Join(configDir, DefaultConfigFile),
 		RegistryDir: filepath.Join(configDir, "registry"),
+		CacheDir:    filepath.Join(configDir, "cache", "registries"),
 		WorkDir:     workDir,
 	}, nil
 }
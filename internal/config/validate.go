@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"github.com/okto-digital/regis3/pkg/refs"
+)
+
+// KnownTargets lists the values accepted for default_target. "auto" picks
+// the target per project from the tool directories it contains.
+var KnownTargets = []string{"auto", "claude", "codex", "cursor", "gpt"}
+
+// OutputFormats lists the values accepted for output_format.
+var OutputFormats = []string{"pretty", "json", "quiet", "vscode"}
+
+// IconSets lists the values accepted for icons.
+var IconSets = []string{"auto", "unicode", "ascii", "none"}
+
+// FieldError describes a single invalid configuration value.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationError collects every problem found in a configuration.
+type ValidationError struct {
+	// Path is the config file the values were read from, if any.
+	Path   string
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		fmt.Fprintf(&b, "invalid config %s:", e.Path)
+	} else {
+		b.WriteString("invalid config:")
+	}
+	for _, fe := range e.Errors {
+		fmt.Fprintf(&b, "\n  - %s", fe.Error())
+	}
+	return b.String()
+}
+
+// Validate checks the configuration and returns a *ValidationError listing
+// all invalid fields, or nil if the configuration is usable.
+func (c *Config) Validate() error {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.RegistryPath == "" {
+		add("registry_path", "must be set (run 'regis3 init' or 'regis3 config set registry <path>')")
+	}
+	if c.RegistryURL != "" {
+		if err := registry.ValidateRemoteURL(c.RegistryURL); err != nil {
+			add("registry_path", "%s", err)
+		}
+	}
+	if !contains(KnownTargets, c.DefaultTarget) {
+		add("default_target", "must be one of %s (got %q)", strings.Join(KnownTargets, ", "), c.DefaultTarget)
+	}
+	if !contains(IconSets, c.Icons) {
+		add("icons", "must be one of %s (got %q)", strings.Join(IconSets, ", "), c.Icons)
+	}
+	if c.Locale != "" && !i18n.IsSupported(i18n.Normalize(c.Locale)) {
+		add("locale", "must be one of %s (got %q)", strings.Join(i18n.Locales, ", "), c.Locale)
+	}
+	if !contains(OutputFormats, c.OutputFormat) {
+		add("output_format", "must be one of %s (got %q)", strings.Join(OutputFormats, ", "), c.OutputFormat)
+	}
+
+	for itemType, allowed := range c.DependencyRules {
+		if !registry.IsValidType(itemType) {
+			add("dependency_rules", "has unknown item type %q", itemType)
+		}
+		for _, depType := range allowed {
+			if !registry.IsValidType(depType) {
+				add("dependency_rules."+itemType, "has unknown item type %q", depType)
+			}
+		}
+	}
+
+	for capability, provider := range c.Providers {
+		if !registry.IsCapability(capability) {
+			add("providers", "key %q must start with %q", capability, registry.CapabilityPrefix)
+		}
+		if !isItemRef(provider) {
+			add("providers."+capability, "must be an item reference like skill:name (got %q)", provider)
+		}
+	}
+
+	for name, path := range c.Registries {
+		switch {
+		case name == DefaultRegistryName:
+			add("registries", "must not name a registry %q (it is registry_path)", name)
+		case !refs.IsRegistryName(name):
+			add("registries", "has invalid name %q (use lowercase letters, digits, '-', '_' and '.')", name)
+		case path == "":
+			add("registries."+name, "must not be empty")
+		case registry.IsRemote(path):
+			if err := registry.ValidateRemoteURL(path); err != nil {
+				add("registries."+name, "%s", err)
+			}
+		}
+	}
+
+	for _, pattern := range c.Workspace {
+		if pattern == "" {
+			add("workspace", "must not have empty entries")
+		} else if _, err := filepath.Match(pattern, ""); err != nil {
+			add("workspace", "has invalid pattern %q", pattern)
+		}
+	}
+
+	for _, ref := range c.Prefer {
+		if !isItemRef(ref) {
+			add("prefer", "entry must be an item reference like skill:name (got %q)", ref)
+		}
+	}
+
+	filter := registry.Filter{Include: c.Build.Include, Exclude: c.Build.Exclude}
+	if err := filter.Validate(); err != nil {
+		add("build", "has an %s", err.Error())
+	}
+
+	if err := (registry.Filter{Include: c.License.Require}).Validate(); err != nil {
+		add("license.require", "has an %s", err.Error())
+	}
+
+	for _, format := range c.Build.Frontmatter {
+		if !frontmatter.IsFormat(frontmatter.Format(format)) {
+			add("build.frontmatter", "has unknown format %q (must be yaml or toml)", format)
+		}
+	}
+	for _, key := range c.Build.MetaKeys {
+		if !registry.IsMetaKey(key) {
+			add("build.meta_keys", "has invalid key %q", key)
+		}
+	}
+
+	for _, check := range c.Lint.Disable {
+		if !registry.IsLintCheck(check) {
+			add("lint.disable", "has unknown check %q (must be one of %s)", check, strings.Join(registry.LintChecks, ", "))
+		}
+	}
+
+	for itemType, b := range c.SizeBudgets {
+		if !registry.IsValidType(itemType) {
+			add("size_budgets", "has unknown item type %q", itemType)
+		}
+		var warn, max int
+		var err error
+		if b.Warn != "" {
+			if warn, err = registry.ParseSize(b.Warn); err != nil {
+				add("size_budgets."+itemType+".warn", "has an %s", err.Error())
+			}
+		}
+		if b.Max != "" {
+			if max, err = registry.ParseSize(b.Max); err != nil {
+				add("size_budgets."+itemType+".max", "has an %s", err.Error())
+			}
+		}
+		if warn > 0 && max > 0 && warn > max {
+			add("size_budgets."+itemType, "warn (%s) must not exceed max (%s)", b.Warn, b.Max)
+		}
+	}
+
+	if c.MergeBudget != "" {
+		if _, err := registry.ParseSize(c.MergeBudget); err != nil {
+			add("merge_budget", "has an %s", err.Error())
+		}
+	}
+
+	if c.Report.URL != "" {
+		if u, err := url.Parse(c.Report.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("report.url", "must be an http or https URL (got %q)", c.Report.URL)
+		}
+	}
+
+	for itemType := range c.ConfirmMerge {
+		if !registry.ItemType(itemType).IsMergeType() {
+			add("confirm_merge", "has %q, which is not a merge type (philosophy, project, ruleset)", itemType)
+		}
+	}
+
+	if c.List.Sort != "" && !contains(registry.SortOrders, c.List.Sort) {
+		add("list.sort", "must be one of %s (got %q)", strings.Join(registry.SortOrders, ", "), c.List.Sort)
+	}
+	for i, itemType := range c.List.TypeOrder {
+		if !registry.IsValidType(itemType) {
+			add("list.type_order", "has unknown item type %q", itemType)
+		} else if contains(c.List.TypeOrder[:i], itemType) {
+			add("list.type_order", "lists %q twice", itemType)
+		}
+	}
+
+	if c.Import.StagingDir != "" && c.RegistryPath != "" {
+		// Staged files must not be built as items
+		rel, err := filepath.Rel(c.StagingPath(), c.RegistryPath)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			add("import.staging_dir", "must not be or contain the registry (got %q)", c.Import.StagingDir)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	// Map iteration order is random; keep the report stable
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return &ValidationError{Errors: errs}
+}
+
+// isItemRef reports whether s is a normalized local reference type:name
+// with a known type.
+func isItemRef(s string) bool {
+	r, err := refs.Parse(s)
+	return err == nil && r.IsLocal() && r.ID() == s && registry.IsValidType(r.Type)
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			RegistryPath:  "/tmp/registry",
+			DefaultTarget: "claude",
+			OutputFormat:  "pretty",
+			Icons:         "auto",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "defaults are valid",
+			modify: func(c *Config) {},
+		},
+		{
+			name:   "unknown target",
+			modify: func(c *Config) { c.DefaultTarget = "vim" },
+			want:   []string{`default_target must be one of auto, claude, codex, cursor, gpt (got "vim")`},
+		},
+		{
+			name:   "unknown icon set",
+			modify: func(c *Config) { c.Icons = "emoji" },
+			want:   []string{`icons must be one of auto, unicode, ascii, none (got "emoji")`},
+		},
+		{
+			name:   "supported locale",
+			modify: func(c *Config) { c.Locale = "de_DE.UTF-8" },
+		},
+		{
+			name:   "unsupported locale",
+			modify: func(c *Config) { c.Locale = "fr" },
+			want:   []string{`locale must be one of en, de (got "fr")`},
+		},
+		{
+			name:   "unknown output format",
+			modify: func(c *Config) { c.OutputFormat = "xml" },
+			want:   []string{`output_format must be one of pretty, json, quiet, vscode (got "xml")`},
+		},
+		{
+			name:   "missing registry",
+			modify: func(c *Config) { c.RegistryPath = "" },
+			want:   []string{"registry_path must be set (run 'regis3 init' or 'regis3 config set registry <path>')"},
+		},
+		{
+			name: "dependency rules with unknown types",
+			modify: func(c *Config) {
+				c.DependencyRules = map[string][]string{"widget": {"skill"}, "skill": {"gadget"}}
+			},
+			want: []string{
+				`dependency_rules has unknown item type "widget"`,
+				`dependency_rules.skill has unknown item type "gadget"`,
+			},
+		},
+		{
+			name: "provider and preference references",
+			modify: func(c *Config) {
+				c.Providers = map[string]string{"capability:git": "skill:git-flow", "git": "git-flow"}
+				c.Prefer = []string{"skill:vitest", "jest"}
+			},
+			want: []string{
+				`prefer entry must be an item reference like skill:name (got "jest")`,
+				`providers key "git" must start with "capability:"`,
+				`providers.git must be an item reference like skill:name (got "git-flow")`,
+			},
+		},
+		{
+			name: "size budgets",
+			modify: func(c *Config) {
+				c.SizeBudgets = map[string]SizeBudgetConfig{
+					"philosophy": {Warn: "2KB"},
+					"skill":      {Warn: "40KB", Max: "20KB"},
+					"doc":        {Max: "lots"},
+					"widget":     {Warn: "1KB"},
+				}
+			},
+			want: []string{
+				`size_budgets has unknown item type "widget"`,
+				`size_budgets.doc.max has an invalid size "lots" (use bytes or a KB/MB suffix, e.g. 20KB)`,
+				`size_budgets.skill warn (40KB) must not exceed max (20KB)`,
+			},
+		},
+		{
+			name:   "merge budget",
+			modify: func(c *Config) { c.MergeBudget = "16 kilobytes" },
+			want:   []string{`merge_budget has an invalid size "16 kilobytes" (use bytes or a KB/MB suffix, e.g. 20KB)`},
+		},
+		{
+			name:   "confirm merge",
+			modify: func(c *Config) { c.ConfirmMerge = map[string]bool{"philosophy": false, "skill": true} },
+			want:   []string{`confirm_merge has "skill", which is not a merge type (philosophy, project, ruleset)`},
+		},
+		{
+			name: "list order",
+			modify: func(c *Config) {
+				c.List.Sort = "modified"
+				c.List.TypeOrder = []string{"stack", "skill"}
+			},
+		},
+		{
+			name: "invalid list order",
+			modify: func(c *Config) {
+				c.List.Sort = "size"
+				c.List.TypeOrder = []string{"stack", "widget", "stack"}
+			},
+			want: []string{
+				`list.sort must be one of type, name, modified (got "size")`,
+				`list.type_order has unknown item type "widget"`,
+				`list.type_order lists "stack" twice`,
+			},
+		},
+		{
+			name:   "staging directory in the registry",
+			modify: func(c *Config) { c.Import.StagingDir = ".staging" },
+		},
+		{
+			name:   "staging directory outside the registry",
+			modify: func(c *Config) { c.Import.StagingDir = "/tmp/regis3-staging" },
+		},
+		{
+			name:   "staging directory containing the registry",
+			modify: func(c *Config) { c.Import.StagingDir = "/tmp" },
+			want:   []string{`import.staging_dir must not be or contain the registry (got "/tmp")`},
+		},
+		{
+			name: "registries",
+			modify: func(c *Config) {
+				c.Registries = map[string]string{"team": "~/team", "default": "/tmp/other", "My Team": "/tmp/mine", "empty": "", "evil": "--upload-pack=x://y"}
+			},
+			want: []string{
+				`registries has invalid name "My Team" (use lowercase letters, digits, '-', '_' and '.')`,
+				`registries must not name a registry "default" (it is registry_path)`,
+				`registries.empty must not be empty`,
+				`registries.evil git URL "--upload-pack=x://y" must not start with '-'`,
+			},
+		},
+		{
+			name: "workspace",
+			modify: func(c *Config) {
+				c.Workspace = []string{"~/code/*", "", "~/code/[a-"}
+			},
+			want: []string{
+				`workspace has invalid pattern "~/code/[a-"`,
+				`workspace must not have empty entries`,
+			},
+		},
+		{
+			name: "report url",
+			modify: func(c *Config) {
+				c.Report.URL = "hooks.example.com/regis3"
+			},
+			want: []string{
+				`report.url must be an http or https URL (got "hooks.example.com/regis3")`,
+			},
+		},
+		{
+			name: "license requirement",
+			modify: func(c *Config) {
+				c.License.Require = []string{"imported/**", "vendor/[a-"}
+			},
+			want: []string{
+				`license.require has an invalid pattern "vendor/[a-": syntax error in pattern`,
+			},
+		},
+		{
+			name: "frontmatter dialect",
+			modify: func(c *Config) {
+				c.Build.Frontmatter = []string{"yaml", "json"}
+				c.Build.MetaKeys = []string{"meta.regis3", "meta."}
+			},
+			want: []string{
+				`build.frontmatter has unknown format "json" (must be yaml or toml)`,
+				`build.meta_keys has invalid key "meta."`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			var got []string
+			for _, fe := range verr.Errors {
+				got = append(got, fe.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_StagingPath(t *testing.T) {
+	tests := []struct {
+		staging string
+		want    string
+	}{
+		{"", "/srv/registry/import"},
+		{".staging", "/srv/registry/.staging"},
+		{"/var/regis3/staging", "/var/regis3/staging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.staging, func(t *testing.T) {
+			c := &Config{RegistryPath: "/srv/registry", Import: ImportConfig{StagingDir: tt.staging}}
+			assert.Equal(t, filepath.FromSlash(tt.want), c.StagingPath())
+		})
+	}
+}
+
+func TestConfig_AllRegistries(t *testing.T) {
+	c := &Config{
+		RegistryPath: "/srv/registry",
+		Registries:   map[string]string{"team": "/srv/team", "archive": "/srv/archive"},
+	}
+	assert.Equal(t, []NamedRegistry{
+		{Name: DefaultRegistryName, Path: "/srv/registry"},
+		{Name: "archive", Path: "/srv/archive"},
+		{Name: "team", Path: "/srv/team"},
+	}, c.AllRegistries())
+
+	assert.Equal(t, filepath.FromSlash("/srv/team/import"), c.StagingPathIn("/srv/team"))
+}
+
+func TestConfig_RemoteRegistries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	url := "git@github.com:org/registry.git"
+	c := &Config{
+		RegistryPath: "/srv/registry",
+		Registries:   map[string]string{"team": url},
+	}
+
+	all := c.AllRegistries()
+	require.Len(t, all, 2)
+	assert.Equal(t, url, all[1].URL)
+	assert.Equal(t, DefaultCacheDir(), filepath.Dir(all[1].Path))
+	assert.Equal(t, all[1].Path, all[1].Remote().Dir)
+	assert.Nil(t, all[0].Remote())
+
+	c.UseRegistry("team")
+	assert.Equal(t, all[1].Path, c.RegistryPath)
+	assert.Equal(t, url, c.RegistryURL)
+
+	c.UseRegistry("/srv/other")
+	assert.Equal(t, "/srv/other", c.RegistryPath)
+	assert.Empty(t, c.RegistryURL)
+
+	// The URL is saved, not the clone
+	c.UseRegistry(url)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, Save(c, path))
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, url, loaded.RegistryURL)
+	assert.Equal(t, c.RegistryPath, loaded.RegistryPath)
+}
+
+func TestConfig_WorkspaceProjects(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"api", "web", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if name == "notes.txt" {
+			require.NoError(t, os.WriteFile(path, nil, 0644))
+			continue
+		}
+		require.NoError(t, os.Mkdir(path, 0755))
+	}
+
+	c := &Config{Workspace: []string{filepath.Join(dir, "*"), filepath.Join(dir, "web"), filepath.Join(dir, "missing")}}
+	assert.Equal(t, []string{filepath.Join(dir, "api"), filepath.Join(dir, "web")}, c.WorkspaceProjects())
+}
+
+func TestConfig_ConfirmsMerge(t *testing.T) {
+	c := &Config{ConfirmMerge: map[string]bool{"philosophy": false, "project": true}}
+	assert.False(t, c.ConfirmsMerge("philosophy"))
+	assert.True(t, c.ConfirmsMerge("project"))
+	assert.True(t, c.ConfirmsMerge("ruleset"), "types without an entry ask")
+	assert.True(t, (&Config{}).ConfirmsMerge("ruleset"))
+}
+
+func TestConfig_ReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, Save(&Config{RegistryPath: "/srv/registry", ReadOnly: true}, path))
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.ReadOnly)
+
+	require.NoError(t, Save(&Config{RegistryPath: "/srv/registry"}, path))
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	assert.False(t, loaded.ReadOnly)
+
+	t.Setenv("REGIS3_READ_ONLY", "true")
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.ReadOnly)
+}
@@ -0,0 +1,223 @@
+// Package crypt encrypts item bodies at rest, for registries kept on shared
+// drives. Bodies are sealed with AES-256-GCM and stored as an armored block
+// in place of the markdown; the frontmatter stays readable.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Environment variables the key is read from, in order of precedence.
+const (
+	// KeyEnv holds the base64-encoded key.
+	KeyEnv = "REGIS3_KEY"
+
+	// KeyFileEnv names a file holding the key.
+	KeyFileEnv = "REGIS3_KEY_FILE"
+
+	// KeyCommandEnv is a shell command printing the key, e.g. a password
+	// manager or agent lookup.
+	KeyCommandEnv = "REGIS3_KEY_COMMAND"
+)
+
+// KeySize is the length of a key in bytes.
+const KeySize = 32
+
+// Armor lines around an encrypted body.
+const (
+	beginPrefix = "<!-- regis3:encrypted key="
+	beginSuffix = " -->"
+	endLine     = "<!-- /regis3:encrypted -->"
+	lineWidth   = 76
+)
+
+// ErrNoKey indicates that no key is configured.
+var ErrNoKey = fmt.Errorf("no encryption key (set %s, %s or %s)", KeyEnv, KeyFileEnv, KeyCommandEnv)
+
+// Key is an encryption key.
+type Key [KeySize]byte
+
+// GenerateKey returns a new random key.
+func GenerateKey() (Key, error) {
+	var k Key
+	if _, err := rand.Read(k[:]); err != nil {
+		return k, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return k, nil
+}
+
+// ParseKey decodes a base64-encoded key.
+func ParseKey(s string) (Key, error) {
+	var k Key
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return k, fmt.Errorf("invalid key: %w", err)
+	}
+	if len(data) != KeySize {
+		return k, fmt.Errorf("invalid key: %d bytes, expected %d", len(data), KeySize)
+	}
+	copy(k[:], data)
+	return k, nil
+}
+
+// String returns the key base64-encoded, as ParseKey reads it.
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// ID identifies the key without revealing it, so encrypted bodies can
+// tell which key sealed them.
+func (k Key) ID() string {
+	sum := sha256.Sum256(k[:])
+	return hex.EncodeToString(sum[:6])
+}
+
+// LoadKey reads the key from the environment: KeyEnv, else the file named
+// by KeyFileEnv, else the output of KeyCommandEnv. It returns ErrNoKey if
+// none is set.
+func LoadKey() (Key, error) {
+	if s := os.Getenv(KeyEnv); s != "" {
+		return ParseKey(s)
+	}
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to read key file: %w", err)
+		}
+		return ParseKey(string(data))
+	}
+	if command := os.Getenv(KeyCommandEnv); command != "" {
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/c"
+		}
+		cmd := exec.Command(shell, flag, command)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return Key{}, fmt.Errorf("key command failed: %w", err)
+		}
+		return ParseKey(string(out))
+	}
+	return Key{}, ErrNoKey
+}
+
+var (
+	defaultOnce sync.Once
+	defaultKey  Key
+	defaultErr  error
+)
+
+// DefaultKey returns the key from LoadKey, loading it once per process so
+// a key command runs only once.
+func DefaultKey() (Key, error) {
+	defaultOnce.Do(func() {
+		defaultKey, defaultErr = LoadKey()
+	})
+	return defaultKey, defaultErr
+}
+
+// IsEncrypted reports whether body is an encrypted block.
+func IsEncrypted(body string) bool {
+	return strings.HasPrefix(strings.TrimLeft(body, "\r\n"), beginPrefix)
+}
+
+// KeyID returns the ID of the key an encrypted body was sealed with.
+func KeyID(body string) (string, bool) {
+	if !IsEncrypted(body) {
+		return "", false
+	}
+	first, _, _ := strings.Cut(strings.TrimLeft(body, "\r\n"), "\n")
+	first = strings.TrimSpace(first)
+	if !strings.HasSuffix(first, beginSuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(first, beginPrefix), beginSuffix), true
+}
+
+// Encrypt seals body with key and returns the armored block.
+func Encrypt(key Key, body string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(body), []byte(key.ID()))
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+
+	var b strings.Builder
+	b.WriteString(beginPrefix + key.ID() + beginSuffix + "\n")
+	for len(encoded) > lineWidth {
+		b.WriteString(encoded[:lineWidth] + "\n")
+		encoded = encoded[lineWidth:]
+	}
+	b.WriteString(encoded + "\n")
+	b.WriteString(endLine + "\n")
+	return b.String(), nil
+}
+
+// Decrypt opens an armored block sealed with key. Bodies that aren't
+// encrypted are returned unchanged.
+func Decrypt(key Key, body string) (string, error) {
+	if !IsEncrypted(body) {
+		return body, nil
+	}
+	id, ok := KeyID(body)
+	if !ok {
+		return "", errors.New("malformed encrypted body")
+	}
+	if id != key.ID() {
+		return "", fmt.Errorf("encrypted with key %s, but the configured key is %s", id, key.ID())
+	}
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[len(lines)-1]) != endLine {
+		return "", errors.New("malformed encrypted body: missing end marker")
+	}
+	var encoded strings.Builder
+	for _, line := range lines[1 : len(lines)-1] {
+		encoded.WriteString(strings.TrimSpace(line))
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted body: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted body: too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", errors.New("failed to decrypt body: wrong key or modified content")
+	}
+	return string(plain), nil
+}
+
+// newGCM returns the AES-GCM cipher for key.
+func newGCM(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
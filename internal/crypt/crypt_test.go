@@ -0,0 +1,112 @@
+package crypt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	body := "# Testing\n\n" + strings.Repeat("Write table-driven tests.\n", 20)
+	sealed, err := Encrypt(key, body)
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(sealed))
+	assert.NotContains(t, sealed, "table-driven")
+	id, ok := KeyID(sealed)
+	require.True(t, ok)
+	assert.Equal(t, key.ID(), id)
+	for _, line := range strings.Split(strings.TrimSpace(sealed), "\n") {
+		assert.LessOrEqual(t, len(line), lineWidth+len(beginPrefix))
+	}
+
+	plain, err := Decrypt(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, body, plain)
+
+	// Plain bodies pass through
+	plain, err = Decrypt(key, body)
+	require.NoError(t, err)
+	assert.Equal(t, body, plain)
+	assert.False(t, IsEncrypted(body))
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := GenerateKey()
+	require.NoError(t, err)
+
+	sealed, err := Encrypt(key, "# Secret\n")
+	require.NoError(t, err)
+
+	_, err = Decrypt(other, sealed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), key.ID())
+
+	lines := strings.Split(sealed, "\n")
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing end marker", strings.Join(lines[:len(lines)-2], "\n")},
+		{"not base64", lines[0] + "\n!!!\n" + endLine + "\n"},
+		{"too short", lines[0] + "\nAAAA\n" + endLine + "\n"},
+		{"modified", lines[0] + "\n" + strings.Replace(lines[1], lines[1][:4], "AAAA", 1) + "\n" + strings.Join(lines[2:], "\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(key, tt.body)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	parsed, err := ParseKey(" " + key.String() + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseKey("not a key")
+	assert.Error(t, err)
+	_, err = ParseKey("c2hvcnQ=")
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	t.Setenv(KeyEnv, "")
+	t.Setenv(KeyFileEnv, "")
+	t.Setenv(KeyCommandEnv, "")
+	_, err = LoadKey()
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	t.Setenv(KeyCommandEnv, "echo "+key.String())
+	loaded, err := LoadKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(key.String()+"\n"), 0600))
+	t.Setenv(KeyFileEnv, path)
+	t.Setenv(KeyCommandEnv, "false")
+	loaded, err = LoadKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	t.Setenv(KeyEnv, "invalid")
+	_, err = LoadKey()
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+// Package ctl is the control socket of an interactive regis3 session. While
+// the picker is open, scripts and editor plugins send actions to it (e.g.
+// rebuilding the registry or installing items) instead of running them in a
+// separate process, so the session shows the results and the project's
+// trackers are only written by one process.
+package ctl
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ErrNoSession is returned by Send when no session listens on the socket.
+	ErrNoSession = errors.New("no regis3 session is running")
+
+	// ErrSessionRunning is returned by Listen when another session already
+	// listens on the socket.
+	ErrSessionRunning = errors.New("another regis3 session is running")
+)
+
+// requestTimeout bounds how long Send waits for a reply; installs may take
+// a while.
+const requestTimeout = 5 * time.Minute
+
+// Request is an action for the session to perform.
+type Request struct {
+	// Action names the action, e.g. "build" or "install".
+	Action string `json:"action"`
+
+	// Args are the action's arguments, e.g. the item refs to install.
+	Args []string `json:"args,omitempty"`
+}
+
+// Handler performs a request and returns the reply, which is sent to the
+// client as JSON.
+type Handler func(req Request) any
+
+// SocketPath returns the control socket of sessions in projectDir. Sockets
+// live in a directory only the user can access, as project paths may be too
+// long for a socket address: $XDG_RUNTIME_DIR, or a regis3 directory per
+// user in the temporary directory.
+func SocketPath(projectDir string) (string, error) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(socketDir(), fmt.Sprintf("regis3-%x.sock", sum[:8])), nil
+}
+
+// socketDir returns the directory of the user's control sockets.
+func socketDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("regis3-%d", os.Getuid()))
+}
+
+// privateDir creates dir if it doesn't exist, and checks that only the
+// user can access it, so no one else can take the socket's place or
+// connect to it before its permissions are set.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if info.Mode().Perm()&0077 != 0 || !ownedByUser(info) {
+		return fmt.Errorf("%s must be a directory only you can access (mode 0700)", dir)
+	}
+	return nil
+}
+
+// Server accepts requests on a control socket.
+type Server struct {
+	listener net.Listener
+	handler  Handler
+}
+
+// Listen starts serving requests on the socket at path, which must be in a
+// directory only the user can access; it is created if missing. A socket
+// left behind by a session that exited is replaced.
+func Listen(path string, handler Handler) (*Server, error) {
+	if err := privateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, ErrSessionRunning
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Only the user running the session may control it
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &Server{listener: listener, handler: handler}
+	go s.serve()
+	return s, nil
+}
+
+// Close stops serving and removes the socket.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+// serve accepts connections until the server is closed.
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle answers the single request of a connection.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	json.NewEncoder(conn).Encode(s.handler(req))
+}
+
+// Send sends req to the session listening on the socket at path and decodes
+// its reply into reply.
+func Send(path string, req Request, reply any) error {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return ErrNoSession
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(conn).Decode(reply); err != nil {
+		return fmt.Errorf("reading reply: %w", err)
+	}
+	return nil
+}
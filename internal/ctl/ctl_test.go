@@ -0,0 +1,98 @@
+package ctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketPath returns a socket path short enough for a socket address.
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+func TestSend(t *testing.T) {
+	path := socketPath(t)
+	server, err := Listen(path, func(req Request) any {
+		return map[string]string{"done": req.Action + " " + strings.Join(req.Args, " ")}
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	var reply map[string]string
+	require.NoError(t, Send(path, Request{Action: "install", Args: []string{"skill:a", "skill:b"}}, &reply))
+	assert.Equal(t, "install skill:a skill:b", reply["done"])
+
+	_, err = Listen(path, func(Request) any { return nil })
+	assert.ErrorIs(t, err, ErrSessionRunning)
+}
+
+func TestSend_NoSession(t *testing.T) {
+	var reply any
+	assert.ErrorIs(t, Send(socketPath(t), Request{Action: "build"}, &reply), ErrNoSession)
+}
+
+func TestListen_StaleSocket(t *testing.T) {
+	path := socketPath(t)
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	server, err := Listen(path, func(Request) any { return "ok" })
+	require.NoError(t, err)
+	defer server.Close()
+
+	var reply string
+	require.NoError(t, Send(path, Request{Action: "build"}, &reply))
+	assert.Equal(t, "ok", reply)
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	a, err := SocketPath("/home/user/project")
+	require.NoError(t, err)
+	b, err := SocketPath("/home/user/other")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, filepath.Join(os.TempDir(), fmt.Sprintf("regis3-%d", os.Getuid())), filepath.Dir(a))
+	assert.True(t, strings.HasPrefix(filepath.Base(a), "regis3-"))
+
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	c, err := SocketPath("/home/user/project")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, filepath.Base(a)), c)
+}
+
+func TestListen_PrivateDir(t *testing.T) {
+	t.Run("missing directory is created", func(t *testing.T) {
+		path := filepath.Join(filepath.Dir(socketPath(t)), "run", "s.sock")
+		server, err := Listen(path, func(Request) any { return "ok" })
+		require.NoError(t, err)
+		defer server.Close()
+
+		info, err := os.Stat(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	})
+
+	t.Run("shared directory is refused", func(t *testing.T) {
+		path := socketPath(t)
+		require.NoError(t, os.Chmod(filepath.Dir(path), 0777))
+
+		_, err := Listen(path, func(Request) any { return "ok" })
+		assert.ErrorContains(t, err, "only you can access")
+		assert.NoFileExists(t, path)
+	})
+}
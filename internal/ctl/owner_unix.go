@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package ctl
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByUser reports whether the file is owned by the user running
+// regis3.
+func ownedByUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
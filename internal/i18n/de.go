@@ -0,0 +1,374 @@
+package i18n
+
+// german translates CLI messages to German.
+var german = Catalog{
+	// Output labels
+	"Total:":                "Gesamt:",
+	"Note:":                 "Hinweis:",
+	"TARGET":                "ZIEL",
+	"ITEM":                  "ELEMENT",
+	"STATUS":                "STATUS",
+	"HASH":                  "HASH",
+	"LICENSE":               "LIZENZ",
+	"REGISTRY":              "REGISTRY",
+	"ITEMS":                 "ELEMENTE",
+	"BUILD":                 "BUILD",
+	"WARNED":                "WARNUNGEN",
+	"SIZE":                  "GRÖSSE",
+	"SCORE":                 "WERTUNG",
+	"HEALTH":                "ZUSTAND",
+	"%d errors":             "%d Fehler",
+	"%d warnings":           "%d Warnungen",
+	"ok":                    "ok",
+	"update available":      "Update verfügbar",
+	"pinned":                "fixiert",
+	"local override":        "lokale Überschreibung",
+	"merged into %s":        "zusammengeführt in %s",
+	"removed from registry": "aus der Registry entfernt",
+	"missing":               "fehlt",
+	"modified":              "lokal geändert",
+	"(mentioned as %q)":     "(erwähnt als %q)",
+	"(matches %s)":          "(passt zu %s)",
+
+	// Relative times
+	"installed %s":       "installiert %s",
+	"updated %s":         "aktualisiert %s",
+	"at an unknown time": "zu unbekannter Zeit",
+	"just now":           "gerade eben",
+	"1 minute ago":       "vor 1 Minute",
+	"%d minutes ago":     "vor %d Minuten",
+	"1 hour ago":         "vor 1 Stunde",
+	"%d hours ago":       "vor %d Stunden",
+	"yesterday":          "gestern",
+	"%d days ago":        "vor %d Tagen",
+	"1 month ago":        "vor 1 Monat",
+	"%d months ago":      "vor %d Monaten",
+	"1 year ago":         "vor 1 Jahr",
+	"%d years ago":       "vor %d Jahren",
+
+	// Pretty writer
+	"%s %d items":                           "%s %d Elemente",
+	"%s Build complete":                     "%s Build abgeschlossen",
+	"   Items:    %d":                       "   Elemente:    %d",
+	"   Excluded: %d":                       "   Ausgeschlossen: %d",
+	"   Updated:  %s":                       "   Aktualisiert: %s",
+	"   Removed:  %s":                       "   Entfernt:  %s",
+	"   Path:     %s":                       "   Pfad:     %s",
+	"   Health:   %d/100":                   "   Zustand:  %d/100",
+	"   Duration: %s":                       "   Dauer:    %s",
+	"Aliases: %s":                           "Aliasse: %s",
+	"Tags: %s":                              "Tags: %s",
+	"Dependencies:":                         "Abhängigkeiten:",
+	"One of:":                               "Eines von:",
+	"Provides:":                             "Stellt bereit:",
+	"Files:":                                "Dateien:",
+	"Source: %s":                            "Quelle: %s",
+	"%s Installed:":                         "%s Installiert:",
+	"%s Skipped:":                           "%s Übersprungen:",
+	"%s (dry run - no changes made)":        "%s (Probelauf – keine Änderungen vorgenommen)",
+	"Validated %d items":                    "%d Elemente validiert",
+	"  %s Errors:   %d":                     "  %s Fehler:   %d",
+	"  %s Warnings: %d":                     "  %s Warnungen: %d",
+	"  %s Info:     %d":                     "  %s Hinweise: %d",
+	"  %s All items valid":                  "  %s Alle Elemente gültig",
+	"Target: %s %s":                         "Ziel: %s %s",
+	"No items installed":                    "Keine Elemente installiert",
+	"No items found":                        "Keine Elemente gefunden",
+	"Installed items (%s):":                 "Installierte Elemente (%s):",
+	"%s Would import:":                      "%s Würde importieren:",
+	"%s Imported:":                          "%s Importiert:",
+	"%s Would stage:":                       "%s Würde bereitstellen:",
+	"%s Would split into staged items:":     "%s Würde in bereitgestellte Elemente aufteilen:",
+	"%s Split into staged items:":           "%s In bereitgestellte Elemente aufgeteilt:",
+	"\"# %s\", %d lines":                    "\"# %s\", %d Zeilen",
+	"%s Staged (need regis3 frontmatter):":  "%s Bereitgestellt (regis3-Frontmatter fehlt):",
+	"%s Errors:":                            "%s Fehler:",
+	"%s Processed:":                         "%s Verarbeitet:",
+	"%s Pending (need regis3 frontmatter):": "%s Ausstehend (regis3-Frontmatter fehlt):",
+	"%s Merged:":                            "%s Zusammengeführt:",
+	"as %s: %s":                             "als %s: %s",
+	"%s Flagged for review (possible prompt injection):":     "%s Zur Prüfung markiert (mögliche Prompt-Injection):",
+	"%s Registry updated":                                    "%s Registry aktualisiert",
+	"%s Already up to date":                                  "%s Bereits aktuell",
+	"   Items: %d":                                           "   Elemente: %d",
+	"%s No orphaned files found":                             "%s Keine verwaisten Dateien gefunden",
+	"%s Found %d orphaned files:":                            "%s %d verwaiste Dateien gefunden:",
+	"%s No missing dependencies found for %s":                "%s Keine fehlenden Abhängigkeiten für %s gefunden",
+	"%s Added %d dependencies to %s:":                        "%s %d Abhängigkeiten zu %s hinzugefügt:",
+	"%s %s mentions %d items not in deps:":                   "%s %s erwähnt %d Elemente, die nicht in deps stehen:",
+	"%s No languages or frameworks detected in this project": "%s Keine Sprachen oder Frameworks in diesem Projekt erkannt",
+	"Languages:  %s":                                         "Sprachen:   %s",
+	"Frameworks: %s":                                         "Frameworks: %s",
+	"%s No registry items match this project":                "%s Keine Registry-Elemente passen zu diesem Projekt",
+	"%s %d registry items match this project:":               "%s %d Registry-Elemente passen zu diesem Projekt:",
+	"%s No executable content found in %d items":             "%s Kein ausführbarer Inhalt in %d Elementen gefunden",
+	"%s %d of %d items contain executable content:":          "%s %d von %d Elementen enthalten ausführbaren Inhalt:",
+	"%s Upgraded regis3 %s %s %s":                            "%s regis3 aktualisiert: %s %s %s",
+	"   Path: %s":                                            "   Pfad: %s",
+	"%s regis3 %s is available (current: %s)":                "%s regis3 %s ist verfügbar (aktuell: %s)",
+	"%s regis3 %s is up to date":                             "%s regis3 %s ist aktuell",
+	"  Built:    %s":                                         "  Gebaut:   %s",
+	"  Commit:   %s":                                         "  Commit:   %s",
+	"  Platform: %s":                                         "  Plattform: %s",
+	"  Manifest: %s":                                         "  Manifest: %s",
+	"  Built by: %s":                                         "  Gebaut von: %s",
+	"%s Generated packaging for regis3 %s":                   "%s Paketdateien für regis3 %s erzeugt",
+	"Registry health: %s":                                    "Zustand der Registry: %s",
+	"With validation warnings (%d of %d items):":             "Mit Validierungswarnungen (%d von %d Elementen):",
+	"Without tags (%d of %d items):":                         "Ohne Tags (%d von %d Elementen):",
+	"Without author (%d of %d items):":                       "Ohne Autor (%d von %d Elementen):",
+	"Stale drafts (%d of %d items):":                         "Veraltete Entwürfe (%d von %d Elementen):",
+	"Orphaned files (%d):":                                   "Verwaiste Dateien (%d):",
+	"%s Wrote badge to %s":                                   "%s Badge nach %s geschrieben",
+	"%s No stale items (%d items scanned)":                   "%s Keine veralteten Elemente (%d Elemente geprüft)",
+	"%s %d of %d items unchanged for %d days:":               "%s %d von %d Elementen seit %d Tagen unverändert:",
+	"Config: %s":                                             "Konfiguration: %s",
+	"%s No registry items depend on %s":                      "%s Keine Registry-Elemente hängen von %s ab",
+	"%s %d registry items depend on %s:":                     "%s %d Registry-Elemente hängen von %s ab:",
+	"%s No projects affected (%d projects scanned)":          "%s Keine Projekte betroffen (%d Projekte geprüft)",
+	"%s %d of %d projects affected:":                         "%s %d von %d Projekten betroffen:",
+	"%s Completed in %v":                                     "%s Abgeschlossen in %v",
+
+	// Command messages
+	"No projects to check (set workspace in the config or use --projects)":               "Keine Projekte zu prüfen (workspace in der Konfiguration setzen oder --projects verwenden)",
+	"%d files kept in staging for review (possible prompt injection)":                    "%d Dateien zur Prüfung im Staging behalten (mögliche Prompt-Injection)",
+	"%d files pending (need regis3 frontmatter)":                                         "%d Dateien ausstehend (regis3-Frontmatter fehlt)",
+	"%d files still pending (need regis3 frontmatter)":                                   "%d Dateien weiterhin ausstehend (regis3-Frontmatter fehlt)",
+	"%d items installed for %d targets":                                                  "%d Elemente für %d Ziele installiert",
+	"%d items installed in this project":                                                 "%d Elemente in diesem Projekt installiert",
+	"%d items not installed":                                                             "%d Elemente nicht installiert",
+	"%d orphaned files found":                                                            "%d verwaiste Dateien gefunden",
+	"%s is not installed in this project (use 'regis3 project add')":                     "%s ist in diesem Projekt nicht installiert (verwende 'regis3 project add')",
+	"%s%d items have updates available":                                                  "%s%d Elemente haben Updates",
+	"%s%d pinned items have updates available":                                           "%s%d fixierte Elemente haben Updates",
+	"%s%s:%s is missing from %s":                                                         "%s%s:%s fehlt in %s",
+	"%s%s:%s no longer exists in the registry":                                           "%s%s:%s existiert nicht mehr in der Registry",
+	"%s%s:%s was modified locally (%s)":                                                  "%s%s:%s wurde lokal geändert (%s)",
+	"All %d items are valid":                                                             "Alle %d Elemente sind gültig",
+	"All installed items are up to date":                                                 "Alle installierten Elemente sind aktuell",
+	"Audit failed: %s":                                                                   "Audit fehlgeschlagen: %s",
+	"Build failed: %s":                                                                   "Build fehlgeschlagen: %s",
+	"Cannot locate executable: %s":                                                       "Programmdatei nicht gefunden: %s",
+	"Could not move all pending files to %s: %s":                                         "Nicht alle ausstehenden Dateien konnten nach %s verschoben werden: %s",
+	"Chose %s for %s":                                                                    "%[1]s für %[2]s gewählt",
+	"Describe command failed for %d files, descriptions were taken from the content: %s": "Beschreibungsbefehl für %d Dateien fehlgeschlagen, Beschreibungen stammen aus dem Inhalt: %s",
+	"Error: %s":                                   "Fehler: %s",
+	"Failed to list pending: %s":                  "Ausstehende Dateien konnten nicht aufgelistet werden: %s",
+	"Failed to load manifest: %s":                 "Manifest konnte nicht geladen werden: %s",
+	"Failed to load registry: %s":                 "Registry konnte nicht geladen werden: %s",
+	"Failed to open %s: %s":                       "%s konnte nicht geöffnet werden: %s",
+	"Failed to parse %s: %s":                      "%s konnte nicht gelesen werden: %s",
+	"Failed to read checksums: %s":                "Prüfsummen konnten nicht gelesen werden: %s",
+	"Failed to rebuild manifest: %s":              "Manifest konnte nicht neu erstellt werden: %s",
+	"Failed to scan registry":                     "Registry konnte nicht durchsucht werden",
+	"Failed to scan registry: %s":                 "Registry konnte nicht durchsucht werden: %s",
+	"%d files need formatting (run 'regis3 fmt')": "%d Dateien müssen formatiert werden ('regis3 fmt' ausführen)",
+	"All %d files are formatted":                  "Alle %d Dateien sind formatiert",
+	"Formatted %d of %d files":                    "%d von %d Dateien formatiert",
+	"Failed to update %s: %s":                     "%s konnte nicht aktualisiert werden: %s",
+	"Change times are file modification times (the registry is not a git repository)": "Änderungszeiten sind Dateizeitstempel (die Registry ist kein Git-Repository)",
+	"Failed to write badge: %s":  "Badge konnte nicht geschrieben werden: %s",
+	"Failed to write config: %s": "Konfiguration konnte nicht geschrieben werden: %s",
+	"Filtered builds have no health score (build without build.include or --only)": "Gefilterte Builds haben keine Zustandsbewertung (ohne build.include oder --only bauen)",
+	"Filtered build: the manifest only contains matching items":                    "Gefilterter Build: Das Manifest enthält nur passende Elemente",
+	"Found %d items":                                                "%d Elemente gefunden",
+	"Found %d items matching '%s'":                                  "%d Elemente für '%s' gefunden",
+	"Git fetch failed: %s":                                          "Git fetch fehlgeschlagen: %s",
+	"Cloning registry %s...":                                        "Klone Registry %s...",
+	"Cloned %s into %s":                                             "%s nach %s geklont",
+	"Invalid registry name: %s (choose one with --name)":            "Ungültiger Registry-Name: %s (mit --name wählen)",
+	"A registry named %s already exists":                            "Eine Registry namens %s existiert bereits",
+	"Registry not found: %s":                                        "Registry nicht gefunden: %s",
+	"No registry named %s":                                          "Keine Registry namens %s",
+	"%s has validation errors; run 'regis3 --registry %s validate'": "%s hat Validierungsfehler; 'regis3 --registry %s validate' ausführen",
+	"Added registry %s (%d items)":                                  "Registry %s hinzugefügt (%d Elemente)",
+	"Could not delete the clone %s: %s":                             "Klon %s konnte nicht gelöscht werden: %s",
+	"Removed registry %s":                                           "Registry %s entfernt",
+	"Git pull failed: %s":                                           "Git pull fehlgeschlagen: %s",
+	"Import failed: %s":                                             "Import fehlgeschlagen: %s",
+	"Imported %d files to registry":                                 "%d Dateien in die Registry importiert",
+	"Indexed %d items":                                              "%d Elemente indiziert",
+	"Installation failed: %s":                                       "Installation fehlgeschlagen: %s",
+	"Installation cancelled, %s left unchanged":                     "Installation abgebrochen, %s bleibt unverändert",
+	"No item picker is open in this project (open one with 'regis3 project add')": "In diesem Projekt ist keine Elementauswahl geöffnet (mit 'regis3 project add' öffnen)",
+	"Control request failed: %s":                                                             "Steuerungsanfrage fehlgeschlagen: %s",
+	"Installed %d items to project":                                                          "%d Elemente im Projekt installiert",
+	"Installer error: %s":                                                                    "Installationsfehler: %s",
+	"Invalid sort order: %s (must be type, name, modified or size)":                          "Ungültige Sortierung: %s (erlaubt sind type, name, modified oder size)",
+	"Kept pinned %s (use --force or 'regis3 project unpin' to update it)":                    "Fixiertes %s beibehalten (mit --force oder 'regis3 project unpin' aktualisieren)",
+	"Merged local changes to %s with the update":                                             "Lokale Änderungen an %s mit der Aktualisierung zusammengeführt",
+	"Local changes to %s conflict with the update; resolve the conflict markers in its file": "Lokale Änderungen an %s stehen im Konflikt mit der Aktualisierung; Konfliktmarkierungen in der Datei auflösen",
+	"Kept local changes to %s without updating it (use --force to overwrite them)":           "Lokale Änderungen an %s beibehalten, nicht aktualisiert (mit --force überschreiben)",
+	"Merge failed: %s":                            "Zusammenführen fehlgeschlagen: %s",
+	"Merged %d items into %s":                     "%d Elemente in %s zusammengeführt",
+	"No builds recorded yet (run 'regis3 build')": "Noch keine Builds aufgezeichnet ('regis3 build' ausführen)",
+	"Last %d of %d recorded builds:":              "Letzte %d von %d aufgezeichneten Builds:",
+	"Since %s:":                                   "Seit %s:",
+	"items %d %s %d (%+d)":                        "Elemente %d %s %d (%+d)",
+	"items with warnings %d %s %d":                "Elemente mit Warnungen %d %s %d",
+	"content size %s %s %s":                       "Inhaltsgröße %s %s %s",
+	"Used the local override of %s from %s":       "Lokale Überschreibung von %s aus %s verwendet",
+	"%s in %s overrides no registry item":         "%s in %s überschreibt kein Registry-Element",
+	"Opened %s":                                   "%s geöffnet",
+	"Move detailed content into skill files, which load on demand, to shrink %s": "Verschiebe ausführliche Inhalte in Skill-Dateien, die bei Bedarf geladen werden, um %s zu verkleinern",
+	"Moved %d pending files to the staging directory %s":                         "%d ausstehende Dateien in das Staging-Verzeichnis %s verschoben",
+	"Moved %d files to registry":                                                 "%d Dateien in die Registry verschoben",
+	"No configuration file found":                                                "Keine Konfigurationsdatei gefunden",
+	"No files pending":                                                           "Keine ausstehenden Dateien",
+	"No files pending in import staging":                                         "Keine ausstehenden Dateien im Import-Staging",
+	"No items installed in this project":                                         "Keine Elemente in diesem Projekt installiert",
+	"No items listed in %s":                                                      "Keine Elemente in %s aufgeführt",
+	"No items match '%s'":                                                        "Keine Elemente passen zu '%s'",
+	"No items match the filter":                                                  "Keine Elemente passen zum Filter",
+	"No items selected":                                                          "Keine Elemente ausgewählt",
+	"No orphaned files found":                                                    "Keine verwaisten Dateien gefunden",
+	"No release archive for this platform: %s":                                   "Kein Release-Archiv für diese Plattform: %s",
+	"Pinned %s":               "%s fixiert",
+	"Ran setup script for %s": "Setup-Skript für %s ausgeführt",
+	"Registry is already up to date (%d items)":                          "Registry ist bereits aktuell (%d Elemente)",
+	"Registry is empty":                                                  "Registry ist leer",
+	"Registry is not a git repository":                                   "Registry ist kein Git-Repository",
+	"Registry updated (%d items)":                                        "Registry aktualisiert (%d Elemente)",
+	"Reindex failed: %s":                                                 "Neuindizierung fehlgeschlagen: %s",
+	"Release has no checksums; refusing to install an unverified binary": "Release hat keine Prüfsummen; ungeprüfte Programmdatei wird nicht installiert",
+	"Removed %d items from project":                                      "%d Elemente aus dem Projekt entfernt",
+	"Install with 'regis3 project add <item>' or pick them with 'regis3 suggest --pick'": "Installiere mit 'regis3 project add <element>' oder wähle mit 'regis3 suggest --pick' aus",
+	"No items installed in this project; name the items to render":                       "Keine Elemente in diesem Projekt installiert; nenne die Elemente, die ausgegeben werden sollen",
+	"Installed items not in the registry: %s":                                            "Installierte Elemente nicht in der Registry: %s",
+	"Failed to resolve dependencies: %s":                                                 "Abhängigkeiten konnten nicht aufgelöst werden: %s",
+	"Missing dependencies: %s":                                                           "Fehlende Abhängigkeiten: %s",
+	"Failed to render: %s":                                                               "Ausgabe fehlgeschlagen: %s",
+	"Failed to inspect the project: %s":                                                  "Projekt konnte nicht untersucht werden: %s",
+	"Run 'regis3 build' to update the manifest":                                          "Führe 'regis3 build' aus, um das Manifest zu aktualisieren",
+	"Scan failed: %s":                                                                    "Scan fehlgeschlagen: %s",
+	"Selection cancelled: %s":                                                            "Auswahl abgebrochen: %s",
+	"Set %s = %s":                                                                        "%s = %s gesetzt",
+	"Skipped %d already installed":                                                       "%d bereits installierte übersprungen",
+	"Skipped %d merged items (edit %s manually)":                                         "%d zusammengeführte Elemente übersprungen (%s manuell bearbeiten)",
+	"Skipped pinned %s (run 'regis3 project unpin %s' to update it)":                     "Fixiertes %s übersprungen (zum Aktualisieren 'regis3 project unpin %s' ausführen)",
+	"Skipped setup script for %s (use --allow-scripts to run it)":                        "Setup-Skript für %s übersprungen (mit --allow-scripts ausführen)",
+	"Split %d files into %d staged items in %s (review their frontmatter)":               "%d Dateien in %d bereitgestellte Elemente in %s aufgeteilt (Frontmatter prüfen)",
+	"Staged %d files in %s (need regis3 headers)":                                        "%d Dateien in %s bereitgestellt (regis3-Header fehlen)",
+	"Target not found: %s":                                                               "Ziel nicht gefunden: %s",
+	"File not found: %s":                                                                 "Datei nicht gefunden: %s",
+	"Cannot serve the preview: %s":                                                       "Vorschau kann nicht bereitgestellt werden: %s",
+	"Previewing %s at http://%s (ctrl+c to stop)":                                        "Vorschau von %s unter http://%s (Strg+C zum Beenden)",
+	"This is a development build; use --force to replace it with a release":              "Dies ist ein Entwicklungs-Build; mit --force durch ein Release ersetzen",
+	"Uninstall failed: %s":                                                               "Deinstallation fehlgeschlagen: %s",
+	"Unknown config key: %s":                                                             "Unbekannter Konfigurationsschlüssel: %s",
+	"Unpinned %s":                                                                        "Fixierung von %s aufgehoben",
+	"Another regis3 process (PID %d, %s) is changing this project; try again when it has finished": "Ein anderer regis3-Prozess (PID %d, %s) ändert dieses Projekt; versuche es erneut, wenn er fertig ist",
+	"Waiting for regis3 (PID %d, %s) to finish...":                                                 "Warte, bis regis3 (PID %d, %s) fertig ist...",
+	"%s is deprecated and may be removed from the registry":                                        "%s ist veraltet und wird möglicherweise aus der Registry entfernt",
+	"%s is deprecated, use %s instead":                                                             "%s ist veraltet, verwenden Sie stattdessen %s",
+	"Installed %d items to %d targets":                                                             "%d Elemente in %d Ziele installiert",
+	"%s is skipped for %s: %s":                                                                     "%s wird für %s übersprungen: %s",
+	"%s is not skipped for %s: %s":                                                                 "%s wird für %s nicht übersprungen: %s",
+	"Skipped %s: %s":                                                                               "%s übersprungen: %s",
+	"Generated key %s: %s":                                                                         "Schlüssel %s erzeugt: %s",
+	"Store the key safely and set %s to it; without it the registry can't be read":                 "Bewahren Sie den Schlüssel sicher auf und setzen Sie %s darauf; ohne ihn ist die Registry nicht lesbar",
+	"Encrypted %d items with key %s":                                                               "%d Elemente mit Schlüssel %s verschlüsselt",
+	"All items are encrypted with key %s":                                                          "Alle Elemente sind mit Schlüssel %s verschlüsselt",
+	"Registry is encrypted with key %s, but the configured key is %s":                              "Die Registry ist mit Schlüssel %s verschlüsselt, konfiguriert ist aber %s",
+	"Failed to encrypt registry: %s":                                                               "Verschlüsseln der Registry fehlgeschlagen: %s",
+	"Registry is not encrypted (run 'regis3 crypt init')":                                          "Die Registry ist nicht verschlüsselt ('regis3 crypt init' ausführen)",
+	"Re-encrypted %d items from key %s to %s":                                                      "%d Elemente von Schlüssel %s auf %s umgeschlüsselt",
+	"New key %s: %s":                                                                               "Neuer Schlüssel %s: %s",
+	"Set %s to the new key; the old key no longer reads the registry":                              "Setzen Sie %s auf den neuen Schlüssel; der alte liest die Registry nicht mehr",
+	"Registry is encrypted with key %s: %d encrypted, %d plain":                                    "Die Registry ist mit Schlüssel %s verschlüsselt: %d verschlüsselt, %d unverschlüsselt",
+	"%s is not encrypted (run 'regis3 crypt init')":                                                "%s ist nicht verschlüsselt ('regis3 crypt init' ausführen)",
+	"encrypted with another key":                                                                   "mit einem anderen Schlüssel verschlüsselt",
+	"The configured key is %s, not the registry's":                                                 "Der konfigurierte Schlüssel ist %s, nicht der der Registry",
+	"Registry %s is read-only; %s would modify it (set read_only: false to allow changes)":         "Die Registry %s ist schreibgeschützt; %s würde sie ändern (read_only: false erlaubt Änderungen)",
+	"Invalid value for %s: %s (expected true or false)":                                            "Ungültiger Wert für %s: %s (erwartet true oder false)",
+	"Failed to report the installation: %s":                                                        "Melden der Installation fehlgeschlagen: %s",
+	"Failed to read the inventory: %s":                                                             "Inventar konnte nicht gelesen werden: %s",
+	"%d items installed, %d without a license":                                                     "%d Elemente installiert, %d ohne Lizenz",
+	"Nothing was installed: the installation was rolled back":                                      "Nichts installiert: die Installation wurde zurückgerollt",
+	"Nothing was updated: the update was rolled back":                                              "Nichts aktualisiert: die Aktualisierung wurde zurückgerollt",
+	"variant %s":                                                             "Variante %s",
+	"No item to install defines variant %q":                                  "Kein zu installierendes Element definiert die Variante %q",
+	"Registry is at schema version %d, nothing to migrate":                   "Registry ist auf Schema-Version %d, nichts zu migrieren",
+	"No files were changed; fix the files above and run the migration again": "Keine Dateien geändert; korrigieren Sie die obigen Dateien und führen Sie die Migration erneut aus",
+	"Would migrate %d of %d files to schema version %d (dry run)":            "Würde %d von %d Dateien auf Schema-Version %d migrieren (Probelauf)",
+	"Migrated %d of %d files to schema version %d":                           "%d von %d Dateien auf Schema-Version %d migriert",
+	"linked": "verknüpft",
+	"Linked %d items to their registry files":                                               "%d Elemente mit ihren Registry-Dateien verknüpft",
+	"Copied %s instead of linking it: %s":                                                   "%s kopiert statt verknüpft: %s",
+	"%s is needed by %s (use --cascade to remove them too, or --force to remove it anyway)": "%s wird von %s benötigt (--cascade entfernt diese mit, --force entfernt es trotzdem)",
+	"%s depends on the removed items and is removed too":                                    "%s hängt von den entfernten Elementen ab und wird mit entfernt",
+	"%s is removed though installed items depend on it: %s":                                 "%s wird entfernt, obwohl installierte Elemente davon abhängen: %s",
+	"Quarantined %d items with validation errors; they are left out of the manifest":        "%d Elemente mit Validierungsfehlern in Quarantäne; sie fehlen im Manifest",
+	"%d quarantined":                                  "%d in Quarantäne",
+	"No unneeded dependencies installed":              "Keine unbenötigten Abhängigkeiten installiert",
+	"Would remove %d unneeded dependencies (dry run)": "Würde %d unbenötigte Abhängigkeiten entfernen (Probelauf)",
+	"Removed %d unneeded dependencies":                "%d unbenötigte Abhängigkeiten entfernt",
+	"%d dependencies are no longer needed (run 'regis3 project autoremove' to remove them)": "%d Abhängigkeiten werden nicht mehr benötigt ('regis3 project autoremove' entfernt sie)",
+	"%s is quarantined because of validation errors: %s (see 'regis3 quarantine')":          "%s ist wegen Validierungsfehlern in Quarantäne: %s (siehe 'regis3 quarantine')",
+	"%d items are quarantined because of validation errors (see 'regis3 quarantine')":       "%d Elemente sind wegen Validierungsfehlern in Quarantäne (siehe 'regis3 quarantine')",
+	"%s is not quarantined": "%s ist nicht in Quarantäne",
+	"Fix the files and run 'regis3 build' to add the items back (with --allow-errors while others remain broken)": "Korrigiere die Dateien und führe 'regis3 build' aus, um die Elemente wieder aufzunehmen (mit --allow-errors, solange andere fehlerhaft sind)",
+	"%s No items are quarantined":                         "%s Keine Elemente in Quarantäne",
+	"%s %d items are left out of the manifest:":           "%s %d Elemente fehlen im Manifest:",
+	"Apply failed: %s":                                    "Anwenden fehlgeschlagen: %s",
+	"No %s in this project":                               "Keine %s in diesem Projekt",
+	"Sync failed: %s":                                     "Synchronisierung fehlgeschlagen: %s",
+	"No %s in this project (project add writes it)":       "Keine %s in diesem Projekt (project add legt sie an)",
+	"%s has no items for target %s (locked: %s)":          "%s enthält keine Elemente für das Ziel %s (gesperrt: %s)",
+	"%s was written with the registry %s":                 "%s wurde mit der Registry %s geschrieben",
+	"Removed %d items not in %s":                          "%d Elemente entfernt, die nicht in %s stehen",
+	"Update failed: %s":                                   "Update fehlgeschlagen: %s",
+	"Update cancelled, %s left unchanged":                 "Update abgebrochen, %s bleibt unverändert",
+	"Updated %d items":                                    "%d Elemente aktualisiert",
+	"Would import %d files, stage %d files (dry run)":     "Würde %d Dateien importieren und %d bereitstellen (Probelauf)",
+	"Would install %d items (dry run)":                    "Würde %d Elemente installieren (Probelauf)",
+	"Would remove %d items (dry run)":                     "Würde %d Elemente entfernen (Probelauf)",
+	"Would split %d files into %d staged items (dry run)": "Würde %d Dateien in %d bereitgestellte Elemente aufteilen (Probelauf)",
+	"Would update %d items (dry run)":                     "Würde %d Elemente aktualisieren (Probelauf)",
+	"not installed in this project":                       "in diesem Projekt nicht installiert",
+
+	// Setup
+	"Error: %v":                                      "Fehler: %v",
+	"regis3 is already initialized.":                 "regis3 ist bereits eingerichtet.",
+	"Registry: %s":                                   "Registry: %s",
+	"Default target: %s":                             "Standardziel: %s",
+	"Welcome to regis3!":                             "Willkommen bei regis3!",
+	"auto (detect from each project)":                "auto (für jedes Projekt erkennen)",
+	"Registry location":                              "Speicherort der Registry",
+	"Where your skills, agents and other items live": "Wo deine Skills, Agents und anderen Elemente liegen",
+	"enter a directory":                              "gib ein Verzeichnis an",
+	"How do you want to start?":                      "Wie möchtest du beginnen?",
+	"regis3 is folder-structure agnostic: organize the registry however you like": "regis3 gibt keine Ordnerstruktur vor: organisiere die Registry, wie du möchtest",
+	"Empty registry": "Leere Registry",
+	"Default folders (skills/, agents/, commands/, ...)":         "Standardordner (skills/, agents/, commands/, ...)",
+	"Clone an existing registry from git":                        "Bestehende Registry aus Git klonen",
+	"Git repository to clone":                                    "Zu klonendes Git-Repository",
+	"enter a repository URL":                                     "gib eine Repository-URL an",
+	"Default target":                                             "Standardziel",
+	"The tool items are installed for in projects":               "Das Werkzeug, für das Elemente in Projekten installiert werden",
+	"Cloning %s into %s":                                         "Klone %s nach %s",
+	"Error cloning registry: %v":                                 "Fehler beim Klonen der Registry: %v",
+	"Creating registry at: %s":                                   "Lege Registry an unter: %s",
+	"Error creating registry directory: %v":                      "Fehler beim Anlegen des Registry-Verzeichnisses: %v",
+	"Error creating .build: %v":                                  "Fehler beim Anlegen von .build: %v",
+	"Error creating %s: %v":                                      "Fehler beim Anlegen von %s: %v",
+	"Created default folder structure.":                          "Standard-Ordnerstruktur angelegt.",
+	"Error creating config directory: %v":                        "Fehler beim Anlegen des Konfigurationsverzeichnisses: %v",
+	"Error saving config: %v":                                    "Fehler beim Speichern der Konfiguration: %v",
+	"Warning: could not build the manifest: %v":                  "Warnung: Manifest konnte nicht gebaut werden: %v",
+	"regis3 initialized successfully!":                           "regis3 erfolgreich eingerichtet!",
+	"Next steps:":                                                "Nächste Schritte:",
+	"Run 'regis3 list' to see the %d available items":            "Führe 'regis3 list' aus, um die %d verfügbaren Elemente zu sehen",
+	"Run 'regis3 project add' in a project to install items":     "Führe 'regis3 project add' in einem Projekt aus, um Elemente zu installieren",
+	"Add markdown files with regis3 frontmatter to the registry": "Lege Markdown-Dateien mit regis3-Frontmatter in der Registry ab",
+	"Run 'regis3 build' to build the manifest":                   "Führe 'regis3 build' aus, um das Manifest zu bauen",
+	"Run 'regis3 list' to see available items":                   "Führe 'regis3 list' aus, um die verfügbaren Elemente zu sehen",
+	"Run 'regis3 project add <type:name>' to install items":      "Führe 'regis3 project add <type:name>' aus, um Elemente zu installieren",
+	"Tip: Organize your registry however you like - regis3 scans all subdirectories and is folder-structure agnostic.": "Tipp: Organisiere deine Registry, wie du möchtest – regis3 durchsucht alle Unterverzeichnisse und gibt keine Ordnerstruktur vor.",
+	"%s already exists and is not empty":                     "%s existiert bereits und ist nicht leer",
+	"regis3 is not set up yet. Run 'regis3 init' to set up.": "regis3 ist noch nicht eingerichtet. Führe 'regis3 init' aus, um es einzurichten.",
+	"regis3 is not set up yet. Set it up now?":               "regis3 ist noch nicht eingerichtet. Jetzt einrichten?",
+	"Creates ~/.regis3/config.yaml and a registry":           "Legt ~/.regis3/config.yaml und eine Registry an",
+	"Set up":  "Einrichten",
+	"Not now": "Nicht jetzt",
+}
@@ -0,0 +1,97 @@
+// Package i18n translates user-facing CLI messages.
+//
+// Messages are looked up by their English text, which doubles as the
+// format string: call sites keep the English message, and a catalog maps it
+// to the selected locale's translation. Messages missing from a catalog are
+// shown in English. Translations may reorder arguments with explicit
+// indexes such as %[2]s.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultLocale is the locale of the messages in the source code.
+const DefaultLocale = "en"
+
+// Catalog maps English messages to their translations.
+type Catalog map[string]string
+
+// catalogs holds the translations of every supported locale except English.
+var catalogs = map[string]Catalog{
+	"de": german,
+}
+
+// Locales lists the supported locales, English first.
+var Locales = []string{DefaultLocale, "de"}
+
+// current is the catalog of the selected locale; nil for English.
+var (
+	current       Catalog
+	currentLocale = DefaultLocale
+)
+
+// SetLocale selects the locale for translated messages. Unsupported
+// locales fall back to English.
+func SetLocale(locale string) {
+	currentLocale, current = DefaultLocale, nil
+	if catalog, ok := catalogs[locale]; ok {
+		currentLocale, current = locale, catalog
+	}
+}
+
+// Locale returns the selected locale.
+func Locale() string {
+	return currentLocale
+}
+
+// IsSupported reports whether locale has a catalog.
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok || locale == DefaultLocale
+}
+
+// Detect returns the locale to use: the configured locale if set, else the
+// first of LC_ALL, LC_MESSAGES and LANG that is set. Values like
+// de_DE.UTF-8 are reduced to their language; unsupported languages and the
+// C/POSIX locales give English.
+func Detect(configured string) string {
+	value := configured
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value != "" {
+			break
+		}
+		value = os.Getenv(env)
+	}
+
+	lang := Normalize(value)
+	if !IsSupported(lang) {
+		return DefaultLocale
+	}
+	return lang
+}
+
+// Normalize reduces a locale name such as de_DE.UTF-8 or de-AT to its
+// lowercase language code.
+func Normalize(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang, _, _ = strings.Cut(lang, "-")
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+// T returns the translation of msg, or msg itself if it has none.
+func T(msg string) string {
+	if translated, ok := current[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format with args.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"de", "de"},
+		{"de_DE.UTF-8", "de"},
+		{"de-AT", "de"},
+		{"DE", "de"},
+		{"en_US@euro", "en"},
+		{"C", "c"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.input))
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		env        map[string]string
+		want       string
+	}{
+		{name: "nothing set", want: "en"},
+		{name: "configured wins", configured: "de", env: map[string]string{"LANG": "en_US.UTF-8"}, want: "de"},
+		{name: "from LANG", env: map[string]string{"LANG": "de_DE.UTF-8"}, want: "de"},
+		{name: "LC_ALL before LANG", env: map[string]string{"LC_ALL": "de_CH", "LANG": "en_US"}, want: "de"},
+		{name: "LC_MESSAGES before LANG", env: map[string]string{"LC_MESSAGES": "en", "LANG": "de"}, want: "en"},
+		{name: "POSIX locale", env: map[string]string{"LANG": "C.UTF-8"}, want: "en"},
+		{name: "unsupported language", env: map[string]string{"LANG": "fr_FR.UTF-8"}, want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(env, tt.env[env])
+			}
+			assert.Equal(t, tt.want, Detect(tt.configured))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Cleanup(func() { SetLocale(DefaultLocale) })
+
+	SetLocale("de")
+	assert.Equal(t, "de", Locale())
+	assert.Equal(t, "3 Elemente gefunden", Sprintf("Found %d items", 3))
+	assert.Equal(t, "skill:git für capability:git gewählt", Sprintf("Chose %s for %s", "skill:git", "capability:git"))
+	assert.Equal(t, "untranslated message", T("untranslated message"))
+
+	SetLocale("fr")
+	assert.Equal(t, DefaultLocale, Locale())
+	assert.Equal(t, "Found 3 items", Sprintf("Found %d items", 3))
+}
+
+// verbPattern matches format verbs, with an optional explicit argument index.
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// verbs returns the verbs of a format string in argument order, without
+// their explicit indexes.
+func verbs(format string) []string {
+	var found []string
+	for _, match := range verbPattern.FindAllStringSubmatch(format, -1) {
+		verb := match[0]
+		if match[1] != "" {
+			verb = "%" + verb[len(match[1])+1:]
+		}
+		if verb != "%%" {
+			found = append(found, verb)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for msg, translated := range catalog {
+			assert.Equal(t, verbs(msg), verbs(translated), "%s translation of %q", locale, msg)
+		}
+	}
+}
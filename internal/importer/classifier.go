@@ -4,18 +4,27 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/okto-digital/regis3/internal/registry"
-	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"gopkg.in/yaml.v3"
 )
 
 // Classifier classifies markdown files and suggests regis3 types.
-type Classifier struct{}
+type Classifier struct {
+	// Describer suggests descriptions for files without regis3 metadata.
+	Describer Describer
 
-// NewClassifier creates a new classifier.
+	// Dialect selects the frontmatter formats and keys regis3 metadata is
+	// read from.
+	Dialect registry.Dialect
+}
+
+// NewClassifier creates a new classifier that describes files by their
+// content (see HeuristicDescriber).
 func NewClassifier() *Classifier {
-	return &Classifier{}
+	return &Classifier{Describer: HeuristicDescriber{}}
 }
 
 // Classification contains the classification result for a file.
@@ -29,6 +38,10 @@ type Classification struct {
 	// SuggestedName is the suggested name (kebab-case).
 	SuggestedName string
 
+	// SuggestedDesc is the suggested description, derived from the content
+	// (see Describe).
+	SuggestedDesc string
+
 	// Confidence is the classification confidence (0-100).
 	Confidence int
 
@@ -76,30 +89,38 @@ func (c *Classifier) Classify(path string) (*Classification, error) {
 	return result, nil
 }
 
-// parseExistingMeta attempts to parse regis3 metadata from content.
-func (c *Classifier) parseExistingMeta(content string) (*registry.Regis3Meta, bool) {
-	// Try to parse frontmatter
-	doc, err := frontmatter.ParseBytes([]byte(content))
-	if err != nil || doc.Frontmatter == "" {
-		return nil, false
+// Describe sets the suggested description of a classification without
+// regis3 metadata. It is separate from Classify because describers may be
+// slow, such as a language model behind a describe command.
+func (c *Classifier) Describe(class *Classification) {
+	if !class.HasValidRegis3 {
+		class.SuggestedDesc = c.describe(class.Path, class.Content)
 	}
+}
 
-	// Check for regis3 block
-	var wrapper struct {
-		Regis3 registry.Regis3Meta `yaml:"regis3"`
+// describe suggests a description for content. Describer errors leave the
+// description empty or to its fallback.
+func (c *Classifier) describe(path, content string) string {
+	if c.Describer == nil {
+		return ""
 	}
+	desc, _ := c.Describer.Describe(path, content)
+	return desc
+}
 
-	_, err = frontmatter.UnmarshalBytes([]byte(content), &wrapper)
+// parseExistingMeta attempts to parse regis3 metadata from content.
+func (c *Classifier) parseExistingMeta(content string) (*registry.Regis3Meta, bool) {
+	meta, _, err := c.Dialect.ParseMeta([]byte(content))
 	if err != nil {
 		return nil, false
 	}
 
 	// Validate required fields
-	if wrapper.Regis3.Type == "" || wrapper.Regis3.Name == "" {
+	if meta.Type == "" || meta.Name == "" {
 		return nil, false
 	}
 
-	return &wrapper.Regis3, true
+	return meta, true
 }
 
 // suggestType suggests a type based on content analysis.
@@ -262,6 +283,7 @@ func (c *Classifier) ClassifyMany(paths []string) ([]*Classification, error) {
 }
 
 // GenerateFrontmatter generates regis3 frontmatter for a classification.
+// Without desc, the suggested description is used.
 func (c *Classifier) GenerateFrontmatter(class *Classification, desc string) string {
 	var sb strings.Builder
 
@@ -269,8 +291,11 @@ func (c *Classifier) GenerateFrontmatter(class *Classification, desc string) str
 	sb.WriteString("regis3:\n")
 	sb.WriteString("  type: " + class.SuggestedType + "\n")
 	sb.WriteString("  name: " + class.SuggestedName + "\n")
+	if desc == "" {
+		desc = class.SuggestedDesc
+	}
 	if desc != "" {
-		sb.WriteString("  desc: " + desc + "\n")
+		sb.WriteString("  desc: " + yamlScalar(desc) + "\n")
 	} else {
 		sb.WriteString("  desc: \"TODO: Add description\"\n")
 	}
@@ -306,3 +331,12 @@ func (c *Classifier) AddFrontmatterToContent(class *Classification, desc string)
 	// Add frontmatter to content
 	return fm + class.Content
 }
+
+// yamlScalar formats s as a YAML scalar, quoting it only when needed.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSpace(string(out))
+}
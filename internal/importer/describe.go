@@ -0,0 +1,211 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// MaxDescLength is the longest description the heuristic describer suggests.
+const MaxDescLength = 120
+
+// DescribeTimeout limits how long a describe command may run per file.
+const DescribeTimeout = 30 * time.Second
+
+// Describer suggests an item description from a file's content.
+type Describer interface {
+	// Describe returns a one-line description, or "" if it has none.
+	Describe(path, content string) (string, error)
+}
+
+// HeuristicDescriber describes content by its first paragraph, or by its
+// title and section headings when it has no prose.
+type HeuristicDescriber struct{}
+
+var (
+	markdownLink   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownMarkup = regexp.MustCompile("[*`]+")
+	sentenceEnd    = regexp.MustCompile(`[.!?](\s+\p{Lu}|\s*$)`)
+)
+
+// Describe implements Describer.
+func (HeuristicDescriber) Describe(path, content string) (string, error) {
+	if doc, err := frontmatter.ParseBytes([]byte(content)); err == nil && doc.Frontmatter != "" {
+		content = doc.Body
+	}
+
+	var title string
+	var headings, paragraph []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			if len(paragraph) > 0 {
+				return summarize(strings.Join(paragraph, " ")), nil
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			if len(paragraph) > 0 {
+				return summarize(strings.Join(paragraph, " ")), nil
+			}
+			text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if title == "" && strings.HasPrefix(trimmed, "# ") {
+				title = text
+			} else if text != "" {
+				headings = append(headings, text)
+			}
+		case strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, ">"),
+			strings.HasPrefix(trimmed, "<"), strings.HasPrefix(trimmed, "---"):
+			// Tables, quotes, HTML and rules don't make descriptions
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			// Neither do lists
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	if len(paragraph) > 0 {
+		return summarize(strings.Join(paragraph, " ")), nil
+	}
+
+	// No prose: the title and what it covers
+	switch {
+	case title != "" && len(headings) > 0:
+		return summarize(title + ": " + strings.Join(headings, ", ")), nil
+	case title != "":
+		return summarize(title), nil
+	case len(headings) > 0:
+		return summarize(strings.Join(headings, ", ")), nil
+	}
+	return "", nil
+}
+
+// summarize strips markdown from text and shortens it to its first
+// sentence, at most MaxDescLength characters.
+func summarize(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownMarkup.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+
+	if loc := sentenceEnd.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	return shorten(strings.TrimRight(text, ".:;, "))
+}
+
+// shorten cuts text at a word boundary to at most MaxDescLength characters.
+func shorten(text string) string {
+	if utf8.RuneCountInString(text) <= MaxDescLength {
+		return text
+	}
+	var words []string
+	length := 0
+	for _, word := range strings.Fields(text) {
+		n := utf8.RuneCountInString(word)
+		if length+n+1 > MaxDescLength-len("...") {
+			break
+		}
+		words = append(words, word)
+		length += n + 1
+	}
+	return strings.TrimRight(strings.Join(words, " "), ".:;, ") + "..."
+}
+
+// CommandDescriber runs a shell command to describe content, e.g. a CLI
+// for a language model. The content is passed on stdin and the file path
+// in REGIS3_FILE; the first line of output is the description. Failures
+// and empty output use Fallback.
+type CommandDescriber struct {
+	// Command is run with sh -c (cmd /C on Windows).
+	Command string
+
+	// Fallback describes content when the command fails.
+	Fallback Describer
+
+	// OnError, if set, is called when the command fails, before falling
+	// back.
+	OnError func(path string, err error)
+
+	// cache holds descriptions by content, so previews and the import
+	// that follows run the command once per file.
+	cache map[string]string
+}
+
+// NewCommandDescriber creates a command describer that falls back to the
+// heuristics.
+func NewCommandDescriber(command string) *CommandDescriber {
+	return &CommandDescriber{Command: command, Fallback: HeuristicDescriber{}}
+}
+
+// Describe implements Describer.
+func (d *CommandDescriber) Describe(path, content string) (string, error) {
+	if desc, ok := d.cache[content]; ok {
+		return desc, nil
+	}
+	desc, err := d.run(path, content)
+	if err == nil && desc != "" {
+		if d.cache == nil {
+			d.cache = make(map[string]string)
+		}
+		d.cache[content] = desc
+		return desc, nil
+	}
+	if err != nil && d.OnError != nil {
+		d.OnError(path, err)
+	}
+	if d.Fallback == nil {
+		return "", err
+	}
+	fallback, fallbackErr := d.Fallback.Describe(path, content)
+	if fallbackErr != nil {
+		return "", fallbackErr
+	}
+	return fallback, err
+}
+
+// run runs the command and returns the first line of its output.
+func (d *CommandDescriber) run(path, content string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DescribeTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", d.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", d.Command)
+	}
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Env = append(os.Environ(), "REGIS3_FILE="+path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("describe command timed out after %s", DescribeTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("describe command failed: %s", msg)
+		}
+		return "", fmt.Errorf("describe command failed: %w", err)
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return shorten(strings.Trim(strings.TrimSpace(line), `"`)), nil
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeuristicDescriber(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "first paragraph",
+			content: "# Code Review\n\nReview pull requests for **correctness** and [style](https://example.com). Then approve.\n\nMore text.\n",
+			want:    "Review pull requests for correctness and style",
+		},
+		{
+			name:    "paragraph without heading",
+			content: "---\ntitle: x\n---\nUse table-driven tests\nfor Go code.\n",
+			want:    "Use table-driven tests for Go code",
+		},
+		{
+			name:    "skips code, lists and quotes",
+			content: "# Setup\n\n```sh\nmake install\n```\n\n- step one\n> note\n\nInstall the tools first.\n",
+			want:    "Install the tools first",
+		},
+		{
+			name:    "title and headings without prose",
+			content: "# Git Conventions\n\n## Branches\n\n- feature/x\n\n## Commits\n\n- feat: x\n",
+			want:    "Git Conventions: Branches, Commits",
+		},
+		{
+			name:    "abbreviations don't end the sentence",
+			content: "Prefer small helpers, e.g. for parsing, over large functions.\n",
+			want:    "Prefer small helpers, e.g. for parsing, over large functions",
+		},
+		{
+			name:    "empty",
+			content: "```\ncode only\n```\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, err := HeuristicDescriber{}.Describe("x.md", tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, desc)
+		})
+	}
+}
+
+func TestHeuristicDescriber_Shortens(t *testing.T) {
+	desc, err := HeuristicDescriber{}.Describe("x.md", strings.Repeat("word ", 60))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(desc), MaxDescLength)
+	assert.True(t, strings.HasSuffix(desc, "word..."))
+}
+
+func TestCommandDescriber(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	d := NewCommandDescriber(`read first; echo "\"Describes: $first ($REGIS3_FILE)\""; echo ignored`)
+	desc, err := d.Describe("notes.md", "hello\nworld\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Describes: hello (notes.md)", desc)
+
+	var failed []string
+	d = NewCommandDescriber("echo broken >&2; exit 1")
+	d.OnError = func(path string, err error) { failed = append(failed, path) }
+	desc, err = d.Describe("notes.md", "# Notes\n\nFallback text.\n")
+	assert.ErrorContains(t, err, "describe command failed: broken")
+	assert.Equal(t, "Fallback text", desc, "falls back to the heuristics")
+	assert.Equal(t, []string{"notes.md"}, failed)
+}
+
+func TestCommandDescriber_Caches(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	d := NewCommandDescriber("echo x >> '" + calls + "'; echo Cached")
+	for range 2 {
+		desc, err := d.Describe("x.md", "same content")
+		require.NoError(t, err)
+		assert.Equal(t, "Cached", desc)
+	}
+
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(data), "the command runs once per content")
+}
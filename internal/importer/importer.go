@@ -6,12 +6,14 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"github.com/okto-digital/regis3/internal/registry"
 )
 
 const (
-	// ImportDir is the staging directory for files pending YAML headers.
-	ImportDir = "import"
+	// ImportDir is the default staging directory for files pending YAML
+	// headers, relative to the registry.
+	ImportDir = registry.DefaultStagingDir
 )
 
 // Importer handles importing external files into the registry.
@@ -19,20 +21,38 @@ type Importer struct {
 	// RegistryPath is the path to the registry.
 	RegistryPath string
 
+	// StagingDir is the staging directory for files without regis3
+	// frontmatter. It defaults to ImportDir in the registry.
+	StagingDir string
+
 	// Scanner scans external paths.
 	Scanner *ExternalScanner
 
 	// Classifier classifies files.
 	Classifier *Classifier
 
+	// Dialect selects the frontmatter formats and keys the registry reads
+	// item metadata from. Scanner and Classifier have their own.
+	Dialect registry.Dialect
+
 	// DryRun if true, only simulates import.
 	DryRun bool
+
+	// CheckInjection if true, staged files are scanned for prompt-injection
+	// patterns (see ScanInjection) and flagged files are kept in staging.
+	CheckInjection bool
+
+	// SplitOnH1 if true, files without regis3 frontmatter that contain
+	// several level-1 headings are staged as one item per heading (see
+	// SplitOnH1), each with suggested frontmatter.
+	SplitOnH1 bool
 }
 
 // NewImporter creates a new importer.
 func NewImporter(registryPath string) *Importer {
 	return &Importer{
 		RegistryPath: registryPath,
+		StagingDir:   filepath.Join(registryPath, ImportDir),
 		Scanner:      NewExternalScanner(),
 		Classifier:   NewClassifier(),
 		DryRun:       false,
@@ -44,9 +64,13 @@ type ImportResult struct {
 	// Imported are files copied directly to the registry (had valid regis3).
 	Imported []ImportedFile
 
-	// Staged are files copied to the import/ staging directory.
+	// Staged are files copied to the staging directory.
 	Staged []ImportedFile
 
+	// Split are the items staged from files split at their headings, one
+	// per section. Only set with SplitOnH1.
+	Split []ImportedFile
+
 	// Skipped are files that were skipped (already exist, etc.).
 	Skipped []SkippedFile
 
@@ -70,6 +94,15 @@ type ImportedFile struct {
 
 	// WasStaged indicates if the file was staged (no regis3 block).
 	WasStaged bool
+
+	// Desc is the suggested description of a split item.
+	Desc string
+
+	// Section is the heading of the section a split item was made from.
+	Section string
+
+	// Lines is the number of lines of a split item.
+	Lines int
 }
 
 // SkippedFile represents a skipped file.
@@ -110,6 +143,22 @@ func (i *Importer) ScanAndImport(externalPath string) (*ImportResult, error) {
 
 	// Process each file
 	for _, file := range scanResult.Files {
+		if i.SplitOnH1 && !file.HasRegis3 {
+			parts, err := i.splitScannedFile(file)
+			if err != nil {
+				result.Errors = append(result.Errors, ImportError{
+					Path:    file.Path,
+					Message: err.Error(),
+					Err:     err,
+				})
+				continue
+			}
+			if parts != nil {
+				result.Split = append(result.Split, parts...)
+				continue
+			}
+		}
+
 		imported, err := i.importFile(file)
 		if err != nil {
 			result.Errors = append(result.Errors, ImportError{
@@ -149,12 +198,15 @@ func (i *Importer) importFile(file ScannedFile) (*ImportedFile, error) {
 
 	if class.HasValidRegis3 {
 		// Has valid regis3 - import directly to registry
-		destPath = i.getRegistryPath(class.ExistingMeta.Type, class.ExistingMeta.Name)
+		destPath, err = i.getRegistryPath(class.ExistingMeta.Type, class.ExistingMeta.Name)
 	} else {
-		// No regis3 - stage in import/ directory
-		destPath = filepath.Join(i.RegistryPath, ImportDir, file.RelPath)
+		// No regis3 - stage for review
+		destPath, err = pathutil.Join(i.StagingDir, file.RelPath)
 		wasStaged = true
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if destination already exists
 	if !i.DryRun {
@@ -188,7 +240,15 @@ func (i *Importer) importFile(file ScannedFile) (*ImportedFile, error) {
 }
 
 // getRegistryPath returns the path in the registry for an item type.
-func (i *Importer) getRegistryPath(itemType, name string) string {
+// Type and name come from imported frontmatter, so they must not escape the registry.
+func (i *Importer) getRegistryPath(itemType, name string) (string, error) {
+	if err := pathutil.CheckName(itemType); err != nil {
+		return "", err
+	}
+	if err := pathutil.CheckName(name); err != nil {
+		return "", err
+	}
+
 	// Map types to directories
 	dirMap := map[string]string{
 		"skill":      "skills",
@@ -210,7 +270,7 @@ func (i *Importer) getRegistryPath(itemType, name string) string {
 		dir = itemType + "s"
 	}
 
-	return filepath.Join(i.RegistryPath, dir, name+".md")
+	return pathutil.Join(i.RegistryPath, dir, name+".md")
 }
 
 // copyFile copies a file from src to dest.
@@ -221,6 +281,11 @@ func (i *Importer) copyFile(src, dest string) error {
 		return err
 	}
 
+	return i.writeFile(dest, content)
+}
+
+// writeFile writes content to dest, creating its directory.
+func (i *Importer) writeFile(dest string, content []byte) error {
 	// Create destination directory
 	dir := filepath.Dir(dest)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -231,9 +296,9 @@ func (i *Importer) copyFile(src, dest string) error {
 	return os.WriteFile(dest, content, 0644)
 }
 
-// ProcessStaging processes files in the import/ staging directory.
+// ProcessStaging processes files in the staging directory.
 func (i *Importer) ProcessStaging() (*ProcessResult, error) {
-	stagingDir := filepath.Join(i.RegistryPath, ImportDir)
+	stagingDir := i.StagingDir
 
 	// Check if staging directory exists
 	if _, err := os.Stat(stagingDir); os.IsNotExist(err) {
@@ -269,6 +334,14 @@ func (i *Importer) ProcessStaging() (*ProcessResult, error) {
 			return nil
 		}
 
+		if i.CheckInjection {
+			if findings := ScanInjection(class.Content); len(findings) > 0 {
+				// Keep in staging until someone has reviewed it
+				result.Flagged = append(result.Flagged, FlaggedFile{Path: path, Findings: findings})
+				return nil
+			}
+		}
+
 		if !class.HasValidRegis3 {
 			// Still no regis3 block - add to pending
 			result.Pending = append(result.Pending, PendingFile{
@@ -282,7 +355,15 @@ func (i *Importer) ProcessStaging() (*ProcessResult, error) {
 		}
 
 		// Has regis3 - move to proper location
-		destPath := i.getRegistryPath(class.ExistingMeta.Type, class.ExistingMeta.Name)
+		destPath, err := i.getRegistryPath(class.ExistingMeta.Type, class.ExistingMeta.Name)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportError{
+				Path:    path,
+				Message: err.Error(),
+				Err:     err,
+			})
+			return nil
+		}
 
 		if !i.DryRun {
 			// Copy to new location
@@ -326,6 +407,10 @@ type ProcessResult struct {
 	// Pending are files still waiting for regis3 headers.
 	Pending []PendingFile
 
+	// Flagged are files kept in staging for manual review because they
+	// matched prompt-injection patterns. Only set with CheckInjection.
+	Flagged []FlaggedFile
+
 	// Errors are processing errors.
 	Errors []ImportError
 }
@@ -338,18 +423,25 @@ type ProcessedFile struct {
 	Name       string
 }
 
+// FlaggedFile represents a staged file with suspected prompt injection.
+type FlaggedFile struct {
+	Path     string
+	Findings []InjectionFinding
+}
+
 // PendingFile represents a file still pending in staging.
 type PendingFile struct {
 	Path          string
 	SuggestedType string
 	SuggestedName string
+	SuggestedDesc string
 	Confidence    int
 	Reason        string
 }
 
 // ListPending lists files pending in the staging directory.
 func (i *Importer) ListPending() ([]PendingFile, error) {
-	stagingDir := filepath.Join(i.RegistryPath, ImportDir)
+	stagingDir := i.StagingDir
 
 	// Check if staging directory exists
 	if _, err := os.Stat(stagingDir); os.IsNotExist(err) {
@@ -380,11 +472,13 @@ func (i *Importer) ListPending() ([]PendingFile, error) {
 		}
 
 		if !class.HasValidRegis3 {
+			i.Classifier.Describe(class)
 			relPath, _ := filepath.Rel(stagingDir, path)
 			pending = append(pending, PendingFile{
 				Path:          relPath,
 				SuggestedType: class.SuggestedType,
 				SuggestedName: class.SuggestedName,
+				SuggestedDesc: class.SuggestedDesc,
 				Confidence:    class.Confidence,
 				Reason:        class.Reason,
 			})
@@ -398,12 +492,12 @@ func (i *Importer) ListPending() ([]PendingFile, error) {
 
 // Reindex rebuilds the registry manifest.
 func (i *Importer) Reindex() (*registry.BuildResult, error) {
-	return registry.BuildRegistry(i.RegistryPath)
+	return registry.BuildRegistryWithOptions(i.RegistryPath, registry.BuildOptions{StagingDir: i.StagingDir, Dialect: i.Dialect})
 }
 
 // StagingExists checks if the staging directory has files.
 func (i *Importer) StagingExists() bool {
-	stagingDir := filepath.Join(i.RegistryPath, ImportDir)
+	stagingDir := i.StagingDir
 	info, err := os.Stat(stagingDir)
 	if err != nil {
 		return false
@@ -420,3 +514,78 @@ func (i *Importer) StagingExists() bool {
 
 	return len(entries) > 0
 }
+
+// MigrateStaging moves pending files from an earlier staging directory to
+// StagingDir, keeping their paths, and removes the old directory once it is
+// empty. Files that already exist in StagingDir are left where they are. It
+// returns the moved files, relative to the staging directory.
+func (i *Importer) MigrateStaging(from string) ([]string, error) {
+	if filepath.Clean(from) == filepath.Clean(i.StagingDir) {
+		return nil, nil
+	}
+	if _, err := os.Stat(from); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var moved []string
+	var dirs []string
+	err := filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filepath.Clean(path) == filepath.Clean(i.StagingDir) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dest, err := pathutil.Join(i.StagingDir, rel)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(dest); err == nil {
+			// Staged in both places - keep both
+			return nil
+		}
+
+		if !i.DryRun {
+			if err := i.moveFile(path, dest); err != nil {
+				return fmt.Errorf("failed to move %s: %w", rel, err)
+			}
+		}
+		moved = append(moved, rel)
+		return nil
+	})
+	if err != nil {
+		return moved, err
+	}
+
+	if !i.DryRun {
+		// Deepest first, so parents are empty when their turn comes
+		for n := len(dirs) - 1; n >= 0; n-- {
+			os.Remove(dirs[n])
+		}
+	}
+	return moved, nil
+}
+
+// moveFile moves a file, copying it when src and dest are on different
+// devices.
+func (i *Importer) moveFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+	if err := i.copyFile(src, dest); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
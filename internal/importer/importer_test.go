@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -307,6 +309,82 @@ This is a plain document.`
 	assert.FileExists(t, filepath.Join(registryDir, "import", "without-regis3.md"))
 }
 
+func TestImporter_Dialect(t *testing.T) {
+	externalDir := t.TempDir()
+	registryDir := t.TempDir()
+
+	legacy := `+++
+[meta.regis3]
+type = "skill"
+name = "legacy-skill"
+desc = "A skill written for another tool"
++++
+# Legacy Skill`
+	require.NoError(t, os.WriteFile(filepath.Join(externalDir, "legacy.md"), []byte(legacy), 0644))
+
+	t.Run("default dialect stages the file", func(t *testing.T) {
+		importer := NewImporter(registryDir)
+		importer.DryRun = true
+		result, err := importer.ScanAndImport(externalDir)
+		require.NoError(t, err)
+		assert.Empty(t, result.Imported)
+		assert.Len(t, result.Staged, 1)
+	})
+
+	t.Run("configured dialect imports it as is", func(t *testing.T) {
+		dialect := registry.Dialect{
+			Formats: []frontmatter.Format{frontmatter.FormatYAML, frontmatter.FormatTOML},
+			Keys:    []string{"regis3", "meta.regis3"},
+		}
+		importer := NewImporter(registryDir)
+		importer.Dialect = dialect
+		importer.Scanner.Dialect = dialect
+		importer.Classifier.Dialect = dialect
+
+		result, err := importer.ScanAndImport(externalDir)
+		require.NoError(t, err)
+		require.Len(t, result.Imported, 1)
+		assert.Equal(t, "legacy-skill", result.Imported[0].Name)
+
+		data, err := os.ReadFile(filepath.Join(registryDir, "skills", "legacy-skill.md"))
+		require.NoError(t, err)
+		assert.Equal(t, legacy, string(data))
+
+		build, err := importer.Reindex()
+		require.NoError(t, err)
+		assert.Contains(t, build.Manifest.Items, "skill:legacy-skill")
+	})
+}
+
+func TestImporter_RejectsPathTraversal(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	externalDir := filepath.Join(tmpDir, "external")
+	registryDir := filepath.Join(tmpDir, "registry")
+	require.NoError(t, os.MkdirAll(externalDir, 0755))
+	require.NoError(t, os.MkdirAll(registryDir, 0755))
+
+	malicious := `---
+regis3:
+  type: skill
+  name: ../../escaped
+  desc: Tries to write outside the registry
+---
+# Malicious`
+	require.NoError(t, os.WriteFile(filepath.Join(externalDir, "malicious.md"), []byte(malicious), 0644))
+
+	importer := NewImporter(registryDir)
+	result, err := importer.ScanAndImport(externalDir)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Imported)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "unsafe path")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "escaped.md"))
+}
+
 func TestImporter_DryRun(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
 	require.NoError(t, err)
@@ -423,6 +501,61 @@ func TestImporter_StagingExists(t *testing.T) {
 	assert.True(t, importer.StagingExists())
 }
 
+func TestImporter_ConfiguredStagingDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	sourceDir := filepath.Join(tmpDir, "source")
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "notes.md"), []byte("# Notes\n"), 0644))
+
+	importer := NewImporter(registryDir)
+	importer.StagingDir = filepath.Join(tmpDir, "staging")
+
+	result, err := importer.ScanAndImport(sourceDir)
+	require.NoError(t, err)
+	require.Len(t, result.Staged, 1)
+	assert.FileExists(t, filepath.Join(tmpDir, "staging", "notes.md"))
+	assert.NoDirExists(t, filepath.Join(registryDir, ImportDir))
+
+	pending, err := importer.ListPending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "notes.md", pending[0].Path)
+}
+
+func TestImporter_MigrateStaging(t *testing.T) {
+	registryDir := t.TempDir()
+	legacy := filepath.Join(registryDir, ImportDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(legacy, "team"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "notes.md"), []byte("# Notes\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "team", "review.md"), []byte("# Review\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "both.md"), []byte("# Old\n"), 0644))
+
+	importer := NewImporter(registryDir)
+	importer.StagingDir = filepath.Join(registryDir, ".staging")
+	require.NoError(t, os.MkdirAll(importer.StagingDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(importer.StagingDir, "both.md"), []byte("# New\n"), 0644))
+
+	moved, err := importer.MigrateStaging(legacy)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"notes.md", filepath.Join("team", "review.md")}, moved)
+
+	assert.FileExists(t, filepath.Join(importer.StagingDir, "notes.md"))
+	assert.FileExists(t, filepath.Join(importer.StagingDir, "team", "review.md"))
+	assert.NoDirExists(t, filepath.Join(legacy, "team"), "emptied directories are removed")
+	assert.FileExists(t, filepath.Join(legacy, "both.md"), "files staged in both places stay")
+	content, err := os.ReadFile(filepath.Join(importer.StagingDir, "both.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New\n", string(content))
+
+	// Nothing left to move
+	require.NoError(t, os.Remove(filepath.Join(legacy, "both.md")))
+	moved, err = importer.MigrateStaging(legacy)
+	require.NoError(t, err)
+	assert.Empty(t, moved)
+	assert.NoDirExists(t, legacy)
+}
+
 func TestClassifier_AddFrontmatterToContent(t *testing.T) {
 	classifier := NewClassifier()
 
@@ -459,3 +592,170 @@ Content here.`,
 		assert.Contains(t, result, "# Test")
 	})
 }
+
+func TestSplitOnH1(t *testing.T) {
+	content := `---
+title: Collection
+---
+Prompts I use often.
+
+# Code Review
+
+You are a reviewer.
+
+` + "```md\n# Not a heading\n```" + `
+
+# Commit Messages
+
+Write conventional commits.
+`
+
+	sections := SplitOnH1(content)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Code Review", sections[0].Title)
+	assert.Contains(t, sections[0].Content, "Prompts I use often.", "text before the first heading goes to the first section")
+	assert.Contains(t, sections[0].Content, "# Not a heading", "headings in code fences don't split")
+	assert.NotContains(t, sections[0].Content, "title: Collection")
+	assert.Equal(t, "Commit Messages", sections[1].Title)
+	assert.Equal(t, "# Commit Messages\n\nWrite conventional commits.\n", sections[1].Content)
+
+	single := SplitOnH1("# Only One\n\n## Sub\n")
+	assert.Len(t, single, 1)
+}
+
+func TestImporter_SplitOnH1(t *testing.T) {
+	tmpDir := t.TempDir()
+	externalDir := filepath.Join(tmpDir, "external")
+	registryDir := filepath.Join(tmpDir, "registry")
+	require.NoError(t, os.MkdirAll(filepath.Join(externalDir, "prompts"), 0755))
+
+	collection := "# Code Review\n\nYou are a reviewer.\n\n# Code Review\n\nA second take.\n\n# Testing Guidelines\n\nBest practice for tests.\n"
+	require.NoError(t, os.WriteFile(filepath.Join(externalDir, "prompts", "collection.md"), []byte(collection), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(externalDir, "single.md"), []byte("# Single\n\nOne item.\n"), 0644))
+
+	importer := NewImporter(registryDir)
+	importer.SplitOnH1 = true
+	importer.DryRun = true
+
+	preview, err := importer.ScanAndImport(externalDir)
+	require.NoError(t, err)
+	require.Len(t, preview.Split, 3)
+	assert.Len(t, preview.Staged, 1, "files with one heading are staged whole")
+	assert.NoDirExists(t, filepath.Join(registryDir, "import"), "dry run writes nothing")
+
+	importer.DryRun = false
+	result, err := importer.ScanAndImport(externalDir)
+	require.NoError(t, err)
+	require.Len(t, result.Split, 3)
+
+	var names []string
+	for _, part := range result.Split {
+		names = append(names, part.Name)
+		assert.Equal(t, "prompt", part.Type, "directory hints apply to every section")
+	}
+	assert.Equal(t, []string{"code-review", "code-review-2", "testing-guidelines"}, names)
+	assert.Equal(t, "Testing Guidelines", result.Split[2].Section)
+
+	staged, err := os.ReadFile(filepath.Join(registryDir, "import", "prompts", "code-review-2.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(staged), "name: code-review-2")
+	assert.Contains(t, string(staged), "desc: A second take\n", "descriptions come from the section")
+	assert.Contains(t, string(staged), "A second take.")
+	assert.NotContains(t, string(staged), "You are a reviewer.")
+	assert.FileExists(t, filepath.Join(registryDir, "import", "single.md"))
+	assert.NoFileExists(t, filepath.Join(registryDir, "import", "prompts", "collection.md"))
+}
+
+func TestImporter_MergeStaged(t *testing.T) {
+	skill := `---
+regis3:
+  type: skill
+  name: testing
+  desc: Testing practices
+---
+# Testing
+
+Write tests first.
+`
+	tests := []struct {
+		name     string
+		staged   string
+		mode     MergeMode
+		source   []string // expected in the item's source
+		additive string   // expected additional file
+	}{
+		{
+			name:   "section demotes headings",
+			staged: "---\ntitle: notes\n---\n# Table Tests\n\nUse tables.\n\n```sh\n# comment\n```\n",
+			mode:   MergeSection,
+			source: []string{"Write tests first.\n\n## Table Tests\n\nUse tables.\n\n```sh\n# comment\n```\n"},
+		},
+		{
+			name:   "section without heading is titled from the file name",
+			staged: "Mock at the boundaries.\n",
+			mode:   MergeSection,
+			source: []string{"## Table tests\n\nMock at the boundaries.\n"},
+		},
+		{
+			name:     "file",
+			staged:   "# Table Tests\n\nUse tables.\n",
+			mode:     MergeFile,
+			source:   []string{"  files:\n    - table_tests.md\n", "# Testing\n\nWrite tests first.\n"},
+			additive: "# Table Tests\n\nUse tables.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "skills"), 0755))
+			require.NoError(t, os.MkdirAll(filepath.Join(registryDir, ImportDir), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "testing.md"), []byte(skill), 0644))
+			staged := filepath.Join(registryDir, ImportDir, "table_tests.md")
+			require.NoError(t, os.WriteFile(staged, []byte(tt.staged), 0644))
+
+			item := &registry.Item{
+				Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing"},
+				Source:     "skills/testing.md",
+				SourceDir:  "skills",
+			}
+			merged, err := NewImporter(registryDir).MergeStaged("import/table_tests.md", item, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, "skill:testing", merged.Ref)
+			assert.NoFileExists(t, staged, "merged files leave staging")
+
+			source, err := os.ReadFile(filepath.Join(registryDir, "skills", "testing.md"))
+			require.NoError(t, err)
+			for _, s := range tt.source {
+				assert.Contains(t, string(source), s)
+			}
+			assert.NotContains(t, string(source), "title: notes")
+
+			if tt.additive != "" {
+				assert.Equal(t, filepath.Join(registryDir, "skills", "table_tests.md"), merged.DestPath)
+				content, err := os.ReadFile(merged.DestPath)
+				require.NoError(t, err)
+				assert.Equal(t, tt.additive, string(content))
+			}
+		})
+	}
+}
+
+func TestImporter_MergeStagedErrors(t *testing.T) {
+	registryDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "skills"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, ImportDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "notes.md"), []byte("existing"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, ImportDir, "notes.md"), []byte("# Notes\n"), 0644))
+
+	item := &registry.Item{Source: "skills/testing.md", SourceDir: "skills"}
+	imp := NewImporter(registryDir)
+
+	_, err := imp.MergeStaged("notes.md", item, MergeFile)
+	assert.ErrorContains(t, err, "already exists")
+	_, err = imp.MergeStaged("../skills/notes.md", item, MergeSection)
+	assert.Error(t, err, "staged paths stay in staging")
+	_, err = imp.MergeStaged("notes.md", item, "append")
+	assert.ErrorContains(t, err, "unknown merge mode")
+	assert.FileExists(t, filepath.Join(registryDir, ImportDir, "notes.md"))
+}
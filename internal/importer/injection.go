@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InjectionFinding is a suspected prompt-injection pattern in imported content.
+type InjectionFinding struct {
+	// Line is the 1-based line where the pattern starts.
+	Line int
+
+	// Pattern names what was found.
+	Pattern string
+
+	// Excerpt is the matched text, shortened for display.
+	Excerpt string
+}
+
+func (f InjectionFinding) String() string {
+	return fmt.Sprintf("line %d: %s: %q", f.Line, f.Pattern, f.Excerpt)
+}
+
+// injectionPatterns are common phrasings used to hijack an assistant's
+// instructions. They are heuristics: matches need a human look, not a verdict.
+var injectionPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"instruction override", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(the\s+)?(previous|prior|above|earlier|preceding|system)\s+(instructions|prompts?|rules|messages|context)`)},
+	{"role reassignment", regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|in)\b`)},
+	{"secrecy request", regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|mention\s+(this\s+)?to|reveal\s+(this\s+)?to)\s+the\s+user\b`)},
+	{"system prompt reference", regexp.MustCompile(`(?i)\b(reveal|print|output|show)\s+(your|the)\s+system\s+prompt\b`)},
+}
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+	base64Pattern      = regexp.MustCompile(`[A-Za-z0-9+/]{80,}={0,2}`)
+)
+
+// invisibleRunes can hide text from a human reader while a model still sees it.
+var invisibleRunes = map[rune]string{
+	'\u200b': "zero-width space",
+	'\u200c': "zero-width non-joiner",
+	'\u200d': "zero-width joiner",
+	'\u2060': "word joiner",
+	'\u202e': "right-to-left override",
+	'\ufeff': "zero-width no-break space",
+}
+
+// ScanInjection checks content for common prompt-injection patterns: hidden
+// HTML comments, instruction overrides ("ignore previous instructions"),
+// long base64 blobs and invisible characters. Findings are in content order.
+func ScanInjection(content string) []InjectionFinding {
+	var findings []InjectionFinding
+	lineAt := func(offset int) int {
+		return strings.Count(content[:offset], "\n") + 1
+	}
+
+	for _, m := range htmlCommentPattern.FindAllStringSubmatchIndex(content, -1) {
+		if text := strings.TrimSpace(content[m[2]:m[3]]); text != "" {
+			findings = append(findings, InjectionFinding{Line: lineAt(m[0]), Pattern: "hidden HTML comment", Excerpt: excerpt(text)})
+		}
+	}
+	for _, p := range injectionPatterns {
+		for _, m := range p.re.FindAllStringIndex(content, -1) {
+			findings = append(findings, InjectionFinding{Line: lineAt(m[0]), Pattern: p.name, Excerpt: excerpt(content[m[0]:m[1]])})
+		}
+	}
+	for _, m := range base64Pattern.FindAllStringIndex(content, -1) {
+		findings = append(findings, InjectionFinding{Line: lineAt(m[0]), Pattern: "base64 blob", Excerpt: excerpt(content[m[0]:m[1]])})
+	}
+	for i, line := range strings.Split(content, "\n") {
+		for _, r := range line {
+			if name, ok := invisibleRunes[r]; ok {
+				findings = append(findings, InjectionFinding{Line: i + 1, Pattern: "invisible character", Excerpt: name})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// excerpt collapses whitespace and shortens s for display.
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return s
+}
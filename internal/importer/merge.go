@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// MergeMode selects how a staged file is merged into an existing item.
+type MergeMode string
+
+const (
+	// MergeSection appends the staged content to the item's source file as
+	// a new section.
+	MergeSection MergeMode = "section"
+
+	// MergeFile copies the staged content next to the item's source and
+	// adds it to the item's files.
+	MergeFile MergeMode = "file"
+)
+
+// MergedFile represents a staged file merged into an existing item.
+type MergedFile struct {
+	// SourcePath is the staged file.
+	SourcePath string
+
+	// DestPath is the file that received the content: the item's source
+	// for MergeSection, the new additional file for MergeFile.
+	DestPath string
+
+	// Ref is the item merged into.
+	Ref string
+
+	// Mode is how the content was merged.
+	Mode MergeMode
+}
+
+// MergeStaged merges the staged file at stagedPath, relative to the import
+// staging directory, into an existing registry item instead of creating a
+// new item. The staged file's frontmatter is dropped and the file is removed
+// from staging.
+func (i *Importer) MergeStaged(stagedPath string, item *registry.Item, mode MergeMode) (*MergedFile, error) {
+	if mode != MergeSection && mode != MergeFile {
+		return nil, fmt.Errorf("unknown merge mode %q (use %s or %s)", mode, MergeSection, MergeFile)
+	}
+
+	// Accept paths as listed by import --list or from the registry root
+	stagedPath = filepath.ToSlash(stagedPath)
+	if rel := registry.StagingRel(i.RegistryPath, i.StagingDir); rel != "" {
+		stagedPath = strings.TrimPrefix(stagedPath, rel+"/")
+	}
+	source, err := pathutil.Join(i.StagingDir, stagedPath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	body := string(content)
+	if doc, err := frontmatter.ParseBytes(content); err == nil && doc.Frontmatter != "" {
+		body = doc.Body
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%s has no content to merge", stagedPath)
+	}
+
+	itemSource, err := pathutil.Join(i.RegistryPath, item.Source)
+	if err != nil {
+		return nil, err
+	}
+	merged := &MergedFile{SourcePath: source, Ref: item.FullName(), Mode: mode}
+
+	switch mode {
+	case MergeSection:
+		merged.DestPath = itemSource
+		if !i.DryRun {
+			existing, err := os.ReadFile(itemSource)
+			if err != nil {
+				return nil, err
+			}
+			text := strings.TrimRight(string(existing), "\n") + "\n\n" + asSection(body, stagedPath) + "\n"
+			if err := i.writeFile(itemSource, []byte(text)); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", item.Source, err)
+			}
+		}
+
+	case MergeFile:
+		name := filepath.Base(stagedPath)
+		dest, err := pathutil.Join(i.RegistryPath, item.SourceDir, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(dest); err == nil {
+			return nil, fmt.Errorf("%s already exists next to %s", name, item.Source)
+		}
+		merged.DestPath = dest
+		if !i.DryRun {
+			if err := i.writeFile(dest, []byte(body+"\n")); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", name, err)
+			}
+			if err := registry.AddFiles(itemSource, []string{name}); err != nil {
+				os.Remove(dest)
+				return nil, fmt.Errorf("failed to add %s to %s: %w", name, item.Source, err)
+			}
+		}
+	}
+
+	if !i.DryRun {
+		if err := os.Remove(source); err != nil {
+			return nil, fmt.Errorf("failed to remove staged file: %w", err)
+		}
+	}
+	return merged, nil
+}
+
+// asSection turns content into a section of another document: headings move
+// down one level, and content without a leading heading gets one from the
+// file name.
+func asSection(body, path string) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.HasPrefix(line, "#") && strings.HasPrefix(strings.TrimLeft(line, "#"), " ") && !strings.HasPrefix(line, "######") {
+			lines[n] = "#" + line
+		}
+	}
+
+	section := strings.Join(lines, "\n")
+	if !strings.HasPrefix(section, "#") {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		title := strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }), " ")
+		if title != "" {
+			title = strings.ToUpper(title[:1]) + title[1:]
+		}
+		section = "## " + title + "\n\n" + section
+	}
+	return section
+}
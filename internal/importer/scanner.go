@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
 )
 
 // ExternalScanner scans external directories for markdown files.
@@ -13,6 +16,10 @@ type ExternalScanner struct {
 
 	// Extensions are file extensions to include.
 	Extensions []string
+
+	// Dialect selects the frontmatter formats and keys regis3 metadata is
+	// read from.
+	Dialect registry.Dialect
 }
 
 // NewExternalScanner creates a new external scanner with defaults.
@@ -60,7 +67,7 @@ type ScannedFile struct {
 	// Size is the file size in bytes.
 	Size int64
 
-	// HasFrontmatter indicates if the file has YAML frontmatter.
+	// HasFrontmatter indicates if the file has frontmatter.
 	HasFrontmatter bool
 
 	// HasRegis3 indicates if the file has a regis3 block.
@@ -210,39 +217,14 @@ func (s *ExternalScanner) checkFrontmatter(path string) (hasFrontmatter, hasRegi
 		return false, false, err
 	}
 
-	content := string(buf[:n])
-
-	// Check for frontmatter delimiter
-	if !strings.HasPrefix(content, "---") {
-		return false, false, nil
-	}
-
-	// Find closing delimiter
-	lines := strings.Split(content, "\n")
-	if len(lines) < 2 {
+	// Frontmatter in a format the dialect doesn't read is just content
+	doc, err := frontmatter.ParseBytes(buf[:n])
+	if err != nil || !s.Dialect.Accepts(doc.Format) {
 		return false, false, nil
 	}
 
-	inFrontmatter := false
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if i == 0 && trimmed == "---" {
-			inFrontmatter = true
-			continue
-		}
-		if inFrontmatter {
-			if trimmed == "---" {
-				hasFrontmatter = true
-				break
-			}
-			// Check for regis3 block
-			if strings.HasPrefix(trimmed, "regis3:") {
-				hasRegis3 = true
-			}
-		}
-	}
-
-	return hasFrontmatter, hasRegis3, nil
+	_, _, err = s.Dialect.ParseMeta(buf[:n])
+	return true, err == nil, nil
 }
 
 // isMarkdown checks if a file is a markdown file.
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// Section is a part of a markdown file that starts with a level-1 heading.
+type Section struct {
+	// Title is the heading text.
+	Title string
+
+	// Content is the section, including its heading.
+	Content string
+}
+
+// SplitOnH1 splits markdown content at its level-1 headings. Text before the
+// first heading belongs to the first section, and headings inside code
+// fences don't split. Frontmatter is dropped. Content with fewer than two
+// headings gives a single section.
+func SplitOnH1(content string) []Section {
+	if doc, err := frontmatter.ParseBytes([]byte(content)); err == nil && doc.Frontmatter != "" {
+		content = doc.Body
+	}
+
+	var sections []Section
+	var current []string
+	var title string
+	inFence := false
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(current, "\n"))
+		if text != "" {
+			sections = append(sections, Section{Title: title, Content: text + "\n"})
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if heading, ok := strings.CutPrefix(line, "# "); ok && !inFence {
+			if title != "" {
+				flush()
+			}
+			title = strings.TrimSpace(heading)
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	if len(sections) < 2 {
+		return []Section{{Title: title, Content: strings.TrimSpace(content) + "\n"}}
+	}
+	return sections
+}
+
+// splitScannedFile stages each section of a file without regis3 frontmatter
+// as its own item, with suggested frontmatter. It returns nil when the file
+// has fewer than two sections, so it is imported whole.
+func (i *Importer) splitScannedFile(file ScannedFile) ([]ImportedFile, error) {
+	class, err := i.Classifier.Classify(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify: %w", err)
+	}
+	if class.HasValidRegis3 {
+		return nil, nil
+	}
+
+	sections := SplitOnH1(class.Content)
+	if len(sections) < 2 {
+		return nil, nil
+	}
+
+	stagingDir, err := pathutil.Join(i.StagingDir, filepath.Dir(file.RelPath))
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]ImportedFile, 0, len(sections))
+	used := make(map[string]bool)
+	for n, section := range sections {
+		name := toKebabCase(section.Title)
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", class.SuggestedName, n+1)
+		}
+		for base, k := name, 2; used[name]; k++ {
+			name = fmt.Sprintf("%s-%d", base, k)
+		}
+		used[name] = true
+
+		itemType, _, _ := i.Classifier.suggestType(file.Path, section.Content)
+		part := &Classification{
+			Path:          file.Path,
+			SuggestedType: itemType,
+			SuggestedName: name,
+			SuggestedDesc: i.Classifier.describe(file.Path, section.Content),
+			Content:       section.Content,
+		}
+
+		destPath, err := pathutil.Join(stagingDir, name+".md")
+		if err != nil {
+			return nil, err
+		}
+		if !i.DryRun {
+			if _, err := os.Stat(destPath); err == nil {
+				// Part exists - skip
+				continue
+			}
+			content := i.Classifier.AddFrontmatterToContent(part, "")
+			if err := i.writeFile(destPath, []byte(content)); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+
+		parts = append(parts, ImportedFile{
+			SourcePath: file.Path,
+			DestPath:   destPath,
+			Type:       itemType,
+			Name:       name,
+			Desc:       part.SuggestedDesc,
+			Section:    section.Title,
+			Lines:      strings.Count(section.Content, "\n"),
+			WasStaged:  true,
+		})
+	}
+	return parts, nil
+}
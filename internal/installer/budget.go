@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// mergeBudgetTop is how many of the largest merged items a budget report lists.
+const mergeBudgetTop = 3
+
+// MergeContribution is an item's share of the merge file's managed section.
+type MergeContribution struct {
+	ID   string
+	Size int
+}
+
+// MergeBudgetReport describes a managed section over its size budget.
+type MergeBudgetReport struct {
+	// File is the merge file (e.g. CLAUDE.md).
+	File string
+
+	// Size is the size of the managed section in bytes.
+	Size int
+
+	// Budget is the configured limit in bytes.
+	Budget int
+
+	// Largest are the biggest contributing items, largest first.
+	Largest []MergeContribution
+}
+
+func (r *MergeBudgetReport) Error() string {
+	parts := make([]string, len(r.Largest))
+	for i, c := range r.Largest {
+		parts[i] = fmt.Sprintf("%s (%s)", c.ID, registry.FormatSize(c.Size))
+	}
+	return fmt.Sprintf("%s managed section is %s, over the %s budget; largest: %s",
+		r.File, registry.FormatSize(r.Size), registry.FormatSize(r.Budget), strings.Join(parts, ", "))
+}
+
+// Contributions returns the size each merged item adds, largest first.
+func (m *MergeContent) Contributions() []MergeContribution {
+	var contributions []MergeContribution
+	for _, sections := range m.sections {
+		for _, section := range sections {
+			contributions = append(contributions, MergeContribution{ID: section.Item.FullName(), Size: len(section.Content)})
+		}
+	}
+	sortContributions(contributions)
+	return contributions
+}
+
+// sectionContributions returns the size of each item's block in a managed
+// section, largest first. Markers and the heading of the item's type that
+// Generate puts in the first block of a type are not counted.
+func sectionContributions(section string) []MergeContribution {
+	var contributions []MergeContribution
+	for _, seg := range parseMergeFile(section) {
+		if seg.id == "" {
+			continue
+		}
+		itemType, _, _ := strings.Cut(seg.id, ":")
+		content := strings.TrimPrefix(seg.inner(), fmt.Sprintf("## %s\n\n", capitalizeFirst(itemType)))
+		contributions = append(contributions, MergeContribution{ID: seg.id, Size: len(content)})
+	}
+	sortContributions(contributions)
+	return contributions
+}
+
+// sortContributions sorts contributions largest first, then by ID.
+func sortContributions(contributions []MergeContribution) {
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Size != contributions[j].Size {
+			return contributions[i].Size > contributions[j].Size
+		}
+		return contributions[i].ID < contributions[j].ID
+	})
+}
+
+// checkMergeBudget returns a report if the managed section written with
+// mergeContent exceeds MergeBudget, or nil if it fits or no budget is set.
+// The section includes the blocks of merged items not in mergeContent.
+func (i *Installer) checkMergeBudget(mergeContent *MergeContent) (*MergeBudgetReport, error) {
+	if i.MergeBudget <= 0 {
+		return nil, nil
+	}
+	_, section, err := i.updatedMergeSection(mergeContent)
+	if err != nil {
+		return nil, err
+	}
+	if len(section) <= i.MergeBudget {
+		return nil, nil
+	}
+	largest := sectionContributions(section)
+	if len(largest) > mergeBudgetTop {
+		largest = largest[:mergeBudgetTop]
+	}
+	return &MergeBudgetReport{
+		File:    i.Target.MergeFile,
+		Size:    len(section),
+		Budget:  i.MergeBudget,
+		Largest: largest,
+	}, nil
+}
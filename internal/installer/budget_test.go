@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstaller_MergeBudget(t *testing.T) {
+	newManifest := func(registryDir string) *registry.Manifest {
+		manifest := registry.NewManifest(registryDir)
+		for name, size := range map[string]int{"big": 600, "medium": 300, "small": 50, "tiny": 10} {
+			manifest.AddItem(&registry.Item{
+				Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Desc: "Principles"},
+				Content:    strings.Repeat("x", size),
+				Source:     "philosophies/" + name + ".md",
+			})
+		}
+		return manifest
+	}
+	ids := []string{"philosophy:big", "philosophy:medium", "philosophy:small", "philosophy:tiny"}
+
+	tests := []struct {
+		name   string
+		budget int
+		strict bool
+		over   bool
+	}{
+		{name: "no budget", budget: 0},
+		{name: "within budget", budget: 2048},
+		{name: "over budget warns", budget: 512, over: true},
+		{name: "over budget strict", budget: 512, strict: true, over: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryDir := t.TempDir()
+			projectDir := t.TempDir()
+
+			installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			installer.MergeBudget = tt.budget
+			installer.StrictMergeBudget = tt.strict
+
+			result, err := installer.Install(newManifest(registryDir), ids)
+			if !tt.over {
+				require.NoError(t, err)
+				assert.Nil(t, result.MergeBudget)
+				return
+			}
+
+			require.NotNil(t, result.MergeBudget)
+			report := result.MergeBudget
+			assert.Equal(t, "CLAUDE.md", report.File)
+			assert.Equal(t, tt.budget, report.Budget)
+			assert.Greater(t, report.Size, tt.budget)
+			assert.Equal(t, []MergeContribution{
+				{ID: "philosophy:big", Size: 600},
+				{ID: "philosophy:medium", Size: 300},
+				{ID: "philosophy:small", Size: 50},
+			}, report.Largest)
+			assert.Contains(t, report.Error(), "largest: philosophy:big (600B), philosophy:medium (300B), philosophy:small (50B)")
+
+			_, statErr := os.Stat(filepath.Join(projectDir, "CLAUDE.md"))
+			if tt.strict {
+				assert.ErrorIs(t, err, report)
+				assert.True(t, os.IsNotExist(statErr), "strict budget must not write the merge file")
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, statErr)
+			}
+		})
+	}
+}
+
+func TestInstaller_MergeBudgetSeparateInstalls(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"first", "second"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Desc: "Principles"},
+			Content:    strings.Repeat("x", 600),
+			Source:     "philosophies/" + name + ".md",
+		})
+	}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	installer.MergeBudget = 1000
+	installer.StrictMergeBudget = true
+
+	result, err := installer.Install(manifest, []string{"philosophy:first"})
+	require.NoError(t, err)
+	assert.Nil(t, result.MergeBudget)
+	before, err := os.ReadFile(filepath.Join(projectDir, "CLAUDE.md"))
+	require.NoError(t, err)
+
+	// The block of the item installed before counts towards the budget
+	result, err = installer.Install(manifest, []string{"philosophy:second"})
+	require.NotNil(t, result.MergeBudget)
+	assert.ErrorIs(t, err, result.MergeBudget)
+	assert.Greater(t, result.MergeBudget.Size, 1200)
+	assert.Equal(t, []MergeContribution{
+		{ID: "philosophy:first", Size: 600},
+		{ID: "philosophy:second", Size: 600},
+	}, result.MergeBudget.Largest)
+
+	after, err := os.ReadFile(filepath.Join(projectDir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.False(t, installer.Tracker.IsInstalled("philosophy:second"))
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd)
+
+package installer
+
+// freeSpace reports that the free space is unknown on this platform, so
+// the disk space check is skipped.
+func freeSpace(dir string) (uint64, bool) {
+	return 0, false
+}
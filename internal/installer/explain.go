@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// Explanation tells what installing an item would do and why.
+type Explanation struct {
+	// Item is the item explained.
+	Item string
+
+	// Target is the target it would be installed to.
+	Target string
+
+	// Skipped is true when installing would leave the item as it is.
+	Skipped bool
+
+	// Reason is the rule or state deciding what happens to the item.
+	Reason string
+}
+
+// Explain tells whether installing the item with the given ID would skip
+// it and why, applying the same rules as Install without writing anything.
+// Dependencies of the item are not considered.
+func (i *Installer) Explain(manifest *registry.Manifest, id string) (*Explanation, error) {
+	manifest = i.Overrides.Apply(manifest)
+	item, ok := manifest.Items[id]
+	if !ok {
+		return nil, fmt.Errorf("item not found: %s", id)
+	}
+	if err := i.loadContent(item); err != nil {
+		return nil, err
+	}
+	item, _, err := i.withVariant(item)
+	if err != nil {
+		return nil, err
+	}
+	content, _, err := i.render(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform content: %w", err)
+	}
+
+	e := &Explanation{Item: id, Target: i.Target.Name}
+	_, reason, err := i.skipReason(item, hashItem(item, content))
+	switch {
+	case err != nil:
+		e.Reason = err.Error()
+	case reason != "":
+		e.Skipped = true
+		e.Reason = reason
+	case i.Target.IsMergeType(item.Type):
+		e.Reason = fmt.Sprintf("%s items are merged into %s, not skipped", item.Type, i.Target.MergeFile)
+	case item.Type == "stack":
+		e.Skipped = true
+		e.Reason = stackSkipReason
+	case i.Tracker.IsInstalled(id):
+		e.Reason = "the registry content changed since it was installed, it would be updated"
+	default:
+		e.Reason = "not installed yet, it would be installed"
+	}
+	return e, nil
+}
@@ -0,0 +1,98 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func explainManifest(registryDir string) *registry.Manifest {
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool"},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "clean", Desc: "Clean"},
+		Content:    "# Clean",
+		Source:     "philosophies/clean.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "base", Desc: "Base", Deps: []string{"skill:tool"}},
+		Content:    "# Base",
+		Source:     "stacks/base.md",
+	})
+	return manifest
+}
+
+func TestInstaller_Explain(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	manifest := explainManifest(registryDir)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	explain := func(id string) *Explanation {
+		e, err := inst.Explain(manifest, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, e.Item)
+		assert.Equal(t, "claude", e.Target)
+		return e
+	}
+
+	e := explain("skill:tool")
+	assert.False(t, e.Skipped)
+	assert.Contains(t, e.Reason, "would be installed")
+
+	e = explain("philosophy:clean")
+	assert.False(t, e.Skipped)
+	assert.Contains(t, e.Reason, "merged into CLAUDE.md")
+
+	e = explain("stack:base")
+	assert.True(t, e.Skipped)
+	assert.Equal(t, stackSkipReason, e.Reason)
+
+	_, err = inst.Install(manifest, []string{"stack:base"})
+	require.NoError(t, err)
+
+	e = explain("skill:tool")
+	assert.True(t, e.Skipped)
+	assert.Contains(t, e.Reason, "up to date")
+
+	require.True(t, inst.Tracker.SetPinned("skill:tool", true))
+	manifest.Items["skill:tool"].Content = "# Tool v2"
+	e = explain("skill:tool")
+	assert.True(t, e.Skipped)
+	assert.Contains(t, e.Reason, "pinned")
+
+	inst.Force = true
+	e = explain("skill:tool")
+	assert.False(t, e.Skipped)
+	assert.Contains(t, e.Reason, "would be updated")
+
+	_, err = inst.Explain(manifest, "skill:missing")
+	assert.Error(t, err)
+}
+
+func TestInstaller_InstallRecordsSkipReasons(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	manifest := explainManifest(registryDir)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	result, err := inst.Install(manifest, []string{"stack:base"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"stack:base": stackSkipReason}, result.SkipReasons)
+
+	result, err = inst.Install(manifest, []string{"stack:base"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"skill:tool", "stack:base"}, result.Skipped)
+	require.Len(t, result.SkipReasons, 2)
+	assert.Contains(t, result.SkipReasons["skill:tool"], "up to date")
+}
@@ -0,0 +1,174 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// GuardFile marks a project as being changed by a regis3 process. It holds
+// the process ID and the command, so other processes can wait for it or
+// report who holds it.
+const GuardFile = ".regis3/busy"
+
+// takeoverSuffix names the file a process holds while it takes over a
+// guard left behind by a process that exited.
+const takeoverSuffix = ".takeover"
+
+// BusyError indicates that another regis3 process is changing the project.
+type BusyError struct {
+	// PID is the process ID of the other process.
+	PID int
+
+	// Command is the command the other process runs.
+	Command string
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("another regis3 process (PID %d, %s) is changing this project", e.PID, e.Command)
+}
+
+// Guard keeps other regis3 processes from changing a project at the same
+// time, so their writes to the tracker and the merge file don't interleave.
+type Guard struct {
+	path string
+}
+
+// AcquireGuard claims the project for command. If another running process
+// holds it, the error is a *BusyError. A guard left behind by a process
+// that exited is taken over.
+func AcquireGuard(projectDir, command string) (*Guard, error) {
+	path := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(GuardFile), err)
+	}
+	// Write the guard next to its path and link it into place, which fails
+	// if the guard exists, so other processes never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".busy-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", GuardFile, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = fmt.Fprintf(tmp, "%d %s\n", os.Getpid(), command)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", GuardFile, err)
+	}
+
+	err = os.Link(tmp.Name(), path)
+	if err == nil {
+		return &Guard{path: path}, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("failed to create %s: %w", GuardFile, err)
+	}
+	if busy := readGuard(path); busy != nil && processAlive(busy.PID) {
+		return nil, busy
+	}
+	// The holder exited without releasing the guard
+	if err := takeOver(path, tmp.Name()); err != nil {
+		return nil, err
+	}
+	return &Guard{path: path}, nil
+}
+
+// takeOver replaces the guard at path, left behind by a process that
+// exited, with the guard written to tmp. Processes taking over the same
+// guard at once would remove each other's new guard, so they take turns
+// through a second file, created like the guard, and the guard is checked
+// to still be stale on the process's turn. If another process gets the
+// guard, the error is a *BusyError.
+func takeOver(path, tmp string) error {
+	turn := path + takeoverSuffix
+	for attempt := 0; ; attempt++ {
+		err := os.Link(tmp, turn)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create %s: %w", GuardFile+takeoverSuffix, err)
+		}
+		holder := readGuard(turn)
+		if holder != nil && processAlive(holder.PID) {
+			return holder
+		}
+		// A process exited while taking over; only retry once, as
+		// others may remove its file at the same time
+		if attempt > 0 {
+			if holder == nil {
+				holder = &BusyError{Command: "unknown"}
+			}
+			return holder
+		}
+		if err := os.Remove(turn); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale %s: %w", GuardFile+takeoverSuffix, err)
+		}
+	}
+	defer os.Remove(turn)
+
+	busy := readGuard(path)
+	if busy != nil && processAlive(busy.PID) {
+		return busy
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale %s: %w", GuardFile, err)
+	}
+	if err := os.Link(tmp, path); err != nil {
+		// A process that found no guard at all claimed it first
+		if busy := readGuard(path); busy != nil && errors.Is(err, os.ErrExist) {
+			return busy
+		}
+		return fmt.Errorf("failed to create %s: %w", GuardFile, err)
+	}
+	return nil
+}
+
+// Release gives up the guard. The guard's directory is removed too when
+// nothing else is in it. A guard that another process has taken over in
+// the meantime is left in place.
+func (g *Guard) Release() error {
+	if holder := readGuard(g.path); holder == nil || holder.PID != os.Getpid() {
+		return nil
+	}
+	if err := os.Remove(g.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", GuardFile, err)
+	}
+	os.Remove(filepath.Dir(g.path))
+	return nil
+}
+
+// readGuard returns the holder recorded in a guard file, or nil if the
+// file can't be read or parsed.
+func readGuard(path string) *BusyError {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	pid, command, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
+	n, err := strconv.Atoi(pid)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	return &BusyError{PID: n, Command: command}
+}
+
+// processAlive reports whether a process with the given ID is running.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Windows finds running processes only; signals aren't supported
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,176 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireGuard(t *testing.T) {
+	projectDir := t.TempDir()
+	guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+
+	guard, err := AcquireGuard(projectDir, "project add")
+	require.NoError(t, err)
+	data, err := os.ReadFile(guardPath)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d project add\n", os.Getpid()), string(data))
+
+	// A second claim fails while the guard is held
+	_, err = AcquireGuard(projectDir, "apply")
+	var busy *BusyError
+	require.ErrorAs(t, err, &busy)
+	assert.Equal(t, os.Getpid(), busy.PID)
+	assert.Equal(t, "project add", busy.Command)
+
+	require.NoError(t, guard.Release())
+	assert.NoFileExists(t, guardPath)
+	assert.NoDirExists(t, filepath.Dir(guardPath))
+
+	guard, err = AcquireGuard(projectDir, "apply")
+	require.NoError(t, err)
+	require.NoError(t, guard.Release())
+}
+
+func TestGuard_ReleaseTakenOver(t *testing.T) {
+	projectDir := t.TempDir()
+	guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+
+	guard, err := AcquireGuard(projectDir, "apply")
+	require.NoError(t, err)
+
+	// Another process took the guard over, e.g. after deeming it stale
+	other := fmt.Sprintf("%d project add\n", os.Getpid()+1)
+	require.NoError(t, os.WriteFile(guardPath, []byte(other), 0644))
+
+	require.NoError(t, guard.Release())
+	data, err := os.ReadFile(guardPath)
+	require.NoError(t, err)
+	assert.Equal(t, other, string(data))
+}
+
+func TestAcquireGuard_Stale(t *testing.T) {
+	// A process that has exited
+	cmd := exec.Command("go", "version")
+	require.NoError(t, cmd.Run())
+	exited := cmd.Process.Pid
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "exited process", content: fmt.Sprintf("%d project add\n", exited)},
+		{name: "unreadable content", content: "garbage"},
+		{name: "empty", content: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+			require.NoError(t, os.MkdirAll(filepath.Dir(guardPath), 0755))
+			require.NoError(t, os.WriteFile(guardPath, []byte(tt.content), 0644))
+
+			guard, err := AcquireGuard(projectDir, "apply")
+			require.NoError(t, err)
+			defer guard.Release()
+			assert.Equal(t, "apply", readGuard(guardPath).Command)
+		})
+	}
+}
+
+func TestAcquireGuard_StaleTakeover(t *testing.T) {
+	// A process that has exited
+	cmd := exec.Command("go", "version")
+	require.NoError(t, cmd.Run())
+	stale := fmt.Sprintf("%d project add\n", cmd.Process.Pid)
+
+	t.Run("one of many takes over", func(t *testing.T) {
+		for round := 0; round < 20; round++ {
+			projectDir := t.TempDir()
+			guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+			require.NoError(t, os.MkdirAll(filepath.Dir(guardPath), 0755))
+			require.NoError(t, os.WriteFile(guardPath, []byte(stale), 0644))
+
+			var wg sync.WaitGroup
+			guards := make(chan *Guard, 8)
+			for n := 0; n < cap(guards); n++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					guard, err := AcquireGuard(projectDir, "apply")
+					if err == nil {
+						guards <- guard
+						return
+					}
+					var busy *BusyError
+					assert.ErrorAs(t, err, &busy)
+				}()
+			}
+			wg.Wait()
+			close(guards)
+
+			require.Len(t, guards, 1)
+			require.NoError(t, (<-guards).Release())
+			assert.NoFileExists(t, guardPath)
+			assert.NoFileExists(t, guardPath+takeoverSuffix)
+		}
+	})
+
+	t.Run("late process keeps the new guard", func(t *testing.T) {
+		projectDir := t.TempDir()
+		guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+		require.NoError(t, os.MkdirAll(filepath.Dir(guardPath), 0755))
+		require.NoError(t, os.WriteFile(guardPath, []byte(stale), 0644))
+
+		guard, err := AcquireGuard(projectDir, "apply")
+		require.NoError(t, err)
+		defer guard.Release()
+
+		// Another process found the stale guard before it was taken over
+		late := filepath.Join(projectDir, ".busy-late")
+		require.NoError(t, os.WriteFile(late, []byte("1 project sync\n"), 0644))
+		err = takeOver(guardPath, late)
+		var busy *BusyError
+		require.ErrorAs(t, err, &busy)
+		assert.Equal(t, "apply", busy.Command)
+		assert.Equal(t, "apply", readGuard(guardPath).Command)
+	})
+
+	t.Run("left behind while taking over", func(t *testing.T) {
+		projectDir := t.TempDir()
+		guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+		require.NoError(t, os.MkdirAll(filepath.Dir(guardPath), 0755))
+		require.NoError(t, os.WriteFile(guardPath, []byte(stale), 0644))
+		require.NoError(t, os.WriteFile(guardPath+takeoverSuffix, []byte(stale), 0644))
+
+		guard, err := AcquireGuard(projectDir, "apply")
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), readGuard(guardPath).PID)
+		assert.NoFileExists(t, guardPath+takeoverSuffix)
+		require.NoError(t, guard.Release())
+	})
+
+	t.Run("taken over by a running process", func(t *testing.T) {
+		projectDir := t.TempDir()
+		guardPath := filepath.Join(projectDir, filepath.FromSlash(GuardFile))
+		require.NoError(t, os.MkdirAll(filepath.Dir(guardPath), 0755))
+		require.NoError(t, os.WriteFile(guardPath, []byte(stale), 0644))
+		running := fmt.Sprintf("%d project sync\n", os.Getpid())
+		require.NoError(t, os.WriteFile(guardPath+takeoverSuffix, []byte(running), 0644))
+
+		_, err := AcquireGuard(projectDir, "apply")
+		var busy *BusyError
+		require.ErrorAs(t, err, &busy)
+		assert.Equal(t, "project sync", busy.Command)
+		data, err := os.ReadFile(guardPath)
+		require.NoError(t, err)
+		assert.Equal(t, stale, string(data))
+	})
+}
@@ -3,10 +3,18 @@ package installer
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/profile"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/okto-digital/regis3/internal/resolver"
 )
@@ -22,6 +30,10 @@ type Installer struct {
 	// RegistryPath is the path to the registry.
 	RegistryPath string
 
+	// RegistryURL is the git URL of a remote registry, recorded in the
+	// lockfile instead of RegistryPath.
+	RegistryURL string
+
 	// Tracker tracks installed items.
 	Tracker *Tracker
 
@@ -33,11 +45,73 @@ type Installer struct {
 
 	// Force if true, reinstalls even if up to date.
 	Force bool
+
+	// ResolverOptions configures dependency resolution (e.g. capability providers).
+	ResolverOptions resolver.Options
+
+	// Timings, if set, records the resolve, verify, write and commit phases of Install.
+	Timings *profile.Timings
+
+	// AllowScripts runs item setup scripts without asking.
+	AllowScripts bool
+
+	// ConfirmScript is asked before running an item's setup script unless
+	// AllowScripts is set. If nil, setup scripts are skipped.
+	ConfirmScript func(item *registry.Item, script string) (bool, error)
+
+	// ScriptOutput receives the output of setup scripts (default: stderr).
+	ScriptOutput io.Writer
+
+	// MergeBudget limits the size in bytes of the merge file's managed
+	// section. Larger sections are reported in InstallResult.MergeBudget.
+	// Zero means no limit.
+	MergeBudget int
+
+	// StrictMergeBudget fails the installation when the managed section
+	// exceeds MergeBudget.
+	StrictMergeBudget bool
+
+	// ConfirmMerge is asked before the managed section of the merge file is
+	// rewritten in a way that loses content (see MergeChange). Declining
+	// cancels the installation with ErrMergeDeclined. If nil, the rewrite
+	// goes ahead and is only reported in InstallResult.MergeChange.
+	ConfirmMerge func(change *MergeChange) (bool, error)
+
+	// Overrides, if set, shadow registry items with project-local copies
+	// (see LoadOverrides).
+	Overrides *Overrides
+
+	// Link installs files as symbolic links to their registry sources
+	// instead of copies, so registry edits show in the project at once.
+	// Files the target rewrites, and encrypted bodies, are still copied.
+	Link bool
+
+	// Variant selects the named variant of the items that define it.
+	// Items keep the variant they were installed with unless another one
+	// they define is selected; registry.DefaultVariant selects their own
+	// body.
+	Variant string
+
+	// Explicit marks the requested items as installed explicitly, also
+	// when they were installed as dependencies before. Otherwise only new
+	// items are marked, by whether they were requested, so updates keep
+	// how items were installed.
+	Explicit bool
+
+	// tx stages writes during Install so they are applied together.
+	tx *Transaction
+
+	// lockDeps are the resolved dependencies of the items being installed,
+	// recorded in the lockfile.
+	lockDeps map[string][]string
+
+	// locked maps item IDs to how they are locked during Sync.
+	locked map[string]*LockedItem
 }
 
 // NewInstaller creates a new installer.
 func NewInstaller(projectDir, registryPath string, target *Target) (*Installer, error) {
-	tracker, err := LoadTracker(projectDir, target.Name)
+	tracker, err := LoadTargetTracker(projectDir, target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load tracker: %w", err)
 	}
@@ -66,11 +140,94 @@ type InstallResult struct {
 	// Skipped are items that were already up to date.
 	Skipped []string
 
+	// Pinned are pinned items kept at their installed content although
+	// the registry has changed.
+	Pinned []string
+
 	// Errors are installation errors.
 	Errors []InstallError
 
 	// MergedItems are items merged into CLAUDE.md.
 	MergedItems []string
+
+	// Choices maps stacks to the alternative picked from their one_of list.
+	Choices map[string]string
+
+	// Aliases maps requested aliases to the items they resolved to.
+	Aliases map[string]string
+
+	// Plan describes the resolved installation for hooks and scripts.
+	Plan *Plan
+
+	// Scripts are items whose setup script ran.
+	Scripts []string
+
+	// SkippedScripts are items whose setup script was not allowed to run.
+	SkippedScripts []string
+
+	// MergeBudget is set when the merge file's managed section exceeds
+	// the installer's MergeBudget.
+	MergeBudget *MergeBudgetReport
+
+	// MergeChange is set when the merge file's managed section lost
+	// content.
+	MergeChange *MergeChange
+
+	// Local are the resolved items taken from project-local overrides.
+	Local []string
+
+	// Deprecated maps the installed items marked deprecated to the items
+	// replacing them, or to "" if none is named.
+	Deprecated map[string]string
+
+	// SkipReasons maps skipped and pinned items to why they were left as
+	// they are.
+	SkipReasons map[string]string
+
+	// Variants maps the items installed with a variant to its name.
+	Variants map[string]string
+
+	// Linked are the items whose file was linked to the registry in link
+	// mode, and NotLinked maps those copied instead to why.
+	Linked    []string
+	NotLinked map[string]string
+
+	// LocalMerged are updated items whose installed file was changed
+	// locally; the changes were merged with the update.
+	LocalMerged []string
+
+	// Conflicts are updated items whose local changes conflict with the
+	// update. Their file holds both versions between conflict markers.
+	Conflicts []string
+
+	// KeptLocal are items skipped because their installed file was changed
+	// locally and there is no base copy to merge the update with.
+	KeptLocal []string
+
+	// RolledBack is set when items failed and the installation was
+	// cancelled: nothing was written, and only Errors lists items.
+	RolledBack bool
+
+	// committed is set once the installation's writes were applied.
+	committed bool
+}
+
+// rollBack clears the items the cancelled installation would have written.
+func (r *InstallResult) rollBack() {
+	r.RolledBack = true
+	r.Installed = nil
+	r.Updated = nil
+	r.Skipped = nil
+	r.Pinned = nil
+	r.MergedItems = nil
+	r.LocalMerged = nil
+	r.Conflicts = nil
+	r.KeptLocal = nil
+	r.Deprecated = nil
+	r.SkipReasons = nil
+	r.Variants = nil
+	r.Linked = nil
+	r.NotLinked = nil
 }
 
 // InstallError represents an installation error.
@@ -84,28 +241,162 @@ func (e InstallError) Error() string {
 	return fmt.Sprintf("%s: %s", e.ItemID, e.Message)
 }
 
+// CombineResults merges the results of installing the same items to
+// several targets, keyed by target name. An item is listed in the combined
+// result if it is listed for any target, and errors name their target.
+// Plans and merge file reports are per target and not combined.
+func CombineResults(results map[string]*InstallResult) *InstallResult {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	union := func(into []string, ids []string) []string {
+		for _, id := range ids {
+			if !slices.Contains(into, id) {
+				into = append(into, id)
+			}
+		}
+		return into
+	}
+	merge := func(into map[string]string, m map[string]string) map[string]string {
+		for k, v := range m {
+			if into == nil {
+				into = make(map[string]string)
+			}
+			into[k] = v
+		}
+		return into
+	}
+
+	combined := &InstallResult{}
+	for _, name := range names {
+		r := results[name]
+		combined.Installed = union(combined.Installed, r.Installed)
+		combined.Updated = union(combined.Updated, r.Updated)
+		combined.Skipped = union(combined.Skipped, r.Skipped)
+		combined.Pinned = union(combined.Pinned, r.Pinned)
+		combined.MergedItems = union(combined.MergedItems, r.MergedItems)
+		combined.Scripts = union(combined.Scripts, r.Scripts)
+		combined.SkippedScripts = union(combined.SkippedScripts, r.SkippedScripts)
+		combined.Local = union(combined.Local, r.Local)
+		combined.LocalMerged = union(combined.LocalMerged, r.LocalMerged)
+		combined.Conflicts = union(combined.Conflicts, r.Conflicts)
+		combined.KeptLocal = union(combined.KeptLocal, r.KeptLocal)
+		combined.RolledBack = combined.RolledBack || r.RolledBack
+		combined.Choices = merge(combined.Choices, r.Choices)
+		combined.Aliases = merge(combined.Aliases, r.Aliases)
+		combined.Deprecated = merge(combined.Deprecated, r.Deprecated)
+		combined.Variants = merge(combined.Variants, r.Variants)
+		combined.Linked = union(combined.Linked, r.Linked)
+		combined.NotLinked = merge(combined.NotLinked, r.NotLinked)
+		for _, e := range r.Errors {
+			e.Message = name + ": " + e.Message
+			combined.Errors = append(combined.Errors, e)
+		}
+	}
+	return combined
+}
+
 // Install installs the specified items and their dependencies.
 func (i *Installer) Install(manifest *registry.Manifest, itemIDs []string) (*InstallResult, error) {
 	result := &InstallResult{}
+	manifest = i.Overrides.Apply(manifest)
 
 	// Resolve dependencies
-	r := resolver.NewResolver(manifest)
+	stop := i.Timings.Start("resolve")
+	r := resolver.NewResolverWithOptions(manifest, i.ResolverOptions)
 	resolved, err := r.Resolve(itemIDs)
+	stop()
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
 	}
+	result.Choices = resolved.Choices
+	result.Aliases = resolved.Aliases
+	requested := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		if target, ok := resolved.Aliases[id]; ok {
+			id = target
+		}
+		requested[id] = true
+	}
+	i.lockDeps = make(map[string][]string, len(resolved.Items))
+	for _, item := range resolved.Items {
+		if i.Overrides.Has(item) {
+			result.Local = append(result.Local, item.FullName())
+		}
+		deps := r.Graph().Dependencies(item.FullName())
+		if choice, ok := resolved.Choices[item.FullName()]; ok {
+			deps = append(append([]string{}, deps...), choice)
+		}
+		i.lockDeps[item.FullName()] = deps
+	}
+
+	result.Plan, err = i.NewPlan(resolved.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan installation: %w", err)
+	}
 
 	// Check for missing dependencies
 	if len(resolved.Missing) > 0 {
 		return nil, fmt.Errorf("missing dependencies: %v", resolved.Missing)
 	}
 
+	// Report what would fail partway before writing anything
+	if len(result.Plan.Preflight) > 0 {
+		for _, issue := range result.Plan.Preflight {
+			id := issue.Item
+			if id == "" {
+				id = "preflight"
+			}
+			result.Errors = append(result.Errors, InstallError{
+				ItemID:  id,
+				Message: issue.Message,
+				Err:     errors.New(issue.Message),
+			})
+		}
+		return result, nil
+	}
+
+	// Stage all writes so item files, the merge file and the tracker
+	// are applied together or not at all
+	if !i.DryRun {
+		tx, err := NewTransaction(i.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		i.tx = tx
+		restore := i.Tracker.snapshot()
+		defer func() {
+			tx.Discard()
+			i.tx = nil
+			if !result.committed {
+				restore()
+			}
+		}()
+	}
+
 	// Prepare merge content
 	mergeContent := NewMergeContent()
 
 	// Install each item in order
 	for _, item := range resolved.Items {
-		itemResult, err := i.installItem(item, mergeContent)
+		// Catch missing or modified files before writing anything for the item
+		stop := i.Timings.Start("verify")
+		err := i.loadContent(item)
+		if err == nil {
+			err = item.VerifyFiles(i.sourceRoot(item))
+		}
+		selected, variant := item, ""
+		if err == nil {
+			selected, variant, err = i.withVariant(item)
+		}
+		link, notLinked := "", ""
+		if err == nil && i.Link && item.Type != "stack" && !i.Target.IsMergeType(item.Type) {
+			link, notLinked, err = i.linkSource(selected, variant)
+		}
+		stop()
 		if err != nil {
 			result.Errors = append(result.Errors, InstallError{
 				ItemID:  item.FullName(),
@@ -115,39 +406,190 @@ func (i *Installer) Install(manifest *registry.Manifest, itemIDs []string) (*Ins
 			continue
 		}
 
+		stop = i.Timings.Start("write")
+		wasInstalled := i.Tracker.IsInstalled(item.FullName())
+		itemResult, reason, err := i.installItem(selected, variant, link, mergeContent)
+		stop()
+		if err != nil {
+			result.Errors = append(result.Errors, InstallError{
+				ItemID:  item.FullName(),
+				Message: err.Error(),
+				Err:     err,
+			})
+			continue
+		}
+		i.markDependency(item.FullName(), requested[item.FullName()], wasInstalled)
+
+		// Items already installed were warned about when they were
+		if item.Deprecated() && itemResult != installResultSkipped && itemResult != installResultPinned && itemResult != installResultKeptLocal {
+			if result.Deprecated == nil {
+				result.Deprecated = make(map[string]string)
+			}
+			result.Deprecated[item.FullName()] = item.ReplacedBy
+		}
+
+		if reason != "" {
+			if result.SkipReasons == nil {
+				result.SkipReasons = make(map[string]string)
+			}
+			result.SkipReasons[item.FullName()] = reason
+		}
+
 		switch itemResult {
 		case installResultInstalled:
 			result.Installed = append(result.Installed, item.FullName())
 		case installResultUpdated:
 			result.Updated = append(result.Updated, item.FullName())
+		case installResultLocalMerged:
+			result.Updated = append(result.Updated, item.FullName())
+			result.LocalMerged = append(result.LocalMerged, item.FullName())
+		case installResultConflict:
+			result.Updated = append(result.Updated, item.FullName())
+			result.Conflicts = append(result.Conflicts, item.FullName())
+		case installResultKeptLocal:
+			result.Skipped = append(result.Skipped, item.FullName())
+			result.KeptLocal = append(result.KeptLocal, item.FullName())
 		case installResultSkipped:
 			result.Skipped = append(result.Skipped, item.FullName())
+		case installResultPinned:
+			result.Pinned = append(result.Pinned, item.FullName())
 		case installResultMerged:
 			result.MergedItems = append(result.MergedItems, item.FullName())
 		}
+
+		written := itemResult != installResultSkipped && itemResult != installResultKeptLocal && itemResult != installResultPinned
+		if variant != "" && written {
+			if result.Variants == nil {
+				result.Variants = make(map[string]string)
+			}
+			result.Variants[item.FullName()] = variant
+		}
+		if link != "" && written {
+			if installed := i.Tracker.GetInstalled(item.FullName()); i.DryRun || installed.Files[0].Link != "" {
+				result.Linked = append(result.Linked, item.FullName())
+			} else {
+				notLinked = "it was changed locally"
+			}
+		}
+		if notLinked != "" && written {
+			if result.NotLinked == nil {
+				result.NotLinked = make(map[string]string)
+			}
+			result.NotLinked[item.FullName()] = notLinked
+		}
+	}
+
+	// A failed item cancels the whole installation, so the project is
+	// never left half-installed
+	if len(result.Errors) > 0 && !i.DryRun {
+		result.rollBack()
+		return result, nil
 	}
 
 	// Write merged content to CLAUDE.md
 	if mergeContent.HasContent() {
-		if err := i.writeMergeFile(mergeContent); err != nil {
+		report, err := i.checkMergeBudget(mergeContent)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", i.Target.MergeFile, err)
+		}
+		if report != nil {
+			result.MergeBudget = report
+			if i.StrictMergeBudget {
+				result.Errors = append(result.Errors, InstallError{
+					ItemID:  i.Target.MergeFile,
+					Message: report.Error(),
+					Err:     report,
+				})
+				return result, report
+			}
+		}
+
+		change, err := i.checkMergeChange(mergeContent)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", i.Target.MergeFile, err)
+		}
+		if change != nil {
+			result.MergeChange = change
+			if i.ConfirmMerge != nil && !i.DryRun {
+				confirmed, err := i.ConfirmMerge(change)
+				if err != nil {
+					return result, err
+				}
+				if !confirmed {
+					return result, ErrMergeDeclined
+				}
+			}
+		}
+
+		stop := i.Timings.Start("write")
+		err = i.writeMergeFile(mergeContent)
+		stop()
+		if err != nil {
 			result.Errors = append(result.Errors, InstallError{
-				ItemID:  "CLAUDE.md",
+				ItemID:  i.Target.MergeFile,
 				Message: err.Error(),
 				Err:     err,
 			})
+			return result, fmt.Errorf("failed to write %s: %w", i.Target.MergeFile, err)
 		}
 	}
 
-	// Save tracker
-	if !i.DryRun {
-		if err := i.Tracker.Save(); err != nil {
-			return result, fmt.Errorf("failed to save tracker: %w", err)
+	if i.DryRun {
+		return result, nil
+	}
+
+	// Stage tracker and apply everything
+	stop = i.Timings.Start("commit")
+	err = i.commit()
+	stop()
+	if err != nil {
+		return result, err
+	}
+	result.committed = true
+
+	// Items are in place; run the setup scripts of those written now
+	written := make(map[string]bool)
+	for _, id := range append(append(append([]string{}, result.Installed...), result.Updated...), result.MergedItems...) {
+		written[id] = true
+	}
+	var setup []*registry.Item
+	for _, item := range resolved.Items {
+		if written[item.FullName()] && item.Setup != "" {
+			setup = append(setup, item)
 		}
 	}
+	i.runSetupScripts(setup, result)
 
 	return result, nil
 }
 
+// loadContent reads the body of items loaded from a manifest, which doesn't
+// store content.
+func (i *Installer) loadContent(item *registry.Item) error {
+	if item.Content != "" || item.Source == "" {
+		return nil
+	}
+	return item.LoadContent(i.sourceRoot(item))
+}
+
+// commit stages the tracker and lockfile and applies the transaction.
+func (i *Installer) commit() error {
+	data, err := i.Tracker.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to save tracker: %w", err)
+	}
+	if err := i.tx.WriteFile(i.Tracker.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save tracker: %w", err)
+	}
+	if err := i.stageLock(); err != nil {
+		return err
+	}
+	if err := i.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to apply installation: %w", err)
+	}
+	return nil
+}
+
 type installResultType int
 
 const (
@@ -155,22 +597,58 @@ const (
 	installResultUpdated
 	installResultSkipped
 	installResultMerged
+	installResultPinned
+	installResultLocalMerged
+	installResultConflict
+	installResultKeptLocal
 )
 
-// installItem installs a single item.
-func (i *Installer) installItem(item *registry.Item, mergeContent *MergeContent) (installResultType, error) {
+// stackSkipReason is why stacks are skipped: they have no file.
+const stackSkipReason = "stacks only group their dependencies, nothing is written for them"
+
+// skipReason decides whether installing item with content hash leaves it
+// as it is. It returns installResultSkipped or installResultPinned and the
+// reason, or an empty reason if the item is to be written.
+func (i *Installer) skipReason(item *registry.Item, hash string) (installResultType, string, error) {
+	installed := i.Tracker.GetInstalled(item.FullName())
+
+	// Locked items only install at their locked content
+	if locked, ok := i.locked[item.FullName()]; ok && hash != locked.SourceHash {
+		if installed != nil && installed.SourceHash == locked.SourceHash {
+			return installResultSkipped, fmt.Sprintf("kept at the content locked in %s", LockFile), nil
+		}
+		return 0, "", fmt.Errorf("registry content differs from %s (run 'regis3 project add' to install and lock it)", LockFile)
+	}
+
+	// Check if needs update; link mode replaces copies with links
+	relink := i.Link && installed != nil && !installed.Linked && !installed.Merged && installed.InstalledPath != ""
+	if !i.Force && !relink && !i.Tracker.NeedsUpdate(item.FullName(), hash) {
+		return installResultSkipped, "already installed and up to date (use --force to reinstall)", nil
+	}
+
+	// Pinned items stay on their installed content unless forced
+	if installed != nil && installed.Pinned && !i.Force {
+		return installResultPinned, "pinned to its installed content (use --force or 'regis3 project unpin' to update it)", nil
+	}
+	return 0, "", nil
+}
+
+// installItem installs a single item, whose content is that of the given
+// variant. Its file is a link to the registry file link if that is set and
+// the installed file wasn't changed locally. Skipped and pinned items come
+// with the reason they were left as they are.
+func (i *Installer) installItem(item *registry.Item, variant, link string, mergeContent *MergeContent) (installResultType, string, error) {
 	// Transform content
-	content, err := i.Transformer.Transform(item)
+	content, files, err := i.render(item)
 	if err != nil {
-		return 0, fmt.Errorf("failed to transform content: %w", err)
+		return 0, "", fmt.Errorf("failed to transform content: %w", err)
 	}
 
 	// Calculate content hash
-	hash := hashContent(content)
+	hash := hashItem(item, content)
 
-	// Check if needs update
-	if !i.Force && !i.Tracker.NeedsUpdate(item.FullName(), hash) {
-		return installResultSkipped, nil
+	if result, reason, err := i.skipReason(item, hash); err != nil || reason != "" {
+		return result, reason, err
 	}
 
 	// Handle merge types
@@ -179,8 +657,9 @@ func (i *Installer) installItem(item *registry.Item, mergeContent *MergeContent)
 		if !i.DryRun {
 			i.Tracker.MarkInstalled(item.FullName(), item.Type, item.Name, i.Target.MergeFile, true)
 			i.Tracker.SetSourceHash(item.FullName(), hash)
+			i.Tracker.SetVariant(item.FullName(), variant)
 		}
-		return installResultMerged, nil
+		return installResultMerged, "", nil
 	}
 
 	// Handle stack type (meta-type, no direct installation)
@@ -190,89 +669,270 @@ func (i *Installer) installItem(item *registry.Item, mergeContent *MergeContent)
 			i.Tracker.MarkInstalled(item.FullName(), item.Type, item.Name, "", false)
 			i.Tracker.SetSourceHash(item.FullName(), hash)
 		}
-		return installResultSkipped, nil
+		return installResultSkipped, stackSkipReason, nil
+	}
+
+	// Get file permissions
+	mode, err := item.FileMode()
+	if err != nil {
+		return 0, "", err
 	}
 
 	// Get installation path
 	destPath, err := i.Target.GetPath(item.Type, item.Name)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get installation path: %w", err)
+		return 0, "", fmt.Errorf("failed to get installation path: %w", err)
 	}
 
-	fullPath := filepath.Join(i.ProjectDir, destPath)
+	fullPath, err := pathutil.Join(i.ProjectDir, destPath)
+	if err != nil {
+		return 0, "", err
+	}
 
 	// Check if already installed
 	isUpdate := i.Tracker.IsInstalled(item.FullName())
 
+	// Keep changes made to the installed file since
+	written, merge, err := i.mergeLocalChanges(item, destPath, fullPath, content)
+	if err != nil {
+		return 0, "", err
+	}
+	if merge == localKept {
+		return installResultKeptLocal, "changed locally and no base to merge the update with; kept (use --force to overwrite)", nil
+	}
+	if merge != localNone {
+		link = ""
+	}
+
 	// Write file
 	if !i.DryRun {
-		if err := i.writeFile(fullPath, content); err != nil {
-			return 0, fmt.Errorf("failed to write file: %w", err)
+		if link != "" {
+			err = i.tx.Symlink(fullPath, link)
+		} else {
+			err = i.writeFile(fullPath, written, mode)
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to write file: %w", err)
 		}
+		if err := i.writeFile(i.basePath(item.Type, item.Name), content, 0644); err != nil {
+			return 0, "", fmt.Errorf("failed to write base copy: %w", err)
+		}
+		installed := []InstalledFile{{Path: filepath.ToSlash(destPath), SHA256: hashContent(content), Link: link}}
 
 		// Copy additional files if specified
-		if len(item.Files) > 0 {
-			if err := i.copyAdditionalFiles(item, filepath.Dir(fullPath)); err != nil {
-				return 0, fmt.Errorf("failed to copy additional files: %w", err)
+		if len(files) > 0 {
+			copied, err := i.copyAdditionalFiles(item, files, filepath.Dir(destPath))
+			if err != nil {
+				return 0, "", fmt.Errorf("failed to copy additional files: %w", err)
 			}
+			installed = append(installed, copied...)
+		}
+
+		// Remove files of the previous version that are no longer installed
+		if err := i.removeStaleFiles(item.FullName(), installed); err != nil {
+			return 0, "", err
 		}
 
 		// Update tracker
 		i.Tracker.MarkInstalled(item.FullName(), item.Type, item.Name, destPath, false)
 		i.Tracker.SetSourceHash(item.FullName(), hash)
+		i.Tracker.SetFiles(item.FullName(), installed)
+		i.Tracker.SetVariant(item.FullName(), variant)
+		i.Tracker.SetLinked(item.FullName(), i.Link)
 	}
 
-	if isUpdate {
-		return installResultUpdated, nil
+	switch {
+	case merge == localMerged:
+		return installResultLocalMerged, "", nil
+	case merge == localConflict:
+		return installResultConflict, "", nil
+	case isUpdate:
+		return installResultUpdated, "", nil
 	}
-	return installResultInstalled, nil
+	return installResultInstalled, "", nil
+}
+
+// BaseDir holds, next to the tracker, a copy of each installed file as the
+// registry last wrote it: the base of the three-way merge that keeps local
+// changes when the item is updated.
+const BaseDir = ".regis3-base"
+
+// basePath returns the path of an item's base copy.
+func (i *Installer) basePath(itemType, name string) string {
+	return filepath.Join(filepath.Dir(i.Tracker.Path), BaseDir, itemType, name)
 }
 
-// writeFile writes content to a file, creating directories as needed.
-func (i *Installer) writeFile(path, content string) error {
-	// Create directory if needed
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// localMerge is what an update did with local changes to the installed file.
+type localMerge int
+
+const (
+	localNone     localMerge = iota // the file wasn't changed locally
+	localMerged                     // the changes were merged with the update
+	localConflict                   // the changes conflict with the update
+	localKept                       // the file was kept, with nothing to merge against
+)
+
+// mergeLocalChanges returns the content to write for item, whose file
+// destPath may have been edited since it was installed. The edits are
+// merged three-way with the new content, against the base copy of what was
+// installed; without a base copy the file is kept. Forced installs
+// overwrite local changes.
+func (i *Installer) mergeLocalChanges(item *registry.Item, destPath, fullPath, content string) (string, localMerge, error) {
+	installed := i.Tracker.GetInstalled(item.FullName())
+	if i.Force || installed == nil || len(installed.Files) == 0 {
+		return content, localNone, nil
+	}
+	recorded := installed.Files[0]
+	if recorded.SHA256 == "" || recorded.Link != "" || recorded.Path != filepath.ToSlash(destPath) {
+		return content, localNone, nil
 	}
 
-	// Write file
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	data, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) {
+		return content, localNone, nil
+	}
+	if err != nil {
+		return "", localNone, fmt.Errorf("failed to read installed file: %w", err)
+	}
+	local := string(data)
+	if hashContent(local) == recorded.SHA256 || local == content {
+		return content, localNone, nil
 	}
 
-	return nil
+	base, err := os.ReadFile(i.basePath(item.Type, item.Name))
+	if err != nil {
+		return local, localKept, nil
+	}
+	merged, conflicts := Merge3(string(base), local, content)
+	if conflicts > 0 {
+		return merged, localConflict, nil
+	}
+	return merged, localMerged, nil
 }
 
-// copyAdditionalFiles copies additional files specified in the item.
-func (i *Installer) copyAdditionalFiles(item *registry.Item, destDir string) error {
-	for _, file := range item.Files {
-		srcPath := filepath.Join(i.RegistryPath, item.SourceDir, file)
-		destPath := filepath.Join(destDir, file)
+// writeFile stages content to be written to path when the install commits.
+func (i *Installer) writeFile(path, content string, perm os.FileMode) error {
+	return i.tx.WriteFile(path, []byte(content), perm)
+}
 
-		// Read source file
-		content, err := os.ReadFile(srcPath)
+// render returns the item's content as installed for the target and the
+// additional files to copy alongside it, with directory entries expanded.
+// Targets with InlineFiles get small text files inlined into the content
+// instead.
+func (i *Installer) render(item *registry.Item) (string, []string, error) {
+	content, err := i.Transformer.Transform(item)
+	if err != nil || len(item.Files) == 0 || item.Type == "stack" || i.Target.IsMergeType(item.Type) {
+		return content, nil, err
+	}
+	files, err := item.ExpandFiles(i.sourceRoot(item))
+	if err != nil {
+		return "", nil, err
+	}
+	if !i.Target.InlineFiles {
+		return content, files, nil
+	}
+	return i.Transformer.InlineFiles(item, content, i.sourceRoot(item), files)
+}
+
+// copyAdditionalFiles copies the item's additional files, relative to its
+// source directory, into destDir (relative to the project) and returns the
+// files installed.
+func (i *Installer) copyAdditionalFiles(item *registry.Item, expanded []string, destDir string) ([]InstalledFile, error) {
+	var files []InstalledFile
+	for _, file := range expanded {
+		srcPath, err := pathutil.Join(i.sourceRoot(item), item.SourceDir, file)
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := pathutil.Join(destDir, file)
+		if err != nil {
+			return nil, err
+		}
+		destPath, err := pathutil.Join(i.ProjectDir, relPath)
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
+			return nil, err
 		}
+		installed := InstalledFile{Path: filepath.ToSlash(relPath)}
 
-		// Create destination directory
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return fmt.Errorf("failed to create directory for %s: %w", file, err)
+		// Link mode links the file to its source
+		if i.Link {
+			if installed.Link, err = filepath.Abs(srcPath); err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(srcPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			if err := i.tx.Symlink(destPath, installed.Link); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			installed.SHA256 = hashContent(string(content))
+			files = append(files, installed)
+			continue
+		}
+
+		// Skip assets that are already installed and unchanged; links
+		// are replaced with copies
+		if want, ok := item.Checksum(file); ok && !i.Force && !isSymlink(destPath) {
+			if have, err := registry.ChecksumFile(destPath); err == nil && have.Matches(want) {
+				installed.SHA256 = want.SHA256
+				files = append(files, installed)
+				continue
+			}
+		}
+
+		// Read source file, keeping its permission bits
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		content, err := os.ReadFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
 		}
 
-		// Write destination file
-		if err := os.WriteFile(destPath, content, 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", file, err)
+		// Stage destination file
+		if err := i.tx.WriteFile(destPath, content, info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file, err)
 		}
+		installed.SHA256 = hashContent(string(content))
+		files = append(files, installed)
 	}
 
+	return files, nil
+}
+
+// removeStaleFiles stages the removal of files recorded for an installed
+// item that the new version no longer installs.
+func (i *Installer) removeStaleFiles(id string, files []InstalledFile) error {
+	installed := i.Tracker.GetInstalled(id)
+	if installed == nil {
+		return nil
+	}
+	keep := make(map[string]bool, len(files))
+	for _, f := range files {
+		keep[f.Path] = true
+	}
+	for _, path := range installed.Paths() {
+		if keep[filepath.ToSlash(path)] {
+			continue
+		}
+		// The tracker file is editable, so don't trust its paths
+		fullPath, err := pathutil.Join(i.ProjectDir, path)
+		if err != nil {
+			return err
+		}
+		i.tx.Remove(fullPath)
+	}
 	return nil
 }
 
 // writeMergeFile writes merged content to CLAUDE.md.
 func (i *Installer) writeMergeFile(mergeContent *MergeContent) error {
-	mergeFilePath := filepath.Join(i.ProjectDir, i.Target.MergeFile)
+	mergeFilePath, err := pathutil.Join(i.ProjectDir, i.Target.MergeFile)
+	if err != nil {
+		return err
+	}
 
 	// Read existing file if it exists
 	existing := ""
@@ -290,12 +950,138 @@ func (i *Installer) writeMergeFile(mergeContent *MergeContent) error {
 		return nil
 	}
 
-	return os.WriteFile(mergeFilePath, []byte(finalContent), 0644)
+	return i.writeFile(mergeFilePath, finalContent, 0644)
+}
+
+// markDependency records whether an item was installed only as a
+// dependency: as locked during Sync, by whether it was requested when it is
+// new, and not any more when it is requested with Explicit.
+func (i *Installer) markDependency(id string, requested, wasInstalled bool) {
+	switch {
+	case i.locked != nil:
+		if locked, ok := i.locked[id]; ok {
+			i.Tracker.SetDependency(id, locked.Dependency)
+		}
+	case !wasInstalled:
+		i.Tracker.SetDependency(id, !requested)
+	case requested && i.Explicit:
+		i.Tracker.SetDependency(id, false)
+	}
+}
+
+// Unneeded returns the items installed only as dependencies that no
+// explicitly installed item depends on any more, directly or transitively,
+// sorted. Dependencies come from the registry and from the lockfile, which
+// records the one_of alternatives chosen.
+func (i *Installer) Unneeded(manifest *registry.Manifest) ([]string, error) {
+	deps, err := i.dependencies(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	needed := make(map[string]bool)
+	var queue []string
+	for _, id := range i.Tracker.ListInstalled() {
+		if !i.Tracker.GetInstalled(id).Dependency {
+			needed[id] = true
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dep := range deps[id] {
+			if !needed[dep] {
+				needed[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	var unneeded []string
+	for _, id := range i.Tracker.ListInstalled() {
+		if !needed[id] {
+			unneeded = append(unneeded, id)
+		}
+	}
+	sort.Strings(unneeded)
+	return unneeded, nil
+}
+
+// InstalledDependents returns, for each of itemIDs that installed items
+// depend on, directly or transitively, those items, sorted. Items among
+// itemIDs don't count, as they are removed together. Dependencies are those
+// Unneeded follows.
+func (i *Installer) InstalledDependents(itemIDs []string, manifest *registry.Manifest) (map[string][]string, error) {
+	deps, err := i.dependencies(manifest)
+	if err != nil {
+		return nil, err
+	}
+	dependents := make(map[string][]string)
+	for id, list := range deps {
+		for _, dep := range list {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+
+	removing := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		removing[id] = true
+	}
+	result := make(map[string][]string)
+	for _, id := range itemIDs {
+		visited := map[string]bool{id: true}
+		queue := []string{id}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			for _, dependent := range dependents[node] {
+				if visited[dependent] {
+					continue
+				}
+				visited[dependent] = true
+				queue = append(queue, dependent)
+				if !removing[dependent] && i.Tracker.IsInstalled(dependent) {
+					result[id] = append(result[id], dependent)
+				}
+			}
+		}
+		sort.Strings(result[id])
+	}
+	return result, nil
+}
+
+// dependencies returns the direct dependencies of each item: those in the
+// registry's dependency graph and those the lockfile records, which include
+// the one_of alternatives chosen.
+func (i *Installer) dependencies(manifest *registry.Manifest) (map[string][]string, error) {
+	lock, err := LoadLock(i.ProjectDir)
+	if err != nil && !errors.Is(err, ErrNoLock) {
+		return nil, err
+	}
+	graph := resolver.NewResolverWithOptions(i.Overrides.Apply(manifest), i.ResolverOptions).Graph()
+
+	deps := make(map[string][]string)
+	for _, id := range graph.Nodes() {
+		deps[id] = graph.Dependencies(id)
+	}
+	for id, locked := range lock.Targets[i.Target.Name] {
+		deps[id] = append(append([]string{}, deps[id]...), locked.Deps...)
+	}
+	return deps, nil
 }
 
-// Uninstall removes installed items.
-func (i *Installer) Uninstall(itemIDs []string) (*UninstallResult, error) {
+// Uninstall removes installed items. The manifest is optional: with it,
+// merged items are removed by regenerating the managed section of the merge
+// file from the merged items that remain, and the additional files the
+// registry lists for an item are removed even if the tracker predates them.
+// Without it, merged items are skipped.
+func (i *Installer) Uninstall(itemIDs []string, manifest *registry.Manifest) (*UninstallResult, error) {
 	result := &UninstallResult{}
+	unmerge := make(map[string]bool)
+	if manifest != nil {
+		manifest = i.Overrides.Apply(manifest)
+	}
 
 	for _, id := range itemIDs {
 		installed := i.Tracker.GetInstalled(id)
@@ -304,54 +1090,260 @@ func (i *Installer) Uninstall(itemIDs []string) (*UninstallResult, error) {
 			continue
 		}
 
-		// Skip merge types for now (would need to regenerate CLAUDE.md)
+		// Merged items are removed together once the others are done
 		if installed.Merged {
-			result.Skipped = append(result.Skipped, id)
+			if manifest == nil {
+				result.Skipped = append(result.Skipped, id)
+			} else {
+				unmerge[id] = true
+			}
 			continue
 		}
 
-		// Delete the file if it exists and has a path
-		if installed.InstalledPath != "" {
-			fullPath := filepath.Join(i.ProjectDir, installed.InstalledPath)
-			if !i.DryRun {
-				// Delete file
-				if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
-					result.Errors = append(result.Errors, InstallError{
-						ItemID:  id,
-						Message: err.Error(),
-						Err:     err,
-					})
-					continue
-				}
-
-				// Try to remove empty parent directory
-				dir := filepath.Dir(fullPath)
-				os.Remove(dir) // Ignore error if not empty
-			}
+		// Delete every file recorded for the item
+		removed, err := i.removeFiles(i.uninstallPaths(id, installed, manifest))
+		result.Paths = append(result.Paths, removed...)
+		if err != nil {
+			result.Errors = append(result.Errors, InstallError{
+				ItemID:  id,
+				Message: err.Error(),
+				Err:     err,
+			})
+			continue
 		}
 
 		if !i.DryRun {
+			// The base copy is only needed to merge updates
+			os.Remove(i.basePath(installed.Type, installed.Name))
 			i.Tracker.MarkUninstalled(id)
 		}
 		result.Uninstalled = append(result.Uninstalled, id)
 	}
 
-	// Save tracker
+	if len(unmerge) > 0 {
+		i.unmergeItems(itemIDs, unmerge, manifest, result)
+	}
+
+	// Save tracker and lockfile
 	if !i.DryRun {
 		if err := i.Tracker.Save(); err != nil {
 			return result, fmt.Errorf("failed to save tracker: %w", err)
 		}
+		if err := i.saveLock(); err != nil {
+			return result, err
+		}
 	}
 
 	return result, nil
 }
 
+// uninstallPaths returns the files to delete for an installed item: those
+// recorded in the tracker plus, when the manifest knows the item, its
+// installed path and additional files.
+func (i *Installer) uninstallPaths(id string, installed *InstalledItem, manifest *registry.Manifest) []string {
+	paths := installed.Paths()
+	if manifest == nil || installed.InstalledPath == "" {
+		return paths
+	}
+	item, ok := manifest.Items[id]
+	if !ok {
+		return paths
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		seen[filepath.ToSlash(path)] = true
+	}
+	add := func(path string) {
+		path = filepath.ToSlash(path)
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	add(installed.InstalledPath)
+	destDir := filepath.Dir(installed.InstalledPath)
+	for _, file := range item.InstallFiles() {
+		if path, err := pathutil.Join(destDir, file); err == nil {
+			add(path)
+		}
+	}
+	return paths
+}
+
+// unmergeItems removes merged items by regenerating the merge file from the
+// merged items that stay installed. The items are only marked uninstalled if
+// the merge file could be rewritten.
+func (i *Installer) unmergeItems(itemIDs []string, unmerge map[string]bool, manifest *registry.Manifest, result *UninstallResult) {
+	err := i.rewriteMergeFile(manifest, unmerge)
+	for _, id := range itemIDs {
+		if !unmerge[id] {
+			continue
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, InstallError{
+				ItemID:  id,
+				Message: err.Error(),
+				Err:     err,
+			})
+			continue
+		}
+		if !i.DryRun {
+			i.Tracker.MarkUninstalled(id)
+		}
+		result.Uninstalled = append(result.Uninstalled, id)
+		delete(unmerge, id) // Listed twice
+	}
+}
+
+// rewriteMergeFile regenerates the managed section of the merge file from
+// the installed merged items, removing the blocks of those in exclude. The
+// section is removed once no merged items remain, and the file with it if
+// nothing else is left.
+func (i *Installer) rewriteMergeFile(manifest *registry.Manifest, exclude map[string]bool) error {
+	mergeContent := NewMergeContent()
+	for _, id := range i.Tracker.ListInstalled() {
+		installed := i.Tracker.GetInstalled(id)
+		if !installed.Merged || exclude[id] {
+			continue
+		}
+		item, ok := manifest.Items[id]
+		if !ok {
+			return fmt.Errorf("cannot regenerate %s: %s is not in the registry", i.Target.MergeFile, id)
+		}
+		if err := i.loadContent(item); err != nil {
+			return fmt.Errorf("cannot regenerate %s: %w", i.Target.MergeFile, err)
+		}
+		content, err := i.Transformer.Transform(item)
+		if err != nil {
+			return fmt.Errorf("cannot regenerate %s: %w", i.Target.MergeFile, err)
+		}
+		mergeContent.Add(item, content)
+	}
+
+	mergeFilePath, err := pathutil.Join(i.ProjectDir, i.Target.MergeFile)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(mergeFilePath)
+	if os.IsNotExist(err) {
+		if !mergeContent.HasContent() {
+			return nil
+		}
+	} else if err != nil {
+		return err
+	}
+
+	var finalContent string
+	if mergeContent.HasContent() {
+		var removed []string
+		for id := range exclude {
+			removed = append(removed, id)
+		}
+		finalContent = UpdateExistingFile(string(data), mergeContent.Generate(), removed...)
+	} else {
+		finalContent = RemoveManagedContent(string(data))
+	}
+
+	if i.DryRun {
+		return nil
+	}
+	if strings.TrimSpace(finalContent) == "" {
+		if err := os.Remove(mergeFilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(mergeFilePath, []byte(finalContent), 0644)
+}
+
+// removeFiles deletes installed files and the directories they leave empty,
+// and returns the project-relative paths removed. A dry run deletes nothing
+// and returns the paths that would be removed.
+func (i *Installer) removeFiles(paths []string) ([]string, error) {
+	root, err := filepath.Abs(i.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	gone := make(map[string]bool)
+	for _, path := range paths {
+		// The tracker file is editable, so don't trust its paths
+		fullPath, err := pathutil.Join(root, path)
+		if err != nil {
+			return removed, err
+		}
+		if _, err := os.Lstat(fullPath); os.IsNotExist(err) {
+			continue
+		}
+		if !i.DryRun {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+		}
+		gone[fullPath] = true
+		removed = append(removed, filepath.ToSlash(path))
+	}
+
+	// Directories are checked once all files are gone, so a dry run sees
+	// the same empty directories a real run leaves behind
+	files := removed
+	for _, path := range files {
+		dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(path)))
+		removed = append(removed, removeEmptyParents(root, dir, i.DryRun, gone)...)
+	}
+	return removed, nil
+}
+
+// removeEmptyParents removes empty directories from dir up to root, stopping
+// at the first directory that isn't empty, and returns their paths relative
+// to root. Entries in gone count as removed already.
+func removeEmptyParents(root, dir string, dryRun bool, gone map[string]bool) []string {
+	var removed []string
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if gone[dir] || !isEmptyDir(dir, gone) {
+			break
+		}
+		if !dryRun {
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+		}
+		gone[dir] = true
+		if rel, err := filepath.Rel(root, dir); err == nil {
+			removed = append(removed, filepath.ToSlash(rel)+"/")
+		}
+		dir = filepath.Dir(dir)
+	}
+	return removed
+}
+
+// isEmptyDir reports whether dir has no entries other than those in gone.
+func isEmptyDir(dir string, gone map[string]bool) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !gone[filepath.Join(dir, entry.Name())] {
+			return false
+		}
+	}
+	return true
+}
+
 // UninstallResult contains the result of an uninstall operation.
 type UninstallResult struct {
 	Uninstalled []string
 	Skipped     []string
 	NotFound    []string
 	Errors      []InstallError
+
+	// Paths are the project-relative files and directories removed, or
+	// that would be removed in a dry run. Directories end in a slash.
+	Paths []string
 }
 
 // Status returns the installation status for items.
@@ -359,12 +1351,14 @@ func (i *Installer) Status(manifest *registry.Manifest) *StatusResult {
 	result := &StatusResult{
 		Items: make(map[string]*ItemStatus),
 	}
+	manifest = i.Overrides.Apply(manifest)
 
 	for id, item := range manifest.Items {
 		status := &ItemStatus{
-			ID:   id,
-			Type: item.Type,
-			Name: item.Name,
+			ID:    id,
+			Type:  item.Type,
+			Name:  item.Name,
+			Local: i.Overrides.Has(item),
 		}
 
 		installed := i.Tracker.GetInstalled(id)
@@ -374,19 +1368,157 @@ func (i *Installer) Status(manifest *registry.Manifest) *StatusResult {
 			status.UpdatedAt = installed.UpdatedAt
 			status.Path = installed.InstalledPath
 			status.Merged = installed.Merged
+			status.Pinned = installed.Pinned
+			status.Variant = installed.Variant
+			status.Linked = installed.Linked
 
 			// Check if needs update
-			content, _ := i.Transformer.Transform(item)
-			hash := hashContent(content)
+			i.loadContent(item)
+			if selected, _, err := i.withVariant(item); err == nil {
+				item = selected
+			}
+			content, _, _ := i.render(item)
+			hash := hashItem(item, content)
 			status.NeedsUpdate = installed.SourceHash != hash
+			status.Drift = i.drift(installed, content, status.NeedsUpdate)
 		}
 
 		result.Items[id] = status
 	}
 
+	// Installed items that are no longer in the manifest
+	for _, id := range i.Tracker.ListInstalled() {
+		if _, ok := result.Items[id]; ok {
+			continue
+		}
+		installed := i.Tracker.GetInstalled(id)
+		_, removed := manifest.GetTombstone(id)
+		result.Items[id] = &ItemStatus{
+			ID:          id,
+			Type:        installed.Type,
+			Name:        installed.Name,
+			Installed:   true,
+			InstalledAt: installed.InstalledAt,
+			UpdatedAt:   installed.UpdatedAt,
+			Path:        installed.InstalledPath,
+			Merged:      installed.Merged,
+			Pinned:      installed.Pinned,
+			Variant:     installed.Variant,
+			Linked:      installed.Linked,
+			Removed:     removed,
+			Drift:       i.drift(installed, "", true),
+		}
+	}
+
 	return result
 }
 
+// Outdated returns the installed items whose registry content changed,
+// sorted: those an update would install, and pinned items it would skip.
+// Items no longer in the manifest are not included.
+func (i *Installer) Outdated(manifest *registry.Manifest) (outdated, pinned []string) {
+	for id, status := range i.Status(manifest).Items {
+		if !status.Installed || !status.NeedsUpdate || status.Removed {
+			continue
+		}
+		if _, ok := manifest.Items[id]; !ok {
+			continue
+		}
+		if status.Pinned {
+			pinned = append(pinned, id)
+		} else {
+			outdated = append(outdated, id)
+		}
+	}
+	sort.Strings(outdated)
+	sort.Strings(pinned)
+	return outdated, pinned
+}
+
+// Drift states reported in ItemStatus.Drift.
+const (
+	// DriftMissing means the installed file no longer exists.
+	DriftMissing = "missing"
+
+	// DriftModified means the installed file was edited in the project.
+	DriftModified = "modified"
+)
+
+// drift compares an installed item's files with what regis3 wrote. Items
+// with recorded files are checked against the recorded hashes; older
+// records compare the installed file with the content regis3 writes for it,
+// and when an update is pending that content has changed too, so only a
+// missing file is reported. Merged items are checked against their block
+// in the merge file. Stacks have no file of their own and never
+// drift.
+func (i *Installer) drift(installed *InstalledItem, content string, needsUpdate bool) string {
+	if installed.Merged {
+		return i.mergeDrift(installed.ID, content, needsUpdate)
+	}
+	files := installed.Files
+	if len(files) == 0 {
+		if installed.InstalledPath == "" {
+			return ""
+		}
+		files = []InstalledFile{{Path: installed.InstalledPath}}
+		if !needsUpdate {
+			files[0].SHA256 = hashContent(content)
+		}
+	}
+
+	drift := ""
+	for _, f := range files {
+		path, err := pathutil.Join(i.ProjectDir, f.Path)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return DriftMissing
+		}
+		if f.Link != "" {
+			if linkDrift(path, f) != "" {
+				drift = DriftModified
+			}
+			continue
+		}
+		if err == nil && f.SHA256 != "" && hashContent(string(data)) != f.SHA256 {
+			drift = DriftModified
+		}
+	}
+	return drift
+}
+
+// mergeDrift reports a merged item whose merge file or block is gone as
+// missing, and one whose content its block no longer contains as modified.
+// Merge files written before items had their own blocks are checked against
+// the managed section. With an update pending the content has changed, so
+// only a missing block is reported.
+func (i *Installer) mergeDrift(id, content string, needsUpdate bool) string {
+	path, err := pathutil.Join(i.ProjectDir, i.Target.MergeFile)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DriftMissing
+	}
+	if err != nil {
+		return ""
+	}
+	managed, ok := ExtractItemContent(string(data), id)
+	if !ok {
+		managed = legacyManagedContent(string(data))
+	}
+	if managed == "" {
+		return DriftMissing
+	}
+	if !needsUpdate && !strings.Contains(managed, strings.TrimSpace(content)) {
+		return DriftModified
+	}
+	return ""
+}
+
 // StatusResult contains installation status for items.
 type StatusResult struct {
 	Items map[string]*ItemStatus
@@ -398,11 +1530,17 @@ type ItemStatus struct {
 	Type        string
 	Name        string
 	Installed   bool
-	InstalledAt interface{}
-	UpdatedAt   interface{}
+	InstalledAt time.Time
+	UpdatedAt   time.Time
 	Path        string
 	Merged      bool
 	NeedsUpdate bool
+	Pinned      bool   // item is kept at its installed content
+	Variant     string // variant installed, empty for the item's own body
+	Linked      bool   // item was installed in link mode
+	Local       bool   // item comes from a project-local override
+	Removed     bool   // item was deleted from the registry
+	Drift       string // DriftMissing or DriftModified, empty if unchanged
 }
 
 // hashContent returns a SHA256 hash of content.
@@ -410,3 +1548,17 @@ func hashContent(content string) string {
 	h := sha256.Sum256([]byte(content))
 	return hex.EncodeToString(h[:])
 }
+
+// hashItem returns a hash of the installed content and, when known, the
+// checksums of the item's additional files, so changed assets trigger updates.
+func hashItem(item *registry.Item, content string) string {
+	if len(item.Checksums) == 0 {
+		return hashContent(content)
+	}
+	var b strings.Builder
+	b.WriteString(content)
+	for _, c := range item.Checksums {
+		fmt.Fprintf(&b, "\x00%s:%s", c.Path, c.SHA256)
+	}
+	return hashContent(b.String())
+}
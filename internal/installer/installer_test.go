@@ -3,8 +3,10 @@ package installer
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"github.com/okto-digital/regis3/internal/registry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,6 +33,106 @@ func TestDefaultClaudeTarget(t *testing.T) {
 	assert.Equal(t, ".claude/commands/deploy.md", path)
 }
 
+func TestDefaultCodexTarget(t *testing.T) {
+	target := DefaultCodexTarget()
+
+	assert.Equal(t, "codex", target.Name)
+	assert.Equal(t, "AGENTS.md", target.MergeFile)
+	assert.True(t, target.IsMergeType("ruleset"))
+
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "ruleset", Name: "style", Desc: "Style rules", Order: 10},
+		Content:    "# Style",
+		Source:     "rulesets/style.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "command", Name: "deploy", Desc: "Deploy"},
+		Content:    "Deploy the app.",
+		Source:     "commands/deploy.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing", Desc: "Testing practices"},
+		Content:    "# Testing",
+		Source:     "skills/testing.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, target)
+	require.NoError(t, err)
+	result, err := installer.Install(manifest, []string{"ruleset:style", "command:deploy", "skill:testing"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.Equal(t, []string{"ruleset:style"}, result.MergedItems)
+
+	agents, err := os.ReadFile(filepath.Join(projectDir, "AGENTS.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(agents), "# Style")
+	assert.FileExists(t, filepath.Join(projectDir, ".codex", "prompts", "deploy.md"))
+
+	skill, err := os.ReadFile(filepath.Join(projectDir, ".codex", "skills", "testing", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nname: testing\ndescription: Testing practices\n---\n\n# Testing", string(skill))
+	assert.FileExists(t, filepath.Join(projectDir, ".codex", TrackerFile))
+}
+
+func TestDefaultCursorTarget(t *testing.T) {
+	target := DefaultCursorTarget()
+
+	assert.Equal(t, "cursor", target.Name)
+	assert.Equal(t, ".cursor", target.BaseDir)
+	assert.Empty(t, target.MergeFile)
+	assert.True(t, target.InlineFiles)
+
+	for _, itemType := range []string{"skill", "ruleset", "philosophy"} {
+		path, err := target.GetPath(itemType, "testing")
+		require.NoError(t, err)
+		assert.Equal(t, ".cursor/rules/testing.mdc", path)
+	}
+
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing", Desc: "Testing practices"},
+		Content:    "---\nregis3:\n  type: skill\n---\n# Testing",
+		Source:     "skills/testing.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "ruleset", Name: "style", Desc: "Style rules", Order: 10},
+		Content:    "# Style",
+		Source:     "rulesets/style.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, target)
+	require.NoError(t, err)
+	result, err := installer.Install(manifest, []string{"skill:testing", "ruleset:style"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.ElementsMatch(t, []string{"skill:testing", "ruleset:style"}, result.Installed)
+	assert.Empty(t, result.MergedItems)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, ".cursor", "rules", "testing.mdc"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndescription: Testing practices\nalwaysApply: false\n---\n\n# Testing", string(content))
+
+	content, err = os.ReadFile(filepath.Join(projectDir, ".cursor", "rules", "style.mdc"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndescription: Style rules\nalwaysApply: true\n---\n\n# Style", string(content))
+	assert.FileExists(t, filepath.Join(projectDir, ".cursor", TrackerFile))
+}
+
+func TestBuiltinTarget(t *testing.T) {
+	for _, name := range []string{"claude", "codex", "cursor"} {
+		target, ok := BuiltinTarget(name)
+		require.True(t, ok, name)
+		assert.Equal(t, name, target.Name)
+	}
+	_, ok := BuiltinTarget("vim")
+	assert.False(t, ok)
+}
+
 func TestTarget_GetPath(t *testing.T) {
 	target := DefaultClaudeTarget()
 
@@ -58,6 +160,22 @@ func TestTarget_GetPath(t *testing.T) {
 	}
 }
 
+func TestTarget_GetPath_RejectsTraversal(t *testing.T) {
+	target := DefaultClaudeTarget()
+
+	for _, name := range []string{"../../evil", "a/b", "..", ""} {
+		t.Run(name, func(t *testing.T) {
+			_, err := target.GetPath("skill", name)
+			assert.ErrorIs(t, err, pathutil.ErrUnsafePath)
+		})
+	}
+
+	// Paths from target definitions are checked as well
+	target.Paths["doc"] = PathConfig{Dir: "../../outside", Pattern: "{name}.md"}
+	_, err := target.GetPath("doc", "readme")
+	assert.ErrorIs(t, err, pathutil.ErrUnsafePath)
+}
+
 func TestTarget_IsMergeType(t *testing.T) {
 	target := DefaultClaudeTarget()
 
@@ -66,6 +184,9 @@ func TestTarget_IsMergeType(t *testing.T) {
 	assert.True(t, target.IsMergeType("ruleset"))
 	assert.False(t, target.IsMergeType("skill"))
 	assert.False(t, target.IsMergeType("subagent"))
+
+	// Targets that give the merge types a path install them as files
+	assert.False(t, DefaultCursorTarget().IsMergeType("ruleset"))
 }
 
 func TestStripFrontmatter(t *testing.T) {
@@ -189,36 +310,189 @@ func TestMergeContent(t *testing.T) {
 	assert.Contains(t, result, "Project description")
 }
 
-func TestUpdateExistingFile(t *testing.T) {
-	t.Run("empty existing", func(t *testing.T) {
-		result := UpdateExistingFile("", "New content")
-		assert.Contains(t, result, "<!-- regis3:start -->")
-		assert.Contains(t, result, "New content")
-		assert.Contains(t, result, "<!-- regis3:end -->")
+func TestMergeContent_EqualOrder(t *testing.T) {
+	generate := func(names ...string) string {
+		mc := NewMergeContent()
+		for _, name := range names {
+			mc.Add(&registry.Item{
+				Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Order: 10},
+			}, "Rules for "+name+".")
+		}
+		return mc.Generate()
+	}
+
+	result := generate("kiss", "clean-code")
+	assert.Equal(t, generate("clean-code", "kiss"), result)
+	assert.Less(t, strings.Index(result, "clean-code"), strings.Index(result, "kiss"))
+}
+
+func TestRender(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "skills", "testing.md"), []byte("---\nregis3:\n  type: skill\n  name: testing\n---\n# Testing\n\nWrite tests first.\n"), 0644))
+
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing"}, Source: "skills/testing.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "kiss", Order: 20}, Content: "Keep it simple."},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "base"}, Content: "Base stack."},
+		{Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "clean-code", Order: 10}, Content: "Clean code."},
+		{Regis3Meta: registry.Regis3Meta{Type: "project", Name: "app"}, Content: "The app."},
+	}
+
+	got, err := Render(items, root, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `<!-- Rendered by regis3 from 4 items; edit the registry, not this file -->
+
+<!-- regis3:item project:app -->
+The app.
+<!-- regis3:end project:app -->
+
+<!-- regis3:item philosophy:clean-code -->
+Clean code.
+<!-- regis3:end philosophy:clean-code -->
+
+<!-- regis3:item philosophy:kiss -->
+Keep it simple.
+<!-- regis3:end philosophy:kiss -->
+
+<!-- regis3:item skill:testing -->
+# Testing
+
+Write tests first.
+<!-- regis3:end skill:testing -->
+`, got)
+
+	t.Run("target transforms", func(t *testing.T) {
+		target := &Target{Name: "wrapped", Transforms: map[string]TransformConfig{
+			"skill": {WrapWith: "<skill name=\"{name}\">\n{content}</skill>"},
+		}}
+		got, err := Render(items[:1], root, target)
+		require.NoError(t, err)
+		assert.Contains(t, got, "<!-- regis3:item skill:testing -->\n<skill name=\"testing\">\n# Testing\n\nWrite tests first.\n</skill>\n<!-- regis3:end skill:testing -->")
 	})
 
-	t.Run("with existing content", func(t *testing.T) {
-		existing := "# My Project\n\nUser content here."
-		result := UpdateExistingFile(existing, "Managed content")
-		assert.Contains(t, result, "# My Project")
-		assert.Contains(t, result, "User content here")
-		assert.Contains(t, result, "Managed content")
+	t.Run("missing source", func(t *testing.T) {
+		_, err := Render([]*registry.Item{{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "gone"}, Source: "skills/gone.md"}}, root, nil)
+		assert.ErrorContains(t, err, "skill:gone")
 	})
+}
+
+func TestUpdateExistingFile(t *testing.T) {
+	block := func(id, content string) string {
+		return "<!-- regis3:item " + id + " -->\n" + content + "\n<!-- regis3:end " + id + " -->"
+	}
+	generated := func(blocks ...string) string {
+		return strings.Join(blocks, "\n\n")
+	}
+	clean := block("philosophy:clean", "Clean code.")
+	kiss := block("philosophy:kiss", "Keep it simple.")
+	tests := []struct {
+		name     string
+		existing string
+		content  string
+		remove   []string
+		expected string
+	}{
+		{
+			name:     "empty existing",
+			content:  generated(clean),
+			expected: clean + "\n",
+		},
+		{
+			name:     "appended to user content",
+			existing: "# My Project\n\nUser content here.\n",
+			content:  generated(clean),
+			expected: "# My Project\n\nUser content here.\n\n" + clean + "\n",
+		},
+		{
+			name:     "legacy managed section is migrated",
+			existing: "# Header\n\n<!-- regis3:start -->\nOld managed content\n<!-- regis3:end -->\n\n# Footer",
+			content:  generated(clean, kiss),
+			expected: "# Header\n\n" + clean + "\n\n" + kiss + "\n\n# Footer\n",
+		},
+		{
+			name:     "item updated in place",
+			existing: "# Header\n\n" + block("philosophy:clean", "Old.") + "\n\nMy note.\n\n" + kiss + "\n",
+			content:  generated(clean, kiss),
+			expected: "# Header\n\n" + clean + "\n\nMy note.\n\n" + kiss + "\n",
+		},
+		{
+			name:     "item removed",
+			existing: clean + "\n\nMy note.\n\n" + kiss + "\n\n# Footer\n",
+			content:  generated(kiss),
+			remove:   []string{"philosophy:clean"},
+			expected: "My note.\n\n" + kiss + "\n\n# Footer\n",
+		},
+		{
+			name:     "blocks of other items are kept",
+			existing: clean + "\n\nMy note.\n\n" + block("philosophy:kiss", "Old.") + "\n",
+			content:  generated(kiss),
+			expected: clean + "\n\nMy note.\n\n" + kiss + "\n",
+		},
+		{
+			name:     "new item follows the last kept block",
+			existing: clean + "\n\n# Footer\n",
+			content:  generated(kiss),
+			expected: clean + "\n\n" + kiss + "\n\n# Footer\n",
+		},
+		{
+			name:     "item inserted after the one it follows",
+			existing: clean + "\n\nMy note.\n",
+			content:  generated(clean, kiss),
+			expected: clean + "\n\n" + kiss + "\n\nMy note.\n",
+		},
+		{
+			name:     "item inserted before the first",
+			existing: "# Header\n\n" + kiss + "\n",
+			content:  generated(clean, kiss),
+			expected: "# Header\n\n" + clean + "\n\n" + kiss + "\n",
+		},
+		{
+			name:     "changed order fills the existing places",
+			existing: kiss + "\n\nMy note.\n\n" + clean + "\n",
+			content:  generated(clean, kiss),
+			expected: clean + "\n\nMy note.\n\n" + kiss + "\n",
+		},
+		{
+			name:     "unterminated block is user content",
+			existing: "<!-- regis3:item philosophy:gone -->\nMine\n",
+			content:  generated(clean),
+			expected: "<!-- regis3:item philosophy:gone -->\nMine\n\n" + clean + "\n",
+		},
+	}
 
-	t.Run("replace existing managed section", func(t *testing.T) {
-		existing := `# Header
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UpdateExistingFile(tt.existing, tt.content, tt.remove...)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, result, UpdateExistingFile(result, tt.content, tt.remove...), "updating again changes nothing")
+		})
+	}
+}
 
-<!-- regis3:start -->
-Old managed content
-<!-- regis3:end -->
+func TestMergeContent_GenerateBlocks(t *testing.T) {
+	mc := NewMergeContent()
+	mc.Add(&registry.Item{Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "kiss", Order: 20}}, "Keep it simple.\n")
+	mc.Add(&registry.Item{Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "clean", Order: 10}}, "Clean code.")
 
-# Footer`
-		result := UpdateExistingFile(existing, "New managed content")
-		assert.Contains(t, result, "# Header")
-		assert.Contains(t, result, "New managed content")
-		assert.NotContains(t, result, "Old managed content")
-		assert.Contains(t, result, "# Footer")
-	})
+	result := mc.Generate()
+	assert.Equal(t, `<!-- regis3:item philosophy:clean -->
+## Philosophy
+
+Clean code.
+<!-- regis3:end philosophy:clean -->
+
+<!-- regis3:item philosophy:kiss -->
+Keep it simple.
+<!-- regis3:end philosophy:kiss -->`, result)
+
+	file := UpdateExistingFile("# Mine\n", result)
+	assert.Equal(t, result, ExtractManagedContent(file))
+	content, ok := ExtractItemContent(file, "philosophy:kiss")
+	assert.True(t, ok)
+	assert.Equal(t, "Keep it simple.", content)
+	_, ok = ExtractItemContent(file, "philosophy:other")
+	assert.False(t, ok)
 }
 
 func TestExtractManagedContent(t *testing.T) {
@@ -234,6 +508,26 @@ Managed content here
 	assert.Equal(t, "Managed content here", result)
 }
 
+func TestRemoveManagedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"no managed section", "# Notes", "# Notes"},
+		{"only managed section", "<!-- regis3:start -->\nManaged\n<!-- regis3:end -->", ""},
+		{"content before", "# Notes\n\n<!-- regis3:start -->\nManaged\n<!-- regis3:end -->\n", "# Notes"},
+		{"content around", "# Header\n\n<!-- regis3:start -->\nManaged\n<!-- regis3:end -->\n\n# Footer", "# Header\n\n# Footer"},
+		{"item blocks", "# Header\n\n<!-- regis3:item philosophy:kiss -->\nKiss\n<!-- regis3:end philosophy:kiss -->\n\nMy note.\n\n<!-- regis3:item ruleset:go -->\nGo\n<!-- regis3:end ruleset:go -->\n", "# Header\n\nMy note."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RemoveManagedContent(tt.content))
+		})
+	}
+}
+
 func TestSanitizeName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -385,6 +679,68 @@ Content here.`,
 	assert.True(t, installer.Tracker.IsInstalled("skill:test-skill"))
 }
 
+func TestInstaller_InstallReportsDeprecated(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "old", Desc: "Old", Status: "deprecated", ReplacedBy: "skill:new"},
+		Content:    "# Old",
+		Source:     "skills/old.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "legacy", Desc: "Legacy", Status: "deprecated"},
+		Content:    "# Legacy",
+		Source:     "skills/legacy.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "new", Desc: "New"},
+		Content:    "# New",
+		Source:     "skills/new.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	result, err := installer.Install(manifest, []string{"skill:old", "skill:legacy", "skill:new"})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Installed, 3)
+	assert.Equal(t, map[string]string{"skill:old": "skill:new", "skill:legacy": ""}, result.Deprecated)
+
+	// Installing them again skips them without another warning
+	result, err = installer.Install(manifest, []string{"skill:old"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:old"}, result.Skipped)
+	assert.Empty(t, result.Deprecated)
+}
+
+func TestCombineResults(t *testing.T) {
+	results := map[string]*InstallResult{
+		"cursor": {
+			Installed:  []string{"skill:a", "skill:b"},
+			Errors:     []InstallError{{ItemID: "skill:c", Message: "failed to write"}},
+			Deprecated: map[string]string{"skill:b": ""},
+		},
+		"claude": {
+			Installed: []string{"skill:b"},
+			Skipped:   []string{"skill:a"},
+			Choices:   map[string]string{"stack:web": "skill:vitest"},
+		},
+	}
+
+	combined := CombineResults(results)
+	assert.Equal(t, []string{"skill:b", "skill:a"}, combined.Installed, "targets in name order, each item once")
+	assert.Equal(t, []string{"skill:a"}, combined.Skipped)
+	assert.Equal(t, map[string]string{"stack:web": "skill:vitest"}, combined.Choices)
+	assert.Equal(t, map[string]string{"skill:b": ""}, combined.Deprecated)
+	require.Len(t, combined.Errors, 1)
+	assert.Equal(t, "skill:c", combined.Errors[0].ItemID)
+	assert.Equal(t, "cursor: failed to write", combined.Errors[0].Message)
+
+	assert.Empty(t, CombineResults(nil).Installed)
+}
+
 func TestInstaller_InstallWithDependencies(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
 	require.NoError(t, err)
@@ -467,9 +823,9 @@ func TestInstaller_InstallMergeType(t *testing.T) {
 
 	content, err := os.ReadFile(claudemd)
 	require.NoError(t, err)
-	assert.Contains(t, string(content), "regis3:start")
+	assert.Contains(t, string(content), "<!-- regis3:item philosophy:clean-code -->")
 	assert.Contains(t, string(content), "Clean Code")
-	assert.Contains(t, string(content), "regis3:end")
+	assert.Contains(t, string(content), "<!-- regis3:end philosophy:clean-code -->")
 }
 
 func TestInstaller_DryRun(t *testing.T) {
@@ -544,7 +900,7 @@ func TestInstaller_Uninstall(t *testing.T) {
 	assert.FileExists(t, skillPath)
 
 	// Now uninstall
-	result, err := installer.Uninstall([]string{"skill:test"})
+	result, err := installer.Uninstall([]string{"skill:test"}, manifest)
 	require.NoError(t, err)
 
 	assert.Len(t, result.Uninstalled, 1)
@@ -553,35 +909,1144 @@ func TestInstaller_Uninstall(t *testing.T) {
 	assert.False(t, installer.Tracker.IsInstalled("skill:test"))
 }
 
-func TestLoadTarget(t *testing.T) {
-	// Create temp file
-	tmpFile, err := os.CreateTemp("", "target-*.yaml")
+func TestInstaller_InstalledDependents(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, item := range []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "base", Desc: "Base"}, Content: "# Base"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing", Desc: "Testing", Deps: []string{"skill:base"}}, Content: "# Testing"},
+		{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "review", Desc: "Review", Deps: []string{"skill:testing"}}, Content: "# Review"},
+		{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "lint", Desc: "Lint", Deps: []string{"skill:base"}}, Content: "# Lint"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "jest", Desc: "Jest"}, Content: "# Jest"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "vitest", Desc: "Vitest"}, Content: "# Vitest"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "frontend", Desc: "Frontend", OneOf: []string{"skill:jest", "skill:vitest"}}, Content: "# Frontend"},
+	} {
+		item.Source = item.Type + "s/" + item.Name + ".md"
+		manifest.AddItem(item)
+	}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
 	require.NoError(t, err)
-	defer os.Remove(tmpFile.Name())
+	result, err := installer.Install(manifest, []string{"command:review", "stack:frontend"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	require.True(t, installer.Tracker.IsInstalled("skill:jest"))
 
-	content := `
-name: test-target
-description: Test target
-version: "1.0.0"
-base_dir: .test
-merge_file: TEST.md
-paths:
-  skill:
-    dir: skills
-    pattern: "{name}.md"
-`
-	_, err = tmpFile.WriteString(content)
+	tests := []struct {
+		name string
+		ids  []string
+		want map[string][]string
+	}{
+		{
+			name: "transitive dependents that are installed",
+			ids:  []string{"skill:base"},
+			want: map[string][]string{"skill:base": {"command:review", "skill:testing"}},
+		},
+		{
+			name: "items removed together don't count",
+			ids:  []string{"skill:testing", "command:review"},
+			want: map[string][]string{},
+		},
+		{
+			name: "nothing depends on it",
+			ids:  []string{"command:review"},
+			want: map[string][]string{},
+		},
+		{
+			name: "alternative chosen in the lockfile",
+			ids:  []string{"skill:jest"},
+			want: map[string][]string{"skill:jest": {"stack:frontend"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dependents, err := installer.InstalledDependents(tt.ids, manifest)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, dependents)
+		})
+	}
+
+	// Unneeded follows the same dependencies
+	unneeded, err := installer.Unneeded(manifest)
 	require.NoError(t, err)
-	tmpFile.Close()
+	assert.Empty(t, unneeded)
+}
 
-	target, err := LoadTarget(tmpFile.Name())
+func TestInstaller_Unneeded(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, item := range []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "base", Desc: "Base"}, Content: "# Base"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing", Desc: "Testing", Deps: []string{"skill:base"}}, Content: "# Testing"},
+		{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "review", Desc: "Review", Deps: []string{"skill:testing"}}, Content: "# Review"},
+		{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "lint", Desc: "Lint", Deps: []string{"skill:base"}}, Content: "# Lint"},
+	} {
+		item.Source = item.Type + "s/" + item.Name + ".md"
+		manifest.AddItem(item)
+	}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"command:review", "command:lint"})
 	require.NoError(t, err)
 
-	assert.Equal(t, "test-target", target.Name)
-	assert.Equal(t, ".test", target.BaseDir)
-	assert.Equal(t, "TEST.md", target.MergeFile)
+	// Only the requested items are explicit
+	assert.False(t, installer.Tracker.GetInstalled("command:review").Dependency)
+	assert.True(t, installer.Tracker.GetInstalled("skill:testing").Dependency)
+	assert.True(t, installer.Tracker.GetInstalled("skill:base").Dependency)
+	lock, err := LoadLock(projectDir)
+	require.NoError(t, err)
+	assert.True(t, lock.Targets[DefaultClaudeTarget().Name]["skill:base"].Dependency)
 
-	path, err := target.GetPath("skill", "my-skill")
+	unneeded, err := installer.Unneeded(manifest)
 	require.NoError(t, err)
-	assert.Equal(t, ".test/skills/my-skill.md", path)
+	assert.Empty(t, unneeded)
+
+	// skill:base is still needed by command:lint
+	_, err = installer.Uninstall([]string{"command:review"}, manifest)
+	require.NoError(t, err)
+	unneeded, err = installer.Unneeded(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:testing"}, unneeded)
+
+	// Updating a dependency keeps it one; requesting it explicitly doesn't
+	_, err = installer.Install(manifest, []string{"skill:testing"})
+	require.NoError(t, err)
+	assert.True(t, installer.Tracker.GetInstalled("skill:testing").Dependency)
+	installer.Explicit = true
+	_, err = installer.Install(manifest, []string{"skill:testing"})
+	require.NoError(t, err)
+	assert.False(t, installer.Tracker.GetInstalled("skill:testing").Dependency)
+	unneeded, err = installer.Unneeded(manifest)
+	require.NoError(t, err)
+	assert.Empty(t, unneeded)
+}
+
+func TestInstaller_MergeSeparateInstalls(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"a", "b"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Desc: name},
+			Content:    "Rules for " + name,
+			Source:     "philosophies/" + name + ".md",
+		})
+	}
+
+	install := func(id string) string {
+		installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		result, err := installer.Install(manifest, []string{id})
+		require.NoError(t, err)
+		require.Empty(t, result.Errors)
+		assert.Equal(t, []string{id}, result.MergedItems)
+
+		data, err := os.ReadFile(filepath.Join(projectDir, "CLAUDE.md"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	install("philosophy:a")
+	data := install("philosophy:b")
+	assert.Contains(t, data, "Rules for a")
+	assert.Contains(t, data, "Rules for b")
+
+	// Updating one item keeps the other's block
+	item, _ := manifest.GetItem("philosophy:a")
+	item.Content = "New rules for a"
+	data = install("philosophy:a")
+	assert.Contains(t, data, "New rules for a")
+	assert.Contains(t, data, "Rules for b")
+	assert.Equal(t, 1, strings.Count(data, "<!-- regis3:item philosophy:a -->"))
+}
+
+func TestInstaller_UninstallMerged(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"clean-code", "testing"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Desc: name},
+			Content:    "Rules for " + name,
+			Source:     "philosophies/" + name + ".md",
+		})
+	}
+
+	mergePath := filepath.Join(projectDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(mergePath, []byte("# My Project"), 0644))
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"philosophy:clean-code", "philosophy:testing"})
+	require.NoError(t, err)
+
+	t.Run("skipped without a manifest", func(t *testing.T) {
+		result, err := installer.Uninstall([]string{"philosophy:testing"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"philosophy:testing"}, result.Skipped)
+		assert.True(t, installer.Tracker.IsInstalled("philosophy:testing"))
+	})
+
+	t.Run("regenerates the managed section", func(t *testing.T) {
+		// A note between the items' blocks survives
+		data, err := os.ReadFile(mergePath)
+		require.NoError(t, err)
+		edited := strings.Replace(string(data), "<!-- regis3:item philosophy:testing -->", "My note.\n\n<!-- regis3:item philosophy:testing -->", 1)
+		require.NoError(t, os.WriteFile(mergePath, []byte(edited), 0644))
+
+		result, err := installer.Uninstall([]string{"philosophy:testing"}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"philosophy:testing"}, result.Uninstalled)
+		assert.False(t, installer.Tracker.IsInstalled("philosophy:testing"))
+
+		data, err = os.ReadFile(mergePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "# My Project")
+		assert.Contains(t, string(data), "Rules for clean-code")
+		assert.Contains(t, string(data), "My note.")
+		assert.NotContains(t, string(data), "Rules for testing")
+	})
+
+	t.Run("removes the managed section with the last item", func(t *testing.T) {
+		result, err := installer.Uninstall([]string{"philosophy:clean-code"}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"philosophy:clean-code"}, result.Uninstalled)
+
+		data, err := os.ReadFile(mergePath)
+		require.NoError(t, err)
+		assert.Equal(t, "# My Project\n\nMy note.", string(data))
+	})
+
+	t.Run("fails when a remaining item left the registry", func(t *testing.T) {
+		_, err := installer.Install(manifest, []string{"philosophy:clean-code", "philosophy:testing"})
+		require.NoError(t, err)
+
+		partial := registry.NewManifest(registryDir)
+		partial.AddItem(manifest.Items["philosophy:testing"])
+
+		result, err := installer.Uninstall([]string{"philosophy:testing"}, partial)
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0].Message, "philosophy:clean-code")
+		assert.True(t, installer.Tracker.IsInstalled("philosophy:testing"))
+	})
+}
+
+func TestInstaller_UninstallManifestFiles(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"helper.sh"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+	})
+
+	// A tracker written before file tracking only knows the main file
+	skillDir := filepath.Join(projectDir, ".claude", "skills", "tool")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("# Tool"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "helper.sh"), []byte("echo"), 0755))
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	installer.Tracker.MarkInstalled("skill:tool", "skill", "tool", ".claude/skills/tool/SKILL.md", false)
+
+	result, err := installer.Uninstall([]string{"skill:tool"}, manifest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:tool"}, result.Uninstalled)
+	assert.NoDirExists(t, skillDir)
+}
+
+func TestInstaller_FileModes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "skills"), 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "run.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "script", Name: "setup", Desc: "Setup"},
+		Content:    "#!/bin/sh\necho setup\n",
+		Source:     "scripts/setup.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "doc", Name: "private", Desc: "Private", Mode: "0600"},
+		Content:    "# Private",
+		Source:     "docs/private.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"run.sh"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	_, err = installer.Install(manifest, []string{"script:setup", "doc:private", "skill:tool"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		mode os.FileMode
+	}{
+		{filepath.Join(".claude", "scripts", "setup.sh"), 0755},
+		{filepath.Join(".claude", "docs", "private.md"), 0600},
+		{filepath.Join(".claude", "skills", "tool", "SKILL.md"), 0644},
+		{filepath.Join(".claude", "skills", "tool", "run.sh"), 0755},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info, err := os.Stat(filepath.Join(projectDir, tt.path))
+			require.NoError(t, err)
+			assert.Equal(t, tt.mode, info.Mode().Perm())
+		})
+	}
+}
+
+func TestInstaller_VerifiesAdditionalFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	assetPath := filepath.Join(registryDir, "skills", "asset.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(assetPath), 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(assetPath, []byte("v1"), 0644))
+
+	item := &registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"asset.txt"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	}
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(item)
+	manifest.ComputeChecksums()
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	_, err = installer.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+	installedAsset := filepath.Join(projectDir, ".claude", "skills", "tool", "asset.txt")
+	assert.FileExists(t, installedAsset)
+
+	t.Run("unchanged assets are not copied again", func(t *testing.T) {
+		require.NoError(t, os.Chmod(installedAsset, 0600))
+		item.Content = "# Tool v2"
+
+		result, err := installer.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+		assert.Contains(t, result.Updated, "skill:tool")
+
+		info, err := os.Stat(installedAsset)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("modified source files are rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(assetPath, []byte("tampered"), 0644))
+
+		result, err := installer.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0].Message, "file changed since the manifest was built")
+	})
+}
+
+func TestInstaller_RollsBackFailedInstall(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	assetPath := filepath.Join(registryDir, "skills", "asset.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(assetPath), 0755))
+	require.NoError(t, os.WriteFile(assetPath, []byte("v1"), 0644))
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "command", Name: "review", Desc: "Review"},
+		Content:    "# Review",
+		Source:     "commands/review.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "kiss", Desc: "KISS", Order: 1},
+		Content:    "# KISS",
+		Source:     "philosophies/kiss.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"asset.txt"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+	manifest.ComputeChecksums()
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"command:review"})
+	require.NoError(t, err)
+	tracker, err := os.ReadFile(installer.Tracker.Path)
+	require.NoError(t, err)
+	hash := installer.Tracker.GetInstalled("command:review").SourceHash
+
+	// The asset of the last item fails verification after the others
+	// were staged
+	require.NoError(t, os.WriteFile(assetPath, []byte("tampered"), 0644))
+	manifest.Items["command:review"].Content = "# Review v2"
+
+	result, err := installer.Install(manifest, []string{"command:review", "philosophy:kiss", "skill:tool"})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "skill:tool", result.Errors[0].ItemID)
+	assert.True(t, result.RolledBack)
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.MergedItems)
+
+	// Nothing was written, and the tracker is as it was
+	content, err := os.ReadFile(filepath.Join(projectDir, ".claude", "commands", "review.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# Review")
+	assert.NotContains(t, string(content), "v2")
+	assert.NoFileExists(t, filepath.Join(projectDir, "CLAUDE.md"))
+	assert.NoDirExists(t, filepath.Join(projectDir, ".claude", "skills"))
+	saved, err := os.ReadFile(installer.Tracker.Path)
+	require.NoError(t, err)
+	assert.Equal(t, string(tracker), string(saved))
+	assert.False(t, installer.Tracker.IsInstalled("philosophy:kiss"))
+	assert.Equal(t, hash, installer.Tracker.GetInstalled("command:review").SourceHash)
+	entries, err := os.ReadDir(projectDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".regis3-tx-")
+	}
+}
+
+func TestInstaller_CopiesDirectories(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	for path, content := range map[string]string{
+		"skills/reference/api.md":        "# API",
+		"skills/reference/guides/cli.md": "# CLI",
+		"skills/reference/.DS_Store":     "junk",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(registryDir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, path), []byte(content), 0644))
+	}
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"reference/"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+	manifest.ComputeChecksums()
+	assert.Equal(t, []string{"reference/api.md", "reference/guides/cli.md"}, manifest.Items["skill:tool"].InstallFiles())
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	result, err := installer.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	skillDir := filepath.Join(projectDir, ".claude", "skills", "tool")
+	assert.FileExists(t, filepath.Join(skillDir, "reference", "api.md"))
+	assert.FileExists(t, filepath.Join(skillDir, "reference", "guides", "cli.md"))
+	assert.NoFileExists(t, filepath.Join(skillDir, "reference", ".DS_Store"))
+	assert.Len(t, installer.Tracker.GetInstalled("skill:tool").Files, 3)
+
+	t.Run("files added since the build are rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "reference", "new.md"), []byte("# New"), 0644))
+		defer os.Remove(filepath.Join(registryDir, "skills", "reference", "new.md"))
+
+		result, err := installer.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0].Message, "file changed since the manifest was built: reference/new.md")
+	})
+
+	t.Run("uninstall removes the directory", func(t *testing.T) {
+		result, err := installer.Uninstall([]string{"skill:tool"}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:tool"}, result.Uninstalled)
+		assert.NoDirExists(t, skillDir)
+	})
+}
+
+func TestInstaller_InlineFiles(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	for path, content := range map[string]string{
+		"skills/reference/api.md":  "# API\n\n```go\nclient.Get()\n```\n",
+		"skills/scripts/run.sh":    "#!/bin/sh\necho run\n",
+		"skills/assets/logo.png":   "\x89PNG\x00\x01",
+		"skills/reference/big.txt": strings.Repeat("x", MaxInlineSize+1),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(registryDir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, path), []byte(content), 0644))
+	}
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{
+			Type:  "skill",
+			Name:  "tool",
+			Desc:  "Tool",
+			Files: []string{"reference/", "scripts/run.sh", "assets/logo.png"},
+		},
+		Content:   "# Tool",
+		Source:    "skills/tool.md",
+		SourceDir: "skills",
+	})
+	manifest.ComputeChecksums()
+
+	target := DefaultClaudeTarget()
+	target.InlineFiles = true
+	installer, err := NewInstaller(projectDir, registryDir, target)
+	require.NoError(t, err)
+
+	result, err := installer.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+
+	skillDir := filepath.Join(projectDir, ".claude", "skills", "tool")
+	content, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Tool\n\n## Files\n\n"+
+		"### reference/api.md\n\n````md\n# API\n\n```go\nclient.Get()\n```\n````\n\n"+
+		"### scripts/run.sh\n\n```sh\n#!/bin/sh\necho run\n```", string(content))
+
+	// Binary and large files are copied
+	assert.NoFileExists(t, filepath.Join(skillDir, "reference", "api.md"))
+	assert.NoFileExists(t, filepath.Join(skillDir, "scripts", "run.sh"))
+	assert.FileExists(t, filepath.Join(skillDir, "reference", "big.txt"))
+	assert.FileExists(t, filepath.Join(skillDir, "assets", "logo.png"))
+	assert.Len(t, installer.Tracker.GetInstalled("skill:tool").Files, 3)
+	assert.False(t, installer.Status(manifest).Items["skill:tool"].NeedsUpdate)
+}
+
+func TestInstaller_StatusReportsRemovedItems(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	installer, err := NewInstaller(tmpDir, tmpDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	installer.Tracker.MarkInstalled("skill:gone", "skill", "gone", ".claude/skills/gone/SKILL.md", false)
+	installer.Tracker.MarkInstalled("skill:local", "skill", "local", ".claude/skills/local/SKILL.md", false)
+
+	manifest := registry.NewManifest(tmpDir)
+	manifest.Tombstones = []registry.Tombstone{{ID: "skill:gone", Source: "skills/gone.md"}}
+
+	status := installer.Status(manifest)
+
+	require.Contains(t, status.Items, "skill:gone")
+	assert.True(t, status.Items["skill:gone"].Installed)
+	assert.True(t, status.Items["skill:gone"].Removed)
+
+	require.Contains(t, status.Items, "skill:local")
+	assert.False(t, status.Items["skill:local"].Removed)
+}
+
+func TestInstaller_StatusReportsDrift(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"kept", "edited", "deleted", "stale"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "command", Name: name, Desc: "A command"},
+			Content:    "# " + name + "\n",
+			Source:     "commands/" + name + ".md",
+		})
+	}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"command:kept", "command:edited", "command:deleted", "command:stale"})
+	require.NoError(t, err)
+
+	commands := filepath.Join(projectDir, ".claude", "commands")
+	require.NoError(t, os.WriteFile(filepath.Join(commands, "edited.md"), []byte("# mine\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(commands, "deleted.md")))
+	require.NoError(t, os.WriteFile(filepath.Join(commands, "stale.md"), []byte("# mine\n"), 0644))
+	manifest.Items["command:stale"].Content = "# stale, updated\n"
+
+	status := installer.Status(manifest)
+
+	assert.Empty(t, status.Items["command:kept"].Drift)
+	assert.Equal(t, DriftModified, status.Items["command:edited"].Drift)
+	assert.Equal(t, DriftMissing, status.Items["command:deleted"].Drift)
+
+	// Recorded hashes tell local edits apart from pending updates
+	assert.True(t, status.Items["command:stale"].NeedsUpdate)
+	assert.Equal(t, DriftModified, status.Items["command:stale"].Drift)
+
+	// Without recorded files, a pending update hides local edits
+	installer.Tracker.GetInstalled("command:stale").Files = nil
+	status = installer.Status(manifest)
+	assert.Empty(t, status.Items["command:stale"].Drift)
+}
+
+func TestInstaller_UpdateMergesLocalChanges(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"merged", "conflict", "kept"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "command", Name: name, Desc: "A command"},
+			Content:    "# " + name + "\n\nintro\n\nbody\n",
+			Source:     "commands/" + name + ".md",
+		})
+	}
+	ids := []string{"command:merged", "command:conflict", "command:kept"}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, ids)
+	require.NoError(t, err)
+
+	commands := filepath.Join(projectDir, ".claude", "commands")
+	require.NoError(t, os.WriteFile(filepath.Join(commands, "merged.md"), []byte("# merged\n\nmy intro\n\nbody\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(commands, "conflict.md"), []byte("# conflict\n\nintro\n\nmy body\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(commands, "kept.md"), []byte("# kept\n\nmine\n"), 0644))
+	require.NoError(t, os.Remove(installer.basePath("command", "kept")))
+	for _, id := range ids {
+		manifest.Items[id].Content += "\nmore\n"
+	}
+	manifest.Items["command:conflict"].Content = "# conflict\n\nintro\n\nnew body\n"
+
+	result, err := installer.Install(manifest, ids)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"command:merged", "command:conflict"}, result.Updated)
+	assert.Equal(t, []string{"command:merged"}, result.LocalMerged)
+	assert.Equal(t, []string{"command:conflict"}, result.Conflicts)
+	assert.Equal(t, []string{"command:kept"}, result.KeptLocal)
+	assert.Contains(t, result.SkipReasons["command:kept"], "changed locally")
+
+	content, err := os.ReadFile(filepath.Join(commands, "merged.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# merged\n\nmy intro\n\nbody\n\nmore\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(commands, "conflict.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# conflict\n\nintro\n\n<<<<<<< local\nmy body\n=======\nnew body\n>>>>>>> registry\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(commands, "kept.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# kept\n\nmine\n", string(content))
+
+	// Local changes still show as drift
+	assert.Equal(t, DriftModified, installer.Status(manifest).Items["command:merged"].Drift)
+
+	// Forced installs overwrite them
+	installer.Force = true
+	_, err = installer.Install(manifest, []string{"command:kept"})
+	require.NoError(t, err)
+	content, err = os.ReadFile(filepath.Join(commands, "kept.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# kept\n\nintro\n\nbody\n\nmore", string(content))
+
+	// Uninstalling removes the base copy
+	installer.Force = false
+	_, err = installer.Uninstall([]string{"command:kept"}, manifest)
+	require.NoError(t, err)
+	assert.NoFileExists(t, installer.basePath("command", "kept"))
+}
+
+func TestInstaller_StatusMergedItems(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "calm", Desc: "Calm"},
+		Content:    "Stay calm.",
+		Source:     "philosophies/calm.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"philosophy:calm"})
+	require.NoError(t, err)
+
+	mergePath := filepath.Join(projectDir, "CLAUDE.md")
+	status := installer.Status(manifest).Items["philosophy:calm"]
+	assert.True(t, status.Merged)
+	assert.Equal(t, "CLAUDE.md", status.Path)
+	assert.False(t, status.NeedsUpdate)
+	assert.Empty(t, status.Drift)
+
+	t.Run("changed content needs an update", func(t *testing.T) {
+		manifest.Items["philosophy:calm"].Content = "Stay very calm."
+		defer func() { manifest.Items["philosophy:calm"].Content = "Stay calm." }()
+
+		status := installer.Status(manifest).Items["philosophy:calm"]
+		assert.True(t, status.NeedsUpdate)
+		assert.Empty(t, status.Drift)
+	})
+
+	t.Run("edited managed section", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(mergePath, []byte("<!-- regis3:item philosophy:calm -->\nBe loud.\n<!-- regis3:end philosophy:calm -->\n"), 0644))
+		assert.Equal(t, DriftModified, installer.Status(manifest).Items["philosophy:calm"].Drift)
+
+		require.NoError(t, os.WriteFile(mergePath, []byte("<!-- regis3:start -->\nBe loud.\n<!-- regis3:end -->\n"), 0644))
+		assert.Equal(t, DriftModified, installer.Status(manifest).Items["philosophy:calm"].Drift)
+	})
+
+	t.Run("legacy managed section", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(mergePath, []byte("<!-- regis3:start -->\n## Philosophy\n\nStay calm.\n<!-- regis3:end -->\n"), 0644))
+		assert.Empty(t, installer.Status(manifest).Items["philosophy:calm"].Drift)
+	})
+
+	t.Run("missing managed section", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(mergePath, []byte("# Mine"), 0644))
+		assert.Equal(t, DriftMissing, installer.Status(manifest).Items["philosophy:calm"].Drift)
+
+		require.NoError(t, os.WriteFile(mergePath, []byte("<!-- regis3:item philosophy:other -->\nOther\n<!-- regis3:end philosophy:other -->\n"), 0644))
+		assert.Equal(t, DriftMissing, installer.Status(manifest).Items["philosophy:calm"].Drift)
+
+		require.NoError(t, os.Remove(mergePath))
+		assert.Equal(t, DriftMissing, installer.Status(manifest).Items["philosophy:calm"].Drift)
+	})
+}
+
+func TestTarget_TrackerPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("proj", ".claude", TrackerFile), DefaultClaudeTarget().TrackerPath("proj"))
+	assert.Equal(t, filepath.Join("proj", ".cursor", TrackerFile), (&Target{Name: "cursor", BaseDir: ".cursor"}).TrackerPath("proj"))
+	assert.Equal(t, filepath.Join("proj", ".claude", TrackerFile), (&Target{Name: "root"}).TrackerPath("proj"))
+}
+
+func TestDetectTarget(t *testing.T) {
+	candidates := []*Target{DefaultClaudeTarget(), DefaultCodexTarget(), DefaultCursorTarget()}
+
+	tests := []struct {
+		name       string
+		files      []string
+		wantTarget string
+		wantReason string
+	}{
+		{"empty project", nil, "claude", "no target directory or file found, using claude"},
+		{"cursor directory", []string{".cursor/rules.md"}, "cursor", "found .cursor/"},
+		{"claude directory", []string{".claude/settings.json"}, "claude", "found .claude/"},
+		{"both directories", []string{".claude/settings.json", ".cursor/rules.md"}, "claude", "found .claude/ (also .cursor/; set default_target to choose)"},
+		{"installed items win", []string{".claude/settings.json", ".cursor/" + TrackerFile}, "cursor", "items already installed in .cursor/"},
+		{"merge file", []string{"AGENTS.md"}, "codex", "found AGENTS.md"},
+		{"detect file", []string{".cursorrules"}, "cursor", "found .cursorrules"},
+		{"merge file and directory", []string{"CLAUDE.md", ".codex/config.toml"}, "claude", "found CLAUDE.md (also .codex/; set default_target to choose)"},
+		{"file named like a directory", []string{".codex"}, "claude", "no target directory or file found, using claude"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(projectDir, file)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+			}
+
+			target, reason := DetectTarget(projectDir, candidates)
+			assert.Equal(t, tt.wantTarget, target.Name)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestInstaller_InstallIsAtomic(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(registryDir, 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "test", Desc: "Test"},
+		Content:    "# Test",
+		Source:     "skills/test.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "clean", Desc: "Clean", Order: 10},
+		Content:    "# Clean",
+		Source:     "philosophies/clean.md",
+	})
+
+	// A regular file where the merge file's directory should be makes the
+	// merge file impossible to write after the skill has been staged
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "blocked"), []byte("x"), 0644))
+	target := DefaultClaudeTarget()
+	target.MergeFile = "blocked/CLAUDE.md"
+
+	installer, err := NewInstaller(projectDir, registryDir, target)
+	require.NoError(t, err)
+
+	_, err = installer.Install(manifest, []string{"skill:test", "philosophy:clean"})
+	require.Error(t, err)
+
+	// Nothing should have been applied
+	assert.NoFileExists(t, filepath.Join(projectDir, ".claude", "skills", "test", "SKILL.md"))
+	assert.False(t, TrackerExists(projectDir))
+
+	// Staging directory should be cleaned up
+	entries, err := os.ReadDir(projectDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestInstaller_RejectsPathTraversal(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "regis3-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(registryDir, 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "secret"), []byte("secret"), 0644))
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "evil", Desc: "Evil", Files: []string{"../secret"}},
+		Content:    "# Evil",
+		Source:     "skills/evil.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	result, err := installer.Install(manifest, []string{"skill:evil"})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "unsafe path")
+
+	t.Run("uninstall ignores tracked paths outside the project", func(t *testing.T) {
+		installer.Tracker.MarkInstalled("skill:evil", "skill", "evil", "../secret", false)
+
+		result, err := installer.Uninstall([]string{"skill:evil"}, manifest)
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.FileExists(t, filepath.Join(tmpDir, "secret"))
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	t.Run("commit replaces and creates files", func(t *testing.T) {
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "existing.md")
+		created := filepath.Join(dir, "nested", "created.md")
+		require.NoError(t, os.WriteFile(existing, []byte("old"), 0644))
+
+		tx, err := NewTransaction(dir)
+		require.NoError(t, err)
+		require.NoError(t, tx.WriteFile(existing, []byte("new"), 0644))
+		require.NoError(t, tx.WriteFile(created, []byte("first"), 0644))
+		require.NoError(t, tx.WriteFile(created, []byte("second"), 0644))
+		assert.Equal(t, 2, tx.Len())
+
+		// Nothing is visible before commit
+		content, err := os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(content))
+		assert.NoFileExists(t, created)
+
+		require.NoError(t, tx.Commit())
+
+		content, err = os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(content))
+		content, err = os.ReadFile(created)
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(content))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "staging directory should be removed")
+	})
+
+	t.Run("links replace files without writing through them", func(t *testing.T) {
+		dir := t.TempDir()
+		source := filepath.Join(dir, "source.md")
+		dest := filepath.Join(dir, "dest.md")
+		require.NoError(t, os.WriteFile(source, []byte("source"), 0644))
+		require.NoError(t, os.WriteFile(dest, []byte("copy"), 0644))
+
+		tx, err := NewTransaction(dir)
+		require.NoError(t, err)
+		require.NoError(t, tx.Symlink(dest, source))
+		require.NoError(t, tx.Commit())
+		target, err := os.Readlink(dest)
+		require.NoError(t, err)
+		assert.Equal(t, source, target)
+
+		tx, err = NewTransaction(dir)
+		require.NoError(t, err)
+		require.NoError(t, tx.WriteFile(dest, []byte("copy"), 0644))
+		require.NoError(t, tx.Commit())
+		assert.False(t, isSymlink(dest))
+		content, err := os.ReadFile(source)
+		require.NoError(t, err)
+		assert.Equal(t, "source", string(content))
+	})
+
+	t.Run("failed commit restores previous state", func(t *testing.T) {
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "existing.md")
+		created := filepath.Join(dir, "created.md")
+		blocked := filepath.Join(dir, "file", "blocked.md")
+		require.NoError(t, os.WriteFile(existing, []byte("old"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0644))
+
+		tx, err := NewTransaction(dir)
+		require.NoError(t, err)
+		require.NoError(t, tx.WriteFile(existing, []byte("new"), 0644))
+		require.NoError(t, tx.WriteFile(created, []byte("new"), 0644))
+		require.NoError(t, tx.WriteFile(blocked, []byte("new"), 0644))
+
+		require.Error(t, tx.Commit())
+
+		content, err := os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(content))
+		assert.NoFileExists(t, created)
+	})
+
+	t.Run("remove deletes files and empty directories", func(t *testing.T) {
+		dir := t.TempDir()
+		removed := filepath.Join(dir, "nested", "removed.md")
+		blocked := filepath.Join(dir, "file", "blocked.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(removed), 0755))
+		require.NoError(t, os.WriteFile(removed, []byte("old"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0644))
+
+		tx, err := NewTransaction(dir)
+		require.NoError(t, err)
+		tx.Remove(removed)
+		tx.Remove(filepath.Join(dir, "missing.md"))
+		require.NoError(t, tx.Commit())
+		assert.NoDirExists(t, filepath.Dir(removed))
+
+		// A failed commit restores removed files
+		require.NoError(t, os.MkdirAll(filepath.Dir(removed), 0755))
+		require.NoError(t, os.WriteFile(removed, []byte("old"), 0644))
+		tx, err = NewTransaction(dir)
+		require.NoError(t, err)
+		tx.Remove(removed)
+		require.NoError(t, tx.WriteFile(blocked, []byte("new"), 0644))
+		require.Error(t, tx.Commit())
+		assert.FileExists(t, removed)
+	})
+}
+
+func TestLoadTarget(t *testing.T) {
+	// Create temp file
+	tmpFile, err := os.CreateTemp("", "target-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := `
+name: test-target
+description: Test target
+version: "1.0.0"
+base_dir: .test
+merge_file: TEST.md
+paths:
+  skill:
+    dir: skills
+    pattern: "{name}.md"
+`
+	_, err = tmpFile.WriteString(content)
+	require.NoError(t, err)
+	tmpFile.Close()
+
+	target, err := LoadTarget(tmpFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, "test-target", target.Name)
+	assert.Equal(t, ".test", target.BaseDir)
+	assert.Equal(t, "TEST.md", target.MergeFile)
+
+	path, err := target.GetPath("skill", "my-skill")
+	require.NoError(t, err)
+	assert.Equal(t, ".test/skills/my-skill.md", path)
+}
+
+func TestInstaller_LoadsContentFromRegistry(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "tool.md"), []byte("---\nregis3:\n  type: skill\n  name: tool\n---\n# Tool\n"), 0644))
+
+	// Items loaded from a manifest have no content
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool"},
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = inst.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, ".claude", "skills", "tool", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Tool", string(content))
+}
+
+func TestInstaller_Variants(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "commands"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "commands", "review-concise.md"), []byte("# Review briefly"), 0644))
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "command", Name: "review", Desc: "Review", Variants: map[string]string{"concise": "review-concise.md"}},
+		Content:    "# Review",
+		Source:     "commands/review.md",
+		SourceDir:  "commands",
+	})
+	manifest.ComputeChecksums()
+	destPath := filepath.Join(projectDir, ".claude", "commands", "review.md")
+
+	install := func(variant string) *InstallResult {
+		installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		installer.Variant = variant
+		result, err := installer.Install(manifest, []string{"command:review"})
+		require.NoError(t, err)
+		require.Empty(t, result.Errors)
+		return result
+	}
+	installed := func() (string, string) {
+		content, err := os.ReadFile(destPath)
+		require.NoError(t, err)
+		tracker, err := LoadTargetTracker(projectDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		return string(content), tracker.GetInstalled("command:review").Variant
+	}
+
+	result := install("concise")
+	assert.Equal(t, map[string]string{"command:review": "concise"}, result.Variants)
+	content, variant := installed()
+	assert.Contains(t, content, "# Review briefly")
+	assert.Equal(t, "concise", variant)
+
+	// Later installs keep the variant, and the lockfile records it
+	result = install("")
+	assert.Equal(t, []string{"command:review"}, result.Skipped)
+	_, variant = installed()
+	assert.Equal(t, "concise", variant)
+	lock, err := LoadLock(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, "concise", lock.Targets[DefaultClaudeTarget().Name]["command:review"].Variant)
+
+	// A variant the item doesn't define leaves it as it is
+	result = install("detailed")
+	assert.Equal(t, []string{"command:review"}, result.Skipped)
+
+	// The default variant goes back to the item's own body
+	result = install(registry.DefaultVariant)
+	assert.Equal(t, []string{"command:review"}, result.Updated)
+	assert.Empty(t, result.Variants)
+	content, variant = installed()
+	assert.NotContains(t, content, "briefly")
+	assert.Empty(t, variant)
+}
+
+func TestInstaller_Link(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	for path, content := range map[string]string{
+		"skills/tool.md":   "---\nregis3:\n  type: skill\n  name: tool\n---\n# Tool\n",
+		"skills/asset.txt": "v1",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(registryDir, filepath.Dir(path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, path), []byte(content), 0644))
+	}
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"asset.txt"}},
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "kiss", Desc: "KISS"},
+		Content:    "# KISS",
+		Source:     "philosophies/kiss.md",
+	})
+	manifest.ComputeChecksums()
+
+	install := func(link bool) *InstallResult {
+		installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		installer.Link = link
+		result, err := installer.Install(manifest, []string{"skill:tool", "philosophy:kiss"})
+		require.NoError(t, err)
+		require.Empty(t, result.Errors)
+		return result
+	}
+	skillPath := filepath.Join(projectDir, ".claude", "skills", "tool", "SKILL.md")
+	assetPath := filepath.Join(projectDir, ".claude", "skills", "tool", "asset.txt")
+
+	// Item files link to the registry; merged items are merged as usual
+	result := install(true)
+	assert.Equal(t, []string{"skill:tool"}, result.Linked)
+	assert.Equal(t, []string{"philosophy:kiss"}, result.MergedItems)
+	for path, source := range map[string]string{skillPath: "skills/tool.md", assetPath: "skills/asset.txt"} {
+		target, err := os.Readlink(path)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(registryDir, source), target)
+	}
+
+	// Registry edits show at once and aren't drift
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "asset.txt"), []byte("v2"), 0644))
+	content, err := os.ReadFile(assetPath)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	assert.Empty(t, installer.Status(manifest).Items["skill:tool"].Drift)
+
+	// Copy mode replaces the links with copies once the item changes,
+	// leaving the registry as it is
+	manifest.ComputeChecksums()
+	result = install(false)
+	assert.Empty(t, result.Linked)
+	assert.Contains(t, result.Updated, "skill:tool")
+	assert.False(t, isSymlink(skillPath))
+	assert.False(t, isSymlink(assetPath))
+	content, err = os.ReadFile(filepath.Join(registryDir, "skills", "asset.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	// Linking again replaces the copies, though the item didn't change
+	result = install(true)
+	assert.Equal(t, []string{"skill:tool"}, result.Linked)
+	assert.True(t, isSymlink(skillPath))
 }
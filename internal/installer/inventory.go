@@ -0,0 +1,182 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/buildinfo"
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// InventoryItem is an installed item as listed in a project's inventory.
+type InventoryItem struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Version string `json:"version,omitempty"`
+
+	// Hash is the SHA256 of the item's content as installed.
+	Hash string `json:"hash,omitempty"`
+
+	// License is the item's license from the registry, if it declares one.
+	License string `json:"license,omitempty"`
+
+	// Variant is the variant installed, if any.
+	Variant string `json:"variant,omitempty"`
+
+	// Registry is the path or git URL of the registry the item came from.
+	Registry string `json:"registry,omitempty"`
+
+	Files []InstalledFile `json:"files,omitempty"`
+}
+
+// Inventory lists the items installed in a project for the given targets,
+// sorted by target and ID. Licenses come from the manifest, which may be
+// nil; the registry is the one the lockfile records, or else the tracker.
+func Inventory(projectDir string, targets []*Target, manifest *registry.Manifest) ([]InventoryItem, error) {
+	lock, err := LoadLock(projectDir)
+	if err != nil && !errors.Is(err, ErrNoLock) {
+		return nil, err
+	}
+
+	var items []InventoryItem
+	for _, target := range targets {
+		tracker, err := LoadTargetTracker(projectDir, target)
+		if err != nil {
+			return nil, err
+		}
+		source := lock.Registry
+		if source == "" {
+			source = tracker.Data.RegistryPath
+		}
+		for _, id := range tracker.ListInstalled() {
+			installed := tracker.GetInstalled(id)
+			item := InventoryItem{
+				ID:       id,
+				Type:     installed.Type,
+				Name:     installed.Name,
+				Target:   target.Name,
+				Version:  installed.Version,
+				Hash:     installed.SourceHash,
+				Variant:  installed.Variant,
+				Registry: source,
+				Files:    installed.Files,
+			}
+			if manifest != nil {
+				if regItem, ok := manifest.Items[id]; ok {
+					item.License = regItem.License
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Target != items[j].Target {
+			return items[i].Target < items[j].Target
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// CycloneDX returns the inventory of the named project as a CycloneDX 1.5
+// JSON document, one component per item and target.
+func CycloneDX(project string, items []InventoryItem) ([]byte, error) {
+	type hash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	type license struct {
+		License map[string]string `json:"license,omitempty"`
+	}
+	type property struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type component struct {
+		Type       string     `json:"type"`
+		BOMRef     string     `json:"bom-ref"`
+		Group      string     `json:"group"`
+		Name       string     `json:"name"`
+		Version    string     `json:"version,omitempty"`
+		Hashes     []hash     `json:"hashes,omitempty"`
+		Licenses   []license  `json:"licenses,omitempty"`
+		Properties []property `json:"properties"`
+	}
+
+	components := make([]component, 0, len(items))
+	for _, item := range items {
+		c := component{
+			Type:    "data",
+			BOMRef:  item.Target + "/" + item.ID,
+			Group:   item.Type,
+			Name:    item.Name,
+			Version: item.Version,
+			Properties: []property{
+				{Name: "regis3:id", Value: item.ID},
+				{Name: "regis3:target", Value: item.Target},
+			},
+		}
+		if item.Hash != "" {
+			c.Hashes = []hash{{Alg: "SHA-256", Content: item.Hash}}
+		}
+		if item.License != "" {
+			c.Licenses = []license{{License: spdxLicense(item.License)}}
+		}
+		if item.Variant != "" {
+			c.Properties = append(c.Properties, property{Name: "regis3:variant", Value: item.Variant})
+		}
+		if item.Registry != "" {
+			c.Properties = append(c.Properties, property{Name: "regis3:registry", Value: item.Registry})
+		}
+		for _, f := range item.Files {
+			c.Properties = append(c.Properties, property{Name: "regis3:file", Value: f.Path})
+		}
+		components = append(components, c)
+	}
+
+	type application struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	type metadata struct {
+		Timestamp string `json:"timestamp"`
+		Tools     struct {
+			Components []application `json:"components"`
+		} `json:"tools"`
+		Component application `json:"component"`
+	}
+	bom := struct {
+		BOMFormat   string      `json:"bomFormat"`
+		SpecVersion string      `json:"specVersion"`
+		Version     int         `json:"version"`
+		Metadata    metadata    `json:"metadata"`
+		Components  []component `json:"components"`
+	}{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: application{Type: "application", Name: project},
+		},
+		Components: components,
+	}
+	bom.Metadata.Tools.Components = []application{{Type: "application", Name: "regis3", Version: buildinfo.Version}}
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// spdxLicense returns the CycloneDX license entry for a license field: an
+// SPDX identifier, or a name for anything else.
+func spdxLicense(license string) map[string]string {
+	for _, r := range license {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '+') {
+			return map[string]string{"name": license}
+		}
+	}
+	return map[string]string{"id": license}
+}
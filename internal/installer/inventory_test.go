@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "go-style", Desc: "Go style", License: "MIT"},
+		Content:    "# Go style\n",
+		Source:     "skills/go-style.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "command", Name: "review", Desc: "Review"},
+		Content:    "# Review\n",
+		Source:     "commands/review.md",
+	})
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"skill:go-style", "command:review"})
+	require.NoError(t, err)
+
+	items, err := Inventory(projectDir, []*Target{DefaultClaudeTarget()}, manifest)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "command:review", items[0].ID)
+	assert.Empty(t, items[0].License)
+	assert.Equal(t, "skill:go-style", items[1].ID)
+	assert.Equal(t, "claude", items[1].Target)
+	assert.Equal(t, "MIT", items[1].License)
+	assert.Equal(t, registryDir, items[1].Registry)
+	assert.Equal(t, installer.Tracker.GetInstalled("skill:go-style").SourceHash, items[1].Hash)
+	assert.NotEmpty(t, items[1].Hash)
+	require.Len(t, items[1].Files, 1)
+	assert.Equal(t, ".claude/skills/go-style/SKILL.md", items[1].Files[0].Path)
+
+	// Without a manifest, licenses are unknown
+	items, err = Inventory(projectDir, []*Target{DefaultClaudeTarget()}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, items[1].License)
+}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := CycloneDX("web", []InventoryItem{
+		{ID: "skill:go-style", Type: "skill", Name: "go-style", Target: "claude", Hash: "abc", License: "MIT", Registry: "/reg"},
+		{ID: "command:review", Type: "command", Name: "review", Target: "claude", License: "Internal use only"},
+	})
+	require.NoError(t, err)
+
+	var bom struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+		Metadata    struct {
+			Component struct {
+				Name string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			BOMRef string `json:"bom-ref"`
+			Group  string `json:"group"`
+			Name   string `json:"name"`
+			Hashes []struct {
+				Alg     string `json:"alg"`
+				Content string `json:"content"`
+			} `json:"hashes"`
+			Licenses []struct {
+				License map[string]string `json:"license"`
+			} `json:"licenses"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &bom))
+
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Equal(t, "web", bom.Metadata.Component.Name)
+	require.Len(t, bom.Components, 2)
+
+	c := bom.Components[0]
+	assert.Equal(t, "claude/skill:go-style", c.BOMRef)
+	assert.Equal(t, "skill", c.Group)
+	assert.Equal(t, "go-style", c.Name)
+	require.Len(t, c.Hashes, 1)
+	assert.Equal(t, "SHA-256", c.Hashes[0].Alg)
+	assert.Equal(t, "abc", c.Hashes[0].Content)
+	assert.Equal(t, map[string]string{"id": "MIT"}, c.Licenses[0].License)
+
+	// Licenses that aren't SPDX identifiers are named
+	assert.Equal(t, map[string]string{"name": "Internal use only"}, bom.Components[1].Licenses[0].License)
+	assert.Empty(t, bom.Components[1].Hashes)
+}
@@ -0,0 +1,65 @@
+package installer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/okto-digital/regis3/internal/crypt"
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// linkSource returns the absolute path of the registry file an item's
+// installed file links to in link mode, the item file or the file of the
+// variant installed. If the file has to be copied instead, it returns the
+// reason.
+func (i *Installer) linkSource(item *registry.Item, variant string) (string, string, error) {
+	cfg := i.Target.GetTransform(item.Type)
+	if cfg.AddHeader != "" || cfg.WrapWith != "" || (i.Target.InlineFiles && len(item.Files) > 0) {
+		return "", "the " + i.Target.Name + " target rewrites its content", nil
+	}
+
+	file := item.Source
+	if variant != "" {
+		file = path.Join(item.SourceDir, item.Variants[variant])
+	}
+	src, err := pathutil.Join(i.sourceRoot(item), file)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", "", err
+	}
+	body := string(data)
+	if doc, err := frontmatter.ParseBytes(data); err == nil {
+		body = doc.Body
+	}
+	if crypt.IsEncrypted(body) {
+		return "", "its body is encrypted in the registry", nil
+	}
+
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return "", "", err
+	}
+	return abs, "", nil
+}
+
+// linkDrift reports a linked file that is no longer a link to its source
+// as modified. Its content follows the registry, so it isn't compared.
+func linkDrift(fullPath string, f InstalledFile) string {
+	target, err := os.Readlink(fullPath)
+	if err != nil || target != f.Link {
+		return DriftModified
+	}
+	return ""
+}
+
+// isSymlink reports whether path is a symbolic link.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
@@ -0,0 +1,289 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+)
+
+const (
+	// LockFile is the project lockfile, in the project root. It records
+	// what is installed for each target so installs can be reproduced
+	// with Sync.
+	LockFile = "regis3.lock"
+
+	// LockVersion is the current lockfile format.
+	LockVersion = "1"
+)
+
+// ErrNoLock indicates a project without a lockfile.
+var ErrNoLock = errors.New("no " + LockFile + " in the project")
+
+// Lock is the content of a project lockfile.
+type Lock struct {
+	// Version is the lockfile format version.
+	Version string `json:"version"`
+
+	// Registry is the path or git URL of the registry the items were
+	// installed from.
+	Registry string `json:"registry"`
+
+	// Targets maps target names to their installed items by ID.
+	Targets map[string]map[string]*LockedItem `json:"targets"`
+}
+
+// LockedItem is an installed item as recorded in the lockfile.
+type LockedItem struct {
+	// SourceHash is the hash of the item's content as installed.
+	SourceHash string `json:"source_hash"`
+
+	// Deps are the item's resolved dependencies, with capabilities
+	// replaced by their providers.
+	Deps []string `json:"deps,omitempty"`
+
+	// Variant is the variant the item was installed with, if any.
+	Variant string `json:"variant,omitempty"`
+
+	// Dependency is set for items installed only as dependencies.
+	Dependency bool `json:"dependency,omitempty"`
+}
+
+// LockPath returns the path of a project's lockfile.
+func LockPath(projectDir string) string {
+	return filepath.Join(projectDir, LockFile)
+}
+
+// LoadLock reads a project's lockfile. A project without one has an empty
+// lock and ErrNoLock.
+func LoadLock(projectDir string) (*Lock, error) {
+	lock := &Lock{Version: LockVersion, Targets: make(map[string]map[string]*LockedItem)}
+	data, err := os.ReadFile(LockPath(projectDir))
+	if os.IsNotExist(err) {
+		return lock, ErrNoLock
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", LockFile, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFile, err)
+	}
+	if lock.Targets == nil {
+		lock.Targets = make(map[string]map[string]*LockedItem)
+	}
+	return lock, nil
+}
+
+// Items returns the IDs of the items locked for a target, sorted.
+func (l *Lock) Items(target string) []string {
+	ids := make([]string, 0, len(l.Targets[target]))
+	for id := range l.Targets[target] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// record replaces a target's items with those installed according to the
+// tracker. Dependencies come from deps, or are kept from the lock for items
+// that weren't resolved now.
+func (l *Lock) record(target string, tracker *Tracker, deps map[string][]string) {
+	previous := l.Targets[target]
+	items := make(map[string]*LockedItem, tracker.Count())
+	for _, id := range tracker.ListInstalled() {
+		installed := tracker.GetInstalled(id)
+		locked := &LockedItem{SourceHash: installed.SourceHash, Variant: installed.Variant, Dependency: installed.Dependency}
+		if d, ok := deps[id]; ok {
+			locked.Deps = d
+		} else if old, ok := previous[id]; ok {
+			locked.Deps = old.Deps
+		}
+		items[id] = locked
+	}
+	if len(items) == 0 {
+		delete(l.Targets, target)
+		return
+	}
+	l.Targets[target] = items
+}
+
+// marshal encodes the lockfile for writing to disk.
+func (l *Lock) marshal() ([]byte, error) {
+	l.Version = LockVersion
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", LockFile, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// updatedLock returns the project lockfile with the target's installed
+// items recorded, or nil when nothing is installed for any target.
+func (i *Installer) updatedLock() (*Lock, error) {
+	lock, err := LoadLock(i.ProjectDir)
+	if err != nil && !errors.Is(err, ErrNoLock) {
+		return nil, err
+	}
+	lock.Registry = i.RegistryPath
+	if i.RegistryURL != "" {
+		lock.Registry = i.RegistryURL
+	}
+	lock.record(i.Target.Name, i.Tracker, i.lockDeps)
+	if len(lock.Targets) == 0 {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// saveLock writes the project lockfile, or removes it when nothing is
+// installed any more.
+func (i *Installer) saveLock() error {
+	lock, err := i.updatedLock()
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		if err := os.Remove(LockPath(i.ProjectDir)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", LockFile, err)
+		}
+		return nil
+	}
+	data, err := lock.marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(LockPath(i.ProjectDir), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFile, err)
+	}
+	return nil
+}
+
+// stageLock stages the project lockfile in the install transaction.
+func (i *Installer) stageLock() error {
+	lock, err := i.updatedLock()
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return nil
+	}
+	data, err := lock.marshal()
+	if err != nil {
+		return err
+	}
+	return i.tx.WriteFile(LockPath(i.ProjectDir), data, 0644)
+}
+
+// SyncResult contains the result of reconciling a target with the lockfile
+// or the project spec.
+type SyncResult struct {
+	// Install is the result of installing the locked items.
+	Install *InstallResult
+
+	// Uninstall is the result of removing the items the lock doesn't list,
+	// or nil if there were none or installing failed.
+	Uninstall *UninstallResult
+}
+
+// Sync reconciles the target's items with the lockfile: the locked items
+// are installed at their locked content, and then installed items the lock
+// doesn't list are removed. If installing fails, nothing is removed. Items
+// whose registry content no longer matches the lock fail to install, unless
+// the project already has the locked content. Dependencies resolve to the
+// alternatives and capability providers recorded in the lock; resolving to
+// an item the lock doesn't list is an error.
+func (i *Installer) Sync(manifest *registry.Manifest, lock *Lock) (*SyncResult, error) {
+	locked := lock.Targets[i.Target.Name]
+	ids := lock.Items(i.Target.Name)
+
+	available := i.Overrides.Apply(manifest)
+	var missing []string
+	for _, id := range ids {
+		if _, ok := available.Items[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("locked items not in the registry: %v", missing)
+	}
+
+	options := i.ResolverOptions
+	defer func() { i.ResolverOptions = options }()
+	i.ResolverOptions = lockedOptions(options, available, locked)
+	resolved, err := resolver.NewResolverWithOptions(available, i.ResolverOptions).Resolve(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	var unlocked []string
+	for _, item := range resolved.Items {
+		if _, ok := locked[item.FullName()]; !ok {
+			unlocked = append(unlocked, item.FullName())
+		}
+	}
+	if len(unlocked) > 0 {
+		sort.Strings(unlocked)
+		return nil, fmt.Errorf("dependencies not in %s: %v", LockFile, unlocked)
+	}
+
+	var extra []string
+	for _, id := range i.Tracker.ListInstalled() {
+		if _, ok := locked[id]; !ok {
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+
+	i.locked = locked
+	defer func() { i.locked = nil }()
+
+	// Items are only removed once the install succeeded, which rolls back
+	// on failure, so a failed sync leaves the project as it was.
+	installed, err := i.Install(manifest, ids)
+	result := &SyncResult{Install: installed}
+	if err != nil || len(installed.Errors) > 0 || len(extra) == 0 {
+		return result, err
+	}
+	result.Uninstall, err = i.Uninstall(extra, manifest)
+	return result, err
+}
+
+// lockedOptions returns options that resolve the locked items as they were
+// when locked: the one_of alternatives and capability providers recorded
+// in their dependencies take precedence, and nothing is asked.
+func lockedOptions(options resolver.Options, manifest *registry.Manifest, locked map[string]*LockedItem) resolver.Options {
+	var preferred []string
+	providers := make(map[string]string, len(options.Providers))
+	maps.Copy(providers, options.Providers)
+	for _, id := range slices.Sorted(maps.Keys(locked)) {
+		item, ok := manifest.Items[id]
+		if !ok {
+			continue
+		}
+		for _, dep := range locked[id].Deps {
+			if slices.Contains(item.OneOf, dep) {
+				preferred = append(preferred, dep)
+			}
+			provider, ok := manifest.Items[dep]
+			if !ok {
+				continue
+			}
+			for _, ref := range manifest.ItemDeps(item) {
+				if registry.IsCapability(ref) && provider.ProvidesCapability(ref) {
+					providers[ref] = dep
+				}
+			}
+		}
+	}
+
+	options.Preferred = append(preferred, options.Preferred...)
+	options.Providers = providers
+	options.Choose = nil
+	return options
+}
@@ -0,0 +1,265 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lockTestManifest(registryDir string) *registry.Manifest {
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "base", Desc: "Base"},
+		Content:    "# Base",
+		Source:     "skills/base.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "app", Desc: "App", Deps: []string{"skill:base"}},
+		Content:    "# App",
+		Source:     "skills/app.md",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "extra", Desc: "Extra"},
+		Content:    "# Extra",
+		Source:     "skills/extra.md",
+	})
+	return manifest
+}
+
+func TestInstaller_WritesLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	manifest := lockTestManifest(registryDir)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = inst.Install(manifest, []string{"skill:app"})
+	require.NoError(t, err)
+
+	lock, err := LoadLock(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, LockVersion, lock.Version)
+	assert.Equal(t, registryDir, lock.Registry)
+	assert.Equal(t, []string{"skill:app", "skill:base"}, lock.Items("claude"))
+
+	app := lock.Targets["claude"]["skill:app"]
+	assert.Equal(t, []string{"skill:base"}, app.Deps)
+	assert.Equal(t, inst.Tracker.GetInstalled("skill:app").SourceHash, app.SourceHash)
+
+	// A later install keeps the recorded dependencies of earlier items
+	_, err = inst.Install(manifest, []string{"skill:extra"})
+	require.NoError(t, err)
+	lock, err = LoadLock(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:app", "skill:base", "skill:extra"}, lock.Items("claude"))
+	assert.Equal(t, []string{"skill:base"}, lock.Targets["claude"]["skill:app"].Deps)
+
+	// Removing everything removes the lockfile
+	_, err = inst.Uninstall([]string{"skill:app", "skill:base", "skill:extra"}, manifest)
+	require.NoError(t, err)
+	_, err = LoadLock(projectDir)
+	assert.ErrorIs(t, err, ErrNoLock)
+}
+
+func TestInstaller_DryRunLeavesLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	inst.DryRun = true
+	_, err = inst.Install(lockTestManifest(registryDir), []string{"skill:app"})
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, LockPath(projectDir))
+}
+
+func TestInstaller_Sync(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	manifest := lockTestManifest(registryDir)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = inst.Install(manifest, []string{"skill:app"})
+	require.NoError(t, err)
+	lock, err := LoadLock(projectDir)
+	require.NoError(t, err)
+
+	t.Run("installs into a fresh project", func(t *testing.T) {
+		fresh := filepath.Join(tmpDir, "fresh")
+		require.NoError(t, os.MkdirAll(fresh, 0755))
+		inst, err := NewInstaller(fresh, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+
+		result, err := inst.Sync(manifest, lock)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, result.Install.Installed)
+		assert.Nil(t, result.Uninstall)
+		assert.FileExists(t, filepath.Join(fresh, ".claude", "skills", "app", "SKILL.md"))
+	})
+
+	t.Run("removes items not in the lock", func(t *testing.T) {
+		extra, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		_, err = extra.Install(manifest, []string{"skill:extra"})
+		require.NoError(t, err)
+
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		result, err := inst.Sync(manifest, lock)
+		require.NoError(t, err)
+		require.NotNil(t, result.Uninstall)
+		assert.Equal(t, []string{"skill:extra"}, result.Uninstall.Uninstalled)
+		assert.False(t, inst.Tracker.IsInstalled("skill:extra"))
+
+		synced, err := LoadLock(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:app", "skill:base"}, synced.Items("claude"))
+	})
+
+	t.Run("refuses changed registry content", func(t *testing.T) {
+		changed := lockTestManifest(registryDir)
+		changed.Items["skill:base"].Content = "# Base\n\nChanged."
+
+		fresh := filepath.Join(tmpDir, "changed")
+		require.NoError(t, os.MkdirAll(fresh, 0755))
+		inst, err := NewInstaller(fresh, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+
+		result, err := inst.Sync(changed, lock)
+		require.NoError(t, err)
+		require.Len(t, result.Install.Errors, 1)
+		assert.Equal(t, "skill:base", result.Install.Errors[0].ItemID)
+		assert.Contains(t, result.Install.Errors[0].Message, LockFile)
+	})
+
+	t.Run("failed install removes nothing", func(t *testing.T) {
+		changed := lockTestManifest(registryDir)
+		changed.Items["skill:base"].Content = "# Base\n\nChanged."
+
+		fresh := filepath.Join(tmpDir, "failed")
+		require.NoError(t, os.MkdirAll(fresh, 0755))
+		inst, err := NewInstaller(fresh, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		_, err = inst.Install(manifest, []string{"skill:extra"})
+		require.NoError(t, err)
+
+		result, err := inst.Sync(changed, lock)
+		require.NoError(t, err)
+		require.Len(t, result.Install.Errors, 1)
+		assert.Nil(t, result.Uninstall)
+		assert.True(t, inst.Tracker.IsInstalled("skill:extra"))
+		assert.FileExists(t, filepath.Join(fresh, ".claude", "skills", "extra", "SKILL.md"))
+	})
+
+	t.Run("keeps the locked content already installed", func(t *testing.T) {
+		changed := lockTestManifest(registryDir)
+		changed.Items["skill:base"].Content = "# Base\n\nChanged."
+
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		result, err := inst.Sync(changed, lock)
+		require.NoError(t, err)
+		assert.Empty(t, result.Install.Errors)
+		assert.Equal(t, lock.Targets["claude"]["skill:base"].SourceHash, inst.Tracker.GetInstalled("skill:base").SourceHash)
+	})
+
+	t.Run("fails on items missing from the registry", func(t *testing.T) {
+		manifest := registry.NewManifest(registryDir)
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		_, err = inst.Sync(manifest, lock)
+		assert.ErrorContains(t, err, "locked items not in the registry")
+	})
+
+	t.Run("fails on dependencies not in the lock", func(t *testing.T) {
+		partial := &Lock{Version: LockVersion, Targets: map[string]map[string]*LockedItem{
+			"claude": {"skill:app": lock.Targets["claude"]["skill:app"]},
+		}}
+		fresh := filepath.Join(tmpDir, "partial")
+		require.NoError(t, os.MkdirAll(fresh, 0755))
+		inst, err := NewInstaller(fresh, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+
+		_, err = inst.Sync(manifest, partial)
+		assert.ErrorContains(t, err, "dependencies not in "+LockFile+": [skill:base]")
+		assert.Empty(t, inst.Tracker.ListInstalled())
+	})
+}
+
+func TestInstaller_SyncLockedChoices(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	manifest := registry.NewManifest(registryDir)
+	for _, item := range []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "jest", Desc: "Jest", Provides: []string{"capability:testing"}}, Content: "# Jest", Source: "skills/jest.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "vitest", Desc: "Vitest", Provides: []string{"capability:testing"}}, Content: "# Vitest", Source: "skills/vitest.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "frontend", Desc: "Frontend", OneOf: []string{"skill:jest", "skill:vitest"}}, Content: "# Frontend", Source: "stacks/frontend.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tdd", Desc: "TDD", Deps: []string{"capability:testing"}}, Content: "# TDD", Source: "skills/tdd.md"},
+	} {
+		manifest.AddItem(item)
+	}
+
+	// Install with the choices made, then sync into fresh projects without
+	// them: the lock reproduces the choices
+	tests := []struct {
+		name    string
+		install []string
+		options resolver.Options
+		want    []string
+	}{
+		{
+			name:    "one_of alternative",
+			install: []string{"stack:frontend"},
+			options: resolver.Options{Preferred: []string{"skill:vitest"}},
+			want:    []string{"skill:vitest", "stack:frontend"},
+		},
+		{
+			name:    "capability provider",
+			install: []string{"skill:tdd"},
+			options: resolver.Options{Providers: map[string]string{"capability:testing": "skill:vitest"}},
+			want:    []string{"skill:tdd", "skill:vitest"},
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := filepath.Join(tmpDir, fmt.Sprintf("project%d", n))
+			require.NoError(t, os.MkdirAll(projectDir, 0755))
+			inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			inst.ResolverOptions = tt.options
+			_, err = inst.Install(manifest, tt.install)
+			require.NoError(t, err)
+			lock, err := LoadLock(projectDir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, lock.Items("claude"))
+
+			fresh := filepath.Join(tmpDir, fmt.Sprintf("fresh%d", n))
+			require.NoError(t, os.MkdirAll(fresh, 0755))
+			inst, err = NewInstaller(fresh, registryDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			result, err := inst.Sync(manifest, lock)
+			require.NoError(t, err)
+			assert.NotContains(t, result.Install.Installed, "skill:jest")
+			assert.ElementsMatch(t, tt.want, inst.Tracker.ListInstalled())
+
+			synced, err := LoadLock(fresh)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, synced.Items("claude"))
+		})
+	}
+}
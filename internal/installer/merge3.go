@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written around the two sides of a conflicting change.
+const (
+	conflictLocal    = "<<<<<<< local"
+	conflictSplit    = "======="
+	conflictRegistry = ">>>>>>> registry"
+)
+
+// Merge3 merges the changes made to base locally and in the registry, line
+// by line. Where only one side changed a region its version is taken; where
+// both changed it differently, both versions are kept between conflict
+// markers. It returns the merged text and the number of conflicts. A final
+// newline, which editors often add or strip, is kept as it is locally.
+func Merge3(base, local, registry string) (string, int) {
+	split := func(s string) []string {
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	b, l, r := split(base), split(local), split(registry)
+	toLocal := matchLines(b, l)
+	toRegistry := matchLines(b, r)
+
+	var merged []string
+	conflicts := 0
+	resolve := func(bs, ls, rs []string) {
+		switch {
+		case slices.Equal(ls, bs) || slices.Equal(ls, rs):
+			merged = append(merged, rs...)
+		case slices.Equal(rs, bs):
+			merged = append(merged, ls...)
+		default:
+			conflicts++
+			merged = append(merged, conflictLocal)
+			merged = append(merged, ls...)
+			merged = append(merged, conflictSplit)
+			merged = append(merged, rs...)
+			merged = append(merged, conflictRegistry)
+		}
+	}
+
+	// Base lines kept on both sides split the texts into regions merged
+	// on their own
+	bi, li, ri := 0, 0, 0
+	for k := range b {
+		if toLocal[k] < 0 || toRegistry[k] < 0 {
+			continue
+		}
+		resolve(b[bi:k], l[li:toLocal[k]], r[ri:toRegistry[k]])
+		merged = append(merged, b[k])
+		bi, li, ri = k+1, toLocal[k]+1, toRegistry[k]+1
+	}
+	resolve(b[bi:], l[li:], r[ri:])
+
+	text := strings.Join(merged, "\n")
+	if strings.HasSuffix(local, "\n") {
+		text += "\n"
+	}
+	return text, conflicts
+}
+
+// matchLines maps each line of a to the line of b it is kept as by
+// diffLines, or to -1 if it is removed.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for _, op := range diffLines(a, b) {
+		switch op.kind {
+		case ' ':
+			match[i] = j
+			i++
+			j++
+		case '-':
+			match[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return match
+}
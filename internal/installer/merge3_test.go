@@ -0,0 +1,77 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "# Title\n\nintro\n\nbody\n\nend\n"
+
+	tests := []struct {
+		name      string
+		local     string
+		registry  string
+		want      string
+		conflicts int
+	}{
+		{
+			name:     "unchanged",
+			local:    base,
+			registry: base,
+			want:     base,
+		},
+		{
+			name:     "registry change only",
+			local:    base,
+			registry: "# Title\n\nintro\n\nnew body\n\nend\n",
+			want:     "# Title\n\nintro\n\nnew body\n\nend\n",
+		},
+		{
+			name:     "local change only",
+			local:    "# Title\n\nmy intro\n\nbody\n\nend\n",
+			registry: base,
+			want:     "# Title\n\nmy intro\n\nbody\n\nend\n",
+		},
+		{
+			name:     "separate changes",
+			local:    "# Title\n\nmy intro\n\nbody\n\nend\n",
+			registry: "# Title\n\nintro\n\nnew body\n\nend\nappendix\n",
+			want:     "# Title\n\nmy intro\n\nnew body\n\nend\nappendix\n",
+		},
+		{
+			name:     "same change on both sides",
+			local:    "# Title\n\nintro\n\nfixed\n\nend\n",
+			registry: "# Title\n\nintro\n\nfixed\n\nend\n",
+			want:     "# Title\n\nintro\n\nfixed\n\nend\n",
+		},
+		{
+			name:      "conflicting changes",
+			local:     "# Title\n\nintro\n\nmine\n\nend\n",
+			registry:  "# Title\n\nintro\n\ntheirs\n\nend\n",
+			want:      "# Title\n\nintro\n\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> registry\n\nend\n",
+			conflicts: 1,
+		},
+		{
+			name:     "final newline stripped locally",
+			local:    "# Title\n\nintro\n\nbody\n\nend",
+			registry: "# Title\n\nintro\n\nnew body\n\nend\n",
+			want:     "# Title\n\nintro\n\nnew body\n\nend",
+		},
+		{
+			name:     "local deletion",
+			local:    "# Title\n\nbody\n\nend\n",
+			registry: "# Title\n\nintro\n\nbody\n\nend\nappendix\n",
+			want:     "# Title\n\nbody\n\nend\nappendix\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.local, tt.registry)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.conflicts, conflicts)
+		})
+	}
+}
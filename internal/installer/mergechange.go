@@ -0,0 +1,220 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+)
+
+// ErrMergeDeclined is returned by Install when a rewrite of the merge file
+// that loses content is not confirmed. Nothing is installed.
+var ErrMergeDeclined = errors.New("merge file rewrite declined")
+
+// mergeDiffContext is how many unchanged lines a diff shows around changes.
+const mergeDiffContext = 2
+
+// MergeChange describes a rewrite of the merge file's managed section that
+// loses content: the section gets shorter or headings in it are gone.
+type MergeChange struct {
+	// File is the merge file (e.g. CLAUDE.md).
+	File string
+
+	// Items are the merged items the rewrite writes, by ref.
+	Items []string
+
+	// Removed are the headings of the old section missing from the new one.
+	Removed []string
+
+	// Old and New are the managed section before and after the rewrite.
+	Old string
+	New string
+}
+
+// Summary describes what the rewrite loses in one line.
+func (c *MergeChange) Summary() string {
+	var losses []string
+	if old, updated := countLines(c.Old), countLines(c.New); updated < old {
+		losses = append(losses, fmt.Sprintf("shrinks from %d to %d lines", old, updated))
+	}
+	if len(c.Removed) > 0 {
+		quoted := make([]string, len(c.Removed))
+		for i, heading := range c.Removed {
+			quoted[i] = fmt.Sprintf("%q", heading)
+		}
+		losses = append(losses, "removes "+strings.Join(quoted, ", "))
+	}
+	return c.File + " managed section " + strings.Join(losses, " and ")
+}
+
+// Diff returns a line diff of the managed section, as LineDiff does.
+func (c *MergeChange) Diff() string {
+	return LineDiff(c.Old, c.New)
+}
+
+// LineDiff returns a line diff of old and new: removed lines start with
+// "-", added lines with "+" and unchanged lines with a space. Unchanged
+// lines away from changes are left out, with "@@" marking the gaps.
+func LineDiff(old, new string) string {
+	ops := diffLines(strings.Split(old, "\n"), strings.Split(new, "\n"))
+
+	// Keep changed lines and the context around them
+	keep := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		for j := max(0, i-mergeDiffContext); j <= min(len(ops)-1, i+mergeDiffContext); j++ {
+			keep[j] = true
+		}
+	}
+
+	var lines []string
+	skipped := false
+	for i, op := range ops {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(lines) > 0 {
+			lines = append(lines, "@@")
+		}
+		skipped = false
+		lines = append(lines, string(op.kind)+op.line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updatedMergeSection returns the merge file's managed section before and
+// after writing mergeContent. The old section is empty if there is no
+// merge file.
+func (i *Installer) updatedMergeSection(mergeContent *MergeContent) (old, updated string, err error) {
+	mergeFilePath, err := pathutil.Join(i.ProjectDir, i.Target.MergeFile)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := os.ReadFile(mergeFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+	updated = ExtractManagedContent(UpdateExistingFile(string(data), mergeContent.Generate()))
+	return ExtractManagedContent(string(data)), updated, nil
+}
+
+// checkMergeChange returns the change if writing mergeContent would lose
+// content from the merge file's existing managed section, or nil.
+func (i *Installer) checkMergeChange(mergeContent *MergeContent) (*MergeChange, error) {
+	old, updated, err := i.updatedMergeSection(mergeContent)
+	if err != nil {
+		return nil, err
+	}
+	if old == "" || old == updated {
+		return nil, nil
+	}
+
+	remaining := make(map[string]bool)
+	for _, heading := range headings(updated) {
+		remaining[heading] = true
+	}
+	var removed []string
+	for _, heading := range headings(old) {
+		if !remaining[heading] {
+			removed = append(removed, heading)
+		}
+	}
+	if len(removed) == 0 && countLines(updated) >= countLines(old) {
+		return nil, nil
+	}
+
+	var items []string
+	for _, c := range mergeContent.Contributions() {
+		items = append(items, c.ID)
+	}
+	sort.Strings(items)
+	return &MergeChange{
+		File:    i.Target.MergeFile,
+		Items:   items,
+		Removed: removed,
+		Old:     old,
+		New:     updated,
+	}, nil
+}
+
+// headings returns the markdown headings in content, outside code blocks.
+func headings(content string) []string {
+	var result []string
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "```"):
+			inCode = !inCode
+		case !inCode && strings.HasPrefix(line, "#"):
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// countLines returns the number of non-blank lines in content.
+func countLines(content string) int {
+	n := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// diffOp is a line of a diff: kept (' '), removed ('-') or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the edit script turning a into b, from their longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,180 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstaller_MergeChange(t *testing.T) {
+	newManifest := func(registryDir string) *registry.Manifest {
+		manifest := registry.NewManifest(registryDir)
+		for name, content := range map[string]string{
+			"kiss":       "# KISS\n\nKeep it simple.",
+			"clean-code": "# Clean Code\n\nName things well.",
+		} {
+			manifest.AddItem(&registry.Item{
+				Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: name, Desc: "Principles", Order: 10},
+				Content:    content,
+				Source:     "philosophies/" + name + ".md",
+			})
+		}
+		return manifest
+	}
+
+	tests := []struct {
+		name      string
+		confirm   *bool
+		dryRun    bool
+		wantErr   error
+		rewritten bool
+	}{
+		{name: "not asked", rewritten: true},
+		{name: "confirmed", confirm: boolPtr(true), rewritten: true},
+		{name: "declined", confirm: boolPtr(false), wantErr: ErrMergeDeclined},
+		{name: "dry run is not asked", confirm: boolPtr(false), dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryDir := t.TempDir()
+			projectDir := t.TempDir()
+			manifest := newManifest(registryDir)
+
+			first, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			result, err := first.Install(manifest, []string{"philosophy:kiss"})
+			require.NoError(t, err)
+			assert.Nil(t, result.MergeChange, "a new merge file loses nothing")
+
+			mergeFile := filepath.Join(projectDir, "CLAUDE.md")
+			before, err := os.ReadFile(mergeFile)
+			require.NoError(t, err)
+			hash := first.Tracker.GetInstalled("philosophy:kiss").SourceHash
+
+			// The update drops the item's heading
+			kiss, _ := manifest.GetItem("philosophy:kiss")
+			kiss.Content = "Keep it simple.\n\nReally simple."
+
+			second, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			second.DryRun = tt.dryRun
+			asked := 0
+			if tt.confirm != nil {
+				second.ConfirmMerge = func(change *MergeChange) (bool, error) {
+					asked++
+					return *tt.confirm, nil
+				}
+			}
+
+			result, err = second.Install(manifest, []string{"philosophy:kiss"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.confirm != nil && !tt.dryRun {
+				assert.Equal(t, 1, asked)
+			} else {
+				assert.Zero(t, asked)
+			}
+
+			change := result.MergeChange
+			require.NotNil(t, change)
+			assert.Equal(t, "CLAUDE.md", change.File)
+			assert.Equal(t, []string{"philosophy:kiss"}, change.Items)
+			assert.Equal(t, []string{"# KISS"}, change.Removed)
+			assert.Equal(t, `CLAUDE.md managed section removes "# KISS"`, change.Summary())
+
+			after, err := os.ReadFile(mergeFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rewritten, string(before) != string(after))
+
+			tracker, err := LoadTargetTracker(projectDir, DefaultClaudeTarget())
+			require.NoError(t, err)
+			assert.Equal(t, tt.rewritten, tracker.GetInstalled("philosophy:kiss").SourceHash != hash)
+		})
+	}
+}
+
+func TestInstaller_MergeChange_NoLoss(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	item := &registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "ruleset", Name: "style", Desc: "Style rules", Order: 10},
+		Content:    "# Style\n\nUse gofmt.",
+		Source:     "rulesets/style.md",
+	}
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(item)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = inst.Install(manifest, []string{"ruleset:style"})
+	require.NoError(t, err)
+
+	// A growing section is not a loss
+	item.Content = "# Style\n\nUse gofmt.\nUse go vet."
+	inst, err = NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	inst.ConfirmMerge = func(*MergeChange) (bool, error) {
+		t.Fatal("no confirmation expected")
+		return false, nil
+	}
+	result, err := inst.Install(manifest, []string{"ruleset:style"})
+	require.NoError(t, err)
+	assert.Nil(t, result.MergeChange)
+}
+
+func TestMergeChange_Summary(t *testing.T) {
+	change := &MergeChange{
+		File:    "CLAUDE.md",
+		Removed: []string{"# KISS", "## Ruleset"},
+		Old:     "## Philosophy\n\n# KISS\n\nKeep it simple.\n\n## Ruleset\n\nUse gofmt.",
+		New:     "## Philosophy\n\nName things well.",
+	}
+	assert.Equal(t, `CLAUDE.md managed section shrinks from 5 to 2 lines and removes "# KISS", "## Ruleset"`, change.Summary())
+}
+
+func TestMergeChange_Diff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "removed section",
+			old:  "## Philosophy\n\n# KISS\n\nKeep it simple.",
+			new:  "## Philosophy\n\n# Clean Code\n\nName things well.",
+			want: " ## Philosophy\n \n-# KISS\n+# Clean Code\n \n-Keep it simple.\n+Name things well.",
+		},
+		{
+			name: "distant changes",
+			old:  "a\nb\nc\nd\ne\nf\ng\nh",
+			new:  "A\nb\nc\nd\ne\nf\ng",
+			want: "-a\n+A\n b\n c\n@@\n f\n g\n-h",
+		},
+		{
+			name: "unchanged",
+			old:  "a\nb",
+			new:  "a\nb",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := &MergeChange{Old: tt.old, New: tt.new}
+			assert.Equal(t, tt.want, change.Diff())
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// LocalDir is the project directory holding project-local overrides:
+// customized copies of registry items, laid out like a registry.
+const LocalDir = ".regis3/local"
+
+// Overrides are project-local items that shadow the registry items with
+// the same reference when installing, updating and checking status, so a
+// project can carry a customized copy of a shared item.
+type Overrides struct {
+	// Dir is the directory the overrides were read from.
+	Dir string
+
+	// Items maps item IDs to their local copies.
+	Items map[string]*registry.Item
+}
+
+// LoadOverrides reads the overrides in the project's LocalDir. A project
+// without one has no overrides. Files that fail to parse are an error, so
+// a broken override doesn't silently install the registry item instead.
+func LoadOverrides(projectDir string, dialect registry.Dialect) (*Overrides, error) {
+	o := &Overrides{
+		Dir:   filepath.Join(projectDir, filepath.FromSlash(LocalDir)),
+		Items: make(map[string]*registry.Item),
+	}
+	if _, err := os.Stat(o.Dir); os.IsNotExist(err) {
+		return o, nil
+	}
+
+	scanner := registry.NewScanner(o.Dir)
+	scanner.Dialect = dialect
+	scan, err := scanner.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	if len(scan.Errors) > 0 {
+		return nil, fmt.Errorf("invalid override: %w", scan.Errors[0])
+	}
+	for _, item := range scan.Items {
+		if other, ok := o.Items[item.FullName()]; ok {
+			return nil, fmt.Errorf("%s is overridden twice: %s and %s", item.FullName(), other.Source, item.Source)
+		}
+		// Changed assets update the installed copy, like in a built manifest
+		item.ComputeChecksums(o.Dir)
+		o.Items[item.FullName()] = item
+	}
+	return o, nil
+}
+
+// Apply returns a copy of manifest with the overridden items replaced by
+// their local copies. Overrides of items the registry doesn't have are
+// left out; see Unmatched. The copy has no hash, so its dependency graph
+// is neither read from nor written to the registry's graph cache.
+func (o *Overrides) Apply(manifest *registry.Manifest) *registry.Manifest {
+	if o == nil || len(o.Items) == 0 {
+		return manifest
+	}
+	shadowed := *manifest
+	shadowed.Hash = ""
+	shadowed.Items = make(map[string]*registry.Item, len(manifest.Items))
+	for id, item := range manifest.Items {
+		if local, ok := o.Items[id]; ok {
+			item = local
+		}
+		shadowed.Items[id] = item
+	}
+	return &shadowed
+}
+
+// Unmatched returns the sorted IDs of overrides with no registry item of
+// the same reference.
+func (o *Overrides) Unmatched(manifest *registry.Manifest) []string {
+	if o == nil {
+		return nil
+	}
+	var ids []string
+	for id := range o.Items {
+		if _, ok := manifest.Items[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Has reports whether item is a local override.
+func (o *Overrides) Has(item *registry.Item) bool {
+	return o != nil && o.Items[item.FullName()] == item
+}
+
+// sourceRoot returns the directory item's source paths are relative to: the
+// overrides directory for local copies, the registry otherwise.
+func (i *Installer) sourceRoot(item *registry.Item) string {
+	if i.Overrides.Has(item) {
+		return i.Overrides.Dir
+	}
+	return i.RegistryPath
+}
@@ -0,0 +1,158 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOverride(t *testing.T, projectDir, rel, content string) {
+	path := filepath.Join(projectDir, filepath.FromSlash(LocalDir), rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadOverrides(t *testing.T) {
+	t.Run("project without overrides", func(t *testing.T) {
+		o, err := LoadOverrides(t.TempDir(), registry.Dialect{})
+		require.NoError(t, err)
+		assert.Empty(t, o.Items)
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		projectDir := t.TempDir()
+		writeOverride(t, projectDir, "skills/tool.md", "---\nregis3:\n  type: [skill\n---\n")
+
+		_, err := LoadOverrides(projectDir, registry.Dialect{})
+		assert.ErrorContains(t, err, "invalid override")
+	})
+
+	t.Run("same item twice", func(t *testing.T) {
+		projectDir := t.TempDir()
+		writeOverride(t, projectDir, "skills/a.md", "---\nregis3:\n  type: skill\n  name: tool\n---\n")
+		writeOverride(t, projectDir, "skills/b.md", "---\nregis3:\n  type: skill\n  name: tool\n---\n")
+
+		_, err := LoadOverrides(projectDir, registry.Dialect{})
+		assert.ErrorContains(t, err, "skill:tool is overridden twice")
+	})
+}
+
+func TestOverrides_Apply(t *testing.T) {
+	projectDir := t.TempDir()
+	writeOverride(t, projectDir, "skills/tool.md", "---\nregis3:\n  type: skill\n  name: tool\n---\n# Local tool\n")
+	writeOverride(t, projectDir, "skills/extra.md", "---\nregis3:\n  type: skill\n  name: extra\n---\n")
+
+	manifest := registry.NewManifest(t.TempDir())
+	registryItem := &registry.Item{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool"}, Content: "# Tool"}
+	manifest.AddItem(registryItem)
+	manifest.AddItem(&registry.Item{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "other"}, Content: "# Other"})
+
+	o, err := LoadOverrides(projectDir, registry.Dialect{})
+	require.NoError(t, err)
+	shadowed := o.Apply(manifest)
+
+	assert.True(t, o.Has(shadowed.Items["skill:tool"]))
+	assert.False(t, o.Has(shadowed.Items["skill:other"]))
+	assert.NotContains(t, shadowed.Items, "skill:extra", "overrides only shadow registry items")
+	assert.Same(t, registryItem, manifest.Items["skill:tool"], "the manifest is left as is")
+	assert.Equal(t, []string{"skill:extra"}, o.Unmatched(manifest))
+
+	var none *Overrides
+	assert.Same(t, manifest, none.Apply(manifest))
+	assert.Empty(t, none.Unmatched(manifest))
+}
+
+func TestInstaller_InstallsOverrides(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	writeOverride(t, projectDir, "skills/tool.md", "---\nregis3:\n  type: skill\n  name: tool\n  deps: [philosophy:kiss]\n  files: [asset.txt]\n---\n# Local tool\n")
+	writeOverride(t, projectDir, "skills/asset.txt", "local asset")
+	writeOverride(t, projectDir, "philosophies/kiss.md", "---\nregis3:\n  type: philosophy\n  name: kiss\n---\nKeep it local.\n")
+
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Deps: []string{"philosophy:kiss"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+	manifest.AddItem(&registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "kiss", Desc: "KISS"},
+		Content:    "Keep it simple.",
+		Source:     "philosophies/kiss.md",
+	})
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	inst.Overrides, err = LoadOverrides(projectDir, registry.Dialect{})
+	require.NoError(t, err)
+
+	result, err := inst.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.ElementsMatch(t, []string{"skill:tool", "philosophy:kiss"}, result.Local)
+
+	skill, err := os.ReadFile(filepath.Join(projectDir, ".claude", "skills", "tool", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Local tool", string(skill))
+	asset, err := os.ReadFile(filepath.Join(projectDir, ".claude", "skills", "tool", "asset.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "local asset", string(asset))
+	merged, err := os.ReadFile(filepath.Join(projectDir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(merged), "Keep it local.")
+
+	// The installed override is up to date, and marked as local
+	status := inst.Status(manifest)
+	assert.True(t, status.Items["skill:tool"].Local)
+	assert.False(t, status.Items["skill:tool"].NeedsUpdate)
+	outdated, _ := inst.Outdated(manifest)
+	assert.Empty(t, outdated)
+
+	// Without the override, the registry item is an update
+	inst.Overrides = nil
+	outdated, _ = inst.Outdated(manifest)
+	assert.Equal(t, []string{"philosophy:kiss", "skill:tool"}, outdated)
+}
+
+func TestInstaller_OverridesBypassGraphCache(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	writeOverride(t, projectDir, "skills/a.md", "---\nregis3:\n  type: skill\n  name: a\n  deps: [skill:b]\n---\n# Local a\n")
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"a", "b"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "skill", Name: name, Desc: name},
+			Content:    "# " + name,
+			Source:     "skills/" + name + ".md",
+		})
+	}
+	manifest.Hash = "abc"
+	opts := resolver.Options{GraphCache: filepath.Join(registryDir, registry.DefaultBuildDir, resolver.DefaultGraphFile)}
+
+	// Warm the cache with the registry's graph
+	_, err := resolver.NewResolverWithOptions(manifest, opts).GetAllInstallOrder()
+	require.NoError(t, err)
+	require.FileExists(t, opts.GraphCache)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	inst.ResolverOptions = opts
+	inst.DryRun = true
+	inst.Overrides, err = LoadOverrides(projectDir, registry.Dialect{})
+	require.NoError(t, err)
+
+	result, err := inst.Install(manifest, []string{"skill:a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:b", "skill:a"}, result.Installed, "the override's dependency is installed")
+
+	// The cache still holds the registry's graph
+	r := resolver.NewResolverWithOptions(manifest, opts)
+	assert.Empty(t, r.Graph().Dependencies("skill:a"))
+}
@@ -0,0 +1,214 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// PlanFile is the file name of the install plan written for hooks and scripts.
+const PlanFile = "regis3-plan.json"
+
+// Plan describes a resolved installation so hooks and item scripts can act
+// on it, e.g. installing npm packages a skill needs. It is passed to them as
+// environment variables (see Env) and as a JSON file.
+type Plan struct {
+	// Target is the name of the installation target.
+	Target string `json:"target"`
+
+	// ProjectDir is the absolute project directory.
+	ProjectDir string `json:"project_dir"`
+
+	// RegistryPath is the registry the items come from.
+	RegistryPath string `json:"registry_path"`
+
+	// DryRun is set when nothing will be written.
+	DryRun bool `json:"dry_run"`
+
+	// Items are the items to install, dependencies first.
+	Items []PlanItem `json:"items"`
+
+	// Preflight are the problems that keep the items from being written,
+	// such as unwritable directories or too little disk space.
+	Preflight []PreflightIssue `json:"preflight,omitempty"`
+}
+
+// PlanItem is a single item of an install plan.
+type PlanItem struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Source string `json:"source"`
+
+	// Path is the install path relative to the project, or the merge file
+	// for merged items. Empty for stacks and items that can't be installed.
+	Path   string `json:"path,omitempty"`
+	Merged bool   `json:"merged,omitempty"`
+}
+
+// PathConflictError reports two items that would install to the same path.
+type PathConflictError struct {
+	// Path is the contested path, relative to the project.
+	Path string
+
+	// Owner is the item (or regis3 file) already claiming the path.
+	Owner string
+
+	// Item is the item that would overwrite it.
+	Item string
+}
+
+func (e *PathConflictError) Error() string {
+	return fmt.Sprintf("%s and %s both install to %s", e.Owner, e.Item, e.Path)
+}
+
+// NewPlan describes installing items (in order) with this installer. Items
+// that would write the same file as another planned or installed item, the
+// merge file or the tracker fail with a *PathConflictError. Problems writing
+// the items are checked up front and listed in the plan's Preflight.
+func (i *Installer) NewPlan(items []*registry.Item) (*Plan, error) {
+	projectDir, err := filepath.Abs(i.ProjectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	plan := &Plan{
+		Target:       i.Target.Name,
+		ProjectDir:   projectDir,
+		RegistryPath: i.RegistryPath,
+		DryRun:       i.DryRun,
+		Items:        make([]PlanItem, 0, len(items)),
+	}
+	for _, item := range items {
+		planItem := PlanItem{
+			ID:     item.FullName(),
+			Type:   item.Type,
+			Name:   item.Name,
+			Source: item.Source,
+		}
+		switch {
+		case i.Target.IsMergeType(item.Type):
+			planItem.Path = i.Target.MergeFile
+			planItem.Merged = true
+		case item.Type != string(registry.TypeStack):
+			// An invalid path is reported when the item is installed
+			planItem.Path, _ = i.Target.GetPath(item.Type, item.Name)
+		}
+		plan.Items = append(plan.Items, planItem)
+	}
+
+	if err := i.checkConflicts(items, plan); err != nil {
+		return nil, err
+	}
+	plan.Preflight = i.preflight(items, plan)
+	return plan, nil
+}
+
+// checkConflicts claims every file the planned items write, including
+// additional files, and reports the first path claimed twice. Paths are
+// compared case-insensitively, as they collide on case-insensitive
+// filesystems too.
+func (i *Installer) checkConflicts(items []*registry.Item, plan *Plan) error {
+	owners := make(map[string]string)
+	claim := func(path, owner string) error {
+		key := strings.ToLower(filepath.ToSlash(filepath.Clean(path)))
+		if existing, ok := owners[key]; ok && existing != owner {
+			return &PathConflictError{Path: filepath.ToSlash(path), Owner: existing, Item: owner}
+		}
+		owners[key] = owner
+		return nil
+	}
+
+	if i.Target.MergeFile != "" {
+		claim(i.Target.MergeFile, "the merge file")
+	}
+	if rel, err := filepath.Rel(i.ProjectDir, i.Tracker.Path); err == nil {
+		claim(rel, "the regis3 tracker")
+	}
+
+	// Installed items keep their files unless they are reinstalled now
+	planned := make(map[string]bool, len(items))
+	for _, item := range items {
+		planned[item.FullName()] = true
+	}
+	installed := i.Tracker.ListInstalled()
+	sort.Strings(installed)
+	for _, id := range installed {
+		if planned[id] {
+			continue
+		}
+		for _, path := range i.Tracker.GetInstalled(id).Paths() {
+			claim(path, id)
+		}
+	}
+
+	for n, item := range items {
+		planItem := plan.Items[n]
+		if planItem.Merged || planItem.Path == "" {
+			continue
+		}
+		if err := claim(planItem.Path, planItem.ID); err != nil {
+			return err
+		}
+		for _, file := range item.InstallFiles() {
+			if err := claim(filepath.Join(filepath.Dir(planItem.Path), file), planItem.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IDs returns the full names of the planned items in order.
+func (p *Plan) IDs() []string {
+	ids := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// Write writes the plan as JSON to PlanFile in dir and returns its path.
+func (p *Plan) Write(dir string) (string, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode plan: %w", err)
+	}
+	path := filepath.Join(dir, PlanFile)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write plan: %w", err)
+	}
+	return path, nil
+}
+
+// Env returns the plan as environment variables (KEY=value) for hooks and
+// scripts. planPath is the JSON file written by Write; it is omitted if empty.
+//
+//	REGIS3_TARGET       target name
+//	REGIS3_PROJECT_DIR  absolute project directory
+//	REGIS3_REGISTRY     registry path
+//	REGIS3_DRY_RUN      "1" for dry runs, "0" otherwise
+//	REGIS3_ITEMS        space-separated item IDs, dependencies first
+//	REGIS3_PLAN         path to the JSON plan
+func (p *Plan) Env(planPath string) []string {
+	dryRun := "0"
+	if p.DryRun {
+		dryRun = "1"
+	}
+	env := []string{
+		"REGIS3_TARGET=" + p.Target,
+		"REGIS3_PROJECT_DIR=" + p.ProjectDir,
+		"REGIS3_REGISTRY=" + p.RegistryPath,
+		"REGIS3_DRY_RUN=" + dryRun,
+		"REGIS3_ITEMS=" + strings.Join(p.IDs(), " "),
+	}
+	if planPath != "" {
+		env = append(env, "REGIS3_PLAN="+planPath)
+	}
+	return env
+}
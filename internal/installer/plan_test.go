@@ -0,0 +1,126 @@
+package installer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstaller_NewPlan(t *testing.T) {
+	projectDir := t.TempDir()
+	inst, err := NewInstaller(projectDir, "/registry", DefaultClaudeTarget())
+	require.NoError(t, err)
+	inst.DryRun = true
+
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "testing"}, Source: "skills/testing.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "philosophy", Name: "clean-code"}, Source: "philosophies/clean-code.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "base"}, Source: "stacks/base.md"},
+	}
+
+	plan, err := inst.NewPlan(items)
+	require.NoError(t, err)
+	assert.Equal(t, "claude", plan.Target)
+	assert.Equal(t, []PlanItem{
+		{ID: "skill:testing", Type: "skill", Name: "testing", Source: "skills/testing.md", Path: ".claude/skills/testing/SKILL.md"},
+		{ID: "philosophy:clean-code", Type: "philosophy", Name: "clean-code", Source: "philosophies/clean-code.md", Path: "CLAUDE.md", Merged: true},
+		{ID: "stack:base", Type: "stack", Name: "base", Source: "stacks/base.md"},
+	}, plan.Items)
+
+	t.Run("env", func(t *testing.T) {
+		env := plan.Env("/tmp/plan.json")
+		assert.Contains(t, env, "REGIS3_TARGET=claude")
+		assert.Contains(t, env, "REGIS3_PROJECT_DIR="+projectDir)
+		assert.Contains(t, env, "REGIS3_REGISTRY=/registry")
+		assert.Contains(t, env, "REGIS3_DRY_RUN=1")
+		assert.Contains(t, env, "REGIS3_ITEMS=skill:testing philosophy:clean-code stack:base")
+		assert.Contains(t, env, "REGIS3_PLAN=/tmp/plan.json")
+		assert.NotContains(t, plan.Env(""), "REGIS3_PLAN=")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path, err := plan.Write(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, PlanFile, filepath.Base(path))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var decoded Plan
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, *plan, decoded)
+	})
+}
+
+func TestInstaller_NewPlanDetectsConflicts(t *testing.T) {
+	// A custom target that puts commands and docs in the same directory
+	target := DefaultClaudeTarget()
+	target.Paths["doc"] = PathConfig{Dir: "commands", Pattern: "{name}.md"}
+
+	tests := []struct {
+		name      string
+		items     []*registry.Item
+		installed map[string]string // id -> path
+		want      string
+	}{
+		{
+			name: "no conflict",
+			items: []*registry.Item{
+				{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "deploy"}},
+				{Regis3Meta: registry.Regis3Meta{Type: "doc", Name: "guide"}},
+			},
+		},
+		{
+			name: "two planned items",
+			items: []*registry.Item{
+				{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "deploy"}},
+				{Regis3Meta: registry.Regis3Meta{Type: "doc", Name: "Deploy"}},
+			},
+			want: "command:deploy and doc:Deploy both install to .claude/commands/Deploy.md",
+		},
+		{
+			name: "additional file",
+			items: []*registry.Item{
+				{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "deploy", Files: []string{"guide.md"}}},
+				{Regis3Meta: registry.Regis3Meta{Type: "doc", Name: "guide"}},
+			},
+			want: "command:deploy and doc:guide both install to .claude/commands/guide.md",
+		},
+		{
+			name:      "installed item",
+			items:     []*registry.Item{{Regis3Meta: registry.Regis3Meta{Type: "doc", Name: "deploy"}}},
+			installed: map[string]string{"command:deploy": ".claude/commands/deploy.md"},
+			want:      "command:deploy and doc:deploy both install to .claude/commands/deploy.md",
+		},
+		{
+			name:      "reinstalled item",
+			items:     []*registry.Item{{Regis3Meta: registry.Regis3Meta{Type: "command", Name: "deploy"}}},
+			installed: map[string]string{"command:deploy": ".claude/commands/deploy.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst, err := NewInstaller(t.TempDir(), "/registry", target)
+			require.NoError(t, err)
+			for id, path := range tt.installed {
+				itemType, name, _ := strings.Cut(id, ":")
+				inst.Tracker.MarkInstalled(id, itemType, name, path, false)
+			}
+
+			_, err = inst.NewPlan(tt.items)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var conflict *PathConflictError
+			require.ErrorAs(t, err, &conflict)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
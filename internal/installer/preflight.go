@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// maxNameLength is the longest file name most filesystems accept, in bytes.
+const maxNameLength = 255
+
+// maxPathLength returns the longest absolute path the OS accepts, in bytes.
+func maxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		return 260 // MAX_PATH, unless long paths are enabled
+	case "darwin":
+		return 1024
+	default:
+		return 4096
+	}
+}
+
+// PreflightIssue is a problem found before installing that would make the
+// installation fail partway.
+type PreflightIssue struct {
+	// Item is the item affected, or empty for the installation as a whole.
+	Item string `json:"item,omitempty"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// preflight checks that the planned items can be written: every file's
+// path is within the OS limits, the directories they go to are writable and
+// the filesystem has space for them.
+func (i *Installer) preflight(items []*registry.Item, plan *Plan) []PreflightIssue {
+	defer i.Timings.Start("preflight")()
+
+	var issues []PreflightIssue
+	// Transactions stage files in the project directory
+	dirs := map[string][]string{existingDir(plan.ProjectDir): nil}
+	var size int64
+
+	for n, item := range items {
+		planItem := plan.Items[n]
+		if planItem.Path == "" {
+			continue
+		}
+		size += i.sourceSize(item)
+		if planItem.Merged {
+			continue
+		}
+
+		files := []string{planItem.Path}
+		for _, file := range item.InstallFiles() {
+			files = append(files, filepath.Join(filepath.Dir(planItem.Path), file))
+		}
+		for _, file := range files {
+			path := filepath.Join(plan.ProjectDir, file)
+			if msg := checkPathLength(path); msg != "" {
+				issues = append(issues, PreflightIssue{Item: planItem.ID, Message: msg})
+				continue
+			}
+			dir := existingDir(filepath.Dir(path))
+			if !slices.Contains(dirs[dir], planItem.ID) {
+				dirs[dir] = append(dirs[dir], planItem.ID)
+			}
+		}
+	}
+
+	checked := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		checked = append(checked, dir)
+	}
+	sort.Strings(checked)
+	for _, dir := range checked {
+		if err := checkWritable(dir); err != nil {
+			issues = append(issues, PreflightIssue{
+				Item:    strings.Join(dirs[dir], ", "),
+				Message: fmt.Sprintf("%s is not writable: %v", dir, err),
+			})
+		}
+	}
+
+	if free, ok := freeSpace(existingDir(plan.ProjectDir)); ok && uint64(size) > free {
+		issues = append(issues, PreflightIssue{
+			Message: fmt.Sprintf("not enough disk space: the items need %s, %s is available",
+				registry.FormatSize(int(size)), registry.FormatSize(int(free))),
+		})
+	}
+	return issues
+}
+
+// sourceSize estimates the bytes installing an item writes: its source file
+// and additional files.
+func (i *Installer) sourceSize(item *registry.Item) int64 {
+	var size int64
+	if path, err := pathutil.Join(i.sourceRoot(item), item.Source); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	if len(item.Checksums) > 0 {
+		for _, c := range item.Checksums {
+			size += c.Size
+		}
+		return size
+	}
+	for _, file := range item.Files {
+		path, err := pathutil.Join(i.sourceRoot(item), item.SourceDir, file)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// checkPathLength describes why path exceeds the OS limits, or returns ""
+// if it doesn't.
+func checkPathLength(path string) string {
+	if len(path) > maxPathLength() {
+		return fmt.Sprintf("%s is longer than %d characters", path, maxPathLength())
+	}
+	for _, name := range strings.Split(filepath.ToSlash(path), "/") {
+		if len(name) > maxNameLength {
+			return fmt.Sprintf("file name %s is longer than %d characters", name, maxNameLength)
+		}
+	}
+	return ""
+}
+
+// existingDir returns dir or its closest existing parent, which the
+// installation creates the missing directories in.
+func existingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// checkWritable checks that files can be created in dir by creating one.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".regis3-check-")
+	if err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied")
+		}
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "short", path: "/project/.claude/skills/tool/SKILL.md"},
+		{name: "long file name", path: "/project/" + strings.Repeat("a", 256) + ".md", want: "is longer than 255 characters"},
+		{name: "long path", path: strings.Repeat("/abcdefgh", maxPathLength()/9+1), want: "is longer than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkPathLength(tt.path)
+			if tt.want == "" {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Contains(t, got, tt.want)
+		})
+	}
+}
+
+func TestInstaller_Preflight(t *testing.T) {
+	registryDir := t.TempDir()
+
+	t.Run("long paths fail before writing", func(t *testing.T) {
+		projectDir := t.TempDir()
+		manifest := registry.NewManifest(registryDir)
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "ok", Desc: "OK"},
+			Content:    "# OK",
+			Source:     "skills/ok.md",
+		})
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{
+				Type:  "skill",
+				Name:  "tool",
+				Desc:  "Tool",
+				Files: []string{strings.Repeat("a", 300) + ".md"},
+			},
+			Content: "# Tool",
+			Source:  "skills/tool.md",
+		})
+
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		result, err := inst.Install(manifest, []string{"skill:ok", "skill:tool"})
+		require.NoError(t, err)
+
+		require.Len(t, result.Plan.Preflight, 1)
+		assert.Equal(t, "skill:tool", result.Plan.Preflight[0].Item)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0].Message, "longer than 255 characters")
+		assert.Empty(t, result.Installed)
+		assert.NoDirExists(t, filepath.Join(projectDir, ".claude"))
+	})
+
+	t.Run("unwritable directories fail before writing", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write read-only directories")
+		}
+		projectDir := t.TempDir()
+		claudeDir := filepath.Join(projectDir, ".claude")
+		require.NoError(t, os.MkdirAll(claudeDir, 0555))
+		defer os.Chmod(claudeDir, 0755)
+
+		manifest := registry.NewManifest(registryDir)
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool"},
+			Content:    "# Tool",
+			Source:     "skills/tool.md",
+		})
+
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		result, err := inst.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "skill:tool", result.Errors[0].ItemID)
+		assert.Contains(t, result.Errors[0].Message, "is not writable")
+	})
+
+	t.Run("dry runs report the same problems", func(t *testing.T) {
+		projectDir := t.TempDir()
+		manifest := registry.NewManifest(registryDir)
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{
+				Type:  "skill",
+				Name:  "tool",
+				Desc:  "Tool",
+				Files: []string{strings.Repeat("a", 300) + ".md"},
+			},
+			Content: "# Tool",
+			Source:  "skills/tool.md",
+		})
+
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		inst.DryRun = true
+		result, err := inst.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Empty(t, result.Installed)
+	})
+}
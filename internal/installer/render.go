@@ -0,0 +1,98 @@
+package installer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// NoneTarget is the name of the pseudo-target rendering items as plain
+// markdown, for assistants without file-based configuration.
+const NoneTarget = "none"
+
+// mergeTypeOrder is the order of the merge types in the merge file.
+var mergeTypeOrder = []string{"project", "philosophy", "ruleset"}
+
+// Render concatenates the content of items into one self-contained markdown
+// document, e.g. for pasting into an assistant without file-based
+// configuration. Items are rendered in RenderOrder, each enclosed in
+// regis3:item and regis3:end comments naming it. Content is read from the
+// registry where it isn't loaded yet, and the target's transforms are
+// applied, including InlineFiles; a nil target renders content as is.
+func Render(items []*registry.Item, registryPath string, target *Target) (string, error) {
+	if target == nil {
+		target = &Target{Name: NoneTarget}
+	}
+	transformer := NewTransformer(target)
+
+	rendered := RenderOrder(items)
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- Rendered by regis3 from %d items; edit the registry, not this file -->\n", len(rendered))
+	for _, item := range rendered {
+		if item.Content == "" && item.Source != "" {
+			if err := item.LoadContent(registryPath); err != nil {
+				return "", fmt.Errorf("%s: %w", item.FullName(), err)
+			}
+		}
+		content, err := transformer.Transform(item)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", item.FullName(), err)
+		}
+		if target.InlineFiles && len(item.Files) > 0 && !target.IsMergeType(item.Type) {
+			files, err := item.ExpandFiles(registryPath)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", item.FullName(), err)
+			}
+			if content, _, err = transformer.InlineFiles(item, content, registryPath, files); err != nil {
+				return "", fmt.Errorf("%s: %w", item.FullName(), err)
+			}
+		}
+		b.WriteString("\n" + itemStartMarker(item.FullName()) + "\n")
+		if content != "" {
+			b.WriteString(content + "\n")
+		}
+		b.WriteString(itemEndMarker(item.FullName()) + "\n")
+	}
+	return b.String(), nil
+}
+
+// RenderOrder returns the items Render includes, in order: the merge types
+// first, in merge file order, followed by the other items in the given
+// order. Stacks have no content and are left out.
+func RenderOrder(items []*registry.Item) []*registry.Item {
+	var merged, other []*registry.Item
+	for _, item := range items {
+		switch {
+		case item.Type == "stack":
+		case slices.Contains(mergeTypeOrder, item.Type):
+			merged = append(merged, item)
+		default:
+			other = append(other, item)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		ri, rj := mergeTypeRank(merged[i].Type), mergeTypeRank(merged[j].Type)
+		if ri != rj {
+			return ri < rj
+		}
+		if merged[i].Order != merged[j].Order {
+			return merged[i].Order < merged[j].Order
+		}
+		return merged[i].Name < merged[j].Name
+	})
+
+	return append(merged, other...)
+}
+
+// mergeTypeRank returns the position of a merge type in the merge file.
+func mergeTypeRank(itemType string) int {
+	for i, t := range mergeTypeOrder {
+		if t == itemType {
+			return i
+		}
+	}
+	return len(mergeTypeOrder)
+}
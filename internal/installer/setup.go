@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// ScriptTimeout bounds how long an item's setup script may run.
+const ScriptTimeout = 10 * time.Minute
+
+// runSetupScripts runs the setup scripts of freshly installed items, in
+// install order, from the project directory. Scripts only run when allowed
+// by AllowScripts or ConfirmScript; failures are recorded as item errors.
+func (i *Installer) runSetupScripts(items []*registry.Item, result *InstallResult) {
+	if len(items) == 0 {
+		return
+	}
+
+	// Hand the install plan to the scripts
+	planPath := ""
+	if dir, err := os.MkdirTemp("", "regis3-plan-"); err == nil {
+		defer os.RemoveAll(dir)
+		planPath, _ = result.Plan.Write(dir)
+	}
+
+	for _, item := range items {
+		script, err := pathutil.Join(i.sourceRoot(item), item.SourceDir, item.Setup)
+		if err != nil {
+			result.Errors = append(result.Errors, InstallError{ItemID: item.FullName(), Message: err.Error(), Err: err})
+			continue
+		}
+
+		allowed := i.AllowScripts
+		if !allowed && i.ConfirmScript != nil {
+			allowed, err = i.ConfirmScript(item, script)
+			if err != nil {
+				result.Errors = append(result.Errors, InstallError{ItemID: item.FullName(), Message: err.Error(), Err: err})
+				continue
+			}
+		}
+		if !allowed {
+			result.SkippedScripts = append(result.SkippedScripts, item.FullName())
+			continue
+		}
+
+		if err := i.runScript(item, script, result.Plan.Env(planPath)); err != nil {
+			result.Errors = append(result.Errors, InstallError{
+				ItemID:  item.FullName(),
+				Message: fmt.Sprintf("setup script %s failed: %s", item.Setup, err),
+				Err:     err,
+			})
+			continue
+		}
+		result.Scripts = append(result.Scripts, item.FullName())
+	}
+}
+
+// runScript runs a single setup script with the plan environment.
+func (i *Installer) runScript(item *registry.Item, script string, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ScriptTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if strings.HasSuffix(script, ".sh") {
+		cmd = exec.CommandContext(ctx, "sh", script)
+	} else {
+		cmd = exec.CommandContext(ctx, script)
+	}
+
+	itemPath, _ := i.Target.GetPath(item.Type, item.Name)
+	cmd.Dir = i.ProjectDir
+	cmd.Env = append(append(os.Environ(), env...),
+		"REGIS3_ITEM="+item.FullName(),
+		"REGIS3_ITEM_PATH="+itemPath,
+		"REGIS3_ITEM_SOURCE="+filepath.Dir(script),
+	)
+
+	out := i.ScriptOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	cmd.Stdout, cmd.Stderr = out, out
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", ScriptTimeout)
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstaller_SetupScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("setup scripts use sh")
+	}
+
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "mcp", "scripts"), 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "mcp", "scripts", "setup.sh"),
+		[]byte("echo \"$REGIS3_ITEM $REGIS3_ITEMS\" > setup.out\ncat \"$REGIS3_PLAN\" > plan.out\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "mcp", "scripts", "fail.sh"), []byte("exit 3\n"), 0644))
+
+	newManifest := func(version string, script string) *registry.Manifest {
+		manifest := registry.NewManifest(registryDir)
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "mcp", Name: "github", Desc: "GitHub", Setup: "scripts/" + script},
+			Content:    "{}" + version,
+			Source:     "mcp/github.md",
+			SourceDir:  "mcp",
+		})
+		return manifest
+	}
+
+	t.Run("skipped without permission", func(t *testing.T) {
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+
+		result, err := inst.Install(newManifest("1", "setup.sh"), []string{"mcp:github"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mcp:github"}, result.SkippedScripts)
+		assert.NoFileExists(t, filepath.Join(projectDir, "setup.out"))
+	})
+
+	t.Run("confirmation decides", func(t *testing.T) {
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		var asked string
+		inst.ConfirmScript = func(item *registry.Item, script string) (bool, error) {
+			asked = item.FullName()
+			return false, nil
+		}
+
+		result, err := inst.Install(newManifest("2", "setup.sh"), []string{"mcp:github"})
+		require.NoError(t, err)
+		assert.Equal(t, "mcp:github", asked)
+		assert.Equal(t, []string{"mcp:github"}, result.SkippedScripts)
+	})
+
+	t.Run("runs with the plan when allowed", func(t *testing.T) {
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		inst.AllowScripts = true
+
+		result, err := inst.Install(newManifest("3", "setup.sh"), []string{"mcp:github"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, []string{"mcp:github"}, result.Scripts)
+
+		out, err := os.ReadFile(filepath.Join(projectDir, "setup.out"))
+		require.NoError(t, err)
+		assert.Equal(t, "mcp:github mcp:github", strings.TrimSpace(string(out)))
+
+		plan, err := os.ReadFile(filepath.Join(projectDir, "plan.out"))
+		require.NoError(t, err)
+		assert.Contains(t, string(plan), `"path": ".claude/mcp/github.json"`)
+	})
+
+	t.Run("up to date items don't rerun", func(t *testing.T) {
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		inst.AllowScripts = true
+
+		result, err := inst.Install(newManifest("3", "setup.sh"), []string{"mcp:github"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Scripts)
+	})
+
+	t.Run("failures are item errors", func(t *testing.T) {
+		inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+		require.NoError(t, err)
+		inst.AllowScripts = true
+
+		result, err := inst.Install(newManifest("4", "fail.sh"), []string{"mcp:github"})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0].Message, "setup script scripts/fail.sh failed: exit status 3")
+	})
+}
@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFile is the project spec, in the project root. It declares the items
+// a project should have installed.
+const SpecFile = ".regis3.yaml"
+
+// ErrNoSpec indicates a project without a spec.
+var ErrNoSpec = errors.New("no " + SpecFile + " in the project")
+
+// Spec is the content of a project spec: the desired state Apply reconciles
+// a project to.
+type Spec struct {
+	// Target is the target to install for. Empty means the project's
+	// target.
+	Target string `yaml:"target,omitempty"`
+
+	// Items are the references of the items to install. Their
+	// dependencies are installed too.
+	Items []string `yaml:"items"`
+}
+
+// SpecPath returns the path of a project's spec.
+func SpecPath(projectDir string) string {
+	return filepath.Join(projectDir, SpecFile)
+}
+
+// LoadSpec reads a project's spec, returning ErrNoSpec if there is none.
+// Unknown fields are an error, so a misspelled key isn't ignored.
+func LoadSpec(projectDir string) (*Spec, error) {
+	data, err := os.ReadFile(SpecPath(projectDir))
+	if os.IsNotExist(err) {
+		return nil, ErrNoSpec
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SpecFile, err)
+	}
+
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", SpecFile, err)
+	}
+	for n, ref := range spec.Items {
+		spec.Items[n] = strings.TrimSpace(ref)
+		if spec.Items[n] == "" {
+			return nil, fmt.Errorf("%s: item %d is empty", SpecFile, n+1)
+		}
+	}
+	return &spec, nil
+}
+
+// Apply reconciles the target's items with itemIDs: the listed items are
+// installed or updated to the registry content, like Install does, and
+// marked as installed explicitly, and then installed items that are neither
+// listed nor a dependency of a listed item are removed. If installing
+// fails, nothing is removed. Pinned items stay at their installed content.
+func (i *Installer) Apply(manifest *registry.Manifest, itemIDs []string) (*SyncResult, error) {
+	r := resolver.NewResolverWithOptions(i.Overrides.Apply(manifest), i.ResolverOptions)
+	resolved, err := r.Resolve(itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	if len(resolved.Missing) > 0 {
+		return nil, fmt.Errorf("missing dependencies: %v", resolved.Missing)
+	}
+	wanted := make(map[string]bool, len(resolved.Items))
+	for _, item := range resolved.Items {
+		wanted[item.FullName()] = true
+	}
+
+	// Install makes the same one_of choices without asking again
+	options := i.ResolverOptions
+	defer func() { i.ResolverOptions = options }()
+	choices := make([]string, 0, len(resolved.Choices))
+	for _, choice := range resolved.Choices {
+		choices = append(choices, choice)
+	}
+	sort.Strings(choices)
+	i.ResolverOptions.Preferred = append(append([]string{}, options.Preferred...), choices...)
+	i.ResolverOptions.Choose = nil
+
+	var extra []string
+	for _, id := range i.Tracker.ListInstalled() {
+		if !wanted[id] {
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+
+	explicit := i.Explicit
+	defer func() { i.Explicit = explicit }()
+	i.Explicit = true
+
+	// As in Sync, items are only removed once the install succeeded
+	installed, err := i.Install(manifest, itemIDs)
+	result := &SyncResult{Install: installed}
+	if err != nil || len(installed.Errors) > 0 || len(extra) == 0 {
+		return result, err
+	}
+	result.Uninstall, err = i.Uninstall(extra, manifest)
+	return result, err
+}
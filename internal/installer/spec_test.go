@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Spec
+		wantErr string
+	}{
+		{
+			name:    "items and target",
+			content: "target: cursor\nitems:\n  - skill:testing\n  - stack:base\n",
+			want:    &Spec{Target: "cursor", Items: []string{"skill:testing", "stack:base"}},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    &Spec{},
+		},
+		{
+			name:    "unknown field",
+			content: "itmes:\n  - skill:testing\n",
+			wantErr: "field itmes not found",
+		},
+		{
+			name:    "empty item",
+			content: "items:\n  - skill:testing\n  - \"\"\n",
+			wantErr: "item 2 is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(SpecPath(dir), []byte(tt.content), 0644))
+
+			spec, err := LoadSpec(dir)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, spec)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := LoadSpec(t.TempDir())
+		assert.ErrorIs(t, err, ErrNoSpec)
+	})
+}
+
+func TestInstaller_Apply(t *testing.T) {
+	tmpDir := t.TempDir()
+	registryDir := filepath.Join(tmpDir, "registry")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	manifest := lockTestManifest(registryDir)
+
+	inst, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	result, err := inst.Apply(manifest, []string{"skill:app", "skill:extra"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"skill:app", "skill:base", "skill:extra"}, result.Install.Installed)
+	assert.Nil(t, result.Uninstall)
+
+	t.Run("updates changed items and removes unlisted ones", func(t *testing.T) {
+		changed := lockTestManifest(registryDir)
+		changed.Items["skill:app"].Content = "# App\n\nChanged."
+
+		result, err := inst.Apply(changed, []string{"skill:app"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:app"}, result.Install.Updated)
+		assert.Equal(t, []string{"skill:base"}, result.Install.Skipped)
+		require.NotNil(t, result.Uninstall)
+		assert.Equal(t, []string{"skill:extra"}, result.Uninstall.Uninstalled)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, inst.Tracker.ListInstalled())
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		inst.DryRun = true
+		defer func() { inst.DryRun = false }()
+
+		result, err := inst.Apply(manifest, []string{"skill:extra"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:extra"}, result.Install.Installed)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, result.Uninstall.Uninstalled)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, inst.Tracker.ListInstalled())
+	})
+
+	t.Run("failed install removes nothing", func(t *testing.T) {
+		broken := lockTestManifest(registryDir)
+		broken.Items["skill:extra"].Content = ""
+
+		result, err := inst.Apply(broken, []string{"skill:extra"})
+		require.NoError(t, err)
+		require.Len(t, result.Install.Errors, 1)
+		assert.Nil(t, result.Uninstall)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, inst.Tracker.ListInstalled())
+		assert.FileExists(t, filepath.Join(projectDir, ".claude", "skills", "app", "SKILL.md"))
+	})
+
+	t.Run("missing items fail before changing anything", func(t *testing.T) {
+		_, err := inst.Apply(registry.NewManifest(registryDir), []string{"skill:app"})
+		assert.Error(t, err)
+		assert.ElementsMatch(t, []string{"skill:app", "skill:base"}, inst.Tracker.ListInstalled())
+	})
+}
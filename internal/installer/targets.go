@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -30,6 +32,16 @@ type Target struct {
 
 	// Transforms defines content transformations per type.
 	Transforms map[string]TransformConfig `yaml:"transforms"`
+
+	// InlineFiles appends an item's small text files to its content as
+	// fenced appendices, for tools that read a single file per item.
+	// Files too large or binary to inline are still copied.
+	InlineFiles bool `yaml:"inline_files"`
+
+	// Detect lists further files, or directories ending in a slash, whose
+	// presence shows that a project uses the tool. The base directory and
+	// the merge file always do.
+	Detect []string `yaml:"detect"`
 }
 
 // PathConfig defines the installation path for an item type.
@@ -63,6 +75,11 @@ func (t *Target) GetPath(itemType, name string) (string, error) {
 		return "", fmt.Errorf("unknown item type: %s", itemType)
 	}
 
+	// The name ends up in the path, so it must be a single component
+	if err := pathutil.CheckName(name); err != nil {
+		return "", err
+	}
+
 	// Build path
 	dir := filepath.Join(t.BaseDir, pathCfg.Dir)
 
@@ -75,11 +92,16 @@ func (t *Target) GetPath(itemType, name string) (string, error) {
 	// Replace placeholders
 	filename = replacePlaceholder(filename, "name", name)
 
+	path := filepath.Join(dir, filename)
 	if pathCfg.Subdirs {
-		return filepath.Join(dir, name, filename), nil
+		path = filepath.Join(dir, name, filename)
 	}
 
-	return filepath.Join(dir, filename), nil
+	// Target definitions may come from user files, so check the rendered path too
+	if err := pathutil.CheckRelative(path); err != nil {
+		return "", err
+	}
+	return path, nil
 }
 
 // GetTransform returns the transform config for an item type.
@@ -90,16 +112,95 @@ func (t *Target) GetTransform(itemType string) TransformConfig {
 	return TransformConfig{}
 }
 
-// IsMergeType returns true if this type merges into the merge file.
+// IsMergeType returns true if this type merges into the merge file. The
+// merge types (philosophy, project, ruleset) install as files of their own
+// instead for targets that give them a path.
 func (t *Target) IsMergeType(itemType string) bool {
 	switch itemType {
 	case "philosophy", "project", "ruleset":
-		return true
+		path := t.Paths[itemType]
+		return path.Dir == "" && path.Pattern == ""
 	default:
 		return false
 	}
 }
 
+// TrackerPath returns the path of the target's tracking file in a project.
+// Each target keeps its own tracker in its base directory, so a project can
+// have items installed for several targets; targets installing to the
+// project root share the .claude tracker.
+func (t *Target) TrackerPath(projectDir string) string {
+	return filepath.Join(projectDir, t.trackerDir(), TrackerFile)
+}
+
+// trackerDir returns the directory holding the target's tracker, relative
+// to the project.
+func (t *Target) trackerDir() string {
+	if t.BaseDir == "" || t.BaseDir == "." {
+		return ".claude"
+	}
+	return t.BaseDir
+}
+
+// markers returns the files and directories (ending in a slash) whose
+// presence shows that a project uses the target's tool: its base
+// directory, its merge file and those listed in Detect.
+func (t *Target) markers() []string {
+	var markers []string
+	if t.BaseDir != "" && t.BaseDir != "." {
+		markers = append(markers, filepath.ToSlash(t.BaseDir)+"/")
+	}
+	if t.MergeFile != "" {
+		markers = append(markers, filepath.ToSlash(t.MergeFile))
+	}
+	return append(markers, t.Detect...)
+}
+
+// FindMarker returns the first of the target's markers present in the
+// project, or "" if there is none.
+func (t *Target) FindMarker(projectDir string) string {
+	for _, marker := range t.markers() {
+		info, err := os.Stat(filepath.Join(projectDir, filepath.FromSlash(strings.TrimSuffix(marker, "/"))))
+		if err == nil && info.IsDir() == strings.HasSuffix(marker, "/") {
+			return marker
+		}
+	}
+	return ""
+}
+
+// DetectTarget picks a project's target from the tool directories and
+// files it contains (see Target.Detect), checking candidates in order. A
+// target with a tracker wins, as regis3 already installs there; otherwise
+// the first target with a marker present is chosen, and without either the
+// first candidate. The returned reason explains the choice.
+func DetectTarget(projectDir string, candidates []*Target) (*Target, string) {
+	if len(candidates) == 0 {
+		return nil, "no targets available"
+	}
+
+	for _, t := range candidates {
+		if _, err := os.Stat(t.TrackerPath(projectDir)); err == nil {
+			return t, fmt.Sprintf("items already installed in %s/", t.trackerDir())
+		}
+	}
+
+	var found []*Target
+	var markers []string
+	for _, t := range candidates {
+		if marker := t.FindMarker(projectDir); marker != "" {
+			found = append(found, t)
+			markers = append(markers, marker)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return candidates[0], fmt.Sprintf("no target directory or file found, using %s", candidates[0].Name)
+	case 1:
+		return found[0], fmt.Sprintf("found %s", markers[0])
+	}
+	return found[0], fmt.Sprintf("found %s (also %s; set default_target to choose)", markers[0], strings.Join(markers[1:], ", "))
+}
+
 // replacePlaceholder replaces {key} with value in the pattern.
 func replacePlaceholder(pattern, key, value string) string {
 	placeholder := "{" + key + "}"
@@ -134,6 +235,21 @@ func LoadTargetByName(targetsDir, name string) (*Target, error) {
 	return LoadTarget(path)
 }
 
+// BuiltinTargets returns the targets that ship with regis3, by name.
+func BuiltinTargets() []*Target {
+	return []*Target{DefaultClaudeTarget(), DefaultCodexTarget(), DefaultCursorTarget()}
+}
+
+// BuiltinTarget returns the built-in target with the given name.
+func BuiltinTarget(name string) (*Target, bool) {
+	for _, t := range BuiltinTargets() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
 // DefaultClaudeTarget returns the default Claude Code target configuration.
 func DefaultClaudeTarget() *Target {
 	return &Target{
@@ -209,6 +325,144 @@ func DefaultClaudeTarget() *Target {
 	}
 }
 
+// DefaultCodexTarget returns the target for OpenAI Codex and other
+// assistants reading AGENTS.md: the merge types are merged into AGENTS.md
+// and commands and prompts become Codex prompts in .codex/prompts.
+func DefaultCodexTarget() *Target {
+	return &Target{
+		Name:        "codex",
+		Description: "OpenAI Codex / AGENTS.md target",
+		Version:     "1.0.0",
+		BaseDir:     ".codex",
+		MergeFile:   "AGENTS.md",
+		Paths: map[string]PathConfig{
+			"skill": {
+				Dir:     "skills",
+				Pattern: "SKILL.md",
+				Subdirs: true,
+			},
+			"subagent": {
+				Dir:     "agents",
+				Pattern: "{name}.md",
+			},
+			"command": {
+				Dir:     "prompts",
+				Pattern: "{name}.md",
+			},
+			"prompt": {
+				Dir:     "prompts",
+				Pattern: "{name}.md",
+			},
+			"mcp": {
+				Dir:     "mcp",
+				Pattern: "{name}.json",
+			},
+			"script": {
+				Dir:     "scripts",
+				Pattern: "{name}.sh",
+			},
+			"doc": {
+				Dir:     "docs",
+				Pattern: "{name}.md",
+			},
+			"hook": {
+				Dir:     "hooks",
+				Pattern: "{name}.md",
+			},
+			// Merge types don't have paths - they merge into AGENTS.md
+			"philosophy": {},
+			"project":    {},
+			"ruleset":    {},
+			"stack":      {}, // Stacks are meta-types, no direct installation
+		},
+		Transforms: map[string]TransformConfig{
+			// Codex finds skills by the name and description in their
+			// frontmatter
+			"skill": {
+				StripFrontmatter: true,
+				AddHeader:        "---\nname: {name}\ndescription: {desc}\n---",
+			},
+			"command":    {StripFrontmatter: true},
+			"prompt":     {StripFrontmatter: true},
+			"subagent":   {StripFrontmatter: true},
+			"doc":        {StripFrontmatter: true},
+			"philosophy": {StripFrontmatter: true},
+			"project":    {StripFrontmatter: true},
+			"ruleset":    {StripFrontmatter: true},
+		},
+	}
+}
+
+// cursorRule is the .mdc frontmatter of Cursor rules. Rules that always
+// apply are included in every request; the others are attached by the agent
+// when their description matches the task.
+const cursorRule = "---\ndescription: {desc}\nalwaysApply: %t\n---"
+
+// DefaultCursorTarget returns the Cursor target configuration. Skills and
+// subagents become rules the agent attaches by description, and the merge
+// types become rules that always apply, so there is no merge file. Cursor
+// reads one file per rule, so small additional files are inlined.
+func DefaultCursorTarget() *Target {
+	rule := func(always bool) TransformConfig {
+		return TransformConfig{
+			StripFrontmatter: true,
+			AddHeader:        fmt.Sprintf(cursorRule, always),
+		}
+	}
+	rulePath := PathConfig{Dir: "rules", Pattern: "{name}.mdc"}
+
+	return &Target{
+		Name:        "cursor",
+		Description: "Cursor target",
+		Version:     "1.0.0",
+		BaseDir:     ".cursor",
+		InlineFiles: true,
+		Detect:      []string{".cursorrules"},
+		Paths: map[string]PathConfig{
+			"skill":      rulePath,
+			"subagent":   rulePath,
+			"philosophy": rulePath,
+			"project":    rulePath,
+			"ruleset":    rulePath,
+			"command": {
+				Dir:     "commands",
+				Pattern: "{name}.md",
+			},
+			"mcp": {
+				Dir:     "mcp",
+				Pattern: "{name}.json",
+			},
+			"script": {
+				Dir:     "scripts",
+				Pattern: "{name}.sh",
+			},
+			"doc": {
+				Dir:     "docs",
+				Pattern: "{name}.md",
+			},
+			"hook": {
+				Dir:     "hooks",
+				Pattern: "{name}.md",
+			},
+			"prompt": {
+				Dir:     "prompts",
+				Pattern: "{name}.md",
+			},
+			"stack": {}, // Stacks are meta-types, no direct installation
+		},
+		Transforms: map[string]TransformConfig{
+			"skill":      rule(false),
+			"subagent":   rule(false),
+			"philosophy": rule(true),
+			"project":    rule(true),
+			"ruleset":    rule(true),
+			"command":    {StripFrontmatter: true},
+			"doc":        {StripFrontmatter: true},
+			"prompt":     {StripFrontmatter: true},
+		},
+	}
+}
+
 // ListAvailableTargets returns available target names from a directory.
 func ListAvailableTargets(targetsDir string) ([]string, error) {
 	entries, err := os.ReadDir(targetsDir)
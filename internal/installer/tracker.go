@@ -6,11 +6,18 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
 )
 
 const (
 	// TrackerFile is the name of the installation tracking file.
 	TrackerFile = "installed.json"
+
+	// TrackerVersion is the current tracker file format. Version 2 records
+	// every installed file with its hash, version 3 writes timestamps as
+	// RFC 3339; older trackers are migrated on load.
+	TrackerVersion = "3"
 )
 
 // Tracker tracks installed items in a project.
@@ -20,6 +27,9 @@ type Tracker struct {
 
 	// Data contains the tracking data.
 	Data *TrackerData
+
+	// projectDir is the project the tracked paths are relative to.
+	projectDir string
 }
 
 // TrackerData is the structure of the tracking file.
@@ -68,6 +78,141 @@ type InstalledItem struct {
 
 	// Merged indicates if this was merged into CLAUDE.md.
 	Merged bool `json:"merged,omitempty"`
+
+	// Files are all files written for the item, including additional files.
+	Files []InstalledFile `json:"files,omitempty"`
+
+	// Pinned keeps the item at SourceHash: installs skip newer registry
+	// content until the item is unpinned.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Variant is the variant of the item installed, or empty for the
+	// item's own body.
+	Variant string `json:"variant,omitempty"`
+
+	// Linked is set for items installed in link mode, whose files link to
+	// the registry where possible.
+	Linked bool `json:"linked,omitempty"`
+
+	// Dependency is set for items installed only as dependencies of other
+	// items, which autoremove uninstalls once no explicitly installed item
+	// needs them.
+	Dependency bool `json:"dependency,omitempty"`
+}
+
+// trackerTime is a tracker timestamp. It is written as RFC 3339 and read
+// from the formats earlier versions wrote: RFC 3339 with or without
+// fractional seconds, "2006-01-02 15:04:05", dates, Unix seconds, or
+// empty for unknown.
+type trackerTime time.Time
+
+// trackerTimeLayouts are the text layouts trackerTime reads.
+var trackerTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+func (t trackerTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).Format(time.RFC3339))
+}
+
+func (t *trackerTime) UnmarshalJSON(data []byte) error {
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*t = trackerTime(time.Unix(seconds, 0))
+		return nil
+	}
+
+	var text *string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	if text == nil || *text == "" {
+		*t = trackerTime{}
+		return nil
+	}
+	for _, layout := range trackerTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, *text, time.Local); err == nil {
+			*t = trackerTime(parsed)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp %q", *text)
+}
+
+// MarshalJSON writes the timestamps as RFC 3339.
+func (d TrackerData) MarshalJSON() ([]byte, error) {
+	type plain TrackerData
+	return json.Marshal(struct {
+		plain
+		LastUpdated trackerTime `json:"last_updated"`
+	}{plain(d), trackerTime(d.LastUpdated)})
+}
+
+// UnmarshalJSON reads the timestamps in any format trackerTime accepts.
+func (d *TrackerData) UnmarshalJSON(data []byte) error {
+	type plain TrackerData
+	aux := struct {
+		*plain
+		LastUpdated trackerTime `json:"last_updated"`
+	}{plain: (*plain)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.LastUpdated = time.Time(aux.LastUpdated)
+	return nil
+}
+
+// MarshalJSON writes the timestamps as RFC 3339.
+func (i InstalledItem) MarshalJSON() ([]byte, error) {
+	type plain InstalledItem
+	return json.Marshal(struct {
+		plain
+		InstalledAt trackerTime `json:"installed_at"`
+		UpdatedAt   trackerTime `json:"updated_at"`
+	}{plain(i), trackerTime(i.InstalledAt), trackerTime(i.UpdatedAt)})
+}
+
+// UnmarshalJSON reads the timestamps in any format trackerTime accepts.
+func (i *InstalledItem) UnmarshalJSON(data []byte) error {
+	type plain InstalledItem
+	aux := struct {
+		*plain
+		InstalledAt trackerTime `json:"installed_at"`
+		UpdatedAt   trackerTime `json:"updated_at"`
+	}{plain: (*plain)(i)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	i.InstalledAt = time.Time(aux.InstalledAt)
+	i.UpdatedAt = time.Time(aux.UpdatedAt)
+	return nil
+}
+
+// InstalledFile records a file written to the project.
+type InstalledFile struct {
+	// Path is the file path relative to the project directory.
+	Path string `json:"path"`
+
+	// SHA256 is the hex-encoded digest of the content as installed.
+	SHA256 string `json:"sha256"`
+
+	// Link is the registry file the file is a symbolic link to, for files
+	// installed in link mode.
+	Link string `json:"link,omitempty"`
+}
+
+// Paths returns the project-relative paths of the item's files. Items
+// recorded before file tracking fall back to the installed path.
+func (i *InstalledItem) Paths() []string {
+	if len(i.Files) == 0 {
+		if i.InstalledPath == "" || i.Merged {
+			return nil
+		}
+		return []string{i.InstalledPath}
+	}
+	paths := make([]string, len(i.Files))
+	for n, f := range i.Files {
+		paths[n] = f.Path
+	}
+	return paths
 }
 
 // NewTracker creates a new tracker for a project directory.
@@ -75,11 +220,12 @@ func NewTracker(projectDir, targetName string) *Tracker {
 	return &Tracker{
 		Path: filepath.Join(projectDir, ".claude", TrackerFile),
 		Data: &TrackerData{
-			Version:     "1.0.0",
+			Version:     TrackerVersion,
 			Target:      targetName,
 			LastUpdated: time.Now(),
 			Items:       make(map[string]*InstalledItem),
 		},
+		projectDir: projectDir,
 	}
 }
 
@@ -103,9 +249,41 @@ func (t *Tracker) Load() error {
 		t.Data.Items = make(map[string]*InstalledItem)
 	}
 
+	if t.Data.Version != TrackerVersion {
+		t.migrate()
+	}
+
 	return nil
 }
 
+// migrate upgrades tracker data from version 1, which recorded one path per
+// item, by recording that path as the item's only file with the hash of its
+// current content. Timestamps of older trackers were parsed leniently on
+// load; missing ones are filled in from the others. The result is written
+// with the next save.
+func (t *Tracker) migrate() {
+	for _, item := range t.Data.Items {
+		if item.InstalledAt.IsZero() {
+			item.InstalledAt = item.UpdatedAt
+		}
+		if item.UpdatedAt.IsZero() {
+			item.UpdatedAt = item.InstalledAt
+		}
+
+		if len(item.Files) > 0 || item.Merged || item.InstalledPath == "" {
+			continue
+		}
+		file := InstalledFile{Path: item.InstalledPath}
+		if path, err := pathutil.Join(t.projectDir, item.InstalledPath); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				file.SHA256 = hashContent(string(data))
+			}
+		}
+		item.Files = []InstalledFile{file}
+	}
+	t.Data.Version = TrackerVersion
+}
+
 // Save saves the tracker data to disk.
 func (t *Tracker) Save() error {
 	// Ensure directory exists
@@ -114,11 +292,9 @@ func (t *Tracker) Save() error {
 		return fmt.Errorf("failed to create tracker directory: %w", err)
 	}
 
-	t.Data.LastUpdated = time.Now()
-
-	data, err := json.MarshalIndent(t.Data, "", "  ")
+	data, err := t.Marshal()
 	if err != nil {
-		return fmt.Errorf("failed to marshal tracker data: %w", err)
+		return err
 	}
 
 	if err := os.WriteFile(t.Path, data, 0644); err != nil {
@@ -128,6 +304,29 @@ func (t *Tracker) Save() error {
 	return nil
 }
 
+// snapshot returns a function restoring the tracker data to its current
+// state, so an installation that doesn't complete leaves it unchanged.
+func (t *Tracker) snapshot() func() {
+	data, err := json.Marshal(t.Data)
+	return func() {
+		var saved TrackerData
+		if err == nil && json.Unmarshal(data, &saved) == nil {
+			t.Data = &saved
+		}
+	}
+}
+
+// Marshal stamps the tracker data and encodes it for writing to disk.
+func (t *Tracker) Marshal() ([]byte, error) {
+	t.Data.LastUpdated = time.Now()
+
+	data, err := json.MarshalIndent(t.Data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tracker data: %w", err)
+	}
+	return data, nil
+}
+
 // IsInstalled checks if an item is installed.
 func (t *Tracker) IsInstalled(id string) bool {
 	_, ok := t.Data.Items[id]
@@ -162,6 +361,46 @@ func (t *Tracker) MarkInstalled(id, itemType, name, path string, merged bool) {
 	}
 }
 
+// SetFiles records the files written for an installed item.
+func (t *Tracker) SetFiles(id string, files []InstalledFile) {
+	if item, ok := t.Data.Items[id]; ok {
+		item.Files = files
+	}
+}
+
+// SetVariant records the variant an item was installed with.
+func (t *Tracker) SetVariant(id, variant string) {
+	if item, ok := t.Data.Items[id]; ok {
+		item.Variant = variant
+	}
+}
+
+// SetLinked records whether an item was installed in link mode.
+func (t *Tracker) SetLinked(id string, linked bool) {
+	if item, ok := t.Data.Items[id]; ok {
+		item.Linked = linked
+	}
+}
+
+// SetDependency records whether an item was installed only as a
+// dependency.
+func (t *Tracker) SetDependency(id string, dependency bool) {
+	if item, ok := t.Data.Items[id]; ok {
+		item.Dependency = dependency
+	}
+}
+
+// SetPinned pins or unpins an installed item. It returns false if the item
+// is not installed.
+func (t *Tracker) SetPinned(id string, pinned bool) bool {
+	item, ok := t.Data.Items[id]
+	if !ok {
+		return false
+	}
+	item.Pinned = pinned
+	return true
+}
+
 // MarkUninstalled removes an item from the tracker.
 func (t *Tracker) MarkUninstalled(id string) {
 	delete(t.Data.Items, id)
@@ -223,6 +462,17 @@ func LoadTracker(projectDir, targetName string) (*Tracker, error) {
 	return tracker, nil
 }
 
+// LoadTargetTracker loads or creates the tracker for a target, stored at
+// the target's TrackerPath.
+func LoadTargetTracker(projectDir string, target *Target) (*Tracker, error) {
+	tracker := NewTracker(projectDir, target.Name)
+	tracker.Path = target.TrackerPath(projectDir)
+	if err := tracker.Load(); err != nil {
+		return nil, err
+	}
+	return tracker, nil
+}
+
 // TrackerExists checks if a tracker file exists in the project.
 func TrackerExists(projectDir string) bool {
 	path := filepath.Join(projectDir, ".claude", TrackerFile)
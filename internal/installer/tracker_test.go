@@ -0,0 +1,202 @@
+package installer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_MigratesVersion1(t *testing.T) {
+	projectDir := t.TempDir()
+	skill := filepath.Join(projectDir, ".claude", "skills", "old", "SKILL.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(skill), 0755))
+	require.NoError(t, os.WriteFile(skill, []byte("# Old"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".claude", TrackerFile), []byte(`{
+  "version": "1.0.0",
+  "target": "claude",
+  "items": {
+    "skill:old": {"id": "skill:old", "type": "skill", "name": "old", "installed_path": ".claude/skills/old/SKILL.md"},
+    "philosophy:clean": {"id": "philosophy:clean", "type": "philosophy", "name": "clean", "installed_path": "CLAUDE.md", "merged": true}
+  }
+}`), 0644))
+
+	tracker, err := LoadTargetTracker(projectDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+
+	assert.Equal(t, TrackerVersion, tracker.Data.Version)
+	assert.Equal(t, []InstalledFile{{Path: ".claude/skills/old/SKILL.md", SHA256: hashContent("# Old")}}, tracker.GetInstalled("skill:old").Files)
+	assert.Empty(t, tracker.GetInstalled("philosophy:clean").Files)
+}
+
+func TestTracker_Timestamps(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".claude"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".claude", TrackerFile), []byte(`{
+  "version": "2",
+  "target": "claude",
+  "last_updated": "2024-03-01T10:00:00.123456789+01:00",
+  "items": {
+    "skill:nano": {"id": "skill:nano", "installed_at": "2024-03-01T10:00:00.5Z", "updated_at": "2024-03-02T10:00:00Z"},
+    "skill:plain": {"id": "skill:plain", "installed_at": "2024-03-01 10:00:00", "updated_at": ""},
+    "skill:unix": {"id": "skill:unix", "installed_at": 1709287200},
+    "skill:date": {"id": "skill:date", "updated_at": "2024-03-01"}
+  }
+}`), 0644))
+
+	tracker, err := LoadTargetTracker(projectDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	assert.Equal(t, TrackerVersion, tracker.Data.Version)
+
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	nano := tracker.GetInstalled("skill:nano")
+	assert.True(t, nano.InstalledAt.Equal(day.Add(500*time.Millisecond)))
+	assert.True(t, nano.UpdatedAt.Equal(day.Add(24*time.Hour)))
+
+	plain := tracker.GetInstalled("skill:plain")
+	assert.True(t, plain.InstalledAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)))
+	assert.True(t, plain.UpdatedAt.Equal(plain.InstalledAt), "missing times are filled in")
+
+	assert.True(t, tracker.GetInstalled("skill:unix").InstalledAt.Equal(day))
+	assert.True(t, tracker.GetInstalled("skill:date").InstalledAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)))
+
+	// Saved as RFC 3339
+	data, err := tracker.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"installed_at": "`+day.Add(500*time.Millisecond).Format(time.RFC3339)+`"`)
+	assert.NotContains(t, string(data), ".5Z")
+
+	var reloaded TrackerData
+	require.NoError(t, json.Unmarshal(data, &reloaded))
+	assert.True(t, reloaded.Items["skill:unix"].InstalledAt.Equal(day))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"installed_at": "last tuesday"}`), &InstalledItem{}))
+}
+
+func TestInstaller_TracksEveryFile(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "skills", "refs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "one.txt"), []byte("one"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "skills", "refs", "two.txt"), []byte("two"), 0644))
+
+	item := &registry.Item{
+		Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"one.txt", "refs/two.txt"}},
+		Content:    "# Tool",
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	}
+	manifest := registry.NewManifest(registryDir)
+	manifest.AddItem(item)
+	manifest.ComputeChecksums()
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"skill:tool"})
+	require.NoError(t, err)
+
+	skillDir := filepath.Join(projectDir, ".claude", "skills", "tool")
+	assert.Equal(t, []InstalledFile{
+		{Path: ".claude/skills/tool/SKILL.md", SHA256: hashContent("# Tool")},
+		{Path: ".claude/skills/tool/one.txt", SHA256: hashContent("one")},
+		{Path: ".claude/skills/tool/refs/two.txt", SHA256: hashContent("two")},
+	}, installer.Tracker.GetInstalled("skill:tool").Files)
+
+	t.Run("update removes files no longer installed", func(t *testing.T) {
+		item.Files = []string{"one.txt"}
+		item.Content = "# Tool v2"
+		manifest.ComputeChecksums()
+
+		_, err := installer.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(skillDir, "one.txt"))
+		assert.NoDirExists(t, filepath.Join(skillDir, "refs"))
+		assert.Len(t, installer.Tracker.GetInstalled("skill:tool").Files, 2)
+	})
+
+	t.Run("dry run lists the paths to remove", func(t *testing.T) {
+		installer.DryRun = true
+		defer func() { installer.DryRun = false }()
+
+		result, err := installer.Uninstall([]string{"skill:tool"}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			".claude/skills/tool/SKILL.md",
+			".claude/skills/tool/one.txt",
+			".claude/skills/tool/",
+			".claude/skills/",
+		}, result.Paths)
+		assert.DirExists(t, skillDir)
+	})
+
+	t.Run("uninstall removes every file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(skillDir, "notes.txt"), []byte("mine"), 0644))
+
+		result, err := installer.Uninstall([]string{"skill:tool"}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:tool"}, result.Uninstalled)
+		assert.Equal(t, []string{".claude/skills/tool/SKILL.md", ".claude/skills/tool/one.txt"}, result.Paths)
+		assert.FileExists(t, filepath.Join(skillDir, "notes.txt"))
+
+		// Without the user's file the directories go too
+		require.NoError(t, os.Remove(filepath.Join(skillDir, "notes.txt")))
+		_, err = installer.Install(manifest, []string{"skill:tool"})
+		require.NoError(t, err)
+		result, err = installer.Uninstall([]string{"skill:tool"}, manifest)
+		require.NoError(t, err)
+		assert.Contains(t, result.Paths, ".claude/skills/")
+		assert.NoDirExists(t, skillDir)
+	})
+}
+
+func TestInstaller_PinnedItems(t *testing.T) {
+	registryDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	manifest := registry.NewManifest(registryDir)
+	for _, name := range []string{"pinned", "free"} {
+		manifest.AddItem(&registry.Item{
+			Regis3Meta: registry.Regis3Meta{Type: "skill", Name: name, Desc: "Skill"},
+			Content:    "# v1",
+			Source:     "skills/" + name + ".md",
+		})
+	}
+
+	installer, err := NewInstaller(projectDir, registryDir, DefaultClaudeTarget())
+	require.NoError(t, err)
+	_, err = installer.Install(manifest, []string{"skill:pinned", "skill:free"})
+	require.NoError(t, err)
+
+	assert.True(t, installer.Tracker.SetPinned("skill:pinned", true))
+	assert.False(t, installer.Tracker.SetPinned("skill:missing", true))
+
+	// New registry content
+	for _, item := range manifest.Items {
+		item.Content = "# v2"
+	}
+
+	outdated, pinned := installer.Outdated(manifest)
+	assert.Equal(t, []string{"skill:free"}, outdated)
+	assert.Equal(t, []string{"skill:pinned"}, pinned)
+
+	result, err := installer.Install(manifest, []string{"skill:pinned", "skill:free"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:free"}, result.Updated)
+	assert.Equal(t, []string{"skill:pinned"}, result.Pinned)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, ".claude", "skills", "pinned", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# v1", string(content))
+
+	// Unpinning lets the item update again
+	installer.Tracker.SetPinned("skill:pinned", false)
+	result, err = installer.Install(manifest, []string{"skill:pinned"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:pinned"}, result.Updated)
+}
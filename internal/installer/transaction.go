@@ -0,0 +1,200 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Transaction stages file writes and removals in a temporary directory and
+// applies them together with renames, so a failed installation leaves the
+// project untouched.
+type Transaction struct {
+	// dir is the staging directory. It lives inside the project so that
+	// renames stay on the same filesystem.
+	dir string
+
+	// writes are the staged writes in the order they were added.
+	writes []*stagedWrite
+
+	// byDest indexes staged writes by destination path.
+	byDest map[string]*stagedWrite
+}
+
+// stagedWrite is a single file waiting to be moved into place, or removed.
+type stagedWrite struct {
+	staged string
+	dest   string
+	backup string // previous file moved aside during commit
+	exists bool   // whether dest existed before commit
+	remove bool   // delete dest instead of writing it
+}
+
+// NewTransaction creates a transaction that stages files inside projectDir.
+func NewTransaction(projectDir string) (*Transaction, error) {
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(projectDir, ".regis3-tx-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return &Transaction{
+		dir:    dir,
+		byDest: make(map[string]*stagedWrite),
+	}, nil
+}
+
+// WriteFile stages content to be written to dest with the given permissions on commit.
+// Writing the same destination twice keeps the latest content.
+func (t *Transaction) WriteFile(dest string, content []byte, perm os.FileMode) error {
+	w, ok := t.byDest[dest]
+	if !ok {
+		w = &stagedWrite{
+			staged: filepath.Join(t.dir, fmt.Sprintf("%d", len(t.writes))),
+			dest:   dest,
+		}
+	}
+
+	if err := os.WriteFile(w.staged, content, perm); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", dest, err)
+	}
+	// Apply perm exactly, regardless of umask or a previously staged mode
+	if err := os.Chmod(w.staged, perm); err != nil {
+		return fmt.Errorf("failed to set mode on %s: %w", dest, err)
+	}
+
+	w.remove = false
+
+	if !ok {
+		t.writes = append(t.writes, w)
+		t.byDest[dest] = w
+	}
+	return nil
+}
+
+// Symlink stages a symbolic link to target to be created at dest on commit.
+func (t *Transaction) Symlink(dest, target string) error {
+	w, ok := t.byDest[dest]
+	if !ok {
+		w = &stagedWrite{
+			staged: filepath.Join(t.dir, fmt.Sprintf("%d", len(t.writes))),
+			dest:   dest,
+		}
+	}
+
+	os.Remove(w.staged)
+	if err := os.Symlink(target, w.staged); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", dest, err)
+	}
+
+	w.remove = false
+
+	if !ok {
+		t.writes = append(t.writes, w)
+		t.byDest[dest] = w
+	}
+	return nil
+}
+
+// Remove stages dest for deletion on commit. Directories left empty are
+// removed too. Removing a destination staged for writing cancels the write.
+func (t *Transaction) Remove(dest string) {
+	w, ok := t.byDest[dest]
+	if !ok {
+		w = &stagedWrite{dest: dest}
+		t.writes = append(t.writes, w)
+		t.byDest[dest] = w
+	}
+	w.remove = true
+}
+
+// Len returns the number of staged writes and removals.
+func (t *Transaction) Len() int {
+	return len(t.writes)
+}
+
+// Commit moves all staged files into place. If any move fails, files that
+// were already moved are reverted and the previous contents restored.
+func (t *Transaction) Commit() error {
+	defer t.Discard()
+
+	for n, w := range t.writes {
+		if err := t.apply(w, n); err != nil {
+			t.revert(t.writes[:n])
+			return err
+		}
+	}
+	return nil
+}
+
+// apply moves a single staged file into place, backing up any existing file.
+// Removals only move the existing file aside.
+func (t *Transaction) apply(w *stagedWrite, n int) error {
+	if w.remove {
+		if _, err := os.Lstat(w.dest); err != nil {
+			return nil // already gone
+		}
+		w.backup = filepath.Join(t.dir, fmt.Sprintf("%d.bak", n))
+		if err := os.Rename(w.dest, w.backup); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", w.dest, err)
+		}
+		w.exists = true
+		t.removeEmptyParents(filepath.Dir(w.dest))
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", w.dest, err)
+	}
+
+	// Links are replaced, not written through
+	if _, err := os.Lstat(w.dest); err == nil {
+		w.backup = filepath.Join(t.dir, fmt.Sprintf("%d.bak", n))
+		if err := os.Rename(w.dest, w.backup); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", w.dest, err)
+		}
+		w.exists = true
+	}
+
+	if err := os.Rename(w.staged, w.dest); err != nil {
+		if w.exists {
+			os.Rename(w.backup, w.dest)
+		}
+		return fmt.Errorf("failed to write %s: %w", w.dest, err)
+	}
+	return nil
+}
+
+// revert undoes applied writes in reverse order.
+func (t *Transaction) revert(applied []*stagedWrite) {
+	for n := len(applied) - 1; n >= 0; n-- {
+		w := applied[n]
+		if w.exists {
+			os.MkdirAll(filepath.Dir(w.dest), 0755)
+			os.Rename(w.backup, w.dest)
+		} else {
+			os.Remove(w.dest)
+			t.removeEmptyParents(filepath.Dir(w.dest))
+		}
+	}
+}
+
+// removeEmptyParents removes directories emptied during commit, stopping at
+// the first non-empty directory or the project root.
+func (t *Transaction) removeEmptyParents(dir string) {
+	root := filepath.Dir(t.dir)
+	for dir != root && strings.HasPrefix(dir, root) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// Discard removes the staging directory without applying anything.
+// It is safe to call after Commit.
+func (t *Transaction) Discard() {
+	os.RemoveAll(t.dir)
+}
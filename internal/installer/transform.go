@@ -1,11 +1,16 @@
 package installer
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
 	"github.com/okto-digital/regis3/internal/registry"
 )
 
@@ -43,6 +48,64 @@ func (t *Transformer) Transform(item *registry.Item) (string, error) {
 	return strings.TrimSpace(content), nil
 }
 
+// MaxInlineSize is the largest additional file inlined into an item's
+// content for targets with InlineFiles.
+const MaxInlineSize = 16 << 10
+
+// InlineFiles appends files, relative to the item's source directory under
+// root, to content as appendices: a heading naming each file followed by
+// its content in a code fence. It returns the content and the files left
+// to copy, those larger than MaxInlineSize or not text.
+func (t *Transformer) InlineFiles(item *registry.Item, content, root string, files []string) (string, []string, error) {
+	var b strings.Builder
+	var rest []string
+	for _, file := range files {
+		path, err := pathutil.Join(root, item.SourceDir, file)
+		if err != nil {
+			return "", nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if info.Size() > MaxInlineSize {
+			rest = append(rest, file)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+			rest = append(rest, file)
+			continue
+		}
+		text := strings.TrimRight(string(data), "\n")
+		fence := codeFence(text)
+		lang := strings.TrimPrefix(filepath.Ext(file), ".")
+		fmt.Fprintf(&b, "\n\n### %s\n\n%s%s\n%s\n%s", filepath.ToSlash(file), fence, lang, text, fence)
+	}
+	if b.Len() == 0 {
+		return content, rest, nil
+	}
+	return content + "\n\n## Files" + b.String(), rest, nil
+}
+
+// codeFence returns a backtick fence longer than any backtick run in text,
+// so the text can't close it.
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
 // expandTemplate expands placeholders in a template string.
 func (t *Transformer) expandTemplate(template string, item *registry.Item) string {
 	result := template
@@ -116,35 +179,37 @@ func (m *MergeContent) Add(item *registry.Item, content string) {
 	m.sections[item.Type] = append(m.sections[item.Type], section)
 }
 
-// Generate generates the merged content.
+// Generate generates the merged content: each item enclosed in item and end
+// markers naming it, with the heading of its type in the first item of the
+// type.
 func (m *MergeContent) Generate() string {
-	var result strings.Builder
-
-	// Define section order
-	typeOrder := []string{"project", "philosophy", "ruleset"}
+	var blocks []string
 
-	for _, itemType := range typeOrder {
+	for _, itemType := range mergeTypeOrder {
 		sections, ok := m.sections[itemType]
 		if !ok || len(sections) == 0 {
 			continue
 		}
 
-		// Sort by order
+		// Sort by order, then name, so equal orders merge the same way
+		// every time
 		sort.Slice(sections, func(i, j int) bool {
-			return sections[i].Order < sections[j].Order
+			if sections[i].Order != sections[j].Order {
+				return sections[i].Order < sections[j].Order
+			}
+			return sections[i].Item.Name < sections[j].Item.Name
 		})
 
-		// Write section header
-		result.WriteString(fmt.Sprintf("## %s\n\n", capitalizeFirst(itemType)))
-
-		// Write each item
-		for _, section := range sections {
-			result.WriteString(section.Content)
-			result.WriteString("\n\n")
+		for n, section := range sections {
+			content := strings.Trim(section.Content, "\n")
+			if n == 0 {
+				content = fmt.Sprintf("## %s\n\n%s", capitalizeFirst(itemType), content)
+			}
+			blocks = append(blocks, mergeBlock(section.Item.FullName(), content))
 		}
 	}
 
-	return strings.TrimSpace(result.String())
+	return strings.Join(blocks, "\n\n")
 }
 
 // HasContent returns true if there's content to merge.
@@ -157,51 +222,252 @@ func (m *MergeContent) HasContent() bool {
 	return false
 }
 
-// UpdateExistingFile updates an existing CLAUDE.md with new merged content.
-// It preserves user content outside of managed sections.
-func UpdateExistingFile(existing, newContent string) string {
-	// Markers for managed content
-	startMarker := "<!-- regis3:start -->"
-	endMarker := "<!-- regis3:end -->"
+// Markers of the managed section written before items had their own
+// markers. Files with it are migrated on the next update.
+const (
+	legacyStartMarker = "<!-- regis3:start -->"
+	legacyEndMarker   = "<!-- regis3:end -->"
+)
+
+// itemMarker matches the line starting an item's block in the merge file.
+var itemMarker = regexp.MustCompile(`^<!-- regis3:item (\S+) -->$`)
+
+// itemStartMarker and itemEndMarker enclose an item's content in the merge
+// file and in rendered documents.
+func itemStartMarker(id string) string { return "<!-- regis3:item " + id + " -->" }
+func itemEndMarker(id string) string   { return "<!-- regis3:end " + id + " -->" }
 
-	// Check if file has managed section
-	startIdx := strings.Index(existing, startMarker)
-	endIdx := strings.Index(existing, endMarker)
+// mergeBlock encloses an item's content in its markers.
+func mergeBlock(id, content string) string {
+	return itemStartMarker(id) + "\n" + content + "\n" + itemEndMarker(id)
+}
+
+// mergeSegment is a part of a merge file: an item block, the legacy managed
+// section, or text outside them.
+type mergeSegment struct {
+	id     string // item ID of a block
+	legacy bool   // the legacy managed section
+	text   string // the segment, markers included
+}
 
-	if startIdx == -1 || endIdx == -1 {
-		// No managed section - append at the end
-		if strings.TrimSpace(existing) == "" {
-			return wrapManagedContent(newContent)
+// parseMergeFile splits a merge file into item blocks, the legacy managed
+// section and the text around them. An item marker without its end marker
+// is left as text.
+func parseMergeFile(content string) []mergeSegment {
+	var segments []mergeSegment
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			segments = append(segments, mergeSegment{text: text.String()})
+			text.Reset()
 		}
-		return existing + "\n\n" + wrapManagedContent(newContent)
 	}
 
-	// Replace managed section
-	before := existing[:startIdx]
-	after := existing[endIdx+len(endMarker):]
+	lines := strings.SplitAfter(content, "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		end := ""
+		seg := mergeSegment{}
+		if m := itemMarker.FindStringSubmatch(line); m != nil {
+			seg.id, end = m[1], itemEndMarker(m[1])
+		} else if line == legacyStartMarker {
+			seg.legacy, end = true, legacyEndMarker
+		}
+		last := -1
+		if end != "" {
+			for k := n + 1; k < len(lines); k++ {
+				if strings.TrimSpace(lines[k]) == end {
+					last = k
+					break
+				}
+			}
+		}
+		if last == -1 {
+			text.WriteString(lines[n])
+			continue
+		}
+		flush()
+		seg.text = strings.TrimRight(strings.Join(lines[n:last+1], ""), "\n")
+		segments = append(segments, seg)
+		n = last
+	}
+	flush()
+	return segments
+}
 
-	return before + wrapManagedContent(newContent) + after
+// inner returns the content of a block or the legacy section, without its
+// markers.
+func (s mergeSegment) inner() string {
+	_, rest, _ := strings.Cut(s.text, "\n")
+	if i := strings.LastIndex(rest, "\n"); i >= 0 {
+		rest = rest[:i]
+	} else {
+		rest = ""
+	}
+	return strings.Trim(rest, "\n")
 }
 
-// wrapManagedContent wraps content with regis3 markers.
-func wrapManagedContent(content string) string {
-	return fmt.Sprintf("<!-- regis3:start -->\n%s\n<!-- regis3:end -->", content)
+// joinMergeSegments joins the parts of a merge file, with one blank line
+// between them. Text outside blocks is kept as is, except for blank lines
+// at its ends.
+func joinMergeSegments(parts []string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "\n"); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n\n")
 }
 
-// ExtractManagedContent extracts content between regis3 markers.
-func ExtractManagedContent(content string) string {
-	startMarker := "<!-- regis3:start -->"
-	endMarker := "<!-- regis3:end -->"
+// UpdateExistingFile updates an existing CLAUDE.md with new merged content
+// from Generate. Blocks of items in newContent replace the existing blocks
+// of the same items, the blocks of the items in remove are removed and new
+// items are inserted next to the items they follow. Blocks of other items
+// and content the user added outside the blocks, including between them,
+// are preserved. A legacy managed section is replaced by the blocks.
+func UpdateExistingFile(existing, newContent string, remove ...string) string {
+	var blocks []mergeSegment
+	for _, seg := range parseMergeFile(newContent) {
+		if seg.id != "" {
+			blocks = append(blocks, seg)
+		}
+	}
+	segments := parseMergeFile(existing)
+
+	// Existing blocks of items still merged keep their place; the first
+	// block of an item is its slot, duplicates are dropped. Blocks of items
+	// not in newContent are kept unless removed.
+	wanted := make(map[string]bool)
+	for _, b := range blocks {
+		wanted[b.id] = true
+	}
+	removed := make(map[string]bool)
+	for _, id := range remove {
+		removed[id] = true
+	}
+	slot := make(map[string]int)
+	var slots []int
+	lastKept := -1
+	for n, seg := range segments {
+		switch {
+		case seg.id == "":
+		case wanted[seg.id]:
+			if _, ok := slot[seg.id]; !ok {
+				slot[seg.id] = n
+				slots = append(slots, n)
+			}
+		case !removed[seg.id]:
+			lastKept = n
+		}
+	}
 
-	startIdx := strings.Index(content, startMarker)
-	endIdx := strings.Index(content, endMarker)
+	// Slots are filled in the new order, so a changed order is applied
+	// without moving user content. New blocks follow the block before them,
+	// or precede the first slot. Without slots they follow the last kept
+	// block.
+	fill := make(map[int]string)
+	after := make(map[int][]string)
+	var before, unplaced []string
+	anchor, next := -1, 0
+	for _, b := range blocks {
+		if _, ok := slot[b.id]; ok {
+			anchor = slots[next]
+			fill[anchor] = b.text
+			next++
+			continue
+		}
+		switch {
+		case len(slots) == 0:
+			unplaced = append(unplaced, b.text)
+		case anchor == -1:
+			before = append(before, b.text)
+		default:
+			after[anchor] = append(after[anchor], b.text)
+		}
+	}
 
-	if startIdx == -1 || endIdx == -1 {
-		return ""
+	var parts []string
+	placed := false
+	for n, seg := range segments {
+		switch {
+		case seg.legacy:
+			if !placed {
+				parts = append(parts, unplaced...)
+				placed = true
+			}
+		case seg.id == "":
+			parts = append(parts, seg.text)
+		case !wanted[seg.id] && !removed[seg.id]:
+			parts = append(parts, seg.text)
+			if n == lastKept && !placed {
+				parts = append(parts, unplaced...)
+				placed = true
+			}
+		case fill[n] != "":
+			if len(slots) > 0 && n == slots[0] {
+				parts = append(parts, before...)
+			}
+			parts = append(parts, fill[n])
+			parts = append(parts, after[n]...)
+		}
 	}
+	if !placed {
+		parts = append(parts, unplaced...)
+	}
+	return joinMergeSegments(parts) + "\n"
+}
 
-	managed := content[startIdx+len(startMarker) : endIdx]
-	return strings.TrimSpace(managed)
+// RemoveManagedContent removes the managed blocks, markers included, and a
+// legacy managed section from content. User content around them is kept.
+func RemoveManagedContent(content string) string {
+	segments := parseMergeFile(content)
+	var parts []string
+	for _, seg := range segments {
+		if seg.id == "" && !seg.legacy {
+			parts = append(parts, seg.text)
+		}
+	}
+	if len(parts) == len(segments) {
+		return content
+	}
+	return joinMergeSegments(parts)
+}
+
+// ExtractManagedContent extracts the managed blocks, markers included, and
+// the content of a legacy managed section.
+func ExtractManagedContent(content string) string {
+	var parts []string
+	for _, seg := range parseMergeFile(content) {
+		switch {
+		case seg.legacy:
+			parts = append(parts, seg.inner())
+		case seg.id != "":
+			parts = append(parts, seg.text)
+		}
+	}
+	return strings.TrimSpace(joinMergeSegments(parts))
+}
+
+// legacyManagedContent extracts the content of a legacy managed section.
+func legacyManagedContent(content string) string {
+	for _, seg := range parseMergeFile(content) {
+		if seg.legacy {
+			return seg.inner()
+		}
+	}
+	return ""
+}
+
+// ExtractItemContent extracts the content of an item's block, without its
+// markers. It reports false if content has no block for the item.
+func ExtractItemContent(content, id string) (string, bool) {
+	for _, seg := range parseMergeFile(content) {
+		if seg.id == id {
+			return seg.inner(), true
+		}
+	}
+	return "", false
 }
 
 // ValidateContent checks if content is valid for installation.
@@ -0,0 +1,46 @@
+package installer
+
+import (
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// variant returns the variant of item to install: Installer.Variant if the
+// item defines it, else the variant the item is locked at or was installed
+// with, as long as the item still defines it. An empty string selects the
+// item's own body.
+func (i *Installer) variant(item *registry.Item) string {
+	if i.Variant == registry.DefaultVariant {
+		return ""
+	}
+	if _, ok := item.Variants[i.Variant]; ok {
+		return i.Variant
+	}
+
+	previous := ""
+	if locked, ok := i.locked[item.FullName()]; ok {
+		previous = locked.Variant
+	} else if installed := i.Tracker.GetInstalled(item.FullName()); installed != nil {
+		previous = installed.Variant
+	}
+	if _, ok := item.Variants[previous]; ok {
+		return previous
+	}
+	return ""
+}
+
+// withVariant returns item with its body replaced by that of the variant
+// to install, and the variant. Items installed with their own body are
+// returned as they are.
+func (i *Installer) withVariant(item *registry.Item) (*registry.Item, string, error) {
+	variant := i.variant(item)
+	if variant == "" {
+		return item, "", nil
+	}
+	body, err := item.LoadVariant(i.sourceRoot(item), variant)
+	if err != nil {
+		return nil, "", err
+	}
+	selected := *item
+	selected.Content = body
+	return &selected, variant, nil
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// IconSet selects the symbols used in pretty output and the item picker.
+type IconSet string
+
+const (
+	// IconsUnicode uses symbols such as ✓, ⚠ and →.
+	IconsUnicode IconSet = "unicode"
+
+	// IconsASCII uses plain ASCII replacements, for fonts and terminals
+	// that render the unicode symbols at the wrong width.
+	IconsASCII IconSet = "ascii"
+
+	// IconsNone leaves out icons; bars and rules use ASCII.
+	IconsNone IconSet = "none"
+)
+
+// Icons are the symbols of an icon set.
+type Icons struct {
+	Success  string
+	Error    string
+	Warning  string
+	Info     string
+	Arrow    string
+	Bullet   string
+	Progress string
+	Duration string
+	Ellipsis string
+	Up       string
+	Down     string
+
+	// Rule draws table separators; BarFull and BarEmpty draw progress bars.
+	Rule     string
+	BarFull  string
+	BarEmpty string
+}
+
+var (
+	unicodeIcons = Icons{
+		Success: "✓", Error: "✗", Warning: "⚠", Info: "ℹ",
+		Arrow: "→", Bullet: "•", Progress: "⋯", Duration: "⏱",
+		Ellipsis: "…", Up: "↑", Down: "↓",
+		Rule: "─", BarFull: "█", BarEmpty: "░",
+	}
+	asciiIcons = Icons{
+		Success: "+", Error: "x", Warning: "!", Info: "i",
+		Arrow: "->", Bullet: "*", Progress: "..", Duration: "~",
+		Ellipsis: "...", Up: "up", Down: "down",
+		Rule: "-", BarFull: "#", BarEmpty: ".",
+	}
+	noIcons = Icons{
+		Ellipsis: "...", Up: "up", Down: "down",
+		Rule: "-", BarFull: "#", BarEmpty: ".",
+	}
+)
+
+// Icons returns the symbols of the set. Unknown sets give the unicode
+// symbols.
+func (s IconSet) Icons() Icons {
+	switch s {
+	case IconsASCII:
+		return asciiIcons
+	case IconsNone:
+		return noIcons
+	default:
+		return unicodeIcons
+	}
+}
+
+// ParseIconSet parses an icon set name. "auto" and unknown names detect the
+// set from the terminal.
+func ParseIconSet(s string) IconSet {
+	switch s {
+	case "unicode":
+		return IconsUnicode
+	case "ascii":
+		return IconsASCII
+	case "none":
+		return IconsNone
+	default:
+		return DetectIconSet()
+	}
+}
+
+// DetectIconSet picks the icon set the terminal can display: unicode when
+// the locale uses UTF-8, ASCII for dumb terminals, non-UTF-8 locales and the
+// legacy Windows console.
+func DetectIconSet() IconSet {
+	return detectIconSet(runtime.GOOS, os.Getenv)
+}
+
+func detectIconSet(goos string, getenv func(string) string) IconSet {
+	if getenv("TERM") == "dumb" {
+		return IconsASCII
+	}
+	if goos == "windows" {
+		// Windows Terminal and terminals that identify themselves handle
+		// unicode; the legacy console does not.
+		if getenv("WT_SESSION") != "" || getenv("TERM_PROGRAM") != "" {
+			return IconsUnicode
+		}
+		return IconsASCII
+	}
+
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(env); value != "" {
+			value = strings.ToLower(value)
+			if strings.Contains(value, "utf-8") || strings.Contains(value, "utf8") {
+				return IconsUnicode
+			}
+			return IconsASCII
+		}
+	}
+	// No locale set: macOS terminals still use UTF-8, a bare POSIX
+	// environment may not.
+	if goos == "darwin" {
+		return IconsUnicode
+	}
+	return IconsASCII
+}
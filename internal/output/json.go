@@ -1,9 +1,10 @@
 package output
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
+
+	"github.com/okto-digital/regis3/internal/i18n"
 )
 
 // JSONWriter outputs responses as JSON.
@@ -44,7 +45,7 @@ func (w *JSONWriter) Success(message string) error {
 	resp := &Response{
 		Success: true,
 		Messages: []Message{
-			{Level: LevelSuccess, Text: message},
+			{Level: LevelSuccess, Text: i18n.T(message)},
 		},
 	}
 	return w.writeJSON(w.out, resp)
@@ -55,7 +56,7 @@ func (w *JSONWriter) Info(message string) error {
 	resp := &Response{
 		Success: true,
 		Messages: []Message{
-			{Level: LevelInfo, Text: message},
+			{Level: LevelInfo, Text: i18n.T(message)},
 		},
 	}
 	return w.writeJSON(w.out, resp)
@@ -66,7 +67,7 @@ func (w *JSONWriter) Warning(message string) error {
 	resp := &Response{
 		Success: true,
 		Messages: []Message{
-			{Level: LevelWarning, Text: message},
+			{Level: LevelWarning, Text: i18n.T(message)},
 		},
 	}
 	return w.writeJSON(w.out, resp)
@@ -77,7 +78,7 @@ func (w *JSONWriter) Error(message string) error {
 	resp := &Response{
 		Success: false,
 		Error: &ErrorInfo{
-			Message: message,
+			Message: i18n.T(message),
 		},
 	}
 	return w.writeJSON(w.errOut, resp)
@@ -133,11 +134,9 @@ func (w *JSONWriter) Progress(current, total int, message string) error {
 	return w.writeJSON(w.out, resp)
 }
 
-// writeJSON writes a value as indented JSON.
+// writeJSON writes a value as indented JSON, streaming large lists.
 func (w *JSONWriter) writeJSON(out io.Writer, v interface{}) error {
-	encoder := json.NewEncoder(out)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(v); err != nil {
+	if err := streamJSON(out, v); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 	return nil
@@ -19,6 +19,7 @@ func TestParseFormat(t *testing.T) {
 	}{
 		{"json", FormatJSON},
 		{"quiet", FormatQuiet},
+		{"vscode", FormatVSCode},
 		{"pretty", FormatPretty},
 		{"", FormatPretty},
 		{"unknown", FormatPretty},
@@ -47,6 +48,12 @@ func TestNew(t *testing.T) {
 		assert.True(t, ok)
 	})
 
+	t.Run("vscode", func(t *testing.T) {
+		w := New(FormatVSCode, cfg)
+		_, ok := w.(*VSCodeWriter)
+		assert.True(t, ok)
+	})
+
 	t.Run("pretty", func(t *testing.T) {
 		w := New(FormatPretty, cfg)
 		_, ok := w.(*PrettyWriter)
@@ -156,6 +163,75 @@ func TestJSONWriter_Write(t *testing.T) {
 	assert.Equal(t, "test", result.Command)
 }
 
+func TestJSONWriter_StreamsLikeEncoder(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *Response
+	}{
+		{"empty", &Response{}},
+		{"list", &Response{
+			Success:  true,
+			Command:  "list",
+			Data:     &ListData{Items: []ListItem{{Type: "skill", Name: "a<b>", Tags: []string{"x", "y"}}, {Type: "doc", Name: "c", Size: 12}}, TotalCount: 2},
+			Messages: []Message{{Level: LevelInfo, Text: "Found 2 items"}},
+			Duration: time.Second,
+		}},
+		{"empty list", &Response{Success: true, Data: ListData{Items: []ListItem{}}}},
+		{"nil list", &Response{Success: true, Data: &ListData{}}},
+		{"nested lists", &Response{Data: &AuditData{Items: []AuditItem{{ID: "script:x", Reasons: []string{"script"}}}}}},
+		{"embedded fields", &Response{Data: []struct {
+			ListItem
+			Extra int `json:"extra,string"`
+		}{{ListItem: ListItem{Type: "skill", Name: "a"}, Extra: 1}}}},
+		{"map", &Response{Data: map[string]interface{}{"b": []int{1, 2}, "a": nil}}},
+		{"error", &Response{Error: &ErrorInfo{Code: "E1", Message: "failed", Details: "more"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytes.Buffer
+			enc := json.NewEncoder(&want)
+			enc.SetIndent("", "  ")
+			require.NoError(t, enc.Encode(tt.resp))
+
+			var got bytes.Buffer
+			require.NoError(t, NewJSONWriter(&Config{Output: &got}).Write(tt.resp))
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
+
+func TestJSONWriter_VersionData(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &Config{Output: &buf, ErrOutput: &buf}
+	w := NewJSONWriter(cfg)
+
+	resp := NewResponse("version", VersionData{
+		Version:         "1.2.3",
+		Commit:          "abc1234",
+		BuildTime:       "2025-01-01T00:00:00Z",
+		BuiltBy:         "goreleaser",
+		GoVersion:       "go1.25.0",
+		Platform:        "linux/amd64",
+		ManifestVersion: "1.0.0",
+	})
+	require.NoError(t, w.Write(resp))
+
+	var result struct {
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, map[string]string{
+		"version":          "1.2.3",
+		"commit":           "abc1234",
+		"build_time":       "2025-01-01T00:00:00Z",
+		"built_by":         "goreleaser",
+		"go_version":       "go1.25.0",
+		"platform":         "linux/amd64",
+		"manifest_version": "1.0.0",
+	}, result.Data)
+}
+
 func TestJSONWriter_WriteError(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := &Config{Output: &buf, ErrOutput: &buf}
@@ -350,6 +426,54 @@ func TestPrettyWriter_List(t *testing.T) {
 	assert.Contains(t, output, "•")
 }
 
+func TestPrettyWriter_IconSets(t *testing.T) {
+	tests := []struct {
+		icons IconSet
+		want  string
+	}{
+		{IconsUnicode, "✓ done\n  • item\n"},
+		{IconsASCII, "+ done\n  * item\n"},
+		{IconsNone, "done\n  item\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.icons), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewPrettyWriter(&Config{Output: &buf, ErrOutput: &buf, NoColor: true, Icons: tt.icons})
+
+			require.NoError(t, w.Success("done"))
+			require.NoError(t, w.List([]string{"item"}))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestDetectIconSet(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want IconSet
+	}{
+		{name: "utf-8 locale", goos: "linux", env: map[string]string{"LANG": "en_US.UTF-8"}, want: IconsUnicode},
+		{name: "utf8 spelling", goos: "linux", env: map[string]string{"LC_ALL": "de_DE.utf8"}, want: IconsUnicode},
+		{name: "LC_ALL wins", goos: "linux", env: map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, want: IconsASCII},
+		{name: "posix locale", goos: "linux", env: map[string]string{"LANG": "C"}, want: IconsASCII},
+		{name: "no locale", goos: "linux", want: IconsASCII},
+		{name: "no locale on macOS", goos: "darwin", want: IconsUnicode},
+		{name: "dumb terminal", goos: "linux", env: map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"}, want: IconsASCII},
+		{name: "legacy windows console", goos: "windows", want: IconsASCII},
+		{name: "windows terminal", goos: "windows", env: map[string]string{"WT_SESSION": "1"}, want: IconsUnicode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.want, detectIconSet(tt.goos, getenv))
+		})
+	}
+}
+
 func TestPrettyWriter_WriteResponse(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := &Config{Output: &buf, ErrOutput: &buf, NoColor: true}
@@ -367,6 +491,36 @@ func TestPrettyWriter_WriteResponse(t *testing.T) {
 	assert.Contains(t, output, "warning message")
 }
 
+func TestPrettyWriter_Trends(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &Config{Output: &buf, ErrOutput: &buf, NoColor: true}
+	w := NewPrettyWriter(cfg)
+
+	start := time.Date(2026, 9, 1, 12, 0, 0, 0, time.Local)
+	data := &TrendsData{
+		Builds: []TrendBuild{
+			{Time: start, Items: 10, Warned: 3, Size: 2048, Score: 70},
+			{Time: start.Add(24 * time.Hour), Items: 10, Warned: 2, Size: 2100, Score: 75},
+			{Time: start.Add(48 * time.Hour), Items: 13, Warned: 1, Size: 3072, Score: 82},
+		},
+		Recorded: 5,
+		Types: []TypeTrend{
+			{Type: "skill", First: 6, Last: 9},
+			{Type: "command", First: 4, Last: 4},
+		},
+	}
+	require.NoError(t, w.Write(NewResponse("registry trends", data)))
+
+	output := buf.String()
+	assert.Contains(t, output, "Last 3 of 5 recorded builds:")
+	assert.Contains(t, output, "2026-09-03 12:00  13 (+3)")
+	assert.Contains(t, output, "Since 2026-09-01:")
+	assert.Contains(t, output, "items 10 → 13 (+3)")
+	assert.Contains(t, output, "content size 2.0KB → 3.0KB")
+	assert.Contains(t, output, "skill 6 → 9 (+3)")
+	assert.NotContains(t, output, "command 4", "unchanged types are left out")
+}
+
 func TestPrettyWriter_StripAnsi(t *testing.T) {
 	input := "\x1b[31mred text\x1b[0m"
 	result := stripAnsi(input)
@@ -509,3 +663,57 @@ func TestQuietWriter_Warning_NotVerbose(t *testing.T) {
 	// Should not output warnings when not verbose
 	assert.Empty(t, errBuf.String())
 }
+
+func TestVSCodeWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	var errBuf bytes.Buffer
+	w := NewVSCodeWriter(&Config{Output: &buf, ErrOutput: &errBuf})
+
+	require.NoError(t, w.Write(&Response{
+		Success: false,
+		Data: ValidateData{Issues: []IssueData{
+			{Severity: "error", File: "/reg/skills/a.md", Line: 3, Column: 3, Field: "desc", Message: "required field is missing"},
+			{Severity: "warning", File: "/reg/skills/b.md", Line: 7, Column: 1, Message: "duplicate item"},
+		}},
+	}))
+	require.NoError(t, w.Write(&Response{
+		Success: true,
+		Data: &ListData{Items: []ListItem{
+			{Type: "skill", Name: "a", Desc: "Alpha", File: "/reg/skills/a.md", Line: 4, Column: 3},
+			{Type: "skill", Name: "b"},
+		}},
+	}))
+	require.NoError(t, w.Write(&Response{Success: true, Data: []string{"plain"}}))
+
+	assert.Equal(t, "/reg/skills/a.md:3:3: error: desc: required field is missing\n"+
+		"/reg/skills/b.md:7:1: warning: duplicate item\n"+
+		"/reg/skills/a.md:4:3: info: skill:a - Alpha\n"+
+		"skill:b\n"+
+		"plain\n", buf.String())
+	assert.Empty(t, errBuf.String())
+}
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{30 * time.Hour, "yesterday"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{40 * 24 * time.Hour, "1 month ago"},
+		{200 * 24 * time.Hour, "6 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, TimeAgo(now.Add(-tt.ago), now))
+		})
+	}
+	assert.Equal(t, "at an unknown time", TimeAgo(time.Time{}, now))
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/okto-digital/regis3/internal/i18n"
+	"github.com/okto-digital/regis3/pkg/refs"
 )
 
 // Styles for pretty output
@@ -26,23 +29,38 @@ var (
 	styleMuted   = lipgloss.NewStyle().Foreground(colorMuted)
 	styleAccent  = lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
 	styleBold    = lipgloss.NewStyle().Bold(true)
-
-	// Icons
-	iconSuccess  = styleSuccess.Render("✓")
-	iconError    = styleError.Render("✗")
-	iconWarning  = styleWarning.Render("⚠")
-	iconInfo     = styleInfo.Render("ℹ")
-	iconArrow    = styleMuted.Render("→")
-	iconBullet   = styleMuted.Render("•")
-	iconProgress = styleInfo.Render("⋯")
 )
 
+// noIcon stands in for an empty icon until writeLine removes it together
+// with the space that follows it.
+const noIcon = "\x00"
+
+// renderIcons styles the icons of a set. Empty icons become noIcon.
+func renderIcons(icons Icons) Icons {
+	render := func(style lipgloss.Style, icon string) string {
+		if icon == "" {
+			return noIcon
+		}
+		return style.Render(icon)
+	}
+	icons.Success = render(styleSuccess, icons.Success)
+	icons.Error = render(styleError, icons.Error)
+	icons.Warning = render(styleWarning, icons.Warning)
+	icons.Info = render(styleInfo, icons.Info)
+	icons.Arrow = render(styleMuted, icons.Arrow)
+	icons.Bullet = render(styleMuted, icons.Bullet)
+	icons.Progress = render(styleInfo, icons.Progress)
+	icons.Duration = render(styleMuted, icons.Duration)
+	return icons
+}
+
 // PrettyWriter outputs human-friendly formatted text.
 type PrettyWriter struct {
 	out     io.Writer
 	errOut  io.Writer
 	noColor bool
 	verbose bool
+	icons   Icons
 }
 
 // NewPrettyWriter creates a new pretty writer.
@@ -52,6 +70,7 @@ func NewPrettyWriter(cfg *Config) *PrettyWriter {
 		errOut:  cfg.ErrOutput,
 		noColor: cfg.NoColor,
 		verbose: cfg.Verbose,
+		icons:   renderIcons(cfg.Icons.Icons()),
 	}
 }
 
@@ -87,7 +106,7 @@ func (w *PrettyWriter) Write(resp *Response) error {
 
 	// Write duration if present and verbose
 	if w.verbose && resp.Duration > 0 {
-		w.writeLine(w.out, "%s Completed in %v", styleMuted.Render("⏱"), resp.Duration)
+		w.writeLine(w.out, "%s Completed in %v", w.icons.Duration, resp.Duration)
 	}
 
 	return nil
@@ -104,6 +123,12 @@ func (w *PrettyWriter) writeData(data interface{}) {
 		w.writeBuildData(d)
 	case BuildData:
 		w.writeBuildData(&d)
+	case *BuildAllData:
+		w.writeBuildAllData(d)
+	case BuildAllData:
+		w.writeBuildAllData(&d)
+	case *ImpactData:
+		w.writeImpactData(d)
 	case *InfoData:
 		w.writeInfoData(d)
 	case InfoData:
@@ -112,14 +137,30 @@ func (w *PrettyWriter) writeData(data interface{}) {
 		w.writeInstallData(d)
 	case InstallData:
 		w.writeInstallData(&d)
+	case *TargetsInstallData:
+		w.writeTargetsInstallData(d)
+	case TargetsInstallData:
+		w.writeTargetsInstallData(&d)
+	case *RemoveData:
+		w.writeRemoveData(d)
+	case RemoveData:
+		w.writeRemoveData(&d)
 	case *ValidateData:
 		w.writeValidateData(d)
 	case ValidateData:
 		w.writeValidateData(&d)
+	case *InventoryData:
+		w.writeInventoryData(d)
+	case *MigrateData:
+		w.writeMigrateData(d)
 	case *StatusData:
 		w.writeStatusData(d)
 	case StatusData:
 		w.writeStatusData(&d)
+	case *TargetsStatusData:
+		w.writeTargetsStatusData(d)
+	case TargetsStatusData:
+		w.writeTargetsStatusData(&d)
 	case *ScanData:
 		w.writeScanData(d)
 	case ScanData:
@@ -134,8 +175,53 @@ func (w *PrettyWriter) writeData(data interface{}) {
 		w.writeUpdateData(&d)
 	case *OrphansData:
 		w.writeOrphansData(d)
+	case *QuarantineData:
+		w.writeQuarantineData(d)
 	case OrphansData:
 		w.writeOrphansData(&d)
+	case *SuggestDepsData:
+		w.writeSuggestDepsData(d)
+	case SuggestDepsData:
+		w.writeSuggestDepsData(&d)
+	case *RenderData:
+		// The document is the output, e.g. for -o ASSISTANT.md
+		fmt.Fprint(w.out, d.Content)
+	case *SuggestData:
+		w.writeSuggestData(d)
+	case SuggestData:
+		w.writeSuggestData(&d)
+	case *AuditData:
+		w.writeAuditData(d)
+	case AuditData:
+		w.writeAuditData(&d)
+	case *HealthData:
+		w.writeHealthData(d)
+	case HealthData:
+		w.writeHealthData(&d)
+	case *StaleData:
+		w.writeStaleData(d)
+	case StaleData:
+		w.writeStaleData(&d)
+	case *TrendsData:
+		w.writeTrendsData(d)
+	case TrendsData:
+		w.writeTrendsData(&d)
+	case *UpgradeData:
+		w.writeUpgradeData(d)
+	case UpgradeData:
+		w.writeUpgradeData(&d)
+	case *VersionData:
+		w.writeVersionData(d)
+	case VersionData:
+		w.writeVersionData(&d)
+	case *ReleaseData:
+		w.writeReleaseData(d)
+	case ReleaseData:
+		w.writeReleaseData(&d)
+	case *DoctorData:
+		w.writeDoctorData(d)
+	case DoctorData:
+		w.writeDoctorData(&d)
 	case *ConfigData:
 		w.writeConfigData(d)
 	case ConfigData:
@@ -156,24 +242,80 @@ func (w *PrettyWriter) writeListData(data *ListData) {
 
 	for _, item := range data.Items {
 		typeStyle := w.getTypeStyle(item.Type)
+		if data.Sort == "size" {
+			w.writeLine(w.out, "%8s  %s %s",
+				formatSize(item.Size),
+				typeStyle.Render(item.Type+":"+item.Name),
+				styleMuted.Render(item.Desc))
+			continue
+		}
 		w.writeLine(w.out, "%s %s",
 			typeStyle.Render(item.Type+":"+item.Name),
 			styleMuted.Render(item.Desc))
 	}
 
 	w.writeLine(w.out, "")
-	w.writeLine(w.out, "%s %d items", styleMuted.Render("Total:"), data.TotalCount)
+	w.writeLine(w.out, "%s %d items", styleMuted.Render(i18n.T("Total:")), data.TotalCount)
 }
 
 // writeBuildData writes build response data.
 func (w *PrettyWriter) writeBuildData(data *BuildData) {
 	w.writeLine(w.out, "")
-	w.writeLine(w.out, "%s Build complete", iconSuccess)
+	w.writeLine(w.out, "%s Build complete", w.icons.Success)
 	w.writeLine(w.out, "   Items:    %d", data.ItemCount)
+	if data.Excluded > 0 {
+		w.writeLine(w.out, "   Excluded: %d", data.Excluded)
+	}
+	if len(data.Quarantined) > 0 {
+		w.writeLine(w.out, "   Quarantined: %s", styleWarning.Render(strings.Join(data.Quarantined, ", ")))
+	}
+	if len(data.Updated) > 0 {
+		w.writeLine(w.out, "   Updated:  %s", strings.Join(data.Updated, ", "))
+	}
+	if len(data.Removed) > 0 {
+		w.writeLine(w.out, "   Removed:  %s", strings.Join(data.Removed, ", "))
+	}
+	if data.Health != nil {
+		w.writeLine(w.out, "   Health:   %d/100", *data.Health)
+	}
 	w.writeLine(w.out, "   Path:     %s", data.ManifestPath)
 	w.writeLine(w.out, "   Duration: %s", data.Duration)
 }
 
+// writeBuildAllData writes one line per built registry.
+func (w *PrettyWriter) writeBuildAllData(data *BuildAllData) {
+	nameHeader, itemsHeader, healthHeader := i18n.T("REGISTRY"), i18n.T("ITEMS"), i18n.T("HEALTH")
+	nameWidth := len(nameHeader)
+	for _, r := range data.Registries {
+		nameWidth = max(nameWidth, len(r.Name))
+	}
+	itemsWidth, healthWidth := max(len(itemsHeader), 5), max(len(healthHeader), 7)
+
+	w.writeLine(w.out, "")
+	w.writeLine(w.out, "%s", styleMuted.Render(fmt.Sprintf("%-*s  %-*s  %-*s  %s", nameWidth, nameHeader, itemsWidth, itemsHeader, healthWidth, healthHeader, i18n.T("STATUS"))))
+	for _, r := range data.Registries {
+		health := "-"
+		if r.Health != nil {
+			health = fmt.Sprintf("%d/100", *r.Health)
+		}
+		var status string
+		switch {
+		case r.Error != "":
+			status = styleError.Render(r.Error)
+		case r.Errors > 0:
+			status = styleError.Render(i18n.Sprintf("%d errors", r.Errors))
+		case r.Quarantined > 0:
+			status = styleWarning.Render(i18n.Sprintf("%d quarantined", r.Quarantined))
+		case r.Warnings > 0:
+			status = styleWarning.Render(i18n.Sprintf("%d warnings", r.Warnings))
+		default:
+			status = styleSuccess.Render(i18n.T("ok"))
+		}
+		w.writeLine(w.out, "%-*s  %-*d  %-*s  %s",
+			nameWidth, r.Name, itemsWidth, r.ItemCount, healthWidth, health, status)
+	}
+}
+
 // writeInfoData writes info response data.
 func (w *PrettyWriter) writeInfoData(data *InfoData) {
 	typeStyle := w.getTypeStyle(data.Type)
@@ -183,6 +325,10 @@ func (w *PrettyWriter) writeInfoData(data *InfoData) {
 	w.writeLine(w.out, "%s", data.Desc)
 	w.writeLine(w.out, "")
 
+	if len(data.Aliases) > 0 {
+		w.writeLine(w.out, "Aliases: %s", styleMuted.Render(strings.Join(data.Aliases, ", ")))
+	}
+
 	if len(data.Tags) > 0 {
 		tags := make([]string, len(data.Tags))
 		for i, tag := range data.Tags {
@@ -191,17 +337,35 @@ func (w *PrettyWriter) writeInfoData(data *InfoData) {
 		w.writeLine(w.out, "Tags: %s", strings.Join(tags, " "))
 	}
 
+	if data.DepsQuery != "" {
+		w.writeLine(w.out, "Dependencies matching: %s", styleMuted.Render(data.DepsQuery))
+	}
+
 	if len(data.Dependencies) > 0 {
 		w.writeLine(w.out, "Dependencies:")
 		for _, dep := range data.Dependencies {
-			w.writeLine(w.out, "  %s %s", iconArrow, dep)
+			w.writeLine(w.out, "  %s %s", w.icons.Arrow, dep)
+		}
+	}
+
+	if len(data.Alternatives) > 0 {
+		w.writeLine(w.out, "One of:")
+		for _, alt := range data.Alternatives {
+			w.writeLine(w.out, "  %s %s", w.icons.Arrow, alt)
+		}
+	}
+
+	if len(data.Provides) > 0 {
+		w.writeLine(w.out, "Provides:")
+		for _, p := range data.Provides {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, p)
 		}
 	}
 
 	if len(data.Files) > 0 {
 		w.writeLine(w.out, "Files:")
 		for _, f := range data.Files {
-			w.writeLine(w.out, "  %s %s", iconBullet, f)
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, f)
 		}
 	}
 
@@ -213,23 +377,58 @@ func (w *PrettyWriter) writeInfoData(data *InfoData) {
 // writeInstallData writes install response data.
 func (w *PrettyWriter) writeInstallData(data *InstallData) {
 	if len(data.Installed) > 0 {
-		w.writeLine(w.out, "%s Installed:", iconSuccess)
+		w.writeLine(w.out, "%s Installed:", w.icons.Success)
 		for _, item := range data.Installed {
 			typeStyle := w.getTypeStyle(item.Type)
-			w.writeLine(w.out, "  %s %s", iconArrow, typeStyle.Render(item.Type+":"+item.Name))
+			variant := ""
+			if item.Variant != "" {
+				variant = " " + styleMuted.Render("("+i18n.Sprintf("variant %s", item.Variant)+")")
+			}
+			w.writeLine(w.out, "  %s %s%s", w.icons.Arrow, typeStyle.Render(item.Type+":"+item.Name), variant)
 		}
 	}
 
 	if len(data.Skipped) > 0 {
-		w.writeLine(w.out, "%s Skipped:", iconWarning)
+		w.writeLine(w.out, "%s Skipped:", w.icons.Warning)
 		for _, item := range data.Skipped {
-			w.writeLine(w.out, "  %s %s", iconBullet, styleMuted.Render(item))
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(item))
 		}
 	}
 
 	if data.DryRun {
 		w.writeLine(w.out, "")
-		w.writeLine(w.out, "%s (dry run - no changes made)", styleMuted.Render("Note:"))
+		w.writeLine(w.out, "%s (dry run - no changes made)", styleMuted.Render(i18n.T("Note:")))
+	}
+}
+
+// writeTargetsInstallData writes the install data of each target under its
+// name.
+func (w *PrettyWriter) writeTargetsInstallData(data *TargetsInstallData) {
+	dryRun := false
+	for i, t := range data.Targets {
+		if i > 0 {
+			w.writeLine(w.out, "")
+		}
+		w.writeLine(w.out, "%s", styleBold.Render(t.Target))
+		// The dry run note follows the last target only
+		dryRun = dryRun || t.DryRun
+		t.DryRun = false
+		w.writeInstallData(&t)
+	}
+	if dryRun {
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, "%s (dry run - no changes made)", styleMuted.Render(i18n.T("Note:")))
+	}
+}
+
+// writeRemoveData writes remove response data. Dry runs list the paths that
+// would be removed.
+func (w *PrettyWriter) writeRemoveData(data *RemoveData) {
+	if !data.DryRun || len(data.Paths) == 0 {
+		return
+	}
+	for _, path := range data.Paths {
+		w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(path))
 	}
 }
 
@@ -239,22 +438,75 @@ func (w *PrettyWriter) writeValidateData(data *ValidateData) {
 	w.writeLine(w.out, "Validated %d items", data.ItemCount)
 
 	if data.ErrorCount > 0 {
-		w.writeLine(w.out, "  %s Errors:   %d", iconError, data.ErrorCount)
+		w.writeLine(w.out, "  %s Errors:   %d", w.icons.Error, data.ErrorCount)
 	}
 	if data.WarnCount > 0 {
-		w.writeLine(w.out, "  %s Warnings: %d", iconWarning, data.WarnCount)
+		w.writeLine(w.out, "  %s Warnings: %d", w.icons.Warning, data.WarnCount)
 	}
 	if data.InfoCount > 0 {
-		w.writeLine(w.out, "  %s Info:     %d", iconInfo, data.InfoCount)
+		w.writeLine(w.out, "  %s Info:     %d", w.icons.Info, data.InfoCount)
 	}
 
 	if data.ErrorCount == 0 && data.WarnCount == 0 {
-		w.writeLine(w.out, "  %s All items valid", iconSuccess)
+		w.writeLine(w.out, "  %s All items valid", w.icons.Success)
 	}
 }
 
+// writeMigrateData writes the migrations applied and the diff of each file
+// they rewrite.
+func (w *PrettyWriter) writeMigrateData(data *MigrateData) {
+	for _, m := range data.Migrations {
+		w.writeLine(w.out, "  %s %s", w.icons.Bullet, m)
+	}
+	for _, f := range data.Files {
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, "%s", styleBold.Render(f.Path))
+		for _, line := range strings.Split(f.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				line = styleSuccess.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = styleError.Render(line)
+			default:
+				line = styleMuted.Render(line)
+			}
+			w.writeLine(w.out, "  %s", line)
+		}
+	}
+}
+
+// writeInventoryData writes a project inventory as a table, or its BOM.
+func (w *PrettyWriter) writeInventoryData(data *InventoryData) {
+	if data.BOM != "" {
+		fmt.Fprint(w.out, data.BOM)
+		return
+	}
+	if len(data.Items) == 0 {
+		w.Info("No items installed")
+		return
+	}
+
+	rows := make([][]string, 0, len(data.Items))
+	for _, item := range data.Items {
+		hash := item.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		license := item.License
+		if license == "" {
+			license = "-"
+		}
+		rows = append(rows, []string{item.Target, item.ID, hash, license})
+	}
+	w.writeLine(w.out, "")
+	w.Table([]string{i18n.T("TARGET"), i18n.T("ITEM"), i18n.T("HASH"), i18n.T("LICENSE")}, rows)
+}
+
 // writeStatusData writes status response data.
 func (w *PrettyWriter) writeStatusData(data *StatusData) {
+	if data.TargetReason != "" {
+		w.writeLine(w.out, "Target: %s %s", data.Target, styleMuted.Render("("+data.TargetReason+")"))
+	}
 	if len(data.Items) == 0 {
 		w.Info("No items installed")
 		return
@@ -265,13 +517,88 @@ func (w *PrettyWriter) writeStatusData(data *StatusData) {
 	for _, item := range data.Items {
 		typeStyle := w.getTypeStyle(item.Type)
 		status := ""
+		if item.Merged {
+			status = " " + styleMuted.Render("["+i18n.Sprintf("merged into %s", item.DestPath)+"]")
+		}
 		if item.NeedsUpdate {
-			status = " " + styleWarning.Render("[update available]")
+			status += " " + styleWarning.Render("["+i18n.T("update available")+"]")
+		}
+		if item.Pinned {
+			status += " " + styleMuted.Render("["+i18n.T("pinned")+"]")
+		}
+		if item.Variant != "" {
+			status += " " + styleMuted.Render("["+i18n.Sprintf("variant %s", item.Variant)+"]")
+		}
+		if item.Linked {
+			status += " " + styleMuted.Render("["+i18n.T("linked")+"]")
 		}
-		w.writeLine(w.out, "  %s %s%s",
-			iconBullet,
+		if item.Local {
+			status += " " + styleMuted.Render("["+i18n.T("local override")+"]")
+		}
+		if item.Removed {
+			status = " " + styleWarning.Render("["+i18n.T("removed from registry")+"]")
+		}
+		if item.Drift != "" {
+			status += " " + styleWarning.Render("["+i18n.T(item.Drift)+"]")
+		}
+		installed := i18n.Sprintf("installed %s", TimeAgo(item.InstalledAt, time.Now()))
+		if item.UpdatedAt.Sub(item.InstalledAt) >= time.Minute {
+			installed += ", " + i18n.Sprintf("updated %s", TimeAgo(item.UpdatedAt, time.Now()))
+		}
+		w.writeLine(w.out, "  %s %s%s  %s",
+			w.icons.Bullet,
 			typeStyle.Render(item.Type+":"+item.Name),
-			status)
+			status,
+			styleMuted.Render(installed))
+	}
+}
+
+// writeTargetsStatusData writes the installed items of several targets as one table.
+func (w *PrettyWriter) writeTargetsStatusData(data *TargetsStatusData) {
+	if len(data.Targets) == 0 {
+		w.Info("No items installed")
+		return
+	}
+
+	targetHeader, itemHeader := i18n.T("TARGET"), i18n.T("ITEM")
+	targetWidth, itemWidth := len(targetHeader), len(itemHeader)
+	for _, t := range data.Targets {
+		targetWidth = max(targetWidth, len(t.Target))
+		for _, item := range t.Items {
+			itemWidth = max(itemWidth, len(item.Type)+1+len(item.Name))
+		}
+	}
+
+	w.writeLine(w.out, "")
+	w.writeLine(w.out, "%s", styleMuted.Render(fmt.Sprintf("%-*s  %-*s  %s", targetWidth, targetHeader, itemWidth, itemHeader, i18n.T("STATUS"))))
+	for _, t := range data.Targets {
+		for _, item := range t.Items {
+			var states []string
+			if item.NeedsUpdate {
+				states = append(states, i18n.T("update available"))
+			}
+			if item.Pinned {
+				states = append(states, i18n.T("pinned"))
+			}
+			if item.Local {
+				states = append(states, i18n.T("local override"))
+			}
+			if item.Removed {
+				states = append(states, i18n.T("removed from registry"))
+			}
+			if item.Drift != "" {
+				states = append(states, i18n.T(item.Drift))
+			}
+			status := styleSuccess.Render(i18n.T("ok"))
+			if len(states) > 0 {
+				status = styleWarning.Render(strings.Join(states, ", "))
+			}
+			ref := item.Type + ":" + item.Name
+			w.writeLine(w.out, "%-*s  %s%s  %s",
+				targetWidth, t.Target,
+				w.getTypeStyle(item.Type).Render(ref), strings.Repeat(" ", itemWidth-len(ref)),
+				status)
+		}
 	}
 }
 
@@ -279,61 +606,101 @@ func (w *PrettyWriter) writeStatusData(data *StatusData) {
 func (w *PrettyWriter) writeScanData(data *ScanData) {
 	if len(data.Imported) > 0 {
 		if data.DryRun {
-			w.writeLine(w.out, "%s Would import:", iconInfo)
+			w.writeLine(w.out, "%s Would import:", w.icons.Info)
 		} else {
-			w.writeLine(w.out, "%s Imported:", iconSuccess)
+			w.writeLine(w.out, "%s Imported:", w.icons.Success)
 		}
 		for _, item := range data.Imported {
 			typeStyle := w.getTypeStyle(item.Type)
-			w.writeLine(w.out, "  %s %s", iconArrow, typeStyle.Render(item.Type+":"+item.Name))
+			w.writeLine(w.out, "  %s %s", w.icons.Arrow, typeStyle.Render(item.Type+":"+item.Name))
 		}
 	}
 
 	if len(data.Staged) > 0 {
 		if data.DryRun {
-			w.writeLine(w.out, "%s Would stage:", iconWarning)
+			w.writeLine(w.out, "%s Would stage:", w.icons.Warning)
 		} else {
-			w.writeLine(w.out, "%s Staged (need regis3 frontmatter):", iconWarning)
+			w.writeLine(w.out, "%s Staged (need regis3 frontmatter):", w.icons.Warning)
 		}
 		for _, item := range data.Staged {
-			w.writeLine(w.out, "  %s %s", iconBullet, styleMuted.Render(item.SourcePath))
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(item.SourcePath))
+		}
+	}
+
+	if len(data.Split) > 0 {
+		if data.DryRun {
+			w.writeLine(w.out, "%s Would split into staged items:", w.icons.Info)
+		} else {
+			w.writeLine(w.out, "%s Split into staged items:", w.icons.Warning)
+		}
+		source := ""
+		for _, item := range data.Split {
+			if item.SourcePath != source {
+				source = item.SourcePath
+				w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(source))
+			}
+			typeStyle := w.getTypeStyle(item.Type)
+			w.writeLine(w.out, "    %s %s  %s", w.icons.Arrow, typeStyle.Render(item.Type+":"+item.Name),
+				styleMuted.Render(i18n.Sprintf("\"# %s\", %d lines", item.Section, item.Lines)))
+			if item.Desc != "" {
+				w.writeLine(w.out, "      %s", styleMuted.Render(item.Desc))
+			}
 		}
 	}
 
 	if len(data.Errors) > 0 {
-		w.writeLine(w.out, "%s Errors:", iconError)
+		w.writeLine(w.out, "%s Errors:", w.icons.Error)
 		for _, e := range data.Errors {
-			w.writeLine(w.out, "  %s %s", iconBullet, styleError.Render(e))
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleError.Render(e))
 		}
 	}
 
 	if data.DryRun {
 		w.writeLine(w.out, "")
-		w.writeLine(w.out, "%s (dry run - no changes made)", styleMuted.Render("Note:"))
+		w.writeLine(w.out, "%s (dry run - no changes made)", styleMuted.Render(i18n.T("Note:")))
 	}
 }
 
 // writeImportData writes import response data.
 func (w *PrettyWriter) writeImportData(data *ImportData) {
 	if len(data.Processed) > 0 {
-		w.writeLine(w.out, "%s Processed:", iconSuccess)
+		w.writeLine(w.out, "%s Processed:", w.icons.Success)
 		for _, item := range data.Processed {
 			typeStyle := w.getTypeStyle(item.Type)
-			w.writeLine(w.out, "  %s %s", iconArrow, typeStyle.Render(item.Type+":"+item.Name))
+			w.writeLine(w.out, "  %s %s", w.icons.Arrow, typeStyle.Render(item.Type+":"+item.Name))
 		}
 	}
 
 	if len(data.Pending) > 0 {
-		w.writeLine(w.out, "%s Pending (need regis3 frontmatter):", iconWarning)
+		w.writeLine(w.out, "%s Pending (need regis3 frontmatter):", w.icons.Warning)
 		for _, item := range data.Pending {
-			w.writeLine(w.out, "  %s %s", iconBullet, styleMuted.Render(item.Path))
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(item.Path))
+		}
+	}
+
+	if len(data.Merged) > 0 {
+		w.writeLine(w.out, "%s Merged:", w.icons.Success)
+		for _, item := range data.Merged {
+			into := w.getTypeStyle(refs.TypeOf(item.Into)).Render(item.Into)
+			w.writeLine(w.out, "  %s %s %s %s  %s", w.icons.Bullet, styleMuted.Render(item.Path), w.icons.Arrow, into,
+				styleMuted.Render(i18n.Sprintf("as %s: %s", item.Mode, item.DestPath)))
+		}
+	}
+
+	if len(data.Flagged) > 0 {
+		w.writeLine(w.out, "%s Flagged for review (possible prompt injection):", w.icons.Warning)
+		for _, item := range data.Flagged {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, item.Path)
+			for _, f := range item.Findings {
+				w.writeLine(w.out, "      %s", styleMuted.Render(f))
+			}
 		}
 	}
 
 	if len(data.Errors) > 0 {
-		w.writeLine(w.out, "%s Errors:", iconError)
+		w.writeLine(w.out, "%s Errors:", w.icons.Error)
 		for _, e := range data.Errors {
-			w.writeLine(w.out, "  %s %s", iconBullet, styleError.Render(e))
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleError.Render(e))
 		}
 	}
 }
@@ -341,9 +708,9 @@ func (w *PrettyWriter) writeImportData(data *ImportData) {
 // writeUpdateData writes update response data.
 func (w *PrettyWriter) writeUpdateData(data *UpdateData) {
 	if data.Updated {
-		w.writeLine(w.out, "%s Registry updated", iconSuccess)
+		w.writeLine(w.out, "%s Registry updated", w.icons.Success)
 	} else {
-		w.writeLine(w.out, "%s Already up to date", iconInfo)
+		w.writeLine(w.out, "%s Already up to date", w.icons.Info)
 	}
 	w.writeLine(w.out, "   Items: %d", data.ItemCount)
 }
@@ -351,19 +718,277 @@ func (w *PrettyWriter) writeUpdateData(data *UpdateData) {
 // writeOrphansData writes orphans response data.
 func (w *PrettyWriter) writeOrphansData(data *OrphansData) {
 	if len(data.Orphans) == 0 {
-		w.writeLine(w.out, "%s No orphaned files found", iconSuccess)
+		w.writeLine(w.out, "%s No orphaned files found", w.icons.Success)
 		return
 	}
 
-	w.writeLine(w.out, "%s Found %d orphaned files:", iconWarning, data.Count)
+	w.writeLine(w.out, "%s Found %d orphaned files:", w.icons.Warning, data.Count)
 	for _, orphan := range data.Orphans {
-		w.writeLine(w.out, "  %s %s", iconBullet, styleMuted.Render(orphan.Path))
+		w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(orphan.Path))
 		if orphan.Reason != "" {
 			w.writeLine(w.out, "      %s", styleMuted.Render(orphan.Reason))
 		}
 	}
 }
 
+// writeQuarantineData lists the quarantined items with their errors.
+func (w *PrettyWriter) writeQuarantineData(data *QuarantineData) {
+	if len(data.Items) == 0 {
+		w.writeLine(w.out, "%s No items are quarantined", w.icons.Success)
+		return
+	}
+
+	w.writeLine(w.out, "%s %d items are left out of the manifest:", w.icons.Warning, len(data.Items))
+	for _, item := range data.Items {
+		if item.ID == "" {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, item.Path)
+		} else {
+			typeStyle := w.getTypeStyle(refs.TypeOf(item.ID))
+			w.writeLine(w.out, "  %s %s  %s", w.icons.Bullet, typeStyle.Render(item.ID), styleMuted.Render(item.Path))
+		}
+		for _, e := range item.Errors {
+			w.writeLine(w.out, "      %s", styleError.Render(e))
+		}
+	}
+}
+
+// writeSuggestDepsData writes suggested dependencies.
+func (w *PrettyWriter) writeSuggestDepsData(data *SuggestDepsData) {
+	if len(data.Suggestions) == 0 {
+		w.writeLine(w.out, "%s No missing dependencies found for %s", w.icons.Success, data.Item)
+		return
+	}
+
+	if data.Written {
+		w.writeLine(w.out, "%s Added %d dependencies to %s:", w.icons.Success, len(data.Suggestions), data.Path)
+	} else {
+		w.writeLine(w.out, "%s %s mentions %d items not in deps:", w.icons.Info, data.Item, len(data.Suggestions))
+	}
+	for _, s := range data.Suggestions {
+		typeStyle := w.getTypeStyle(refs.TypeOf(s.Ref))
+		w.writeLine(w.out, "  %s %s %s", w.icons.Bullet, typeStyle.Render(s.Ref), styleMuted.Render(i18n.Sprintf("(mentioned as %q)", s.Mention)))
+	}
+}
+
+// writeSuggestData writes the items recommended for a project.
+func (w *PrettyWriter) writeSuggestData(data *SuggestData) {
+	if len(data.Languages) == 0 && len(data.Frameworks) == 0 {
+		w.writeLine(w.out, "%s No languages or frameworks detected in this project", w.icons.Info)
+		return
+	}
+	if len(data.Languages) > 0 {
+		w.writeLine(w.out, "Languages:  %s", strings.Join(data.Languages, ", "))
+	}
+	if len(data.Frameworks) > 0 {
+		w.writeLine(w.out, "Frameworks: %s", strings.Join(data.Frameworks, ", "))
+	}
+	w.writeLine(w.out, "")
+
+	if len(data.Suggestions) == 0 {
+		w.writeLine(w.out, "%s No registry items match this project", w.icons.Info)
+		return
+	}
+	w.writeLine(w.out, "%s %d registry items match this project:", w.icons.Info, len(data.Suggestions))
+	for _, s := range data.Suggestions {
+		typeStyle := w.getTypeStyle(refs.TypeOf(s.Ref))
+		w.writeLine(w.out, "  %s %s %s", w.icons.Bullet, typeStyle.Render(s.Ref), styleMuted.Render(i18n.Sprintf("(matches %s)", strings.Join(s.Matches, ", "))))
+		if s.Desc != "" {
+			w.writeLine(w.out, "      %s", styleMuted.Render(s.Desc))
+		}
+	}
+}
+
+// writeAuditData writes items with executable content.
+func (w *PrettyWriter) writeAuditData(data *AuditData) {
+	if len(data.Items) == 0 {
+		w.writeLine(w.out, "%s No executable content found in %d items", w.icons.Success, data.ItemsScanned)
+		return
+	}
+
+	w.writeLine(w.out, "%s %d of %d items contain executable content:", w.icons.Warning, data.Count, data.ItemsScanned)
+	for _, item := range data.Items {
+		typeStyle := w.getTypeStyle(refs.TypeOf(item.ID))
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, "%s", typeStyle.Render(item.ID))
+		for _, reason := range item.Reasons {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, reason)
+		}
+		for _, f := range item.Files {
+			w.writeLine(w.out, "    %s  %s  %s", styleMuted.Render(f.SHA256[:12]), styleMuted.Render(f.Modified), f.Path)
+		}
+	}
+}
+
+// writeHealthData writes the registry health score and what lowers it.
+func (w *PrettyWriter) writeHealthData(data *HealthData) {
+	style := styleSuccess
+	switch {
+	case data.Score < 60:
+		style = styleError
+	case data.Score < 80:
+		style = styleWarning
+	}
+	w.writeLine(w.out, "Registry health: %s", style.Render(fmt.Sprintf("%d/100", data.Score)))
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"With validation warnings (%d of %d items):", data.Warned},
+		{"Without tags (%d of %d items):", data.Untagged},
+		{"Without author (%d of %d items):", data.Unowned},
+		{"Stale drafts (%d of %d items):", data.StaleDrafts},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, section.title, len(section.items), data.Items)
+		for _, item := range section.items {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, item)
+		}
+	}
+	if len(data.Orphans) > 0 {
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, "Orphaned files (%d):", len(data.Orphans))
+		for _, path := range data.Orphans {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(path))
+		}
+	}
+	if data.Badge != "" {
+		w.writeLine(w.out, "")
+		w.writeLine(w.out, "%s Wrote badge to %s", w.icons.Success, data.Badge)
+	}
+}
+
+// writeStaleData writes items that haven't changed in a while.
+func (w *PrettyWriter) writeStaleData(data *StaleData) {
+	if len(data.Items) == 0 {
+		w.writeLine(w.out, "%s No stale items (%d items scanned)", w.icons.Success, data.ItemsScanned)
+		return
+	}
+
+	w.writeLine(w.out, "%s %d of %d items unchanged for %d days:", w.icons.Warning, data.Count, data.ItemsScanned, data.Days)
+	for _, item := range data.Items {
+		typeStyle := w.getTypeStyle(refs.TypeOf(item.ID))
+		w.writeLine(w.out, "  %s %s  %s  %s", w.icons.Bullet, typeStyle.Render(item.ID), styleMuted.Render(item.LastChanged), strings.Join(item.Reasons, ", "))
+	}
+}
+
+// writeTrendsData writes the recorded builds as a table, followed by the
+// change from the first to the last.
+func (w *PrettyWriter) writeTrendsData(data *TrendsData) {
+	if len(data.Builds) == 0 {
+		w.Info("No builds recorded yet (run 'regis3 build')")
+		return
+	}
+
+	w.writeLine(w.out, "Last %d of %d recorded builds:", len(data.Builds), data.Recorded)
+	w.writeLine(w.out, "")
+	w.writeLine(w.out, "%s", styleMuted.Render(fmt.Sprintf("%-16s  %-10s  %-6s  %-8s  %s",
+		i18n.T("BUILD"), i18n.T("ITEMS"), i18n.T("WARNED"), i18n.T("SIZE"), i18n.T("SCORE"))))
+	for i, build := range data.Builds {
+		items := fmt.Sprintf("%d", build.Items)
+		if i > 0 && build.Items != data.Builds[i-1].Items {
+			items += fmt.Sprintf(" (%+d)", build.Items-data.Builds[i-1].Items)
+		}
+		w.writeLine(w.out, "%-16s  %-10s  %-6d  %-8s  %d",
+			build.Time.Local().Format("2006-01-02 15:04"), items, build.Warned, formatSize(build.Size), build.Score)
+	}
+
+	if len(data.Builds) < 2 {
+		return
+	}
+	first, last := data.Builds[0], data.Builds[len(data.Builds)-1]
+	w.writeLine(w.out, "")
+	w.writeLine(w.out, "Since %s:", first.Time.Local().Format(time.DateOnly))
+	w.writeLine(w.out, "  %s %s", w.icons.Bullet, i18n.Sprintf("items %d %s %d (%+d)", first.Items, w.icons.Arrow, last.Items, last.Items-first.Items))
+	w.writeLine(w.out, "  %s %s", w.icons.Bullet, i18n.Sprintf("items with warnings %d %s %d", first.Warned, w.icons.Arrow, last.Warned))
+	w.writeLine(w.out, "  %s %s", w.icons.Bullet, i18n.Sprintf("content size %s %s %s", formatSize(first.Size), w.icons.Arrow, formatSize(last.Size)))
+	for _, t := range data.Types {
+		if t.First == t.Last {
+			continue
+		}
+		w.writeLine(w.out, "  %s %s %d %s %d (%+d)", w.icons.Bullet, w.getTypeStyle(t.Type).Render(t.Type), t.First, w.icons.Arrow, t.Last, t.Last-t.First)
+	}
+}
+
+// writeImpactData writes the projects and items a change to an item affects.
+func (w *PrettyWriter) writeImpactData(data *ImpactData) {
+	typeStyle := w.getTypeStyle(refs.TypeOf(data.Item))
+	if len(data.Dependents) == 0 {
+		w.writeLine(w.out, "%s No registry items depend on %s", w.icons.Success, typeStyle.Render(data.Item))
+	} else {
+		w.writeLine(w.out, "%s %d registry items depend on %s:", w.icons.Warning, len(data.Dependents), typeStyle.Render(data.Item))
+		for _, id := range data.Dependents {
+			w.writeLine(w.out, "  %s %s", w.icons.Bullet, w.getTypeStyle(refs.TypeOf(id)).Render(id))
+		}
+	}
+
+	w.writeLine(w.out, "")
+	if len(data.Projects) == 0 {
+		w.writeLine(w.out, "%s No projects affected (%d projects scanned)", w.icons.Success, data.ProjectsScanned)
+		return
+	}
+	w.writeLine(w.out, "%s %d of %d projects affected:", w.icons.Warning, len(data.Projects), data.ProjectsScanned)
+	for _, project := range data.Projects {
+		w.writeLine(w.out, "  %s %s  %s", w.icons.Bullet, project.Path, styleMuted.Render(project.Target))
+		w.writeLine(w.out, "      %s", strings.Join(project.Items, ", "))
+	}
+}
+
+// writeUpgradeData writes upgrade response data.
+func (w *PrettyWriter) writeUpgradeData(data *UpgradeData) {
+	switch {
+	case data.Upgraded:
+		w.writeLine(w.out, "%s Upgraded regis3 %s %s %s", w.icons.Success, data.Current, w.icons.Arrow, data.Latest)
+		w.writeLine(w.out, "   Path: %s", styleMuted.Render(data.Path))
+	case data.UpdateAvailable:
+		w.writeLine(w.out, "%s regis3 %s is available (current: %s)", w.icons.Info, data.Latest, data.Current)
+		if data.ReleaseURL != "" {
+			w.writeLine(w.out, "   %s", styleMuted.Render(data.ReleaseURL))
+		}
+	default:
+		w.writeLine(w.out, "%s regis3 %s is up to date", w.icons.Success, data.Current)
+	}
+}
+
+// writeVersionData writes version response data.
+func (w *PrettyWriter) writeVersionData(data *VersionData) {
+	w.writeLine(w.out, "regis3 %s", data.Version)
+	w.writeLine(w.out, "  Built:    %s", data.BuildTime)
+	w.writeLine(w.out, "  Commit:   %s", data.Commit)
+	w.writeLine(w.out, "  Go:       %s", data.GoVersion)
+	w.writeLine(w.out, "  Platform: %s", data.Platform)
+	w.writeLine(w.out, "  Manifest: %s", data.ManifestVersion)
+	if data.BuiltBy != "manual" {
+		w.writeLine(w.out, "  Built by: %s", data.BuiltBy)
+	}
+}
+
+// writeReleaseData writes release response data.
+func (w *PrettyWriter) writeReleaseData(data *ReleaseData) {
+	w.writeLine(w.out, "%s Generated packaging for regis3 %s", w.icons.Success, data.Version)
+	for _, f := range data.Files {
+		w.writeLine(w.out, "  %s %s", w.icons.Bullet, styleMuted.Render(f))
+	}
+}
+
+// writeDoctorData writes doctor check results.
+func (w *PrettyWriter) writeDoctorData(data *DoctorData) {
+	for _, check := range data.Checks {
+		icon := w.icons.Success
+		switch check.Status {
+		case CheckWarning:
+			icon = w.icons.Warning
+		case CheckError:
+			icon = w.icons.Error
+		}
+		w.writeLine(w.out, "%s %s: %s", icon, styleBold.Render(check.Name), check.Message)
+	}
+}
+
 // writeConfigData writes config response data.
 func (w *PrettyWriter) writeConfigData(data *ConfigData) {
 	w.writeLine(w.out, "Config: %s", styleMuted.Render(data.Path))
@@ -382,7 +1007,7 @@ func (w *PrettyWriter) writeMap(data map[string]interface{}) {
 
 // writeError writes an error.
 func (w *PrettyWriter) writeError(err *ErrorInfo) {
-	w.writeLine(w.errOut, "%s %s", iconError, styleError.Render(err.Message))
+	w.writeLine(w.errOut, "%s %s", w.icons.Error, styleError.Render(err.Message))
 	if err.Details != "" {
 		w.writeLine(w.errOut, "   %s", styleMuted.Render(err.Details))
 	}
@@ -393,31 +1018,31 @@ func (w *PrettyWriter) writeError(err *ErrorInfo) {
 
 // WriteError writes an error response.
 func (w *PrettyWriter) WriteError(err error) error {
-	w.writeLine(w.errOut, "%s %s", iconError, styleError.Render(err.Error()))
+	w.writeLine(w.errOut, "%s %s", w.icons.Error, styleError.Render(err.Error()))
 	return nil
 }
 
 // Success writes a success message.
 func (w *PrettyWriter) Success(message string) error {
-	w.writeLine(w.out, "%s %s", iconSuccess, message)
+	w.writeLine(w.out, "%s %s", w.icons.Success, i18n.T(message))
 	return nil
 }
 
 // Info writes an info message.
 func (w *PrettyWriter) Info(message string) error {
-	w.writeLine(w.out, "%s %s", iconInfo, message)
+	w.writeLine(w.out, "%s %s", w.icons.Info, i18n.T(message))
 	return nil
 }
 
 // Warning writes a warning message.
 func (w *PrettyWriter) Warning(message string) error {
-	w.writeLine(w.out, "%s %s", iconWarning, styleWarning.Render(message))
+	w.writeLine(w.out, "%s %s", w.icons.Warning, styleWarning.Render(i18n.T(message)))
 	return nil
 }
 
 // Error writes an error message.
 func (w *PrettyWriter) Error(message string) error {
-	w.writeLine(w.errOut, "%s %s", iconError, styleError.Render(message))
+	w.writeLine(w.errOut, "%s %s", w.icons.Error, styleError.Render(i18n.T(message)))
 	return nil
 }
 
@@ -450,7 +1075,7 @@ func (w *PrettyWriter) Table(headers []string, rows [][]string) error {
 	// Print separator
 	sepLine := ""
 	for _, width := range widths {
-		sepLine += strings.Repeat("─", width) + "  "
+		sepLine += strings.Repeat(w.icons.Rule, width) + "  "
 	}
 	w.writeLine(w.out, "%s", styleMuted.Render(sepLine))
 
@@ -471,7 +1096,7 @@ func (w *PrettyWriter) Table(headers []string, rows [][]string) error {
 // List writes a bulleted list.
 func (w *PrettyWriter) List(items []string) error {
 	for _, item := range items {
-		w.writeLine(w.out, "  %s %s", iconBullet, item)
+		w.writeLine(w.out, "  %s %s", w.icons.Bullet, item)
 	}
 	return nil
 }
@@ -480,7 +1105,7 @@ func (w *PrettyWriter) List(items []string) error {
 func (w *PrettyWriter) Progress(current, total int, message string) error {
 	percent := float64(current) / float64(total) * 100
 	bar := w.progressBar(current, total, 20)
-	w.writeLine(w.out, "\r%s %s %3.0f%% %s", iconProgress, bar, percent, message)
+	w.writeLine(w.out, "\r%s %s %3.0f%% %s", w.icons.Progress, bar, percent, message)
 	return nil
 }
 
@@ -491,7 +1116,7 @@ func (w *PrettyWriter) progressBar(current, total, width int) string {
 		filled = width
 	}
 
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	bar := strings.Repeat(w.icons.BarFull, filled) + strings.Repeat(w.icons.BarEmpty, width-filled)
 	return styleInfo.Render("[") + bar + styleInfo.Render("]")
 }
 
@@ -529,7 +1154,11 @@ func (w *PrettyWriter) getStatusStyle(status string) lipgloss.Style {
 
 // writeLine writes a formatted line to the writer.
 func (w *PrettyWriter) writeLine(out io.Writer, format string, args ...interface{}) {
-	line := fmt.Sprintf(format, args...)
+	line := i18n.Sprintf(format, args...)
+	if strings.Contains(line, noIcon) {
+		line = strings.ReplaceAll(line, noIcon+" ", "")
+		line = strings.ReplaceAll(line, noIcon, "")
+	}
 	if w.noColor {
 		// Strip ANSI codes if no color
 		line = stripAnsi(line)
@@ -557,3 +1186,15 @@ func stripAnsi(s string) string {
 	}
 	return result.String()
 }
+
+// formatSize formats a byte count for display (e.g. 512B, 2.0KB, 1.5MB).
+func formatSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -48,18 +48,80 @@ func (w *QuietWriter) writeData(data interface{}) {
 	case *BuildData:
 		// Just output the count
 		fmt.Fprintln(w.out, d.ItemCount)
+	case *BuildAllData:
+		for _, r := range d.Registries {
+			fmt.Fprintf(w.out, "%s %d\n", r.Name, r.ItemCount)
+		}
+	case *ImpactData:
+		for i, project := range d.Projects {
+			// A project is listed once per affected target
+			if i == 0 || d.Projects[i-1].Path != project.Path {
+				fmt.Fprintln(w.out, project.Path)
+			}
+		}
 	case *InfoData:
 		fmt.Fprintf(w.out, "%s:%s\n", d.Type, d.Name)
 	case *InstallData:
 		for _, item := range d.Installed {
 			fmt.Fprintf(w.out, "%s:%s\n", item.Type, item.Name)
 		}
+	case *TargetsInstallData:
+		for _, t := range d.Targets {
+			for _, item := range t.Installed {
+				fmt.Fprintf(w.out, "%s %s:%s\n", t.Target, item.Type, item.Name)
+			}
+		}
 	case *ValidateData:
 		if d.ErrorCount == 0 {
 			fmt.Fprintln(w.out, "valid")
 		} else {
 			fmt.Fprintln(w.out, "invalid")
 		}
+	case *PinData:
+		for _, id := range d.Items {
+			fmt.Fprintln(w.out, id)
+		}
+	case *StaleData:
+		for _, item := range d.Items {
+			fmt.Fprintln(w.out, item.ID)
+		}
+	case *HealthData:
+		fmt.Fprintln(w.out, d.Score)
+	case *TrendsData:
+		if len(d.Builds) > 0 {
+			fmt.Fprintln(w.out, d.Builds[len(d.Builds)-1].Items)
+		}
+	case *RenderData:
+		fmt.Fprint(w.out, d.Content)
+	case *InventoryData:
+		for _, item := range d.Items {
+			fmt.Fprintln(w.out, item.ID)
+		}
+	case *MigrateData:
+		for _, f := range d.Files {
+			fmt.Fprintln(w.out, f.Path)
+		}
+	case *SuggestData:
+		for _, s := range d.Suggestions {
+			fmt.Fprintln(w.out, s.Ref)
+		}
+	case *AuditData:
+		for _, item := range d.Items {
+			fmt.Fprintln(w.out, item.ID)
+		}
+	case *SuggestDepsData:
+		for _, s := range d.Suggestions {
+			fmt.Fprintln(w.out, s.Ref)
+		}
+	case *QuarantineData:
+		// Paths, so the files can be passed to an editor
+		for _, item := range d.Items {
+			fmt.Fprintln(w.out, item.Path)
+		}
+	case *VersionData:
+		fmt.Fprintln(w.out, d.Version)
+	case VersionData:
+		fmt.Fprintln(w.out, d.Version)
 	case []string:
 		for _, s := range d {
 			fmt.Fprintln(w.out, s)
@@ -1,8 +1,9 @@
 package output
 
 import (
-	"fmt"
 	"time"
+
+	"github.com/okto-digital/regis3/internal/i18n"
 )
 
 // Response is the standard response structure for all commands.
@@ -152,9 +153,9 @@ func (b *ResponseBuilder) WithData(data interface{}) *ResponseBuilder {
 
 // WithInfo adds an info message with formatting.
 func (b *ResponseBuilder) WithInfo(format string, args ...interface{}) *ResponseBuilder {
-	text := format
+	text := i18n.T(format)
 	if len(args) > 0 {
-		text = fmt.Sprintf(format, args...)
+		text = i18n.Sprintf(format, args...)
 	}
 	b.resp.Messages = append(b.resp.Messages, Message{
 		Level: LevelInfo,
@@ -165,9 +166,9 @@ func (b *ResponseBuilder) WithInfo(format string, args ...interface{}) *Response
 
 // WithWarning adds a warning message with formatting.
 func (b *ResponseBuilder) WithWarning(format string, args ...interface{}) *ResponseBuilder {
-	text := format
+	text := i18n.T(format)
 	if len(args) > 0 {
-		text = fmt.Sprintf(format, args...)
+		text = i18n.Sprintf(format, args...)
 	}
 	b.resp.Messages = append(b.resp.Messages, Message{
 		Level: LevelWarning,
@@ -180,7 +181,7 @@ func (b *ResponseBuilder) WithWarning(format string, args ...interface{}) *Respo
 func (b *ResponseBuilder) WithError(path, message string) *ResponseBuilder {
 	b.resp.Messages = append(b.resp.Messages, Message{
 		Level:   LevelError,
-		Text:    message,
+		Text:    i18n.T(message),
 		Details: path,
 	})
 	return b
@@ -246,6 +247,7 @@ type ListData struct {
 	Items      []ListItem `json:"items"`
 	TotalCount int        `json:"total_count"`
 	Filtered   bool       `json:"filtered,omitempty"`
+	Sort       string     `json:"sort,omitempty"`
 }
 
 // ListItem represents an item in a list.
@@ -254,13 +256,46 @@ type ListItem struct {
 	Name string   `json:"name"`
 	Desc string   `json:"desc"`
 	Tags []string `json:"tags,omitempty"`
+	Size int      `json:"size,omitempty"`
+
+	// License is the item's declared license.
+	License string `json:"license,omitempty"`
+
+	// File, Line and Column locate the item (search results: the matching
+	// field) in the registry.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
 }
 
 // BuildData is the response data for build commands.
 type BuildData struct {
-	ItemCount    int    `json:"item_count"`
-	ManifestPath string `json:"manifest_path"`
-	Duration     string `json:"duration"`
+	ItemCount    int      `json:"item_count"`
+	Excluded     int      `json:"excluded,omitempty"`
+	Quarantined  []string `json:"quarantined,omitempty"`
+	Updated      []string `json:"updated,omitempty"`
+	Removed      []string `json:"removed,omitempty"`
+	ManifestPath string   `json:"manifest_path"`
+	Duration     string   `json:"duration"`
+	Health       *int     `json:"health,omitempty"`
+}
+
+// BuildAllData is the response data for building every configured registry.
+type BuildAllData struct {
+	Registries []RegistryBuild `json:"registries"`
+}
+
+// RegistryBuild is the build result of one registry.
+type RegistryBuild struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ItemCount   int    `json:"item_count"`
+	Errors      int    `json:"errors,omitempty"`
+	Warnings    int    `json:"warnings,omitempty"`
+	Quarantined int    `json:"quarantined,omitempty"`
+	Health      *int   `json:"health,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	Error       string `json:"error,omitempty"`
 }
 
 // InfoData is the response data for info commands.
@@ -269,8 +304,12 @@ type InfoData struct {
 	Name         string   `json:"name"`
 	Desc         string   `json:"desc"`
 	Path         string   `json:"path"`
+	Aliases      []string `json:"aliases,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
 	Dependencies []string `json:"dependencies,omitempty"`
+	DepsQuery    string   `json:"deps_query,omitempty"`
+	Provides     []string `json:"provides,omitempty"`
+	Alternatives []string `json:"alternatives,omitempty"`
 	Files        []string `json:"files,omitempty"`
 }
 
@@ -280,6 +319,60 @@ type InstallData struct {
 	Skipped   []string        `json:"skipped,omitempty"`
 	Target    string          `json:"target"`
 	DryRun    bool            `json:"dry_run,omitempty"`
+
+	// DeprecatedInstalled are the installed items marked deprecated, for
+	// policy checks in CI.
+	DeprecatedInstalled []DeprecatedItem `json:"deprecated_installed,omitempty"`
+
+	// SkipReasons maps skipped items to why they were skipped, when asked
+	// for with --explain-skips.
+	SkipReasons map[string]string `json:"skip_reasons,omitempty"`
+
+	// LocalMerged are updated items whose local changes were merged with
+	// the update, and Conflicts those whose changes conflict with it.
+	LocalMerged []string `json:"local_merged,omitempty"`
+	Conflicts   []string `json:"conflicts,omitempty"`
+
+	// KeptLocal are items whose local changes were kept instead of
+	// updating them.
+	KeptLocal []string `json:"kept_local,omitempty"`
+
+	// Linked are the items linked to their registry files with --link,
+	// and NotLinked maps those copied instead to why.
+	Linked    []string          `json:"linked,omitempty"`
+	NotLinked map[string]string `json:"not_linked,omitempty"`
+}
+
+// WhyNotData is the response data for why-not.
+type WhyNotData struct {
+	Item    string `json:"item"`
+	Target  string `json:"target"`
+	Skipped bool   `json:"skipped"`
+	Reason  string `json:"reason"`
+}
+
+// CryptData is the response data for the crypt commands.
+type CryptData struct {
+	KeyID string `json:"key_id,omitempty"`
+
+	// Key is set only when the command generated a new key.
+	Key       string   `json:"key,omitempty"`
+	Changed   []string `json:"changed"`
+	Encrypted int      `json:"encrypted"`
+	Plain     []string `json:"plain,omitempty"`
+	OtherKey  []string `json:"other_key,omitempty"`
+}
+
+// TargetsInstallData is the response data for installing to several
+// targets at once.
+type TargetsInstallData struct {
+	Targets []InstallData `json:"targets"`
+}
+
+// DeprecatedItem is a deprecated item and the item replacing it, if named.
+type DeprecatedItem struct {
+	ID         string `json:"id"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
 }
 
 // InstalledItem represents an installed item.
@@ -287,6 +380,7 @@ type InstalledItem struct {
 	Type     string `json:"type"`
 	Name     string `json:"name"`
 	DestPath string `json:"dest_path"`
+	Variant  string `json:"variant,omitempty"`
 }
 
 // RemoveData is the response data for remove commands.
@@ -294,35 +388,134 @@ type RemoveData struct {
 	Removed  []InstalledItem `json:"removed"`
 	NotFound []string        `json:"not_found,omitempty"`
 	DryRun   bool            `json:"dry_run,omitempty"`
+
+	// Paths are the project-relative files and directories removed, or
+	// that would be removed in a dry run. Directories end in a slash.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// PinData is the response data for project pin and unpin.
+type PinData struct {
+	// Items are the pinned or unpinned items.
+	Items []string `json:"items"`
+
+	// Pinned is true for pin, false for unpin.
+	Pinned bool `json:"pinned"`
+
+	// NotInstalled are references to items not installed in the project.
+	NotInstalled []string `json:"not_installed,omitempty"`
+}
+
+// MigrateData is the response data for registry migrate.
+type MigrateData struct {
+	// From and To are the schema versions before and after the migrations.
+	From int `json:"from"`
+	To   int `json:"to"`
+
+	// Migrations describe the migrations applied, by version.
+	Migrations []string `json:"migrations"`
+
+	Files  []MigrateFile `json:"files"`
+	DryRun bool          `json:"dry_run,omitempty"`
+}
+
+// MigrateFile is an item file rewritten by the migrations.
+type MigrateFile struct {
+	Path string `json:"path"`
+
+	// Diff is the change to the file's frontmatter, as from
+	// installer.LineDiff.
+	Diff string `json:"diff"`
+}
+
+// InventoryData is the response data for project inventory.
+type InventoryData struct {
+	Project string          `json:"project"`
+	Items   []InventoryItem `json:"items"`
+
+	// BOM is the inventory as a CycloneDX document, written as is instead
+	// of the items when set.
+	BOM string `json:"-"`
+}
+
+// InventoryItem is an installed item in a project inventory.
+type InventoryItem struct {
+	ID       string   `json:"id"`
+	Target   string   `json:"target"`
+	Version  string   `json:"version,omitempty"`
+	Hash     string   `json:"hash,omitempty"`
+	License  string   `json:"license,omitempty"`
+	Variant  string   `json:"variant,omitempty"`
+	Registry string   `json:"registry,omitempty"`
+	Files    []string `json:"files,omitempty"`
 }
 
 // StatusData is the response data for status commands.
 type StatusData struct {
 	Items  []StatusItem `json:"items"`
 	Target string       `json:"target"`
+
+	// TargetReason explains why the target was chosen.
+	TargetReason string `json:"target_reason,omitempty"`
 }
 
 // StatusItem represents an installed item's status.
 type StatusItem struct {
-	Type        string `json:"type"`
-	Name        string `json:"name"`
-	InstalledAt string `json:"installed_at"`
-	DestPath    string `json:"dest_path"`
-	NeedsUpdate bool   `json:"needs_update,omitempty"`
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	InstalledAt time.Time `json:"installed_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	DestPath    string    `json:"dest_path"`
+	Merged      bool      `json:"merged,omitempty"`
+	NeedsUpdate bool      `json:"needs_update,omitempty"`
+	Pinned      bool      `json:"pinned,omitempty"`
+	Variant     string    `json:"variant,omitempty"`
+	Linked      bool      `json:"linked,omitempty"`
+	Local       bool      `json:"local,omitempty"`
+	Removed     bool      `json:"removed,omitempty"`
+	Drift       string    `json:"drift,omitempty"`
+}
+
+// TargetsStatusData is the response data for status across all targets.
+type TargetsStatusData struct {
+	Targets []StatusData `json:"targets"`
 }
 
 // ValidateData is the response data for validate commands.
 type ValidateData struct {
-	ItemCount  int `json:"item_count"`
-	ErrorCount int `json:"error_count"`
-	WarnCount  int `json:"warn_count"`
-	InfoCount  int `json:"info_count"`
+	ItemCount  int         `json:"item_count"`
+	ErrorCount int         `json:"error_count"`
+	WarnCount  int         `json:"warn_count"`
+	InfoCount  int         `json:"info_count"`
+	Issues     []IssueData `json:"issues,omitempty"`
+}
+
+// IssueData is a validation issue, located in its file so editors can jump
+// to it.
+type IssueData struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+
+	// Cycle lists the items of a circular dependency, the first repeated
+	// at the end.
+	Cycle []string `json:"cycle,omitempty"`
+
+	// Item and Dependency name the item with a dependency that can't be
+	// resolved, and the dependency.
+	Item       string `json:"item,omitempty"`
+	Dependency string `json:"dependency,omitempty"`
 }
 
 // ScanData is the response data for scan commands.
 type ScanData struct {
 	Imported []ImportedItem `json:"imported"`
 	Staged   []ImportedItem `json:"staged"`
+	Split    []ImportedItem `json:"split,omitempty"`
 	Errors   []string       `json:"errors,omitempty"`
 	DryRun   bool           `json:"dry_run,omitempty"`
 }
@@ -333,20 +526,40 @@ type ImportedItem struct {
 	DestPath   string `json:"dest_path"`
 	Type       string `json:"type"`
 	Name       string `json:"name"`
+	Desc       string `json:"desc,omitempty"`
+	Section    string `json:"section,omitempty"`
+	Lines      int    `json:"lines,omitempty"`
 }
 
 // ImportData is the response data for import commands.
 type ImportData struct {
 	Processed []ImportedItem `json:"processed"`
 	Pending   []PendingItem  `json:"pending"`
+	Flagged   []FlaggedItem  `json:"flagged,omitempty"`
+	Merged    []MergedItem   `json:"merged,omitempty"`
 	Errors    []string       `json:"errors,omitempty"`
 }
 
+// MergedItem represents a staged file merged into an existing item.
+type MergedItem struct {
+	Path     string `json:"path"`
+	Into     string `json:"into"`
+	Mode     string `json:"mode"`
+	DestPath string `json:"dest_path"`
+}
+
+// FlaggedItem represents a staged file held back for manual review.
+type FlaggedItem struct {
+	Path     string   `json:"path"`
+	Findings []string `json:"findings"`
+}
+
 // PendingItem represents a file pending in staging.
 type PendingItem struct {
 	Path          string `json:"path"`
 	SuggestedType string `json:"suggested_type"`
 	SuggestedName string `json:"suggested_name"`
+	SuggestedDesc string `json:"suggested_desc,omitempty"`
 	Confidence    int    `json:"confidence"`
 }
 
@@ -357,6 +570,18 @@ type UpdateData struct {
 	GitOutput string `json:"git_output,omitempty"`
 }
 
+// RegistryAddData is the response data for the registry add command.
+type RegistryAddData struct {
+	Name string `json:"name"`
+
+	// Location is the path or git URL the registry was added as; Path is
+	// the local directory it is read from.
+	Location  string `json:"location"`
+	Path      string `json:"path"`
+	Remote    bool   `json:"remote,omitempty"`
+	ItemCount int    `json:"item_count"`
+}
+
 // OrphansData is the response data for orphans commands.
 type OrphansData struct {
 	Orphans []OrphanFile `json:"orphans"`
@@ -370,6 +595,203 @@ type OrphanFile struct {
 	Reason string `json:"reason"`
 }
 
+// QuarantineData is the response data for the quarantine command.
+type QuarantineData struct {
+	Items []QuarantinedItem `json:"items"`
+}
+
+// QuarantinedItem is an item left out of the manifest because of
+// validation errors.
+type QuarantinedItem struct {
+	// ID is empty for a file that couldn't be parsed into an item.
+	ID     string   `json:"id,omitempty"`
+	Path   string   `json:"path"`
+	Errors []string `json:"errors"`
+}
+
+// SuggestDepsData is the response data for the suggest-deps command.
+type SuggestDepsData struct {
+	Item        string          `json:"item"`
+	Path        string          `json:"path"`
+	Suggestions []DepSuggestion `json:"suggestions"`
+	Written     bool            `json:"written"`
+}
+
+// DepSuggestion is a proposed dependency found in an item's content.
+type DepSuggestion struct {
+	Ref     string `json:"ref"`
+	Mention string `json:"mention"`
+}
+
+// RenderData is the response data for the project render command.
+type RenderData struct {
+	Target  string   `json:"target"`
+	Items   []string `json:"items"`
+	Content string   `json:"content"`
+}
+
+// SuggestData is the response data for the suggest command.
+type SuggestData struct {
+	Languages   []string         `json:"languages"`
+	Frameworks  []string         `json:"frameworks"`
+	Suggestions []ItemSuggestion `json:"suggestions"`
+}
+
+// ItemSuggestion is a registry item recommended for the project.
+type ItemSuggestion struct {
+	Ref     string   `json:"ref"`
+	Desc    string   `json:"desc"`
+	Score   int      `json:"score"`
+	Matches []string `json:"matches"`
+}
+
+// AuditData is the response data for the audit command.
+type AuditData struct {
+	Items        []AuditItem `json:"items"`
+	Count        int         `json:"count"`
+	ItemsScanned int         `json:"items_scanned"`
+}
+
+// AuditItem is an item with content that could execute.
+type AuditItem struct {
+	ID      string      `json:"id"`
+	Reasons []string    `json:"reasons"`
+	Files   []AuditFile `json:"files"`
+}
+
+// AuditFile identifies a file of an audited item.
+type AuditFile struct {
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+}
+
+// HealthData is the response data for the registry health command.
+type HealthData struct {
+	Score       int      `json:"score"`
+	Items       int      `json:"items"`
+	Warned      []string `json:"warned"`
+	Untagged    []string `json:"untagged"`
+	Unowned     []string `json:"unowned"`
+	StaleDrafts []string `json:"stale_drafts"`
+	Orphans     []string `json:"orphans"`
+	Badge       string   `json:"badge,omitempty"`
+}
+
+// StaleData is the response data for the registry stale command.
+type StaleData struct {
+	Items        []StaleItem `json:"items"`
+	Count        int         `json:"count"`
+	ItemsScanned int         `json:"items_scanned"`
+	Days         int         `json:"days"`
+	FromGit      bool        `json:"from_git"`
+}
+
+// StaleItem is an item that hasn't changed in a while.
+type StaleItem struct {
+	ID          string   `json:"id"`
+	Source      string   `json:"source"`
+	LastChanged string   `json:"last_changed"`
+	Reasons     []string `json:"reasons"`
+}
+
+// TrendsData is the response data for the registry trends command.
+type TrendsData struct {
+	// Builds are the latest recorded builds, oldest first.
+	Builds []TrendBuild `json:"builds"`
+
+	// Recorded is the number of builds in the history.
+	Recorded int `json:"recorded"`
+
+	// Types compares the item counts per type of the first and last build.
+	Types []TypeTrend `json:"types"`
+}
+
+// TrendBuild is the statistics of one build.
+type TrendBuild struct {
+	Time   time.Time `json:"time"`
+	Items  int       `json:"items"`
+	Warned int       `json:"warned"`
+	Size   int       `json:"size"`
+	Score  int       `json:"score"`
+}
+
+// TypeTrend is the number of items of a type in the first and last build.
+type TypeTrend struct {
+	Type  string `json:"type"`
+	First int    `json:"first"`
+	Last  int    `json:"last"`
+}
+
+// ImpactData is the response data for the registry impact command.
+type ImpactData struct {
+	Item string `json:"item"`
+
+	// Dependents are registry items that depend on the item, directly or
+	// through other items, or offer it as an alternative.
+	Dependents []string `json:"dependents"`
+
+	// Projects are the workspace projects with affected items installed.
+	Projects        []ProjectImpact `json:"projects"`
+	ProjectsScanned int             `json:"projects_scanned"`
+}
+
+// ProjectImpact lists the affected items installed in a project.
+type ProjectImpact struct {
+	Path   string   `json:"path"`
+	Target string   `json:"target"`
+	Items  []string `json:"items"`
+}
+
+// UpgradeData is the response data for upgrade commands.
+type UpgradeData struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"update_available"`
+	Upgraded        bool   `json:"upgraded"`
+	Path            string `json:"path,omitempty"`
+	ReleaseURL      string `json:"release_url,omitempty"`
+}
+
+// VersionData is the response data for the version command.
+type VersionData struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	BuildTime       string `json:"build_time"`
+	BuiltBy         string `json:"built_by"`
+	GoVersion       string `json:"go_version"`
+	Platform        string `json:"platform"`
+	ManifestVersion string `json:"manifest_version"`
+}
+
+// ReleaseData is the response data for release commands.
+type ReleaseData struct {
+	Version string   `json:"version"`
+	Files   []string `json:"files"`
+}
+
+// CheckStatus is the outcome of a single doctor check.
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckWarning CheckStatus = "warning"
+	CheckError   CheckStatus = "error"
+)
+
+// DoctorCheck is a single diagnostic check.
+type DoctorCheck struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// DoctorData is the response data for doctor commands.
+type DoctorData struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
 // ConfigData is the response data for config commands.
 type ConfigData struct {
 	Path     string            `json:"path"`
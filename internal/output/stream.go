@@ -0,0 +1,192 @@
+package output
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonStream writes indented JSON without holding the whole document in
+// memory: structs and slices are written field by field and element by
+// element, and only the values inside them are marshaled at once. The output
+// is identical to json.Encoder with two-space indentation.
+type jsonStream struct {
+	w   *bufio.Writer
+	err error
+}
+
+// streamJSON writes v to out as indented JSON followed by a newline.
+func streamJSON(out io.Writer, v interface{}) error {
+	s := &jsonStream{w: bufio.NewWriter(out)}
+	s.value(reflect.ValueOf(v), "")
+	s.write("\n")
+	if s.err != nil {
+		return s.err
+	}
+	return s.w.Flush()
+}
+
+// write writes raw text unless an earlier write failed.
+func (s *jsonStream) write(text string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(text)
+	}
+}
+
+// value writes v, with nested lines indented one level below indent.
+func (s *jsonStream) value(v reflect.Value, indent string) {
+	if s.err != nil {
+		return
+	}
+	if !v.IsValid() {
+		s.write("null")
+		return
+	}
+	if implementsMarshaler(v.Type()) {
+		s.marshal(v, indent)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			s.write("null")
+			return
+		}
+		s.value(v.Elem(), indent)
+	case reflect.Struct:
+		s.structValue(v, indent)
+	case reflect.Slice:
+		if v.IsNil() {
+			s.write("null")
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			s.marshal(v, indent) // base64 string
+			return
+		}
+		s.sliceValue(v, indent)
+	case reflect.Array:
+		s.sliceValue(v, indent)
+	default:
+		s.marshal(v, indent)
+	}
+}
+
+// sliceValue writes the elements of a slice or array one at a time.
+func (s *jsonStream) sliceValue(v reflect.Value, indent string) {
+	if v.Len() == 0 {
+		s.write("[]")
+		return
+	}
+	inner := indent + "  "
+	s.write("[\n")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			s.write(",\n")
+		}
+		s.write(inner)
+		s.value(v.Index(i), inner)
+	}
+	s.write("\n" + indent + "]")
+}
+
+// structValue writes a struct's fields one at a time. Structs with embedded
+// fields or options the stream doesn't handle are marshaled as a whole.
+func (s *jsonStream) structValue(v reflect.Value, indent string) {
+	t := v.Type()
+	type field struct {
+		name  string
+		value reflect.Value
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			s.marshal(v, indent)
+			return
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",string,") {
+			s.marshal(v, indent)
+			return
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fv := v.Field(i)
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+		fields = append(fields, field{name, fv})
+	}
+
+	if len(fields) == 0 {
+		s.write("{}")
+		return
+	}
+	inner := indent + "  "
+	s.write("{\n")
+	for i, f := range fields {
+		if i > 0 {
+			s.write(",\n")
+		}
+		key, _ := json.Marshal(f.name)
+		s.write(inner + string(key) + ": ")
+		s.value(f.value, inner)
+	}
+	s.write("\n" + indent + "}")
+}
+
+// marshal writes v encoded in one piece.
+func (s *jsonStream) marshal(v reflect.Value, indent string) {
+	data, err := json.MarshalIndent(v.Interface(), indent, "  ")
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.write(string(data))
+}
+
+// implementsMarshaler reports whether t encodes itself.
+func implementsMarshaler(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if t.Kind() != reflect.Pointer {
+		p := reflect.PointerTo(t)
+		return p.Implements(jsonMarshalerType) || p.Implements(textMarshalerType)
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty for omitempty, as encoding/json
+// defines it.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"time"
+
+	"github.com/okto-digital/regis3/internal/i18n"
+)
+
+// TimeAgo describes how long before now t was, e.g. "3 days ago", in the
+// current locale. Zero times are "at an unknown time".
+func TimeAgo(t, now time.Time) string {
+	if t.IsZero() {
+		return i18n.T("at an unknown time")
+	}
+
+	d := now.Sub(t)
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	switch {
+	case d < time.Minute:
+		return i18n.T("just now")
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "1 minute ago", "%d minutes ago")
+	case d < day:
+		return plural(int(d/time.Hour), "1 hour ago", "%d hours ago")
+	case d < month:
+		return plural(int(d/day), "yesterday", "%d days ago")
+	case d < year:
+		return plural(int(d/month), "1 month ago", "%d months ago")
+	default:
+		return plural(int(d/year), "1 year ago", "%d years ago")
+	}
+}
+
+// plural picks the singular message for n == 1 and formats the plural
+// otherwise.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return i18n.T(one)
+	}
+	return i18n.Sprintf(many, n)
+}
@@ -0,0 +1,59 @@
+package output
+
+import "fmt"
+
+// VSCodeWriter writes located results one per line, as
+// file:line:column: severity: message, for editor problem matchers such as
+// those of VS Code tasks. Other data is written like in quiet mode.
+type VSCodeWriter struct {
+	*QuietWriter
+}
+
+// NewVSCodeWriter creates a new VS Code writer.
+func NewVSCodeWriter(cfg *Config) *VSCodeWriter {
+	return &VSCodeWriter{QuietWriter: NewQuietWriter(cfg)}
+}
+
+// Write writes validation issues and search results as locations.
+func (w *VSCodeWriter) Write(resp *Response) error {
+	switch d := resp.Data.(type) {
+	case ValidateData:
+		w.writeIssues(d.Issues)
+	case *ValidateData:
+		w.writeIssues(d.Issues)
+	case ListData:
+		w.writeItems(d.Items)
+	case *ListData:
+		w.writeItems(d.Items)
+	default:
+		return w.QuietWriter.Write(resp)
+	}
+
+	if resp.Error != nil {
+		fmt.Fprintln(w.errOut, resp.Error.Message)
+	}
+	return nil
+}
+
+// writeIssues writes validation issues as locations.
+func (w *VSCodeWriter) writeIssues(issues []IssueData) {
+	for _, issue := range issues {
+		message := issue.Message
+		if issue.Field != "" {
+			message = issue.Field + ": " + message
+		}
+		fmt.Fprintf(w.out, "%s:%d:%d: %s: %s\n", issue.File, issue.Line, issue.Column, issue.Severity, message)
+	}
+}
+
+// writeItems writes items as locations; items without a file are written
+// like in quiet mode.
+func (w *VSCodeWriter) writeItems(items []ListItem) {
+	for _, item := range items {
+		if item.File == "" {
+			fmt.Fprintf(w.out, "%s:%s\n", item.Type, item.Name)
+			continue
+		}
+		fmt.Fprintf(w.out, "%s:%d:%d: info: %s:%s - %s\n", item.File, item.Line, item.Column, item.Type, item.Name, item.Desc)
+	}
+}
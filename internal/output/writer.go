@@ -12,6 +12,7 @@ const (
 	FormatPretty Format = "pretty"
 	FormatJSON   Format = "json"
 	FormatQuiet  Format = "quiet"
+	FormatVSCode Format = "vscode"
 )
 
 // ParseFormat parses a format string into a Format type.
@@ -21,6 +22,8 @@ func ParseFormat(s string) Format {
 		return FormatJSON
 	case "quiet":
 		return FormatQuiet
+	case "vscode":
+		return FormatVSCode
 	default:
 		return FormatPretty
 	}
@@ -69,6 +72,9 @@ type Config struct {
 
 	// Verbose enables verbose output.
 	Verbose bool
+
+	// Icons selects the symbols of pretty output (default: unicode).
+	Icons IconSet
 }
 
 // DefaultConfig returns the default output configuration.
@@ -78,6 +84,7 @@ func DefaultConfig() *Config {
 		ErrOutput: os.Stderr,
 		NoColor:   false,
 		Verbose:   false,
+		Icons:     DetectIconSet(),
 	}
 }
 
@@ -92,6 +99,8 @@ func New(format Format, cfg *Config) Writer {
 		return NewJSONWriter(cfg)
 	case FormatQuiet:
 		return NewQuietWriter(cfg)
+	case FormatVSCode:
+		return NewVSCodeWriter(cfg)
 	default:
 		return NewPrettyWriter(cfg)
 	}
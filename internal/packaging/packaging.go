@@ -0,0 +1,269 @@
+// Package packaging generates package manager metadata (Homebrew formula,
+// Scoop manifest) for a regis3 release from its checksums.txt.
+package packaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/okto-digital/regis3/internal/upgrade"
+)
+
+const (
+	// Homepage is the project homepage used in package metadata.
+	Homepage = "https://github.com/okto-digital/regis3"
+
+	// Description is the one-line package description.
+	Description = "Registry manager for LLM assistant configurations"
+
+	// License is the SPDX license identifier.
+	License = "MIT"
+
+	// FormulaFile is the Homebrew formula file name.
+	FormulaFile = "regis3.rb"
+
+	// ScoopFile is the Scoop manifest file name.
+	ScoopFile = "regis3.json"
+)
+
+// Artifact is a release archive for one platform.
+type Artifact struct {
+	OS     string
+	Arch   string
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// Release describes the artifacts of a single version.
+type Release struct {
+	Version   string
+	Artifacts []Artifact
+}
+
+// platforms lists the OS/arch pairs goreleaser builds.
+var platforms = []struct{ os, arch string }{
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"windows", "amd64"},
+	{"windows", "arm64"},
+}
+
+// DownloadURL returns the GitHub download URL of a release asset.
+func DownloadURL(version, name string) string {
+	return fmt.Sprintf("%s/releases/download/v%s/%s", Homepage, version, name)
+}
+
+// NewRelease builds release metadata from the contents of checksums.txt.
+// Every platform archive must be listed.
+func NewRelease(version string, checksums []byte) (*Release, error) {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" || version == "dev" {
+		return nil, fmt.Errorf("a release version is required (got %q)", version)
+	}
+
+	sums := upgrade.ParseChecksums(checksums)
+	release := &Release{Version: version}
+	for _, p := range platforms {
+		name := upgrade.ArchiveName(version, p.os, p.arch)
+		sum, ok := sums[name]
+		if !ok {
+			return nil, fmt.Errorf("no checksum listed for %s", name)
+		}
+		release.Artifacts = append(release.Artifacts, Artifact{
+			OS:     p.os,
+			Arch:   p.arch,
+			Name:   name,
+			URL:    DownloadURL(version, name),
+			SHA256: sum,
+		})
+	}
+	return release, nil
+}
+
+// Artifact returns the archive for a platform.
+func (r *Release) Artifact(goos, goarch string) (Artifact, bool) {
+	for _, a := range r.Artifacts {
+		if a.OS == goos && a.Arch == goarch {
+			return a, true
+		}
+	}
+	return Artifact{}, false
+}
+
+var formulaTemplate = template.Must(template.New("formula").Parse(`# typed: false
+# frozen_string_literal: true
+
+# This file is generated by "regis3 release packaging". Do not edit.
+class Regis3 < Formula
+  desc "{{ .Description }}"
+  homepage "{{ .Homepage }}"
+  version "{{ .Version }}"
+  license "{{ .License }}"
+
+  on_macos do
+    on_intel do
+      url "{{ .DarwinAmd64.URL }}"
+      sha256 "{{ .DarwinAmd64.SHA256 }}"
+    end
+    on_arm do
+      url "{{ .DarwinArm64.URL }}"
+      sha256 "{{ .DarwinArm64.SHA256 }}"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "{{ .LinuxAmd64.URL }}"
+      sha256 "{{ .LinuxAmd64.SHA256 }}"
+    end
+    on_arm do
+      url "{{ .LinuxArm64.URL }}"
+      sha256 "{{ .LinuxArm64.SHA256 }}"
+    end
+  end
+
+  def install
+    bin.install "regis3"
+  end
+
+  test do
+    system "#{bin}/regis3", "version"
+  end
+end
+`))
+
+// HomebrewFormula renders the Homebrew formula for the release.
+func (r *Release) HomebrewFormula() ([]byte, error) {
+	data := map[string]interface{}{
+		"Description": Description,
+		"Homepage":    Homepage,
+		"License":     License,
+		"Version":     r.Version,
+	}
+	for key, p := range map[string][2]string{
+		"DarwinAmd64": {"darwin", "amd64"},
+		"DarwinArm64": {"darwin", "arm64"},
+		"LinuxAmd64":  {"linux", "amd64"},
+		"LinuxArm64":  {"linux", "arm64"},
+	} {
+		a, ok := r.Artifact(p[0], p[1])
+		if !ok {
+			return nil, fmt.Errorf("missing %s/%s archive", p[0], p[1])
+		}
+		data[key] = a
+	}
+
+	var buf bytes.Buffer
+	if err := formulaTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render formula: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scoopManifest is the Scoop app manifest format.
+type scoopManifest struct {
+	Version      string                       `json:"version"`
+	Description  string                       `json:"description"`
+	Homepage     string                       `json:"homepage"`
+	License      string                       `json:"license"`
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+	Bin          string                       `json:"bin"`
+	Checkver     string                       `json:"checkver"`
+	Autoupdate   scoopAutoupdate              `json:"autoupdate"`
+}
+
+type scoopArchitecture struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+}
+
+type scoopAutoupdate struct {
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+	Hash         scoopHash                    `json:"hash"`
+}
+
+type scoopHash struct {
+	URL string `json:"url"`
+}
+
+// scoopArchitectures maps Scoop architecture names to Go architectures.
+var scoopArchitectures = map[string]string{
+	"64bit": "amd64",
+	"arm64": "arm64",
+}
+
+// ScoopManifest renders the Scoop manifest for the release.
+func (r *Release) ScoopManifest() ([]byte, error) {
+	m := scoopManifest{
+		Version:      r.Version,
+		Description:  Description,
+		Homepage:     Homepage,
+		License:      License,
+		Architecture: make(map[string]scoopArchitecture),
+		Bin:          upgrade.BinaryName + ".exe",
+		Checkver:     "github",
+		Autoupdate: scoopAutoupdate{
+			Architecture: make(map[string]scoopArchitecture),
+			Hash:         scoopHash{URL: DownloadURL("$version", upgrade.ChecksumsFile)},
+		},
+	}
+	for scoopArch, goarch := range scoopArchitectures {
+		a, ok := r.Artifact("windows", goarch)
+		if !ok {
+			return nil, fmt.Errorf("missing windows/%s archive", goarch)
+		}
+		m.Architecture[scoopArch] = scoopArchitecture{URL: a.URL, Hash: a.SHA256}
+		m.Autoupdate.Architecture[scoopArch] = scoopArchitecture{
+			URL: DownloadURL("$version", upgrade.ArchiveName("$version", "windows", goarch)),
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render scoop manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteFiles writes the Homebrew formula and Scoop manifest into dir and
+// returns the paths written.
+func (r *Release) WriteFiles(dir string) ([]string, error) {
+	formula, err := r.HomebrewFormula()
+	if err != nil {
+		return nil, err
+	}
+	scoop, err := r.ScoopManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{FormulaFile, formula},
+		{ScoopFile, scoop},
+	}
+
+	var written []string
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, f.content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,112 @@
+package packaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/upgrade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChecksums returns a checksums.txt listing every platform archive.
+func testChecksums(version string) []byte {
+	var b strings.Builder
+	for i, p := range platforms {
+		fmt.Fprintf(&b, "%064x  %s\n", i+1, upgrade.ArchiveName(version, p.os, p.arch))
+	}
+	return []byte(b.String())
+}
+
+func TestNewRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		checksums []byte
+		wantErr   string
+	}{
+		{name: "complete", version: "1.2.3", checksums: testChecksums("1.2.3")},
+		{name: "leading v", version: "v1.2.3", checksums: testChecksums("1.2.3")},
+		{name: "dev build", version: "dev", checksums: testChecksums("dev"), wantErr: "release version is required"},
+		{name: "missing archive", version: "1.2.3", checksums: testChecksums("1.2.2"), wantErr: "no checksum listed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := NewRelease(tt.version, tt.checksums)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "1.2.3", release.Version)
+			assert.Len(t, release.Artifacts, len(platforms))
+
+			a, ok := release.Artifact("darwin", "arm64")
+			require.True(t, ok)
+			assert.Equal(t, "https://github.com/okto-digital/regis3/releases/download/v1.2.3/regis3_1.2.3_darwin_arm64.tar.gz", a.URL)
+			assert.Equal(t, fmt.Sprintf("%064x", 2), a.SHA256)
+		})
+	}
+}
+
+func TestRelease_HomebrewFormula(t *testing.T) {
+	release, err := NewRelease("1.2.3", testChecksums("1.2.3"))
+	require.NoError(t, err)
+
+	formula, err := release.HomebrewFormula()
+	require.NoError(t, err)
+
+	text := string(formula)
+	assert.Contains(t, text, "class Regis3 < Formula")
+	assert.Contains(t, text, `version "1.2.3"`)
+	for _, goos := range []string{"darwin", "linux"} {
+		for _, goarch := range []string{"amd64", "arm64"} {
+			a, _ := release.Artifact(goos, goarch)
+			assert.Contains(t, text, fmt.Sprintf("url %q\n      sha256 %q", a.URL, a.SHA256))
+		}
+	}
+	assert.NotContains(t, text, "windows")
+}
+
+func TestRelease_ScoopManifest(t *testing.T) {
+	release, err := NewRelease("1.2.3", testChecksums("1.2.3"))
+	require.NoError(t, err)
+
+	data, err := release.ScoopManifest()
+	require.NoError(t, err)
+
+	var m scoopManifest
+	require.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, "1.2.3", m.Version)
+	assert.Equal(t, "regis3.exe", m.Bin)
+
+	amd64, _ := release.Artifact("windows", "amd64")
+	assert.Equal(t, scoopArchitecture{URL: amd64.URL, Hash: amd64.SHA256}, m.Architecture["64bit"])
+	arm64, _ := release.Artifact("windows", "arm64")
+	assert.Equal(t, scoopArchitecture{URL: arm64.URL, Hash: arm64.SHA256}, m.Architecture["arm64"])
+
+	assert.Equal(t,
+		"https://github.com/okto-digital/regis3/releases/download/v$version/regis3_$version_windows_amd64.zip",
+		m.Autoupdate.Architecture["64bit"].URL)
+}
+
+func TestRelease_WriteFiles(t *testing.T) {
+	release, err := NewRelease("1.2.3", testChecksums("1.2.3"))
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "packaging")
+	written, err := release.WriteFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, FormulaFile), filepath.Join(dir, ScoopFile)}, written)
+
+	for _, path := range written {
+		_, err := os.Stat(path)
+		assert.NoError(t, err)
+	}
+}
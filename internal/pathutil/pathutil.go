@@ -0,0 +1,64 @@
+// Package pathutil guards file operations against paths that escape their root.
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath indicates a path that could escape its root directory.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// CheckRelative ensures p is a relative path without ".." components,
+// so joining it to a root directory cannot escape that root.
+func CheckRelative(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: empty path", ErrUnsafePath)
+	}
+	if filepath.IsAbs(p) || filepath.VolumeName(p) != "" || strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) {
+		return fmt.Errorf("%w: %s (must be relative)", ErrUnsafePath, p)
+	}
+	for _, part := range strings.FieldsFunc(p, isSeparator) {
+		if part == ".." {
+			return fmt.Errorf("%w: %s (must not contain '..')", ErrUnsafePath, p)
+		}
+	}
+	return nil
+}
+
+// CheckName ensures name is a single path component (no separators, not "." or "..").
+func CheckName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+		return fmt.Errorf("%w: %q is not a valid file name", ErrUnsafePath, name)
+	}
+	return nil
+}
+
+// Join joins relative path elements to root and verifies the result stays under root.
+// Empty elements are ignored.
+func Join(root string, elem ...string) (string, error) {
+	parts := []string{root}
+	for _, e := range elem {
+		if e == "" {
+			continue
+		}
+		if err := CheckRelative(e); err != nil {
+			return "", err
+		}
+		parts = append(parts, e)
+	}
+
+	joined := filepath.Join(parts...)
+	rel, err := filepath.Rel(filepath.Clean(root), joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s escapes %s", ErrUnsafePath, joined, root)
+	}
+	return joined, nil
+}
+
+// isSeparator reports whether r separates path components on any platform.
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
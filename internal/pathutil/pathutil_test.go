@@ -0,0 +1,79 @@
+package pathutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRelative(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"file.md", false},
+		{"assets/logo.png", false},
+		{"./assets/logo.png", false},
+		{"a..b/file", false},
+		{"", true},
+		{"/etc/passwd", true},
+		{"../secret", true},
+		{"assets/../../secret", true},
+		{`..\secret`, true},
+		{`\windows\system32`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := CheckRelative(tt.path)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsafePath)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"git-conventions", false},
+		{"v1.2", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"a/b", true},
+		{`a\b`, true},
+		{"../../.ssh/authorized_keys", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckName(tt.name)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsafePath)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	root := filepath.Join("project", "root")
+
+	got, err := Join(root, ".claude", "", "skills/test/SKILL.md")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".claude", "skills", "test", "SKILL.md"), got)
+
+	_, err = Join(root, "../../.ssh/authorized_keys")
+	assert.ErrorIs(t, err, ErrUnsafePath)
+
+	_, err = Join(root, "/etc/passwd")
+	assert.ErrorIs(t, err, ErrUnsafePath)
+}
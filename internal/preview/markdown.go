@@ -0,0 +1,148 @@
+package preview
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	ruleLinePattern    = regexp.MustCompile(`^([-*_])(\s*[-*_]){2,}$`)
+	bulletPattern      = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	numberedPattern    = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	linkPattern        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern      = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	unsafeSchemePrefix = regexp.MustCompile(`(?i)^\s*(javascript|vbscript|data):`)
+)
+
+// renderMarkdown converts the common subset of markdown used in registry
+// items to HTML: headings, paragraphs, fenced code, lists, block quotes,
+// rules, and code spans, links and emphasis in text. Anything else is shown
+// as text; all text is escaped.
+func renderMarkdown(src string) string {
+	var b strings.Builder
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence := trimmed[:3]
+			lang := strings.TrimSpace(trimmed[3:])
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			i++ // closing fence
+			b.WriteString("<pre><code")
+			if lang != "" {
+				b.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+			}
+			b.WriteString(">" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			m := headingPattern.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(m[1])))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case ruleLinePattern.MatchString(trimmed):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			b.WriteString("<blockquote>\n" + renderMarkdown(strings.Join(quoted, "\n")) + "</blockquote>\n")
+
+		case bulletPattern.MatchString(trimmed), numberedPattern.MatchString(trimmed):
+			pattern, tag := bulletPattern, "ul"
+			if !bulletPattern.MatchString(trimmed) {
+				pattern, tag = numberedPattern, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for i < len(lines) {
+				m := pattern.FindStringSubmatch(strings.TrimSpace(lines[i]))
+				if m == nil {
+					break
+				}
+				item := []string{m[1]}
+				// Indented lines continue the item
+				for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && startsIndented(lines[i]); i++ {
+					item = append(item, strings.TrimSpace(lines[i]))
+				}
+				b.WriteString("<li>" + renderInline(strings.Join(item, " ")) + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			var para []string
+			for ; i < len(lines) && startsParagraphLine(lines[i]); i++ {
+				para = append(para, strings.TrimSpace(lines[i]))
+			}
+			b.WriteString("<p>" + renderInline(strings.Join(para, "\n")) + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// startsIndented reports whether line starts with whitespace.
+func startsIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// startsParagraphLine reports whether line continues a paragraph, rather
+// than being blank or starting another block.
+func startsParagraphLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" &&
+		!strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") &&
+		!strings.HasPrefix(trimmed, ">") &&
+		!headingPattern.MatchString(trimmed) &&
+		!ruleLinePattern.MatchString(trimmed) &&
+		!bulletPattern.MatchString(trimmed) &&
+		!numberedPattern.MatchString(trimmed)
+}
+
+// renderInline escapes text and renders code spans, links and emphasis.
+// Code spans are rendered as is.
+func renderInline(text string) string {
+	var b strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		case i%2 == 1:
+			// An unmatched backtick is text
+			b.WriteString("`" + renderEmphasis(part))
+		default:
+			b.WriteString(renderEmphasis(part))
+		}
+	}
+	return b.String()
+}
+
+// renderEmphasis escapes text and renders links and emphasis.
+func renderEmphasis(text string) string {
+	text = html.EscapeString(text)
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		if unsafeSchemePrefix.MatchString(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return `<a href="` + parts[2] + `">` + parts[1] + `</a>`
+	})
+	text = boldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1$2</em>")
+	return text
+}
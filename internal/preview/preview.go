@@ -0,0 +1,243 @@
+// Package preview serves a live HTML preview of a registry item for authors:
+// its rendered content, metadata and validation issues, and what installing
+// it writes for a target. The page reloads when the file is saved.
+package preview
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// DefaultPollInterval is how often the previewed file is checked for
+// changes.
+const DefaultPollInterval = 500 * time.Millisecond
+
+// Server serves the preview of one item file.
+type Server struct {
+	// RegistryPath is the registry the file belongs to.
+	RegistryPath string
+
+	// File is the path of the item file.
+	File string
+
+	// Dialect reads the item's metadata.
+	Dialect registry.Dialect
+
+	// Targets are the targets whose install output can be shown; the first
+	// is shown by default.
+	Targets []*installer.Target
+
+	// PollInterval is how often the file is checked for changes while a page
+	// is open (default: DefaultPollInterval).
+	PollInterval time.Duration
+}
+
+// Handler returns the HTTP handler of the preview: the page at / (with
+// ?target=name choosing the target) and its reload events at /events.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.servePage)
+	mux.HandleFunc("/events", s.serveEvents)
+	return mux
+}
+
+// page is the data of the preview page.
+type page struct {
+	File    string
+	Error   string
+	Item    *registry.Item
+	Issues  []registry.ValidationIssue
+	Content template.HTML
+
+	Targets    []string
+	Target     string
+	InstallTo  string
+	Install    string
+	InstallErr string
+}
+
+// load reads the item file and prepares the page for the named target; an
+// unknown or empty name selects the first target.
+func (s *Server) load(targetName string) *page {
+	p := &page{File: s.File}
+	var target *installer.Target
+	for _, t := range s.Targets {
+		p.Targets = append(p.Targets, t.Name)
+		if target == nil || t.Name == targetName {
+			target = t
+		}
+	}
+
+	scanner := registry.NewScanner(s.RegistryPath)
+	scanner.Dialect = s.Dialect
+	item, err := scanner.ScanFile(s.File)
+	if err != nil {
+		// A half-written file is normal while editing; the next save reloads
+		p.Error = err.Error()
+		return p
+	}
+	p.Item = item
+	p.Content = template.HTML(renderMarkdown(item.Content))
+
+	validation := registry.NewValidator(s.RegistryPath).ValidateItem(item)
+	validation.Locate(s.RegistryPath, s.Dialect)
+	p.Issues = validation.Issues
+
+	if target != nil {
+		p.Target = target.Name
+		p.InstallTo, p.Install, err = install(item, target)
+		if err != nil {
+			p.InstallErr = err.Error()
+		}
+	}
+	return p
+}
+
+// install returns where installing item for target writes and what.
+// Merged items are shown as their section of the merge file.
+func install(item *registry.Item, target *installer.Target) (string, string, error) {
+	content, err := installer.NewTransformer(target).Transform(item)
+	if err != nil {
+		return "", "", err
+	}
+	if target.IsMergeType(item.Type) {
+		merged := installer.NewMergeContent()
+		merged.Add(item, content)
+		return target.MergeFile + " (managed section)", merged.Generate(), nil
+	}
+	path, err := target.GetPath(item.Type, item.Name)
+	if err != nil {
+		return "", "", err
+	}
+	return path, content, nil
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := pageTemplate.Execute(w, s.load(r.URL.Query().Get("target"))); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveEvents streams a reload event whenever the file changes, until the
+// page is closed.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	// Changes count from the moment the page connects
+	last := modTime(s.File)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	flusher.Flush()
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			// Editors may replace the file on save, so compare times only
+			if current := modTime(s.File); !current.Equal(last) {
+				last = current
+				fmt.Fprint(w, "data: reload\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// modTime returns the modification time of path, or the zero time.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+var pageTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Item}}{{.Item.FullName}}{{else}}{{.File}}{{end}} - regis3 preview</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; color: #222; }
+header { padding: .8em 1.5em; background: #f4f4f6; border-bottom: 1px solid #ddd; }
+header h1 { font-size: 1.1em; margin: 0; }
+header .file { color: #777; font-size: .85em; }
+main { display: flex; gap: 1.5em; padding: 1em 1.5em; }
+section { flex: 1; min-width: 0; }
+h2.panel { font-size: .8em; text-transform: uppercase; letter-spacing: .05em; color: #777; }
+pre { background: #f6f8fa; padding: .8em; overflow-x: auto; }
+code { font-family: ui-monospace, monospace; font-size: .9em; }
+table { border-collapse: collapse; font-size: .9em; }
+td { padding: .15em .8em .15em 0; vertical-align: top; }
+td:first-child { color: #777; }
+.error { color: #b00020; }
+.warning { color: #9a6700; }
+.info { color: #555; }
+.targets a { margin-right: .6em; }
+.targets a.current { font-weight: bold; text-decoration: none; color: #222; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{if .Item}}{{.Item.FullName}}{{else}}Item preview{{end}}</h1>
+<div class="file">{{.File}}</div>
+</header>
+{{if .Error}}
+<main><section><p class="error">{{.Error}}</p></section></main>
+{{else}}
+<main>
+<section>
+<h2 class="panel">Content</h2>
+{{.Content}}
+</section>
+<section>
+<h2 class="panel">Metadata</h2>
+<table>
+<tr><td>Type</td><td>{{.Item.Type}}</td></tr>
+<tr><td>Name</td><td>{{.Item.Name}}</td></tr>
+<tr><td>Description</td><td>{{.Item.Desc}}</td></tr>
+{{with .Item.Tags}}<tr><td>Tags</td><td>{{range $i, $t := .}}{{if $i}}, {{end}}{{$t}}{{end}}</td></tr>{{end}}
+{{with .Item.Deps}}<tr><td>Dependencies</td><td>{{range $i, $d := .}}{{if $i}}, {{end}}{{$d}}{{end}}</td></tr>{{end}}
+{{with .Item.Files}}<tr><td>Files</td><td>{{range $i, $f := .}}{{if $i}}, {{end}}{{$f}}{{end}}</td></tr>{{end}}
+</table>
+<h2 class="panel">Issues</h2>
+{{range .Issues}}<div class="{{.Severity}}">{{.Line}}:{{.Column}} {{.Severity}}{{if .Field}} {{.Field}}{{end}}: {{.Message}}</div>
+{{else}}<p class="info">No issues</p>{{end}}
+<h2 class="panel">Installed for {{.Target}}</h2>
+<div class="targets">{{$current := .Target}}{{range .Targets}}<a href="?target={{.}}"{{if eq . $current}} class="current"{{end}}>{{.}}</a>{{end}}</div>
+{{if .InstallErr}}<p class="error">{{.InstallErr}}</p>{{else}}
+<p><code>{{.InstallTo}}</code></p>
+<pre><code>{{.Install}}</code></pre>
+{{end}}
+</section>
+</main>
+{{end}}
+<script>
+new EventSource("/events" + location.search).onmessage = function () { location.reload(); };
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,148 @@
+package preview
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/installer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "heading and paragraph",
+			src:  "# Title\n\nSome **bold** and *italic* text\nacross lines.",
+			want: "<h1>Title</h1>\n<p>Some <strong>bold</strong> and <em>italic</em> text\nacross lines.</p>\n",
+		},
+		{
+			name: "fenced code is escaped",
+			src:  "```go\nif a < b {}\n```",
+			want: "<pre><code class=\"language-go\">if a &lt; b {}</code></pre>\n",
+		},
+		{
+			name: "lists",
+			src:  "- one\n- two\n  continued\n\n1. first\n2. second",
+			want: "<ul>\n<li>one</li>\n<li>two continued</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name: "quote and rule",
+			src:  "> quoted\n\n---",
+			want: "<blockquote>\n<p>quoted</p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "code spans and links",
+			src:  "Run `<make>` and see [docs](https://example.com).",
+			want: "<p>Run <code>&lt;make&gt;</code> and see <a href=\"https://example.com\">docs</a>.</p>\n",
+		},
+		{
+			name: "html is escaped",
+			src:  "<script>alert(1)</script>",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		},
+		{
+			name: "unsafe links are text",
+			src:  "[click](JavaScript:void)",
+			want: "<p>click</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderMarkdown(tt.src))
+		})
+	}
+}
+
+func newTestServer(t *testing.T, content string) *Server {
+	root := t.TempDir()
+	file := filepath.Join(root, "skills", "demo.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+	cursor := installer.DefaultClaudeTarget()
+	cursor.Name = "cursor"
+	return &Server{
+		RegistryPath: root,
+		File:         file,
+		Targets:      []*installer.Target{installer.DefaultClaudeTarget(), cursor},
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+func get(t *testing.T, handler http.Handler, url string) string {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestServer_Page(t *testing.T) {
+	s := newTestServer(t, "---\nregis3:\n  type: skill\n  name: demo\n  desc: A demo skill for previews\n---\n# Demo\n\nUse it.\n")
+	handler := s.Handler()
+
+	body := get(t, handler, "/")
+	assert.Contains(t, body, "<title>skill:demo - regis3 preview</title>")
+	assert.Contains(t, body, "<h1>Demo</h1>")
+	assert.Contains(t, body, "Installed for claude")
+	assert.Contains(t, body, filepath.Join(".claude", "skills", "demo", "SKILL.md"))
+	assert.Contains(t, body, "2:1 warning tags: no tags specified", "missing fields point at the block")
+
+	body = get(t, handler, "/?target=cursor")
+	assert.Contains(t, body, "Installed for cursor")
+	assert.Contains(t, body, `<a href="?target=cursor" class="current">cursor</a>`)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_PageMergeType(t *testing.T) {
+	s := newTestServer(t, "---\nregis3:\n  type: philosophy\n  name: kiss\n  desc: Keep it simple\n  order: 10\n---\nKeep it simple.\n")
+
+	body := get(t, s.Handler(), "/")
+	assert.Contains(t, body, "CLAUDE.md (managed section)")
+	assert.Contains(t, body, "## Philosophy\n\nKeep it simple.")
+}
+
+func TestServer_PageInvalidFile(t *testing.T) {
+	s := newTestServer(t, "---\nregis3:\n  type: [skill\n---\n")
+
+	body := get(t, s.Handler(), "/")
+	assert.Contains(t, body, `class="error"`)
+	assert.Contains(t, body, "Item preview")
+}
+
+func TestServer_Events(t *testing.T) {
+	s := newTestServer(t, "---\nregis3:\n  type: skill\n  name: demo\n---\n")
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Saving the file sends a reload
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(s.File, later, later))
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: reload\n", line)
+}
@@ -0,0 +1,165 @@
+// Package profile collects phase timings and pprof profiles for diagnosing
+// slow builds and installs.
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+)
+
+const (
+	// CPUFile is the CPU profile file name.
+	CPUFile = "cpu.pprof"
+
+	// HeapFile is the heap profile file name.
+	HeapFile = "heap.pprof"
+
+	// TimingsFile is the timing breakdown file name.
+	TimingsFile = "timings.txt"
+)
+
+// Phase is a named step of an operation and the time spent in it.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Timings records how long each phase of an operation took, in the order
+// the phases first ran. A nil *Timings ignores all calls, so callers can
+// record unconditionally.
+type Timings struct {
+	Phases []Phase
+}
+
+// NewTimings creates an empty timing record.
+func NewTimings() *Timings {
+	return &Timings{}
+}
+
+// Add adds d to the named phase, creating it if needed.
+func (t *Timings) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	for i := range t.Phases {
+		if t.Phases[i].Name == name {
+			t.Phases[i].Duration += d
+			return
+		}
+	}
+	t.Phases = append(t.Phases, Phase{Name: name, Duration: d})
+}
+
+// Start begins timing a phase and returns a function that ends it.
+//
+//	defer t.Start("validate")()
+func (t *Timings) Start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.Add(name, time.Since(start))
+	}
+}
+
+// Total returns the sum of all phase durations.
+func (t *Timings) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	var total time.Duration
+	for _, p := range t.Phases {
+		total += p.Duration
+	}
+	return total
+}
+
+// String formats the timings as an aligned table.
+func (t *Timings) String() string {
+	if t == nil || len(t.Phases) == 0 {
+		return ""
+	}
+
+	width := len("total")
+	for _, p := range t.Phases {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	var b strings.Builder
+	for _, p := range t.Phases {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, p.Name, p.Duration.Round(time.Microsecond))
+	}
+	fmt.Fprintf(&b, "%-*s  %s\n", width, "total", t.Total().Round(time.Microsecond))
+	return b.String()
+}
+
+// Profiler writes a CPU profile while running and a heap profile and timing
+// breakdown when stopped.
+type Profiler struct {
+	// Dir is the directory profiles are written to.
+	Dir string
+
+	// Timings collects the phase breakdown of the profiled command.
+	Timings *Timings
+
+	cpu *os.File
+}
+
+// Start creates dir and begins CPU profiling.
+func Start(dir string) (*Profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, CPUFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	return &Profiler{Dir: dir, Timings: NewTimings(), cpu: f}, nil
+}
+
+// Stop ends CPU profiling, writes the heap profile and timing breakdown, and
+// returns the paths of all files written.
+func (p *Profiler) Stop() ([]string, error) {
+	pprof.StopCPUProfile()
+	if err := p.cpu.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write CPU profile: %w", err)
+	}
+	files := []string{p.cpu.Name()}
+
+	heapPath := filepath.Join(p.Dir, HeapFile)
+	f, err := os.Create(heapPath)
+	if err != nil {
+		return files, fmt.Errorf("failed to create heap profile: %w", err)
+	}
+	runtime.GC() // up-to-date allocation statistics
+	err = pprof.WriteHeapProfile(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return files, fmt.Errorf("failed to write heap profile: %w", err)
+	}
+	files = append(files, heapPath)
+
+	timingsPath := filepath.Join(p.Dir, TimingsFile)
+	if err := os.WriteFile(timingsPath, []byte(p.Timings.String()), 0644); err != nil {
+		return files, fmt.Errorf("failed to write timings: %w", err)
+	}
+	files = append(files, timingsPath)
+
+	return files, nil
+}
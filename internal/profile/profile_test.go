@@ -0,0 +1,68 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimings(t *testing.T) {
+	timings := NewTimings()
+	timings.Add("scan", 2*time.Millisecond)
+	timings.Add("validate", time.Millisecond)
+	timings.Add("scan", 3*time.Millisecond)
+
+	assert.Equal(t, []Phase{
+		{Name: "scan", Duration: 5 * time.Millisecond},
+		{Name: "validate", Duration: time.Millisecond},
+	}, timings.Phases)
+	assert.Equal(t, 6*time.Millisecond, timings.Total())
+	assert.Equal(t, "scan      5ms\nvalidate  1ms\ntotal     6ms\n", timings.String())
+}
+
+func TestTimings_Nil(t *testing.T) {
+	var timings *Timings
+
+	assert.NotPanics(t, func() {
+		timings.Add("scan", time.Second)
+		timings.Start("parse")()
+	})
+	assert.Zero(t, timings.Total())
+	assert.Empty(t, timings.String())
+}
+
+func TestTimings_Start(t *testing.T) {
+	timings := NewTimings()
+
+	stop := timings.Start("write")
+	time.Sleep(time.Millisecond)
+	stop()
+
+	require.Len(t, timings.Phases, 1)
+	assert.Equal(t, "write", timings.Phases[0].Name)
+	assert.GreaterOrEqual(t, timings.Phases[0].Duration, time.Millisecond)
+}
+
+func TestProfiler(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+
+	p, err := Start(dir)
+	require.NoError(t, err)
+	p.Timings.Add("scan", time.Millisecond)
+
+	files, err := p.Stop()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, CPUFile),
+		filepath.Join(dir, HeapFile),
+		filepath.Join(dir, TimingsFile),
+	}, files)
+
+	data, err := os.ReadFile(filepath.Join(dir, TimingsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "scan")
+}
@@ -0,0 +1,280 @@
+// Package recommend detects what a project is built with and recommends
+// registry items that fit it.
+package recommend
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxDepth is how deep Detect looks for source files below the project root.
+const maxDepth = 4
+
+// skipDirs are directories holding dependencies or build output rather than
+// project sources.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// extLanguages maps source file extensions to languages.
+var extLanguages = map[string]string{
+	".go":    "go",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".mjs":   "javascript",
+	".vue":   "vue",
+	".py":    "python",
+	".rs":    "rust",
+	".rb":    "ruby",
+	".php":   "php",
+	".java":  "java",
+	".kt":    "kotlin",
+	".cs":    "csharp",
+	".swift": "swift",
+	".c":     "c",
+	".cpp":   "cpp",
+	".sh":    "shell",
+	".sql":   "sql",
+}
+
+// Project describes what a project is built with.
+type Project struct {
+	// Languages are the languages of the project's source files, most
+	// used first.
+	Languages []string
+
+	// Frameworks are the dependencies declared in go.mod, package.json and
+	// requirements.txt, sorted by name.
+	Frameworks []string
+
+	// Existing are the names of the skills, agents and commands already in
+	// the project's .claude directory, whether installed by regis3 or not.
+	Existing []string
+}
+
+// Detect inspects the project in dir.
+func Detect(dir string) (*Project, error) {
+	counts := make(map[string]int)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories don't stop the detection
+			if d != nil && d.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || skipDirs[name] || depth(dir, path) >= maxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if lang, ok := extLanguages[strings.ToLower(filepath.Ext(path))]; ok {
+			counts[lang]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{}
+	for lang := range counts {
+		project.Languages = append(project.Languages, lang)
+	}
+	sort.Slice(project.Languages, func(i, j int) bool {
+		li, lj := project.Languages[i], project.Languages[j]
+		if counts[li] != counts[lj] {
+			return counts[li] > counts[lj]
+		}
+		return li < lj
+	})
+
+	frameworks := make(map[string]bool)
+	for _, read := range []func(string) ([]string, error){goModules, npmPackages, pythonPackages} {
+		names, err := read(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			frameworks[name] = true
+		}
+	}
+	project.Frameworks = sortedKeys(frameworks)
+
+	project.Existing, err = existingContent(dir)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// depth returns how many directories path is below root.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+// goModules returns the names of the modules directly required in go.mod:
+// the last path element without a major version suffix, e.g. cobra for
+// github.com/spf13/cobra and yaml for gopkg.in/yaml.v3.
+func goModules(dir string) ([]string, error) {
+	var names []string
+	err := readLines(filepath.Join(dir, "go.mod"), func() func(string) {
+		inBlock := false
+		return func(line string) {
+			switch {
+			case line == "require (":
+				inBlock = true
+			case inBlock && line == ")":
+				inBlock = false
+			case inBlock || strings.HasPrefix(line, "require "):
+				fields := strings.Fields(strings.TrimPrefix(line, "require "))
+				if len(fields) > 0 && !strings.HasPrefix(fields[0], "//") && !strings.HasSuffix(line, "// indirect") {
+					names = append(names, moduleName(fields[0]))
+				}
+			}
+		}
+	}())
+	return names, err
+}
+
+// moduleName returns the short name of a Go module path.
+func moduleName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if base, major, ok := strings.Cut(name, ".v"); ok && major != "" && strings.Trim(major, "0123456789") == "" {
+		name = base
+	}
+	return strings.ToLower(name)
+}
+
+// npmPackages returns the dependencies listed in package.json. Scoped
+// packages are named by their scope, e.g. angular for @angular/core; type
+// definitions (@types) are left out.
+func npmPackages(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		// A broken package.json is the project's problem, not ours
+		return nil, nil
+	}
+
+	var names []string
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		for name := range deps {
+			if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+				if scope == "@types" {
+					continue
+				}
+				name = strings.TrimPrefix(scope, "@")
+			}
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	return names, nil
+}
+
+// pythonPackages returns the packages listed in requirements.txt.
+func pythonPackages(dir string) ([]string, error) {
+	var names []string
+	err := readLines(filepath.Join(dir, "requirements.txt"), func(line string) {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			return
+		}
+		if i := strings.IndexAny(line, "<>=!~[;@ "); i >= 0 {
+			line = line[:i]
+		}
+		if line != "" {
+			names = append(names, strings.ToLower(line))
+		}
+	})
+	return names, err
+}
+
+// readLines calls fn with each trimmed line of a file. A missing file has
+// no lines.
+func readLines(path string, fn func(string)) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fn(strings.TrimSpace(scanner.Text()))
+	}
+	return scanner.Err()
+}
+
+// existingContent returns the names of the skills, agents and commands in
+// the project's .claude directory.
+func existingContent(dir string) ([]string, error) {
+	names := make(map[string]bool)
+	for _, sub := range []string{"skills", "agents", "commands"} {
+		entries, err := os.ReadDir(filepath.Join(dir, ".claude", sub))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !e.IsDir() {
+				if filepath.Ext(name) != ".md" {
+					continue
+				}
+				name = strings.TrimSuffix(name, ".md")
+			}
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return sortedKeys(names), nil
+}
+
+// sortedKeys returns the keys of a set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package recommend
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// Weights of the ways an item can match a project. A framework is a
+// stronger hint than a language: a Vue project wants the Vue skill more
+// than every JavaScript item.
+const (
+	languageWeight  = 1
+	frameworkWeight = 2
+)
+
+// keywordAliases maps common spellings of item keywords to the names
+// Detect uses.
+var keywordAliases = map[string]string{
+	"golang": "go",
+	"js":     "javascript",
+	"node":   "javascript",
+	"nodejs": "javascript",
+	"ts":     "typescript",
+	"py":     "python",
+	"rb":     "ruby",
+	"vuejs":  "vue",
+	"bash":   "shell",
+	"sh":     "shell",
+}
+
+// Suggestion is a registry item recommended for a project.
+type Suggestion struct {
+	Ref string
+
+	// Score ranks the suggestion; higher is a better fit.
+	Score int
+
+	// Matches are the languages and frameworks of the project the item
+	// matched, strongest first.
+	Matches []string
+}
+
+// Recommend returns the manifest items whose tags, category or name match
+// the project's languages and frameworks, best fit first and then by ref.
+// Items in skip (e.g. those installed already), items with the same name
+// as existing .claude content and deprecated items are left out.
+func Recommend(manifest *registry.Manifest, project *Project, skip map[string]bool) []Suggestion {
+	existing := make(map[string]bool, len(project.Existing))
+	for _, name := range project.Existing {
+		existing[name] = true
+	}
+
+	var suggestions []Suggestion
+	for ref, item := range manifest.Items {
+		if skip[ref] || existing[strings.ToLower(item.Name)] || item.Status == string(registry.StatusDeprecated) {
+			continue
+		}
+
+		words := keywords(item)
+		s := Suggestion{Ref: ref}
+		for _, fw := range project.Frameworks {
+			if words[fw] {
+				s.Score += frameworkWeight
+				s.Matches = append(s.Matches, fw)
+			}
+		}
+		for _, lang := range project.Languages {
+			if words[lang] && !slices.Contains(s.Matches, lang) {
+				s.Score += languageWeight
+				s.Matches = append(s.Matches, lang)
+			}
+		}
+		if s.Score > 0 {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Ref < suggestions[j].Ref
+	})
+	return suggestions
+}
+
+// keywords returns the lowercase words an item can be matched by: its tags,
+// category and the parts of its name.
+func keywords(item *registry.Item) map[string]bool {
+	words := make(map[string]bool)
+	add := func(word string) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			return
+		}
+		if alias, ok := keywordAliases[word]; ok {
+			word = alias
+		}
+		words[word] = true
+	}
+
+	for _, tag := range item.Tags {
+		add(tag)
+	}
+	for _, part := range strings.Split(item.Cat, "/") {
+		add(part)
+	}
+	add(item.Name)
+	for _, part := range strings.FieldsFunc(item.Name, func(r rune) bool { return r == '-' || r == '_' }) {
+		add(part)
+	}
+	return words
+}
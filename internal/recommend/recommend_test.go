@@ -0,0 +1,124 @@
+package recommend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example.com/app\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.0\n\nrequire (\n\tgithub.com/go-chi/chi/v5 v5.0.0\n\tgopkg.in/yaml.v3 v3.0.1\n\tgolang.org/x/sys v0.1.0 // indirect\n)\n")
+	writeFile(t, dir, "package.json", `{"dependencies": {"vue": "^3.4.0", "@angular/core": "17"}, "devDependencies": {"vitest": "1", "@types/node": "20"}}`)
+	writeFile(t, dir, "requirements.txt", "# tools\nDjango>=4.2\nrequests[security]==2.31\n-r dev.txt\n")
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "cmd/app/app.go", "package app\n")
+	writeFile(t, dir, "web/src/App.vue", "<template></template>\n")
+	writeFile(t, dir, "node_modules/vue/index.js", "")
+	writeFile(t, dir, "a/b/c/d/deep.py", "")
+	writeFile(t, dir, ".claude/skills/testing/SKILL.md", "")
+	writeFile(t, dir, ".claude/agents/Reviewer.md", "")
+	writeFile(t, dir, ".claude/commands/notes.txt", "")
+
+	project, err := Detect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "vue"}, project.Languages)
+	assert.Equal(t, []string{"angular", "chi", "cobra", "django", "requests", "vitest", "vue", "yaml"}, project.Frameworks)
+	assert.Equal(t, []string{"reviewer", "testing"}, project.Existing)
+}
+
+func TestDetect_Empty(t *testing.T) {
+	project, err := Detect(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, project.Languages)
+	assert.Empty(t, project.Frameworks)
+	assert.Empty(t, project.Existing)
+}
+
+func TestModuleName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"github.com/spf13/cobra", "cobra"},
+		{"github.com/go-chi/chi/v5", "chi"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/charmbracelet/bubbletea", "bubbletea"},
+		{"github.com/tpope/vim.vim", "vim.vim"},
+		{"v2", "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, moduleName(tt.path))
+		})
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	manifest := registry.NewManifest("/registry")
+	for _, meta := range []registry.Regis3Meta{
+		{Type: "skill", Name: "go-testing", Tags: []string{"testing"}},
+		{Type: "skill", Name: "cobra-cli", Tags: []string{"golang", "cli"}},
+		{Type: "skill", Name: "vue-components", Cat: "frontend/vue"},
+		{Type: "skill", Name: "python-style", Tags: []string{"py"}},
+		{Type: "skill", Name: "clean-code"},
+		{Type: "skill", Name: "old-go", Tags: []string{"go"}, Status: "deprecated"},
+		{Type: "skill", Name: "testing", Tags: []string{"go"}},
+		{Type: "subagent", Name: "go-reviewer"},
+	} {
+		manifest.AddItem(&registry.Item{Regis3Meta: meta})
+	}
+
+	project := &Project{
+		Languages:  []string{"go", "python", "vue"},
+		Frameworks: []string{"cobra", "vue"},
+		Existing:   []string{"testing"},
+	}
+
+	tests := []struct {
+		name string
+		skip map[string]bool
+		want []Suggestion
+	}{
+		{
+			name: "ranked by fit",
+			want: []Suggestion{
+				{Ref: "skill:cobra-cli", Score: 3, Matches: []string{"cobra", "go"}},
+				{Ref: "skill:vue-components", Score: 2, Matches: []string{"vue"}},
+				{Ref: "skill:go-testing", Score: 1, Matches: []string{"go"}},
+				{Ref: "skill:python-style", Score: 1, Matches: []string{"python"}},
+				{Ref: "subagent:go-reviewer", Score: 1, Matches: []string{"go"}},
+			},
+		},
+		{
+			name: "skipped items",
+			skip: map[string]bool{"skill:cobra-cli": true, "skill:go-testing": true, "subagent:go-reviewer": true},
+			want: []Suggestion{
+				{Ref: "skill:vue-components", Score: 2, Matches: []string{"vue"}},
+				{Ref: "skill:python-style", Score: 1, Matches: []string{"python"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Recommend(manifest, project, tt.skip))
+		})
+	}
+
+	t.Run("nothing detected", func(t *testing.T) {
+		assert.Empty(t, Recommend(manifest, &Project{}, nil))
+	})
+}
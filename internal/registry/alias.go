@@ -0,0 +1,41 @@
+package registry
+
+import "github.com/okto-digital/regis3/pkg/refs"
+
+// ResolveAlias maps a reference to an item alias (type:alias) to the
+// item's full name. It returns false if ref is not an alias, including
+// when ref already names an item.
+func (m *Manifest) ResolveAlias(ref string) (string, bool) {
+	if _, ok := m.Items[ref]; ok {
+		return "", false
+	}
+	itemType, name, ok := refs.Split(ref)
+	if !ok {
+		return "", false
+	}
+	for id, item := range m.Items {
+		if item.Type == itemType && item.HasAlias(name) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// HasAlias reports whether the item is also known under name.
+func (i *Item) HasAlias(name string) bool {
+	for _, a := range i.Aliases {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AliasRefs returns the item's aliases as type:alias references.
+func (i *Item) AliasRefs() []string {
+	aliasRefs := make([]string, len(i.Aliases))
+	for j, a := range i.Aliases {
+		aliasRefs[j] = refs.Join(i.Type, a)
+	}
+	return aliasRefs
+}
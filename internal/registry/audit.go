@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// AuditFinding is a registry item with content that could execute on a
+// developer's machine.
+type AuditFinding struct {
+	// ID is the item's full name.
+	ID string `json:"id"`
+
+	// Reasons explain why the item was flagged.
+	Reasons []string `json:"reasons"`
+
+	// Files are the item's source file, setup script and additional files.
+	Files []AuditFile `json:"files"`
+}
+
+// AuditFile records the identity of a file for review.
+type AuditFile struct {
+	FileChecksum
+
+	// Modified is the file's last modification time.
+	Modified time.Time `json:"modified"`
+}
+
+// shellFences are code block languages treated as shell code.
+var shellFences = map[string]bool{
+	"bash": true, "sh": true, "shell": true, "zsh": true, "fish": true,
+	"console": true, "shell-session": true, "powershell": true, "ps1": true, "bat": true, "cmd": true,
+}
+
+// scriptExtensions mark additional files that are likely executed.
+var scriptExtensions = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true, ".py": true, ".rb": true,
+	".pl": true, ".js": true, ".mjs": true, ".ts": true, ".ps1": true, ".bat": true, ".cmd": true,
+}
+
+// Audit lists the manifest's items that contain executable content: script
+// and hook items, setup scripts, executable additional files and shell code
+// blocks. Content is read from the registry, so the report reflects the files
+// on disk. Findings are sorted by ID.
+func Audit(registryPath string, manifest *Manifest) ([]AuditFinding, error) {
+	var findings []AuditFinding
+	for _, item := range manifest.Items {
+		finding, err := auditItem(registryPath, item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item.FullName(), err)
+		}
+		if len(finding.Reasons) > 0 {
+			findings = append(findings, finding)
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].ID < findings[j].ID
+	})
+	return findings, nil
+}
+
+// auditItem collects the reasons an item may execute code and its files.
+func auditItem(registryPath string, item *Item) (AuditFinding, error) {
+	finding := AuditFinding{ID: item.FullName()}
+
+	source, err := pathutil.Join(registryPath, item.Source)
+	if err != nil {
+		return finding, err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return finding, fmt.Errorf("failed to read source: %w", err)
+	}
+
+	switch item.Type {
+	case string(TypeScript):
+		finding.Reasons = append(finding.Reasons, "script item (installed as an executable)")
+	case string(TypeHook):
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("hook runs on %s: %s", item.Trigger, item.Run))
+	}
+	if mode, err := item.FileMode(); err == nil && mode&0111 != 0 && item.Type != string(TypeScript) {
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("installed with executable mode %04o", mode))
+	}
+	if item.Setup != "" {
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("setup script %s", item.Setup))
+	}
+
+	body := string(data)
+	if doc, err := frontmatter.ParseBytes(data); err == nil {
+		body = doc.Body
+		if plain, err := decryptBody(body); err == nil {
+			body = plain
+		}
+	}
+	if n := countShellBlocks(body); n > 0 {
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("%d shell code block(s)", n))
+	}
+
+	files := []string{filepath.Base(item.Source)}
+	if item.Setup != "" {
+		files = append(files, item.Setup)
+	}
+	expanded, _ := item.ExpandFiles(registryPath)
+	for _, file := range expanded {
+		path, err := pathutil.Join(registryPath, item.SourceDir, file)
+		if err != nil {
+			continue // reported by validation
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if scriptExtensions[strings.ToLower(filepath.Ext(file))] || info.Mode()&0111 != 0 {
+			finding.Reasons = append(finding.Reasons, fmt.Sprintf("executable file %s", file))
+		}
+		files = append(files, file)
+	}
+
+	if len(finding.Reasons) == 0 {
+		return finding, nil
+	}
+	for _, file := range files {
+		path, err := pathutil.Join(registryPath, item.SourceDir, file)
+		if err != nil {
+			continue
+		}
+		auditFile, err := auditFileInfo(path)
+		if err != nil {
+			continue
+		}
+		auditFile.Path = filepath.ToSlash(filepath.Join(item.SourceDir, file))
+		finding.Files = append(finding.Files, auditFile)
+	}
+	return finding, nil
+}
+
+// auditFileInfo checksums a file and records its modification time.
+func auditFileInfo(path string) (AuditFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return AuditFile{}, err
+	}
+	sum, err := ChecksumFile(path)
+	if err != nil {
+		return AuditFile{}, err
+	}
+	return AuditFile{FileChecksum: sum, Modified: info.ModTime()}, nil
+}
+
+// countShellBlocks counts fenced code blocks tagged with a shell language.
+func countShellBlocks(content string) int {
+	count := 0
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		if !inCode {
+			lang, _, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")), " ")
+			if shellFences[strings.ToLower(lang)] {
+				count++
+			}
+		}
+		inCode = !inCode
+	}
+	return count
+}
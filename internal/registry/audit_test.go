@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"skills/plain.md":   "---\nregis3:\n  type: skill\n  name: plain\n  desc: Plain skill\n---\n# Plain\n\n```go\nfmt.Println()\n```\n",
+		"skills/shell.md":   "---\nregis3:\n  type: skill\n  name: shell\n  desc: Shell skill\n---\n# Shell\n\n```bash\nrm -rf build\n```\n\n```sh\nmake\n```\n",
+		"skills/setup.md":   "---\nregis3:\n  type: skill\n  name: setup\n  desc: Setup skill\n  setup: install.sh\n---\n# Setup\n",
+		"skills/install.sh": "#!/bin/sh\necho hi\n",
+		"scripts/deploy.md": "---\nregis3:\n  type: script\n  name: deploy\n  desc: Deploy script\n---\n# Deploy\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	scan, err := NewScanner(root).Scan()
+	require.NoError(t, err)
+	manifest := newManifestFromScan(root, scan, Filter{}, nil)
+
+	findings, err := Audit(root, manifest)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.ID)
+	}
+	assert.Equal(t, []string{"script:deploy", "skill:setup", "skill:shell"}, ids)
+
+	assert.Equal(t, []string{"setup script install.sh"}, findings[1].Reasons)
+	require.Len(t, findings[1].Files, 2)
+	assert.Equal(t, "skills/setup.md", findings[1].Files[0].Path)
+	assert.Equal(t, "skills/install.sh", findings[1].Files[1].Path)
+	assert.Len(t, findings[1].Files[1].SHA256, 64)
+	assert.False(t, findings[1].Files[1].Modified.IsZero())
+
+	assert.Equal(t, []string{"2 shell code block(s)"}, findings[2].Reasons)
+}
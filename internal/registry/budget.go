@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SizeBudget limits an item's content length in bytes. Zero means no limit.
+type SizeBudget struct {
+	// Warn is the size above which validation warns.
+	Warn int
+
+	// Max is the size above which validation fails.
+	Max int
+}
+
+// SizeBudgets maps item types to their content size budget.
+type SizeBudgets map[string]SizeBudget
+
+// check reports content over the budget for the item's type.
+func (b SizeBudgets) check(item *Item, result *ValidationResult) {
+	budget, ok := b[item.Type]
+	if !ok {
+		return
+	}
+	size := len(item.Content)
+	switch {
+	case budget.Max > 0 && size > budget.Max:
+		result.AddError(item.Source, "content", fmt.Sprintf("content is %s, over the %s limit for %s items", FormatSize(size), FormatSize(budget.Max), item.Type))
+	case budget.Warn > 0 && size > budget.Warn:
+		result.AddWarning(item.Source, "content", fmt.Sprintf("content is %s, over the %s budget for %s items", FormatSize(size), FormatSize(budget.Warn), item.Type))
+	}
+}
+
+// sizeUnits are the suffixes accepted by ParseSize.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int
+}{
+	{"MB", 1024 * 1024}, {"M", 1024 * 1024},
+	{"KB", 1024}, {"K", 1024},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "2048", "2KB" or "1.5MB" into bytes.
+// Units are case-insensitive and binary (1KB = 1024 bytes).
+func ParseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use bytes or a KB/MB suffix, e.g. 20KB)", s)
+	}
+	return int(n * float64(multiplier)), nil
+}
+
+// FormatSize formats a byte count for display (e.g. 512B, 2.0KB, 1.5MB).
+func FormatSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
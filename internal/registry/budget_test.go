@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "2048", want: 2048},
+		{in: "2KB", want: 2048},
+		{in: "2k", want: 2048},
+		{in: "1.5MB", want: 1572864},
+		{in: "512 B", want: 512},
+		{in: "big", wantErr: true},
+		{in: "-1KB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidator_SizeBudgets(t *testing.T) {
+	budgets := SizeBudgets{
+		"philosophy": {Warn: 2048},
+		"skill":      {Warn: 100, Max: 200},
+	}
+
+	tests := []struct {
+		name     string
+		itemType string
+		size     int
+		wantWarn string
+		wantErr  string
+	}{
+		{name: "within budget", itemType: "skill", size: 100},
+		{name: "over warn", itemType: "skill", size: 150, wantWarn: "content is 150B, over the 100B budget for skill items"},
+		{name: "over max", itemType: "skill", size: 250, wantErr: "content is 250B, over the 200B limit for skill items"},
+		{name: "warn only", itemType: "philosophy", size: 4096, wantWarn: "content is 4.0KB, over the 2.0KB budget for philosophy items"},
+		{name: "no budget", itemType: "doc", size: 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &Item{
+				Regis3Meta: Regis3Meta{Type: tt.itemType, Name: "sample", Desc: "Sample item for size checks"},
+				Source:     "sample.md",
+				Content:    strings.Repeat("x", tt.size),
+			}
+			result := &ValidationResult{}
+			budgets.check(item, result)
+
+			var warns, errs []string
+			for _, w := range result.Warnings() {
+				warns = append(warns, w.Message)
+			}
+			for _, e := range result.Errors() {
+				errs = append(errs, e.Message)
+			}
+			assert.Equal(t, nonEmpty(tt.wantWarn), warns)
+			assert.Equal(t, nonEmpty(tt.wantErr), errs)
+		})
+	}
+}
+
+// nonEmpty returns s as a single-element slice, or nil if s is empty.
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
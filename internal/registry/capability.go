@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+)
+
+// CapabilityPrefix marks a virtual dependency that is satisfied by any item
+// declaring it in its provides list (e.g. capability:git-workflow).
+const CapabilityPrefix = "capability:"
+
+// IsCapability reports whether a dependency reference names a capability.
+func IsCapability(ref string) bool {
+	return strings.HasPrefix(ref, CapabilityPrefix)
+}
+
+// ProvidesCapability reports whether the item provides the given capability.
+func (i *Item) ProvidesCapability(capability string) bool {
+	for _, p := range i.Provides {
+		if p == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Providers returns the full names of items providing a capability, sorted.
+func (m *Manifest) Providers(capability string) []string {
+	var providers []string
+	for id, item := range m.Items {
+		if item.ProvidesCapability(capability) {
+			providers = append(providers, id)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
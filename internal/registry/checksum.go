@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+)
+
+// FileChecksum records the size and SHA256 of an item's additional file.
+type FileChecksum struct {
+	// Path is the file path as listed in the item's files field.
+	Path string `json:"path"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// SHA256 is the hex-encoded SHA256 digest of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// ChecksumFile computes the size and SHA256 of a file.
+func ChecksumFile(path string) (FileChecksum, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FileChecksum{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return FileChecksum{}, err
+	}
+
+	return FileChecksum{
+		Path:   path,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// Matches reports whether another checksum has the same size and digest.
+func (c FileChecksum) Matches(other FileChecksum) bool {
+	return c.Size == other.Size && c.SHA256 == other.SHA256
+}
+
+// ComputeChecksums records checksums for every item's additional files.
+// Files that are missing or unsafe are skipped; validation reports those.
+func (m *Manifest) ComputeChecksums() {
+	for _, item := range m.Items {
+		item.ComputeChecksums(m.RegistryPath)
+	}
+}
+
+// ComputeChecksums records checksums for the item's additional files, one
+// per file in its directory entries.
+func (i *Item) ComputeChecksums(registryPath string) {
+	i.Checksums = nil
+	files, err := i.ExpandFiles(registryPath)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		path, err := pathutil.Join(registryPath, i.SourceDir, file)
+		if err != nil {
+			continue
+		}
+		sum, err := ChecksumFile(path)
+		if err != nil {
+			continue
+		}
+		sum.Path = file
+		i.Checksums = append(i.Checksums, sum)
+	}
+}
+
+// Checksum returns the recorded checksum for one of the item's files.
+func (i *Item) Checksum(file string) (FileChecksum, bool) {
+	for _, c := range i.Checksums {
+		if c.Path == file {
+			return c, true
+		}
+	}
+	return FileChecksum{}, false
+}
+
+// VerifyFiles checks the item's additional files in the registry against the
+// checksums recorded at build time, including files added to or removed from
+// its directory entries. Items built without checksums only have their
+// files' existence checked.
+func (i *Item) VerifyFiles(registryPath string) error {
+	files, err := i.ExpandFiles(registryPath)
+	if err != nil {
+		return err
+	}
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file] = true
+	}
+	for _, c := range i.Checksums {
+		if !present[c.Path] {
+			return fmt.Errorf("missing file: %s", c.Path)
+		}
+	}
+	for _, file := range files {
+		path, err := pathutil.Join(registryPath, i.SourceDir, file)
+		if err != nil {
+			return err
+		}
+		sum, err := ChecksumFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("missing file: %s", file)
+			}
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		want, ok := i.Checksum(file)
+		if (ok && !want.Matches(sum)) || (!ok && len(i.Checksums) > 0) {
+			return fmt.Errorf("file changed since the manifest was built: %s (run 'regis3 build')", file)
+		}
+	}
+	return nil
+}
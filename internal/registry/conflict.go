@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictError indicates a file with unresolved merge conflict markers.
+type ConflictError struct {
+	// Line is the line of the first conflict marker.
+	Line int
+
+	// Frontmatter is true if the conflict starts in the frontmatter.
+	Frontmatter bool
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("unresolved merge conflict markers (line %d)", e.Line)
+}
+
+// findConflict returns a ConflictError for the first complete conflict
+// (<<<<<<<, ======= and >>>>>>> markers in order) in a file, or nil.
+// Markers in fenced code blocks are examples, not conflicts.
+func findConflict(content []byte) *ConflictError {
+	lines := strings.Split(string(content), "\n")
+
+	// The frontmatter ends at the delimiter matching the first line
+	frontmatterEnd := 0
+	if open := strings.TrimSpace(lines[0]); open == "---" || open == "+++" {
+		frontmatterEnd = len(lines)
+		for i := 1; i < len(lines); i++ {
+			if line := strings.TrimRight(lines[i], " \t\r"); line == open || (open == "---" && line == "...") {
+				frontmatterEnd = i + 1
+				break
+			}
+		}
+	}
+
+	start, separated := 0, false
+	inCode := false
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if i >= frontmatterEnd && strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		switch {
+		case isConflictMarker(line, "<<<<<<<"):
+			start, separated = i+1, false
+		case start > 0 && line == "=======":
+			separated = true
+		case separated && isConflictMarker(line, ">>>>>>>"):
+			return &ConflictError{Line: start, Frontmatter: start <= frontmatterEnd || start == 1}
+		}
+	}
+	return nil
+}
+
+// isConflictMarker reports whether line is a conflict marker, optionally
+// followed by a space and a label (e.g. <<<<<<< HEAD).
+func isConflictMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+// reportConflicts moves scan errors for files with conflict markers into the
+// validation result, so they fail the build instead of being skipped, and
+// returns the remaining scan errors.
+func reportConflicts(registryPath string, scanErrors []ScanError, result *ValidationResult) []ScanError {
+	remaining := scanErrors[:0]
+	for _, scanErr := range scanErrors {
+		var conflict *ConflictError
+		if !errors.As(scanErr.Err, &conflict) {
+			remaining = append(remaining, scanErr)
+			continue
+		}
+		path := scanErr.Path
+		if rel, err := filepath.Rel(registryPath, path); err == nil {
+			path = rel
+		}
+		field := "content"
+		if conflict.Frontmatter {
+			field = "frontmatter"
+		}
+		result.Issues = append(result.Issues, ValidationIssue{
+			Severity: SeverityError,
+			Path:     path,
+			Field:    field,
+			Message:  conflict.Error(),
+			Line:     conflict.Line,
+			Column:   1,
+		})
+	}
+	return remaining
+}
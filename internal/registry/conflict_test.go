@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConflict(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		wantLine        int
+		wantFrontmatter bool
+	}{
+		{
+			name:    "no markers",
+			content: "---\nregis3:\n  type: skill\n---\n# Title\n",
+		},
+		{
+			name:            "frontmatter",
+			content:         "---\nregis3:\n<<<<<<< HEAD\n  desc: Ours\n=======\n  desc: Theirs\n>>>>>>> feature\n---\n# Title\n",
+			wantLine:        3,
+			wantFrontmatter: true,
+		},
+		{
+			name:            "around the opening delimiter",
+			content:         "<<<<<<< HEAD\n---\nregis3: {}\n=======\n---\nregis3: {}\n>>>>>>> feature\n---\nBody\n",
+			wantLine:        1,
+			wantFrontmatter: true,
+		},
+		{
+			name:     "body",
+			content:  "---\nregis3: {}\n---\n# Title\n\n<<<<<<< HEAD\nOurs\n=======\nTheirs\n>>>>>>> 1a2b3c4\n",
+			wantLine: 6,
+		},
+		{
+			name:     "crlf line endings",
+			content:  "---\r\nregis3: {}\r\n---\r\n<<<<<<< HEAD\r\nOurs\r\n=======\r\nTheirs\r\n>>>>>>> feature\r\n",
+			wantLine: 4,
+		},
+		{
+			name:    "example in a code block",
+			content: "---\nregis3: {}\n---\n# Resolving conflicts\n\n```\n<<<<<<< HEAD\nOurs\n=======\nTheirs\n>>>>>>> feature\n```\n",
+		},
+		{
+			name:    "setext heading is not a separator",
+			content: "---\nregis3: {}\n---\nTitle\n=======\n",
+		},
+		{
+			name:    "incomplete conflict",
+			content: "---\nregis3: {}\n---\n<<<<<<< HEAD\nOurs\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict := findConflict([]byte(tt.content))
+			if tt.wantLine == 0 {
+				assert.Nil(t, conflict)
+				return
+			}
+			require.NotNil(t, conflict)
+			assert.Equal(t, tt.wantLine, conflict.Line)
+			assert.Equal(t, tt.wantFrontmatter, conflict.Frontmatter)
+		})
+	}
+}
+
+func TestBuildRegistry_ConflictMarkers(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, root, "skills/ok.md", "---\nregis3:\n  type: skill\n  name: ok\n  desc: A skill without conflicts\n  tags: [test]\n---\n# Ok\n")
+	writeRegistryFile(t, root, "skills/conflicted.md", "---\nregis3:\n  type: skill\n  name: conflicted\n  desc: A skill in the middle of a merge\n  tags: [test]\n---\n# Conflicted\n\n<<<<<<< HEAD\nOurs\n=======\nTheirs\n>>>>>>> feature\n")
+
+	result, err := BuildRegistry(root)
+	require.NoError(t, err)
+	assert.Empty(t, result.ScanErrors)
+
+	errs := result.Validation.Errors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, filepath.Join("skills", "conflicted.md"), errs[0].Path)
+	assert.Equal(t, "content", errs[0].Field)
+	assert.Equal(t, "unresolved merge conflict markers (line 10)", errs[0].Message)
+	assert.False(t, ManifestExists(root))
+}
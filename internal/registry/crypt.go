@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/crypt"
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// CryptFile marks a registry whose item bodies are kept encrypted. It
+// records the ID of the key, never the key itself.
+const CryptFile = ".regis3-crypt.yaml"
+
+// CryptConfig is the content of CryptFile.
+type CryptConfig struct {
+	// KeyID identifies the key item bodies are encrypted with.
+	KeyID string `yaml:"key_id"`
+}
+
+// LoadCryptConfig reads the registry's CryptFile. It returns nil without
+// an error if the registry isn't encrypted.
+func LoadCryptConfig(registryPath string) (*CryptConfig, error) {
+	data, err := os.ReadFile(filepath.Join(registryPath, CryptFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", CryptFile, err)
+	}
+	var cfg CryptConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", CryptFile, err)
+	}
+	return &cfg, nil
+}
+
+// SaveCryptConfig writes the registry's CryptFile.
+func SaveCryptConfig(registryPath string, cfg *CryptConfig) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	header := "# Item bodies in this registry are encrypted; see 'regis3 crypt'.\n"
+	if err := os.WriteFile(filepath.Join(registryPath, CryptFile), append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", CryptFile, err)
+	}
+	return nil
+}
+
+// decryptBody returns an item body, decrypting it with the configured key
+// if it is encrypted.
+func decryptBody(body string) (string, error) {
+	if !crypt.IsEncrypted(body) {
+		return body, nil
+	}
+	key, err := crypt.DefaultKey()
+	if err != nil {
+		return "", fmt.Errorf("item body is encrypted: %w", err)
+	}
+	return crypt.Decrypt(key, body)
+}
+
+// CryptStatus counts the encrypted and plain item files of a registry.
+type CryptStatus struct {
+	Encrypted []string
+	Plain     []string
+
+	// OtherKey are files encrypted with a key other than the registry's.
+	OtherKey []string
+}
+
+// EncryptItems encrypts the bodies of the registry's items that aren't
+// encrypted yet with key and returns the files changed. Bodies encrypted
+// with another key are an error.
+func EncryptItems(registryPath string, key crypt.Key) ([]string, error) {
+	return updateBodies(registryPath, func(body string) (string, error) {
+		if id, ok := crypt.KeyID(body); ok {
+			if id != key.ID() {
+				return "", fmt.Errorf("encrypted with key %s, not %s", id, key.ID())
+			}
+			return body, nil
+		}
+		return crypt.Encrypt(key, body)
+	})
+}
+
+// RotateItems re-encrypts the bodies of the registry's items from the old
+// key to the new one, encrypting plain bodies too, and returns the files
+// changed.
+func RotateItems(registryPath string, old, new crypt.Key) ([]string, error) {
+	return updateBodies(registryPath, func(body string) (string, error) {
+		plain, err := crypt.Decrypt(old, body)
+		if err != nil {
+			return "", err
+		}
+		return crypt.Encrypt(new, plain)
+	})
+}
+
+// ItemCryptStatus reports which of the registry's item files are encrypted,
+// and with which key.
+func ItemCryptStatus(registryPath, keyID string) (*CryptStatus, error) {
+	status := &CryptStatus{}
+	_, err := updateBodies(registryPath, func(body string) (string, error) {
+		return body, nil
+	}, func(path, body string) {
+		switch id, ok := crypt.KeyID(body); {
+		case !ok:
+			status.Plain = append(status.Plain, path)
+		case keyID != "" && id != keyID:
+			status.OtherKey = append(status.OtherKey, path)
+		default:
+			status.Encrypted = append(status.Encrypted, path)
+		}
+	})
+	return status, err
+}
+
+// updateBodies rewrites the body of every item file and variant file in the
+// registry with update, leaving the frontmatter as it is, and returns the
+// files changed. Variant files without frontmatter are all body. All bodies
+// are updated before any file is written, so a failure leaves the registry
+// unchanged. Visitors see each file's current body.
+func updateBodies(registryPath string, update func(body string) (string, error), visit ...func(path, body string)) ([]string, error) {
+	scanner := NewScanner(registryPath)
+	scanner.keepEncrypted = true
+	result, err := scanner.Scan()
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, result.Errors[0]
+	}
+
+	type change struct {
+		path    string
+		content []byte
+		mode    os.FileMode
+	}
+	var changes []change
+	seen := make(map[string]bool)
+	updateFile := func(source string, variant bool) error {
+		if seen[source] {
+			return nil
+		}
+		seen[source] = true
+		path, err := pathutil.Join(registryPath, source)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		head, oldBody := "", string(data)
+		doc, err := frontmatter.ParseBytes(data)
+		switch {
+		case err == nil:
+			lines := strings.SplitAfter(string(data), "\n")
+			head = strings.Join(lines[:min(doc.BodyLine()-1, len(lines))], "")
+			oldBody = doc.Body
+		case !variant:
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		for _, v := range visit {
+			v(source, oldBody)
+		}
+
+		body, err := update(oldBody)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if body == oldBody {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change{path: source, content: []byte(head + body), mode: info.Mode().Perm()})
+		return nil
+	}
+	for _, item := range result.Items {
+		if err := updateFile(item.Source, false); err != nil {
+			return nil, err
+		}
+		for _, name := range item.VariantNames() {
+			if err := updateFile(filepath.ToSlash(filepath.Join(item.SourceDir, item.Variants[name])), true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var changed []string
+	for _, c := range changes {
+		if err := os.WriteFile(filepath.Join(registryPath, c.path), c.content, c.mode); err != nil {
+			return changed, fmt.Errorf("failed to write %s: %w", c.path, err)
+		}
+		changed = append(changed, c.path)
+	}
+	return changed, nil
+}
@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cryptItem = "---\n# Reviewed quarterly\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n---\n# Testing\n\nWrite table-driven tests.\n"
+
+func writeCryptRegistry(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "testing.md"), []byte(cryptItem), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Registry\n"), 0644))
+	return dir
+}
+
+func TestEncryptItems(t *testing.T) {
+	dir := writeCryptRegistry(t)
+	key, err := crypt.GenerateKey()
+	require.NoError(t, err)
+
+	changed, err := EncryptItems(dir, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md"}, changed)
+
+	data, err := os.ReadFile(filepath.Join(dir, "skills", "testing.md"))
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "# Reviewed quarterly\nregis3:\n  type: skill")
+	assert.NotContains(t, content, "table-driven")
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Registry\n", string(readme))
+
+	// Encrypting again changes nothing
+	changed, err = EncryptItems(dir, key)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// Another key can't encrypt over it
+	other, err := crypt.GenerateKey()
+	require.NoError(t, err)
+	_, err = EncryptItems(dir, other)
+	assert.Error(t, err)
+
+	status, err := ItemCryptStatus(dir, key.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md"}, status.Encrypted)
+	assert.Empty(t, status.Plain)
+
+	// The scanner decrypts with the configured key
+	t.Setenv(crypt.KeyEnv, key.String())
+	scan, err := NewScanner(dir).Scan()
+	require.NoError(t, err)
+	require.Empty(t, scan.Errors)
+	require.Len(t, scan.Items, 1)
+	item := scan.Items[0]
+	assert.Equal(t, "testing", item.Name)
+	assert.Equal(t, "# Testing\n\nWrite table-driven tests.\n", item.Content)
+	assert.Equal(t, len(item.Content), item.Size)
+
+	item.Content = ""
+	require.NoError(t, item.LoadContent(dir))
+	assert.Contains(t, item.Content, "table-driven")
+}
+
+func TestRotateItems(t *testing.T) {
+	dir := writeCryptRegistry(t)
+	old, err := crypt.GenerateKey()
+	require.NoError(t, err)
+	key, err := crypt.GenerateKey()
+	require.NoError(t, err)
+
+	_, err = EncryptItems(dir, old)
+	require.NoError(t, err)
+
+	// The wrong key leaves the registry unchanged
+	_, err = RotateItems(dir, key, old)
+	require.Error(t, err)
+
+	changed, err := RotateItems(dir, old, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md"}, changed)
+
+	status, err := ItemCryptStatus(dir, old.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md"}, status.OtherKey)
+
+	data, err := os.ReadFile(filepath.Join(dir, "skills", "testing.md"))
+	require.NoError(t, err)
+	doc := string(data)
+	body := doc[len(cryptItem)-len("# Testing\n\nWrite table-driven tests.\n"):]
+	plain, err := crypt.Decrypt(key, body)
+	require.NoError(t, err)
+	assert.Equal(t, "# Testing\n\nWrite table-driven tests.\n", plain)
+}
+
+func TestRotateItems_Variants(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skills"), 0755))
+	item := "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  variants:\n    concise: testing.concise.md\n---\n# Testing\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "testing.md"), []byte(item), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "testing.concise.md"), []byte("Test tables.\n"), 0644))
+
+	old, err := crypt.GenerateKey()
+	require.NoError(t, err)
+	key, err := crypt.GenerateKey()
+	require.NoError(t, err)
+
+	changed, err := EncryptItems(dir, old)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md", "skills/testing.concise.md"}, changed)
+	data, err := os.ReadFile(filepath.Join(dir, "skills", "testing.concise.md"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Test tables")
+
+	changed, err = RotateItems(dir, old, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md", "skills/testing.concise.md"}, changed)
+
+	status, err := ItemCryptStatus(dir, key.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skills/testing.md", "skills/testing.concise.md"}, status.Encrypted)
+
+	// The variant decrypts with the new key
+	data, err = os.ReadFile(filepath.Join(dir, "skills", "testing.concise.md"))
+	require.NoError(t, err)
+	plain, err := crypt.Decrypt(key, string(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Test tables.\n", plain)
+}
+
+func TestCryptConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadCryptConfig(dir)
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	require.NoError(t, SaveCryptConfig(dir, &CryptConfig{KeyID: "abc123"}))
+	cfg, err = LoadCryptConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", cfg.KeyID)
+}
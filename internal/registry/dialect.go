@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+)
+
+// DefaultMetaKey is the frontmatter key of the regis3 block.
+const DefaultMetaKey = "regis3"
+
+// Dialect selects the frontmatter formats and keys item metadata is read
+// from, so content written for other tools can be adopted without rewriting
+// it. The zero value reads YAML frontmatter with a regis3 block.
+type Dialect struct {
+	// Formats are the accepted frontmatter formats. Empty means YAML only.
+	Formats []frontmatter.Format
+
+	// Keys are the keys the regis3 block may be stored under, tried in
+	// order. Dotted keys address nested blocks (e.g. meta.regis3). Empty
+	// means DefaultMetaKey.
+	Keys []string
+}
+
+// Validate checks that the formats are supported and the keys well-formed.
+func (d Dialect) Validate() error {
+	for _, format := range d.Formats {
+		if !frontmatter.IsFormat(format) {
+			return fmt.Errorf("unsupported frontmatter format %q (expected yaml or toml)", format)
+		}
+	}
+	for _, key := range d.Keys {
+		if !IsMetaKey(key) {
+			return fmt.Errorf("invalid frontmatter key %q", key)
+		}
+	}
+	return nil
+}
+
+// IsMetaKey reports whether key is a frontmatter key or a dotted path of
+// keys.
+func IsMetaKey(key string) bool {
+	for _, part := range strings.Split(key, ".") {
+		if strings.TrimSpace(part) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Accepts reports whether frontmatter of format is read.
+func (d Dialect) Accepts(format frontmatter.Format) bool {
+	if len(d.Formats) == 0 {
+		return format == frontmatter.FormatYAML
+	}
+	for _, f := range d.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// keys returns the metadata keys to try.
+func (d Dialect) keys() []string {
+	if len(d.Keys) == 0 {
+		return []string{DefaultMetaKey}
+	}
+	return d.Keys
+}
+
+// ParseMeta parses the item metadata and body of a markdown file. It returns
+// ErrNoRegis3Block if the file has no frontmatter in an accepted format or
+// none of the keys holds a block with a type or name.
+func (d Dialect) ParseMeta(content []byte) (*Regis3Meta, *frontmatter.Document, error) {
+	doc, err := frontmatter.ParseBytes(content)
+	if err == frontmatter.ErrNoFrontmatter {
+		return nil, nil, ErrNoRegis3Block
+	}
+	if err != nil {
+		return nil, nil, formatYAMLError(err)
+	}
+	if !d.Accepts(doc.Format) {
+		return nil, doc, ErrNoRegis3Block
+	}
+
+	for _, key := range d.keys() {
+		var meta Regis3Meta
+		found, err := doc.DecodeKey(key, &meta)
+		if err != nil && doc.Format == frontmatter.FormatTOML {
+			return nil, doc, fmt.Errorf("TOML error: %w", err)
+		}
+		if err != nil {
+			return nil, doc, formatYAMLError(err)
+		}
+		if found && (meta.Type != "" || meta.Name != "") {
+			return &meta, doc, nil
+		}
+	}
+	return nil, doc, ErrNoRegis3Block
+}
+
+// Locate returns the position of an item field in a markdown file: the key
+// of the field in the metadata block, or the block itself when the field is
+// missing. The "content" field is the start of the body. Where no position
+// is known, e.g. in TOML frontmatter, it is the start of the file.
+func (d Dialect) Locate(content []byte, field string) frontmatter.Position {
+	start := frontmatter.Position{Line: 1, Column: 1}
+	doc, err := frontmatter.ParseBytes(content)
+	if err != nil {
+		return start
+	}
+	if field == "content" {
+		return frontmatter.Position{Line: doc.BodyLine(), Column: 1}
+	}
+	for _, key := range d.keys() {
+		if field != "" {
+			if pos, ok := doc.KeyPosition(key + "." + field); ok {
+				return pos
+			}
+		}
+		if pos, ok := doc.KeyPosition(key); ok {
+			return pos
+		}
+	}
+	return start
+}
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_ParseMeta(t *testing.T) {
+	legacy := Dialect{
+		Formats: []frontmatter.Format{frontmatter.FormatYAML, frontmatter.FormatTOML},
+		Keys:    []string{"regis3", "regis", "meta.regis3"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		content  string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "default dialect",
+			content:  "---\nregis3:\n  type: skill\n  name: plain\n---\nBody\n",
+			wantName: "plain",
+		},
+		{
+			name:    "default dialect ignores alias keys",
+			content: "---\nregis:\n  type: skill\n  name: legacy\n---\nBody\n",
+			wantErr: ErrNoRegis3Block,
+		},
+		{
+			name:    "default dialect ignores toml",
+			content: "+++\n[regis3]\ntype = \"skill\"\nname = \"toml\"\n+++\nBody\n",
+			wantErr: ErrNoRegis3Block,
+		},
+		{
+			name:     "alias key",
+			dialect:  legacy,
+			content:  "---\nregis:\n  type: skill\n  name: legacy\n---\nBody\n",
+			wantName: "legacy",
+		},
+		{
+			name:     "nested key",
+			dialect:  legacy,
+			content:  "---\nmeta:\n  regis3:\n    type: skill\n    name: nested\n---\nBody\n",
+			wantName: "nested",
+		},
+		{
+			name:     "keys are tried in order",
+			dialect:  legacy,
+			content:  "---\nregis:\n  type: skill\n  name: second\nregis3:\n  type: skill\n  name: first\n---\nBody\n",
+			wantName: "first",
+		},
+		{
+			name:     "toml",
+			dialect:  legacy,
+			content:  "+++\n[regis]\ntype = \"skill\"\nname = \"toml\"\n+++\nBody\n",
+			wantName: "toml",
+		},
+		{
+			name:     "yaml document end marker",
+			content:  "---\nregis3:\n  type: skill\n  name: ended\n...\nBody\n",
+			wantName: "ended",
+		},
+		{
+			name:    "no frontmatter",
+			dialect: legacy,
+			content: "# Just markdown\n",
+			wantErr: ErrNoRegis3Block,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, doc, err := tt.dialect.ParseMeta([]byte(tt.content))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, meta.Name)
+			assert.Equal(t, "Body\n", doc.Body)
+		})
+	}
+
+	t.Run("invalid toml", func(t *testing.T) {
+		_, _, err := legacy.ParseMeta([]byte("+++\n[regis\n+++\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TOML error")
+	})
+}
+
+func TestDialect_Validate(t *testing.T) {
+	assert.NoError(t, Dialect{}.Validate())
+	assert.NoError(t, Dialect{Formats: []frontmatter.Format{"toml"}, Keys: []string{"meta.regis3"}}.Validate())
+	assert.Error(t, Dialect{Formats: []frontmatter.Format{"json"}}.Validate())
+	assert.Error(t, Dialect{Keys: []string{"meta..regis3"}}.Validate())
+}
+
+func TestScanner_Dialect(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "legacy.md"),
+		[]byte("+++\n[meta.regis3]\ntype = \"skill\"\nname = \"legacy\"\ndesc = \"A legacy skill\"\n+++\n# Legacy\n"), 0644))
+
+	scanner := NewScanner(dir)
+	result, err := scanner.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+	assert.Len(t, result.Skipped, 1)
+
+	scanner.Dialect = Dialect{Formats: []frontmatter.Format{frontmatter.FormatTOML}, Keys: []string{"meta.regis3"}}
+	result, err = scanner.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "skill:legacy", result.Items[0].FullName())
+	assert.Equal(t, "# Legacy\n", result.Items[0].Content)
+}
+
+func TestDialect_Locate(t *testing.T) {
+	nested := Dialect{Keys: []string{"regis3", "meta.regis3"}}
+	content := "---\nregis3:\n  type: skill\n  name: demo\n  desc: A demo\n---\n\n# Demo\n"
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		content string
+		field   string
+		want    frontmatter.Position
+	}{
+		{name: "field", content: content, field: "desc", want: frontmatter.Position{Line: 5, Column: 3}},
+		{name: "missing field points at the block", content: content, field: "tags", want: frontmatter.Position{Line: 2, Column: 1}},
+		{name: "no field", content: content, want: frontmatter.Position{Line: 2, Column: 1}},
+		{name: "content", content: content, field: "content", want: frontmatter.Position{Line: 7, Column: 1}},
+		{
+			name:    "nested key",
+			dialect: nested,
+			content: "---\nmeta:\n  regis3:\n    name: demo\n---\n",
+			field:   "name",
+			want:    frontmatter.Position{Line: 4, Column: 5},
+		},
+		{name: "toml", content: "+++\n[regis3]\nname = \"demo\"\n+++\n", field: "name", want: frontmatter.Position{Line: 1, Column: 1}},
+		{name: "no frontmatter", content: "# Demo\n", field: "name", want: frontmatter.Position{Line: 1, Column: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.dialect.Locate([]byte(tt.content), tt.field))
+		})
+	}
+}
+
+func TestValidationResult_Locate(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "skills", "demo.md"), []byte("---\nregis3:\n  type: skill\n  name: Demo\n  desc: Short\n---\nBody\n"), 0644))
+
+	result := &ValidationResult{}
+	result.AddWarning("skills/demo.md", "name", "should be kebab-case")
+	result.AddWarning("skills/demo.md", "tags", "no tags specified")
+	result.AddError("skills/missing.md", "desc", "required field is missing")
+	result.Issues = append(result.Issues, ValidationIssue{Path: "skills/demo.md", Field: "content", Line: 9, Column: 1})
+	result.Locate(root, Dialect{})
+
+	var positions [][2]int
+	for _, issue := range result.Issues {
+		positions = append(positions, [2]int{issue.Line, issue.Column})
+	}
+	assert.Equal(t, [][2]int{{4, 3}, {2, 1}, {1, 1}, {9, 1}}, positions)
+}
@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+)
+
+// Limits on the directories listed in an item's files field, so a stray
+// entry can't copy a whole tree into every project.
+const (
+	// MaxDirFiles is the most files a directory entry may contain.
+	MaxDirFiles = 500
+
+	// MaxDirSize is the most bytes a directory entry may contain.
+	MaxDirSize = 10 << 20
+)
+
+// ExpandFiles returns the item's additional files relative to its source
+// directory, with directory entries (e.g. "reference/") replaced by the
+// files they contain, recursively and in path order. Hidden files and
+// directories are skipped. Entries that don't exist are returned as they
+// are; the files check reports those.
+func (i *Item) ExpandFiles(registryPath string) ([]string, error) {
+	var files []string
+	for _, file := range i.Files {
+		path, err := pathutil.Join(registryPath, i.SourceDir, file)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			files = append(files, file)
+			continue
+		}
+		dirFiles, err := listDir(path, strings.TrimSuffix(filepath.ToSlash(file), "/"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// listDir lists the files under dir as paths prefixed with entry, enforcing
+// MaxDirFiles and MaxDirSize.
+func listDir(dir, entry string) ([]string, error) {
+	var files []string
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil // symlinked directories aren't followed
+		}
+		size += info.Size()
+		if len(files) == MaxDirFiles {
+			return fmt.Errorf("%s/ has more than %d files", entry, MaxDirFiles)
+		}
+		if size > MaxDirSize {
+			return fmt.Errorf("%s/ is larger than %s", entry, FormatSize(MaxDirSize))
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, entry+"/"+filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// InstallFiles returns the additional files the item installs, as recorded
+// in the manifest: directory entries are expanded to the files they
+// contained when the manifest was built. Items built without checksums list
+// their declared files.
+func (i *Item) InstallFiles() []string {
+	if len(i.Checksums) == 0 {
+		return i.Files
+	}
+	files := make([]string, len(i.Checksums))
+	for n, c := range i.Checksums {
+		files[n] = c.Path
+	}
+	return files
+}
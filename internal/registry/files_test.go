@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItem_ExpandFiles(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, root, "skills/tool.md", "# tool")
+	writeRegistryFile(t, root, "skills/asset.txt", "asset")
+	writeRegistryFile(t, root, "skills/reference/b.md", "b")
+	writeRegistryFile(t, root, "skills/reference/a/c.md", "c")
+	writeRegistryFile(t, root, "skills/reference/.git/HEAD", "ref")
+
+	item := &Item{
+		Regis3Meta: Regis3Meta{Type: "skill", Name: "tool", Files: []string{"asset.txt", "reference/", "missing.txt"}},
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	}
+	files, err := item.ExpandFiles(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asset.txt", "reference/a/c.md", "reference/b.md", "missing.txt"}, files)
+
+	item.Files = []string{"reference"}
+	files, err = item.ExpandFiles(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reference/a/c.md", "reference/b.md"}, files, "the trailing slash is optional")
+
+	item.ComputeChecksums(root)
+	assert.Equal(t, files, item.InstallFiles())
+	require.NoError(t, item.VerifyFiles(root))
+
+	require.NoError(t, os.Remove(filepath.Join(root, "skills", "reference", "b.md")))
+	assert.ErrorContains(t, item.VerifyFiles(root), "missing file: reference/b.md")
+}
+
+func TestItem_ExpandFilesLimits(t *testing.T) {
+	t.Run("too many files", func(t *testing.T) {
+		root := t.TempDir()
+		dir := filepath.Join(root, "skills", "many")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		for n := 0; n <= MaxDirFiles; n++ {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%03d.txt", n)), nil, 0644))
+		}
+		item := &Item{Regis3Meta: Regis3Meta{Files: []string{"many/"}}, SourceDir: "skills"}
+
+		_, err := item.ExpandFiles(root)
+		assert.ErrorContains(t, err, "many/ has more than 500 files")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		root := t.TempDir()
+		writeRegistryFile(t, root, "skills/big/blob.bin", strings.Repeat("x", MaxDirSize+1))
+		item := &Item{Regis3Meta: Regis3Meta{Files: []string{"big/"}}, SourceDir: "skills"}
+
+		_, err := item.ExpandFiles(root)
+		assert.ErrorContains(t, err, "big/ is larger than")
+
+		result := NewValidator(root).ValidateItems([]*Item{item})
+		var files []string
+		for _, issue := range result.Errors() {
+			if issue.Field == "files" {
+				files = append(files, issue.Message)
+			}
+		}
+		require.Len(t, files, 1)
+		assert.Contains(t, files[0], "big/ is larger than 10")
+	})
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Filter restricts which registry files a build scans. Patterns are globs
+// relative to the registry root using forward slashes; "**" matches any
+// number of directories. A pattern without a slash matches the file or
+// directory name at any depth, like .gitignore.
+type Filter struct {
+	// Include limits the build to matching files. Empty means all files.
+	Include []string `json:"include,omitempty"`
+
+	// Exclude skips matching files and directories.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty reports whether the filter lets every file through.
+func (f Filter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Validate checks that all patterns are well-formed.
+func (f Filter) Validate() error {
+	for _, p := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the file at rel should be scanned. A file is
+// excluded when it or any of its parent directories matches an exclude pattern.
+func (f Filter) Matches(rel string) bool {
+	for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matchAny(f.Exclude, dir) {
+			return false
+		}
+	}
+	return len(f.Include) == 0 || matchAny(f.Include, rel)
+}
+
+// matchAny reports whether rel matches any of the patterns.
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether the slash-separated path name matches pattern.
+// Invalid patterns never match; use Filter.Validate to report them.
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		// Match the base name at any depth
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments, letting "**" consume zero or more of them.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"skills/**", "skills/git.md", true},
+		{"skills/**", "skills/git/SKILL.md", true},
+		{"skills/**", "agents/git.md", false},
+		{"skills/*.md", "skills/git.md", true},
+		{"skills/*.md", "skills/git/SKILL.md", false},
+		{"**/wip-*.md", "wip-idea.md", true},
+		{"**/wip-*.md", "skills/deep/wip-idea.md", true},
+		{"skills/**/SKILL.md", "skills/SKILL.md", true},
+		{"skills/**/SKILL.md", "skills/a/b/SKILL.md", true},
+		{"*.draft.md", "skills/git.draft.md", true},
+		{"drafts", "skills/drafts", true},
+		{"drafts", "drafts.md", false},
+		{"skills/[", "skills/x.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		path   string
+		want   bool
+	}{
+		{name: "empty filter", filter: Filter{}, path: "skills/git.md", want: true},
+		{name: "included", filter: Filter{Include: []string{"skills/**"}}, path: "skills/git.md", want: true},
+		{name: "not included", filter: Filter{Include: []string{"skills/**"}}, path: "agents/a.md", want: false},
+		{name: "excluded", filter: Filter{Exclude: []string{"drafts/**"}}, path: "drafts/wip.md", want: false},
+		{name: "excluded directory name", filter: Filter{Exclude: []string{"drafts"}}, path: "skills/drafts/wip.md", want: false},
+		{name: "exclude wins over include", filter: Filter{Include: []string{"skills/**"}, Exclude: []string{"**/wip-*"}}, path: "skills/wip-x.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.path))
+		})
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	assert.NoError(t, Filter{Include: []string{"skills/**"}, Exclude: []string{"*.draft.md"}}.Validate())
+	assert.Error(t, Filter{Exclude: []string{"skills/["}}.Validate())
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// metaKeyOrder is the canonical order of regis3 block keys: the order of
+// the Regis3Meta fields.
+var metaKeyOrder = func() map[string]int {
+	order := make(map[string]int)
+	t := reflect.TypeOf(Regis3Meta{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			order[name] = len(order)
+		}
+	}
+	return order
+}()
+
+// Format normalizes the YAML frontmatter of an item file: regis3 block keys
+// in canonical order (unknown keys last, in their original order), strings
+// quoted only where needed and then with double quotes, lists in block
+// style, 2-space indentation and a body ending in a single newline.
+// Comments are kept. Formatting formatted content changes nothing.
+//
+// Files without YAML frontmatter are returned unchanged.
+func (d Dialect) Format(content []byte) ([]byte, error) {
+	doc, err := frontmatter.ParseBytes(content)
+	if err == frontmatter.ErrNoFrontmatter {
+		return content, nil
+	}
+	if err != nil {
+		return nil, formatYAMLError(err)
+	}
+	if doc.Format != frontmatter.FormatYAML {
+		return content, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc.Frontmatter), &root); err != nil {
+		return nil, formatYAMLError(err)
+	}
+	if len(root.Content) == 0 {
+		return content, nil
+	}
+
+	for _, key := range d.keys() {
+		node := &root
+		for _, part := range strings.Split(key, ".") {
+			if node = mappingValue(node, part); node == nil {
+				break
+			}
+		}
+		if node != nil && node.Kind == yaml.MappingNode {
+			sortMetaKeys(node)
+		}
+	}
+	normalizeStyle(&root)
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	enc.Close()
+
+	body := strings.TrimRight(doc.Body, "\n")
+	if body != "" {
+		body += "\n"
+	}
+	return []byte("---\n" + b.String() + "---\n" + body), nil
+}
+
+// sortMetaKeys orders the keys of a regis3 block canonically. The sort is
+// stable, so unknown keys keep their order.
+func sortMetaKeys(node *yaml.Node) {
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+
+	rank := func(p pair) int {
+		if r, ok := metaKeyOrder[p.key.Value]; ok {
+			return r
+		}
+		return len(metaKeyOrder)
+	}
+	// Insertion sort keeps equal ranks in place
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && rank(pairs[j]) < rank(pairs[j-1]); j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+	}
+}
+
+// normalizeStyle switches lists and mappings to block style and quotes
+// single-line strings only if they need it, with double quotes.
+func normalizeStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && !strings.Contains(node.Value, "\n") &&
+		node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		node.Style = 0
+		if needsQuotes(node.Value) {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
+
+// needsQuotes reports whether a string can't be written as a plain scalar.
+func needsQuotes(s string) bool {
+	out, err := yaml.Marshal(s)
+	return err != nil || len(out) == 0 || out[0] == '\'' || out[0] == '"'
+}
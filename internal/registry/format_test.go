@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		content string
+		want    string
+	}{
+		{
+			name:    "already formatted",
+			content: "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  tags:\n    - test\n---\n# Testing\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  tags:\n    - test\n---\n# Testing\n",
+		},
+		{
+			name:    "key order",
+			content: "---\nregis3:\n  tags: [test]\n  custom: kept\n  desc: Testing practices\n  name: testing\n  type: skill\n---\n# Testing\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  tags:\n    - test\n  custom: kept\n---\n# Testing\n",
+		},
+		{
+			name:    "quoting",
+			content: "---\nregis3:\n  type: 'skill'\n  name: \"testing\"\n  desc: 'Use: colons'\n  author: '123'\n  status: 'yes'\n---\nBody\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  desc: \"Use: colons\"\n  status: \"yes\"\n  author: \"123\"\n---\nBody\n",
+		},
+		{
+			name:    "indentation",
+			content: "---\nregis3:\n    type: skill\n    name: testing\n    deps:\n        - skill:git\n---\nBody\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  deps:\n    - skill:git\n---\nBody\n",
+		},
+		{
+			name:    "comments are kept",
+			content: "---\n# Reviewed quarterly\nregis3:\n  name: testing # short name\n  type: skill\n---\nBody\n",
+			want:    "---\n# Reviewed quarterly\nregis3:\n  type: skill\n  name: testing # short name\n---\nBody\n",
+		},
+		{
+			name:    "trailing newlines",
+			content: "---\nregis3:\n  type: skill\n  name: testing\n---\nBody\n\n\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n---\nBody\n",
+		},
+		{
+			name:    "missing trailing newline",
+			content: "---\nregis3:\n  type: skill\n  name: testing\n...\nBody",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n---\nBody\n",
+		},
+		{
+			name:    "dialect keys",
+			dialect: Dialect{Keys: []string{"meta.regis3"}},
+			content: "---\nmeta:\n  regis3:\n    name: testing\n    type: skill\n---\nBody\n",
+			want:    "---\nmeta:\n  regis3:\n    type: skill\n    name: testing\n---\nBody\n",
+		},
+		{
+			name:    "toml is unchanged",
+			content: "+++\n[regis3]\nname = \"testing\"\ntype = \"skill\"\n+++\nBody\n\n",
+			want:    "+++\n[regis3]\nname = \"testing\"\ntype = \"skill\"\n+++\nBody\n\n",
+		},
+		{
+			name:    "no frontmatter",
+			content: "# Just markdown\n\n",
+			want:    "# Just markdown\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dialect.Format([]byte(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			again, err := tt.dialect.Format(got)
+			require.NoError(t, err)
+			assert.Equal(t, string(got), string(again), "formatting is idempotent")
+		})
+	}
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, err := Dialect{}.Format([]byte("---\nregis3: [\n---\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDialect_Format_Idempotent(t *testing.T) {
+	// Multi-line and folded strings take several passes in naive encoders
+	content := "---\nregis3:\n  type: skill\n  name: testing\n  desc: >\n    Long description\n    over two lines\n  run: |\n    go test ./...\n    go vet ./...\n---\nBody\n"
+
+	once, err := Dialect{}.Format([]byte(content))
+	require.NoError(t, err)
+	twice, err := Dialect{}.Format(once)
+	require.NoError(t, err)
+	assert.Equal(t, string(once), string(twice))
+
+	doc, err := frontmatter.ParseBytes(once)
+	require.NoError(t, err)
+	var fm FrontMatter
+	require.NoError(t, doc.Decode(&fm))
+	assert.Equal(t, "Long description over two lines\n", fm.Regis3.Desc)
+	assert.Equal(t, "go test ./...\ngo vet ./...\n", fm.Regis3.Run)
+}
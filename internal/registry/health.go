@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StaleDraftAge is how long a draft may go unchanged before it counts as
+// stale in the health score.
+const StaleDraftAge = 90 * 24 * time.Hour
+
+// Health score weights: the points lost when every item (or file, for
+// orphans) has the problem. Partial problems lose a proportional share.
+const (
+	healthWeightWarnings = 30
+	healthWeightUntagged = 20
+	healthWeightUnowned  = 15
+	healthWeightStale    = 15
+	healthWeightOrphans  = 20
+)
+
+// Health summarizes the maintenance state of a registry as a score from 0
+// to 100, with the items that lower it.
+type Health struct {
+	// Score is 100 for a registry without problems.
+	Score int `json:"score"`
+
+	// Items is the number of items scored.
+	Items int `json:"items"`
+
+	// Warned lists items with validation warnings.
+	Warned []string `json:"warned,omitempty"`
+
+	// Untagged lists items without tags.
+	Untagged []string `json:"untagged,omitempty"`
+
+	// Unowned lists items without an author.
+	Unowned []string `json:"unowned,omitempty"`
+
+	// StaleDrafts lists drafts unchanged for longer than StaleDraftAge.
+	StaleDrafts []string `json:"stale_drafts,omitempty"`
+
+	// Orphans lists markdown files no item uses.
+	Orphans []string `json:"orphans,omitempty"`
+}
+
+// ComputeHealth scores the manifest's items. warned lists the items with
+// validation warnings; orphans are found on disk with the ignore rules (nil
+// for the defaults).
+func ComputeHealth(registryPath string, ignore *Ignore, manifest *Manifest, warned []string) (*Health, error) {
+	orphans, err := FindOrphans(registryPath, ignore, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &Health{Items: len(manifest.Items)}
+	for _, orphan := range orphans {
+		health.Orphans = append(health.Orphans, filepath.ToSlash(orphan.Path))
+	}
+	seen := make(map[string]bool, len(warned))
+	for _, id := range warned {
+		if _, ok := manifest.Items[id]; ok && !seen[id] {
+			seen[id] = true
+			health.Warned = append(health.Warned, id)
+		}
+	}
+
+	cutoff := time.Now().Add(-StaleDraftAge)
+	for id, item := range manifest.Items {
+		if len(item.Tags) == 0 {
+			health.Untagged = append(health.Untagged, id)
+		}
+		if strings.TrimSpace(item.Author) == "" {
+			health.Unowned = append(health.Unowned, id)
+		}
+		if item.Status == string(StatusDraft) {
+			info, err := os.Stat(filepath.Join(registryPath, item.Source))
+			// Checkouts reset modification times, so a fresh clone has no stale drafts
+			if err == nil && info.ModTime().Before(cutoff) {
+				health.StaleDrafts = append(health.StaleDrafts, id)
+			}
+		}
+	}
+
+	sort.Strings(health.Warned)
+	sort.Strings(health.Untagged)
+	sort.Strings(health.Unowned)
+	sort.Strings(health.StaleDrafts)
+	health.Score = health.score()
+	return health, nil
+}
+
+// recordHealth scores the manifest. Filtered manifests leave out items on
+// purpose and are not scored.
+func (m *Manifest) recordHealth(registryPath string, ignore *Ignore, warned []string) {
+	if m.Filter != nil {
+		return
+	}
+	if health, err := ComputeHealth(registryPath, ignore, m, warned); err == nil {
+		m.Health = health
+	}
+}
+
+// score computes the score from the problem lists.
+func (h *Health) score() int {
+	ratio := func(n, total int) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(n) / float64(total)
+	}
+
+	penalty := healthWeightWarnings*ratio(len(h.Warned), h.Items) +
+		healthWeightUntagged*ratio(len(h.Untagged), h.Items) +
+		healthWeightUnowned*ratio(len(h.Unowned), h.Items) +
+		healthWeightStale*ratio(len(h.StaleDrafts), h.Items) +
+		healthWeightOrphans*ratio(len(h.Orphans), h.Items+len(h.Orphans))
+	return 100 - int(math.Round(penalty))
+}
+
+// WarnedItems returns the items with validation warnings, matching issues to
+// items by source path.
+func WarnedItems(manifest *Manifest, result *ValidationResult) []string {
+	warnedPaths := make(map[string]bool)
+	for _, issue := range result.Warnings() {
+		warnedPaths[filepath.Clean(issue.Path)] = true
+	}
+
+	var warned []string
+	for id, item := range manifest.Items {
+		if warnedPaths[filepath.Clean(item.Source)] {
+			warned = append(warned, id)
+		}
+	}
+	return warned
+}
+
+// BadgeColor returns the shields.io-style color for a health score.
+func BadgeColor(score int) string {
+	switch {
+	case score >= 90:
+		return "#4c1"
+	case score >= 80:
+		return "#97ca00"
+	case score >= 70:
+		return "#dfb317"
+	case score >= 60:
+		return "#fe7d37"
+	default:
+		return "#e05d44"
+	}
+}
+
+// Badge renders the score as a flat SVG badge for a README.
+func (h *Health) Badge() []byte {
+	const label = "registry health"
+	value := fmt.Sprintf("%d/100", h.Score)
+
+	// Approximate Verdana 11px glyph widths
+	labelWidth := textWidth(label) + 10
+	valueWidth := textWidth(value) + 10
+	width := labelWidth + valueWidth
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, value)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	b.WriteString(`<g clip-path="url(#r)">`)
+	fmt.Fprintf(&b, `<rect width="%d" height="20" fill="#555"/>`, labelWidth)
+	fmt.Fprintf(&b, `<rect x="%d" width="%d" height="20" fill="%s"/>`, labelWidth, valueWidth, BadgeColor(h.Score))
+	fmt.Fprintf(&b, `<rect width="%d" height="20" fill="url(#s)"/>`, width)
+	b.WriteString(`</g>`)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`, labelWidth/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, labelWidth/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`, labelWidth+valueWidth/2, value)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, labelWidth+valueWidth/2, value)
+	b.WriteString(`</g></svg>`)
+	b.WriteString("\n")
+	return []byte(b.String())
+}
+
+// textWidth estimates the rendered width of s in pixels.
+func textWidth(s string) int {
+	width := 0.0
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == 'i' || r == 'l' || r == 't' || r == 'f' || r == 'r':
+			width += 4.5
+		case r >= 'A' && r <= 'Z', r == 'm', r == 'w':
+			width += 9
+		default:
+			width += 7
+		}
+	}
+	return int(math.Ceil(width))
+}
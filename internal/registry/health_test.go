@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRegistry_Health(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, root, "skills/good.md", "---\nregis3:\n  type: skill\n  name: good\n  desc: Good skill that explains how the team works with a registry\n  tags: [go]\n  author: team-a\n---\n# good\n")
+	writeRegistryFile(t, root, "skills/bare.md", skillFile("bare", "Bare skill that explains how the team works with a registry"))
+	writeRegistryFile(t, root, "skills/heading.md", "---\nregis3:\n  type: skill\n  name: heading\n  desc: Heading skill that explains how the team works with a registry\n  tags: [go]\n  author: team-a\n---\n# Something else\n")
+	writeRegistryFile(t, root, "skills/old.md", "---\nregis3:\n  type: skill\n  name: old\n  desc: Old skill that explains how the team works with a registry\n  status: draft\n  tags: [go]\n  author: team-a\n---\n# old\n")
+	writeRegistryFile(t, root, "notes/todo.md", "# Todo\n")
+
+	old := time.Now().Add(-StaleDraftAge - time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "skills/old.md"), old, old))
+
+	result, err := BuildRegistry(root)
+	require.NoError(t, err)
+	health := result.Manifest.Health
+	require.NotNil(t, health)
+
+	assert.Equal(t, 4, health.Items)
+	assert.Equal(t, []string{"skill:bare", "skill:heading"}, health.Warned)
+	assert.Equal(t, []string{"skill:bare"}, health.Untagged)
+	assert.Equal(t, []string{"skill:bare"}, health.Unowned)
+	assert.Equal(t, []string{"skill:old"}, health.StaleDrafts)
+	assert.Equal(t, []string{"notes/todo.md"}, health.Orphans)
+	// 30*2/4 + 20/4 + 15/4 + 15/4 + 20/5 = 31.5
+	assert.Equal(t, 68, health.Score)
+
+	loaded, err := LoadManifestFromRegistry(root)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.Health)
+	assert.Equal(t, health.Score, loaded.Health.Score)
+
+	t.Run("partial build keeps warnings of unchanged items", func(t *testing.T) {
+		writeRegistryFile(t, root, "skills/bare.md", "---\nregis3:\n  type: skill\n  name: bare\n  desc: Bare skill that explains how the team works with a registry\n  tags: [go]\n  author: team-b\n---\n# bare\n")
+
+		result, err := UpdateRegistry(root, []string{"skills/bare.md"}, BuildOptions{})
+		require.NoError(t, err)
+		health := result.Manifest.Health
+		require.NotNil(t, health)
+		assert.Equal(t, []string{"skill:heading"}, health.Warned)
+		assert.Empty(t, health.Untagged)
+		assert.Empty(t, health.Unowned)
+	})
+
+	t.Run("filtered builds are not scored", func(t *testing.T) {
+		result, err := BuildRegistryWithOptions(root, BuildOptions{Filter: Filter{Include: []string{"skills/good.md"}}})
+		require.NoError(t, err)
+		assert.Nil(t, result.Manifest.Health)
+	})
+}
+
+func TestHealth_Score(t *testing.T) {
+	tests := []struct {
+		name   string
+		health Health
+		want   int
+	}{
+		{"empty registry", Health{}, 100},
+		{"no problems", Health{Items: 10}, 100},
+		{"every item warned", Health{Items: 2, Warned: []string{"a", "b"}}, 70},
+		{"only orphans", Health{Orphans: []string{"a.md"}}, 80},
+		{
+			"everything wrong",
+			Health{Items: 1, Warned: []string{"a"}, Untagged: []string{"a"}, Unowned: []string{"a"}, StaleDrafts: []string{"a"}, Orphans: []string{"b.md"}},
+			10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.health.score())
+		})
+	}
+}
+
+func TestHealth_Badge(t *testing.T) {
+	badge := string((&Health{Score: 87}).Badge())
+
+	assert.True(t, strings.HasPrefix(badge, "<svg "))
+	assert.Contains(t, badge, "registry health: 87/100")
+	assert.Contains(t, badge, BadgeColor(87))
+	assert.Equal(t, "#97ca00", BadgeColor(87))
+	assert.Equal(t, "#e05d44", BadgeColor(12))
+}
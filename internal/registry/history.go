@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultHistoryFile is the build history file in the .build directory, with
+// one JSON line of statistics per build.
+const DefaultHistoryFile = "history.jsonl"
+
+// HistoryEntry records the statistics of one build.
+type HistoryEntry struct {
+	// Time is when the build ran.
+	Time time.Time `json:"time"`
+
+	// Items is the number of items; Stats breaks it down by type.
+	Items int   `json:"items"`
+	Stats Stats `json:"stats"`
+
+	// Warned is the number of items with validation warnings.
+	Warned int `json:"warned"`
+
+	// Size is the total length of the items' content in bytes.
+	Size int `json:"size"`
+
+	// Score is the health score.
+	Score int `json:"score"`
+}
+
+// NewHistoryEntry returns the statistics of a built manifest.
+func NewHistoryEntry(manifest *Manifest) HistoryEntry {
+	entry := HistoryEntry{
+		Time:  manifest.Generated,
+		Items: manifest.Stats.Total(),
+		Stats: manifest.Stats,
+	}
+	for _, item := range manifest.Items {
+		entry.Size += item.Size
+	}
+	if manifest.Health != nil {
+		entry.Warned = len(manifest.Health.Warned)
+		entry.Score = manifest.Health.Score
+	}
+	return entry
+}
+
+// sameStats reports whether two entries differ only in time.
+func (e HistoryEntry) sameStats(other HistoryEntry) bool {
+	e.Time = other.Time
+	return e == other
+}
+
+// HistoryPath returns the path of the registry's build history.
+func HistoryPath(registryPath string) string {
+	return filepath.Join(registryPath, DefaultBuildDir, DefaultHistoryFile)
+}
+
+// LoadHistory reads the registry's build history, oldest first. A registry
+// without history has none; lines that can't be parsed (e.g. cut off by an
+// interrupted write) are skipped.
+func LoadHistory(registryPath string) ([]HistoryEntry, error) {
+	f, err := os.Open(HistoryPath(registryPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read build history: %w", err)
+	}
+	return entries, nil
+}
+
+// recordHistory appends the manifest's statistics to the build history.
+// Filtered manifests leave out items on purpose and are not recorded, nor
+// are builds that changed nothing since the last entry, so rebuilds while
+// editing don't drown the trend.
+func recordHistory(registryPath string, manifest *Manifest) error {
+	if manifest.Filter != nil {
+		return nil
+	}
+	entry := NewHistoryEntry(manifest)
+	history, err := LoadHistory(registryPath)
+	if err != nil {
+		return err
+	}
+	if len(history) > 0 && history[len(history)-1].sameStats(entry) {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to record build history: %w", err)
+	}
+	f, err := os.OpenFile(HistoryPath(registryPath), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to record build history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to record build history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRegistry_History(t *testing.T) {
+	root := t.TempDir()
+	writeRegistryFile(t, root, "skills/good.md", "---\nregis3:\n  type: skill\n  name: good\n  desc: Good skill that explains how the team works with a registry\n  tags: [go]\n  author: team-a\n---\n# good\n")
+
+	history, err := LoadHistory(root)
+	require.NoError(t, err)
+	assert.Empty(t, history, "no builds yet")
+
+	_, err = BuildRegistry(root)
+	require.NoError(t, err)
+
+	// Rebuilding without changes adds nothing
+	_, err = BuildRegistry(root)
+	require.NoError(t, err)
+	history, err = LoadHistory(root)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Items)
+	assert.Equal(t, 1, history[0].Stats.Skills)
+	assert.Equal(t, len("# good\n"), history[0].Size)
+	assert.Equal(t, 0, history[0].Warned)
+	assert.Equal(t, 100, history[0].Score)
+
+	writeRegistryFile(t, root, "commands/deploy.md", "---\nregis3:\n  type: command\n  name: deploy\n  desc: Deploy\n---\n# deploy\n")
+	_, err = BuildRegistry(root)
+	require.NoError(t, err)
+
+	t.Run("partial builds are recorded", func(t *testing.T) {
+		writeRegistryFile(t, root, "skills/good.md", "---\nregis3:\n  type: skill\n  name: good\n  desc: Good skill that explains how the team works with a registry\n  tags: [go]\n  author: team-a\n---\n# good\n\nMore.\n")
+		_, err := UpdateRegistry(root, []string{"skills/good.md"}, BuildOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("filtered builds are not recorded", func(t *testing.T) {
+		_, err := BuildRegistryWithOptions(root, BuildOptions{Filter: Filter{Include: []string{"skills/*"}}})
+		require.NoError(t, err)
+	})
+
+	history, err = LoadHistory(root)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, 2, history[1].Items)
+	assert.Equal(t, 1, history[1].Stats.Commands)
+	assert.Equal(t, 1, history[1].Warned, "the command has a short description")
+	assert.Greater(t, history[2].Size, history[1].Size)
+	assert.False(t, history[2].Time.Before(history[1].Time))
+}
+
+func TestLoadHistory_SkipsBrokenLines(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(HistoryPath(root)), 0755))
+	require.NoError(t, os.WriteFile(HistoryPath(root), []byte("{\"items\":3}\n{\"items\":4,\"si\n"), 0644))
+
+	history, err := LoadHistory(root)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 3, history[0].Items)
+}
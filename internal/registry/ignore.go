@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// TargetsDir holds target definitions when they are kept in the registry.
+const TargetsDir = "targets"
+
+// Ignore decides which registry paths are not item sources: hidden
+// directories such as .build and .git, the targets directory, the import
+// staging directory and files the build filter leaves out. Builds, partial
+// updates and orphan detection share it, so they agree on what belongs to
+// the registry. Staged files may already carry suggested frontmatter, but
+// they aren't items until they are processed.
+type Ignore struct {
+	// staging is the staging directory relative to the registry root, or
+	// "" when it lies outside.
+	staging string
+
+	// filter restricts which files are items.
+	filter Filter
+}
+
+// NewIgnore creates the ignore rules for a registry. stagingDir is relative
+// to the registry root or absolute; empty means DefaultStagingDir.
+func NewIgnore(registryPath, stagingDir string, filter Filter) *Ignore {
+	return &Ignore{
+		staging: StagingRel(registryPath, stagingDir),
+		filter:  filter,
+	}
+}
+
+// SkipDir reports whether the directory at rel, relative to the registry
+// root with forward slashes, is skipped with everything in it. Only the
+// top-level targets and staging directories are skipped; the registry root
+// never is.
+func (ig *Ignore) SkipDir(rel string) bool {
+	rel = path.Clean(filepath.ToSlash(rel))
+	if rel == "." {
+		return false
+	}
+	if strings.HasPrefix(path.Base(rel), ".") {
+		return true
+	}
+	return rel == TargetsDir || rel == ig.staging
+}
+
+// SkipFile reports whether the file at rel can't be an item source: it is
+// not markdown or lies in a skipped directory. Files the filter leaves out
+// are reported by Excluded instead.
+func (ig *Ignore) SkipFile(rel string) bool {
+	rel = path.Clean(filepath.ToSlash(rel))
+	if !strings.HasSuffix(strings.ToLower(rel), ".md") {
+		return true
+	}
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if ig.SkipDir(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded reports whether the build filter leaves out the file at rel.
+func (ig *Ignore) Excluded(rel string) bool {
+	return !ig.filter.Matches(path.Clean(filepath.ToSlash(rel)))
+}
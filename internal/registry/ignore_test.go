@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnore(t *testing.T) {
+	ignore := NewIgnore("/srv/registry", ".staging", Filter{Exclude: []string{"drafts/**"}})
+
+	tests := []struct {
+		path     string
+		dir      bool
+		skip     bool
+		excluded bool
+	}{
+		{path: ".", dir: true},
+		{path: "skills", dir: true},
+		{path: ".build", dir: true, skip: true},
+		{path: ".git", dir: true, skip: true},
+		{path: "skills/.cache", dir: true, skip: true},
+		{path: "targets", dir: true, skip: true},
+		{path: "skills/targets", dir: true},
+		{path: ".staging", dir: true, skip: true},
+		{path: "import", dir: true},
+		{path: "skills/testing.md"},
+		{path: "skills/notes.txt", skip: true},
+		{path: ".build/manifest.md", skip: true},
+		{path: ".staging/notes.md", skip: true},
+		{path: "targets/readme.md", skip: true},
+		{path: "drafts/idea.md", excluded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.dir {
+				assert.Equal(t, tt.skip, ignore.SkipDir(tt.path))
+				return
+			}
+			assert.Equal(t, tt.skip, ignore.SkipFile(tt.path))
+			assert.Equal(t, tt.excluded, ignore.Excluded(tt.path))
+		})
+	}
+}
+
+func TestIgnore_SharedByBuildAndOrphans(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"skills/testing.md":   "---\nregis3:\n  type: skill\n  name: testing\n  desc: A skill\n---\n# Testing\n",
+		"skills/notes.md":     "# Loose notes\n",
+		".staging/staged.md":  "---\nregis3:\n  type: skill\n  name: staged\n  desc: Not reviewed yet\n---\n# Staged\n",
+		"targets/custom.md":   "# Target notes\n",
+		".build/cache.md":     "# Cache\n",
+		"drafts/idea.md":      "# Idea\n",
+		"import/left-over.md": "# Not the staging directory\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	opts := BuildOptions{StagingDir: ".staging", Filter: Filter{Exclude: []string{"drafts"}}}
+	result, err := BuildRegistryWithOptions(dir, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.Manifest.Items, 1)
+	assert.Empty(t, result.ScanErrors)
+
+	orphans, err := FindOrphans(dir, opts.Ignore(dir), result.Manifest)
+	require.NoError(t, err)
+	var paths []string
+	for _, o := range orphans {
+		paths = append(paths, filepath.ToSlash(o.Path))
+	}
+	assert.Equal(t, []string{"import/left-over.md", "skills/notes.md"}, paths)
+}
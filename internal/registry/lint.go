@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+)
+
+// Content consistency checks, referenced by name in LintConfig.Disable.
+const (
+	// LintHeading warns when the body's H1 shares no words with the item's name or desc.
+	LintHeading = "heading"
+
+	// LintSubagentRole warns when a subagent body has no role instructions.
+	LintSubagentRole = "subagent-role"
+
+	// LintNestedItem warns when a file listed in files: is itself a regis3 item.
+	LintNestedItem = "nested-item"
+)
+
+// LintChecks lists all content consistency checks.
+var LintChecks = []string{LintHeading, LintSubagentRole, LintNestedItem}
+
+// LintConfig selects which content consistency checks run during validation.
+// All checks are enabled by default and only produce warnings.
+type LintConfig struct {
+	// Disable lists checks to skip.
+	Disable []string
+}
+
+// Enabled reports whether a check should run.
+func (c LintConfig) Enabled(check string) bool {
+	for _, d := range c.Disable {
+		if d == check {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLintCheck reports whether name is a known check.
+func IsLintCheck(name string) bool {
+	for _, c := range LintChecks {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePhrases mark instructions that tell a subagent who it is.
+var rolePhrases = []string{"you are", "you're", "your role", "your job", "your task", "act as", "role:"}
+
+// lintItem runs the enabled content consistency checks on an item.
+func (v *Validator) lintItem(item *Item, result *ValidationResult) {
+	if v.Lint.Enabled(LintHeading) {
+		if heading := firstHeading(item.Content); heading != "" && !sharesWord(heading, item.Name+" "+item.Desc) {
+			result.AddWarning(item.Source, "content", fmt.Sprintf("heading %q doesn't match the item name or description", heading))
+		}
+	}
+
+	if v.Lint.Enabled(LintSubagentRole) && item.Type == string(TypeSubagent) {
+		body := strings.ToLower(item.Content)
+		hasRole := false
+		for _, phrase := range rolePhrases {
+			if strings.Contains(body, phrase) {
+				hasRole = true
+				break
+			}
+		}
+		if !hasRole {
+			result.AddWarning(item.Source, "content", `subagent has no role instructions (e.g. "You are a ...")`)
+		}
+	}
+
+	if v.Lint.Enabled(LintNestedItem) {
+		files, _ := item.ExpandFiles(v.RegistryRoot)
+		for _, file := range files {
+			if !strings.HasSuffix(strings.ToLower(file), ".md") {
+				continue
+			}
+			path, err := pathutil.Join(v.RegistryRoot, item.SourceDir, file)
+			if err != nil {
+				continue // reported by the files check
+			}
+			if nested, _ := HasRegis3Frontmatter(path); nested {
+				result.AddWarning(item.Source, "files", fmt.Sprintf("%s is itself a regis3 item; add it to deps instead", file))
+			}
+		}
+	}
+}
+
+// firstHeading returns the text of the first H1 outside code blocks.
+func firstHeading(content string) string {
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if !inCode && strings.HasPrefix(trimmed, "# ") {
+			return strings.TrimSpace(trimmed[2:])
+		}
+	}
+	return ""
+}
+
+// sharesWord reports whether a and b have a significant word in common,
+// ignoring case and a trailing plural "s".
+func sharesWord(a, b string) bool {
+	words := make(map[string]bool)
+	for _, w := range significantWords(b) {
+		words[w] = true
+	}
+	for _, w := range significantWords(a) {
+		if words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// significantWords splits s into lowercase words of three or more characters.
+func significantWords(s string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) < 3 || w == "the" || w == "and" || w == "for" || w == "with" {
+			continue
+		}
+		if len(w) > 3 {
+			w = strings.TrimSuffix(w, "s")
+		}
+		words = append(words, w)
+	}
+	return words
+}
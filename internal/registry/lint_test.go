@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintWarnings returns the messages of warnings on the given field.
+func lintWarnings(result *ValidationResult, field string) []string {
+	var messages []string
+	for _, issue := range result.Warnings() {
+		if issue.Field == field {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return messages
+}
+
+func TestValidator_LintContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    *Item
+		disable []string
+		want    []string
+	}{
+		{
+			name: "heading matches name",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "git-conventions", Desc: "Commit message rules"}, Content: "# Git Conventions\n"},
+		},
+		{
+			name: "heading matches description",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing", Desc: "Unit test patterns"}, Content: "# Patterns for Tests\n"},
+		},
+		{
+			name: "heading unrelated",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing", Desc: "Unit test patterns"}, Content: "# Deployment Checklist\n"},
+			want: []string{`heading "Deployment Checklist" doesn't match the item name or description`},
+		},
+		{
+			name: "heading in code block ignored",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing", Desc: "Unit test patterns"}, Content: "```\n# comment\n```\n# Testing\n"},
+		},
+		{
+			name:    "heading check disabled",
+			item:    &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing", Desc: "Unit test patterns"}, Content: "# Deployment Checklist\n"},
+			disable: []string{LintHeading},
+		},
+		{
+			name: "subagent with role",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "subagent", Name: "reviewer", Desc: "Code reviewer"}, Content: "# Reviewer\n\nYou are a careful code reviewer.\n"},
+		},
+		{
+			name: "subagent without role",
+			item: &Item{Regis3Meta: Regis3Meta{Type: "subagent", Name: "reviewer", Desc: "Code reviewer"}, Content: "# Reviewer\n\nChecklist only.\n"},
+			want: []string{`subagent has no role instructions (e.g. "You are a ...")`},
+		},
+		{
+			name:    "subagent role check disabled",
+			item:    &Item{Regis3Meta: Regis3Meta{Type: "subagent", Name: "reviewer", Desc: "Code reviewer"}, Content: "# Reviewer\n"},
+			disable: []string{LintSubagentRole},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(t.TempDir())
+			v.Lint.Disable = tt.disable
+
+			result := &ValidationResult{}
+			v.lintItem(tt.item, result)
+			assert.Equal(t, tt.want, lintWarnings(result, "content"))
+		})
+	}
+}
+
+func TestValidator_LintNestedItem(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills", "tool")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "REFERENCE.md"), []byte("# Reference\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "other.md"), []byte(skillFile("other", "Another skill")), 0644))
+
+	item := &Item{
+		Regis3Meta: Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool skill", Files: []string{"REFERENCE.md", "other.md"}},
+		Source:     "skills/tool/SKILL.md",
+		SourceDir:  "skills/tool",
+	}
+
+	v := NewValidator(tmpDir)
+	result := &ValidationResult{}
+	v.lintItem(item, result)
+	assert.Equal(t, []string{"other.md is itself a regis3 item; add it to deps instead"}, lintWarnings(result, "files"))
+
+	v.Lint.Disable = []string{LintNestedItem}
+	result = &ValidationResult{}
+	v.lintItem(item, result)
+	assert.Empty(t, lintWarnings(result, "files"))
+}
@@ -1,11 +1,15 @@
 package registry
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/okto-digital/regis3/internal/profile"
 )
 
 const (
@@ -19,9 +23,65 @@ const (
 	DefaultManifestFile = "manifest.json"
 )
 
+// BuildOptions configures how the registry is validated during a build.
+type BuildOptions struct {
+	// DependencyRules restricts which types each item type may depend on.
+	DependencyRules DependencyRules
+
+	// Lint selects the content consistency checks to run.
+	Lint LintConfig
+
+	// SizeBudgets limits content length per item type.
+	SizeBudgets SizeBudgets
+
+	// RequireLicense lists registry paths whose items must declare a
+	// license.
+	RequireLicense []string
+
+	// Filter restricts which files are scanned. A filtered build produces a
+	// manifest of just the matching items.
+	Filter Filter
+
+	// StagingDir is the import staging directory, which builds skip. It is
+	// relative to the registry root or absolute; empty means
+	// DefaultStagingDir.
+	StagingDir string
+
+	// Dialect selects the frontmatter formats and keys item metadata is
+	// read from.
+	Dialect Dialect
+
+	// AllowErrors saves the manifest despite validation errors, leaving out
+	// the items with errors and those depending on them. They are listed
+	// in the manifest's quarantine section.
+	AllowErrors bool
+
+	// Timings, if set, records the scan, parse, validate and write phases.
+	Timings *profile.Timings
+}
+
+// Ignore returns the ignore rules for the build options.
+func (o BuildOptions) Ignore(registryPath string) *Ignore {
+	return NewIgnore(registryPath, o.StagingDir, o.Filter)
+}
+
+// newValidator creates a validator configured with the build options.
+func (o BuildOptions) newValidator(registryPath string) *Validator {
+	validator := NewValidator(registryPath)
+	validator.DependencyRules = o.DependencyRules
+	validator.Lint = o.Lint
+	validator.SizeBudgets = o.SizeBudgets
+	validator.RequireLicense = o.RequireLicense
+	validator.Partial = !o.Filter.IsEmpty()
+	return validator
+}
+
 // ManifestBuilder builds a manifest from scanned items.
 type ManifestBuilder struct {
 	RegistryPath string
+
+	// Options configures validation during the build.
+	Options BuildOptions
 }
 
 // NewManifestBuilder creates a new manifest builder.
@@ -33,25 +93,58 @@ func NewManifestBuilder(registryPath string) *ManifestBuilder {
 func (b *ManifestBuilder) Build() (*Manifest, *ValidationResult, error) {
 	// Scan registry
 	scanner := NewScanner(b.RegistryPath)
+	scanner.Filter = b.Options.Filter
+	scanner.StagingDir = b.Options.StagingDir
+	scanner.Dialect = b.Options.Dialect
+	scanner.Timings = b.Options.Timings
 	scanResult, err := scanner.Scan()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to scan registry: %w", err)
 	}
 
 	// Validate items
-	validator := NewValidator(b.RegistryPath)
+	stop := b.Options.Timings.Start("validate")
+	validator := b.Options.newValidator(b.RegistryPath)
 	valResult := validator.ValidateItems(scanResult.Items)
+	scanResult.Errors = reportConflicts(b.RegistryPath, scanResult.Errors, valResult)
+	var quarantined []Quarantined
+	if b.Options.AllowErrors {
+		scanResult.Items, quarantined = quarantine(scanResult.Items, valResult, validator)
+	}
+	stop()
 
 	// Build manifest even if there are warnings (but not errors)
-	manifest := NewManifest(b.RegistryPath)
+	stop = b.Options.Timings.Start("checksum")
+	manifest := newManifestFromScan(b.RegistryPath, scanResult, b.Options.Filter, quarantined)
+	stop()
+
+	stop = b.Options.Timings.Start("health")
+	manifest.recordHealth(b.RegistryPath, b.Options.Ignore(b.RegistryPath), WarnedItems(manifest, valResult))
+	stop()
 
-	for _, item := range scanResult.Items {
+	return manifest, valResult, nil
+}
+
+// newManifestFromScan creates a manifest with computed stats and checksums,
+// recording tombstones for items that disappeared since the last build.
+func newManifestFromScan(registryPath string, scan *ScanResult, filter Filter, quarantined []Quarantined) *Manifest {
+	manifest := NewManifest(registryPath)
+	for _, item := range scan.Items {
 		manifest.AddItem(item)
 	}
-
+	if !filter.IsEmpty() {
+		manifest.Filter = &filter
+	}
+	manifest.Quarantine = quarantined
 	manifest.ComputeStats()
+	manifest.Stats.Excluded = len(scan.Excluded)
+	manifest.ComputeChecksums()
 
-	return manifest, valResult, nil
+	if previous, err := LoadManifestFromRegistry(registryPath); err == nil {
+		manifest.RecordTombstones(previous)
+	}
+
+	return manifest
 }
 
 // BuildAndSave builds the manifest and saves it to the .build directory.
@@ -61,12 +154,14 @@ func (b *ManifestBuilder) BuildAndSave() (*Manifest, *ValidationResult, error) {
 		return nil, nil, err
 	}
 
-	// Don't save if there are errors
-	if valResult.HasErrors() {
+	// Don't save if there are errors, unless the items with errors were
+	// left out
+	if valResult.HasErrors() && !b.Options.AllowErrors {
 		return manifest, valResult, nil
 	}
 
 	// Save manifest
+	defer b.Options.Timings.Start("write")()
 	if err := b.Save(manifest); err != nil {
 		return manifest, valResult, fmt.Errorf("failed to save manifest: %w", err)
 	}
@@ -74,7 +169,8 @@ func (b *ManifestBuilder) BuildAndSave() (*Manifest, *ValidationResult, error) {
 	return manifest, valResult, nil
 }
 
-// Save writes the manifest to the .build directory.
+// Save writes the manifest to the .build directory and records its
+// statistics in the build history.
 func (b *ManifestBuilder) Save(manifest *Manifest) error {
 	buildDir := filepath.Join(b.RegistryPath, DefaultBuildDir)
 
@@ -95,7 +191,7 @@ func (b *ManifestBuilder) Save(manifest *Manifest) error {
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
-	return nil
+	return recordHistory(b.RegistryPath, manifest)
 }
 
 // ManifestPath returns the path to the manifest file.
@@ -114,6 +210,8 @@ func LoadManifest(path string) (*Manifest, error) {
 	if err := json.Unmarshal(data, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %w", err)
 	}
+	sum := sha256.Sum256(data)
+	manifest.Hash = hex.EncodeToString(sum[:])
 
 	return &manifest, nil
 }
@@ -137,35 +235,61 @@ type BuildResult struct {
 	Validation *ValidationResult
 	ScanErrors []ScanError
 	Skipped    []string
+	Excluded   []string
 	Duration   time.Duration
+
+	// Updated and Removed list the items touched by a partial build.
+	Updated []string
+	Removed []string
 }
 
-// BuildRegistry performs a complete build of the registry.
+// BuildRegistry performs a complete build of the registry with default options.
 func BuildRegistry(registryPath string) (*BuildResult, error) {
+	return BuildRegistryWithOptions(registryPath, BuildOptions{})
+}
+
+// BuildRegistryWithOptions performs a complete build of the registry.
+func BuildRegistryWithOptions(registryPath string, opts BuildOptions) (*BuildResult, error) {
 	start := time.Now()
 
 	// Scan
 	scanner := NewScanner(registryPath)
+	scanner.Filter = opts.Filter
+	scanner.StagingDir = opts.StagingDir
+	scanner.Dialect = opts.Dialect
+	scanner.Timings = opts.Timings
 	scanResult, err := scanner.Scan()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan registry: %w", err)
 	}
 
 	// Validate
-	validator := NewValidator(registryPath)
+	stop := opts.Timings.Start("validate")
+	validator := opts.newValidator(registryPath)
 	valResult := validator.ValidateItems(scanResult.Items)
+	scanResult.Errors = reportConflicts(registryPath, scanResult.Errors, valResult)
+	var quarantined []Quarantined
+	if opts.AllowErrors {
+		scanResult.Items, quarantined = quarantine(scanResult.Items, valResult, validator)
+	}
+	stop()
 
 	// Build manifest
-	manifest := NewManifest(registryPath)
-	for _, item := range scanResult.Items {
-		manifest.AddItem(item)
-	}
-	manifest.ComputeStats()
+	stop = opts.Timings.Start("checksum")
+	manifest := newManifestFromScan(registryPath, scanResult, opts.Filter, quarantined)
+	stop()
+
+	stop = opts.Timings.Start("health")
+	manifest.recordHealth(registryPath, opts.Ignore(registryPath), WarnedItems(manifest, valResult))
+	stop()
 
-	// Save manifest if no errors
-	if !valResult.HasErrors() {
+	// Save manifest if no errors, or the items with errors were left out
+	if !valResult.HasErrors() || opts.AllowErrors {
+		stop = opts.Timings.Start("write")
 		builder := NewManifestBuilder(registryPath)
-		if err := builder.Save(manifest); err != nil {
+		err := builder.Save(manifest)
+		stop()
+		if err != nil {
 			return nil, fmt.Errorf("failed to save manifest: %w", err)
 		}
 	}
@@ -175,6 +299,7 @@ func BuildRegistry(registryPath string) (*BuildResult, error) {
 		Validation: valResult,
 		ScanErrors: scanResult.Errors,
 		Skipped:    scanResult.Skipped,
+		Excluded:   scanResult.Excluded,
 		Duration:   time.Since(start),
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/okto-digital/regis3/pkg/registrytest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -284,3 +285,245 @@ regis3:
 	require.NoError(t, err)
 	assert.Len(t, loaded.Items, 3)
 }
+
+func TestManifest_ComputeChecksums(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "skills", "helper.sh"), []byte("echo hi\n"), 0644))
+
+	manifest := NewManifest(tmpDir)
+	manifest.AddItem(&Item{
+		Regis3Meta: Regis3Meta{Type: "skill", Name: "tool", Desc: "Tool", Files: []string{"helper.sh", "missing.sh"}},
+		Source:     "skills/tool.md",
+		SourceDir:  "skills",
+	})
+	manifest.ComputeChecksums()
+
+	item := manifest.Items["skill:tool"]
+	require.Len(t, item.Checksums, 1)
+	assert.Equal(t, "helper.sh", item.Checksums[0].Path)
+	assert.Equal(t, int64(8), item.Checksums[0].Size)
+	assert.Len(t, item.Checksums[0].SHA256, 64)
+
+	t.Run("verify reports missing files", func(t *testing.T) {
+		err := item.VerifyFiles(tmpDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing file: missing.sh")
+	})
+
+	t.Run("verify reports modified files", func(t *testing.T) {
+		item.Files = []string{"helper.sh"}
+		require.NoError(t, item.VerifyFiles(tmpDir))
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "skills", "helper.sh"), []byte("echo changed\n"), 0644))
+		err := item.VerifyFiles(tmpDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file changed since the manifest was built")
+	})
+}
+
+func TestManifest_RecordTombstones(t *testing.T) {
+	previous := NewManifest("/test")
+	previous.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "kept"}, Source: "kept.md"})
+	previous.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "deleted"}, Source: "deleted.md"})
+	previous.Tombstones = []Tombstone{
+		{ID: "skill:old", Source: "old.md"},
+		{ID: "skill:restored", Source: "restored.md"},
+	}
+
+	manifest := NewManifest("/test")
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "kept"}, Source: "kept.md"})
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "restored"}, Source: "restored.md"})
+	manifest.RecordTombstones(previous)
+
+	require.Len(t, manifest.Tombstones, 2)
+	assert.Equal(t, "skill:deleted", manifest.Tombstones[0].ID)
+	assert.Equal(t, "deleted.md", manifest.Tombstones[0].Source)
+	assert.Equal(t, manifest.Generated, manifest.Tombstones[0].RemovedAt)
+	assert.Equal(t, "skill:old", manifest.Tombstones[1].ID)
+
+	_, ok := manifest.GetTombstone("skill:restored")
+	assert.False(t, ok)
+}
+
+func TestBuildRegistry_Tombstones(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	require.NoError(t, os.MkdirAll(skillsDir, 0755))
+
+	skill := `---
+regis3:
+  type: skill
+  name: short-lived
+  desc: A skill that gets deleted
+  tags:
+    - test
+---
+# Short Lived
+`
+	skillPath := filepath.Join(skillsDir, "short-lived.md")
+	require.NoError(t, os.WriteFile(skillPath, []byte(skill), 0644))
+
+	_, err := BuildRegistry(tmpDir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(skillPath))
+	result, err := BuildRegistry(tmpDir)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Manifest.Items)
+	tombstone, ok := result.Manifest.GetTombstone("skill:short-lived")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join("skills", "short-lived.md"), tombstone.Source)
+
+	// Tombstones survive a reload
+	loaded, err := LoadManifestFromRegistry(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, loaded.Tombstones, 1)
+}
+
+func TestBuildRegistry_Filter(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"skills/base.md": `---
+regis3:
+  type: skill
+  name: base
+  desc: Base skill
+  deps:
+    - philosophy:clean
+---
+# Base
+`,
+		"philosophies/clean.md": `---
+regis3:
+  type: philosophy
+  name: clean
+  desc: Clean code
+---
+# Clean
+`,
+		"drafts/wip.md": `---
+regis3:
+  type: skill
+  name: wip
+  desc: Work in progress
+---
+# WIP
+`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(tmpDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Excluding drafts leaves everything else
+	result, err := BuildRegistryWithOptions(tmpDir, BuildOptions{Filter: Filter{Exclude: []string{"drafts/**"}}})
+	require.NoError(t, err)
+	assert.Len(t, result.Manifest.Items, 2)
+	assert.Equal(t, 1, result.Manifest.Stats.Excluded)
+	assert.False(t, result.Validation.HasErrors())
+
+	// A focused build doesn't fail on dependencies outside the filter
+	result, err = BuildRegistryWithOptions(tmpDir, BuildOptions{Filter: Filter{Include: []string{"skills/**"}}})
+	require.NoError(t, err)
+	assert.Len(t, result.Manifest.Items, 1)
+	assert.Equal(t, 2, result.Manifest.Stats.Excluded)
+	assert.False(t, result.Validation.HasErrors())
+	assert.NotEmpty(t, result.Validation.Warnings())
+
+	// Filtered-out items are not recorded as removed
+	assert.Empty(t, result.Manifest.Tombstones)
+	require.NotNil(t, result.Manifest.Filter)
+	assert.Equal(t, []string{"skills/**"}, result.Manifest.Filter.Include)
+}
+
+func BenchmarkBuildRegistry(b *testing.B) {
+	dir := b.TempDir()
+	reg := registrytest.Generate(registrytest.Options{
+		Seed:      1,
+		Items:     map[string]int{"skill": 400, "subagent": 50, "command": 50},
+		Stacks:    20,
+		MaxDeps:   3,
+		BodyLines: 20,
+	})
+	require.NoError(b, reg.Write(dir))
+
+	for b.Loop() {
+		if _, err := BuildRegistry(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBuildRegistry_AllowErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"skills/good.md": `---
+regis3:
+  type: skill
+  name: good
+  desc: A valid skill
+---
+# Good
+`,
+		"skills/broken.md": `---
+regis3:
+  type: skill
+  name: broken
+---
+# Broken
+`,
+		"stacks/uses-broken.md": `---
+regis3:
+  type: stack
+  name: uses-broken
+  desc: Depends on the broken skill
+  deps:
+    - skill:broken
+---
+# Uses Broken
+`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(tmpDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Without AllowErrors the manifest isn't saved
+	result, err := BuildRegistry(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, result.Validation.HasErrors())
+	assert.Empty(t, result.Manifest.Quarantine)
+	assert.False(t, ManifestExists(tmpDir))
+
+	// With it, the broken item and the stack depending on it are left out
+	result, err = BuildRegistryWithOptions(tmpDir, BuildOptions{AllowErrors: true})
+	require.NoError(t, err)
+	assert.True(t, result.Validation.HasErrors())
+	assert.Len(t, result.Manifest.Items, 1)
+	assert.Contains(t, result.Manifest.Items, "skill:good")
+	require.Len(t, result.Manifest.Quarantine, 2)
+	assert.Equal(t, "skill:broken", result.Manifest.Quarantine[0].ID)
+	assert.Equal(t, filepath.Join("skills", "broken.md"), result.Manifest.Quarantine[0].Path)
+	assert.NotEmpty(t, result.Manifest.Quarantine[0].Errors)
+	assert.Equal(t, "stack:uses-broken", result.Manifest.Quarantine[1].ID)
+	assert.Contains(t, result.Manifest.Quarantine[1].Errors[0], "skill:broken")
+
+	loaded, err := LoadManifestFromRegistry(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, loaded.Items, 1)
+	assert.Len(t, loaded.Quarantine, 2)
+
+	// Quarantined items are not recorded as removed, but are once their
+	// file is deleted
+	assert.Empty(t, loaded.Tombstones)
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "stacks", "uses-broken.md")))
+	result, err = BuildRegistryWithOptions(tmpDir, BuildOptions{AllowErrors: true})
+	require.NoError(t, err)
+	require.Len(t, result.Manifest.Quarantine, 1)
+	require.Len(t, result.Manifest.Tombstones, 1)
+	assert.Equal(t, "stack:uses-broken", result.Manifest.Tombstones[0].ID)
+}
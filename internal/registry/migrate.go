@@ -0,0 +1,292 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFile records the version of the frontmatter schema a registry's
+// items were migrated to.
+const SchemaFile = ".regis3-schema.yaml"
+
+// SchemaConfig is the content of SchemaFile.
+type SchemaConfig struct {
+	// Version is the version of the last migration applied.
+	Version int `yaml:"version"`
+}
+
+// Migration is a versioned change to the frontmatter schema, rewriting the
+// regis3 block of every item. Steps must leave items already migrated
+// unchanged, so a migration can run again on items added later.
+type Migration struct {
+	Version int
+	Desc    string
+	Steps   []MigrationStep
+}
+
+// MigrationStep rewrites a regis3 block.
+type MigrationStep interface {
+	apply(block *metaBlock) error
+}
+
+// Migrations are the frontmatter schema migrations, by version.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Desc:    "Rename description, category and dependencies to desc, cat and deps",
+		Steps: []MigrationStep{
+			RenameKey{From: "description", To: "desc"},
+			RenameKey{From: "category", To: "cat"},
+			RenameKey{From: "dependencies", To: "deps"},
+		},
+	},
+}
+
+// SchemaVersion is the current frontmatter schema version.
+var SchemaVersion = Migrations[len(Migrations)-1].Version
+
+// PendingMigrations returns the migrations after version.
+func PendingMigrations(version int) []Migration {
+	var pending []Migration
+	for _, m := range Migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// LoadSchemaVersion reads the schema version from the registry's
+// SchemaFile. A registry without one was never migrated and is at 0.
+func LoadSchemaVersion(registryPath string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(registryPath, SchemaFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", SchemaFile, err)
+	}
+	var cfg SchemaConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", SchemaFile, err)
+	}
+	return cfg.Version, nil
+}
+
+// SaveSchemaVersion writes the registry's SchemaFile.
+func SaveSchemaVersion(registryPath string, version int) error {
+	data, err := yaml.Marshal(&SchemaConfig{Version: version})
+	if err != nil {
+		return err
+	}
+	header := "# Frontmatter schema version of this registry; see 'regis3 registry migrate'.\n"
+	if err := os.WriteFile(filepath.Join(registryPath, SchemaFile), append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SchemaFile, err)
+	}
+	return nil
+}
+
+// Migrate applies the migrations to the regis3 block of an item file. Only
+// the lines of the keys a step changes are rewritten; the rest of the file,
+// including comments, quoting and line endings, stays as it is. Files
+// without YAML frontmatter or a regis3 block are returned unchanged.
+func (d Dialect) Migrate(content []byte, migrations []Migration) ([]byte, error) {
+	doc, err := frontmatter.ParseBytes(content)
+	if err == frontmatter.ErrNoFrontmatter {
+		return content, nil
+	}
+	if err != nil {
+		return nil, formatYAMLError(err)
+	}
+	if doc.Format != frontmatter.FormatYAML {
+		return content, nil
+	}
+
+	// The opening delimiter comes first, then the frontmatter lines
+	lines := strings.SplitAfter(string(content), "\n")
+	n := strings.Count(doc.Frontmatter, "\n")
+	block := &metaBlock{}
+	for _, line := range lines[1 : 1+n] {
+		block.lines = append(block.lines, strings.TrimSuffix(line, "\n"))
+	}
+	for _, key := range d.keys() {
+		block.key = key
+		if err := block.parse(); err != nil {
+			return nil, err
+		}
+		if block.node != nil {
+			break
+		}
+	}
+	if block.node == nil {
+		return content, nil
+	}
+	if block.node.Kind != yaml.MappingNode || block.node.Style&yaml.FlowStyle != 0 {
+		return nil, fmt.Errorf("%s must be a block mapping to be migrated", block.key)
+	}
+
+	for _, m := range migrations {
+		for _, step := range m.Steps {
+			if err := step.apply(block); err != nil {
+				return nil, fmt.Errorf("migration %d: %w", m.Version, err)
+			}
+			if err := block.parse(); err != nil {
+				return nil, fmt.Errorf("migration %d: %w", m.Version, err)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(lines[0])
+	for _, line := range block.lines {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(strings.Join(lines[1+n:], ""))
+	return []byte(b.String()), nil
+}
+
+// metaBlock is the regis3 block of YAML frontmatter, edited line by line.
+type metaBlock struct {
+	// lines are the frontmatter lines, without their newlines.
+	lines []string
+
+	// key is the dotted key of the block.
+	key string
+
+	// node is the block's value, with positions matching lines, or nil if
+	// the frontmatter has no block under key.
+	node *yaml.Node
+}
+
+// parse locates the block in the current lines.
+func (b *metaBlock) parse() error {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(b.lines, "\n")), &root); err != nil {
+		return formatYAMLError(err)
+	}
+	b.node = &root
+	for _, part := range strings.Split(b.key, ".") {
+		if b.node = mappingValue(b.node, part); b.node == nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// find returns the key node of key in the block, or nil.
+func (b *metaBlock) find(key string) *yaml.Node {
+	for i := 0; i+1 < len(b.node.Content); i += 2 {
+		if b.node.Content[i].Value == key {
+			return b.node.Content[i]
+		}
+	}
+	return nil
+}
+
+// extent returns the range of lines [start, end) holding the key and its
+// value: up to the next line indented no deeper than the key, other than
+// list entries, which may be indented like their key. Trailing blank lines
+// are left out.
+func (b *metaBlock) extent(keyNode *yaml.Node) (int, int) {
+	start := keyNode.Line - 1
+	end := start + 1
+	for ; end < len(b.lines); end++ {
+		line := strings.TrimRight(b.lines[end], " \t\r")
+		indent := indentation(line)
+		if line == "" || indent > keyNode.Column-1 {
+			continue
+		}
+		if indent < keyNode.Column-1 || !strings.HasPrefix(line[indent:], "-") {
+			break
+		}
+	}
+	for end > start+1 && strings.TrimSpace(b.lines[end-1]) == "" {
+		end--
+	}
+	return start, end
+}
+
+// indentation returns the number of leading spaces of line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// RenameKey renames a key of the regis3 block, keeping its value as
+// written. Blocks with both keys can't be migrated.
+type RenameKey struct {
+	From string
+	To   string
+}
+
+func (s RenameKey) apply(b *metaBlock) error {
+	keyNode := b.find(s.From)
+	if keyNode == nil {
+		return nil
+	}
+	if b.find(s.To) != nil {
+		return fmt.Errorf("both %s and %s are set", s.From, s.To)
+	}
+
+	line := b.lines[keyNode.Line-1]
+	col := keyNode.Column - 1
+	quote := ""
+	if keyNode.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		quote = line[col : col+1]
+	}
+	if !strings.HasPrefix(line[col:], quote+s.From+quote) {
+		return fmt.Errorf("can't rename %s on line %d", s.From, keyNode.Line+1)
+	}
+	b.lines[keyNode.Line-1] = line[:col] + quote + s.To + line[col+len(quote)+len(s.From):]
+	return nil
+}
+
+// AddKey adds a key with a string value to blocks without it, after their
+// last key.
+type AddKey struct {
+	Key   string
+	Value string
+}
+
+func (s AddKey) apply(b *metaBlock) error {
+	if b.find(s.Key) != nil || len(b.node.Content) == 0 {
+		return nil
+	}
+	first := b.node.Content[0]
+	_, end := b.extent(b.node.Content[len(b.node.Content)-2])
+
+	value := s.Value
+	if needsQuotes(value) {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		value = strings.TrimSuffix(string(out), "\n")
+	}
+	line := strings.Repeat(" ", first.Column-1) + s.Key + ": " + value
+	if strings.HasSuffix(b.lines[end-1], "\r") {
+		line += "\r"
+	}
+	b.lines = append(b.lines[:end], append([]string{line}, b.lines[end:]...)...)
+	return nil
+}
+
+// RemoveKey removes a key and its value from the regis3 block.
+type RemoveKey struct {
+	Key string
+}
+
+func (s RemoveKey) apply(b *metaBlock) error {
+	keyNode := b.find(s.Key)
+	if keyNode == nil {
+		return nil
+	}
+	start, end := b.extent(keyNode)
+	b.lines = append(b.lines[:start], b.lines[end:]...)
+	return nil
+}
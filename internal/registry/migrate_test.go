@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Migrate(t *testing.T) {
+	migrations := []Migration{{
+		Version: 1,
+		Steps: []MigrationStep{
+			RenameKey{From: "description", To: "desc"},
+			RenameKey{From: "dependencies", To: "deps"},
+			RemoveKey{Key: "obsolete"},
+			AddKey{Key: "status", Value: "stable"},
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "migrated",
+			content: "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  status: draft\n---\n# Testing\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  desc: Testing practices\n  status: draft\n---\n# Testing\n",
+		},
+		{
+			name:    "formatting is kept",
+			content: "---\n# Reviewed quarterly\nregis3:\n    name:   'testing'   # short name\n    type: skill\n    description: \"Testing: practices\"\n    dependencies: [skill:git,\n      skill:go]\n    tags:\n    - test\nother: value\n---\n# Testing\n\n",
+			want:    "---\n# Reviewed quarterly\nregis3:\n    name:   'testing'   # short name\n    type: skill\n    desc: \"Testing: practices\"\n    deps: [skill:git,\n      skill:go]\n    tags:\n    - test\n    status: stable\nother: value\n---\n# Testing\n\n",
+		},
+		{
+			name:    "quoted keys",
+			content: "---\nregis3:\n  type: skill\n  name: testing\n  'description': Testing\n  status: stable\n---\nBody\n",
+			want:    "---\nregis3:\n  type: skill\n  name: testing\n  'desc': Testing\n  status: stable\n---\nBody\n",
+		},
+		{
+			name:    "removed key with nested value",
+			content: "---\nregis3:\n  type: skill\n  obsolete:\n    - a\n    - b\n\n  name: testing\n  status: stable\n---\nBody\n",
+			want:    "---\nregis3:\n  type: skill\n\n  name: testing\n  status: stable\n---\nBody\n",
+		},
+		{
+			name:    "line endings are kept",
+			content: "---\r\nregis3:\r\n  type: skill\r\n  name: testing\r\n---\r\nBody\r\n",
+			want:    "---\r\nregis3:\r\n  type: skill\r\n  name: testing\r\n  status: stable\r\n---\r\nBody\r\n",
+		},
+		{
+			name:    "dialect keys",
+			dialect: Dialect{Keys: []string{"meta.regis3"}},
+			content: "---\nmeta:\n  regis3:\n    type: skill\n    name: testing\n    description: Testing\n    status: stable\n---\nBody\n",
+			want:    "---\nmeta:\n  regis3:\n    type: skill\n    name: testing\n    desc: Testing\n    status: stable\n---\nBody\n",
+		},
+		{
+			name:    "toml is unchanged",
+			content: "+++\n[regis3]\nname = \"testing\"\ndescription = \"Testing\"\n+++\nBody\n",
+			want:    "+++\n[regis3]\nname = \"testing\"\ndescription = \"Testing\"\n+++\nBody\n",
+		},
+		{
+			name:    "no regis3 block",
+			content: "---\ntitle: Notes\n---\nBody\n",
+			want:    "---\ntitle: Notes\n---\nBody\n",
+		},
+		{
+			name:    "both keys set",
+			content: "---\nregis3:\n  type: skill\n  desc: Testing\n  description: Testing practices\n---\nBody\n",
+			wantErr: "both description and desc are set",
+		},
+		{
+			name:    "flow block",
+			content: "---\nregis3: {type: skill, name: testing}\n---\nBody\n",
+			wantErr: "must be a block mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dialect.Migrate([]byte(tt.content), migrations)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			again, err := tt.dialect.Migrate(got, migrations)
+			require.NoError(t, err)
+			assert.Equal(t, string(got), string(again), "migrating is idempotent")
+		})
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	assert.Len(t, PendingMigrations(0), len(Migrations))
+	assert.Empty(t, PendingMigrations(SchemaVersion))
+}
+
+func TestSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	version, err := LoadSchemaVersion(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	require.NoError(t, SaveSchemaVersion(dir, 3))
+	version, err = LoadSchemaVersion(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, version)
+}
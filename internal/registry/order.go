@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Sort orders for showing items.
+const (
+	// SortByType groups items by type, then sorts them by name.
+	SortByType = "type"
+
+	// SortByName sorts items alphabetically by name.
+	SortByName = "name"
+
+	// SortByModified lists the most recently modified items first.
+	SortByModified = "modified"
+)
+
+// SortOrders lists the item sort orders.
+var SortOrders = []string{SortByType, SortByName, SortByModified}
+
+// DefaultTypeOrder is the order in which item types are shown by default.
+var DefaultTypeOrder = []string{
+	"skill", "subagent", "command", "doc", "prompt",
+	"philosophy", "project", "ruleset",
+	"mcp", "script", "hook", "stack",
+}
+
+// ItemOrder arranges items for display.
+type ItemOrder struct {
+	// By is the sort order (SortByType, SortByName or SortByModified).
+	// Empty means SortByType.
+	By string
+
+	// Types lists item types in the order they are shown; types not listed
+	// follow in DefaultTypeOrder.
+	Types []string
+}
+
+// TypeOrder returns every item type in the order they are shown.
+func (o ItemOrder) TypeOrder() []string {
+	order := make([]string, 0, len(DefaultTypeOrder))
+	for _, t := range append(append([]string{}, o.Types...), DefaultTypeOrder...) {
+		if !slices.Contains(order, t) {
+			order = append(order, t)
+		}
+	}
+	return order
+}
+
+// Sort sorts items in place. Modification times are those of the items'
+// source files in the registry at registryPath; ties are broken by type and
+// name.
+func (o ItemOrder) Sort(registryPath string, items []*Item) {
+	rank := make(map[string]int)
+	for i, t := range o.TypeOrder() {
+		rank[t] = i
+	}
+	typeRank := func(item *Item) int {
+		if r, ok := rank[item.Type]; ok {
+			return r
+		}
+		return len(rank)
+	}
+
+	var modified map[*Item]time.Time
+	if o.By == SortByModified {
+		modified = make(map[*Item]time.Time, len(items))
+		for _, item := range items {
+			if info, err := os.Stat(filepath.Join(registryPath, item.Source)); err == nil {
+				modified[item] = info.ModTime()
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch o.By {
+		case SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case SortByModified:
+			if !modified[a].Equal(modified[b]) {
+				return modified[a].After(modified[b])
+			}
+		}
+		if ra, rb := typeRank(a), typeRank(b); ra != rb {
+			return ra < rb
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemOrder_Sort(t *testing.T) {
+	root := t.TempDir()
+	newItem := func(typ, name string, age time.Duration) *Item {
+		source := typ + "s/" + name + ".md"
+		writeRegistryFile(t, root, source, "# "+name)
+		modified := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(root, source), modified, modified))
+		return &Item{Regis3Meta: Regis3Meta{Type: typ, Name: name}, Source: source}
+	}
+	items := []*Item{
+		newItem("stack", "base", time.Hour),
+		newItem("skill", "testing", 3*time.Hour),
+		newItem("command", "deploy", 2*time.Hour),
+		newItem("skill", "arch", time.Minute),
+		newItem("custom", "aaa", 4*time.Hour),
+	}
+
+	tests := []struct {
+		name  string
+		order ItemOrder
+		want  []string
+	}{
+		{"default", ItemOrder{}, []string{"arch", "testing", "deploy", "base", "aaa"}},
+		{"by type", ItemOrder{By: SortByType}, []string{"arch", "testing", "deploy", "base", "aaa"}},
+		{"by name", ItemOrder{By: SortByName}, []string{"aaa", "arch", "base", "deploy", "testing"}},
+		{"by modified", ItemOrder{By: SortByModified}, []string{"arch", "base", "deploy", "testing", "aaa"}},
+		{"type order", ItemOrder{Types: []string{"stack", "command"}}, []string{"base", "deploy", "arch", "testing", "aaa"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]*Item{}, items...)
+			tt.order.Sort(root, sorted)
+
+			var names []string
+			for _, item := range sorted {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestItemOrder_TypeOrder(t *testing.T) {
+	order := ItemOrder{Types: []string{"stack", "skill"}}.TypeOrder()
+	assert.Equal(t, []string{"stack", "skill", "subagent", "command"}, order[:4])
+	assert.Len(t, order, len(DefaultTypeOrder))
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Orphan is a markdown file in the registry that no manifest item uses.
+type Orphan struct {
+	// Path is relative to the registry root.
+	Path string
+
+	// Size is the file size in bytes.
+	Size int64
+}
+
+// FindOrphans lists the markdown files in the registry that are neither an
+// item's source nor one of its additional files. Paths the ignore rules skip
+// or exclude are not orphans; nil means the default rules. Orphans are sorted
+// by path.
+func FindOrphans(registryPath string, ignore *Ignore, manifest *Manifest) ([]Orphan, error) {
+	if ignore == nil {
+		ignore = NewIgnore(registryPath, "", Filter{})
+	}
+
+	known := make(map[string]bool)
+	for _, item := range manifest.Items {
+		known[filepath.Clean(item.Source)] = true
+		files, _ := item.ExpandFiles(registryPath)
+		for _, f := range files {
+			known[filepath.Join(item.SourceDir, f)] = true
+		}
+		for _, f := range item.Variants {
+			known[filepath.Join(item.SourceDir, f)] = true
+		}
+	}
+
+	var orphans []Orphan
+	err := filepath.Walk(registryPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+
+		rel, err := filepath.Rel(registryPath, path)
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() {
+			if ignore.SkipDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if ignore.SkipFile(rel) || ignore.Excluded(rel) {
+			return nil
+		}
+		if !known[rel] {
+			orphans = append(orphans, Orphan{Path: rel, Size: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].Path < orphans[j].Path
+	})
+	return orphans, nil
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
+)
+
+// UpdateRegistry rebuilds only the items defined in the given files and
+// merges them into the existing manifest. Paths are relative to the registry
+// root. A path that no longer exists, or no longer has a regis3 block, removes
+// the items it used to define. The changed items are validated individually;
+// duplicate and dependency checks still run across the whole registry.
+func UpdateRegistry(registryPath string, paths []string, opts BuildOptions) (*BuildResult, error) {
+	start := time.Now()
+
+	previous, err := LoadManifestFromRegistry(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("no manifest to update (run a full build first): %w", err)
+	}
+
+	changedPaths := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if err := pathutil.CheckRelative(p); err != nil {
+			return nil, err
+		}
+		changedPaths[filepath.Clean(p)] = true
+	}
+
+	result := &BuildResult{}
+
+	// Keep unchanged items from the previous build
+	var items []*Item
+	var replaced []string
+	for _, item := range previous.Items {
+		if changedPaths[filepath.Clean(item.Source)] {
+			replaced = append(replaced, item.FullName())
+			continue
+		}
+		items = append(items, item)
+	}
+
+	// Re-parse the changed files
+	stop := opts.Timings.Start("parse")
+	scanner := NewScanner(registryPath)
+	scanner.Dialect = opts.Dialect
+	ignore := opts.Ignore(registryPath)
+	var changed []*Item
+	for p := range changedPaths {
+		fullPath := filepath.Join(registryPath, p)
+		if ignore.SkipFile(p) {
+			// Not an item source; items it used to define are removed
+			result.Skipped = append(result.Skipped, fullPath)
+			continue
+		}
+		if ignore.Excluded(p) {
+			result.Excluded = append(result.Excluded, p)
+			continue
+		}
+
+		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+			continue // deleted
+		}
+
+		item, err := scanner.ScanFile(fullPath)
+		if err != nil {
+			if err == ErrNoRegis3Block {
+				result.Skipped = append(result.Skipped, fullPath)
+			} else {
+				result.ScanErrors = append(result.ScanErrors, ScanError{
+					Path:    fullPath,
+					Message: "failed to parse",
+					Err:     err,
+				})
+			}
+			continue
+		}
+		item.ComputeChecksums(registryPath)
+		items = append(items, item)
+		changed = append(changed, item)
+	}
+	stop()
+
+	// Items no longer defined by their file are removed
+	present := make(map[string]bool, len(changed))
+	for _, item := range changed {
+		present[item.FullName()] = true
+		result.Updated = append(result.Updated, item.FullName())
+	}
+	for _, id := range replaced {
+		if !present[id] {
+			result.Removed = append(result.Removed, id)
+		}
+	}
+
+	// Validate
+	stop = opts.Timings.Start("validate")
+	result.Validation = opts.newValidator(registryPath).ValidateChanged(items, changed)
+	result.ScanErrors = reportConflicts(registryPath, result.ScanErrors, result.Validation)
+	stop()
+
+	// Build manifest
+	manifest := NewManifest(registryPath)
+	manifest.Filter = previous.Filter
+	for _, item := range items {
+		manifest.AddItem(item)
+	}
+	manifest.ComputeStats()
+	manifest.Stats.Excluded = previous.Stats.Excluded
+	// Quarantined items stay quarantined until their file is rebuilt
+	for _, q := range previous.Quarantine {
+		if !changedPaths[filepath.Clean(q.Path)] {
+			manifest.Quarantine = append(manifest.Quarantine, q)
+		}
+	}
+	manifest.RecordTombstones(previous)
+	result.Manifest = manifest
+
+	// Unchanged items keep their warnings from the previous build
+	stop = opts.Timings.Start("health")
+	warned := WarnedItems(manifest, result.Validation)
+	if previous.Health != nil {
+		isReplaced := make(map[string]bool, len(replaced))
+		for _, id := range replaced {
+			isReplaced[id] = true
+		}
+		for _, id := range previous.Health.Warned {
+			if !isReplaced[id] {
+				warned = append(warned, id)
+			}
+		}
+	}
+	manifest.recordHealth(registryPath, opts.Ignore(registryPath), warned)
+	stop()
+
+	// Save manifest if no errors
+	if !result.Validation.HasErrors() {
+		stop = opts.Timings.Start("write")
+		err := NewManifestBuilder(registryPath).Save(manifest)
+		stop()
+		if err != nil {
+			return nil, fmt.Errorf("failed to save manifest: %w", err)
+		}
+	}
+
+	sort.Strings(result.Updated)
+	sort.Strings(result.Removed)
+	result.Duration = time.Since(start)
+	return result, nil
+}
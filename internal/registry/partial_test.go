@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRegistryFile writes a registry file relative to root.
+func writeRegistryFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func skillFile(name, desc string, deps ...string) string {
+	content := "---\nregis3:\n  type: skill\n  name: " + name + "\n  desc: " + desc + "\n"
+	if len(deps) > 0 {
+		content += "  deps:\n"
+		for _, dep := range deps {
+			content += "    - " + dep + "\n"
+		}
+	}
+	return content + "---\n# " + name + "\n"
+}
+
+func TestUpdateRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeRegistryFile(t, tmpDir, "skills/base.md", skillFile("base", "Base skill"))
+	writeRegistryFile(t, tmpDir, "skills/app.md", skillFile("app", "App skill", "skill:base"))
+	writeRegistryFile(t, tmpDir, "skills/other.md", skillFile("other", "Other skill"))
+
+	_, err := BuildRegistry(tmpDir)
+	require.NoError(t, err)
+
+	t.Run("updates changed item", func(t *testing.T) {
+		writeRegistryFile(t, tmpDir, "skills/other.md", skillFile("other", "Changed description"))
+
+		result, err := UpdateRegistry(tmpDir, []string{"skills/other.md"}, BuildOptions{})
+		require.NoError(t, err)
+		assert.False(t, result.Validation.HasErrors())
+		assert.Equal(t, []string{"skill:other"}, result.Updated)
+		assert.Empty(t, result.Removed)
+
+		loaded, err := LoadManifestFromRegistry(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, loaded.Items, 3)
+		assert.Equal(t, "Changed description", loaded.Items["skill:other"].Desc)
+	})
+
+	t.Run("dependency checks stay global", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(tmpDir, "skills/base.md")))
+
+		result, err := UpdateRegistry(tmpDir, []string{"skills/base.md"}, BuildOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:base"}, result.Removed)
+		require.True(t, result.Validation.HasErrors())
+		assert.Contains(t, result.Validation.Errors()[0].Message, "dependency not found: skill:base")
+
+		// Manifest is not saved on errors
+		loaded, err := LoadManifestFromRegistry(tmpDir)
+		require.NoError(t, err)
+		assert.Contains(t, loaded.Items, "skill:base")
+	})
+
+	t.Run("removing the dependent as well succeeds", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(tmpDir, "skills/app.md")))
+
+		result, err := UpdateRegistry(tmpDir, []string{"skills/base.md", "skills/app.md"}, BuildOptions{})
+		require.NoError(t, err)
+		assert.False(t, result.Validation.HasErrors())
+		assert.Equal(t, []string{"skill:app", "skill:base"}, result.Removed)
+		assert.Len(t, result.Manifest.Items, 1)
+
+		_, ok := result.Manifest.GetTombstone("skill:base")
+		assert.True(t, ok)
+	})
+
+	t.Run("rejects paths outside the registry", func(t *testing.T) {
+		_, err := UpdateRegistry(tmpDir, []string{"../outside.md"}, BuildOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdateRegistry_RequiresManifest(t *testing.T) {
+	_, err := UpdateRegistry(t.TempDir(), []string{"skills/a.md"}, BuildOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run a full build first")
+}
+
+func TestValidator_ValidateChanged(t *testing.T) {
+	unchanged := &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "Bad_Name", Desc: "Bad"}, Source: "a.md"}
+	changed := &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "good", Desc: "Good", Deps: []string{"skill:missing"}}, Source: "b.md"}
+
+	result := NewValidator(t.TempDir()).ValidateChanged([]*Item{unchanged, changed}, []*Item{changed})
+
+	// Only the changed item is validated individually, but its deps are checked
+	for _, issue := range result.Issues {
+		assert.Equal(t, "b.md", issue.Path)
+	}
+	assert.True(t, result.HasErrors())
+}
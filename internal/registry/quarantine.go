@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/okto-digital/regis3/pkg/refs"
+)
+
+// Quarantined is an item left out of a manifest built with AllowErrors
+// because it failed validation.
+type Quarantined struct {
+	// ID is the item's full name, or empty for a file that couldn't be
+	// parsed into an item, such as one with conflict markers.
+	ID string `json:"id,omitempty"`
+
+	// Path is the item's source file, relative to the registry root.
+	Path string `json:"path"`
+
+	// Errors are the validation errors of the item.
+	Errors []string `json:"errors"`
+}
+
+// quarantine removes the items with validation errors from items and
+// returns the rest with the items removed, sorted by path. Items depending
+// on a removed item would fail validation without it, so they are removed
+// in turn; their errors are added to the result.
+func quarantine(items []*Item, result *ValidationResult, validator *Validator) ([]*Item, []Quarantined) {
+	var quarantined []Quarantined
+	index := make(map[string]int) // path -> index in quarantined
+
+	issues := result.Errors()
+	for len(issues) > 0 {
+		for _, issue := range issues {
+			path := filepath.Clean(issue.Path)
+			i, ok := index[path]
+			if !ok {
+				i = len(quarantined)
+				index[path] = i
+				quarantined = append(quarantined, Quarantined{Path: issue.Path})
+			}
+			message := issue.Message
+			if issue.Field != "" {
+				message = issue.Field + ": " + message
+			}
+			quarantined[i].Errors = append(quarantined[i].Errors, message)
+		}
+
+		kept := make([]*Item, 0, len(items))
+		for _, item := range items {
+			if i, ok := index[filepath.Clean(item.Source)]; ok {
+				quarantined[i].ID = item.FullName()
+				continue
+			}
+			kept = append(kept, item)
+		}
+		if len(kept) == len(items) {
+			break
+		}
+		items = kept
+
+		issues = validator.ValidateItems(items).Errors()
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	sort.Slice(quarantined, func(i, j int) bool {
+		return quarantined[i].Path < quarantined[j].Path
+	})
+	return items, quarantined
+}
+
+// GetQuarantined returns the quarantined item a reference names: by its ID,
+// by its name when the reference has no type, or by its source path.
+func (m *Manifest) GetQuarantined(ref string) (*Quarantined, bool) {
+	for i := range m.Quarantine {
+		q := &m.Quarantine[i]
+		switch {
+		case q.ID != "" && q.ID == ref:
+			return q, true
+		case q.ID != "" && refs.TypeOf(ref) == "" && refs.NameOf(q.ID) == ref:
+			return q, true
+		case filepath.Clean(q.Path) == filepath.Clean(ref):
+			return q, true
+		}
+	}
+	return nil, false
+}
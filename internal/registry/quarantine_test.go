@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest_GetQuarantined(t *testing.T) {
+	manifest := NewManifest("/test")
+	manifest.Quarantine = []Quarantined{
+		{ID: "skill:broken", Path: "skills/broken.md", Errors: []string{"desc: required field is missing"}},
+		{Path: "skills/conflict.md", Errors: []string{"content: unresolved merge conflict"}},
+	}
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{name: "by ID", ref: "skill:broken", want: "skills/broken.md"},
+		{name: "by name", ref: "broken", want: "skills/broken.md"},
+		{name: "by path", ref: "./skills/broken.md", want: "skills/broken.md"},
+		{name: "file without an item", ref: "skills/conflict.md", want: "skills/conflict.md"},
+		{name: "other type", ref: "command:broken"},
+		{name: "not quarantined", ref: "skill:testing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := manifest.GetQuarantined(tt.ref)
+			if tt.want == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.want, q.Path)
+		})
+	}
+}
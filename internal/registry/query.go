@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// queryFields are the item fields a query can test.
+var queryFields = []string{"type", "name", "tag", "cat", "status", "license"}
+
+// queryAnd separates the conditions of a query.
+var queryAnd = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// Query selects items by their metadata, e.g. the deps_query of a dynamic
+// stack. It is one or more conditions joined by "and", each "field=value"
+// or "field!=value" where field is type, name, tag, cat, status or license.
+// Values may be glob patterns:
+//
+//	type=skill and tag=golang and name!=go-legacy-*
+type Query struct {
+	text       string
+	conditions []queryCondition
+}
+
+// queryCondition is a single field comparison of a query.
+type queryCondition struct {
+	field  string
+	value  string
+	negate bool
+}
+
+// ParseQuery parses a query such as "type=skill and tag=golang".
+func ParseQuery(s string) (*Query, error) {
+	q := &Query{text: strings.TrimSpace(s)}
+	if q.text == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	for _, part := range queryAnd.Split(q.text, -1) {
+		field, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid condition %q (expected field=value)", part)
+		}
+		c := queryCondition{field: strings.TrimSpace(field)}
+		if strings.HasSuffix(c.field, "!") {
+			c.negate = true
+			c.field = strings.TrimSpace(strings.TrimSuffix(c.field, "!"))
+		}
+		c.value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if !slices.Contains(queryFields, c.field) {
+			return nil, fmt.Errorf("unknown field %q in %q (expected one of: %s)", c.field, part, strings.Join(queryFields, ", "))
+		}
+		if c.value == "" {
+			return nil, fmt.Errorf("missing value in %q", part)
+		}
+		if _, err := path.Match(c.value, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", c.value, err)
+		}
+		q.conditions = append(q.conditions, c)
+	}
+	return q, nil
+}
+
+// String returns the query as written.
+func (q *Query) String() string {
+	return q.text
+}
+
+// Matches reports whether the item meets every condition of the query.
+func (q *Query) Matches(item *Item) bool {
+	for _, c := range q.conditions {
+		if c.matches(item) == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the item's field matches the condition's value,
+// ignoring negation. A tag condition matches if any tag does.
+func (c queryCondition) matches(item *Item) bool {
+	var values []string
+	switch c.field {
+	case "license":
+		return item.MatchLicense(c.value)
+	case "type":
+		values = []string{item.Type}
+	case "name":
+		values = []string{item.Name}
+	case "tag":
+		values = item.Tags
+	case "cat":
+		values = []string{item.Cat}
+	case "status":
+		values = []string{item.Status}
+	}
+	for _, v := range values {
+		if ok, _ := path.Match(c.value, v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryItems returns the IDs of the items matching the query, sorted.
+func (m *Manifest) QueryItems(q *Query) []string {
+	var ids []string
+	for id, item := range m.Items {
+		if q.Matches(item) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ItemDeps returns the dependencies of an item: its deps, followed for
+// stacks by the items its deps_query currently matches, so dynamic stacks
+// follow the registry's content. A stack never depends on itself, and an
+// invalid query matches nothing.
+func (m *Manifest) ItemDeps(item *Item) []string {
+	if item.DepsQuery == "" || item.Type != string(TypeStack) {
+		return item.Deps
+	}
+	q, err := ParseQuery(item.DepsQuery)
+	if err != nil {
+		return item.Deps
+	}
+	deps := append([]string{}, item.Deps...)
+	for _, id := range m.QueryItems(q) {
+		if id != item.FullName() && !slices.Contains(deps, id) {
+			deps = append(deps, id)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{query: "type=skill"},
+		{query: "type=skill and tag=golang"},
+		{query: "type = skill AND name != go-legacy-*"},
+		{query: `tag="golang"`},
+		{query: "", wantErr: "empty query"},
+		{query: "type", wantErr: "expected field=value"},
+		{query: "author=me", wantErr: "unknown field"},
+		{query: "type=", wantErr: "missing value"},
+		{query: "name=[", wantErr: "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.query, q.String())
+		})
+	}
+}
+
+func TestQuery_Matches(t *testing.T) {
+	item := &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "go-errors", Tags: []string{"golang", "errors"}, Cat: "backend", License: "GPL-3.0-only"}}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"type=skill", true},
+		{"type=subagent", false},
+		{"type=skill and tag=golang", true},
+		{"type=skill and tag=python", false},
+		{"tag=err*", true},
+		{"tag!=python", true},
+		{"tag!=golang", false},
+		{"name=go-*", true},
+		{"name!=go-*", false},
+		{"cat=backend", true},
+		{"status=deprecated", false},
+		{"status!=deprecated", true},
+		{"license=GPL*", true},
+		{"license!=gpl*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, q.Matches(item))
+		})
+	}
+}
+
+func TestItem_MatchLicense(t *testing.T) {
+	tests := []struct {
+		license string
+		pattern string
+		want    bool
+	}{
+		{"MIT", "MIT", true},
+		{"MIT", "mit", true},
+		{"GPL-3.0-only", "GPL*", true},
+		{"LGPL-2.1", "GPL*", false},
+		{"LGPL-2.1", "*GPL*", true},
+		{"", "*", false},
+		{"", "none", true},
+		{"MIT", "none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.license+"/"+tt.pattern, func(t *testing.T) {
+			item := &Item{Regis3Meta: Regis3Meta{License: tt.license}}
+			assert.Equal(t, tt.want, item.MatchLicense(tt.pattern))
+		})
+	}
+}
+
+func TestManifest_ItemDeps(t *testing.T) {
+	m := NewManifest("")
+	m.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "go-errors", Tags: []string{"golang"}}})
+	m.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "go-style", Tags: []string{"golang"}}})
+	m.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "vitest", Tags: []string{"js"}}})
+	m.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "subagent", Name: "gopher", Tags: []string{"golang"}}})
+
+	stack := &Item{Regis3Meta: Regis3Meta{Type: "stack", Name: "go", Tags: []string{"golang"}, Deps: []string{"skill:go-style", "subagent:gopher"}, DepsQuery: "tag=golang"}}
+	m.AddItem(stack)
+	assert.Equal(t, []string{"skill:go-style", "subagent:gopher", "skill:go-errors"}, m.ItemDeps(stack), "deps first, matches once and never the stack itself")
+
+	// New matching items join the stack
+	m.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "go-testing", Tags: []string{"golang"}}})
+	assert.Contains(t, m.ItemDeps(stack), "skill:go-testing")
+
+	stack.DepsQuery = "not a query"
+	assert.Equal(t, stack.Deps, m.ItemDeps(stack))
+
+	skill := &Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "odd", DepsQuery: "tag=golang"}}
+	assert.Empty(t, m.ItemDeps(skill), "only stacks are dynamic")
+}
@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/refs"
+)
+
+// RefError reports a reference that doesn't name exactly one item.
+type RefError struct {
+	// Ref is the reference as given.
+	Ref string
+
+	// Candidates are the items a shorthand reference could mean.
+	// Set when the reference is ambiguous.
+	Candidates []string
+
+	// Suggestions are similar item references, closest first.
+	// Set when nothing matched.
+	Suggestions []string
+}
+
+func (e *RefError) Error() string {
+	if len(e.Candidates) > 0 {
+		return fmt.Sprintf("ambiguous reference '%s': could be %s", e.Ref, strings.Join(e.Candidates, ", "))
+	}
+	msg := fmt.Sprintf("item not found: %s", e.Ref)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, " or "))
+	}
+	return msg
+}
+
+// maxSuggestions limits how many similar references a RefError lists.
+const maxSuggestions = 3
+
+// ResolveRef maps a reference to an item's full name. References may omit
+// the type prefix (git-conventions) when exactly one item has that name or
+// alias, and may name an alias (see ResolveAlias). They are parsed with
+// refs.Parse; malformed references and references to other registries or
+// versions return an error wrapping refs.ErrInvalid. Unknown references
+// return a *RefError with similar references as suggestions.
+func (m *Manifest) ResolveRef(ref string) (string, error) {
+	if _, ok := m.Items[ref]; ok {
+		return ref, nil
+	}
+	if target, ok := m.ResolveAlias(ref); ok {
+		return target, nil
+	}
+
+	r, err := refs.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if !r.IsLocal() {
+		return "", fmt.Errorf("%w: %s (registry and version references are not supported yet)", refs.ErrInvalid, ref)
+	}
+	if id := r.ID(); id != ref {
+		// Normalized, e.g. Skill:testing
+		return m.ResolveRef(id)
+	}
+
+	if r.Type == "" {
+		var candidates []string
+		for id, item := range m.Items {
+			if item.Name == ref || item.HasAlias(ref) {
+				candidates = append(candidates, id)
+			}
+		}
+		sort.Strings(candidates)
+		switch len(candidates) {
+		case 1:
+			return candidates[0], nil
+		case 0:
+		default:
+			return "", &RefError{Ref: ref, Candidates: candidates}
+		}
+	}
+
+	return "", &RefError{Ref: ref, Suggestions: m.SimilarRefs(ref)}
+}
+
+// SimilarRefs returns up to three item references close to ref by edit
+// distance, closest first. The name part is also compared on its own, so a
+// reference with the wrong type still finds the item.
+func (m *Manifest) SimilarRefs(ref string) []string {
+	type match struct {
+		id       string
+		distance int
+	}
+
+	_, name, typed := refs.Split(ref)
+	if !typed {
+		name = ref
+	}
+	limit := len(name)/3 + 1
+
+	var matches []match
+	for id, item := range m.Items {
+		d := editDistance(name, item.Name)
+		if typed {
+			d = min(d, editDistance(ref, id))
+		}
+		if d <= limit {
+			matches = append(matches, match{id, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].id < matches[j].id
+	})
+
+	var refs []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		refs = append(refs, matches[i].id)
+	}
+	return refs
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// ReadRefList reads item references, one per line. Blank lines and
+// comments starting with "#" (whole-line or trailing) are ignored.
+func ReadRefList(r io.Reader) ([]string, error) {
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			refs = append(refs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read item list: %w", err)
+	}
+	return refs, nil
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/okto-digital/regis3/pkg/refs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest_ResolveRef(t *testing.T) {
+	manifest := NewManifest("/registry")
+	for _, item := range []*Item{
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "git-conventions", Aliases: []string{"git-flow"}}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing"}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "docker"}},
+		{Regis3Meta: Regis3Meta{Type: "subagent", Name: "docker"}},
+	} {
+		manifest.AddItem(item)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+		invalid bool
+	}{
+		{name: "full reference", ref: "skill:testing", want: "skill:testing"},
+		{name: "shorthand", ref: "git-conventions", want: "skill:git-conventions"},
+		{name: "alias", ref: "skill:git-flow", want: "skill:git-conventions"},
+		{name: "shorthand alias", ref: "git-flow", want: "skill:git-conventions"},
+		{name: "ambiguous shorthand", ref: "docker", wantErr: "ambiguous reference 'docker': could be skill:docker, subagent:docker"},
+		{name: "typo in full reference", ref: "skill:git-convention", wantErr: "item not found: skill:git-convention (did you mean skill:git-conventions?)"},
+		{name: "typo in shorthand", ref: "testin", wantErr: "did you mean skill:testing?"},
+		{name: "wrong type", ref: "subagent:testing", wantErr: "did you mean skill:testing?"},
+		{name: "nothing similar", ref: "skill:kubernetes", wantErr: "item not found: skill:kubernetes"},
+		{name: "normalized", ref: " Skill:testing", want: "skill:testing"},
+		{name: "malformed", ref: "skill:", wantErr: "has no name", invalid: true},
+		{name: "version", ref: "skill:testing@1.0.0", wantErr: "not supported yet", invalid: true},
+		{name: "other registry", ref: "team/skill:testing", wantErr: "not supported yet", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manifest.ResolveRef(tt.ref)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				if tt.invalid {
+					assert.ErrorIs(t, err, refs.ErrInvalid)
+					return
+				}
+				var refErr *RefError
+				assert.ErrorAs(t, err, &refErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"skill:testing", "skill:testing", 0},
+		{"skill:tseting", "skill:testing", 2},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, editDistance(tt.a, tt.b), "%s -> %s", tt.a, tt.b)
+	}
+}
+
+func TestReadRefList(t *testing.T) {
+	input := `# Team defaults
+skill:git-conventions
+  testing   # shorthand is fine
+
+stack:web # trailing comment
+`
+
+	refs, err := ReadRefList(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:git-conventions", "testing", "stack:web"}, refs)
+}
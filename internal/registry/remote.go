@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// IsRemote reports whether location is a git URL (https://host/org/repo.git,
+// ssh://host/repo or git@host:org/repo.git) rather than a local path.
+func IsRemote(location string) bool {
+	if strings.Contains(location, "://") {
+		return true
+	}
+	// scp-like syntax: user@host:path
+	at := strings.Index(location, "@")
+	colon := strings.Index(location, ":")
+	return at > 0 && colon > at && !strings.ContainsAny(location[:colon], `/\`)
+}
+
+// ValidateRemoteURL returns an error if url can't be used as a git URL.
+// URLs starting with "-" are rejected, as git would read them as options.
+func ValidateRemoteURL(url string) error {
+	if strings.HasPrefix(url, "-") {
+		return fmt.Errorf("git URL %q must not start with '-'", url)
+	}
+	return nil
+}
+
+// RemoteName returns the repository name of a git URL, lowercased and
+// without a .git suffix (e.g. registry for git@github.com:org/Registry.git).
+func RemoteName(url string) string {
+	url = strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(url, ":/"); i >= 0 {
+		url = url[i+1:]
+	}
+	return strings.ToLower(strings.TrimSuffix(url, ".git"))
+}
+
+// Remote is a registry backed by a git repository. It is used through a
+// clone in a local cache, which builds and installs read like any local
+// registry.
+type Remote struct {
+	// URL is the repository to clone.
+	URL string
+
+	// Dir is the clone in the cache.
+	Dir string
+}
+
+// NewRemote returns the remote registry at url, cloned into cacheDir. The
+// clone is named after the repository and a hash of the URL, so remotes of
+// the same name don't share a clone.
+func NewRemote(cacheDir, url string) *Remote {
+	sum := sha256.Sum256([]byte(url))
+	name := RemoteName(url) + "-" + hex.EncodeToString(sum[:4])
+	return &Remote{URL: url, Dir: filepath.Join(cacheDir, name)}
+}
+
+// Cloned reports whether the repository has been cloned into the cache.
+func (r *Remote) Cloned() bool {
+	_, err := os.Stat(filepath.Join(r.Dir, ".git"))
+	return err == nil
+}
+
+// Sync clones the repository into the cache, or fetches it and moves the
+// clone to the remote's default branch, discarding changes made in the
+// cache. It reports whether the registry content changed.
+func (r *Remote) Sync() (bool, error) {
+	if err := ValidateRemoteURL(r.URL); err != nil {
+		return false, err
+	}
+	if !r.Cloned() {
+		if err := os.MkdirAll(filepath.Dir(r.Dir), 0755); err != nil {
+			return false, fmt.Errorf("failed to create registry cache: %w", err)
+		}
+		// A failed clone may leave a partial directory behind
+		if err := os.RemoveAll(r.Dir); err != nil {
+			return false, err
+		}
+		if _, err := git("", "clone", "--quiet", "--", r.URL, r.Dir); err != nil {
+			return false, fmt.Errorf("failed to clone %s: %w", r.URL, err)
+		}
+		return true, nil
+	}
+
+	before, err := git(r.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	if _, err := git(r.Dir, "fetch", "--quiet", "origin"); err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", r.URL, err)
+	}
+	if _, err := git(r.Dir, "reset", "--quiet", "--hard", "origin/HEAD"); err != nil {
+		return false, err
+	}
+	after, err := git(r.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	return before != after, nil
+}
+
+// git runs a git command in dir and returns its trimmed output. Errors
+// carry git's message.
+func git(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	out, err := exec.Command("git", args...).CombinedOutput()
+	msg := strings.TrimSpace(string(out))
+	if err != nil {
+		if msg == "" {
+			return "", err
+		}
+		return "", fmt.Errorf("%s", msg)
+	}
+	return msg, nil
+}
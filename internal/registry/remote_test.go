@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRemote(t *testing.T) {
+	tests := []struct {
+		location string
+		want     bool
+		name     string
+	}{
+		{"git@github.com:org/Registry.git", true, "registry"},
+		{"https://github.com/org/team-registry.git", true, "team-registry"},
+		{"ssh://git@host/srv/registry/", true, "registry"},
+		{"file:///srv/registry.git", true, "registry"},
+		{"/srv/registry", false, ""},
+		{"~/registry", false, ""},
+		{"registry@v2", false, ""},
+		{`C:\registry`, false, ""},
+		{"dir/user@host:path", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRemote(tt.location))
+			if tt.want {
+				assert.Equal(t, tt.name, RemoteName(tt.location))
+			}
+		})
+	}
+}
+
+func TestValidateRemoteURL(t *testing.T) {
+	assert.NoError(t, ValidateRemoteURL("https://github.com/org/registry.git"))
+	assert.NoError(t, ValidateRemoteURL("git@github.com:org/registry.git"))
+	assert.ErrorContains(t, ValidateRemoteURL("--upload-pack=touch pwned://x"), "must not start with '-'")
+	assert.ErrorContains(t, ValidateRemoteURL("-u://x"), "must not start with '-'")
+}
+
+func TestRemote_Sync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := t.TempDir()
+	run := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commit := func(rel, content string) {
+		writeRegistryFile(t, src, rel, content)
+		run(src, "add", ".")
+		run(src, "commit", "-q", "-m", rel)
+	}
+	run(src, "init", "-q")
+	commit("skills/one.md", skillFile("one", "First remote skill"))
+
+	cache := t.TempDir()
+	remote := NewRemote(cache, "file://"+src)
+	assert.Equal(t, cache, filepath.Dir(remote.Dir))
+	assert.NotEqual(t, remote.Dir, NewRemote(cache, "file://"+src+"-fork").Dir)
+	assert.False(t, remote.Cloned())
+
+	changed, err := remote.Sync()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, remote.Cloned())
+	assert.FileExists(t, filepath.Join(remote.Dir, "skills", "one.md"))
+
+	changed, err = remote.Sync()
+	require.NoError(t, err)
+	assert.False(t, changed, "nothing new")
+
+	// New commits arrive; changes made in the clone are discarded
+	commit("skills/two.md", skillFile("two", "Second remote skill"))
+	require.NoError(t, os.WriteFile(filepath.Join(remote.Dir, "skills", "one.md"), []byte("edited"), 0644))
+	changed, err = remote.Sync()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.FileExists(t, filepath.Join(remote.Dir, "skills", "two.md"))
+	content, err := os.ReadFile(filepath.Join(remote.Dir, "skills", "one.md"))
+	require.NoError(t, err)
+	assert.Equal(t, skillFile("one", "First remote skill"), string(content))
+
+	_, err = NewRemote(cache, "file://"+filepath.Join(src, "missing")).Sync()
+	assert.ErrorContains(t, err, "failed to clone")
+
+	// URLs are never read as git options
+	marker := filepath.Join(t.TempDir(), "pwned")
+	_, err = NewRemote(cache, "--upload-pack=touch "+marker+";://x").Sync()
+	assert.ErrorContains(t, err, "must not start with '-'")
+	assert.NoFileExists(t, marker)
+}
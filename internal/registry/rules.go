@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/refs"
+)
+
+// DependencyRules restricts which item types an item type may depend on.
+// Keys are item types; values list the dependency types they may reference.
+// Types without an entry are unrestricted.
+type DependencyRules map[string][]string
+
+// Allows reports whether an item of itemType may depend on an item of depType.
+func (r DependencyRules) Allows(itemType, depType string) bool {
+	allowed, ok := r[itemType]
+	if !ok {
+		return true
+	}
+	for _, t := range allowed {
+		if t == depType {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns a human-readable list of allowed dependency types.
+func (r DependencyRules) Describe(itemType string) string {
+	allowed := r[itemType]
+	if len(allowed) == 0 {
+		return "none"
+	}
+	return strings.Join(allowed, ", ")
+}
+
+// Check returns an error describing a rule violation, or nil if the dependency is allowed.
+func (r DependencyRules) Check(itemType, dep string) error {
+	depType, _, ok := refs.Split(dep)
+	if !ok || r.Allows(itemType, depType) {
+		return nil
+	}
+	return fmt.Errorf("%s may not depend on %s (%s); allowed dependency types: %s",
+		itemType, depType, dep, r.Describe(itemType))
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/okto-digital/regis3/internal/pathutil"
+	"github.com/okto-digital/regis3/internal/profile"
 	"github.com/okto-digital/regis3/pkg/frontmatter"
 	"gopkg.in/yaml.v3"
 )
@@ -15,6 +18,25 @@ import (
 type Scanner struct {
 	// RootDir is the registry root directory.
 	RootDir string
+
+	// Filter restricts which files are scanned.
+	Filter Filter
+
+	// StagingDir is the import staging directory, which is skipped (see
+	// Ignore). It is relative to RootDir or absolute; empty means
+	// DefaultStagingDir.
+	StagingDir string
+
+	// Dialect selects the frontmatter formats and keys item metadata is
+	// read from.
+	Dialect Dialect
+
+	// Timings, if set, records time spent walking ("scan") and parsing files ("parse").
+	Timings *profile.Timings
+
+	// keepEncrypted leaves encrypted bodies as they are instead of
+	// decrypting them.
+	keepEncrypted bool
 }
 
 // NewScanner creates a new scanner for the given registry directory.
@@ -30,6 +52,8 @@ type ScanResult struct {
 	Errors []ScanError
 	// Skipped are files without regis3 frontmatter.
 	Skipped []string
+	// Excluded are markdown files left out by the filter.
+	Excluded []string
 }
 
 // ScanError represents an error encountered while scanning a file.
@@ -54,11 +78,28 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 		Skipped: make([]string, 0),
 	}
 
+	if err := s.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.Dialect.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Check if root directory exists
 	if _, err := os.Stat(s.RootDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("registry directory does not exist: %s", s.RootDir)
 	}
 
+	ignore := NewIgnore(s.RootDir, s.StagingDir, s.Filter)
+
+	// Walking time is the total minus time spent parsing
+	start := time.Now()
+	var parseTime time.Duration
+	defer func() {
+		s.Timings.Add("scan", time.Since(start)-parseTime)
+		s.Timings.Add("parse", parseTime)
+	}()
+
 	err := filepath.Walk(s.RootDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			result.Errors = append(result.Errors, ScanError{
@@ -69,26 +110,33 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 			return nil // continue walking
 		}
 
-		// Skip directories
+		rel, relErr := filepath.Rel(s.RootDir, path)
+		if relErr != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+
 		if info.IsDir() {
-			// Skip .build directory
-			if info.Name() == ".build" {
-				return filepath.SkipDir
-			}
-			// Skip .git directory
-			if info.Name() == ".git" {
+			if ignore.SkipDir(rel) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		// Only process .md files
-		if !strings.HasSuffix(strings.ToLower(info.Name()), ".md") {
+		if ignore.SkipFile(rel) {
+			return nil
+		}
+
+		if ignore.Excluded(rel) {
+			result.Excluded = append(result.Excluded, path)
 			return nil
 		}
 
 		// Parse the file
+		parseStart := time.Now()
 		item, err := s.parseFile(path)
+		parseTime += time.Since(parseStart)
 		if err != nil {
 			if err == ErrNoRegis3Block {
 				result.Skipped = append(result.Skipped, path)
@@ -124,21 +172,15 @@ func (s *Scanner) parseFile(path string) (*Item, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Parse frontmatter
-	var fm FrontMatter
-	doc, err := frontmatter.UnmarshalBytes(content, &fm)
-	if err != nil {
-		// Check if it's a "no frontmatter" error
-		if err == frontmatter.ErrNoFrontmatter {
-			return nil, ErrNoRegis3Block
-		}
-		// Provide helpful YAML error messages
-		return nil, formatYAMLError(err)
+	// A file in the middle of a merge is neither old nor new content
+	if conflict := findConflict(content); conflict != nil {
+		return nil, conflict
 	}
 
-	// Check if regis3 block exists
-	if fm.Regis3.Type == "" && fm.Regis3.Name == "" {
-		return nil, ErrNoRegis3Block
+	// Parse frontmatter
+	meta, doc, err := s.Dialect.ParseMeta(content)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate relative path from registry root
@@ -147,11 +189,20 @@ func (s *Scanner) parseFile(path string) (*Item, error) {
 		relPath = path
 	}
 
+	// Encrypted bodies are read with the configured key
+	body := doc.Body
+	if !s.keepEncrypted {
+		if body, err = decryptBody(doc.Body); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create item
 	item := &Item{
-		Regis3Meta: fm.Regis3,
+		Regis3Meta: *meta,
 		Source:     relPath,
-		Content:    doc.Body,
+		Content:    body,
+		Size:       len(body),
 		SourceDir:  filepath.Dir(relPath),
 	}
 
@@ -163,6 +214,52 @@ func (s *Scanner) ScanFile(path string) (*Item, error) {
 	return s.parseFile(path)
 }
 
+// LoadContent reads the item's body from its source file in the registry.
+// Manifests don't store content, so items loaded from one have none until
+// it is loaded.
+func (i *Item) LoadContent(registryPath string) error {
+	path, err := pathutil.Join(registryPath, i.Source)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read source: %w", err)
+	}
+	doc, err := frontmatter.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	content, err := decryptBody(doc.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", i.Source, err)
+	}
+	i.Content = content
+	return nil
+}
+
+// LoadVariant reads the body of the item's named variant from its file,
+// relative to the item file. Frontmatter in the file is ignored.
+func (i *Item) LoadVariant(registryPath, name string) (string, error) {
+	file, ok := i.Variants[name]
+	if !ok {
+		return "", fmt.Errorf("%s has no variant %q (variants: %s)", i.FullName(), name, strings.Join(i.VariantNames(), ", "))
+	}
+	path, err := pathutil.Join(registryPath, i.SourceDir, file)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read variant %s: %w", name, err)
+	}
+	body := string(data)
+	if doc, err := frontmatter.ParseBytes(data); err == nil {
+		body = doc.Body
+	}
+	return decryptBody(body)
+}
+
 // HasRegis3Frontmatter checks if a file has valid regis3 frontmatter.
 func HasRegis3Frontmatter(path string) (bool, error) {
 	content, err := os.ReadFile(path)
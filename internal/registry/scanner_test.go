@@ -3,6 +3,7 @@ package registry
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,52 @@ func TestScanner_Scan(t *testing.T) {
 	assert.True(t, foundTypes["stack"], "should find stack type")
 }
 
+func TestScanner_SkipsImportStaging(t *testing.T) {
+	dir := t.TempDir()
+	staged := "---\nregis3:\n  type: skill\n  name: staged\n  desc: Split from a document, not reviewed yet\n---\n# Staged\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "import"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skills", "import"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "import", "staged.md"), []byte(staged), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "import", "kept.md"),
+		[]byte(strings.ReplaceAll(staged, "name: staged", "name: kept")), 0644))
+
+	result, err := NewScanner(dir).Scan()
+	require.NoError(t, err)
+
+	require.Len(t, result.Items, 1, "only the top-level staging directory is skipped")
+	assert.Equal(t, "kept", result.Items[0].Name)
+
+	// A configured staging directory replaces the default
+	scanner := NewScanner(dir)
+	scanner.StagingDir = filepath.Join(dir, "skills", "import")
+	result, err = scanner.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "staged", result.Items[0].Name)
+}
+
+func TestStagingRel(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		name    string
+		staging string
+		want    string
+	}{
+		{"default", "", "import"},
+		{"relative", ".staging", ".staging"},
+		{"nested", filepath.Join("drafts", "staging"), "drafts/staging"},
+		{"absolute inside", filepath.Join(root, ".staging"), ".staging"},
+		{"outside", filepath.Join(filepath.Dir(root), "staging"), ""},
+		{"registry root", root, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StagingRel(root, tt.staging))
+		})
+	}
+}
+
 func TestScanner_ScanFile(t *testing.T) {
 	scanner := NewScanner("../../registry")
 
@@ -264,3 +311,30 @@ Content.
 		})
 	}
 }
+
+func TestItem_LoadVariant(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "skills", "variants"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "skills", "variants", "concise.md"), []byte("Be brief."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "skills", "variants", "detailed.md"), []byte("---\ntitle: Detailed\n---\nExplain everything.\n"), 0644))
+
+	item := &Item{
+		Regis3Meta: Regis3Meta{Type: "skill", Name: "review", Variants: map[string]string{
+			"concise":  "variants/concise.md",
+			"detailed": "variants/detailed.md",
+		}},
+		SourceDir: "skills",
+	}
+
+	body, err := item.LoadVariant(tmpDir, "concise")
+	require.NoError(t, err)
+	assert.Equal(t, "Be brief.", body)
+
+	body, err = item.LoadVariant(tmpDir, "detailed")
+	require.NoError(t, err)
+	assert.Equal(t, "Explain everything.", strings.TrimSpace(body))
+
+	_, err = item.LoadVariant(tmpDir, "terse")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "variants: concise, detailed")
+}
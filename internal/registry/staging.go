@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultStagingDir is the directory, relative to the registry root, where
+// imported files wait for regis3 frontmatter.
+const DefaultStagingDir = "import"
+
+// StagingRel returns the staging directory relative to the registry root,
+// with forward slashes, or "" when it lies outside the registry. An empty
+// stagingDir means DefaultStagingDir; relative paths start at the registry
+// root.
+func StagingRel(registryPath, stagingDir string) string {
+	if stagingDir == "" {
+		stagingDir = DefaultStagingDir
+	}
+	if !filepath.IsAbs(stagingDir) {
+		return filepath.ToSlash(filepath.Clean(stagingDir))
+	}
+
+	root, err := filepath.Abs(registryPath)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(stagingDir))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaleItem is an item whose files haven't changed since the cutoff and that
+// is a draft or nothing depends on.
+type StaleItem struct {
+	// ID is the item's full name.
+	ID string
+
+	// Source is the item's source file.
+	Source string
+
+	// LastChanged is the latest change to the item's source or additional
+	// files.
+	LastChanged time.Time
+
+	// Draft reports whether the item is marked draft.
+	Draft bool
+
+	// Dependents counts the items that depend on it, directly, through a
+	// capability it provides or as a stack alternative.
+	Dependents int
+}
+
+// StaleReport is the result of FindStale.
+type StaleReport struct {
+	// Items are the stale items, oldest first.
+	Items []StaleItem
+
+	// FromGit reports whether change times come from git history. Outside
+	// a git repository, file modification times are used.
+	FromGit bool
+}
+
+// FindStale lists the manifest's items unchanged since cutoff that are
+// drafts or have no dependents. Change times come from the registry's git
+// history when available; files git doesn't know use their modification
+// time.
+func FindStale(registryPath string, manifest *Manifest, cutoff time.Time) *StaleReport {
+	history, fromGit := gitChangeTimes(registryPath)
+	report := &StaleReport{FromGit: fromGit}
+	dependents := manifest.dependentCounts()
+
+	for id, item := range manifest.Items {
+		files := []string{item.Source}
+		expanded, _ := item.ExpandFiles(registryPath)
+		for _, f := range expanded {
+			files = append(files, filepath.Join(item.SourceDir, f))
+		}
+
+		var last time.Time
+		for _, file := range files {
+			changed, ok := history[filepath.ToSlash(filepath.Clean(file))]
+			if !ok {
+				info, err := os.Stat(filepath.Join(registryPath, file))
+				if err != nil {
+					continue
+				}
+				changed = info.ModTime()
+			}
+			if changed.After(last) {
+				last = changed
+			}
+		}
+		if last.IsZero() || !last.Before(cutoff) {
+			continue
+		}
+
+		draft := item.Status == string(StatusDraft)
+		if !draft && dependents[id] > 0 {
+			continue
+		}
+		report.Items = append(report.Items, StaleItem{
+			ID:          id,
+			Source:      item.Source,
+			LastChanged: last,
+			Draft:       draft,
+			Dependents:  dependents[id],
+		})
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if !a.LastChanged.Equal(b.LastChanged) {
+			return a.LastChanged.Before(b.LastChanged)
+		}
+		return a.ID < b.ID
+	})
+	return report
+}
+
+// dependentCounts counts, for each item, the items that depend on it.
+// A capability dependency counts for every provider.
+func (m *Manifest) dependentCounts() map[string]int {
+	counts := make(map[string]int)
+	for id, item := range m.Items {
+		targets := make(map[string]bool)
+		for _, ref := range append(append([]string{}, m.ItemDeps(item)...), item.OneOf...) {
+			if IsCapability(ref) {
+				for _, provider := range m.Providers(ref) {
+					targets[provider] = true
+				}
+				continue
+			}
+			if target, err := m.ResolveRef(ref); err == nil {
+				targets[target] = true
+			}
+		}
+		for target := range targets {
+			if target != id {
+				counts[target]++
+			}
+		}
+	}
+	return counts
+}
+
+// gitChangeTimes returns the time of the last commit touching each file in
+// the registry, keyed by slash-separated path relative to the registry. It
+// reports false when the registry isn't in a git repository.
+func gitChangeTimes(registryPath string) (map[string]time.Time, bool) {
+	cmd := exec.Command("git", "-C", registryPath, "-c", "core.quotePath=false", "log", "--format=%x00%ct", "--name-only", "--relative", "--no-renames", "--", ".")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+
+	times := make(map[string]time.Time)
+	var commit time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if stamp, ok := strings.CutPrefix(line, "\x00"); ok {
+			if seconds, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+				commit = time.Unix(seconds, 0)
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		// Commits are newest first, so the first time seen is the latest
+		if _, seen := times[line]; !seen {
+			times[line] = commit
+		}
+	}
+	return times, true
+}
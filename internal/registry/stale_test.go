@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staleRegistry(t *testing.T) (string, *Manifest) {
+	t.Helper()
+	root := t.TempDir()
+	writeRegistryFile(t, root, "skills/base.md", skillFile("base", "Base skill"))
+	writeRegistryFile(t, root, "skills/app.md", skillFile("app", "App skill", "skill:base"))
+	writeRegistryFile(t, root, "skills/draft.md", "---\nregis3:\n  type: skill\n  name: draft\n  desc: Draft skill\n  status: draft\n---\n# draft\n")
+
+	scan, err := NewScanner(root).Scan()
+	require.NoError(t, err)
+	return root, newManifestFromScan(root, scan, Filter{}, nil)
+}
+
+func staleIDs(report *StaleReport) []string {
+	var ids []string
+	for _, item := range report.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestFindStale_ModTimes(t *testing.T) {
+	root, manifest := staleRegistry(t)
+	old := time.Now().AddDate(-1, 0, 0)
+	for _, file := range []string{"skills/base.md", "skills/app.md", "skills/draft.md"} {
+		require.NoError(t, os.Chtimes(filepath.Join(root, file), old, old))
+	}
+
+	report := FindStale(root, manifest, time.Now().AddDate(0, 0, -180))
+	assert.False(t, report.FromGit)
+	// base has a dependent and isn't a draft
+	assert.Equal(t, []string{"skill:app", "skill:draft"}, staleIDs(report))
+	assert.True(t, report.Items[1].Draft)
+
+	report = FindStale(root, manifest, time.Now().AddDate(-2, 0, 0))
+	assert.Empty(t, report.Items)
+}
+
+func TestFindStale_GitHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root, manifest := staleRegistry(t)
+
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("", "init", "-q")
+	git("2020-01-01T00:00:00Z", "add", ".")
+	git("2020-01-01T00:00:00Z", "commit", "-q", "-m", "initial")
+	writeRegistryFile(t, root, "skills/app.md", skillFile("app", "Changed app skill", "skill:base"))
+	git("", "add", ".")
+	git(time.Now().Format(time.RFC3339), "commit", "-q", "-m", "update app")
+
+	// Modification times are all recent; git history decides
+	report := FindStale(root, manifest, time.Now().AddDate(0, 0, -180))
+	assert.True(t, report.FromGit)
+	assert.Equal(t, []string{"skill:draft"}, staleIDs(report))
+	assert.Equal(t, 2020, report.Items[0].LastChanged.UTC().Year())
+}
+
+func TestManifest_DependentCounts(t *testing.T) {
+	manifest := NewManifest("/tmp")
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "git", Provides: []string{"capability:vcs"}}})
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "base"}})
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "skill", Name: "app", Deps: []string{"base", "capability:vcs"}}})
+	manifest.AddItem(&Item{Regis3Meta: Regis3Meta{Type: "stack", Name: "web", OneOf: []string{"skill:base"}}})
+
+	counts := manifest.dependentCounts()
+	assert.Equal(t, 2, counts["skill:base"])
+	assert.Equal(t, 1, counts["skill:git"])
+	assert.Equal(t, 0, counts["skill:app"])
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/okto-digital/regis3/pkg/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// DepSuggestion is a registry item mentioned in an item's content but not
+// listed in its deps.
+type DepSuggestion struct {
+	// Ref is the suggested dependency (type:name).
+	Ref string `json:"ref"`
+
+	// Mention is the text that matched, as it appears in the content.
+	Mention string `json:"mention"`
+}
+
+// SuggestDeps scans an item's content for mentions of other manifest items,
+// either as a full type:name reference or by bare name or alias, and returns
+// those not yet listed in deps. Bare names shared by several items are
+// ambiguous and skipped; mention the full reference instead. Suggestions that
+// the dependency rules forbid are dropped. Results are sorted by ref.
+func SuggestDeps(item *Item, manifest *Manifest, rules DependencyRules) []DepSuggestion {
+	existing := make(map[string]bool, len(item.Deps))
+	for _, dep := range item.Deps {
+		if target, ok := manifest.ResolveAlias(dep); ok {
+			dep = target
+		}
+		existing[dep] = true
+	}
+
+	byName := make(map[string][]string) // lowercase name or alias -> refs
+	for ref, other := range manifest.Items {
+		names := map[string]bool{strings.ToLower(other.Name): true}
+		for _, alias := range other.Aliases {
+			names[strings.ToLower(alias)] = true
+		}
+		for name := range names {
+			byName[name] = append(byName[name], ref)
+		}
+	}
+
+	found := make(map[string]string) // ref -> mention
+	for _, token := range mentionTokens(item.Content) {
+		lower := strings.ToLower(token)
+		if target, ok := manifest.ResolveAlias(lower); ok {
+			lower = target
+		}
+		if _, ok := manifest.Items[lower]; ok {
+			if _, seen := found[lower]; !seen {
+				found[lower] = token
+			}
+			continue
+		}
+		if refs := byName[lower]; len(refs) == 1 {
+			if _, seen := found[refs[0]]; !seen {
+				found[refs[0]] = token
+			}
+		}
+	}
+
+	var suggestions []DepSuggestion
+	for ref, mention := range found {
+		if ref == item.FullName() || existing[ref] || rules.Check(item.Type, ref) != nil {
+			continue
+		}
+		suggestions = append(suggestions, DepSuggestion{Ref: ref, Mention: mention})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Ref < suggestions[j].Ref
+	})
+	return suggestions
+}
+
+// mentionTokens splits content into candidate item references: runs of
+// letters, digits, "-", "_" and ":" with surrounding punctuation trimmed.
+func mentionTokens(content string) []string {
+	fields := strings.FieldsFunc(content, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == ':')
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.Trim(f, "-_:"); len(f) >= 3 {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+// AddDeps appends deps to the regis3.deps list in the frontmatter of the
+// file at path, keeping the body and other fields intact. Deps already
+// listed are skipped.
+func AddDeps(path string, deps []string) error {
+	return addToList(path, "deps", deps)
+}
+
+// AddFiles appends files to the regis3.files list in the frontmatter of the
+// file at path, like AddDeps.
+func AddFiles(path string, files []string) error {
+	return addToList(path, "files", files)
+}
+
+// addToList appends values to the regis3 list key in the frontmatter of the
+// file at path, skipping values already listed.
+func addToList(path, key string, values []string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	doc, err := frontmatter.ParseBytes(content)
+	if err != nil {
+		return err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc.Frontmatter), &root); err != nil {
+		return formatYAMLError(err)
+	}
+	meta := mappingValue(&root, "regis3")
+	if meta == nil || meta.Kind != yaml.MappingNode {
+		return ErrNoRegis3Block
+	}
+
+	list := mappingValue(meta, key)
+	if list == nil {
+		meta.Content = append(meta.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"})
+		list = meta.Content[len(meta.Content)-1]
+	} else if list.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s: expected a list", key)
+	}
+
+	listed := make(map[string]bool, len(list.Content))
+	for _, n := range list.Content {
+		listed[n.Value] = true
+	}
+	for _, value := range values {
+		if !listed[value] {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+			listed[value] = true
+		}
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	enc.Close()
+
+	out := "---\n" + b.String() + "---\n" + doc.Body
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(out), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping (or a document
+// wrapping one), or nil if absent.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestDeps(t *testing.T) {
+	manifest := NewManifest("/registry")
+	for _, item := range []*Item{
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "git-conventions"}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "testing"}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "docker"}},
+		{Regis3Meta: Regis3Meta{Type: "subagent", Name: "docker"}},
+		{Regis3Meta: Regis3Meta{Type: "stack", Name: "web"}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "writer"}},
+		{Regis3Meta: Regis3Meta{Type: "skill", Name: "code-review", Aliases: []string{"reviewing"}}},
+	} {
+		manifest.AddItem(item)
+	}
+
+	item := &Item{
+		Regis3Meta: Regis3Meta{Type: "skill", Name: "writer", Deps: []string{"skill:testing"}},
+		Content: "# Writer\n\nFollow Git-Conventions. Run testing before commits.\n" +
+			"Use subagent:docker, not plain docker. See stack:web. The writer skill.\n" +
+			"Finish by reviewing.\n",
+	}
+
+	tests := []struct {
+		name  string
+		rules DependencyRules
+		want  []DepSuggestion
+	}{
+		{
+			name: "unrestricted",
+			want: []DepSuggestion{
+				{Ref: "skill:code-review", Mention: "reviewing"},
+				{Ref: "skill:git-conventions", Mention: "Git-Conventions"},
+				{Ref: "stack:web", Mention: "stack:web"},
+				{Ref: "subagent:docker", Mention: "subagent:docker"},
+			},
+		},
+		{
+			name:  "rules drop forbidden types",
+			rules: DependencyRules{"skill": {"skill"}},
+			want: []DepSuggestion{
+				{Ref: "skill:code-review", Mention: "reviewing"},
+				{Ref: "skill:git-conventions", Mention: "Git-Conventions"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SuggestDeps(item, manifest, tt.rules))
+		})
+	}
+}
+
+func TestAddDeps(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		deps     []string
+		contains []string
+	}{
+		{
+			name:     "creates deps list",
+			content:  skillFile("app", "App skill") + "\nBody text.\n",
+			deps:     []string{"skill:base"},
+			contains: []string{"  deps:\n    - skill:base\n", "---\n# app\n\nBody text.\n"},
+		},
+		{
+			name:     "appends to existing list",
+			content:  skillFile("app", "App skill", "skill:base"),
+			deps:     []string{"skill:base", "skill:other"},
+			contains: []string{"  deps:\n    - skill:base\n    - skill:other\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.md")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			require.NoError(t, AddDeps(path, tt.deps))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, string(data), s)
+			}
+
+			item, err := NewScanner(filepath.Dir(path)).ScanFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "App skill", item.Desc)
+		})
+	}
+}
+
+func TestAddDeps_NoRegis3Block(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\ntitle: x\n---\nBody\n"), 0644))
+
+	assert.ErrorIs(t, AddDeps(path, []string{"skill:base"}), ErrNoRegis3Block)
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Tombstone records an item that was removed from the registry, so projects
+// that still have it installed can be told it no longer exists upstream.
+type Tombstone struct {
+	// ID is the full name (type:name) of the removed item.
+	ID string `json:"id"`
+
+	// Source is the path the item was last built from.
+	Source string `json:"source"`
+
+	// RemovedAt is the build time at which the item was first found missing.
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// RecordTombstones compares the manifest against the previous build and
+// records items that disappeared. Tombstones from earlier builds are kept
+// unless the item has since been added back. Items left out by the
+// manifest's filter, or quarantined, are not considered removed.
+func (m *Manifest) RecordTombstones(previous *Manifest) {
+	if previous == nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, q := range m.Quarantine {
+		if q.ID != "" {
+			seen[q.ID] = true
+		}
+	}
+	var tombstones []Tombstone
+
+	for _, t := range previous.Tombstones {
+		if _, ok := m.Items[t.ID]; ok || seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		tombstones = append(tombstones, t)
+	}
+
+	for id, item := range previous.Items {
+		if _, ok := m.Items[id]; ok || seen[id] {
+			continue
+		}
+		if m.Filter != nil && !m.Filter.Matches(filepath.ToSlash(item.Source)) {
+			continue
+		}
+		seen[id] = true
+		tombstones = append(tombstones, Tombstone{
+			ID:        id,
+			Source:    item.Source,
+			RemovedAt: m.Generated,
+		})
+	}
+
+	// Items quarantined by the previous build were removed if they are
+	// neither built nor quarantined now
+	for _, q := range previous.Quarantine {
+		if _, ok := m.Items[q.ID]; ok || q.ID == "" || seen[q.ID] {
+			continue
+		}
+		if m.Filter != nil && !m.Filter.Matches(filepath.ToSlash(q.Path)) {
+			continue
+		}
+		seen[q.ID] = true
+		tombstones = append(tombstones, Tombstone{
+			ID:        q.ID,
+			Source:    q.Path,
+			RemovedAt: m.Generated,
+		})
+	}
+
+	sort.Slice(tombstones, func(i, j int) bool {
+		return tombstones[i].ID < tombstones[j].ID
+	})
+	m.Tombstones = tombstones
+}
+
+// GetTombstone returns the tombstone for a removed item.
+func (m *Manifest) GetTombstone(id string) (*Tombstone, bool) {
+	for i := range m.Tombstones {
+		if m.Tombstones[i].ID == id {
+			return &m.Tombstones[i], true
+		}
+	}
+	return nil, false
+}
@@ -3,7 +3,14 @@ package registry
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/okto-digital/regis3/pkg/refs"
 )
 
 // ItemType represents the type of a registry item.
@@ -91,19 +98,28 @@ type TargetOverride struct {
 
 // Regis3Meta contains the regis3 namespace metadata from YAML frontmatter.
 type Regis3Meta struct {
-	Type    string                    `yaml:"type" json:"type"`
-	Name    string                    `yaml:"name" json:"name"`
-	Desc    string                    `yaml:"desc" json:"desc"`
-	Cat     string                    `yaml:"cat,omitempty" json:"cat,omitempty"`
-	Deps    []string                  `yaml:"deps,omitempty" json:"deps,omitempty"`
-	Tags    []string                  `yaml:"tags,omitempty" json:"tags,omitempty"`
-	Files   []string                  `yaml:"files,omitempty" json:"files,omitempty"`
-	Status  string                    `yaml:"status,omitempty" json:"status,omitempty"`
-	Author  string                    `yaml:"author,omitempty" json:"author,omitempty"`
-	Order   int                       `yaml:"order,omitempty" json:"order,omitempty"`
-	Target  map[string]TargetOverride `yaml:"target,omitempty" json:"target,omitempty"`
-	Trigger string                    `yaml:"trigger,omitempty" json:"trigger,omitempty"`
-	Run     string                    `yaml:"run,omitempty" json:"run,omitempty"`
+	Type       string                    `yaml:"type" json:"type"`
+	Name       string                    `yaml:"name" json:"name"`
+	Desc       string                    `yaml:"desc" json:"desc"`
+	Aliases    []string                  `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Cat        string                    `yaml:"cat,omitempty" json:"cat,omitempty"`
+	Deps       []string                  `yaml:"deps,omitempty" json:"deps,omitempty"`
+	DepsQuery  string                    `yaml:"deps_query,omitempty" json:"deps_query,omitempty"`
+	Provides   []string                  `yaml:"provides,omitempty" json:"provides,omitempty"`
+	OneOf      []string                  `yaml:"one_of,omitempty" json:"one_of,omitempty"`
+	Tags       []string                  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Files      []string                  `yaml:"files,omitempty" json:"files,omitempty"`
+	Variants   map[string]string         `yaml:"variants,omitempty" json:"variants,omitempty"`
+	Status     string                    `yaml:"status,omitempty" json:"status,omitempty"`
+	ReplacedBy string                    `yaml:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	Author     string                    `yaml:"author,omitempty" json:"author,omitempty"`
+	License    string                    `yaml:"license,omitempty" json:"license,omitempty"`
+	Order      int                       `yaml:"order,omitempty" json:"order,omitempty"`
+	Target     map[string]TargetOverride `yaml:"target,omitempty" json:"target,omitempty"`
+	Trigger    string                    `yaml:"trigger,omitempty" json:"trigger,omitempty"`
+	Run        string                    `yaml:"run,omitempty" json:"run,omitempty"`
+	Mode       string                    `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Setup      string                    `yaml:"setup,omitempty" json:"setup,omitempty"`
 }
 
 // FrontMatter wraps the regis3 namespace for parsing.
@@ -121,13 +137,19 @@ type Item struct {
 	// Content is the markdown body (excluding frontmatter).
 	Content string `json:"-"`
 
+	// Size is the length of Content in bytes, computed at build time.
+	Size int `json:"size,omitempty"`
+
 	// SourceDir is the directory containing the source file.
 	SourceDir string `json:"source_dir"`
+
+	// Checksums records size and SHA256 of the additional files, computed at build time.
+	Checksums []FileChecksum `json:"checksums,omitempty"`
 }
 
 // FullName returns the type:name identifier for the item.
 func (i *Item) FullName() string {
-	return fmt.Sprintf("%s:%s", i.Type, i.Name)
+	return refs.Join(i.Type, i.Name)
 }
 
 // ItemType returns the parsed ItemType.
@@ -135,13 +157,73 @@ func (i *Item) ItemType() ItemType {
 	return ItemType(i.Type)
 }
 
+// Deprecated reports whether the item is marked deprecated.
+func (i *Item) Deprecated() bool {
+	return i.Status == string(StatusDeprecated)
+}
+
+// DefaultVariant names an item's own body when selecting a variant.
+const DefaultVariant = "default"
+
+// VariantNames returns the names of the item's variants, sorted.
+func (i *Item) VariantNames() []string {
+	names := make([]string, 0, len(i.Variants))
+	for name := range i.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NoLicense is the license pattern matching items that declare no license.
+const NoLicense = "none"
+
+// MatchLicense reports whether the item's license matches pattern, a glob
+// such as GPL*. Licenses are compared ignoring case, like SPDX identifiers;
+// NoLicense matches items without one.
+func (i *Item) MatchLicense(pattern string) bool {
+	if strings.EqualFold(pattern, NoLicense) {
+		return i.License == ""
+	}
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(i.License))
+	return ok && i.License != ""
+}
+
+// FileMode returns the permission bits for the installed file.
+// An explicit mode (octal, e.g. "0755") wins; scripts default to executable.
+func (i *Item) FileMode() (os.FileMode, error) {
+	if i.Mode == "" {
+		if i.Type == string(TypeScript) {
+			return 0755, nil
+		}
+		return 0644, nil
+	}
+
+	mode, err := strconv.ParseUint(i.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid mode '%s' (expected octal permissions like 0644 or 0755)", i.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
 // Manifest represents the built registry index.
 type Manifest struct {
 	Version      string           `json:"version"`
 	Generated    time.Time        `json:"generated"`
 	RegistryPath string           `json:"registry_path"`
 	Items        map[string]*Item `json:"items"`
+	Tombstones   []Tombstone      `json:"tombstones,omitempty"`
+	Filter       *Filter          `json:"filter,omitempty"`
 	Stats        Stats            `json:"stats"`
+	Health       *Health          `json:"health,omitempty"`
+
+	// Quarantine lists the items left out of a manifest built with
+	// AllowErrors because of validation errors.
+	Quarantine []Quarantined `json:"quarantine,omitempty"`
+
+	// Hash is the SHA256 of the manifest file the manifest was loaded
+	// from, or empty for manifests built in memory.
+	Hash string `json:"-"`
 }
 
 // NewManifest creates a new empty manifest.
@@ -212,6 +294,25 @@ type Stats struct {
 	Stacks       int `json:"stacks"`
 	Hooks        int `json:"hooks"`
 	Prompts      int `json:"prompts"`
+
+	// Excluded counts markdown files left out by the build filter.
+	Excluded int `json:"excluded,omitempty"`
+}
+
+// ByType returns the non-zero counts keyed by item type.
+func (s Stats) ByType() map[string]int {
+	counts := make(map[string]int)
+	for itemType, n := range map[ItemType]int{
+		TypeSkill: s.Skills, TypeSubagent: s.Subagents, TypeCommand: s.Commands,
+		TypeMCP: s.MCPs, TypeScript: s.Scripts, TypeDoc: s.Docs,
+		TypeProject: s.Projects, TypePhilosophy: s.Philosophies, TypeRuleset: s.Rulesets,
+		TypeStack: s.Stacks, TypeHook: s.Hooks, TypePrompt: s.Prompts,
+	} {
+		if n > 0 {
+			counts[string(itemType)] = n
+		}
+	}
+	return counts
 }
 
 // Total returns the total number of items.
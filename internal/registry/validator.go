@@ -1,10 +1,15 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/okto-digital/regis3/internal/pathutil"
 )
 
 // Severity indicates the severity of a validation issue.
@@ -35,6 +40,11 @@ type ValidationIssue struct {
 	Path     string
 	Field    string
 	Message  string
+
+	// Line and Column locate the issue in the file; zero until set by
+	// Locate.
+	Line   int
+	Column int
 }
 
 func (v ValidationIssue) String() string {
@@ -111,10 +121,50 @@ func (r *ValidationResult) AddInfo(path, field, message string) {
 	})
 }
 
+// Locate sets the line and column of the issues that have none, from the
+// position of their field in the file's frontmatter: the field's key, or the
+// metadata block when the field is missing. Content issues point at the
+// start of the body. Only files with issues are read.
+func (r *ValidationResult) Locate(registryRoot string, dialect Dialect) {
+	files := make(map[string][]byte)
+	for i := range r.Issues {
+		issue := &r.Issues[i]
+		if issue.Line > 0 || issue.Path == "" {
+			continue
+		}
+		content, ok := files[issue.Path]
+		if !ok {
+			if path, err := pathutil.Join(registryRoot, issue.Path); err == nil {
+				content, _ = os.ReadFile(path)
+			}
+			files[issue.Path] = content
+		}
+		pos := dialect.Locate(content, issue.Field)
+		issue.Line, issue.Column = pos.Line, pos.Column
+	}
+}
+
 // Validator validates registry items.
 type Validator struct {
 	// RegistryRoot is the path to the registry root directory.
 	RegistryRoot string
+
+	// DependencyRules restricts which types each item type may depend on.
+	DependencyRules DependencyRules
+
+	// Lint selects the content consistency checks to run.
+	Lint LintConfig
+
+	// SizeBudgets limits content length per item type.
+	SizeBudgets SizeBudgets
+
+	// Partial indicates the items are a filtered subset of the registry, so
+	// references to items outside it are warnings rather than errors.
+	Partial bool
+
+	// RequireLicense lists registry paths (globs, like Filter patterns)
+	// whose items must declare a license.
+	RequireLicense []string
 }
 
 // NewValidator creates a new validator.
@@ -124,14 +174,28 @@ func NewValidator(registryRoot string) *Validator {
 
 // ValidateItems validates a list of items and checks for cross-item issues.
 func (v *Validator) ValidateItems(items []*Item) *ValidationResult {
+	return v.ValidateChanged(items, items)
+}
+
+// ValidateChanged validates only the changed items individually, but checks
+// cross-item issues (duplicates, dependencies) across all items. Changed
+// items must be part of items.
+func (v *Validator) ValidateChanged(items, changed []*Item) *ValidationResult {
 	result := &ValidationResult{}
 
+	isChanged := make(map[*Item]bool, len(changed))
+	for _, item := range changed {
+		isChanged[item] = true
+	}
+
 	// Track seen names for uniqueness check
 	seen := make(map[string]string) // fullName -> source path
 
 	for _, item := range items {
 		// Validate individual item
-		v.validateItem(item, result)
+		if isChanged[item] {
+			v.validateItem(item, result)
+		}
 
 		// Check for duplicate names
 		fullName := item.FullName()
@@ -142,12 +206,56 @@ func (v *Validator) ValidateItems(items []*Item) *ValidationResult {
 		}
 	}
 
+	// Aliases must not shadow an item or another item's alias
+	aliases := make(map[string]string) // type:alias -> full name
+	for _, item := range items {
+		for _, ref := range item.AliasRefs() {
+			if ref == item.FullName() {
+				continue
+			}
+			if existingPath, exists := seen[ref]; exists {
+				result.AddError(item.Source, "aliases", fmt.Sprintf("alias '%s' collides with the item defined in %s", ref, existingPath))
+			} else if other, exists := aliases[ref]; exists && other != item.FullName() {
+				result.AddError(item.Source, "aliases", fmt.Sprintf("alias '%s' is also an alias of %s", ref, other))
+			} else {
+				aliases[ref] = item.FullName()
+			}
+		}
+	}
+
+	// Merge items sharing an order have no defined order among each other
+	v.validateMergeOrder(items, result)
+
 	// Validate dependencies exist
-	v.validateDependencies(items, seen, result)
+	v.validateDependencies(items, seen, aliases, result)
 
 	return result
 }
 
+// validateMergeOrder warns about merge items of the same type that share an
+// order value. Items without an order are already warned about individually.
+func (v *Validator) validateMergeOrder(items []*Item, result *ValidationResult) {
+	sorted := make([]*Item, 0, len(items))
+	for _, item := range items {
+		if ItemType(item.Type).IsMergeType() && item.Order != 0 {
+			sorted = append(sorted, item)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Source < sorted[j].Source
+	})
+
+	orders := make(map[string]*Item) // type:order -> first item
+	for _, item := range sorted {
+		key := fmt.Sprintf("%s:%d", item.Type, item.Order)
+		if other, exists := orders[key]; exists {
+			result.AddWarning(item.Source, "order", fmt.Sprintf("order %d is also used by %s (%s); merged by name", item.Order, other.FullName(), other.Source))
+			continue
+		}
+		orders[key] = item
+	}
+}
+
 // validateItem validates a single item.
 func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 	// Required: type
@@ -160,6 +268,9 @@ func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 	// Required: name
 	if item.Name == "" {
 		result.AddError(item.Source, "name", "required field is missing")
+	} else if err := pathutil.CheckName(item.Name); err != nil {
+		// Names become file names on install
+		result.AddError(item.Source, "name", err.Error())
 	} else {
 		// Validate name format (kebab-case)
 		if !isKebabCase(item.Name) {
@@ -167,6 +278,23 @@ func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 		}
 	}
 
+	// Aliases keep renamed items addressable and follow the same rules as names
+	for i, alias := range item.Aliases {
+		switch {
+		case alias == item.Name:
+			result.AddWarning(item.Source, "aliases", fmt.Sprintf("alias '%s' is the item's own name", alias))
+		case pathutil.CheckName(alias) != nil:
+			result.AddError(item.Source, "aliases", pathutil.CheckName(alias).Error())
+		case !isKebabCase(alias):
+			result.AddWarning(item.Source, "aliases", fmt.Sprintf("alias '%s' should be kebab-case (lowercase with hyphens)", alias))
+		}
+		for _, prev := range item.Aliases[:i] {
+			if prev == alias {
+				result.AddWarning(item.Source, "aliases", fmt.Sprintf("alias '%s' is listed twice", alias))
+			}
+		}
+	}
+
 	// Required: desc
 	if item.Desc == "" {
 		result.AddError(item.Source, "desc", "required field is missing")
@@ -180,11 +308,46 @@ func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 
 	// Validate files exist (if specified)
 	for _, file := range item.Files {
-		filePath := filepath.Join(v.RegistryRoot, item.SourceDir, file)
+		filePath, err := pathutil.Join(v.RegistryRoot, item.SourceDir, file)
+		if err != nil {
+			result.AddError(item.Source, "files", err.Error())
+			continue
+		}
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
 			result.AddError(item.Source, "files", fmt.Sprintf("referenced file does not exist: %s", file))
 		}
 	}
+	if _, err := item.ExpandFiles(v.RegistryRoot); err != nil && !errors.Is(err, pathutil.ErrUnsafePath) {
+		result.AddError(item.Source, "files", err.Error())
+	}
+
+	// Variants replace the body with another file's
+	if item.Type == string(TypeStack) && len(item.Variants) > 0 {
+		result.AddWarning(item.Source, "variants", "stacks have no content, variants are ignored")
+	}
+	for _, name := range item.VariantNames() {
+		file := item.Variants[name]
+		if name == DefaultVariant {
+			result.AddError(item.Source, "variants", fmt.Sprintf("variant name %q is reserved for the item's own body", name))
+			continue
+		}
+		variantPath, err := pathutil.Join(v.RegistryRoot, item.SourceDir, file)
+		if err != nil {
+			result.AddError(item.Source, "variants", err.Error())
+		} else if _, err := os.Stat(variantPath); os.IsNotExist(err) {
+			result.AddError(item.Source, "variants", fmt.Sprintf("variant %s file does not exist: %s", name, file))
+		}
+	}
+
+	// Setup scripts run from the registry after install
+	if item.Setup != "" {
+		scriptPath, err := pathutil.Join(v.RegistryRoot, item.SourceDir, item.Setup)
+		if err != nil {
+			result.AddError(item.Source, "setup", err.Error())
+		} else if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+			result.AddError(item.Source, "setup", fmt.Sprintf("setup script does not exist: %s", item.Setup))
+		}
+	}
 
 	// Warn if no tags
 	if len(item.Tags) == 0 {
@@ -206,6 +369,19 @@ func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 		}
 	}
 
+	// Items in some directories, e.g. imported collections, must name
+	// their license
+	if item.License == "" {
+		if pattern, ok := v.licensePattern(item.Source); ok {
+			result.AddError(item.Source, "license", fmt.Sprintf("license is required for items in %s", pattern))
+		}
+	}
+
+	// A replacement only makes sense for deprecated items
+	if item.ReplacedBy != "" && !item.Deprecated() {
+		result.AddWarning(item.Source, "replaced_by", "replaced_by is only used on deprecated items")
+	}
+
 	// Validate order for merge types
 	if ItemType(item.Type).IsMergeType() && item.Order == 0 {
 		result.AddWarning(item.Source, "order", "merge type without order specified (will use default ordering)")
@@ -221,23 +397,143 @@ func (v *Validator) validateItem(item *Item, result *ValidationResult) {
 		}
 	}
 
+	// Mode must be valid octal permissions
+	if _, err := item.FileMode(); err != nil {
+		result.AddError(item.Source, "mode", err.Error())
+	}
+
+	// Scripts are installed as executables and need an interpreter line
+	if item.Type == string(TypeScript) && !strings.HasPrefix(strings.TrimLeft(item.Content, "\r\n"), "#!") {
+		result.AddWarning(item.Source, "content", "script has no shebang line (e.g. #!/usr/bin/env bash)")
+	}
+
+	// Provided capabilities must use the capability: prefix
+	for _, capability := range item.Provides {
+		if !IsCapability(capability) || capability == CapabilityPrefix {
+			result.AddError(item.Source, "provides", fmt.Sprintf("invalid capability '%s' (expected %s<name>)", capability, CapabilityPrefix))
+		}
+	}
+
+	// Alternatives are only meaningful on stacks
+	if len(item.OneOf) > 0 {
+		if item.Type != string(TypeStack) {
+			result.AddWarning(item.Source, "one_of", "alternatives are only used on stack items")
+		} else if len(item.OneOf) == 1 {
+			result.AddWarning(item.Source, "one_of", "only one alternative listed (use deps instead)")
+		}
+	}
+
+	// Dynamic dependencies are only resolved for stacks
+	if item.DepsQuery != "" {
+		if item.Type != string(TypeStack) {
+			result.AddWarning(item.Source, "deps_query", "deps_query is only used on stack items")
+		} else if _, err := ParseQuery(item.DepsQuery); err != nil {
+			result.AddError(item.Source, "deps_query", err.Error())
+		}
+	}
+
 	// Stack type should have dependencies
-	if item.Type == string(TypeStack) && len(item.Deps) == 0 {
+	if item.Type == string(TypeStack) && len(item.Deps) == 0 && len(item.OneOf) == 0 && item.DepsQuery == "" {
 		result.AddWarning(item.Source, "deps", "stack type should have dependencies")
 	}
+
+	v.SizeBudgets.check(item, result)
+	v.lintItem(item, result)
 }
 
-// validateDependencies checks that all referenced dependencies exist.
-func (v *Validator) validateDependencies(items []*Item, seen map[string]string, result *ValidationResult) {
+// validateDependencies checks that all referenced dependencies and
+// replacements exist. Capability dependencies must be provided by at least
+// one item.
+func (v *Validator) validateDependencies(items []*Item, seen, aliases map[string]string, result *ValidationResult) {
+	providers := make(map[string][]string) // capability -> provider full names
+	for _, item := range items {
+		for _, capability := range item.Provides {
+			providers[capability] = append(providers[capability], item.FullName())
+		}
+	}
+
 	for _, item := range items {
 		for _, dep := range item.Deps {
-			if _, exists := seen[dep]; !exists {
-				result.AddError(item.Source, "deps", fmt.Sprintf("dependency not found: %s", dep))
+			v.validateReference(item, "deps", dep, seen, aliases, providers, result)
+		}
+		for _, alt := range item.OneOf {
+			v.validateReference(item, "one_of", alt, seen, aliases, providers, result)
+		}
+		if item.DepsQuery != "" && item.Type == string(TypeStack) {
+			v.validateDepsQuery(item, items, result)
+		}
+		if item.ReplacedBy != "" {
+			ref := item.ReplacedBy
+			if target, ok := aliases[ref]; ok {
+				ref = target
+			}
+			if _, exists := seen[ref]; !exists {
+				v.addMissing(item, "replaced_by", fmt.Sprintf("replacement not found: %s", item.ReplacedBy), result)
 			}
 		}
 	}
 }
 
+// validateDepsQuery checks the items a dynamic stack's query matches: it
+// should match something, and the matches must follow the dependency rules.
+func (v *Validator) validateDepsQuery(item *Item, items []*Item, result *ValidationResult) {
+	q, err := ParseQuery(item.DepsQuery)
+	if err != nil {
+		return // reported with the item's fields
+	}
+	matched := 0
+	for _, other := range items {
+		if other == item || !q.Matches(other) {
+			continue
+		}
+		matched++
+		if err := v.DependencyRules.Check(item.Type, other.FullName()); err != nil {
+			result.AddError(item.Source, "deps_query", err.Error())
+		}
+	}
+	if matched == 0 {
+		result.AddWarning(item.Source, "deps_query", fmt.Sprintf("deps_query %q matches no items", item.DepsQuery))
+	}
+}
+
+// validateReference checks a single dependency reference of an item.
+// References to an alias resolve, but are deprecated.
+func (v *Validator) validateReference(item *Item, field, ref string, seen, aliases map[string]string, providers map[string][]string, result *ValidationResult) {
+	if IsCapability(ref) {
+		if len(providers[ref]) == 0 {
+			v.addMissing(item, field, fmt.Sprintf("no item provides capability: %s", ref), result)
+			return
+		}
+		for _, provider := range providers[ref] {
+			if err := v.DependencyRules.Check(item.Type, provider); err != nil {
+				result.AddError(item.Source, field, err.Error())
+			}
+		}
+		return
+	}
+	if target, ok := aliases[ref]; ok {
+		result.AddWarning(item.Source, field, fmt.Sprintf("%s is a deprecated alias for %s", ref, target))
+		ref = target
+	}
+	if _, exists := seen[ref]; !exists {
+		v.addMissing(item, field, fmt.Sprintf("dependency not found: %s", ref), result)
+		return
+	}
+	if err := v.DependencyRules.Check(item.Type, ref); err != nil {
+		result.AddError(item.Source, field, err.Error())
+	}
+}
+
+// addMissing reports an unresolved reference; it is only a warning in a
+// partial build, where the target may simply be filtered out.
+func (v *Validator) addMissing(item *Item, field, message string, result *ValidationResult) {
+	if v.Partial {
+		result.AddWarning(item.Source, field, message+" (not in this filtered build)")
+		return
+	}
+	result.AddError(item.Source, field, message)
+}
+
 // ValidateItem validates a single item (for use during scanning).
 func (v *Validator) ValidateItem(item *Item) *ValidationResult {
 	result := &ValidationResult{}
@@ -274,3 +570,16 @@ func isKebabCase(s string) bool {
 	}
 	return true
 }
+
+// licensePattern returns the RequireLicense pattern matching the item file
+// at source or one of its directories.
+func (v *Validator) licensePattern(source string) (string, bool) {
+	for dir := filepath.ToSlash(source); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		for _, p := range v.RequireLicense {
+			if MatchGlob(p, dir) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
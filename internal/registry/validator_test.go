@@ -1,6 +1,9 @@
 package registry
 
 import (
+	"os"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -175,6 +178,34 @@ func TestValidator_DuplicateNames(t *testing.T) {
 	assert.Equal(t, "second.md", errors[0].Path)
 }
 
+func TestValidator_MergeOrderCollisions(t *testing.T) {
+	v := NewValidator(".")
+
+	item := func(itemType, name string, order int) *Item {
+		return &Item{
+			Regis3Meta: Regis3Meta{Type: itemType, Name: name, Desc: "A merged item", Tags: []string{"test"}, Order: order},
+			Source:     itemType + "/" + name + ".md",
+		}
+	}
+	items := []*Item{
+		item("philosophy", "kiss", 10),
+		item("philosophy", "clean-code", 10),
+		item("philosophy", "testing", 20),
+		item("ruleset", "style", 10),
+	}
+
+	var orderWarnings []ValidationIssue
+	for _, w := range v.ValidateItems(items).Warnings() {
+		if w.Field == "order" {
+			orderWarnings = append(orderWarnings, w)
+		}
+	}
+
+	require.Len(t, orderWarnings, 1)
+	assert.Equal(t, "philosophy/kiss.md", orderWarnings[0].Path)
+	assert.Contains(t, orderWarnings[0].Message, "philosophy:clean-code")
+}
+
 func TestValidator_DependencyNotFound(t *testing.T) {
 	v := NewValidator(".")
 
@@ -425,3 +456,600 @@ func TestValidator_WithSampleRegistry(t *testing.T) {
 	errors := valResult.Errors()
 	assert.Empty(t, errors, "sample registry should have no validation errors: %v", errors)
 }
+
+func TestValidator_DependencyRules(t *testing.T) {
+	items := []*Item{
+		{
+			Regis3Meta: Regis3Meta{
+				Type: "stack",
+				Name: "base",
+				Desc: "Base stack",
+				Deps: []string{"skill:git"},
+				Tags: []string{"test"},
+			},
+			Source: "base.md",
+		},
+		{
+			Regis3Meta: Regis3Meta{
+				Type: "skill",
+				Name: "git",
+				Desc: "Git skill",
+				Tags: []string{"test"},
+			},
+			Source: "git.md",
+		},
+		{
+			Regis3Meta: Regis3Meta{
+				Type: "skill",
+				Name: "bad",
+				Desc: "Depends on a stack",
+				Deps: []string{"stack:base", "skill:git"},
+				Tags: []string{"test"},
+			},
+			Source: "bad.md",
+		},
+	}
+
+	t.Run("no rules allows everything", func(t *testing.T) {
+		v := NewValidator(".")
+		assert.Empty(t, v.ValidateItems(items).Errors())
+	})
+
+	t.Run("rules reject disallowed types", func(t *testing.T) {
+		v := NewValidator(".")
+		v.DependencyRules = DependencyRules{
+			"skill": {"skill", "doc"},
+		}
+
+		errors := v.ValidateItems(items).Errors()
+		require.Len(t, errors, 1)
+		assert.Equal(t, "bad.md", errors[0].Path)
+		assert.Equal(t, "deps", errors[0].Field)
+		assert.Contains(t, errors[0].Message, "skill may not depend on stack")
+		assert.Contains(t, errors[0].Message, "skill, doc")
+	})
+}
+
+func TestDependencyRules_Allows(t *testing.T) {
+	rules := DependencyRules{
+		"skill":    {"skill"},
+		"subagent": {},
+	}
+
+	assert.True(t, rules.Allows("skill", "skill"))
+	assert.False(t, rules.Allows("skill", "stack"))
+	assert.False(t, rules.Allows("subagent", "skill"))
+	assert.True(t, rules.Allows("stack", "skill"))
+	assert.NoError(t, rules.Check("skill", "malformed"))
+}
+
+func TestValidator_Capabilities(t *testing.T) {
+	items := []*Item{
+		{
+			Regis3Meta: Regis3Meta{
+				Type:     "skill",
+				Name:     "git-flow",
+				Desc:     "Git flow workflow",
+				Provides: []string{"capability:git-workflow"},
+				Tags:     []string{"test"},
+			},
+			Source: "git-flow.md",
+		},
+		{
+			Regis3Meta: Regis3Meta{
+				Type:     "skill",
+				Name:     "bad-provides",
+				Desc:     "Provides without prefix",
+				Provides: []string{"git-workflow"},
+				Tags:     []string{"test"},
+			},
+			Source: "bad-provides.md",
+		},
+		{
+			Regis3Meta: Regis3Meta{
+				Type: "stack",
+				Name: "team",
+				Desc: "Team stack",
+				Deps: []string{"capability:git-workflow", "capability:unknown"},
+				Tags: []string{"test"},
+			},
+			Source: "team.md",
+		},
+	}
+
+	v := NewValidator(".")
+	errors := v.ValidateItems(items).Errors()
+	require.Len(t, errors, 2)
+	assert.Equal(t, "bad-provides.md", errors[0].Path)
+	assert.Equal(t, "provides", errors[0].Field)
+	assert.Equal(t, "team.md", errors[1].Path)
+	assert.Contains(t, errors[1].Message, "no item provides capability: capability:unknown")
+
+	t.Run("rules apply to providers", func(t *testing.T) {
+		v := NewValidator(".")
+		v.DependencyRules = DependencyRules{"stack": {"philosophy"}}
+		errors := v.ValidateItems(items).Errors()
+		require.Len(t, errors, 3)
+		assert.Contains(t, errors[1].Message, "stack may not depend on skill")
+	})
+}
+
+func TestValidator_Alternatives(t *testing.T) {
+	items := []*Item{
+		{
+			Regis3Meta: Regis3Meta{Type: "skill", Name: "jest", Desc: "Jest testing skill", Tags: []string{"test"}},
+			Source:     "jest.md",
+		},
+		{
+			Regis3Meta: Regis3Meta{
+				Type:  "stack",
+				Name:  "web",
+				Desc:  "Web stack with alternatives",
+				OneOf: []string{"skill:jest", "skill:vitest"},
+				Tags:  []string{"test"},
+			},
+			Source: "web.md",
+		},
+	}
+
+	v := NewValidator(".")
+	result := v.ValidateItems(items)
+
+	errors := result.Errors()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "one_of", errors[0].Field)
+	assert.Contains(t, errors[0].Message, "skill:vitest")
+
+	for _, w := range result.Warnings() {
+		assert.NotEqual(t, "deps", w.Field, "stack with alternatives should not warn about deps")
+	}
+}
+
+func TestValidator_ScriptsAndModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		item        *Item
+		wantError   string
+		wantWarning string
+	}{
+		{
+			name: "script with shebang",
+			item: &Item{
+				Regis3Meta: Regis3Meta{Type: "script", Name: "setup", Desc: "Sets up the project", Tags: []string{"test"}},
+				Content:    "\n#!/usr/bin/env bash\necho hi\n",
+			},
+		},
+		{
+			name: "script without shebang",
+			item: &Item{
+				Regis3Meta: Regis3Meta{Type: "script", Name: "setup", Desc: "Sets up the project", Tags: []string{"test"}},
+				Content:    "echo hi\n",
+			},
+			wantWarning: "content",
+		},
+		{
+			name: "invalid mode",
+			item: &Item{
+				Regis3Meta: Regis3Meta{Type: "doc", Name: "notes", Desc: "Notes for the team", Tags: []string{"test"}, Mode: "rwx"},
+			},
+			wantError: "mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator(".").ValidateItem(tt.item)
+
+			if tt.wantError == "" {
+				assert.Empty(t, result.Errors())
+			} else {
+				require.Len(t, result.Errors(), 1)
+				assert.Equal(t, tt.wantError, result.Errors()[0].Field)
+			}
+
+			found := false
+			for _, w := range result.Warnings() {
+				if w.Field == "content" {
+					found = true
+				}
+			}
+			assert.Equal(t, tt.wantWarning == "content", found)
+		})
+	}
+}
+
+func TestItem_FileMode(t *testing.T) {
+	tests := []struct {
+		itemType string
+		mode     string
+		want     os.FileMode
+		wantErr  bool
+	}{
+		{"doc", "", 0644, false},
+		{"script", "", 0755, false},
+		{"script", "0700", 0700, false},
+		{"doc", "600", 0600, false},
+		{"doc", "0999", 0, true},
+		{"doc", "01777", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.itemType+"/"+tt.mode, func(t *testing.T) {
+			item := &Item{Regis3Meta: Regis3Meta{Type: tt.itemType, Mode: tt.mode}}
+			got, err := item.FileMode()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidator_UnsafePaths(t *testing.T) {
+	v := NewValidator(".")
+
+	item := &Item{
+		Regis3Meta: Regis3Meta{
+			Type:  "skill",
+			Name:  "../escape",
+			Desc:  "Tries to escape the project",
+			Files: []string{"../../.ssh/authorized_keys", "/etc/passwd"},
+			Tags:  []string{"test"},
+		},
+		Source: "escape.md",
+	}
+
+	errors := v.ValidateItem(item).Errors()
+	require.Len(t, errors, 3)
+	assert.Equal(t, "name", errors[0].Field)
+	assert.Equal(t, "files", errors[1].Field)
+	assert.Equal(t, "files", errors[2].Field)
+	for _, e := range errors {
+		assert.Contains(t, e.Message, "unsafe path")
+	}
+}
+
+func TestValidator_Aliases(t *testing.T) {
+	item := func(itemType, name, source string, aliases []string, deps ...string) *Item {
+		return &Item{
+			Regis3Meta: Regis3Meta{Type: itemType, Name: name, Desc: "An item for testing aliases", Aliases: aliases, Deps: deps, Tags: []string{"test"}},
+			Source:     source,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		items        []*Item
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "dependency on an alias is deprecated",
+			items: []*Item{
+				item("skill", "git-conventions", "git.md", []string{"git-flow"}),
+				item("skill", "app", "app.md", nil, "skill:git-flow"),
+			},
+			wantWarnings: []string{"skill:git-flow is a deprecated alias for skill:git-conventions"},
+		},
+		{
+			name: "alias collides with an item",
+			items: []*Item{
+				item("skill", "git-conventions", "git.md", []string{"git-flow"}),
+				item("skill", "git-flow", "flow.md", nil),
+			},
+			wantErrors: []string{"alias 'skill:git-flow' collides with the item defined in flow.md"},
+		},
+		{
+			name: "alias shared by two items",
+			items: []*Item{
+				item("skill", "git-conventions", "git.md", []string{"git-flow"}),
+				item("skill", "trunk", "trunk.md", []string{"git-flow"}),
+			},
+			wantErrors: []string{"alias 'skill:git-flow' is also an alias of skill:git-conventions"},
+		},
+		{
+			name: "aliases are scoped by type",
+			items: []*Item{
+				item("skill", "git-conventions", "git.md", []string{"git-flow"}),
+				item("subagent", "git-flow", "flow.md", nil),
+			},
+		},
+		{
+			name: "alias must be a valid name",
+			items: []*Item{
+				item("skill", "git-conventions", "git.md", []string{"../git"}),
+			},
+			wantErrors: []string{"../git"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator(t.TempDir()).ValidateItems(tt.items)
+
+			errors := result.Errors()
+			require.Len(t, errors, len(tt.wantErrors), "%v", errors)
+			for i, want := range tt.wantErrors {
+				assert.Contains(t, errors[i].Message, want)
+			}
+			var aliasWarnings []string
+			for _, w := range result.Warnings() {
+				if strings.Contains(w.Message, "alias") {
+					aliasWarnings = append(aliasWarnings, w.Message)
+				}
+			}
+			assert.Equal(t, len(tt.wantWarnings), len(aliasWarnings), "%v", aliasWarnings)
+			for i, want := range tt.wantWarnings {
+				assert.Contains(t, aliasWarnings[i], want)
+			}
+		})
+	}
+}
+
+func TestValidator_ReplacedBy(t *testing.T) {
+	item := func(name, status, replacedBy string) *Item {
+		return &Item{
+			Regis3Meta: Regis3Meta{Type: "skill", Name: name, Desc: "An item for testing replacements", Status: status, ReplacedBy: replacedBy, Tags: []string{"test"}},
+			Source:     name + ".md",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		items       []*Item
+		wantError   string
+		wantWarning string
+	}{
+		{
+			name:  "deprecated item with replacement",
+			items: []*Item{item("old", "deprecated", "skill:new"), item("new", "", "")},
+		},
+		{
+			name:  "deprecated item without replacement",
+			items: []*Item{item("old", "deprecated", "")},
+		},
+		{
+			name:      "replacement not found",
+			items:     []*Item{item("old", "deprecated", "skill:missing")},
+			wantError: "replacement not found: skill:missing",
+		},
+		{
+			name:        "replacement on an item that isn't deprecated",
+			items:       []*Item{item("old", "", "skill:new"), item("new", "", "")},
+			wantWarning: "replaced_by is only used on deprecated items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator(t.TempDir()).ValidateItems(tt.items)
+
+			errors := result.Errors()
+			if tt.wantError == "" {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1, "%v", errors)
+				assert.Contains(t, errors[0].Message, tt.wantError)
+			}
+			var warnings []string
+			for _, w := range result.Warnings() {
+				if w.Field == "replaced_by" {
+					warnings = append(warnings, w.Message)
+				}
+			}
+			if tt.wantWarning == "" {
+				assert.Empty(t, warnings)
+			} else {
+				require.Len(t, warnings, 1)
+				assert.Contains(t, warnings[0], tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidator_RequireLicense(t *testing.T) {
+	item := func(source, license string) *Item {
+		return &Item{
+			Regis3Meta: Regis3Meta{Type: "skill", Name: strings.TrimSuffix(path.Base(source), ".md"), Desc: "An item for testing licenses", License: license, Tags: []string{"test"}},
+			Source:     source,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		require   []string
+		item      *Item
+		wantError string
+	}{
+		{
+			name: "no requirement",
+			item: item("imported/prompts/a.md", ""),
+		},
+		{
+			name:      "directory glob",
+			require:   []string{"imported/**"},
+			item:      item("imported/prompts/a.md", ""),
+			wantError: "license is required for items in imported/**",
+		},
+		{
+			name:      "directory name at any depth",
+			require:   []string{"vendor"},
+			item:      item("skills/vendor/a.md", ""),
+			wantError: "license is required for items in vendor",
+		},
+		{
+			name:    "license declared",
+			require: []string{"imported/**"},
+			item:    item("imported/prompts/a.md", "MIT"),
+		},
+		{
+			name:    "outside the directories",
+			require: []string{"imported/**"},
+			item:    item("skills/a.md", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewValidator(t.TempDir())
+			validator.RequireLicense = tt.require
+			errors := validator.ValidateItems([]*Item{tt.item}).Errors()
+			if tt.wantError == "" {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1, "%v", errors)
+				assert.Equal(t, "license", errors[0].Field)
+				assert.Equal(t, tt.wantError, errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestValidator_DepsQuery(t *testing.T) {
+	item := func(itemType, name, query string, tags ...string) *Item {
+		return &Item{
+			Regis3Meta: Regis3Meta{Type: itemType, Name: name, Desc: "An item for testing queries", DepsQuery: query, Tags: append([]string{"test"}, tags...)},
+			Source:     name + ".md",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		items       []*Item
+		wantError   string
+		wantWarning string
+	}{
+		{
+			name:  "query matching items",
+			items: []*Item{item("stack", "go", "type=skill and tag=golang"), item("skill", "go-style", "", "golang")},
+		},
+		{
+			name:      "invalid query",
+			items:     []*Item{item("stack", "go", "language=go")},
+			wantError: "unknown field",
+		},
+		{
+			name:        "query matching nothing",
+			items:       []*Item{item("stack", "go", "tag=golang")},
+			wantWarning: "matches no items",
+		},
+		{
+			name:        "query on an item that isn't a stack",
+			items:       []*Item{item("skill", "go", "tag=golang"), item("skill", "go-style", "", "golang")},
+			wantWarning: "only used on stack items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator(t.TempDir()).ValidateItems(tt.items)
+
+			errors := result.Errors()
+			if tt.wantError == "" {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1, "%v", errors)
+				assert.Contains(t, errors[0].Message, tt.wantError)
+			}
+			var warnings []string
+			for _, w := range result.Warnings() {
+				if w.Field == "deps_query" {
+					warnings = append(warnings, w.Message)
+				}
+			}
+			if tt.wantWarning == "" {
+				assert.Empty(t, warnings)
+			} else {
+				require.Len(t, warnings, 1)
+				assert.Contains(t, warnings[0], tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidator_SetupScript(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(tmpDir+"/mcp/scripts", 0755))
+	require.NoError(t, os.WriteFile(tmpDir+"/mcp/scripts/setup.sh", []byte("true\n"), 0644))
+
+	tests := []struct {
+		setup   string
+		wantErr string
+	}{
+		{setup: "scripts/setup.sh"},
+		{setup: "scripts/missing.sh", wantErr: "setup script does not exist"},
+		{setup: "../../outside.sh", wantErr: "unsafe path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setup, func(t *testing.T) {
+			item := &Item{
+				Regis3Meta: Regis3Meta{Type: "mcp", Name: "github", Desc: "GitHub server for the project", Setup: tt.setup, Tags: []string{"test"}},
+				Source:     "mcp/github.md",
+				SourceDir:  "mcp",
+			}
+
+			errors := NewValidator(tmpDir).ValidateItem(item).Errors()
+			if tt.wantErr == "" {
+				assert.Empty(t, errors)
+				return
+			}
+			require.Len(t, errors, 1)
+			assert.Equal(t, "setup", errors[0].Field)
+			assert.Contains(t, errors[0].Message, tt.wantErr)
+		})
+	}
+}
+
+func TestValidator_Variants(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(tmpDir+"/skills", 0755))
+	require.NoError(t, os.WriteFile(tmpDir+"/skills/concise.md", []byte("Be brief."), 0644))
+
+	tests := []struct {
+		name     string
+		itemType string
+		variants map[string]string
+		wantErr  string
+		wantWarn string
+	}{
+		{name: "existing file", itemType: "skill", variants: map[string]string{"concise": "concise.md"}},
+		{name: "missing file", itemType: "skill", variants: map[string]string{"detailed": "detailed.md"}, wantErr: "variant detailed file does not exist"},
+		{name: "unsafe path", itemType: "skill", variants: map[string]string{"concise": "../../concise.md"}, wantErr: "unsafe path"},
+		{name: "reserved name", itemType: "skill", variants: map[string]string{"default": "concise.md"}, wantErr: "reserved"},
+		{name: "stack", itemType: "stack", variants: map[string]string{"concise": "concise.md"}, wantWarn: "variants are ignored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &Item{
+				Regis3Meta: Regis3Meta{Type: tt.itemType, Name: "review", Desc: "Review the changes made", Variants: tt.variants, Tags: []string{"test"}},
+				Content:    "# Review",
+				Source:     "skills/review.md",
+				SourceDir:  "skills",
+			}
+			if tt.itemType == "stack" {
+				item.Deps = []string{"skill:other"}
+			}
+
+			result := NewValidator(tmpDir).ValidateItem(item)
+			errors := result.Errors()
+			if tt.wantErr == "" {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1)
+				assert.Equal(t, "variants", errors[0].Field)
+				assert.Contains(t, errors[0].Message, tt.wantErr)
+			}
+			if tt.wantWarn != "" {
+				var warnings []string
+				for _, w := range result.Warnings() {
+					warnings = append(warnings, w.Message)
+				}
+				assert.Contains(t, strings.Join(warnings, "\n"), tt.wantWarn)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package report sends a summary of each installation to a webhook, so
+// platform teams can follow which shared items projects adopt.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/okto-digital/regis3/internal/buildinfo"
+)
+
+// Summary describes an installation. It is posted as JSON.
+type Summary struct {
+	// Command is the command that installed the items (e.g. project add).
+	Command string `json:"command"`
+
+	// Project is the name of the project directory.
+	Project string `json:"project"`
+
+	// User is the name of the user who ran the command.
+	User string `json:"user"`
+
+	// Target is the target the items were installed to.
+	Target string `json:"target"`
+
+	// Installed and Updated are the IDs of the items installed for the
+	// first time and of those updated.
+	Installed []string `json:"installed"`
+	Updated   []string `json:"updated"`
+
+	// Merged are the IDs of the items installed or updated in the target's
+	// merge file (e.g. CLAUDE.md).
+	Merged []string `json:"merged"`
+
+	// Version is the regis3 version.
+	Version string `json:"version"`
+
+	Time time.Time `json:"time"`
+}
+
+// NewSummary returns the summary of an installation in projectDir, with the
+// project, user and version filled in.
+func NewSummary(command, projectDir, target string, installed, updated, merged []string) Summary {
+	project := projectDir
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		project = abs
+	}
+	return Summary{
+		Command:   command,
+		Project:   filepath.Base(project),
+		User:      currentUser(),
+		Target:    target,
+		Installed: nonNil(installed),
+		Updated:   nonNil(updated),
+		Merged:    nonNil(merged),
+		Version:   buildinfo.Version,
+		Time:      time.Now().UTC(),
+	}
+}
+
+// Empty reports whether the installation changed nothing.
+func (s Summary) Empty() bool {
+	return len(s.Installed)+len(s.Updated)+len(s.Merged) == 0
+}
+
+// currentUser returns the name of the user running regis3.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// nonNil returns list, or an empty list if it is nil, so it encodes as [].
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// Client posts summaries to a webhook.
+type Client struct {
+	// URL is the webhook the summaries are posted to.
+	URL string
+
+	// HTTP sends the requests.
+	HTTP *http.Client
+
+	// Attempts is how often a summary is sent before giving up. Network
+	// errors, 429 and 5xx responses are retried; other responses aren't.
+	Attempts int
+
+	// Backoff is the wait before the second attempt; it doubles with
+	// each further attempt.
+	Backoff time.Duration
+}
+
+// NewClient returns a client posting to url, trying three times.
+func NewClient(url string) *Client {
+	return &Client{
+		URL:      url,
+		HTTP:     &http.Client{Timeout: 5 * time.Second},
+		Attempts: 3,
+		Backoff:  time.Second,
+	}
+}
+
+// Send posts the summary, retrying failed attempts.
+func (c *Client) Send(s Summary) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	wait := c.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := c.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.Attempts {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (c *Client) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "regis3")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
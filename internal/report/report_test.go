@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSummary(t *testing.T) {
+	s := NewSummary("project add", "/code/shop", "claude", []string{"skill:testing"}, nil, nil)
+	assert.Equal(t, "shop", s.Project)
+	assert.Equal(t, "claude", s.Target)
+	assert.Equal(t, []string{"skill:testing"}, s.Installed)
+	assert.Equal(t, []string{}, s.Updated)
+	assert.Equal(t, []string{}, s.Merged)
+	assert.NotEmpty(t, s.Version)
+	assert.False(t, s.Time.IsZero())
+	assert.False(t, s.Empty())
+}
+
+func TestSummary_Empty(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		updated   []string
+		merged    []string
+		want      bool
+	}{
+		{name: "nothing changed", want: true},
+		{name: "installed", installed: []string{"skill:testing"}},
+		{name: "updated", updated: []string{"skill:testing"}},
+		{name: "only merged", merged: []string{"philosophy:clean"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSummary("project add", "shop", "claude", tt.installed, tt.updated, tt.merged)
+			assert.Equal(t, tt.want, s.Empty())
+		})
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		requests int32
+	}{
+		{"accepted", []int{http.StatusNoContent}, false, 1},
+		{"retried after server error", []int{http.StatusBadGateway, http.StatusOK}, false, 2},
+		{"retried when throttled", []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}, false, 3},
+		{"gives up after the attempts", []int{http.StatusServiceUnavailable}, true, 3},
+		{"client error is not retried", []int{http.StatusBadRequest}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			var received Summary
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1)) - 1
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses)-1)])
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			client.Backoff = 0
+			err := client.Send(NewSummary("project add", "shop", "claude", []string{"skill:testing"}, []string{"skill:review"}, []string{"philosophy:clean"}))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.requests, requests.Load())
+			assert.Equal(t, []string{"skill:testing"}, received.Installed)
+			assert.Equal(t, []string{"skill:review"}, received.Updated)
+			assert.Equal(t, []string{"philosophy:clean"}, received.Merged)
+		})
+	}
+}
+
+func TestClient_SendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewClient(url)
+	client.Backoff = 0
+	assert.Error(t, client.Send(NewSummary("project add", "shop", "claude", nil, nil, nil)))
+}
@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultGraphFile is the graph cache filename in the registry's build
+// directory.
+const DefaultGraphFile = "graph.json"
+
+// graphCacheVersion changes whenever the cache format does, so caches
+// written by other versions are rebuilt.
+const graphCacheVersion = "1"
+
+// graphCache is the stored dependency graph of a manifest.
+type graphCache struct {
+	// Key identifies the manifest and the options the graph was built with.
+	Key string `json:"key"`
+
+	Nodes []*Node `json:"nodes"`
+
+	// CapabilityErrors maps capabilities without a usable provider to the
+	// reason.
+	CapabilityErrors map[string]string `json:"capability_errors,omitempty"`
+
+	// Order is the topological order of all nodes; Cycle is set instead
+	// if there is none.
+	Order []string `json:"order,omitempty"`
+	Cycle []string `json:"cycle,omitempty"`
+}
+
+// cacheKey returns the cache key for the resolver's manifest and options,
+// or an empty string if the manifest has no hash.
+func (r *Resolver) cacheKey() string {
+	if r.manifest.Hash == "" {
+		return ""
+	}
+
+	capabilities := make([]string, 0, len(r.options.Providers))
+	for capability := range r.options.Providers {
+		capabilities = append(capabilities, capability)
+	}
+	sort.Strings(capabilities)
+
+	h := sha256.New()
+	h.Write([]byte(graphCacheVersion + "\n" + r.manifest.Hash + "\n"))
+	for _, capability := range capabilities {
+		h.Write([]byte(capability + "=" + r.options.Providers[capability] + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadGraph restores the graph from the cache file. It reports false if
+// the cache is missing, unreadable or was built for another manifest.
+func (r *Resolver) loadGraph(path, key string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var cache graphCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Key != key {
+		return false
+	}
+
+	for _, node := range cache.Nodes {
+		r.graph.AddNode(node.ID, node.Type, node.Name, node.Deps)
+	}
+	for capability, msg := range cache.CapabilityErrors {
+		r.capabilityErrors[capability] = errors.New(msg)
+	}
+	if cache.Cycle != nil {
+		r.graph.sorted = &sortResult{err: &CycleError{Cycle: cache.Cycle}}
+	} else {
+		r.graph.sorted = &sortResult{order: cache.Order}
+	}
+	return true
+}
+
+// saveGraph writes the graph and its topological order to the cache file.
+// The cache is an optimization, so failing to write it is not an error.
+func (r *Resolver) saveGraph(path, key string) {
+	cache := graphCache{Key: key}
+	for _, id := range r.graph.Nodes() {
+		node, _ := r.graph.GetNode(id)
+		cache.Nodes = append(cache.Nodes, node)
+	}
+	if len(r.capabilityErrors) > 0 {
+		cache.CapabilityErrors = make(map[string]string, len(r.capabilityErrors))
+		for capability, err := range r.capabilityErrors {
+			cache.CapabilityErrors[capability] = err.Error()
+		}
+	}
+	order, err := r.graph.TopologicalSort()
+	var cycleErr *CycleError
+	if errors.As(err, &cycleErr) {
+		cache.Cycle = cycleErr.Cycle
+	} else {
+		cache.Order = order
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent readers never see a
+	// partial cache
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+}
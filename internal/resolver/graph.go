@@ -2,6 +2,7 @@ package resolver
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -10,14 +11,24 @@ import (
 type Graph struct {
 	nodes map[string]*Node
 	edges map[string][]string // node -> dependencies
+
+	// sorted holds the result of the last topological sort until a node
+	// is added.
+	sorted *sortResult
+}
+
+// sortResult is a memoized topological sort.
+type sortResult struct {
+	order []string
+	err   error
 }
 
 // Node represents an item in the dependency graph.
 type Node struct {
-	ID   string // Full name (e.g., "skill:git-conventions")
-	Type string
-	Name string
-	Deps []string
+	ID   string   `json:"id"` // Full name (e.g., "skill:git-conventions")
+	Type string   `json:"type"`
+	Name string   `json:"name"`
+	Deps []string `json:"deps,omitempty"`
 }
 
 // NewGraph creates an empty dependency graph.
@@ -37,6 +48,7 @@ func (g *Graph) AddNode(id, itemType, name string, deps []string) {
 		Deps: deps,
 	}
 	g.edges[id] = deps
+	g.sorted = nil
 }
 
 // GetNode returns a node by ID.
@@ -75,6 +87,27 @@ func (g *Graph) Dependents(id string) []string {
 	return dependents
 }
 
+// AllDependents returns all nodes that depend on the given node, directly
+// or transitively.
+func (g *Graph) AllDependents(id string) []string {
+	visited := map[string]bool{id: true}
+	queue := []string{id}
+	var result []string
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dependent := range g.Dependents(node) {
+			if !visited[dependent] {
+				visited[dependent] = true
+				result = append(result, dependent)
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 // CycleError represents a circular dependency error.
 type CycleError struct {
 	Cycle []string
@@ -85,8 +118,21 @@ func (e *CycleError) Error() string {
 }
 
 // TopologicalSort returns nodes in dependency order (dependencies first).
-// Returns an error if a cycle is detected.
+// Returns an error if a cycle is detected. The result is reused until the
+// graph changes.
 func (g *Graph) TopologicalSort() ([]string, error) {
+	if g.sorted == nil {
+		order, err := g.sort()
+		g.sorted = &sortResult{order: order, err: err}
+	}
+	if g.sorted.err != nil {
+		return nil, g.sorted.err
+	}
+	return slices.Clone(g.sorted.order), nil
+}
+
+// sort orders the nodes using Kahn's algorithm.
+func (g *Graph) sort() ([]string, error) {
 	// Kahn's algorithm for topological sorting
 	// Also naturally detects cycles
 
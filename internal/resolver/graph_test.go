@@ -65,6 +65,17 @@ func TestGraph_Dependents(t *testing.T) {
 	assert.Empty(t, dependents)
 }
 
+func TestGraph_AllDependents(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("skill:base", "skill", "base", nil)
+	g.AddNode("skill:mid", "skill", "mid", []string{"skill:base"})
+	g.AddNode("stack:top", "stack", "top", []string{"skill:mid", "skill:base"})
+	g.AddNode("skill:other", "skill", "other", nil)
+
+	assert.Equal(t, []string{"skill:mid", "stack:top"}, g.AllDependents("skill:base"))
+	assert.Empty(t, g.AllDependents("skill:other"))
+}
+
 func TestGraph_TopologicalSort_Simple(t *testing.T) {
 	g := NewGraph()
 
@@ -159,6 +170,21 @@ func TestGraph_TopologicalSort_SelfCycle(t *testing.T) {
 	assert.Contains(t, cycleErr.Cycle, "skill:a")
 }
 
+func TestGraph_TopologicalSort_AfterChange(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("skill:a", "skill", "a", nil)
+	g.AddNode("skill:b", "skill", "b", []string{"skill:a"})
+
+	order, err := g.TopologicalSort()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:a", "skill:b"}, order)
+
+	// Adding a node invalidates the previous order
+	g.AddNode("skill:a", "skill", "a", []string{"skill:b"})
+	_, err = g.TopologicalSort()
+	assert.Error(t, err)
+}
+
 func TestGraph_HasCycle(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2,23 +2,67 @@ package resolver
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/okto-digital/regis3/internal/registry"
 )
 
+// Options configures dependency resolution.
+type Options struct {
+	// Providers maps a capability (capability:name) to the item that should
+	// satisfy it when several items provide the same capability.
+	Providers map[string]string
+
+	// Preferred lists items to pick when a stack offers alternatives (one_of).
+	// Earlier entries win.
+	Preferred []string
+
+	// Choose is called to pick an alternative when no preferred item matches.
+	// If nil, the first listed alternative is used.
+	Choose func(stack string, alternatives []string) (string, error)
+
+	// GraphCache, if set, is the file the dependency graph and its
+	// topological order are cached in, keyed by the manifest hash and the
+	// providers. Only manifests loaded from a file are cached.
+	GraphCache string
+}
+
 // Resolver handles dependency resolution for registry items.
 type Resolver struct {
 	graph    *Graph
 	manifest *registry.Manifest
+	options  Options
+
+	// capabilityErrors records capabilities that could not be mapped to a provider.
+	capabilityErrors map[string]error
 }
 
 // NewResolver creates a resolver from a manifest.
 func NewResolver(manifest *registry.Manifest) *Resolver {
+	return NewResolverWithOptions(manifest, Options{})
+}
+
+// NewResolverWithOptions creates a resolver from a manifest with custom options.
+func NewResolverWithOptions(manifest *registry.Manifest, opts Options) *Resolver {
 	r := &Resolver{
-		graph:    NewGraph(),
-		manifest: manifest,
+		graph:            NewGraph(),
+		manifest:         manifest,
+		options:          opts,
+		capabilityErrors: make(map[string]error),
+	}
+
+	key := ""
+	if opts.GraphCache != "" {
+		key = r.cacheKey()
+	}
+	if key != "" && r.loadGraph(opts.GraphCache, key) {
+		return r
 	}
 	r.buildGraph()
+	if key != "" {
+		r.saveGraph(opts.GraphCache, key)
+	}
 	return r
 }
 
@@ -32,17 +76,87 @@ func NewResolverFromItems(items []*registry.Item) *Resolver {
 }
 
 // buildGraph constructs the dependency graph from the manifest.
+// Capability dependencies are replaced by the provider chosen for them.
 func (r *Resolver) buildGraph() {
 	for _, item := range r.manifest.Items {
 		r.graph.AddNode(
 			item.FullName(),
 			item.Type,
 			item.Name,
-			item.Deps,
+			r.mapDeps(r.manifest.ItemDeps(item)),
 		)
 	}
 }
 
+// mapDeps replaces capability references with their providers and aliases
+// with the items they name. Capabilities without a usable provider are kept
+// as-is so they show up as missing.
+func (r *Resolver) mapDeps(deps []string) []string {
+	if len(deps) == 0 {
+		return deps
+	}
+	mapped := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if target, ok := r.manifest.ResolveAlias(dep); ok {
+			dep = target
+		} else if registry.IsCapability(dep) {
+			provider, err := r.Provider(dep)
+			if err != nil {
+				r.capabilityErrors[dep] = err
+			} else if provider != "" {
+				dep = provider
+			}
+		}
+		mapped = append(mapped, dep)
+	}
+	return mapped
+}
+
+// Provider returns the item that satisfies a capability.
+// A configured provider takes precedence; otherwise the capability must have
+// exactly one provider. Returns an empty string if nothing provides it.
+func (r *Resolver) Provider(capability string) (string, error) {
+	providers := r.manifest.Providers(capability)
+
+	if chosen, ok := r.options.Providers[capability]; ok {
+		for _, p := range providers {
+			if p == chosen {
+				return chosen, nil
+			}
+		}
+		return "", fmt.Errorf("configured provider %s does not provide %s", chosen, capability)
+	}
+
+	switch len(providers) {
+	case 0:
+		return "", nil
+	case 1:
+		return providers[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous capability %s: provided by %s (configure a default provider)",
+			capability, strings.Join(providers, ", "))
+	}
+}
+
+// checkCapabilities returns an error if any of the given items (or their
+// transitive dependencies) depend on a capability that could not be resolved.
+func (r *Resolver) checkCapabilities(ids []string) error {
+	if len(r.capabilityErrors) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		nodes := append([]string{id}, r.graph.AllDependencies(id)...)
+		for _, node := range nodes {
+			for _, dep := range r.graph.Dependencies(node) {
+				if err, ok := r.capabilityErrors[dep]; ok {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Graph returns the underlying dependency graph.
 func (r *Resolver) Graph() *Graph {
 	return r.graph
@@ -58,16 +172,42 @@ type ResolveResult struct {
 
 	// Missing are dependencies that don't exist in the registry.
 	Missing []string
+
+	// Choices maps stacks to the alternative picked from their one_of list.
+	Choices map[string]string
+
+	// Aliases maps requested aliases to the items they resolved to.
+	Aliases map[string]string
 }
 
 // Resolve resolves dependencies for the given item IDs.
 // Returns items in installation order (dependencies first).
+// IDs may be item aliases, which resolve to the items they name.
 func (r *Resolver) Resolve(ids []string) (*ResolveResult, error) {
-	// Check for missing items
-	for _, id := range ids {
+	// Map aliases and check for missing items
+	aliases := make(map[string]string)
+	resolvedIDs := make([]string, len(ids))
+	for i, id := range ids {
+		if target, ok := r.manifest.ResolveAlias(id); ok {
+			aliases[id] = target
+			id = target
+		}
 		if _, ok := r.manifest.GetItem(id); !ok {
 			return nil, fmt.Errorf("item not found: %s", id)
 		}
+		resolvedIDs[i] = id
+	}
+	ids = resolvedIDs
+
+	// Pick alternatives offered by stacks
+	choices, err := r.chooseAlternatives(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check capabilities can be mapped to a provider
+	if err := r.checkCapabilities(ids); err != nil {
+		return nil, err
 	}
 
 	// Get installation order
@@ -91,9 +231,82 @@ func (r *Resolver) Resolve(ids []string) (*ResolveResult, error) {
 		Order:   order,
 		Items:   items,
 		Missing: missing,
+		Choices: choices,
+		Aliases: aliases,
 	}, nil
 }
 
+// chooseAlternatives picks one item from the one_of list of every stack
+// reachable from ids and adds it to the stack's dependencies in the graph.
+// Chosen alternatives may themselves be stacks, so this repeats until no
+// unresolved alternatives remain.
+func (r *Resolver) chooseAlternatives(ids []string) (map[string]string, error) {
+	choices := make(map[string]string)
+
+	for {
+		var pending []string
+		for _, id := range ids {
+			nodes := append([]string{id}, r.graph.AllDependencies(id)...)
+			for _, node := range nodes {
+				item, ok := r.manifest.GetItem(node)
+				if !ok || len(item.OneOf) == 0 {
+					continue
+				}
+				if _, done := choices[node]; !done {
+					pending = append(pending, node)
+				}
+			}
+		}
+		if len(pending) == 0 {
+			return choices, nil
+		}
+
+		sort.Strings(pending)
+		for _, stack := range pending {
+			if _, done := choices[stack]; done {
+				continue
+			}
+			item, _ := r.manifest.GetItem(stack)
+			chosen, err := r.chooseAlternative(stack, item.OneOf)
+			if err != nil {
+				return nil, err
+			}
+			choices[stack] = chosen
+
+			node, _ := r.graph.GetNode(stack)
+			deps := append(append([]string{}, node.Deps...), r.mapDeps([]string{chosen})...)
+			r.graph.AddNode(node.ID, node.Type, node.Name, deps)
+		}
+	}
+}
+
+// chooseAlternative picks one of the alternatives for a stack, using the
+// preferred list first, then the Choose callback, then the first entry.
+func (r *Resolver) chooseAlternative(stack string, alternatives []string) (string, error) {
+	for _, preferred := range r.options.Preferred {
+		for _, alt := range alternatives {
+			if alt == preferred {
+				return alt, nil
+			}
+		}
+	}
+
+	if r.options.Choose == nil {
+		return alternatives[0], nil
+	}
+
+	chosen, err := r.options.Choose(stack, alternatives)
+	if err != nil {
+		return "", fmt.Errorf("failed to choose alternative for %s: %w", stack, err)
+	}
+	for _, alt := range alternatives {
+		if alt == chosen {
+			return chosen, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not an alternative for %s", chosen, stack)
+}
+
 // ResolveAll resolves all items in the manifest.
 // Returns items in installation order.
 func (r *Resolver) ResolveAll() (*ResolveResult, error) {
@@ -125,12 +338,11 @@ func (r *Resolver) findMissing(ids []string) []string {
 
 	var check func(id string)
 	check = func(id string) {
-		item, ok := r.manifest.GetItem(id)
-		if !ok {
+		if _, ok := r.manifest.GetItem(id); !ok {
 			return
 		}
 
-		for _, dep := range item.Deps {
+		for _, dep := range r.graph.Dependencies(id) {
 			if _, exists := r.manifest.GetItem(dep); !exists {
 				if !seen[dep] {
 					seen[dep] = true
@@ -193,7 +405,7 @@ func (r *Resolver) GetDependencyInfo(id string) (*DependencyInfo, error) {
 
 	// Find missing dependencies
 	var missing []string
-	for _, dep := range item.Deps {
+	for _, dep := range r.graph.Dependencies(id) {
 		if _, exists := r.manifest.GetItem(dep); !exists {
 			missing = append(missing, dep)
 		}
@@ -201,7 +413,7 @@ func (r *Resolver) GetDependencyInfo(id string) (*DependencyInfo, error) {
 
 	return &DependencyInfo{
 		ID:         id,
-		DirectDeps: item.Deps,
+		DirectDeps: r.manifest.ItemDeps(item),
 		AllDeps:    r.graph.AllDependencies(id),
 		Dependents: r.graph.Dependents(id),
 		Missing:    missing,
@@ -210,44 +422,126 @@ func (r *Resolver) GetDependencyInfo(id string) (*DependencyInfo, error) {
 
 // ValidateResult contains the result of dependency validation.
 type ValidateResult struct {
-	// Valid is true if all dependencies are satisfied and no cycles exist.
+	// Valid is true if no issue is an error.
 	Valid bool
 
-	// Cycle contains the cycle path if one exists.
+	// Issues are the dependency problems found, cycles first.
+	Issues []Issue
+}
+
+// Issue is a dependency problem found by Validate.
+type Issue interface {
+	// Severity is how serious the issue is.
+	Severity() registry.Severity
+
+	// ValidationIssue returns the issue as a validation issue on the deps
+	// field of the item it is reported on, so it can be located in the
+	// item's source file.
+	ValidationIssue() registry.ValidationIssue
+
+	String() string
+}
+
+// CycleIssue is a circular dependency.
+type CycleIssue struct {
+	// Cycle lists the items of the cycle, each depending on the next; the
+	// first item is repeated at the end.
 	Cycle []string
 
-	// MissingDeps are dependencies that reference non-existent items.
-	MissingDeps []string
+	// Path is the source file of the first item, relative to the registry
+	// root.
+	Path string
+}
+
+// Severity returns SeverityError: items in a cycle can't be installed.
+func (c *CycleIssue) Severity() registry.Severity {
+	return registry.SeverityError
+}
 
-	// Errors contains human-readable error messages.
-	Errors []string
+// ValidationIssue returns the cycle as an issue on the first item.
+func (c *CycleIssue) ValidationIssue() registry.ValidationIssue {
+	return registry.ValidationIssue{Severity: c.Severity(), Path: c.Path, Field: "deps", Message: c.String()}
+}
+
+func (c *CycleIssue) String() string {
+	return fmt.Sprintf("circular dependency: %s", strings.Join(c.Cycle, " -> "))
+}
+
+// MissingDepIssue is a dependency on an item that doesn't exist, or on a
+// capability without a usable provider.
+type MissingDepIssue struct {
+	// Item is the full name of the item with the dependency.
+	Item string
+
+	// Path is the item's source file, relative to the registry root.
+	Path string
+
+	// Dep is the missing dependency.
+	Dep string
+
+	// Reason explains why a capability has no usable provider, such as
+	// several items providing it; empty if nothing provides it.
+	Reason string
+}
+
+// Severity returns SeverityError: the item can't be installed.
+func (m *MissingDepIssue) Severity() registry.Severity {
+	return registry.SeverityError
+}
+
+// ValidationIssue returns the missing dependency as an issue on the item.
+func (m *MissingDepIssue) ValidationIssue() registry.ValidationIssue {
+	return registry.ValidationIssue{Severity: m.Severity(), Path: m.Path, Field: "deps", Message: m.String()}
+}
+
+func (m *MissingDepIssue) String() string {
+	if m.Reason != "" {
+		return fmt.Sprintf("%s: %s", m.Item, m.Reason)
+	}
+	return fmt.Sprintf("%s depends on missing: %s", m.Item, m.Dep)
 }
 
 // Validate checks if all dependencies are valid.
 func (r *Resolver) Validate() *ValidateResult {
-	result := &ValidateResult{Valid: true}
+	result := &ValidateResult{}
 
-	// Check for cycles
 	if cycle, err := r.FindCycle(); err == nil && len(cycle) > 0 {
-		result.Valid = false
-		result.Cycle = cycle
-		result.Errors = append(result.Errors,
-			fmt.Sprintf("circular dependency: %v", cycle))
+		result.Issues = append(result.Issues, &CycleIssue{Cycle: cycle, Path: r.source(cycle[0])})
 	}
 
-	// Check for missing dependencies
-	missing := r.graph.Validate()
-	if len(missing) > 0 {
-		result.Valid = false
-		result.MissingDeps = missing
-		for _, m := range missing {
-			result.Errors = append(result.Errors, m)
+	for _, id := range r.graph.Nodes() {
+		seen := make(map[string]bool)
+		for _, dep := range r.graph.Dependencies(id) {
+			if _, exists := r.graph.GetNode(dep); exists || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			issue := &MissingDepIssue{Item: id, Path: r.source(id), Dep: dep}
+			if err, ok := r.capabilityErrors[dep]; ok {
+				issue.Reason = err.Error()
+			}
+			result.Issues = append(result.Issues, issue)
 		}
 	}
 
+	result.Valid = true
+	for _, issue := range result.Issues {
+		if issue.Severity() == registry.SeverityError {
+			result.Valid = false
+		}
+	}
 	return result
 }
 
+// source returns the source file of an item, or an empty string if it
+// isn't in the manifest.
+func (r *Resolver) source(id string) string {
+	if item, ok := r.manifest.GetItem(id); ok {
+		return item.Source
+	}
+	return ""
+}
+
 // GetInstallOrder returns the installation order for specific items.
 // This is a convenience method that returns just the order without full resolution.
 func (r *Resolver) GetInstallOrder(ids []string) ([]string, error) {
@@ -1,9 +1,11 @@
 package resolver
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/pkg/registrytest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -256,9 +258,7 @@ func TestResolver_Validate(t *testing.T) {
 
 	result := r.Validate()
 	assert.True(t, result.Valid)
-	assert.Empty(t, result.Cycle)
-	assert.Empty(t, result.MissingDeps)
-	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Issues)
 }
 
 func TestResolver_Validate_WithCycle(t *testing.T) {
@@ -287,8 +287,17 @@ func TestResolver_Validate_WithCycle(t *testing.T) {
 	result := r.Validate()
 
 	assert.False(t, result.Valid)
-	assert.NotEmpty(t, result.Cycle)
-	assert.NotEmpty(t, result.Errors)
+	require.Len(t, result.Issues, 1)
+	cycle, ok := result.Issues[0].(*CycleIssue)
+	require.True(t, ok)
+	assert.Equal(t, []string{"skill:a", "skill:b", "skill:a"}, cycle.Cycle)
+	assert.Equal(t, "a.md", cycle.Path)
+
+	issue := cycle.ValidationIssue()
+	assert.Equal(t, registry.SeverityError, issue.Severity)
+	assert.Equal(t, "a.md", issue.Path)
+	assert.Equal(t, "deps", issue.Field)
+	assert.Equal(t, "circular dependency: skill:a -> skill:b -> skill:a", issue.Message)
 }
 
 func TestResolver_Validate_WithMissingDeps(t *testing.T) {
@@ -308,8 +317,48 @@ func TestResolver_Validate_WithMissingDeps(t *testing.T) {
 	result := r.Validate()
 
 	assert.False(t, result.Valid)
-	assert.NotEmpty(t, result.MissingDeps)
-	assert.NotEmpty(t, result.Errors)
+	assert.Equal(t, []Issue{
+		&MissingDepIssue{Item: "skill:test", Path: "test.md", Dep: "skill:missing"},
+	}, result.Issues)
+	assert.Equal(t, "skill:test depends on missing: skill:missing", result.Issues[0].String())
+}
+
+func TestResolver_Validate_Capabilities(t *testing.T) {
+	tests := []struct {
+		name       string
+		providers  map[string]string
+		wantIssues []Issue
+	}{
+		{
+			name: "ambiguous capability is reported with the reason",
+			wantIssues: []Issue{
+				&MissingDepIssue{
+					Item:   "stack:team",
+					Path:   "team.md",
+					Dep:    "capability:git-workflow",
+					Reason: "ambiguous capability capability:git-workflow: provided by skill:git-flow, skill:trunk (configure a default provider)",
+				},
+			},
+		},
+		{
+			name:      "configured provider resolves ambiguity",
+			providers: map[string]string{"capability:git-workflow": "skill:trunk"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := registry.NewManifest("")
+			for _, item := range createCapabilityItems() {
+				manifest.AddItem(item)
+			}
+			r := NewResolverWithOptions(manifest, Options{Providers: tt.providers})
+
+			result := r.Validate()
+			assert.Equal(t, len(tt.wantIssues) == 0, result.Valid)
+			assert.Equal(t, tt.wantIssues, result.Issues)
+		})
+	}
 }
 
 func TestResolver_GetInstallOrder(t *testing.T) {
@@ -432,3 +481,278 @@ func TestResolver_DiamondDependency(t *testing.T) {
 	assert.Less(t, indexB, len(order)-1)
 	assert.Less(t, indexC, len(order)-1)
 }
+
+func createCapabilityItems() []*registry.Item {
+	return []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "git-flow", Desc: "Git flow", Provides: []string{"capability:git-workflow"}}, Source: "git-flow.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "trunk", Desc: "Trunk based", Provides: []string{"capability:git-workflow"}}, Source: "trunk.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "linting", Desc: "Linting", Provides: []string{"capability:lint"}}, Source: "linting.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "team", Desc: "Team", Deps: []string{"capability:git-workflow"}}, Source: "team.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "lint", Desc: "Lint", Deps: []string{"capability:lint"}}, Source: "lint.md"},
+	}
+}
+
+func TestResolver_Capabilities(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers map[string]string
+		ids       []string
+		wantOrder []string
+		wantErr   string
+	}{
+		{
+			name:      "single provider is chosen automatically",
+			ids:       []string{"stack:lint"},
+			wantOrder: []string{"skill:linting", "stack:lint"},
+		},
+		{
+			name:    "ambiguous capability errors",
+			ids:     []string{"stack:team"},
+			wantErr: "ambiguous capability capability:git-workflow",
+		},
+		{
+			name:      "configured provider resolves ambiguity",
+			providers: map[string]string{"capability:git-workflow": "skill:trunk"},
+			ids:       []string{"stack:team"},
+			wantOrder: []string{"skill:trunk", "stack:team"},
+		},
+		{
+			name:      "configured provider must provide the capability",
+			providers: map[string]string{"capability:git-workflow": "skill:linting"},
+			ids:       []string{"stack:team"},
+			wantErr:   "does not provide capability:git-workflow",
+		},
+		{
+			name:      "ambiguity outside the requested items is ignored",
+			ids:       []string{"skill:git-flow"},
+			wantOrder: []string{"skill:git-flow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := registry.NewManifest("")
+			for _, item := range createCapabilityItems() {
+				manifest.AddItem(item)
+			}
+			r := NewResolverWithOptions(manifest, Options{Providers: tt.providers})
+
+			result, err := r.Resolve(tt.ids)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOrder, result.Order)
+			assert.Empty(t, result.Missing)
+		})
+	}
+}
+
+func TestResolver_Capabilities_NoProvider(t *testing.T) {
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "team", Desc: "Team", Deps: []string{"capability:missing"}}, Source: "team.md"},
+	}
+
+	r := NewResolverFromItems(items)
+
+	result, err := r.Resolve([]string{"stack:team"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"capability:missing"}, result.Missing)
+}
+
+func TestResolver_Alternatives(t *testing.T) {
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "jest", Desc: "Jest"}, Source: "jest.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "vitest", Desc: "Vitest"}, Source: "vitest.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "lint", Desc: "Lint"}, Source: "lint.md"},
+		{Regis3Meta: registry.Regis3Meta{
+			Type:  "stack",
+			Name:  "web",
+			Desc:  "Web",
+			Deps:  []string{"skill:lint"},
+			OneOf: []string{"skill:jest", "skill:vitest"},
+		}, Source: "web.md"},
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantChoice string
+		wantErr    string
+	}{
+		{
+			name:       "defaults to first alternative",
+			wantChoice: "skill:jest",
+		},
+		{
+			name:       "preferred item wins",
+			opts:       Options{Preferred: []string{"skill:unrelated", "skill:vitest"}},
+			wantChoice: "skill:vitest",
+		},
+		{
+			name: "choose callback is used without preference",
+			opts: Options{Choose: func(stack string, alternatives []string) (string, error) {
+				return alternatives[1], nil
+			}},
+			wantChoice: "skill:vitest",
+		},
+		{
+			name: "choose callback must return an alternative",
+			opts: Options{Choose: func(stack string, alternatives []string) (string, error) {
+				return "skill:lint", nil
+			}},
+			wantErr: "skill:lint is not an alternative for stack:web",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := registry.NewManifest("")
+			for _, item := range items {
+				manifest.AddItem(item)
+			}
+			r := NewResolverWithOptions(manifest, tt.opts)
+
+			result, err := r.Resolve([]string{"stack:web"})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"stack:web": tt.wantChoice}, result.Choices)
+			assert.ElementsMatch(t, []string{"skill:lint", tt.wantChoice, "stack:web"}, result.Order)
+			assert.Equal(t, "stack:web", result.Order[len(result.Order)-1])
+		})
+	}
+}
+
+func TestResolver_Aliases(t *testing.T) {
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "git-conventions", Desc: "Git", Aliases: []string{"git-flow"}}, Source: "git.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "base", Desc: "Base", Deps: []string{"skill:git-flow"}}, Source: "base.md"},
+	}
+
+	r := NewResolverFromItems(items)
+
+	t.Run("requested alias resolves", func(t *testing.T) {
+		result, err := r.Resolve([]string{"skill:git-flow"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:git-conventions"}, result.Order)
+		assert.Equal(t, map[string]string{"skill:git-flow": "skill:git-conventions"}, result.Aliases)
+	})
+
+	t.Run("dependency on an alias resolves", func(t *testing.T) {
+		result, err := r.Resolve([]string{"stack:base"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:git-conventions", "stack:base"}, result.Order)
+		assert.Empty(t, result.Missing)
+		assert.Empty(t, result.Aliases)
+	})
+
+	t.Run("alias of another type does not resolve", func(t *testing.T) {
+		_, err := r.Resolve([]string{"subagent:git-flow"})
+		assert.Error(t, err)
+	})
+}
+
+func TestResolver_DynamicStack(t *testing.T) {
+	items := []*registry.Item{
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "go-style", Desc: "Go style", Tags: []string{"golang"}}, Source: "go-style.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "go-errors", Desc: "Go errors", Tags: []string{"golang"}, Deps: []string{"skill:git"}}, Source: "go-errors.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "skill", Name: "git", Desc: "Git"}, Source: "git.md"},
+		{Regis3Meta: registry.Regis3Meta{Type: "stack", Name: "go", Desc: "All Go skills", DepsQuery: "type=skill and tag=golang"}, Source: "go.md"},
+	}
+
+	r := NewResolverFromItems(items)
+	result, err := r.Resolve([]string{"stack:go"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"skill:git", "skill:go-errors", "skill:go-style", "stack:go"}, result.Order)
+	assert.Equal(t, "stack:go", result.Order[len(result.Order)-1])
+
+	info, err := r.GetDependencyInfo("stack:go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skill:go-errors", "skill:go-style"}, info.DirectDeps)
+}
+
+func TestResolver_GraphCache(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), ".build", DefaultGraphFile)
+	newManifest := func(hash string) *registry.Manifest {
+		manifest := registry.NewManifest("")
+		for _, item := range createCapabilityItems() {
+			manifest.AddItem(item)
+		}
+		manifest.Hash = hash
+		return manifest
+	}
+	opts := Options{GraphCache: cache}
+
+	built := NewResolverWithOptions(newManifest("abc"), opts)
+	require.FileExists(t, cache)
+	wantOrder, err := built.GetAllInstallOrder()
+	require.NoError(t, err)
+
+	t.Run("same manifest reads the cache", func(t *testing.T) {
+		// Drop the items' dependencies: only a cached graph still has them
+		manifest := newManifest("abc")
+		for _, item := range manifest.Items {
+			item.Deps = nil
+		}
+		r := NewResolverWithOptions(manifest, opts)
+		order, err := r.GetAllInstallOrder()
+		require.NoError(t, err)
+		assert.Equal(t, wantOrder, order)
+		assert.Equal(t, []string{"skill:linting"}, r.Graph().Dependencies("stack:lint"))
+
+		_, err = r.Resolve([]string{"stack:team"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous capability capability:git-workflow")
+	})
+
+	t.Run("changed manifest rebuilds the graph", func(t *testing.T) {
+		manifest := newManifest("def")
+		manifest.Items["stack:lint"].Deps = nil
+		r := NewResolverWithOptions(manifest, opts)
+		assert.Empty(t, r.Graph().Dependencies("stack:lint"))
+	})
+
+	t.Run("changed providers rebuild the graph", func(t *testing.T) {
+		NewResolverWithOptions(newManifest("abc"), opts)
+		r := NewResolverWithOptions(newManifest("abc"), Options{
+			GraphCache: cache,
+			Providers:  map[string]string{"capability:git-workflow": "skill:trunk"},
+		})
+		result, err := r.Resolve([]string{"stack:team"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"skill:trunk", "stack:team"}, result.Order)
+	})
+
+	t.Run("manifests without a hash are not cached", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), DefaultGraphFile)
+		NewResolverWithOptions(newManifest(""), Options{GraphCache: other})
+		assert.NoFileExists(t, other)
+	})
+}
+
+func BenchmarkResolver_Resolve(b *testing.B) {
+	dir := b.TempDir()
+	reg := registrytest.Generate(registrytest.Options{
+		Seed:    1,
+		Items:   map[string]int{"skill": 2000},
+		Stacks:  50,
+		MaxDeps: 4,
+	})
+	require.NoError(b, reg.Write(dir))
+	result, err := registry.BuildRegistry(dir)
+	require.NoError(b, err)
+	stacks := reg.Refs()[len(reg.Items)-50:]
+
+	for b.Loop() {
+		r := NewResolver(result.Manifest)
+		if _, err := r.Resolve(stacks); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
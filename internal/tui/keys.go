@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/okto-digital/regis3/internal/output"
+)
+
+// pickerKeyMap holds the picker's key bindings. The handlers match against
+// these bindings and the help line and overlay are generated from them, so
+// the help always shows what the keys do.
+type pickerKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Select   key.Binding
+	Search   key.Binding
+	Confirm  key.Binding
+	Install  key.Binding
+	Edit     key.Binding
+	Cancel   key.Binding
+	Help     key.Binding
+	Palette  key.Binding
+	Quit     key.Binding
+}
+
+// searchKeyMap holds the bindings active while the search field has focus.
+// Other keys are typed into the search.
+type searchKeyMap struct {
+	Up   key.Binding
+	Down key.Binding
+	Done key.Binding
+}
+
+// paletteKeyMap holds the bindings active while the command palette is
+// open. Other keys are typed into the palette.
+type paletteKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	Close  key.Binding
+}
+
+// bindings returns the picker's bindings, with help in its icon set.
+func (p *Picker) bindings() pickerKeyMap {
+	return newPickerKeyMap(p.Icons)
+}
+
+// searchBindings returns the bindings active while searching.
+func (p *Picker) searchBindings() searchKeyMap {
+	return newSearchKeyMap(p.Icons)
+}
+
+// paletteBindings returns the bindings active while the palette is open.
+func (p *Picker) paletteBindings() paletteKeyMap {
+	return newPaletteKeyMap(p.Icons)
+}
+
+func newPickerKeyMap(icons output.Icons) pickerKeyMap {
+	return pickerKeyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp(icons.Up+"/k", "move up")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp(icons.Down+"/j", "move down")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+		Select:   key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space/x", "select")),
+		Search:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		Confirm:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+		Install:  key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "install the item under the cursor")),
+		Edit:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit the source file of the item under the cursor")),
+		Cancel:   key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "clear search or cancel")),
+		Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Palette:  key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "jump to an item or run an action")),
+		Quit:     key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+	}
+}
+
+func newPaletteKeyMap(icons output.Icons) paletteKeyMap {
+	return paletteKeyMap{
+		Up:     key.NewBinding(key.WithKeys("up", "ctrl+k"), key.WithHelp(icons.Up+"/ctrl+k", "previous match")),
+		Down:   key.NewBinding(key.WithKeys("down", "ctrl+j"), key.WithHelp(icons.Down+"/ctrl+j", "next match")),
+		Choose: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "jump to item or run action")),
+		Close:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close palette")),
+	}
+}
+
+func newSearchKeyMap(icons output.Icons) searchKeyMap {
+	return searchKeyMap{
+		Up:   key.NewBinding(key.WithKeys("up"), key.WithHelp(icons.Up, "move up")),
+		Down: key.NewBinding(key.WithKeys("down"), key.WithHelp(icons.Down, "move down")),
+		Done: key.NewBinding(key.WithKeys("esc", "enter"), key.WithHelp("esc/enter", "leave search")),
+	}
+}
+
+// helpSection is a titled group of bindings in the help overlay.
+type helpSection struct {
+	title    string
+	bindings []key.Binding
+}
+
+// fullHelp returns the bindings shown in the help overlay.
+func (p *Picker) fullHelp() []helpSection {
+	k, s, c := p.bindings(), p.searchBindings(), p.paletteBindings()
+	selection := []key.Binding{k.Select, k.Search, k.Confirm, k.Install, k.Cancel}
+	if p.Edit != nil {
+		selection = append(selection[:4], k.Edit, k.Cancel)
+	}
+	return []helpSection{
+		{"Navigation", []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown}},
+		{"Selection", selection},
+		{"While searching", []key.Binding{s.Up, s.Down, s.Done}},
+		{"Command palette", []key.Binding{c.Up, c.Down, c.Choose, c.Close}},
+		{"General", []key.Binding{k.Palette, k.Help, k.Quit}},
+	}
+}
+
+// shortHelp renders the one-line help under the list.
+func (p *Picker) shortHelp() string {
+	k := p.bindings()
+	move := firstKey(k.Up) + "/" + firstKey(k.Down) + " move"
+	parts := []string{move}
+	for _, b := range []key.Binding{k.Select, k.Search, k.Confirm, k.Cancel, k.Help} {
+		parts = append(parts, firstKey(b)+" "+shortDesc(b))
+	}
+
+	sep := " " + p.Icons.Bullet + " "
+	if p.Icons.Bullet == "" {
+		sep = ", "
+	}
+	return strings.Join(parts, sep)
+}
+
+// viewHelp renders the help overlay listing every binding.
+func (p *Picker) viewHelp() string {
+	sections := p.fullHelp()
+	keyWidth := 0
+	for _, section := range sections {
+		for _, b := range section.bindings {
+			keyWidth = max(keyWidth, len([]rune(b.Help().Key)))
+		}
+	}
+
+	var lines []string
+	for i, section := range sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, styleHeader.Render(section.title))
+		for _, b := range section.bindings {
+			help := b.Help()
+			pad := strings.Repeat(" ", keyWidth-len([]rune(help.Key)))
+			lines = append(lines, "  "+styleCursor.Render(help.Key)+pad+"  "+help.Desc)
+		}
+	}
+	return stylePreview.Render(strings.Join(lines, "\n"))
+}
+
+// firstKey returns the first key of a binding's help, e.g. "space" for
+// "space/x".
+func firstKey(b key.Binding) string {
+	help := b.Help().Key
+	if k, _, ok := strings.Cut(help, "/"); ok && k != "" {
+		return k
+	}
+	return help
+}
+
+// shortDesc returns the last word of a binding's description, e.g. "cancel"
+// for "clear search or cancel".
+func shortDesc(b key.Binding) string {
+	words := strings.Fields(b.Help().Desc)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteLimit is the number of matches the command palette shows.
+const paletteLimit = 10
+
+// Action is a command the picker's palette can run, such as rebuilding the
+// registry.
+type Action struct {
+	Name string
+	Desc string
+
+	// Run performs the action. It returns the new entries, or nil when the
+	// list is unchanged.
+	Run func() ([]Entry, error)
+}
+
+// actionDoneMsg carries the result of an action run from the palette.
+type actionDoneMsg struct {
+	name    string
+	entries []Entry
+	err     error
+}
+
+// paletteMatch is an item ref or action matching the palette query.
+type paletteMatch struct {
+	label  string
+	desc   string
+	action *Action // nil for item refs
+	score  int
+}
+
+// palette is the command palette: a fuzzy search over item refs and actions.
+type palette struct {
+	input   textinput.Model
+	matches []paletteMatch
+	cursor  int
+}
+
+func newPalette() *palette {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "item ref or action"
+	input.Focus()
+	return &palette{input: input}
+}
+
+// openPalette opens the command palette.
+func (p *Picker) openPalette() tea.Cmd {
+	p.palette = newPalette()
+	p.updateMatches()
+	return textinput.Blink
+}
+
+// updateMatches recomputes the palette matches for its query, best first.
+func (p *Picker) updateMatches() {
+	query := p.palette.input.Value()
+	var matches []paletteMatch
+	for i := range p.Actions {
+		a := &p.Actions[i]
+		if score, ok := fuzzyScore(query, a.Name); ok {
+			matches = append(matches, paletteMatch{label: a.Name, desc: a.Desc, action: a, score: score})
+		}
+	}
+	for _, e := range p.entries {
+		if score, ok := fuzzyScore(query, e.Ref); ok {
+			matches = append(matches, paletteMatch{label: e.Ref, desc: e.Desc, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		// Actions come first on ties, there are only a few
+		return matches[i].action != nil && matches[j].action == nil
+	})
+	if len(matches) > paletteLimit {
+		matches = matches[:paletteLimit]
+	}
+	p.palette.matches = matches
+	p.palette.cursor = 0
+}
+
+// updatePalette handles keys while the palette is open.
+func (p *Picker) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := p.paletteBindings()
+	pal := p.palette
+	switch {
+	case key.Matches(msg, k.Close):
+		p.palette = nil
+		return p, nil
+	case key.Matches(msg, k.Up):
+		pal.cursor = max(0, pal.cursor-1)
+		return p, nil
+	case key.Matches(msg, k.Down):
+		pal.cursor = max(0, min(len(pal.matches)-1, pal.cursor+1))
+		return p, nil
+	case key.Matches(msg, k.Choose):
+		p.palette = nil
+		if pal.cursor >= len(pal.matches) {
+			return p, nil
+		}
+		match := pal.matches[pal.cursor]
+		if match.action != nil {
+			return p, p.runAction(match.action)
+		}
+		p.jumpTo(match.label)
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	pal.input, cmd = pal.input.Update(msg)
+	p.updateMatches()
+	return p, cmd
+}
+
+// runAction runs an action in the background.
+func (p *Picker) runAction(a *Action) tea.Cmd {
+	p.notice = "Running " + a.Name + "..."
+	name, run := a.Name, a.Run
+	return func() tea.Msg {
+		entries, err := run()
+		return actionDoneMsg{name: name, entries: entries, err: err}
+	}
+}
+
+// jumpTo moves the cursor to the entry with ref, clearing a search that
+// hides it.
+func (p *Picker) jumpTo(ref string) {
+	find := func() bool {
+		for i, idx := range p.visible {
+			if p.entries[idx].Ref == ref {
+				p.cursor = i
+				return true
+			}
+		}
+		return false
+	}
+	if find() {
+		return
+	}
+	p.search.SetValue("")
+	p.applyFilter()
+	find()
+}
+
+// viewPalette renders the palette input and its matches.
+func (p *Picker) viewPalette(width int) string {
+	pal := p.palette
+	lines := []string{pal.input.View(), ""}
+	if len(pal.matches) == 0 {
+		lines = append(lines, styleMuted.Render("No matches"))
+	}
+	for i, m := range pal.matches {
+		label := m.label
+		if m.action != nil {
+			label = styleInstalled.Render(label)
+		}
+		line := label
+		if m.desc != "" {
+			line += "  " + styleMuted.Render(truncate(m.desc, max(0, width-len([]rune(m.label))-8), p.Icons.Ellipsis))
+		}
+		if i == pal.cursor {
+			line = styleCursor.Render(p.Icons.Arrow) + " " + line
+		} else {
+			line = strings.Repeat(" ", len([]rune(p.Icons.Arrow))+1) + line
+		}
+		lines = append(lines, line)
+	}
+	return stylePreview.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// fuzzyScore reports whether every character of query appears in text in
+// order, ignoring case, and scores the match: consecutive characters and
+// characters at the start of a word score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score, qi, prev := 0, 0, -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 2
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter texts among equal matches
+	return score*100 - len(t), true
+}
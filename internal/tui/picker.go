@@ -0,0 +1,681 @@
+// Package tui provides interactive terminal components built on Bubbletea.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/okto-digital/regis3/internal/registry"
+)
+
+// ErrCancelled is returned when the user leaves the picker without confirming.
+var ErrCancelled = errors.New("selection cancelled")
+
+// TypeOrder is the order in which item types are grouped by default.
+var TypeOrder = registry.DefaultTypeOrder
+
+// Order arranges the picker's entries: after the suggested entries, groups
+// follow Types and entries within a group follow Less.
+type Order struct {
+	// Types lists item types in group order (default: TypeOrder); unknown
+	// types come last.
+	Types []string
+
+	// Less orders entries within a group (default: by ref).
+	Less func(a, b Entry) bool
+}
+
+// Entry is a selectable registry item.
+type Entry struct {
+	Ref       string // type:name
+	Type      string
+	Name      string
+	Desc      string
+	Source    string
+	Tags      []string
+	Deps      []string
+	Installed bool
+
+	// Modified is when the item's source file last changed.
+	Modified time.Time
+
+	// Suggested, if set, is why the item is recommended for the project.
+	// Suggested entries are listed first, in the order given.
+	Suggested string
+
+	// Deprecated marks items that shouldn't be installed anymore;
+	// ReplacedBy names the item to use instead, if any.
+	Deprecated bool
+	ReplacedBy string
+
+	// Quarantined lists the validation errors of an item left out of the
+	// manifest. Quarantined entries can't be selected, only edited.
+	Quarantined []string
+}
+
+// group returns the title of the list group the entry belongs to.
+func (e Entry) group() string {
+	if e.Suggested != "" {
+		return "Suggested"
+	}
+	return groupTitle(e.Type)
+}
+
+// matches reports whether the entry contains every word of the query in its
+// ref, description or tags, ignoring case.
+func (e Entry) matches(query string) bool {
+	text := strings.ToLower(e.Ref + " " + e.Desc + " " + strings.Join(e.Tags, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	styleTitle     = lipgloss.NewStyle().Bold(true)
+	styleHeader    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	styleCursor    = lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Bold(true)
+	styleSelected  = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	styleMuted     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	stylePreview   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1)
+	styleInstalled = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	styleWarning   = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+)
+
+// DefaultRefreshInterval is how often a picker with a Reload function checks
+// for changes.
+const DefaultRefreshInterval = 3 * time.Second
+
+// refreshTickMsg asks the picker to check for changes.
+type refreshTickMsg struct{}
+
+// reloadedMsg carries the result of a Reload call.
+type reloadedMsg struct {
+	entries []Entry
+	err     error
+}
+
+// Event is a change made outside the picker, such as an action requested
+// over the control socket, for the picker to show.
+type Event struct {
+	// Notice is shown in the header, e.g. the outcome of the action.
+	Notice string
+
+	// Entries, if not nil, replace the listed entries.
+	Entries []Entry
+}
+
+// eventMsg carries an Event.
+type eventMsg Event
+
+// editedMsg reports that the editor opened on an entry's source exited.
+type editedMsg struct {
+	ref string
+	err error
+}
+
+// Picker is a full-screen multi-select over registry items, grouped by type,
+// with search and a preview of the item under the cursor.
+type Picker struct {
+	title    string
+	entries  []Entry
+	visible  []int // indexes into entries matching the search
+	cursor   int   // index into visible
+	offset   int   // first visible row shown
+	selected map[string]bool
+	search   textinput.Model
+	order    Order
+
+	// Icons are the symbols of the list, preview and help line.
+	Icons output.Icons
+
+	// Reload, if set, is polled every RefreshInterval while the picker is
+	// open. It returns the current entries, or nil when nothing changed, so
+	// changes made by other processes show up without reopening the picker.
+	Reload          func() ([]Entry, error)
+	RefreshInterval time.Duration
+	notice          string
+	showHelp        bool
+
+	// Actions are offered in the command palette (ctrl+p) next to the
+	// item refs.
+	Actions []Action
+	palette *palette
+
+	// Events, if set, delivers changes made outside the picker while it is
+	// open; the picker shows them until the channel is closed.
+	Events <-chan Event
+
+	// Edit, if set, returns the command that opens an entry's source file
+	// in an editor. The picker is suspended while it runs.
+	Edit func(e Entry) *exec.Cmd
+
+	width, height int
+	confirmed     bool
+	cancelled     bool
+}
+
+// NewPicker creates a picker over entries, sorted by type group and name.
+func NewPicker(title string, entries []Entry) *Picker {
+	return NewPickerWithOrder(title, entries, Order{})
+}
+
+// NewPickerWithOrder creates a picker over entries arranged by order.
+func NewPickerWithOrder(title string, entries []Entry, order Order) *Picker {
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "search"
+
+	if order.Types == nil {
+		order.Types = TypeOrder
+	}
+	if order.Less == nil {
+		order.Less = func(a, b Entry) bool { return a.Ref < b.Ref }
+	}
+	p := &Picker{
+		title:    title,
+		selected: make(map[string]bool),
+		search:   search,
+		order:    order,
+		Icons:    output.IconsUnicode.Icons(),
+		width:    100,
+		height:   24,
+	}
+	p.entries = p.sortEntries(entries)
+	p.applyFilter()
+	return p
+}
+
+// sortEntries returns entries sorted by type group and the order within
+// groups, after the suggested entries.
+func (p *Picker) sortEntries(entries []Entry) []Entry {
+	sorted := append([]Entry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := sorted[i].Suggested != "", sorted[j].Suggested != ""
+		if si || sj {
+			return si && !sj
+		}
+		ti, tj := p.typeRank(sorted[i].Type), p.typeRank(sorted[j].Type)
+		if ti != tj {
+			return ti < tj
+		}
+		return p.order.Less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// typeRank returns the position of an item type in the group order;
+// unknown types sort last.
+func (p *Picker) typeRank(itemType string) int {
+	for i, t := range p.order.Types {
+		if t == itemType {
+			return i
+		}
+	}
+	return len(p.order.Types)
+}
+
+// Selected returns the selected refs in display order.
+func (p *Picker) Selected() []string {
+	var refs []string
+	for _, e := range p.entries {
+		if p.selected[e.Ref] {
+			refs = append(refs, e.Ref)
+		}
+	}
+	return refs
+}
+
+// Run shows the picker full-screen and returns the selected refs.
+func (p *Picker) Run() ([]string, error) {
+	if _, err := tea.NewProgram(p, tea.WithAltScreen()).Run(); err != nil {
+		return nil, err
+	}
+	if p.cancelled {
+		return nil, ErrCancelled
+	}
+	return p.Selected(), nil
+}
+
+// Init implements tea.Model.
+func (p *Picker) Init() tea.Cmd {
+	return tea.Batch(p.scheduleRefresh(), p.waitForEvent())
+}
+
+// waitForEvent waits for the next event, if events are delivered.
+func (p *Picker) waitForEvent() tea.Cmd {
+	if p.Events == nil {
+		return nil
+	}
+	events := p.Events
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return eventMsg(event)
+	}
+}
+
+// scheduleRefresh waits for the next refresh check, if reloading is on.
+func (p *Picker) scheduleRefresh() tea.Cmd {
+	if p.Reload == nil {
+		return nil
+	}
+	interval := p.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+// setEntries replaces the entries, keeping the search, the selection of
+// entries that still exist and the cursor on the same entry when possible.
+func (p *Picker) setEntries(entries []Entry) {
+	atCursor, hadCursor := p.current()
+	p.entries = p.sortEntries(entries)
+
+	refs := make(map[string]bool, len(p.entries))
+	for _, e := range p.entries {
+		refs[e.Ref] = true
+	}
+	for ref := range p.selected {
+		if !refs[ref] {
+			delete(p.selected, ref)
+		}
+	}
+
+	cursor := p.cursor
+	p.filter()
+	p.cursor = max(0, min(len(p.visible)-1, cursor))
+	if hadCursor {
+		for i, idx := range p.visible {
+			if p.entries[idx].Ref == atCursor.Ref {
+				p.cursor = i
+				break
+			}
+		}
+	}
+}
+
+// Update implements tea.Model.
+func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+		return p, nil
+
+	case refreshTickMsg:
+		reload := p.Reload
+		return p, func() tea.Msg {
+			entries, err := reload()
+			return reloadedMsg{entries: entries, err: err}
+		}
+
+	case reloadedMsg:
+		// A failed reload (e.g. a manifest being rewritten) keeps the
+		// current list; the next check tries again.
+		if msg.err == nil && msg.entries != nil {
+			p.setEntries(msg.entries)
+			p.notice = "Registry changed, list refreshed"
+		}
+		return p, p.scheduleRefresh()
+
+	case eventMsg:
+		if msg.Entries != nil {
+			p.setEntries(msg.Entries)
+		}
+		p.notice = msg.Notice
+		return p, p.waitForEvent()
+
+	case editedMsg:
+		if msg.err != nil {
+			p.notice = "Editing " + msg.ref + " failed: " + msg.err.Error()
+		} else {
+			p.notice = "Edited " + msg.ref + "; rebuild to see the changes"
+		}
+		return p, nil
+
+	case actionDoneMsg:
+		switch {
+		case msg.err != nil:
+			p.notice = msg.name + " failed: " + msg.err.Error()
+		case msg.entries != nil:
+			p.setEntries(msg.entries)
+			p.notice = msg.name + " finished, list refreshed"
+		default:
+			p.notice = msg.name + " finished"
+		}
+		return p, nil
+
+	case tea.KeyMsg:
+		p.notice = ""
+		if key.Matches(msg, p.bindings().Quit) {
+			p.cancelled = true
+			return p, tea.Quit
+		}
+		if p.palette != nil {
+			return p.updatePalette(msg)
+		}
+		if key.Matches(msg, p.bindings().Palette) {
+			p.showHelp = false
+			p.search.Blur()
+			return p, p.openPalette()
+		}
+
+		if p.search.Focused() {
+			s := p.searchBindings()
+			switch {
+			case key.Matches(msg, s.Done):
+				p.search.Blur()
+				return p, nil
+			case key.Matches(msg, s.Up):
+				p.moveCursor(-1)
+				return p, nil
+			case key.Matches(msg, s.Down):
+				p.moveCursor(1)
+				return p, nil
+			}
+			var cmd tea.Cmd
+			p.search, cmd = p.search.Update(msg)
+			p.applyFilter()
+			return p, cmd
+		}
+
+		k := p.bindings()
+		if p.showHelp {
+			// Any key closes the help overlay
+			p.showHelp = false
+			return p, nil
+		}
+		switch {
+		case key.Matches(msg, k.Up):
+			p.moveCursor(-1)
+		case key.Matches(msg, k.Down):
+			p.moveCursor(1)
+		case key.Matches(msg, k.PageUp):
+			p.moveCursor(-p.listHeight())
+		case key.Matches(msg, k.PageDown):
+			p.moveCursor(p.listHeight())
+		case key.Matches(msg, k.Select):
+			if e, ok := p.current(); ok {
+				if len(e.Quarantined) > 0 {
+					p.notice = quarantineNotice(e)
+					return p, nil
+				}
+				p.selected[e.Ref] = !p.selected[e.Ref]
+			}
+		case key.Matches(msg, k.Search):
+			p.search.Focus()
+			return p, textinput.Blink
+		case key.Matches(msg, k.Help):
+			p.showHelp = true
+		case key.Matches(msg, k.Confirm):
+			p.confirmed = true
+			return p, tea.Quit
+		case key.Matches(msg, k.Install):
+			e, ok := p.current()
+			if !ok {
+				return p, nil
+			}
+			if len(e.Quarantined) > 0 {
+				p.notice = quarantineNotice(e)
+				return p, nil
+			}
+			p.selected[e.Ref] = true
+			p.confirmed = true
+			return p, tea.Quit
+		case key.Matches(msg, k.Edit):
+			e, ok := p.current()
+			if !ok || p.Edit == nil || e.Source == "" {
+				return p, nil
+			}
+			return p, tea.ExecProcess(p.Edit(e), func(err error) tea.Msg {
+				return editedMsg{ref: e.Ref, err: err}
+			})
+		case key.Matches(msg, k.Cancel):
+			if p.search.Value() != "" {
+				p.search.SetValue("")
+				p.applyFilter()
+				return p, nil
+			}
+			p.cancelled = true
+			return p, tea.Quit
+		}
+	}
+	return p, nil
+}
+
+// applyFilter recomputes the visible entries for the current search and
+// moves the cursor to the top.
+func (p *Picker) applyFilter() {
+	p.filter()
+	p.cursor, p.offset = 0, 0
+}
+
+// filter recomputes the visible entries for the current search.
+func (p *Picker) filter() {
+	query := p.search.Value()
+	p.visible = p.visible[:0]
+	for i, e := range p.entries {
+		if e.matches(query) {
+			p.visible = append(p.visible, i)
+		}
+	}
+}
+
+// moveCursor moves the cursor by delta, clamped to the visible entries.
+func (p *Picker) moveCursor(delta int) {
+	p.cursor = max(0, min(len(p.visible)-1, p.cursor+delta))
+}
+
+// current returns the entry under the cursor.
+func (p *Picker) current() (Entry, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.visible) {
+		return Entry{}, false
+	}
+	return p.entries[p.visible[p.cursor]], true
+}
+
+// listHeight is the number of list rows that fit on screen.
+func (p *Picker) listHeight() int {
+	return max(3, p.height-4) // title, search and help lines, plus a spare
+}
+
+// View implements tea.Model.
+func (p *Picker) View() string {
+	if p.confirmed || p.cancelled {
+		return ""
+	}
+
+	listWidth := p.width * 3 / 5
+	previewWidth := p.width - listWidth - 2
+
+	header := styleTitle.Render(p.title) + styleMuted.Render(fmt.Sprintf("  %d selected", len(p.Selected())))
+	if n := p.quarantined(); n > 0 {
+		header += "  " + styleWarning.Render(fmt.Sprintf("%d quarantined", n))
+	}
+	if p.notice != "" {
+		header += "  " + styleInstalled.Render(p.notice)
+	}
+	if p.palette != nil {
+		return strings.Join([]string{header, p.viewPalette(p.width - 2), styleMuted.Render("Type to filter, enter to choose, esc to close")}, "\n")
+	}
+	if p.showHelp {
+		return strings.Join([]string{header, p.viewHelp(), styleMuted.Render("Press any key to close help")}, "\n")
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).Render(p.viewList(listWidth)),
+		"  ",
+		p.viewPreview(previewWidth),
+	)
+	help := styleMuted.Render(p.shortHelp())
+
+	return strings.Join([]string{header, p.search.View(), body, help}, "\n")
+}
+
+// viewList renders the grouped item list, scrolled to keep the cursor visible.
+func (p *Picker) viewList(width int) string {
+	if len(p.visible) == 0 {
+		return styleMuted.Render("No matching items")
+	}
+
+	// Build rows with a header before each group
+	var rows []string
+	cursorRow := 0
+	lastGroup := ""
+	for i, idx := range p.visible {
+		e := p.entries[idx]
+		if group := e.group(); group != lastGroup {
+			rows = append(rows, styleHeader.Render(group))
+			lastGroup = group
+		}
+		if i == p.cursor {
+			cursorRow = len(rows)
+		}
+		rows = append(rows, p.viewEntry(e, i == p.cursor, width))
+	}
+
+	height := p.listHeight()
+	if cursorRow < p.offset {
+		p.offset = cursorRow
+	} else if cursorRow >= p.offset+height {
+		p.offset = cursorRow - height + 1
+	}
+	end := min(len(rows), p.offset+height)
+	return strings.Join(rows[p.offset:end], "\n")
+}
+
+// viewEntry renders a single list row.
+func (p *Picker) viewEntry(e Entry, atCursor bool, width int) string {
+	pointer := "  "
+	if atCursor {
+		pointer = styleCursor.Render("> ")
+	}
+	box := "[ ]"
+	if p.selected[e.Ref] {
+		box = styleSelected.Render("[x]")
+	}
+
+	name := e.Name
+	if atCursor {
+		name = styleCursor.Render(name)
+	}
+	line := fmt.Sprintf("%s%s %s", pointer, box, name)
+	if e.Installed {
+		line += " " + styleInstalled.Render("(installed)")
+	}
+	if e.Deprecated {
+		line += " " + styleWarning.Render("(deprecated)")
+	}
+	if len(e.Quarantined) > 0 {
+		line += " " + styleWarning.Render("(quarantined)")
+	}
+
+	if room := width - lipgloss.Width(line) - 2; room > 10 && e.Desc != "" {
+		line += "  " + styleMuted.Render(truncate(e.Desc, room, p.Icons.Ellipsis))
+	}
+	return line
+}
+
+// viewPreview renders details of the entry under the cursor.
+func (p *Picker) viewPreview(width int) string {
+	e, ok := p.current()
+	if !ok || width < 20 {
+		return ""
+	}
+
+	lines := []string{styleTitle.Render(e.Ref)}
+	if e.Installed {
+		lines = append(lines, styleInstalled.Render("Installed in this project"))
+	}
+	if e.Suggested != "" {
+		lines = append(lines, styleSelected.Render("Suggested: ")+e.Suggested)
+	}
+	if e.Deprecated {
+		lines = append(lines, styleWarning.Render(deprecationText(e)))
+	}
+	if len(e.Quarantined) > 0 {
+		lines = append(lines, styleWarning.Render("Quarantined: left out of the manifest because of validation errors"))
+		for _, err := range e.Quarantined {
+			lines = append(lines, "  "+err)
+		}
+		if p.Edit != nil {
+			lines = append(lines, styleMuted.Render("Press "+firstKey(p.bindings().Edit)+" to edit the source file"))
+		}
+	}
+	lines = append(lines, "", e.Desc)
+	if e.Source != "" {
+		lines = append(lines, "", styleMuted.Render("Source: ")+e.Source)
+	}
+	if len(e.Tags) > 0 {
+		lines = append(lines, styleMuted.Render("Tags: ")+strings.Join(e.Tags, ", "))
+	}
+	if len(e.Deps) > 0 {
+		lines = append(lines, styleMuted.Render("Dependencies:"))
+		prefix := "  "
+		if p.Icons.Arrow != "" {
+			prefix += p.Icons.Arrow + " "
+		}
+		for _, dep := range e.Deps {
+			lines = append(lines, prefix+dep)
+		}
+	}
+
+	return stylePreview.Width(width - 4).Render(strings.Join(lines, "\n"))
+}
+
+// quarantined returns the number of quarantined entries.
+func (p *Picker) quarantined() int {
+	n := 0
+	for _, e := range p.entries {
+		if len(e.Quarantined) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// quarantineNotice tells why a quarantined entry can't be selected.
+func quarantineNotice(e Entry) string {
+	return e.Ref + " is quarantined because of validation errors; fix it to install it"
+}
+
+// deprecationText describes a deprecated entry and its replacement.
+func deprecationText(e Entry) string {
+	if e.ReplacedBy == "" {
+		return "Deprecated"
+	}
+	return "Deprecated, use " + e.ReplacedBy + " instead"
+}
+
+// groupTitle returns the plural header for an item type group.
+func groupTitle(itemType string) string {
+	if itemType == "" {
+		return "Other"
+	}
+	return strings.ToUpper(itemType[:1]) + itemType[1:] + "s"
+}
+
+// truncate shortens s to at most n runes, ending in ellipsis if cut.
+func truncate(s string, n int, ellipsis string) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	keep := max(n-len([]rune(ellipsis)), 0)
+	return string(r[:keep]) + ellipsis
+}
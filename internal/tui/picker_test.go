@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"os/exec"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/okto-digital/regis3/internal/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntries() []Entry {
+	return []Entry{
+		{Ref: "stack:base", Type: "stack", Name: "base", Desc: "Base stack"},
+		{Ref: "skill:testing", Type: "skill", Name: "testing", Desc: "Testing practices", Tags: []string{"qa"}},
+		{Ref: "skill:git-conventions", Type: "skill", Name: "git-conventions", Desc: "Git workflow", Installed: true},
+		{Ref: "subagent:architect", Type: "subagent", Name: "architect", Desc: "Architecture agent"},
+	}
+}
+
+func keys(p *Picker, msgs ...tea.KeyMsg) {
+	for _, msg := range msgs {
+		p.Update(msg)
+	}
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPicker_GroupsByType(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+
+	var refs []string
+	for _, e := range p.entries {
+		refs = append(refs, e.Ref)
+	}
+	assert.Equal(t, []string{"skill:git-conventions", "skill:testing", "subagent:architect", "stack:base"}, refs)
+}
+
+func TestPicker_CustomOrder(t *testing.T) {
+	p := NewPickerWithOrder("Pick", testEntries(), Order{
+		Types: []string{"stack", "subagent", "skill"},
+		Less:  func(a, b Entry) bool { return a.Name > b.Name },
+	})
+
+	var refs []string
+	for _, e := range p.entries {
+		refs = append(refs, e.Ref)
+	}
+	assert.Equal(t, []string{"stack:base", "subagent:architect", "skill:testing", "skill:git-conventions"}, refs)
+}
+
+func TestPicker_Select(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+
+	keys(p, runes(" "), runes("j"), runes("j"), runes(" "), runes("j"), runes("j"), runes("j"))
+	assert.Equal(t, []string{"skill:git-conventions", "subagent:architect"}, p.Selected())
+	assert.Equal(t, 3, p.cursor, "cursor stops at the last entry")
+
+	keys(p, runes("k"), runes(" "))
+	assert.Equal(t, []string{"skill:git-conventions"}, p.Selected())
+
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.True(t, p.confirmed)
+}
+
+func TestPicker_Suggested(t *testing.T) {
+	entries := testEntries()
+	entries[3].Suggested = "matches go"
+	entries[0].Suggested = "matches go, cobra"
+	p := NewPicker("Pick", entries)
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	var refs []string
+	for _, e := range p.entries {
+		refs = append(refs, e.Ref)
+	}
+	assert.Equal(t, []string{"stack:base", "subagent:architect", "skill:git-conventions", "skill:testing"}, refs, "suggestions first, in the given order")
+
+	view := p.View()
+	assert.Contains(t, view, "Suggested")
+	assert.Contains(t, view, "Suggested: matches go, cobra", "preview shows why")
+
+	// i installs the entry under the cursor without selecting it first
+	keys(p, runes("j"))
+	_, cmd := p.Update(runes("i"))
+	assert.NotNil(t, cmd)
+	assert.True(t, p.confirmed)
+	assert.Equal(t, []string{"subagent:architect"}, p.Selected())
+}
+
+func TestPicker_Search(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+
+	keys(p, runes("/"), runes("q"), runes("a"))
+	assert.Len(t, p.visible, 1)
+	e, ok := p.current()
+	assert.True(t, ok)
+	assert.Equal(t, "skill:testing", e.Ref, "matches tags")
+
+	// Leave the search field, select, then clear the search
+	keys(p, tea.KeyMsg{Type: tea.KeyEnter}, runes(" "), tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Len(t, p.visible, 4)
+	assert.False(t, p.cancelled)
+	assert.Equal(t, []string{"skill:testing"}, p.Selected())
+
+	keys(p, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.True(t, p.cancelled)
+}
+
+func TestPicker_View(t *testing.T) {
+	p := NewPicker("Pick items", testEntries())
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	view := p.View()
+	assert.Contains(t, view, "Pick items")
+	assert.Contains(t, view, "Skills")
+	assert.Contains(t, view, "Stacks")
+	assert.Contains(t, view, "(installed)")
+	assert.Contains(t, view, "Installed in this project", "preview shows the entry under the cursor")
+}
+
+func TestPicker_ViewDeprecated(t *testing.T) {
+	entries := testEntries()
+	entries[0].Deprecated = true
+	entries[0].ReplacedBy = "stack:modern"
+	p := NewPicker("Pick items", entries)
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	for i, idx := range p.visible {
+		if p.entries[idx].Deprecated {
+			p.cursor = i
+		}
+	}
+
+	view := p.View()
+	assert.Contains(t, view, "(deprecated)")
+	assert.Contains(t, view, "Deprecated, use stack:modern instead", "preview names the replacement")
+}
+
+func TestPicker_Quarantined(t *testing.T) {
+	entries := append(testEntries(), Entry{
+		Ref: "skill:broken", Type: "skill", Name: "broken", Source: "skills/broken.md",
+		Quarantined: []string{"desc: required field is missing"},
+	})
+	p := NewPicker("Pick items", entries)
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	for i, idx := range p.visible {
+		if p.entries[idx].Ref == "skill:broken" {
+			p.cursor = i
+		}
+	}
+
+	view := p.View()
+	assert.Contains(t, view, "1 quarantined", "header badge")
+	assert.Contains(t, view, "(quarantined)")
+	assert.Contains(t, view, "desc: required field is missing", "preview lists the errors")
+
+	// Quarantined entries can't be selected or installed
+	keys(p, runes(" "))
+	assert.Empty(t, p.Selected())
+	assert.Contains(t, p.View(), "skill:broken is quarantined")
+	_, cmd := p.Update(runes("i"))
+	assert.Nil(t, cmd)
+	assert.False(t, p.confirmed)
+
+	// e opens the source in the editor
+	_, cmd = p.Update(runes("e"))
+	assert.Nil(t, cmd, "no editor without Edit")
+	var edited string
+	p.Edit = func(e Entry) *exec.Cmd {
+		edited = e.Source
+		return exec.Command("true")
+	}
+	_, cmd = p.Update(runes("e"))
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "skills/broken.md", edited)
+	p.Update(editedMsg{ref: "skill:broken"})
+	assert.Contains(t, p.View(), "Edited skill:broken")
+}
+
+func TestPicker_ViewASCIIIcons(t *testing.T) {
+	p := NewPicker("Pick items", testEntries())
+	p.Icons = output.IconsASCII.Icons()
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	view := p.View()
+	assert.Contains(t, view, "up/down move * space select")
+	assert.NotContains(t, view, "↑")
+	assert.NotContains(t, view, "•")
+}
+
+func TestPicker_HelpOverlay(t *testing.T) {
+	p := NewPicker("Pick items", testEntries())
+	p.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	assert.Contains(t, p.View(), "? help")
+
+	keys(p, runes("?"))
+	view := p.View()
+	assert.Contains(t, view, "Navigation")
+	assert.Contains(t, view, "clear search or cancel")
+	assert.Contains(t, view, "leave search")
+	assert.NotContains(t, view, "Skills", "the overlay replaces the list")
+
+	// Any key closes the overlay without acting on it
+	keys(p, runes("x"))
+	assert.Empty(t, p.Selected())
+	assert.Contains(t, p.View(), "Skills")
+
+	// While searching, ? is typed into the search
+	keys(p, runes("/"), runes("?"))
+	assert.Equal(t, "?", p.search.Value())
+	assert.False(t, p.showHelp)
+}
+
+func TestPicker_PaletteJumpsToItem(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+	keys(p, runes("/"), runes("q"), runes("a"), tea.KeyMsg{Type: tea.KeyEnter}) // search hides the architect
+
+	keys(p, tea.KeyMsg{Type: tea.KeyCtrlP}, runes("a"), runes("r"), runes("c"), runes("h"))
+	assert.Contains(t, p.View(), "subagent:architect")
+	keys(p, tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Nil(t, p.palette)
+	assert.Empty(t, p.search.Value(), "the search is cleared to show the item")
+	current, ok := p.current()
+	assert.True(t, ok)
+	assert.Equal(t, "subagent:architect", current.Ref)
+	assert.False(t, p.confirmed)
+}
+
+func TestPicker_PaletteRunsAction(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+	p.Actions = []Action{{
+		Name: "build",
+		Desc: "Rebuild the manifest",
+		Run: func() ([]Entry, error) {
+			return []Entry{{Ref: "skill:new", Type: "skill", Name: "new"}}, nil
+		},
+	}}
+
+	keys(p, tea.KeyMsg{Type: tea.KeyCtrlP}, runes("bld"))
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, p.View(), "Running build...")
+
+	p.Update(cmd())
+	assert.Len(t, p.entries, 1)
+	assert.Contains(t, p.View(), "build finished, list refreshed")
+
+	keys(p, tea.KeyMsg{Type: tea.KeyCtrlP}, runes("zzz"))
+	assert.Contains(t, p.View(), "No matches")
+	keys(p, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, p.palette)
+	assert.False(t, p.cancelled, "esc only closes the palette")
+}
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("gc", "skill:git-conventions")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("cg", "skill:git-conventions")
+	assert.False(t, ok, "characters must appear in order")
+
+	prefix, _ := fuzzyScore("test", "skill:testing")
+	scattered, _ := fuzzyScore("test", "subagent:the-estimator")
+	assert.Greater(t, prefix, scattered)
+
+	short, _ := fuzzyScore("base", "stack:base")
+	long, _ := fuzzyScore("base", "stack:base-extended")
+	assert.Greater(t, short, long)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10, "…"))
+	assert.Equal(t, "a long…", truncate("a long description", 7, "…"))
+	assert.Equal(t, "a l...", truncate("a long description", 6, "..."))
+}
+
+func TestPicker_ReloadKeepsCursorAndSelection(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+	keys(p, runes("x"), runes("j"), runes("x")) // select the first two skills
+	atCursor, _ := p.current()
+	assert.Equal(t, "skill:testing", atCursor.Ref)
+
+	// git-conventions is removed, a new skill sorts before testing
+	entries := []Entry{
+		{Ref: "skill:testing", Type: "skill", Name: "testing"},
+		{Ref: "skill:linting", Type: "skill", Name: "linting"},
+		{Ref: "stack:base", Type: "stack", Name: "base"},
+	}
+	p.Update(reloadedMsg{entries: entries})
+
+	current, ok := p.current()
+	assert.True(t, ok)
+	assert.Equal(t, "skill:testing", current.Ref)
+	assert.Equal(t, []string{"skill:testing"}, p.Selected())
+	assert.Contains(t, p.View(), "Registry changed, list refreshed")
+
+	keys(p, runes("j"))
+	assert.NotContains(t, p.View(), "Registry changed")
+}
+
+func TestPicker_ReloadPolling(t *testing.T) {
+	p := NewPicker("Pick", testEntries())
+	assert.Nil(t, p.Init(), "no polling without Reload")
+
+	calls := 0
+	p.Reload = func() ([]Entry, error) {
+		calls++
+		return nil, nil
+	}
+	assert.NotNil(t, p.Init())
+
+	_, cmd := p.Update(refreshTickMsg{})
+	msg := cmd()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, reloadedMsg{}, msg)
+
+	_, cmd = p.Update(msg)
+	assert.NotNil(t, cmd, "schedules the next check")
+	assert.Len(t, p.entries, 4, "unchanged entries are kept")
+}
+
+func TestPicker_Events(t *testing.T) {
+	events := make(chan Event, 1)
+	p := NewPicker("Pick", testEntries())
+	p.Events = events
+	assert.NotNil(t, p.Init())
+
+	installed := testEntries()
+	installed[1].Installed = true
+	events <- Event{Notice: "install finished, list refreshed", Entries: installed}
+	_, cmd := p.Update(p.waitForEvent()())
+	assert.NotNil(t, cmd, "waits for the next event")
+	assert.Contains(t, p.View(), "install finished, list refreshed")
+	for _, e := range p.entries {
+		assert.Equal(t, e.Ref == "skill:testing" || e.Ref == "skill:git-conventions", e.Installed, e.Ref)
+	}
+
+	events <- Event{Notice: "build failed: broken"}
+	p.Update(p.waitForEvent()())
+	assert.Contains(t, p.View(), "build failed: broken")
+	assert.Len(t, p.entries, 4, "entries are kept without new ones")
+
+	close(events)
+	assert.Nil(t, p.waitForEvent()())
+}
@@ -0,0 +1,315 @@
+// Package upgrade implements self-update from GitHub releases.
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultReleaseURL is the GitHub API endpoint for the latest release.
+	DefaultReleaseURL = "https://api.github.com/repos/okto-digital/regis3/releases/latest"
+
+	// ChecksumsFile is the release asset listing SHA256 sums of all archives.
+	ChecksumsFile = "checksums.txt"
+
+	// BinaryName is the executable name inside release archives.
+	BinaryName = "regis3"
+)
+
+// Release is a GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release version without a leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (*Asset, bool) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// Client fetches releases and their assets.
+type Client struct {
+	// ReleaseURL is the API endpoint returning the latest release.
+	ReleaseURL string
+
+	// HTTPClient performs requests.
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the official release feed.
+func NewClient() *Client {
+	return &Client{
+		ReleaseURL: DefaultReleaseURL,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// LatestRelease fetches the latest published release.
+func (c *Client) LatestRelease() (*Release, error) {
+	data, err := c.get(c.ReleaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// Download fetches an asset's content.
+func (c *Client) Download(asset *Asset) ([]byte, error) {
+	data, err := c.get(asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+// get performs a GET request and returns the body.
+func (c *Client) get(url string) ([]byte, error) {
+	resp, err := c.HTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ArchiveName returns the release archive name for a platform, matching the
+// goreleaser name template.
+func ArchiveName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.%s", BinaryName, strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// CurrentArchiveName returns the archive name for the running platform.
+func CurrentArchiveName(version string) string {
+	return ArchiveName(version, runtime.GOOS, runtime.GOARCH)
+}
+
+// CompareVersions compares two semantic versions (with or without "v").
+// Returns -1 if a < b, 0 if equal, 1 if a > b. A pre-release sorts before
+// its release. Unparseable versions (e.g. "dev") sort before everything.
+func CompareVersions(a, b string) int {
+	pa, preA, okA := parseVersion(a)
+	pb, preB, okB := parseVersion(b)
+
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// parseVersion splits a version into major/minor/patch and pre-release.
+func parseVersion(v string) ([3]int, string, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, _ := strings.Cut(v, "-")
+
+	fields := strings.Split(core, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, "", false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, "", false
+		}
+		parts[i] = n
+	}
+	return parts, pre, true
+}
+
+// ParseChecksums parses a checksums.txt file ("<sha256>  <file>" per line)
+// into a map from file name to lowercase hex digest.
+func ParseChecksums(checksums []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+		}
+	}
+	return sums
+}
+
+// VerifyChecksum checks data against its entry in a checksums.txt file.
+func VerifyChecksum(data []byte, name string, checksums []byte) error {
+	want, ok := ParseChecksums(checksums)[name]
+	if !ok {
+		return fmt.Errorf("no checksum listed for %s", name)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+	return nil
+}
+
+// ExtractBinary returns the regis3 executable from a release archive.
+func ExtractBinary(archive []byte, archiveName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		return extractZip(archive, BinaryName+".exe")
+	}
+	return extractTarGz(archive, BinaryName)
+}
+
+// extractTarGz reads a single file from a .tar.gz archive.
+func extractTarGz(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// extractZip reads a single file from a .zip archive.
+func extractZip(archive []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if filepath.Base(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// ReplaceExecutable atomically replaces the executable at path with data.
+// The old binary is moved aside first, which also works for a running
+// executable on Windows.
+func ReplaceExecutable(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat executable: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".regis3-upgrade-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new executable: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new executable: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0111); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	oldPath := path + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		return fmt.Errorf("failed to move old executable: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Rename(oldPath, path)
+		return fmt.Errorf("failed to install new executable: %w", err)
+	}
+	os.Remove(oldPath) // may fail on Windows while running; harmless
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.2.0", "1.10.0", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc1", 1},
+		{"1.0", "1.0.0", 0},
+		{"dev", "0.1.0", -1},
+		{"0.1.0", "dev", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	assert.Equal(t, "regis3_1.2.3_linux_amd64.tar.gz", ArchiveName("v1.2.3", "linux", "amd64"))
+	assert.Equal(t, "regis3_1.2.3_windows_arm64.zip", ArchiveName("1.2.3", "windows", "arm64"))
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive contents")
+	sum := sha256.Sum256(data)
+	checksums := []byte(fmt.Sprintf("%s  regis3_1.0.0_linux_amd64.tar.gz\n", hex.EncodeToString(sum[:])))
+
+	assert.NoError(t, VerifyChecksum(data, "regis3_1.0.0_linux_amd64.tar.gz", checksums))
+	assert.ErrorContains(t, VerifyChecksum([]byte("tampered"), "regis3_1.0.0_linux_amd64.tar.gz", checksums), "checksum mismatch")
+	assert.ErrorContains(t, VerifyChecksum(data, "other.tar.gz", checksums), "no checksum listed")
+}
+
+func TestExtractBinary(t *testing.T) {
+	archive := makeTarGz(t, map[string]string{
+		"README.md": "readme",
+		"regis3":    "binary",
+	})
+
+	binary, err := ExtractBinary(archive, "regis3_1.0.0_linux_amd64.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(binary))
+
+	_, err = ExtractBinary(makeTarGz(t, map[string]string{"README.md": "readme"}), "x.tar.gz")
+	assert.ErrorContains(t, err, "not found in archive")
+}
+
+func TestClient_LatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprint(w, `{"tag_name":"v1.4.0","assets":[{"name":"checksums.txt","browser_download_url":"http://`+r.Host+`/checksums.txt"}]}`)
+		case "/checksums.txt":
+			fmt.Fprint(w, "abc  file\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ReleaseURL = server.URL + "/latest"
+
+	release, err := client.LatestRelease()
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", release.Version())
+
+	asset, ok := release.Asset(ChecksumsFile)
+	require.True(t, ok)
+	data, err := client.Download(asset)
+	require.NoError(t, err)
+	assert.Equal(t, "abc  file\n", string(data))
+
+	_, err = client.Download(&Asset{Name: "missing", URL: server.URL + "/missing"})
+	assert.Error(t, err)
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regis3")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0755))
+
+	require.NoError(t, ReplaceExecutable(path, []byte("new")))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.NoFileExists(t, path+".old")
+}
+
+// makeTarGz builds an in-memory .tar.gz archive.
+func makeTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0755,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
@@ -1,4 +1,4 @@
-// Package frontmatter provides utilities for parsing YAML frontmatter from markdown files.
+// Package frontmatter provides utilities for parsing YAML and TOML frontmatter from markdown files.
 package frontmatter
 
 import (
@@ -8,28 +8,60 @@ import (
 	"io"
 	"strings"
 
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	// ErrNoFrontmatter indicates the file has no YAML frontmatter block.
+	// ErrNoFrontmatter indicates the file has no frontmatter block.
 	ErrNoFrontmatter = errors.New("no frontmatter found")
 	// ErrUnclosedFrontmatter indicates the frontmatter block was not closed.
 	ErrUnclosedFrontmatter = errors.New("unclosed frontmatter block")
 )
 
-const delimiter = "---"
+// Format is the syntax of a frontmatter block.
+type Format string
+
+const (
+	// FormatYAML is frontmatter enclosed by --- delimiters. The block may
+	// also be closed by the YAML document end marker (...).
+	FormatYAML Format = "yaml"
+	// FormatTOML is frontmatter enclosed by +++ delimiters.
+	FormatTOML Format = "toml"
+)
+
+// Formats are the supported frontmatter formats.
+var Formats = []Format{FormatYAML, FormatTOML}
+
+// IsFormat reports whether f is a supported frontmatter format.
+func IsFormat(f Format) bool {
+	for _, format := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	delimiter     = "---"
+	yamlEnd       = "..."
+	tomlDelimiter = "+++"
+)
 
 // Document represents a parsed markdown file with frontmatter.
 type Document struct {
-	// Frontmatter is the raw YAML frontmatter string.
+	// Frontmatter is the raw frontmatter string.
 	Frontmatter string
+	// Format is the syntax of the frontmatter.
+	Format Format
 	// Body is the content after the frontmatter.
 	Body string
 }
 
 // Parse extracts frontmatter and body from a markdown document.
-// The frontmatter must be at the start of the file, enclosed by --- delimiters.
+// The frontmatter must be at the start of the file, enclosed by --- (YAML)
+// or +++ (TOML) delimiters.
 func Parse(r io.Reader) (*Document, error) {
 	scanner := bufio.NewScanner(r)
 
@@ -41,7 +73,13 @@ func Parse(r io.Reader) (*Document, error) {
 		return nil, ErrNoFrontmatter
 	}
 
-	if strings.TrimSpace(scanner.Text()) != delimiter {
+	var format Format
+	switch strings.TrimSpace(scanner.Text()) {
+	case delimiter:
+		format = FormatYAML
+	case tomlDelimiter:
+		format = FormatTOML
+	default:
 		return nil, ErrNoFrontmatter
 	}
 
@@ -51,7 +89,7 @@ func Parse(r io.Reader) (*Document, error) {
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.TrimSpace(line) == delimiter {
+		if isClosing(format, line) {
 			found = true
 			break
 		}
@@ -80,10 +118,134 @@ func Parse(r io.Reader) (*Document, error) {
 
 	return &Document{
 		Frontmatter: frontmatter.String(),
+		Format:      format,
 		Body:        body.String(),
 	}, nil
 }
 
+// isClosing reports whether line closes a frontmatter block of format. The
+// YAML end marker only counts unindented, so block scalars may contain it.
+func isClosing(format Format, line string) bool {
+	if format == FormatTOML {
+		return strings.TrimSpace(line) == tomlDelimiter
+	}
+	return strings.TrimSpace(line) == delimiter || strings.TrimRight(line, " \t\r") == yamlEnd
+}
+
+// Node returns the frontmatter as a YAML document node. TOML frontmatter is
+// converted, so callers can treat both formats alike. Empty frontmatter
+// yields an empty node.
+func (d *Document) Node() (*yaml.Node, error) {
+	data := []byte(d.Frontmatter)
+	if d.Format == FormatTOML {
+		var values map[string]interface{}
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return &yaml.Node{}, nil
+		}
+		converted, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// Decode unmarshals the frontmatter into v, whatever its format. The struct
+// should have yaml tags for proper field mapping.
+func (d *Document) Decode(v interface{}) error {
+	node, err := d.Node()
+	if err != nil || node.Kind == 0 {
+		return err
+	}
+	return node.Decode(v)
+}
+
+// DecodeKey unmarshals the value under key into v. Dotted keys address
+// nested mappings (e.g. meta.regis3). It reports false if the key is not
+// present.
+func (d *Document) DecodeKey(key string, v interface{}) (bool, error) {
+	node, err := d.Node()
+	if err != nil {
+		return false, err
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, part := range strings.Split(key, ".") {
+		node = lookup(node, part)
+		if node == nil {
+			return false, nil
+		}
+	}
+	return true, node.Decode(v)
+}
+
+// Position is a location in a file; lines and columns start at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// KeyPosition returns the position of key in the file, addressed like in
+// DecodeKey. Positions are only known for YAML frontmatter; it reports
+// false for TOML and for keys that are not present.
+func (d *Document) KeyPosition(key string) (Position, bool) {
+	if d.Format != FormatYAML {
+		return Position{}, false
+	}
+	node, err := d.Node()
+	if err != nil {
+		return Position{}, false
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	var keyNode *yaml.Node
+	for _, part := range strings.Split(key, ".") {
+		keyNode, node = lookupKey(node, part)
+		if node == nil {
+			return Position{}, false
+		}
+	}
+	// Lines are counted from the opening delimiter
+	return Position{Line: keyNode.Line + 1, Column: keyNode.Column}, true
+}
+
+// BodyLine returns the line of the file the body starts on.
+func (d *Document) BodyLine() int {
+	// The frontmatter lines and both delimiters come first
+	return strings.Count(d.Frontmatter, "\n") + 3
+}
+
+// lookup returns the value of key in a mapping node, or nil.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	_, value := lookupKey(node, key)
+	return value
+}
+
+// lookupKey returns the key and value nodes of key in a mapping node, or
+// nils.
+func lookupKey(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
 // ParseBytes parses frontmatter from a byte slice.
 func ParseBytes(data []byte) (*Document, error) {
 	return Parse(bytes.NewReader(data))
@@ -102,7 +264,7 @@ func Unmarshal(r io.Reader, v interface{}) (*Document, error) {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal([]byte(doc.Frontmatter), v); err != nil {
+	if err := doc.Decode(v); err != nil {
 		return doc, err
 	}
 
@@ -53,6 +53,45 @@ Paragraph two.
 			wantBody: "# Heading\n\nParagraph one.\n\nParagraph two.\n",
 			wantErr:  nil,
 		},
+		{
+			name: "yaml document end marker",
+			input: `---
+title: Hello
+...
+Body.
+`,
+			wantFM:   "title: Hello\n",
+			wantBody: "Body.\n",
+		},
+		{
+			name: "indented end marker in block scalar",
+			input: `---
+desc: |
+  ...
+---
+Body.
+`,
+			wantFM:   "desc: |\n  ...\n",
+			wantBody: "Body.\n",
+		},
+		{
+			name: "toml frontmatter",
+			input: `+++
+title = "Hello"
++++
+Body.
+`,
+			wantFM:   "title = \"Hello\"\n",
+			wantBody: "Body.\n",
+		},
+		{
+			name: "unclosed toml frontmatter",
+			input: `+++
+title = "Hello"
+---
+`,
+			wantErr: ErrUnclosedFrontmatter,
+		},
 		{
 			name:    "no frontmatter",
 			input:   "Just regular content.",
@@ -179,6 +218,33 @@ Content.
 			},
 			wantBody: "Content.\n",
 		},
+		{
+			name: "toml frontmatter",
+			input: `+++
+[regis3]
+type = "skill"
+name = "toml-skill"
+desc = "A skill with TOML frontmatter"
+deps = ["skill:base"]
++++
+Content.
+`,
+			wantMeta: Regis3Meta{
+				Type: "skill",
+				Name: "toml-skill",
+				Desc: "A skill with TOML frontmatter",
+				Deps: []string{"skill:base"},
+			},
+			wantBody: "Content.\n",
+		},
+		{
+			name: "invalid toml",
+			input: `+++
+[regis3
++++
+`,
+			wantErr: true,
+		},
 		{
 			name: "inline yaml format",
 			input: `---
@@ -227,3 +293,96 @@ Body.
 	assert.Equal(t, "key: value\n", doc.Frontmatter)
 	assert.Equal(t, "Body.\n", doc.Body)
 }
+
+func TestDocument_DecodeKey(t *testing.T) {
+	type meta struct {
+		Type string `yaml:"type"`
+		Name string `yaml:"name"`
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		key       string
+		wantFound bool
+		wantMeta  meta
+	}{
+		{
+			name:      "top-level key",
+			input:     "---\nregis:\n  type: skill\n  name: legacy\n---\n",
+			key:       "regis",
+			wantFound: true,
+			wantMeta:  meta{Type: "skill", Name: "legacy"},
+		},
+		{
+			name:      "nested key",
+			input:     "---\nmeta:\n  regis3:\n    type: skill\n    name: nested\n---\n",
+			key:       "meta.regis3",
+			wantFound: true,
+			wantMeta:  meta{Type: "skill", Name: "nested"},
+		},
+		{
+			name:      "nested toml table",
+			input:     "+++\n[meta.regis3]\ntype = \"skill\"\nname = \"toml\"\n+++\n",
+			key:       "meta.regis3",
+			wantFound: true,
+			wantMeta:  meta{Type: "skill", Name: "toml"},
+		},
+		{
+			name:  "missing key",
+			input: "---\ntitle: Hello\n---\n",
+			key:   "meta.regis3",
+		},
+		{
+			name:  "empty frontmatter",
+			input: "---\n---\n",
+			key:   "regis3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			var m meta
+			found, err := doc.DecodeKey(tt.key, &m)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantMeta, m)
+		})
+	}
+}
+
+func TestDocument_KeyPosition(t *testing.T) {
+	yamlDoc := "---\ntitle: Hello\nmeta:\n  regis3:\n    type: skill\n    tags: [a, b]\n---\n\n# Body\n"
+
+	tests := []struct {
+		name      string
+		input     string
+		key       string
+		wantPos   Position
+		wantFound bool
+	}{
+		{name: "top-level key", input: yamlDoc, key: "title", wantPos: Position{Line: 2, Column: 1}, wantFound: true},
+		{name: "nested key", input: yamlDoc, key: "meta.regis3.tags", wantPos: Position{Line: 6, Column: 5}, wantFound: true},
+		{name: "block", input: yamlDoc, key: "meta.regis3", wantPos: Position{Line: 4, Column: 3}, wantFound: true},
+		{name: "missing key", input: yamlDoc, key: "meta.regis3.deps"},
+		{name: "TOML", input: "+++\n[regis3]\ntype = \"skill\"\n+++\n", key: "regis3.type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			pos, found := doc.KeyPosition(tt.key)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantPos, pos)
+		})
+	}
+
+	doc, err := ParseString(yamlDoc)
+	require.NoError(t, err)
+	assert.Equal(t, 8, doc.BodyLine())
+}
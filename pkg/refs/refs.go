@@ -0,0 +1,178 @@
+// Package refs parses and formats regis3 item references.
+//
+// A reference has the form [registry/]type:name[@version], for example
+// skill:testing, team/skill:testing or skill:testing@1.2.0. The type may be
+// omitted (testing) where the name alone is unambiguous.
+package refs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalid indicates a malformed reference.
+var ErrInvalid = errors.New("invalid reference")
+
+// Ref is a parsed item reference.
+type Ref struct {
+	// Registry names the registry holding the item, or "" for the local one.
+	Registry string
+
+	// Type is the item type, or "" when omitted.
+	Type string
+
+	// Name is the item name.
+	Name string
+
+	// Version is the requested version, or "" for the current one.
+	Version string
+}
+
+// Parse parses and normalizes a reference. Surrounding space is trimmed,
+// the registry and type are lowercased and a "v" before a version number
+// is dropped. Names keep their case.
+func Parse(s string) (Ref, error) {
+	var r Ref
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return r, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest, r.Version = rest[:i], strings.TrimSpace(rest[i+1:])
+		if r.Version == "" {
+			return r, fmt.Errorf("%w: %q has an empty version", ErrInvalid, s)
+		}
+		if len(r.Version) > 1 && (r.Version[0] == 'v' || r.Version[0] == 'V') && unicode.IsDigit(rune(r.Version[1])) {
+			r.Version = r.Version[1:]
+		}
+	}
+
+	colon := strings.Index(rest, ":")
+	if i := strings.LastIndex(rest, "/"); i >= 0 && (colon < 0 || i < colon) {
+		r.Registry, rest = strings.ToLower(rest[:i]), rest[i+1:]
+		colon = strings.Index(rest, ":")
+		if r.Registry == "" {
+			return r, fmt.Errorf("%w: %q has an empty registry", ErrInvalid, s)
+		}
+	}
+
+	if colon >= 0 {
+		r.Type, r.Name = strings.ToLower(rest[:colon]), rest[colon+1:]
+		if r.Type == "" {
+			return r, fmt.Errorf("%w: %q has an empty type", ErrInvalid, s)
+		}
+	} else {
+		r.Name = rest
+	}
+	if r.Name == "" {
+		return r, fmt.Errorf("%w: %q has no name", ErrInvalid, s)
+	}
+
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Validate checks that each part of the reference is well-formed: the
+// registry and type are made of letters, digits, "-", "_" and ".", and
+// names and versions contain no spaces, separators or reference
+// punctuation.
+func (r Ref) Validate() error {
+	if r.Registry != "" && !isWord(r.Registry) {
+		return fmt.Errorf("%w: registry %q must contain only letters, digits, '-', '_' and '.'", ErrInvalid, r.Registry)
+	}
+	if r.Type != "" && !isWord(r.Type) {
+		return fmt.Errorf("%w: type %q must contain only letters, digits, '-', '_' and '.'", ErrInvalid, r.Type)
+	}
+	if r.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalid)
+	}
+	if r.Name == "." || r.Name == ".." || strings.ContainsFunc(r.Name, isReserved) {
+		return fmt.Errorf("%w: name %q must not contain spaces or any of / \\ : @", ErrInvalid, r.Name)
+	}
+	if strings.ContainsFunc(r.Version, isReserved) {
+		return fmt.Errorf("%w: version %q must not contain spaces or any of / \\ : @", ErrInvalid, r.Version)
+	}
+	return nil
+}
+
+// String formats the reference as [registry/]type:name[@version].
+func (r Ref) String() string {
+	s := r.ID()
+	if r.Registry != "" {
+		s = r.Registry + "/" + s
+	}
+	if r.Version != "" {
+		s += "@" + r.Version
+	}
+	return s
+}
+
+// ID returns the local item identifier: type:name, or the name alone when
+// the type was omitted.
+func (r Ref) ID() string {
+	if r.Type == "" {
+		return r.Name
+	}
+	return Join(r.Type, r.Name)
+}
+
+// IsLocal reports whether the reference names the current version of an
+// item in the local registry.
+func (r Ref) IsLocal() bool {
+	return r.Registry == "" && r.Version == ""
+}
+
+// Join returns the item identifier type:name.
+func Join(itemType, name string) string {
+	return itemType + ":" + name
+}
+
+// Split splits an item identifier type:name. It returns false when id has
+// no type.
+func Split(id string) (itemType, name string, ok bool) {
+	return strings.Cut(id, ":")
+}
+
+// TypeOf returns the type of an item identifier, or "" when it has none.
+func TypeOf(id string) string {
+	itemType, _, ok := Split(id)
+	if !ok {
+		return ""
+	}
+	return itemType
+}
+
+// NameOf returns the name of an item identifier, or id itself when it has
+// no type.
+func NameOf(id string) string {
+	if _, name, ok := Split(id); ok {
+		return name
+	}
+	return id
+}
+
+// IsRegistryName reports whether s can name a registry in references:
+// lowercase letters, digits, "-", "_" and ".".
+func IsRegistryName(s string) bool {
+	return s != "" && isWord(s) && s == strings.ToLower(s)
+}
+
+// isWord reports whether s is made of letters, digits, "-", "_" and ".".
+func isWord(s string) bool {
+	for _, c := range s {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// isReserved reports whether c can't appear in names and versions.
+func isReserved(c rune) bool {
+	return unicode.IsSpace(c) || unicode.IsControl(c) || strings.ContainsRune(`/\:@`, c)
+}
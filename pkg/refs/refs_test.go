@@ -0,0 +1,89 @@
+package refs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Ref
+		str     string
+		wantErr bool
+	}{
+		{input: "skill:testing", want: Ref{Type: "skill", Name: "testing"}, str: "skill:testing"},
+		{input: "testing", want: Ref{Name: "testing"}, str: "testing"},
+		{input: "  Skill:Testing ", want: Ref{Type: "skill", Name: "Testing"}, str: "skill:Testing"},
+		{input: "skill:testing@1.2.0", want: Ref{Type: "skill", Name: "testing", Version: "1.2.0"}, str: "skill:testing@1.2.0"},
+		{input: "skill:testing@v2", want: Ref{Type: "skill", Name: "testing", Version: "2"}, str: "skill:testing@2"},
+		{input: "skill:testing@vnext", want: Ref{Type: "skill", Name: "testing", Version: "vnext"}, str: "skill:testing@vnext"},
+		{input: "Team/skill:testing", want: Ref{Registry: "team", Type: "skill", Name: "testing"}, str: "team/skill:testing"},
+		{input: "team/testing@1.0", want: Ref{Registry: "team", Name: "testing", Version: "1.0"}, str: "team/testing@1.0"},
+		{input: "capability:git-workflow", want: Ref{Type: "capability", Name: "git-workflow"}, str: "capability:git-workflow"},
+		{input: "", wantErr: true},
+		{input: "skill:", wantErr: true},
+		{input: ":testing", wantErr: true},
+		{input: "skill:testing@", wantErr: true},
+		{input: "/skill:testing", wantErr: true},
+		{input: "skill:a/b", wantErr: true},
+		{input: "skill:a:b", wantErr: true},
+		{input: "skill:two words", wantErr: true},
+		{input: "skill:..", wantErr: true},
+		{input: "org/team/skill:testing", wantErr: true},
+		{input: "sk ill:testing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.str, got.String())
+
+			// Formatting round-trips
+			again, err := Parse(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestRef_IDAndIsLocal(t *testing.T) {
+	assert.Equal(t, "skill:testing", Ref{Type: "skill", Name: "testing", Version: "1"}.ID())
+	assert.Equal(t, "testing", Ref{Name: "testing"}.ID())
+	assert.True(t, Ref{Type: "skill", Name: "testing"}.IsLocal())
+	assert.False(t, Ref{Type: "skill", Name: "testing", Version: "1"}.IsLocal())
+	assert.False(t, Ref{Registry: "team", Name: "testing"}.IsLocal())
+}
+
+func TestSplit(t *testing.T) {
+	itemType, name, ok := Split("skill:testing")
+	assert.True(t, ok)
+	assert.Equal(t, "skill", itemType)
+	assert.Equal(t, "testing", name)
+
+	_, _, ok = Split("testing")
+	assert.False(t, ok)
+
+	assert.Equal(t, "skill", TypeOf("skill:testing"))
+	assert.Equal(t, "", TypeOf("testing"))
+	assert.Equal(t, "testing", NameOf("skill:testing"))
+	assert.Equal(t, "testing", NameOf("testing"))
+	assert.Equal(t, "skill:testing", Join("skill", "testing"))
+}
+
+func TestIsRegistryName(t *testing.T) {
+	assert.True(t, IsRegistryName("team"))
+	assert.True(t, IsRegistryName("team-2.x"))
+	assert.False(t, IsRegistryName(""))
+	assert.False(t, IsRegistryName("Team"))
+	assert.False(t, IsRegistryName("my team"))
+	assert.False(t, IsRegistryName("team/x"))
+}
@@ -0,0 +1,287 @@
+// Package registrytest generates synthetic regis3 registries for tests and
+// benchmarks.
+//
+// Generation is deterministic: the same Options always produce the same
+// registry, so a failing or slow scenario can be reproduced from its seed.
+//
+//	reg := registrytest.Generate(registrytest.Options{Seed: 42, Items: map[string]int{"skill": 500}, MaxDeps: 3})
+//	if err := reg.Write(t.TempDir()); err != nil {
+//		t.Fatal(err)
+//	}
+package registrytest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultItems is the number of items per type generated when
+// Options.Items is nil.
+var DefaultItems = map[string]int{
+	"skill":      10,
+	"subagent":   3,
+	"command":    3,
+	"philosophy": 2,
+}
+
+// Options configures the generated registry.
+type Options struct {
+	// Seed seeds every random choice.
+	Seed int64
+
+	// Items is the number of items to generate per type, e.g.
+	// {"skill": 100}. Nil means DefaultItems. Stacks are set with Stacks.
+	Items map[string]int
+
+	// Stacks is the number of stacks. Each depends on two to five of the
+	// other items.
+	Stacks int
+
+	// MaxDeps is the maximum number of dependencies of each item other than
+	// a stack. Dependencies only point to items generated earlier, so the
+	// graph is acyclic unless Cycles is set.
+	MaxDeps int
+
+	// Cycles is the number of dependency cycles to add. Each links two or
+	// three items; it needs at least three items other than stacks.
+	Cycles int
+
+	// BodyLines is the number of paragraph lines in each item body, in
+	// addition to its heading.
+	BodyLines int
+}
+
+// Item is a generated registry item.
+type Item struct {
+	Type  string
+	Name  string
+	Desc  string
+	Tags  []string
+	Deps  []string
+	Order int
+	Body  string
+}
+
+// Ref returns the item reference (type:name).
+func (i Item) Ref() string {
+	return i.Type + ":" + i.Name
+}
+
+// Path returns the item's file path relative to the registry root.
+func (i Item) Path() string {
+	return filepath.Join(typeDir(i.Type), i.Name+".md")
+}
+
+// Markdown returns the item file: a regis3 frontmatter block and the body.
+func (i Item) Markdown() []byte {
+	meta := struct {
+		Type  string   `yaml:"type"`
+		Name  string   `yaml:"name"`
+		Desc  string   `yaml:"desc"`
+		Tags  []string `yaml:"tags,omitempty"`
+		Deps  []string `yaml:"deps,omitempty"`
+		Order int      `yaml:"order,omitempty"`
+	}{i.Type, i.Name, i.Desc, i.Tags, i.Deps, i.Order}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	// Marshalling a struct of strings and ints cannot fail
+	_ = enc.Encode(map[string]any{"regis3": meta})
+	_ = enc.Close()
+	buf.WriteString("---\n")
+	buf.WriteString(i.Body)
+	return buf.Bytes()
+}
+
+// Registry is a generated registry.
+type Registry struct {
+	// Items are the generated items; stacks come last.
+	Items []Item
+
+	// Cycles lists the added dependency cycles as item references. Each
+	// item depends on the next and the last on the first.
+	Cycles [][]string
+}
+
+// Item returns the item with the given reference.
+func (r *Registry) Item(ref string) (Item, bool) {
+	for _, item := range r.Items {
+		if item.Ref() == ref {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Refs returns the references of all items in generation order.
+func (r *Registry) Refs() []string {
+	refs := make([]string, len(r.Items))
+	for i, item := range r.Items {
+		refs[i] = item.Ref()
+	}
+	return refs
+}
+
+// Write writes the item files below dir, creating directories as needed.
+func (r *Registry) Write(dir string) error {
+	for _, item := range r.Items {
+		path := filepath.Join(dir, item.Path())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, item.Markdown(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Generate generates a registry.
+func Generate(opts Options) *Registry {
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+	counts := opts.Items
+	if counts == nil {
+		counts = DefaultItems
+	}
+
+	// Map iteration order is random; generate types in a fixed order
+	types := make([]string, 0, len(counts))
+	for itemType := range counts {
+		if itemType != "stack" {
+			types = append(types, itemType)
+		}
+	}
+	sort.Strings(types)
+
+	reg := &Registry{}
+	order := 0
+	for _, itemType := range types {
+		for n := 1; n <= counts[itemType]; n++ {
+			item := newItem(rng, itemType, n, opts.BodyLines)
+			if isMergeType(itemType) {
+				order += 10
+				item.Order = order
+			}
+			if opts.MaxDeps > 0 && len(reg.Items) > 0 {
+				for _, dep := range pick(rng, reg.Items, rng.IntN(opts.MaxDeps+1)) {
+					item.Deps = append(item.Deps, dep.Ref())
+				}
+			}
+			reg.Items = append(reg.Items, item)
+		}
+	}
+
+	members := reg.Items
+	for c := 0; c < opts.Cycles && len(members) >= 3; c++ {
+		cycle := pick(rng, members, 2+rng.IntN(2))
+		refs := make([]string, len(cycle))
+		for i, item := range cycle {
+			refs[i] = item.Ref()
+		}
+		for i, ref := range refs {
+			reg.addDep(ref, refs[(i+1)%len(refs)])
+		}
+		reg.Cycles = append(reg.Cycles, refs)
+	}
+
+	for n := 1; n <= opts.Stacks && len(members) > 0; n++ {
+		item := newItem(rng, "stack", n, opts.BodyLines)
+		for _, dep := range pick(rng, members, 2+rng.IntN(4)) {
+			item.Deps = append(item.Deps, dep.Ref())
+		}
+		reg.Items = append(reg.Items, item)
+	}
+
+	return reg
+}
+
+// addDep makes the item from depend on the item to, once.
+func (r *Registry) addDep(from, to string) {
+	for i := range r.Items {
+		if r.Items[i].Ref() != from {
+			continue
+		}
+		for _, dep := range r.Items[i].Deps {
+			if dep == to {
+				return
+			}
+		}
+		r.Items[i].Deps = append(r.Items[i].Deps, to)
+		return
+	}
+}
+
+var (
+	topics = []string{"testing", "review", "security", "docs", "release", "refactor", "api", "database", "frontend", "logging"}
+	words  = []string{"consistent", "careful", "small", "reviewed", "documented", "tested", "typed", "readable", "fast", "safe"}
+)
+
+// newItem generates the n-th item of a type.
+func newItem(rng *rand.Rand, itemType string, n, bodyLines int) Item {
+	topic := topics[rng.IntN(len(topics))]
+	name := fmt.Sprintf("%s-%s-%d", topic, itemType, n)
+
+	tags := []string{topic}
+	if extra := topics[rng.IntN(len(topics))]; extra != topic {
+		tags = append(tags, extra)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "# %s %s %d\n\n", strings.ToUpper(topic[:1])+topic[1:], itemType, n)
+	if itemType == "subagent" {
+		fmt.Fprintf(&body, "You are a %s specialist.\n\n", topic)
+	}
+	for l := 0; l < bodyLines; l++ {
+		fmt.Fprintf(&body, "Keep %s work %s and %s.\n", topic, words[rng.IntN(len(words))], words[rng.IntN(len(words))])
+	}
+
+	return Item{
+		Type: itemType,
+		Name: name,
+		Desc: fmt.Sprintf("Synthetic %s %s number %d", topic, itemType, n),
+		Tags: tags,
+		Body: body.String(),
+	}
+}
+
+// pick returns n distinct items chosen at random, in their original order.
+func pick(rng *rand.Rand, items []Item, n int) []Item {
+	n = min(n, len(items))
+	chosen := rng.Perm(len(items))[:n]
+	sort.Ints(chosen)
+	result := make([]Item, n)
+	for i, idx := range chosen {
+		result[i] = items[idx]
+	}
+	return result
+}
+
+// typeDir returns the registry directory conventionally holding a type.
+func typeDir(itemType string) string {
+	switch itemType {
+	case "subagent":
+		return "agents"
+	case "philosophy":
+		return "philosophies"
+	}
+	return itemType + "s"
+}
+
+// isMergeType reports whether items of the type are merged into CLAUDE.md
+// and therefore need an order.
+func isMergeType(itemType string) bool {
+	switch itemType {
+	case "project", "philosophy", "ruleset":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package registrytest
+
+import (
+	"testing"
+
+	"github.com/okto-digital/regis3/internal/registry"
+	"github.com/okto-digital/regis3/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	opts := Options{Seed: 7, Items: map[string]int{"skill": 20, "subagent": 5}, Stacks: 3, MaxDeps: 3, Cycles: 2, BodyLines: 4}
+
+	assert.Equal(t, Generate(opts), Generate(opts))
+
+	other := opts
+	other.Seed = 8
+	assert.NotEqual(t, Generate(opts), Generate(other))
+}
+
+func TestGenerate_Counts(t *testing.T) {
+	reg := Generate(Options{Items: map[string]int{"skill": 4, "philosophy": 2}, Stacks: 2})
+	require.Len(t, reg.Items, 8)
+
+	counts := map[string]int{}
+	for _, item := range reg.Items {
+		counts[item.Type]++
+		switch item.Type {
+		case "philosophy":
+			assert.NotZero(t, item.Order, item.Ref())
+		case "stack":
+			assert.NotEmpty(t, item.Deps, item.Ref())
+		default:
+			assert.Empty(t, item.Deps, item.Ref())
+		}
+	}
+	assert.Equal(t, map[string]int{"skill": 4, "philosophy": 2, "stack": 2}, counts)
+	assert.Equal(t, "stack", reg.Items[len(reg.Items)-1].Type)
+}
+
+func TestRegistry_Write(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		wantCycle bool
+	}{
+		{
+			name: "acyclic",
+			opts: Options{Seed: 1, Items: map[string]int{"skill": 30, "command": 5, "philosophy": 3}, Stacks: 4, MaxDeps: 3, BodyLines: 2},
+		},
+		{
+			name:      "with cycles",
+			opts:      Options{Seed: 2, Items: map[string]int{"skill": 10}, Cycles: 1},
+			wantCycle: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			reg := Generate(tt.opts)
+			require.NoError(t, reg.Write(dir))
+
+			result, err := registry.BuildRegistry(dir)
+			require.NoError(t, err)
+			assert.Empty(t, result.ScanErrors)
+			assert.Empty(t, result.Validation.Errors())
+			require.Len(t, result.Manifest.Items, len(reg.Items))
+			for _, ref := range reg.Refs() {
+				assert.Contains(t, result.Manifest.Items, ref)
+			}
+
+			r := resolver.NewResolver(result.Manifest)
+			assert.Equal(t, tt.wantCycle, r.HasCycle())
+			if tt.wantCycle {
+				require.Len(t, reg.Cycles, 1)
+				cycle, err := r.FindCycle()
+				require.NoError(t, err)
+				assert.Subset(t, cycle, reg.Cycles[0])
+			}
+		})
+	}
+}